@@ -23,7 +23,7 @@ type CambiosHorarioController struct {
 // @Produce json
 // @Success 200 {array} map[string]interface{} "Listado de cambios de horario"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
-// @Router /cambios_horario [get]
+// @Router /v1/cambios_horario [get]
 func (c *CambiosHorarioController) GetAll() {
 	o := orm.NewOrm()
 	var horarios []models.CambiosHorario
@@ -74,7 +74,7 @@ func (c *CambiosHorarioController) GetAll() {
 // @Success 200 {object} map[string]interface{} "Cambio de horario para la fecha actual"
 // @Failure 404 {object} models.ApiResponse "No hay cambios de horario para la fecha actual"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
-// @Router /cambios_horario/actual [get]
+// @Router /v1/cambios_horario/actual [get]
 func (c *CambiosHorarioController) GetByCurrentDate() {
 	o := orm.NewOrm()
 	var cambioHorario models.CambiosHorario
@@ -139,7 +139,7 @@ func (c *CambiosHorarioController) GetByCurrentDate() {
 // @Success 201 {object} map[string]interface{} "Cambio de horario creado"
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
-// @Router /cambios_horario [post]
+// @Router /v1/cambios_horario [post]
 func (c *CambiosHorarioController) Post() {
 	o := orm.NewOrm()
 	var input map[string]interface{}
@@ -299,7 +299,7 @@ func (c *CambiosHorarioController) Post() {
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
 // @Failure 404 {object} models.ApiResponse "Cambio de horario no encontrado"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
-// @Router /cambios_horario [put]
+// @Router /v1/cambios_horario [put]
 func (c *CambiosHorarioController) Put() {
 	o := orm.NewOrm()
 	id, err := c.GetInt64("id")
@@ -449,7 +449,7 @@ func (c *CambiosHorarioController) Put() {
 // @Success 200 {object} models.ApiResponse "Cambio de horario eliminado"
 // @Failure 404 {object} models.ApiResponse "Cambio de horario no encontrado"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
-// @Router /cambios_horario [delete]
+// @Router /v1/cambios_horario [delete]
 func (c *CambiosHorarioController) Delete() {
 	o := orm.NewOrm()
 	id, err := c.GetInt64("id")
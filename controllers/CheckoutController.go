@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// CheckoutController registra pedidos completos en una sola llamada
+type CheckoutController struct {
+	web.Controller
+}
+
+// @Title Post
+// @Summary Registrar un pedido completo (checkout)
+// @Description Crea en una sola transacción el pedido, sus productos, la relación con el cliente, el domicilio (si se envía DOMICILIO) y el pago por el total con el método indicado. Si algo falla no queda ningún registro. Admite la cabecera Idempotency-Key.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param body body models.CheckoutRequest true "Cliente, productos, domicilio y método de pago"
+// @Success 201 {object} models.ApiResponse "Pedido registrado con todos sus datos"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 403 {object} models.ApiResponse "Un cliente solo puede hacer pedidos a su nombre"
+// @Failure 422 {object} models.ApiResponse "Cliente, producto o método de pago inexistente"
+// @Failure 500 {object} models.ApiResponse "Error al registrar el pedido"
+// @Security BearerAuth
+// @Router /v2/pedidos/checkout [post]
+func (c *CheckoutController) Post() {
+	var req models.CheckoutRequest
+	if err := parseJSONBody(&c.Controller, &req); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	response, err := services.NewCheckoutService(newStore()).Checkout(&req, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.Header("Location", fmt.Sprintf("/restaurante/v2/pedidos/%d", response.PEDIDO.PK_ID_PEDIDO))
+	serveData(&c.Controller, http.StatusCreated, "Pedido registrado exitosamente", response)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"restaurante/models"
+	"restaurante/services"
 	"strconv"
 
 	"github.com/beego/beego/v2/client/orm"
@@ -27,23 +28,14 @@ type ClienteController struct {
 // @Success 200 {array} interface{} "Lista de clientes con los campos especificados"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /clientes [get]
+// @Router /v1/clientes [get]
 func (c *ClienteController) GetAll() {
-	o := orm.NewOrm()
-	var clientes []models.Cliente
-
 	// Obtener el valor del parámetro fields
 	fields := c.GetString("fields")
 
-	_, err := o.QueryTable(new(models.Cliente)).All(&clientes)
+	clientes, err := services.NewClienteService(newStore()).List()
 	if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al obtener clientes de la base de datos",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, err)
 		return
 	}
 
@@ -67,11 +59,7 @@ func (c *ClienteController) GetAll() {
 		return
 	}
 
-	// Respuesta completa por defecto, excluyendo las contraseñas
-	for i := range clientes {
-		clientes[i].PASSWORD = ""
-	}
-
+	// Respuesta completa por defecto, sin contraseñas
 	c.Ctx.Output.SetStatus(http.StatusOK)
 	c.Data["json"] = models.ApiResponse{
 		Code:    http.StatusOK,
@@ -91,9 +79,8 @@ func (c *ClienteController) GetAll() {
 // @Success 200 {object} models.Cliente "Cliente encontrado"
 // @Failure 404 {object} models.ApiResponse "Cliente no encontrado"
 // @Security BearerAuth
-// @Router /clientes/search [get]
+// @Router /v1/clientes/search [get]
 func (c *ClienteController) GetById() {
-	o := orm.NewOrm()
 	id, err := c.GetInt("id")
 
 	if err != nil || id == 0 {
@@ -107,22 +94,12 @@ func (c *ClienteController) GetById() {
 		return
 	}
 
-	cliente := models.Cliente{PK_DOCUMENTO_CLIENTE: id}
-
-	err = o.Read(&cliente)
-	if err == orm.ErrNoRows {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Cliente no encontrado",
-		}
-		c.ServeJSON()
+	cliente, err := services.NewClienteService(newStore()).GetByID(id)
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
-	// Excluir la contraseña de la respuesta
-	cliente.PASSWORD = ""
-
 	c.Ctx.Output.SetStatus(http.StatusOK)
 	c.Data["json"] = models.ApiResponse{
 		Code:    http.StatusOK,
@@ -142,7 +119,7 @@ func (c *ClienteController) GetById() {
 // @Success 201 {object} models.Cliente "Cliente creado"
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
 // @Security BearerAuth
-// @Router /clientes [post]
+// @Router /v1/clientes [post]
 func (c *ClienteController) Post() {
 	o := orm.NewOrm()
 	var cliente models.Cliente
@@ -208,7 +185,7 @@ func (c *ClienteController) Post() {
 // @Success 200 {object} models.Cliente "Cliente actualizado"
 // @Failure 404 {object} models.ApiResponse "Cliente no encontrado"
 // @Security BearerAuth
-// @Router /clientes [put]
+// @Router /v1/clientes [put]
 func (c *ClienteController) Put() {
 	o := orm.NewOrm()
 
@@ -318,7 +295,7 @@ func (c *ClienteController) Put() {
 // @Success 200 {object} models.ApiResponse "Cliente eliminado"
 // @Failure 404 {object} models.ApiResponse "Cliente no encontrado"
 // @Security BearerAuth
-// @Router /clientes [delete]
+// @Router /v1/clientes [delete]
 func (c *ClienteController) Delete() {
 	o := orm.NewOrm()
 
@@ -337,20 +314,31 @@ func (c *ClienteController) Delete() {
 
 	cliente := models.Cliente{PK_DOCUMENTO_CLIENTE: id}
 
-	if _, err := o.Delete(&cliente); err == nil {
-		c.Ctx.Output.SetStatus(http.StatusOK)
+	num, err := o.Delete(&cliente)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
 		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusOK,
-			Message: "Cliente eliminado",
+			Code:    http.StatusInternalServerError,
+			Message: "Error al eliminar el cliente",
+			Cause:   err.Error(),
 		}
 		c.ServeJSON()
-	} else {
+		return
+	}
+	if num == 0 {
 		c.Ctx.Output.SetStatus(http.StatusNotFound)
 		c.Data["json"] = models.ApiResponse{
 			Code:    http.StatusNotFound,
 			Message: "Cliente no encontrado",
-			Cause:   err.Error(),
 		}
 		c.ServeJSON()
+		return
+	}
+
+	c.Ctx.Output.SetStatus(http.StatusOK)
+	c.Data["json"] = models.ApiResponse{
+		Code:    http.StatusOK,
+		Message: "Cliente eliminado",
 	}
+	c.ServeJSON()
 }
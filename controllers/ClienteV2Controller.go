@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// ClienteV2Controller expone los recursos anidados de un cliente con parámetros de ruta y la
+// atención de los pedidos que llegan por teléfono
+type ClienteV2Controller struct {
+	web.Controller
+}
+
+// @Title GetPedidos
+// @Summary Historial de pedidos de un cliente (v2)
+// @Description Devuelve los últimos pedidos del cliente, del más reciente al más antiguo, con los productos tal como se cobraron y el favorito que los guarda. Un cliente solo ve su propio historial.
+// @Tags v2 clientes
+// @Produce json
+// @Param documento path int true "Documento del cliente"
+// @Param limite query int false "Cuántos pedidos devolver (por defecto historial_pedidos_cliente)"
+// @Success 200 {array} models.PedidoAnterior "Pedidos anteriores del cliente"
+// @Failure 400 {object} models.ApiResponse "Límite inválido"
+// @Failure 403 {object} models.ApiResponse "El historial es de otro cliente"
+// @Failure 404 {object} models.ApiResponse "Cliente no encontrado"
+// @Security BearerAuth
+// @Router /v2/clientes/{documento}/pedidos [get]
+func (c *ClienteV2Controller) GetPedidos() {
+	documento, err := pathInt64(&c.Controller, ":documento")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	limite, err := c.GetInt("limite", 0)
+	if err != nil {
+		serveError(&c.Controller, badRequestError("El parámetro 'limite' debe ser un número", err))
+		return
+	}
+
+	historial, err := services.NewRecompraService(newStore()).Historial(int(documento), limite, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Pedidos del cliente obtenidos exitosamente", historial)
+}
+
+// @Title GetFavoritos
+// @Summary Pedidos favoritos de un cliente (v2)
+// @Description Devuelve los pedidos que el cliente guardó como favoritos, con sus productos. Para pedirlos de nuevo se usa POST /v2/pedidos/{id}/repetir con el PK_ID_PEDIDO del favorito.
+// @Tags v2 clientes
+// @Produce json
+// @Param documento path int true "Documento del cliente"
+// @Success 200 {array} models.PedidoFavorito "Favoritos del cliente"
+// @Failure 403 {object} models.ApiResponse "Los favoritos son de otro cliente"
+// @Security BearerAuth
+// @Router /v2/clientes/{documento}/favoritos [get]
+func (c *ClienteV2Controller) GetFavoritos() {
+	documento, err := pathInt64(&c.Controller, ":documento")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	favoritos, err := services.NewRecompraService(newStore()).Favoritos(int(documento), currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Favoritos del cliente obtenidos exitosamente", favoritos)
+}
+
+// @Title PostFavorito
+// @Summary Guardar un pedido como favorito (v2)
+// @Description Guarda como favorito un pedido anterior del cliente con un nombre opcional. Los pedidos cancelados y los que ya son favoritos responden 409.
+// @Tags v2 clientes
+// @Accept json
+// @Produce json
+// @Param documento path int true "Documento del cliente"
+// @Param body body models.FavoritoRequest true "Pedido y nombre del favorito"
+// @Success 201 {object} models.PedidoFavorito "Favorito guardado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Los favoritos son de otro cliente"
+// @Failure 409 {object} models.ApiResponse "El pedido está cancelado o ya es favorito"
+// @Failure 422 {object} models.ApiResponse "El pedido no existe o no es del cliente"
+// @Security BearerAuth
+// @Router /v2/clientes/{documento}/favoritos [post]
+func (c *ClienteV2Controller) PostFavorito() {
+	documento, err := pathInt64(&c.Controller, ":documento")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var req models.FavoritoRequest
+	if err := parseJSONBody(&c.Controller, &req); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	favorito, err := services.NewRecompraService(newStore()).CreateFavorito(int(documento), &req, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.Header("Location", fmt.Sprintf("/restaurante/v2/clientes/%d/favoritos/%d", documento, favorito.PK_ID_FAVORITO))
+	serveData(&c.Controller, http.StatusCreated, "Favorito guardado exitosamente", favorito)
+}
+
+// @Title DeleteFavorito
+// @Summary Quitar un favorito (v2)
+// @Description Quita el pedido de los favoritos del cliente; el pedido no cambia.
+// @Tags v2 clientes
+// @Produce json
+// @Param documento path int true "Documento del cliente"
+// @Param id path int true "ID del favorito"
+// @Success 200 {object} models.ApiResponse "Favorito eliminado"
+// @Failure 403 {object} models.ApiResponse "Los favoritos son de otro cliente"
+// @Failure 404 {object} models.ApiResponse "Favorito no encontrado"
+// @Security BearerAuth
+// @Router /v2/clientes/{documento}/favoritos/{id} [delete]
+func (c *ClienteV2Controller) DeleteFavorito() {
+	documento, err := pathInt64(&c.Controller, ":documento")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	if err := services.NewRecompraService(newStore()).DeleteFavorito(int(documento), id, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Favorito eliminado exitosamente", nil)
+}
+
+// @Title GetPorTelefono
+// @Summary Identificar al cliente que llama (v2)
+// @Description Busca al cliente por su teléfono, con o sin +57 y con espacios o guiones, y devuelve el número en E.164, sus datos, las direcciones a las que ha pedido y sus últimos pedidos. Solo para el personal del restaurante.
+// @Tags v2 clientes
+// @Produce json
+// @Param numero query string true "Teléfono desde el que llama el cliente"
+// @Success 200 {object} models.ClienteLlamada "Cliente que llama"
+// @Failure 400 {object} models.ApiResponse "Teléfono inválido"
+// @Failure 403 {object} models.ApiResponse "Solo para el personal del restaurante"
+// @Failure 404 {object} models.ApiResponse "No hay un cliente con ese teléfono"
+// @Security BearerAuth
+// @Router /v2/clientes/telefono [get]
+func (c *ClienteV2Controller) GetPorTelefono() {
+	llamada, err := services.NewPedidoTelefonicoService(newStore()).Buscar(c.GetString("numero"), currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Cliente encontrado exitosamente", llamada)
+}
+
+// @Title PostPedidoTelefonico
+// @Summary Tomar un pedido por teléfono (v2)
+// @Description Registra el pedido de quien llama con el mismo flujo del checkout. El cliente se identifica por TELEFONO; si el número no está registrado se crea el cliente con los datos de CLIENTE y sin contraseña. Un domicilio sin teléfono queda con el número de la llamada. El pago y el domicilio quedan PENDIENTE y el pedido INICIADO hasta que se cobre. Solo para el personal del restaurante.
+// @Tags v2 clientes
+// @Accept json
+// @Produce json
+// @Param body body models.PedidoTelefonicoRequest true "Teléfono, cliente nuevo y pedido"
+// @Success 201 {object} models.PedidoTelefonicoResponse "Pedido registrado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos o faltan los datos del cliente nuevo"
+// @Failure 403 {object} models.ApiResponse "Solo para el personal del restaurante"
+// @Failure 409 {object} models.ApiResponse "El documento ya está registrado con otro teléfono"
+// @Failure 422 {object} models.ApiResponse "Producto, método de pago o cliente inválido"
+// @Security BearerAuth
+// @Router /v2/clientes/telefono/pedidos [post]
+func (c *ClienteV2Controller) PostPedidoTelefonico() {
+	var req models.PedidoTelefonicoRequest
+	if err := parseJSONBody(&c.Controller, &req); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	respuesta, err := services.NewPedidoTelefonicoService(newStore()).Crear(&req, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.Header("Location", fmt.Sprintf("/restaurante/v2/pedidos/%d", respuesta.PEDIDO.PK_ID_PEDIDO))
+	serveData(&c.Controller, http.StatusCreated, "Pedido telefónico registrado exitosamente", respuesta)
+}
@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/impresion"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// CocinaController alimenta la pantalla de cocina con los tickets de cada estación (v2)
+type CocinaController struct {
+	web.Controller
+}
+
+// @Title GetTickets
+// @Summary Tickets de cocina (v2)
+// @Description Devuelve los tickets de la estación del más antiguo al más reciente, con sus ítems, la mesa y si el pedido es a domicilio. Sin estado devuelve los PENDIENTE y PREPARANDO.
+// @Tags v2 cocina
+// @Accept json
+// @Produce json
+// @Param estacion query string false "Estación (PARRILLA, FRITOS, BEBIDAS...); por defecto todas"
+// @Param estado query string false "Estado de los tickets" Enums(PENDIENTE, PREPARANDO, LISTO)
+// @Success 200 {object} models.ApiResponse "Tickets obtenidos"
+// @Failure 400 {object} models.ApiResponse "Estado desconocido"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden ver la cocina"
+// @Security BearerAuth
+// @Router /v2/cocina/tickets [get]
+func (c *CocinaController) GetTickets() {
+	tickets, err := services.NewCocinaService(newStore()).Tickets(c.GetString("estacion"), c.GetString("estado"), currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Tickets de cocina obtenidos exitosamente", tickets)
+}
+
+// @Title PutItem
+// @Summary Cambiar el estado de un ítem (v2)
+// @Description Marca el ítem como PENDIENTE, PREPARANDO o LISTO. El ticket toma el estado de sus ítems y el pedido pasa a LISTO cuando están listos todos sus tickets.
+// @Tags v2 cocina
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del ticket"
+// @Param item path int true "ID del ítem del ticket"
+// @Param body body object true "Cuerpo con ESTADO"
+// @Success 200 {object} models.ApiResponse "Ticket actualizado"
+// @Failure 400 {object} models.ApiResponse "Estado desconocido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar la cocina"
+// @Failure 404 {object} models.ApiResponse "Ticket o ítem no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido ya no está en preparación"
+// @Security BearerAuth
+// @Router /v2/cocina/tickets/{id}/items/{item} [put]
+func (c *CocinaController) PutItem() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	item, err := pathInt64(&c.Controller, ":item")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input struct {
+		ESTADO string `json:"ESTADO"`
+	}
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	ticket, err := services.NewCocinaService(newStore()).UpdateItem(id, item, input.ESTADO, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Ítem del ticket actualizado correctamente", ticket)
+}
+
+// @Title PostBump
+// @Summary Despachar un ticket (v2)
+// @Description Marca listos todos los ítems del ticket y lo saca de la pantalla de la estación. Si era el último ticket pendiente, el pedido pasa a LISTO.
+// @Tags v2 cocina
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del ticket"
+// @Success 200 {object} models.ApiResponse "Ticket listo"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar la cocina"
+// @Failure 404 {object} models.ApiResponse "Ticket no encontrado"
+// @Failure 409 {object} models.ApiResponse "El ticket ya está listo o el pedido ya no está en preparación"
+// @Security BearerAuth
+// @Router /v2/cocina/tickets/{id}/bump [post]
+func (c *CocinaController) PostBump() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	ticket, err := services.NewCocinaService(newStore()).Bump(id, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Ticket despachado exitosamente", ticket)
+}
+
+// @Title PostRecall
+// @Summary Devolver un ticket a la cocina (v2)
+// @Description Vuelve a mostrar un ticket LISTO con sus ítems en PREPARANDO. Si el pedido ya estaba LISTO regresa a EN PREPARACION.
+// @Tags v2 cocina
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del ticket"
+// @Success 200 {object} models.ApiResponse "Ticket devuelto a la cocina"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar la cocina"
+// @Failure 404 {object} models.ApiResponse "Ticket no encontrado"
+// @Failure 409 {object} models.ApiResponse "El ticket no está listo o el pedido ya salió de la cocina"
+// @Security BearerAuth
+// @Router /v2/cocina/tickets/{id}/recall [post]
+func (c *CocinaController) PostRecall() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	ticket, err := services.NewCocinaService(newStore()).Recall(id, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Ticket devuelto a la cocina", ticket)
+}
+
+// @Title GetComanda
+// @Summary Comanda de un ticket (v2)
+// @Description Devuelve la comanda del ticket para la estación, con la mesa o el domicilio, los ítems, sus modificadores y notas, en texto plano, PDF o ESC/POS.
+// @Tags v2 cocina
+// @Produce plain,application/pdf,application/octet-stream
+// @Param id path int true "ID del ticket"
+// @Param formato query string false "Formato de la comanda (por defecto texto)" Enums(texto, pdf, escpos)
+// @Param ancho query int false "Ancho del papel en mm (por defecto 80)" Enums(58, 80)
+// @Success 200 {string} string "Comanda"
+// @Failure 400 {object} models.ApiResponse "Formato o ancho inválido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden ver las comandas"
+// @Failure 404 {object} models.ApiResponse "Ticket no encontrado"
+// @Security BearerAuth
+// @Router /v2/cocina/tickets/{id}/comanda [get]
+func (c *CocinaController) GetComanda() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	ancho, err := anchoDocumento(&c.Controller)
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	documento, err := services.NewCocinaService(newStore()).Comanda(id, ancho, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveDocumento(&c.Controller, documento, c.GetString("formato", impresion.FormatoTexto), fmt.Sprintf("comanda-%d", id))
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/repositories"
+	"restaurante/services"
+	"strconv"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// setETag publica la versión del registro en la cabecera ETag
+func setETag(c *web.Controller, v interface{ GetVersion() int }) {
+	c.Ctx.Output.Header("ETag", fmt.Sprintf(`"%d"`, v.GetVersion()))
+}
+
+// checkIfMatch exige la cabecera If-Match en los PUT y devuelve la versión esperada para
+// la actualización en los servicios. Sin cabecera responde 428 y si no coincide responde 412.
+func checkIfMatch(c *web.Controller, current services.Versioned) (int, error) {
+	header := strings.TrimSpace(c.Ctx.Input.Header("If-Match"))
+	if header == "" {
+		return 0, &services.Error{
+			Code:    http.StatusPreconditionRequired,
+			Message: "La cabecera If-Match es obligatoria; envíe el ETag obtenido al consultar el registro",
+			Data:    current,
+		}
+	}
+	if header == "*" {
+		return current.GetVersion(), nil
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`)
+		if version, err := strconv.Atoi(tag); err == nil && version == current.GetVersion() {
+			return version, nil
+		}
+	}
+	return 0, services.VersionMismatch(current)
+}
+
+// pedidoConIfMatch verifica la cabecera If-Match contra la versión vigente del pedido y aplica
+// cambio en la misma transacción, para que nadie lo modifique entre la verificación y el cambio.
+// Lo usan los PUT de los sub-recursos del pedido (estado, pago, mesa...), que también pisan
+// cambios de otros cajeros.
+func pedidoConIfMatch(c *web.Controller, id int, cambio func(tx repositories.Store) (*models.Pedido, error)) (*models.Pedido, error) {
+	var pedido *models.Pedido
+	err := newStore().Transaction(func(tx repositories.Store) error {
+		actual, err := services.NewPedidoService(tx).GetByID(id)
+		if err != nil {
+			return err
+		}
+		if _, err := checkIfMatch(c, actual); err != nil {
+			return err
+		}
+		pedido, err = cambio(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pedido, nil
+}
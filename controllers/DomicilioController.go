@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"restaurante/models"
+	"restaurante/services"
 	"strconv"
 	"time"
 
@@ -28,7 +29,7 @@ type DomicilioController struct {
 // @Success 200 {array} models.Domicilio "Lista de domicilios"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /domicilios [get]
+// @Router /v1/domicilios [get]
 func (c *DomicilioController) GetAll() {
 	o := orm.NewOrm()
 	qs := o.QueryTable(new(models.Domicilio))
@@ -96,9 +97,8 @@ func (c *DomicilioController) GetAll() {
 // @Success 200 {object} models.Domicilio "Domicilio encontrado"
 // @Failure 404 {object} models.ApiResponse "Domicilio no encontrado"
 // @Security BearerAuth
-// @Router /domicilios/search [get]
+// @Router /v1/domicilios/search [get]
 func (c *DomicilioController) GetById() {
-	o := orm.NewOrm()
 	id, err := c.GetInt("id")
 
 	if err != nil || id == 0 {
@@ -112,27 +112,14 @@ func (c *DomicilioController) GetById() {
 		return
 	}
 
-	domicilio := models.Domicilio{PK_ID_DOMICILIO: id}
-
-	err = o.Read(&domicilio)
-	if err == orm.ErrNoRows {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Domicilio no encontrado",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	domicilio, err := services.NewDomicilioService(newStore()).GetByID(id)
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
-	c.Ctx.Output.SetStatus(http.StatusOK)
-	c.Data["json"] = models.ApiResponse{
-		Code:    http.StatusOK,
-		Message: "Domicilio encontrado",
-		Data:    domicilio,
-	}
-	c.ServeJSON()
+	setETag(&c.Controller, domicilio)
+	serveData(&c.Controller, http.StatusOK, "Domicilio encontrado", domicilio)
 }
 
 // @Title Create
@@ -145,7 +132,7 @@ func (c *DomicilioController) GetById() {
 // @Success 201 {object} models.Domicilio "Domicilio creado"
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
 // @Security BearerAuth
-// @Router /domicilios [post]
+// @Router /v1/domicilios [post]
 func (c *DomicilioController) Post() {
 	o := orm.NewOrm()
 	var input map[string]interface{}
@@ -224,6 +211,9 @@ func (c *DomicilioController) Post() {
 	if createdBy, ok := input["CREATED_BY"].(string); ok {
 		domicilio.CREATED_BY = &createdBy
 	}
+	if distancia, ok := input["DISTANCIA_KM"].(float64); ok && distancia >= 0 {
+		domicilio.DISTANCIA_KM = &distancia
+	}
 
 	// Establecer valores automáticos
 	domicilio.CREATED_AT = time.Now().UTC()
@@ -241,6 +231,7 @@ func (c *DomicilioController) Post() {
 		c.ServeJSON()
 		return
 	}
+	services.PublicarDomicilio(newStore(), &domicilio, services.AccionCreado)
 
 	// Responder con éxito
 	c.Ctx.Output.SetStatus(http.StatusCreated)
@@ -259,14 +250,16 @@ func (c *DomicilioController) Post() {
 // @Accept json
 // @Produce json
 // @Param   id    query    int  true   "ID del Domicilio"
+// @Param   If-Match  header  string  true  "ETag obtenido al consultar el domicilio"
 // @Param   body  body   models.Domicilio true  "Datos del domicilio a actualizar"
 // @Success 200 {object} models.Domicilio "Domicilio actualizado"
 // @Failure 404 {object} models.ApiResponse "Domicilio no encontrado"
+// @Failure 409 {object} models.ApiResponse "El domicilio cambió durante la actualización"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
 // @Security BearerAuth
-// @Router /domicilios [put]
+// @Router /v1/domicilios [put]
 func (c *DomicilioController) Put() {
-	o := orm.NewOrm()
-
 	// Obtener el ID del domicilio
 	idStr := c.GetString("id")
 	id, err := strconv.Atoi(idStr)
@@ -282,14 +275,17 @@ func (c *DomicilioController) Put() {
 	}
 
 	// Buscar el domicilio por ID
-	domicilio := models.Domicilio{PK_ID_DOMICILIO: id}
-	if err := o.Read(&domicilio); err == orm.ErrNoRows {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Domicilio no encontrado",
-		}
-		c.ServeJSON()
+	service := services.NewDomicilioService(newStore())
+	domicilio, err := service.GetByID(id)
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	// Verificar que se está editando la última versión del domicilio
+	version, err := checkIfMatch(&c.Controller, domicilio)
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
@@ -322,21 +318,19 @@ func (c *DomicilioController) Put() {
 	if updatedBy, ok := input["UPDATED_BY"].(string); ok {
 		domicilio.UPDATED_BY = &updatedBy
 	}
+	if distancia, ok := input["DISTANCIA_KM"].(float64); ok && distancia >= 0 {
+		domicilio.DISTANCIA_KM = &distancia
+	}
 
 	// Actualizar la fecha de modificación
 	domicilio.UPDATED_AT = time.Now().UTC()
 
 	// Guardar cambios
-	if _, err := o.Update(&domicilio); err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al actualizar el domicilio",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	if err := service.Update(domicilio, version); err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
+	setETag(&c.Controller, domicilio)
 
 	// Responder con éxito
 	c.Ctx.Output.SetStatus(http.StatusOK)
@@ -358,10 +352,8 @@ func (c *DomicilioController) Put() {
 // @Success 204 {object} nil "Domicilio eliminado"
 // @Failure 404 {object} models.ApiResponse "Domicilio no encontrado"
 // @Security BearerAuth
-// @Router /domicilios [delete]
+// @Router /v1/domicilios [delete]
 func (c *DomicilioController) Delete() {
-	o := orm.NewOrm()
-
 	idStr := c.GetString("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id == 0 {
@@ -375,22 +367,10 @@ func (c *DomicilioController) Delete() {
 		return
 	}
 
-	domicilio := models.Domicilio{PK_ID_DOMICILIO: id}
-
-	if _, err := o.Delete(&domicilio); err == nil {
-		c.Ctx.Output.SetStatus(http.StatusOK)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusOK,
-			Message: "Domicilio eliminado",
-		}
-		c.ServeJSON()
-	} else {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Domicilio no encontrado",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	if err := services.NewDomicilioService(newStore()).Delete(id); err != nil {
+		serveError(&c.Controller, err)
+		return
 	}
+
+	serveData(&c.Controller, http.StatusOK, "Domicilio eliminado", nil)
 }
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"net/http"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// DomicilioV2Controller expone los domicilios como recurso REST con parámetros de ruta
+type DomicilioV2Controller struct {
+	web.Controller
+}
+
+// @Title Get
+// @Summary Obtener un domicilio (v2)
+// @Description Devuelve un domicilio específico por ID.
+// @Tags v2 domicilios
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del domicilio"
+// @Success 200 {object} models.Domicilio "Domicilio encontrado"
+// @Failure 400 {object} models.ApiResponse "ID inválido"
+// @Failure 404 {object} models.ApiResponse "Domicilio no encontrado"
+// @Security BearerAuth
+// @Router /v2/domicilios/{id} [get]
+func (c *DomicilioV2Controller) Get() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	domicilio, err := services.NewDomicilioService(newStore()).GetByID(int(id))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, domicilio)
+	serveData(&c.Controller, http.StatusOK, "Domicilio encontrado", domicilio)
+}
+
+// @Title PutDomiciliario
+// @Summary Asignar el domiciliario (v2)
+// @Description Asigna el trabajador que lleva el domicilio. El domiciliario recibe desde entonces los eventos del domicilio en /v2/eventos.
+// @Tags v2 domicilios
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del domicilio"
+// @Param body body object true "Cuerpo con PK_DOCUMENTO_DOMICILIARIO"
+// @Success 200 {object} models.Domicilio "Domiciliario asignado"
+// @Failure 400 {object} models.ApiResponse "Documento ausente o inválido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden asignar domiciliarios"
+// @Failure 404 {object} models.ApiResponse "Domicilio no encontrado"
+// @Failure 422 {object} models.ApiResponse "El trabajador no existe o está retirado"
+// @Security BearerAuth
+// @Router /v2/domicilios/{id}/domiciliario [put]
+func (c *DomicilioV2Controller) PutDomiciliario() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input struct {
+		PK_DOCUMENTO_DOMICILIARIO int64 `json:"PK_DOCUMENTO_DOMICILIARIO"`
+	}
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	domicilio, err := services.NewDomicilioService(newStore()).AssignDomiciliario(int(id), input.PK_DOCUMENTO_DOMICILIARIO, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, domicilio)
+	serveData(&c.Controller, http.StatusOK, "Domiciliario asignado correctamente", domicilio)
+}
+
+// @Title Delete
+// @Summary Eliminar un domicilio (v2)
+// @Description Elimina un domicilio por ID. No devuelve contenido.
+// @Tags v2 domicilios
+// @Param id path int true "ID del domicilio"
+// @Success 204 "Domicilio eliminado"
+// @Failure 400 {object} models.ApiResponse "ID inválido"
+// @Failure 404 {object} models.ApiResponse "Domicilio no encontrado"
+// @Security BearerAuth
+// @Router /v2/domicilios/{id} [delete]
+func (c *DomicilioV2Controller) Delete() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	if err := services.NewDomicilioService(newStore()).Delete(int(id)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.SetStatus(http.StatusNoContent)
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"restaurante/services"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/context"
+	"golang.org/x/net/websocket"
+)
+
+// intervaloLatido es cada cuánto se escribe un comentario en el stream para que los proxies no
+// cierren la conexión por inactividad
+const intervaloLatido = 25 * time.Second
+
+// EventoController transmite en tiempo real los cambios de pedidos, domicilios, pagos y reservas
+type EventoController struct {
+	web.Controller
+}
+
+// ValidateStreamToken acepta el token también en el parámetro 'token', porque EventSource y
+// WebSocket en el navegador no permiten enviar la cabecera Authorization
+func ValidateStreamToken(ctx *context.Context) {
+	if ctx.Input.Header("Authorization") == "" {
+		if token := ctx.Input.Query("token"); token != "" {
+			ctx.Request.Header.Set("Authorization", token)
+		}
+	}
+	ValidateToken(ctx)
+}
+
+// @Title GetStream
+// @Summary Eventos en tiempo real por SSE (v2)
+// @Description Abre un stream text/event-stream con los cambios de pedidos, domicilios, pagos y reservas. Cada evento lleva id, event (el TIPO) y data (el evento en JSON). Los clientes solo reciben sus pedidos y pagos, los domiciliarios sus domicilios y pedidos asignados y el resto del personal todo. El token se puede enviar en el parámetro 'token'.
+// @Tags v2 eventos
+// @Produce text/event-stream
+// @Param tipos query string false "Tipos separados por coma: PEDIDO, DOMICILIO, PAGO, RESERVA (todos si se omite)"
+// @Param token query string false "Token JWT cuando no se puede enviar la cabecera Authorization"
+// @Success 200 {object} models.Evento "Stream de eventos"
+// @Failure 400 {object} models.ApiResponse "Tipo de evento inválido"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Security BearerAuth
+// @Router /v2/eventos/stream [get]
+func (c *EventoController) GetStream() {
+	suscripcion, err := services.Eventos.Suscribir(currentActor(&c.Controller), tiposEvento(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	defer suscripcion.Cerrar()
+	// La respuesta la escribe el stream; beego no debe buscar una plantilla al terminar
+	c.EnableRender = false
+
+	w := c.Ctx.ResponseWriter
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": conectado\n\n")
+	w.Flush()
+
+	latido := time.NewTicker(intervaloLatido)
+	defer latido.Stop()
+	for {
+		select {
+		case <-c.Ctx.Request.Context().Done():
+			return
+		case <-latido.C:
+			fmt.Fprint(w, ": latido\n\n")
+			w.Flush()
+		case evento, ok := <-suscripcion.Eventos:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(evento)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evento.ID, evento.TIPO, data)
+			w.Flush()
+		}
+	}
+}
+
+// @Title GetWebSocket
+// @Summary Eventos en tiempo real por WebSocket (v2)
+// @Description Abre un WebSocket que envía cada evento como un mensaje JSON, con los mismos filtros por rol que /v2/eventos/stream. El token se puede enviar en el parámetro 'token'.
+// @Tags v2 eventos
+// @Param tipos query string false "Tipos separados por coma: PEDIDO, DOMICILIO, PAGO, RESERVA (todos si se omite)"
+// @Param token query string false "Token JWT cuando no se puede enviar la cabecera Authorization"
+// @Success 101 {object} models.Evento "Conexión establecida"
+// @Failure 400 {object} models.ApiResponse "Tipo de evento inválido o la solicitud no es un WebSocket"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Security BearerAuth
+// @Router /v2/eventos/ws [get]
+func (c *EventoController) GetWebSocket() {
+	if !strings.EqualFold(c.Ctx.Input.Header("Upgrade"), "websocket") {
+		serveError(&c.Controller, badRequestError("La solicitud debe abrir un WebSocket", nil))
+		return
+	}
+
+	suscripcion, err := services.Eventos.Suscribir(currentActor(&c.Controller), tiposEvento(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	defer suscripcion.Cerrar()
+	c.EnableRender = false
+
+	server := websocket.Server{
+		// El origen no se valida: la conexión ya se autenticó con el token
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			// Lo que envíe el cliente se descarta; la lectura solo detecta que cerró la conexión
+			cerrado := make(chan struct{})
+			go func() {
+				defer close(cerrado)
+				var mensaje string
+				for websocket.Message.Receive(ws, &mensaje) == nil {
+				}
+			}()
+
+			for {
+				select {
+				case <-cerrado:
+					return
+				case evento, ok := <-suscripcion.Eventos:
+					if !ok {
+						return
+					}
+					if err := websocket.JSON.Send(ws, evento); err != nil {
+						return
+					}
+				}
+			}
+		},
+	}
+	server.ServeHTTP(c.Ctx.ResponseWriter, c.Ctx.Request)
+}
+
+// tiposEvento lee el parámetro 'tipos' separado por comas
+func tiposEvento(c *web.Controller) []string {
+	tipos := c.GetString("tipos")
+	if tipos == "" {
+		return nil
+	}
+	return strings.Split(tipos, ",")
+}
@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// FacturacionController emite la factura electrónica y el documento equivalente POS de los
+// pedidos y administra las resoluciones de numeración de la DIAN (v2)
+type FacturacionController struct {
+	web.Controller
+}
+
+// @Title GetResoluciones
+// @Summary Listar resoluciones de facturación (v2)
+// @Description Devuelve los rangos de numeración autorizados por la DIAN con el consecutivo SIGUIENTE de cada uno.
+// @Tags v2 facturacion
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.ApiResponse "Resoluciones obtenidas"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar la facturación"
+// @Security BearerAuth
+// @Router /v2/facturacion/resoluciones [get]
+func (c *FacturacionController) GetResoluciones() {
+	resoluciones, err := services.NewFacturacionService(newStore()).Resoluciones(currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Resoluciones de facturación obtenidas exitosamente", resoluciones)
+}
+
+// @Title PostResolucion
+// @Summary Registrar una resolución de facturación (v2)
+// @Description Registra un rango de numeración de FACTURA o POS. El primer consecutivo es DESDE; las resoluciones de factura requieren la CLAVE_TECNICA que entra en el CUFE.
+// @Tags v2 facturacion
+// @Accept json
+// @Produce json
+// @Param body body models.ResolucionFacturacion true "Resolución"
+// @Success 201 {object} models.ApiResponse "Resolución registrada"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Solo un administrador puede registrar resoluciones"
+// @Security BearerAuth
+// @Router /v2/facturacion/resoluciones [post]
+func (c *FacturacionController) PostResolucion() {
+	resolucion := models.ResolucionFacturacion{ACTIVA: true}
+	if err := parseJSONBody(&c.Controller, &resolucion); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	resolucion.PK_ID_RESOLUCION = 0
+
+	if err := services.NewFacturacionService(newStore()).CreateResolucion(&resolucion, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusCreated, "Resolución de facturación registrada exitosamente", resolucion)
+}
+
+// @Title PutResolucion
+// @Summary Actualizar una resolución de facturación (v2)
+// @Description Cambia los datos de una resolución; con ACTIVA en false deja de asignar consecutivos. SIGUIENTE no se modifica y el rango debe incluir los números ya asignados.
+// @Tags v2 facturacion
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la resolución"
+// @Param body body models.ResolucionFacturacion true "Resolución"
+// @Success 200 {object} models.ApiResponse "Resolución actualizada"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Solo un administrador puede modificar resoluciones"
+// @Failure 404 {object} models.ApiResponse "Resolución no encontrada"
+// @Failure 422 {object} models.ApiResponse "El rango no incluye los números ya asignados"
+// @Security BearerAuth
+// @Router /v2/facturacion/resoluciones/{id} [put]
+func (c *FacturacionController) PutResolucion() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	resolucion := models.ResolucionFacturacion{ACTIVA: true}
+	if err := parseJSONBody(&c.Controller, &resolucion); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	resolucion.PK_ID_RESOLUCION = id
+
+	if err := services.NewFacturacionService(newStore()).UpdateResolucion(&resolucion, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Resolución de facturación actualizada exitosamente", resolucion)
+}
+
+// @Title GetDocumentos
+// @Summary Listar documentos electrónicos (v2)
+// @Description Devuelve las facturas y documentos equivalentes emitidos en orden de emisión, con su CUFE o CUDE y el estado del envío.
+// @Tags v2 facturacion
+// @Accept json
+// @Produce json
+// @Param pedido query int false "Solo los documentos de este pedido"
+// @Success 200 {object} models.ApiResponse "Documentos obtenidos"
+// @Failure 400 {object} models.ApiResponse "Pedido inválido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar la facturación"
+// @Security BearerAuth
+// @Router /v2/facturacion/documentos [get]
+func (c *FacturacionController) GetDocumentos() {
+	pedido, err := c.GetInt("pedido", 0)
+	if err != nil {
+		serveError(&c.Controller, badRequestError("El parámetro 'pedido' debe ser un número", err))
+		return
+	}
+
+	documentos, err := services.NewFacturacionService(newStore()).Documentos(pedido, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Documentos electrónicos obtenidos exitosamente", documentos)
+}
+
+// @Title PostDocumento
+// @Summary Emitir el documento electrónico de un pedido (v2)
+// @Description Genera el XML UBL 2.1 del pedido pagado como FACTURA o POS (por defecto), con el consecutivo de la resolución vigente, su CUFE o CUDE y la firma XAdES, lo guarda y lo envía al proveedor tecnológico. Sin ADQUIRENTE se factura al cliente del pedido o al consumidor final. Si el proveedor no responde el documento queda GENERADO para reenviarlo.
+// @Tags v2 facturacion
+// @Accept json
+// @Produce json
+// @Param body body models.DocumentoElectronicoRequest true "Pedido y adquirente"
+// @Success 201 {object} models.ApiResponse "Documento emitido"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar la facturación"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido está cancelado, tiene saldo o ya tiene documento"
+// @Failure 422 {object} models.ApiResponse "No hay una resolución vigente con números disponibles"
+// @Security BearerAuth
+// @Router /v2/facturacion/documentos [post]
+func (c *FacturacionController) PostDocumento() {
+	var input models.DocumentoElectronicoRequest
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	documento, err := services.NewFacturacionService(newStore()).Emitir(input, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusCreated, "Documento electrónico emitido exitosamente", documento)
+}
+
+// @Title GetDocumento
+// @Summary Obtener un documento electrónico (v2)
+// @Description Devuelve los datos de un documento emitido; el XML firmado se descarga en /xml.
+// @Tags v2 facturacion
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del documento"
+// @Success 200 {object} models.ApiResponse "Documento obtenido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar la facturación"
+// @Failure 404 {object} models.ApiResponse "Documento no encontrado"
+// @Security BearerAuth
+// @Router /v2/facturacion/documentos/{id} [get]
+func (c *FacturacionController) GetDocumento() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	documento, err := services.NewFacturacionService(newStore()).Documento(id, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Documento electrónico obtenido exitosamente", documento)
+}
+
+// @Title GetXML
+// @Summary Descargar el XML de un documento electrónico (v2)
+// @Description Descarga el documento UBL 2.1 firmado tal como se envió al proveedor.
+// @Tags v2 facturacion
+// @Produce xml
+// @Param id path int true "ID del documento"
+// @Success 200 {file} file "XML firmado"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar la facturación"
+// @Failure 404 {object} models.ApiResponse "Documento no encontrado"
+// @Security BearerAuth
+// @Router /v2/facturacion/documentos/{id}/xml [get]
+func (c *FacturacionController) GetXML() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	nombre, xml, err := services.NewFacturacionService(newStore()).XML(id, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.Header("Content-Type", "application/xml; charset=utf-8")
+	c.Ctx.Output.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, nombre))
+	if err := c.Ctx.Output.Body(xml); err != nil {
+		serveError(&c.Controller, err)
+	}
+}
+
+// @Title PostEnviar
+// @Summary Reenviar un documento electrónico (v2)
+// @Description Vuelve a enviar al proveedor tecnológico un documento GENERADO o RECHAZADO y guarda su respuesta.
+// @Tags v2 facturacion
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del documento"
+// @Success 200 {object} models.ApiResponse "Documento enviado"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar la facturación"
+// @Failure 404 {object} models.ApiResponse "Documento no encontrado"
+// @Failure 409 {object} models.ApiResponse "El documento ya fue aceptado"
+// @Security BearerAuth
+// @Router /v2/facturacion/documentos/{id}/enviar [post]
+func (c *FacturacionController) PostEnviar() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	documento, err := services.NewFacturacionService(newStore()).Reenviar(id, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Documento electrónico enviado exitosamente", documento)
+}
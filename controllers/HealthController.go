@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"net/http"
+	"restaurante/models"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+type HealthController struct {
+	web.Controller
+}
+
+// Get responde 200 mientras la API esté en funcionamiento; queda fuera del basePath
+// documentado en Swagger y sirve para balanceadores y verificaciones de despliegue
+func (c *HealthController) Get() {
+	c.Ctx.Output.SetStatus(http.StatusOK)
+	c.Data["json"] = models.ApiResponse{
+		Code:    http.StatusOK,
+		Message: "API restaurante en funcionamiento",
+	}
+	c.ServeJSON()
+}
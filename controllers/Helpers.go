@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/repositories"
+	"restaurante/services"
+	"strconv"
+	"strings"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+)
+
+// newStore devuelve los repositorios respaldados por la base de datos para los servicios
+func newStore() repositories.Store {
+	return repositories.NewOrmStore(orm.NewOrm())
+}
+
+// serveError responde con el código y mensaje de un error de la capa de servicios
+func serveError(c *web.Controller, err error) {
+	var svcErr *services.Error
+	if !errors.As(err, &svcErr) {
+		svcErr = &services.Error{Code: http.StatusInternalServerError, Message: "Error interno del servidor", Cause: err}
+	}
+
+	response := models.ApiResponse{
+		Code:    svcErr.Code,
+		Message: svcErr.Message,
+	}
+	if svcErr.Cause != nil {
+		response.Cause = svcErr.Cause.Error()
+	}
+	if svcErr.Data != nil {
+		response.Data = svcErr.Data
+		if versioned, ok := svcErr.Data.(services.Versioned); ok {
+			setETag(c, versioned)
+		}
+	}
+
+	c.Ctx.Output.SetStatus(svcErr.Code)
+	c.Data["json"] = response
+	c.ServeJSON()
+}
+
+// serveData responde con el código indicado y los datos dentro de la respuesta estándar
+func serveData(c *web.Controller, code int, message string, data any) {
+	c.Ctx.Output.SetStatus(code)
+	c.Data["json"] = models.ApiResponse{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	}
+	c.ServeJSON()
+}
+
+// badRequestError construye un error 400 para validaciones hechas en el controlador
+func badRequestError(message string, cause error) error {
+	return &services.Error{Code: http.StatusBadRequest, Message: message, Cause: cause}
+}
+
+// pathInt64 lee un parámetro numérico de la ruta (por ejemplo ":id")
+func pathInt64(c *web.Controller, name string) (int64, error) {
+	value, err := strconv.ParseInt(c.Ctx.Input.Param(name), 10, 64)
+	if err != nil || value <= 0 {
+		return 0, badRequestError(fmt.Sprintf("El parámetro de ruta '%s' es inválido", strings.TrimPrefix(name, ":")), err)
+	}
+	return value, nil
+}
+
+// parseJSONBody decodifica el cuerpo de la solicitud en la estructura indicada
+func parseJSONBody(c *web.Controller, dst any) error {
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, dst); err != nil {
+		return badRequestError("Error al decodificar la solicitud", err)
+	}
+	return nil
+}
+
+// claimsKey es la llave con la que ValidateToken deja los claims del JWT en el contexto
+const claimsKey = "claims"
+
+// currentActor devuelve el usuario autenticado de la petición, o el valor cero si la ruta no exige token
+func currentActor(c *web.Controller) services.Actor {
+	claims, ok := c.Ctx.Input.GetData(claimsKey).(*Claims)
+	if !ok || claims == nil {
+		return services.Actor{}
+	}
+	return services.Actor{Documento: claims.Documento, Rol: claims.Rol}
+}
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/context"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	idempotencyData   = "idempotencia"
+	// Tiempo tras el cual una solicitud que nunca terminó deja de bloquear la clave
+	idempotencyStale = time.Minute
+)
+
+// idempotencyHeaders son las cabeceras de la respuesta original que se repiten al reintentar
+var idempotencyHeaders = []string{"Content-Type", "Location"}
+
+// idempotencyWindow devuelve cuánto tiempo se conservan las respuestas (idempotencia_ventana en app.conf)
+func idempotencyWindow() time.Duration {
+	value := web.AppConfig.DefaultString("idempotencia_ventana", "24h")
+	window, err := time.ParseDuration(value)
+	if err != nil || window <= 0 {
+		return 24 * time.Hour
+	}
+	return window
+}
+
+// responseRecorder copia todo lo que escribe el controlador para poder almacenarlo
+type responseRecorder struct {
+	http.ResponseWriter
+	body strings.Builder
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+// CheckIdempotencyKey intercepta los POST con cabecera Idempotency-Key: repite la respuesta
+// almacenada si el usuario ya usó la clave con la misma solicitud, responde 409 si se usó con otra
+// distinta o si la original sigue en curso, y en otro caso reserva la clave y graba la respuesta.
+func CheckIdempotencyKey(ctx *context.Context) {
+	if ctx.Input.Method() != http.MethodPost {
+		return
+	}
+	key := strings.TrimSpace(ctx.Input.Header(idempotencyHeader))
+	if key == "" || strings.HasSuffix(strings.TrimSuffix(ctx.Input.URL(), "/"), "/login") {
+		return
+	}
+	if len(key) > 255 {
+		writeIdempotencyError(ctx, http.StatusBadRequest, "La cabecera Idempotency-Key no puede superar 255 caracteres")
+		return
+	}
+
+	o := orm.NewOrm()
+	now := time.Now()
+
+	usuario := idempotencyUser(ctx)
+	hash := requestHash(ctx, usuario)
+	registro := models.Idempotencia{USUARIO: usuario, CLAVE: key}
+	err := o.Read(&registro, "USUARIO", "CLAVE")
+	if err == nil {
+		abandoned := registro.CODIGO_RESPUESTA == 0 && now.Sub(registro.CREATED_AT) > idempotencyStale
+		// Una clave vencida que LimpiarClavesIdempotencia aún no borró se puede volver a usar
+		expired := now.Sub(registro.CREATED_AT) > idempotencyWindow()
+		switch {
+		case expired:
+			o.Delete(&registro)
+		case registro.HASH_SOLICITUD != hash:
+			writeIdempotencyError(ctx, http.StatusConflict, "La clave de idempotencia ya se usó con una solicitud diferente")
+			return
+		case abandoned:
+			o.Delete(&registro)
+		case registro.CODIGO_RESPUESTA == 0:
+			writeIdempotencyError(ctx, http.StatusConflict, "La solicitud original con esta clave de idempotencia aún se está procesando")
+			return
+		default:
+			replayIdempotentResponse(ctx, &registro)
+			return
+		}
+	} else if err != orm.ErrNoRows {
+		writeIdempotencyError(ctx, http.StatusInternalServerError, "Error al verificar la clave de idempotencia")
+		return
+	}
+
+	registro = models.Idempotencia{
+		USUARIO:        usuario,
+		CLAVE:          key,
+		METODO:         ctx.Input.Method(),
+		RUTA:           ctx.Input.URL(),
+		HASH_SOLICITUD: hash,
+		CABECERAS:      "{}",
+		CREATED_AT:     now,
+	}
+	if _, err := o.Insert(&registro); err != nil {
+		// Otra solicitud con la misma clave la reservó primero
+		writeIdempotencyError(ctx, http.StatusConflict, "La solicitud original con esta clave de idempotencia aún se está procesando")
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: ctx.ResponseWriter.ResponseWriter}
+	ctx.ResponseWriter.ResponseWriter = recorder
+	ctx.Input.SetData(idempotencyData, &registro)
+}
+
+// StoreIdempotentResponse guarda la respuesta de una solicitud con clave reservada.
+// Los errores 5xx liberan la clave para que el cliente pueda reintentar, y también las
+// respuestas con estado de éxito cuyo cuerpo informa un error en su código, como las de
+// algunos controladores v1: repetirlas le haría creer al cliente que la solicitud se completó.
+func StoreIdempotentResponse(ctx *context.Context) {
+	registro, ok := ctx.Input.GetData(idempotencyData).(*models.Idempotencia)
+	if !ok {
+		return
+	}
+	recorder, ok := ctx.ResponseWriter.ResponseWriter.(*responseRecorder)
+	if !ok {
+		return
+	}
+
+	o := orm.NewOrm()
+	status := ctx.ResponseWriter.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status >= http.StatusInternalServerError || bodyReportsError(status, recorder.body.String()) {
+		o.Delete(registro)
+		return
+	}
+
+	cabeceras := map[string]string{}
+	for _, name := range idempotencyHeaders {
+		if value := recorder.Header().Get(name); value != "" {
+			cabeceras[name] = value
+		}
+	}
+	encoded, _ := json.Marshal(cabeceras)
+
+	registro.CODIGO_RESPUESTA = status
+	registro.RESPUESTA = recorder.body.String()
+	registro.CABECERAS = string(encoded)
+	o.Update(registro, "CODIGO_RESPUESTA", "RESPUESTA", "CABECERAS")
+}
+
+// LimpiarClavesIdempotencia borra las claves más viejas que idempotencia_ventana y devuelve cuántas borró
+func LimpiarClavesIdempotencia() (int64, error) {
+	limite := time.Now().Add(-idempotencyWindow())
+	return orm.NewOrm().QueryTable(new(models.Idempotencia)).Filter("CREATED_AT__lt", limite).Delete()
+}
+
+// bodyReportsError indica si una respuesta con estado de éxito trae un ApiResponse con código de error
+func bodyReportsError(status int, body string) bool {
+	if status >= http.StatusBadRequest {
+		return false
+	}
+	var response models.ApiResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		return false
+	}
+	return response.Code >= http.StatusBadRequest
+}
+
+// idempotencyUser identifica al usuario autenticado como "rol:documento", o vacío sin sesión.
+// Se usa el usuario y no el token para que un reintento con un token renovado repita la respuesta.
+func idempotencyUser(ctx *context.Context) string {
+	if claims, ok := ctx.Input.GetData(claimsKey).(*Claims); ok && claims != nil {
+		return fmt.Sprintf("%s:%d", claims.Rol, claims.Documento)
+	}
+	return ""
+}
+
+// requestHash identifica la solicitud por método, ruta, cuerpo y usuario autenticado
+func requestHash(ctx *context.Context, usuario string) string {
+	h := sha256.New()
+	for _, part := range []string{ctx.Input.Method(), ctx.Input.URI(), usuario} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(ctx.Input.RequestBody)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replayIdempotentResponse(ctx *context.Context, registro *models.Idempotencia) {
+	cabeceras := map[string]string{}
+	json.Unmarshal([]byte(registro.CABECERAS), &cabeceras)
+	for name, value := range cabeceras {
+		ctx.Output.Header(name, value)
+	}
+	ctx.Output.Header("Idempotent-Replayed", "true")
+	ctx.Output.SetStatus(registro.CODIGO_RESPUESTA)
+	ctx.Output.Body([]byte(registro.RESPUESTA))
+}
+
+func writeIdempotencyError(ctx *context.Context, code int, message string) {
+	ctx.Output.SetStatus(code)
+	ctx.Output.JSON(models.ApiResponse{
+		Code:    code,
+		Message: message,
+	}, false, false)
+}
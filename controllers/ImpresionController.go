@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/impresion"
+	"restaurante/models"
+	"restaurante/services"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// ImpresionController administra las impresoras de red y la cola de recibos y comandas (v2)
+type ImpresionController struct {
+	web.Controller
+}
+
+// @Title GetImpresoras
+// @Summary Listar impresoras (v2)
+// @Description Devuelve las impresoras de red registradas. Las que tienen ESTACION imprimen las comandas de esa estación; las demás son de caja.
+// @Tags v2 impresion
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.ApiResponse "Impresoras obtenidas"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar las impresoras"
+// @Security BearerAuth
+// @Router /v2/impresoras [get]
+func (c *ImpresionController) GetImpresoras() {
+	impresoras, err := services.NewImpresionService(newStore()).Impresoras(currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Impresoras obtenidas exitosamente", impresoras)
+}
+
+// @Title PostImpresora
+// @Summary Registrar una impresora (v2)
+// @Description Registra una impresora térmica de red. Sin PUERTO usa el 9100, sin ANCHO papel de 80 mm y sin ACTIVA queda activa. CAJON indica que abre el cajón monedero al imprimir recibos. Solo para administradores.
+// @Tags v2 impresion
+// @Accept json
+// @Produce json
+// @Param body body models.Impresora true "Impresora"
+// @Success 201 {object} models.ApiResponse "Impresora registrada"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Solo para administradores"
+// @Security BearerAuth
+// @Router /v2/impresoras [post]
+func (c *ImpresionController) PostImpresora() {
+	impresora := models.Impresora{ACTIVA: true}
+	if err := parseJSONBody(&c.Controller, &impresora); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	impresora.PK_ID_IMPRESORA = 0
+
+	if err := services.NewImpresionService(newStore()).CreateImpresora(&impresora, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusCreated, "Impresora registrada exitosamente", impresora)
+}
+
+// @Title PutImpresora
+// @Summary Actualizar una impresora (v2)
+// @Description Cambia los datos de una impresora; con ACTIVA en false deja de recibir trabajos. Solo para administradores.
+// @Tags v2 impresion
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la impresora"
+// @Param body body models.Impresora true "Impresora"
+// @Success 200 {object} models.ApiResponse "Impresora actualizada"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Solo para administradores"
+// @Failure 404 {object} models.ApiResponse "Impresora no encontrada"
+// @Security BearerAuth
+// @Router /v2/impresoras/{id} [put]
+func (c *ImpresionController) PutImpresora() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	impresora := models.Impresora{ACTIVA: true}
+	if err := parseJSONBody(&c.Controller, &impresora); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	impresora.PK_ID_IMPRESORA = id
+
+	if err := services.NewImpresionService(newStore()).UpdateImpresora(&impresora, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Impresora actualizada exitosamente", impresora)
+}
+
+// @Title DeleteImpresora
+// @Summary Eliminar una impresora (v2)
+// @Description Elimina la impresora junto con su historial de trabajos. Solo para administradores.
+// @Tags v2 impresion
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la impresora"
+// @Success 200 {object} models.ApiResponse "Impresora eliminada"
+// @Failure 403 {object} models.ApiResponse "Solo para administradores"
+// @Failure 404 {object} models.ApiResponse "Impresora no encontrada"
+// @Security BearerAuth
+// @Router /v2/impresoras/{id} [delete]
+func (c *ImpresionController) DeleteImpresora() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	if err := services.NewImpresionService(newStore()).DeleteImpresora(id, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Impresora eliminada exitosamente", nil)
+}
+
+// @Title GetTrabajos
+// @Summary Cola de impresión (v2)
+// @Description Devuelve los trabajos de impresión del más antiguo al más reciente con su estado, los intentos hechos y el último error.
+// @Tags v2 impresion
+// @Accept json
+// @Produce json
+// @Param estado query string false "Estado de los trabajos" Enums(PENDIENTE, IMPRESO, FALLIDO)
+// @Success 200 {object} models.ApiResponse "Trabajos obtenidos"
+// @Failure 400 {object} models.ApiResponse "Estado desconocido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar las impresoras"
+// @Security BearerAuth
+// @Router /v2/impresion/trabajos [get]
+func (c *ImpresionController) GetTrabajos() {
+	trabajos, err := services.NewImpresionService(newStore()).Trabajos(c.GetString("estado"), currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Trabajos de impresión obtenidos exitosamente", trabajos)
+}
+
+// @Title PostTrabajo
+// @Summary Imprimir un recibo o las comandas de un pedido (v2)
+// @Description Encola el RECIBO del pedido en la impresora de caja o las COMANDA de sus tickets en la impresora de cada estación, y responde sin esperar a la impresora. Los trabajos se envían por TCP (puerto 9100) en ESC/POS con reintentos; su resultado se consulta en /v2/impresion/trabajos.
+// @Tags v2 impresion
+// @Accept json
+// @Produce json
+// @Param body body models.TrabajoImpresionRequest true "Trabajo de impresión"
+// @Success 202 {object} models.ApiResponse "Trabajos encolados"
+// @Failure 400 {object} models.ApiResponse "Tipo desconocido o pedido faltante"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar las impresoras"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 422 {object} models.ApiResponse "No hay una impresora activa para el trabajo o el pedido no tiene comandas"
+// @Security BearerAuth
+// @Router /v2/impresion/trabajos [post]
+func (c *ImpresionController) PostTrabajo() {
+	var input models.TrabajoImpresionRequest
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	trabajos, err := services.NewImpresionService(newStore()).Imprimir(input, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusAccepted, "Trabajos de impresión encolados exitosamente", trabajos)
+}
+
+// @Title PostReintentar
+// @Summary Reintentar un trabajo de impresión (v2)
+// @Description Vuelve a encolar un trabajo PENDIENTE o FALLIDO, por ejemplo después de cambiar el papel o la dirección de la impresora.
+// @Tags v2 impresion
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del trabajo"
+// @Success 202 {object} models.ApiResponse "Trabajo encolado"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden manejar las impresoras"
+// @Failure 404 {object} models.ApiResponse "Trabajo no encontrado"
+// @Failure 409 {object} models.ApiResponse "El trabajo ya se imprimió"
+// @Security BearerAuth
+// @Router /v2/impresion/trabajos/{id}/reintentar [post]
+func (c *ImpresionController) PostReintentar() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	trabajo, err := services.NewImpresionService(newStore()).Reintentar(id, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusAccepted, "Trabajo de impresión encolado exitosamente", trabajo)
+}
+
+// extensionesDocumento es la extensión del archivo que se descarga en cada formato
+var extensionesDocumento = map[string]string{
+	impresion.FormatoTexto:  "txt",
+	impresion.FormatoPDF:    "pdf",
+	impresion.FormatoEscPos: "bin",
+}
+
+// anchoDocumento lee el parámetro 'ancho' (58 u 80 mm, 80 si se omite)
+func anchoDocumento(c *web.Controller) (int, error) {
+	ancho, err := c.GetInt("ancho", impresion.Ancho80)
+	if err != nil || !impresion.AnchoValido(ancho) {
+		return 0, badRequestError("El parámetro 'ancho' debe ser 58 u 80", err)
+	}
+	return ancho, nil
+}
+
+// serveDocumento responde el documento en el formato pedido ('texto', 'pdf' o 'escpos') como archivo
+func serveDocumento(c *web.Controller, documento *impresion.Documento, formato, nombre string) {
+	formato = strings.ToLower(formato)
+	datos, contentType, ok := impresion.Renderizar(documento, formato)
+	if !ok {
+		serveError(c, badRequestError(fmt.Sprintf("Formato '%s' inválido; use %s", formato, strings.Join(impresion.Formatos, ", ")), nil))
+		return
+	}
+	c.Ctx.Output.Header("Content-Type", contentType)
+	c.Ctx.Output.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.%s"`, nombre, extensionesDocumento[formato]))
+	if err := c.Ctx.Output.Body(datos); err != nil {
+		serveError(c, err)
+	}
+}
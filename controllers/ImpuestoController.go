@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"net/http"
+	"restaurante/models"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// ImpuestoController administra las tarifas de impuesto por categoría de producto (v2)
+type ImpuestoController struct {
+	web.Controller
+}
+
+// @Title GetAll
+// @Summary Listar reglas de impuesto (v2)
+// @Description Devuelve la tarifa de cada categoría de impuesto (IMPOCONSUMO, IVA, EXENTO y las que se agreguen).
+// @Tags v2 impuestos
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.ApiResponse "Reglas de impuesto obtenidas"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Security BearerAuth
+// @Router /v2/impuestos [get]
+func (c *ImpuestoController) GetAll() {
+	reglas, err := services.NewImpuestoService(newStore()).List()
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Reglas de impuesto obtenidas exitosamente", reglas)
+}
+
+// @Title Put
+// @Summary Crear o actualizar una regla de impuesto (v2)
+// @Description Fija el nombre y la tarifa (porcentaje entre 0 y 100) de la categoría. Los pedidos ya registrados conservan la tarifa con la que se cobraron. Los precios de los productos incluyen el impuesto. Solo para administradores.
+// @Tags v2 impuestos
+// @Accept json
+// @Produce json
+// @Param categoria path string true "Categoría de impuesto"
+// @Param body body models.ReglaImpuesto true "Nombre y tarifa"
+// @Success 200 {object} models.ApiResponse "Regla de impuesto guardada"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 403 {object} models.ApiResponse "Solo para administradores"
+// @Security BearerAuth
+// @Router /v2/impuestos/{categoria} [put]
+func (c *ImpuestoController) Put() {
+	var regla models.ReglaImpuesto
+	if err := parseJSONBody(&c.Controller, &regla); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	regla.CATEGORIA = c.Ctx.Input.Param(":categoria")
+
+	if err := services.NewImpuestoService(newStore()).Save(&regla, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Regla de impuesto guardada exitosamente", regla)
+}
@@ -24,7 +24,7 @@ type IncidenciaController struct {
 // @Success 200 {array} models.Incidencia "Lista de incidencias"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /incidencias [get]
+// @Router /v1/incidencias [get]
 func (c *IncidenciaController) GetAll() {
 	o := orm.NewOrm()
 	var incidencias []models.Incidencia
@@ -64,7 +64,7 @@ func (c *IncidenciaController) GetAll() {
 // @Failure 404 {object} models.ApiResponse "No se encontraron incidencias"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /incidencias/search [get]
+// @Router /v1/incidencias/search [get]
 func (c *IncidenciaController) GetByDocumentAndDate() {
 	o := orm.NewOrm()
 
@@ -154,7 +154,7 @@ func (c *IncidenciaController) GetByDocumentAndDate() {
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /incidencias [post]
+// @Router /v1/incidencias [post]
 func (c *IncidenciaController) Post() {
 	o := orm.NewOrm()
 	var input map[string]interface{}
@@ -287,7 +287,7 @@ func (c *IncidenciaController) Post() {
 // @Failure 404 {object} models.ApiResponse "Incidencia no encontrada"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /incidencias [put]
+// @Router /v1/incidencias [put]
 func (c *IncidenciaController) Put() {
 	o := orm.NewOrm()
 
@@ -404,7 +404,7 @@ func (c *IncidenciaController) Put() {
 // @Success 200 {object} models.ApiResponse "Incidencia eliminada"
 // @Failure 404 {object} models.ApiResponse "Incidencia no encontrada"
 // @Security BearerAuth
-// @Router /incidencias [delete]
+// @Router /v1/incidencias [delete]
 func (c *IncidenciaController) Delete() {
 	o := orm.NewOrm()
 	id, err := c.GetInt64("id")
@@ -418,8 +418,8 @@ func (c *IncidenciaController) Delete() {
 		return
 	}
 
-	_, err = o.Delete(&models.Incidencia{PK_ID_INCIDENCIA: id})
-	if err == orm.ErrNoRows {
+	num, err := o.Delete(&models.Incidencia{PK_ID_INCIDENCIA: id})
+	if err == orm.ErrNoRows || (err == nil && num == 0) {
 		c.Ctx.Output.SetStatus(http.StatusNotFound)
 		c.Data["json"] = models.ApiResponse{
 			Code:    http.StatusNotFound,
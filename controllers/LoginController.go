@@ -40,7 +40,7 @@ var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 // @Success 200 {object} models.ApiResponse "Inicio de sesión exitoso con token JWT"
 // @Failure 400 {object} models.ApiResponse "Solicitud incorrecta"
 // @Failure 401 {object} models.ApiResponse "Credenciales inválidas"
-// @Router /login [post]
+// @Router /v1/login [post]
 func (c *LoginController) Login() {
 	var loginRequest models.LoginRequest
 	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &loginRequest); err != nil {
@@ -182,5 +182,6 @@ func ValidateToken(ctx *context.Context) {
 		return
 	}
 
-	// Se podría usar el rol aquí para autorizaciones más avanzadas
+	// Los controladores leen el usuario autenticado con currentActor
+	ctx.Input.SetData(claimsKey, claims)
 }
@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// MesaController administra las mesas del salón, sus rondas y el plano (v2)
+type MesaController struct {
+	web.Controller
+}
+
+// @Title GetAll
+// @Summary Listar mesas (v2)
+// @Description Devuelve las mesas ordenadas por zona y número con su estado (LIBRE, OCUPADA, POR LIMPIAR).
+// @Tags v2 mesas
+// @Accept json
+// @Produce json
+// @Param zona query string false "Solo las mesas de la zona"
+// @Success 200 {object} models.ApiResponse "Mesas obtenidas"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Security BearerAuth
+// @Router /v2/mesas [get]
+func (c *MesaController) GetAll() {
+	mesas, err := services.NewMesaService(newStore()).List(c.GetString("zona"))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Mesas obtenidas exitosamente", mesas)
+}
+
+// @Title GetPlano
+// @Summary Plano del salón (v2)
+// @Description Devuelve cuántas mesas están libres, ocupadas y por limpiar, y las mesas de cada zona con sus rondas abiertas, el mesero de cada una y el total pendiente.
+// @Tags v2 mesas
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.ApiResponse "Plano del salón"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Security BearerAuth
+// @Router /v2/mesas/plano [get]
+func (c *MesaController) GetPlano() {
+	plano, err := services.NewMesaService(newStore()).Plano()
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Plano del salón obtenido exitosamente", plano)
+}
+
+// @Title Post
+// @Summary Crear una mesa (v2)
+// @Description Registra una mesa LIBRE con su número, capacidad y zona.
+// @Tags v2 mesas
+// @Accept json
+// @Produce json
+// @Param body body models.Mesa true "Mesa"
+// @Success 201 {object} models.ApiResponse "Mesa creada"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden administrar las mesas"
+// @Failure 409 {object} models.ApiResponse "Ya existe una mesa con ese número"
+// @Security BearerAuth
+// @Router /v2/mesas [post]
+func (c *MesaController) Post() {
+	var mesa models.Mesa
+	if err := parseJSONBody(&c.Controller, &mesa); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	mesa.PK_ID_MESA = 0
+
+	if err := services.NewMesaService(newStore()).Create(&mesa, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.Header("Location", fmt.Sprintf("/restaurante/v2/mesas/%d", mesa.PK_ID_MESA))
+	serveData(&c.Controller, http.StatusCreated, "Mesa creada exitosamente", mesa)
+}
+
+// @Title Put
+// @Summary Actualizar una mesa (v2)
+// @Description Cambia el número, la capacidad y la zona de la mesa. El estado se cambia con /estado.
+// @Tags v2 mesas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la mesa"
+// @Param body body models.Mesa true "Mesa"
+// @Success 200 {object} models.ApiResponse "Mesa actualizada"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden administrar las mesas"
+// @Failure 404 {object} models.ApiResponse "Mesa no encontrada"
+// @Failure 409 {object} models.ApiResponse "Ya existe una mesa con ese número"
+// @Security BearerAuth
+// @Router /v2/mesas/{id} [put]
+func (c *MesaController) Put() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var mesa models.Mesa
+	if err := parseJSONBody(&c.Controller, &mesa); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	mesa.PK_ID_MESA = id
+
+	if err := services.NewMesaService(newStore()).Update(&mesa, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Mesa actualizada exitosamente", mesa)
+}
+
+// @Title PutEstado
+// @Summary Cambiar el estado de una mesa (v2)
+// @Description Marca la mesa como LIBRE, OCUPADA o POR LIMPIAR. Una mesa con pedidos abiertos sigue OCUPADA hasta que se entregan o cancelan.
+// @Tags v2 mesas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la mesa"
+// @Param body body object true "Cuerpo con ESTADO"
+// @Success 200 {object} models.ApiResponse "Estado de la mesa actualizado"
+// @Failure 400 {object} models.ApiResponse "Estado desconocido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden cambiar el estado de las mesas"
+// @Failure 404 {object} models.ApiResponse "Mesa no encontrada"
+// @Failure 409 {object} models.ApiResponse "Cambio no permitido o mesa con pedidos abiertos"
+// @Security BearerAuth
+// @Router /v2/mesas/{id}/estado [put]
+func (c *MesaController) PutEstado() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input struct {
+		ESTADO string `json:"ESTADO"`
+	}
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	mesa, err := services.NewMesaService(newStore()).UpdateEstado(id, input.ESTADO, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Estado de la mesa actualizado correctamente", mesa)
+}
+
+// @Title PostPedido
+// @Summary Abrir una ronda en una mesa (v2)
+// @Description Crea un pedido INICIADO en la mesa a nombre del mesero que lo toma (o del PK_DOCUMENTO_MESERO enviado) y la marca OCUPADA. Una mesa puede tener varias rondas abiertas.
+// @Tags v2 mesas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la mesa"
+// @Param body body models.Pedido true "Datos del pedido"
+// @Success 201 {object} models.ApiResponse "Ronda abierta"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 409 {object} models.ApiResponse "La mesa está por limpiar o el pedido es a domicilio"
+// @Failure 422 {object} models.ApiResponse "La mesa o el mesero no existen"
+// @Security BearerAuth
+// @Router /v2/mesas/{id}/pedidos [post]
+func (c *MesaController) PostPedido() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var pedido models.Pedido
+	if err := parseJSONBody(&c.Controller, &pedido); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	if err := services.NewMesaService(newStore()).AbrirRonda(id, &pedido, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.Header("Location", fmt.Sprintf("/restaurante/v2/pedidos/%d", pedido.PK_ID_PEDIDO))
+	serveData(&c.Controller, http.StatusCreated, "Ronda abierta exitosamente", pedido)
+}
+
+// @Title PostMover
+// @Summary Mover una mesa (v2)
+// @Description Pasa las rondas abiertas de la mesa a la mesa LIBRE indicada, que queda OCUPADA; la mesa de origen queda POR LIMPIAR.
+// @Tags v2 mesas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la mesa de origen"
+// @Param body body models.TrasladoMesa true "Mesa de destino"
+// @Success 200 {object} models.ApiResponse "Mesa de destino con sus rondas"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden mover las mesas"
+// @Failure 404 {object} models.ApiResponse "Mesa no encontrada"
+// @Failure 409 {object} models.ApiResponse "La mesa no tiene rondas abiertas o el destino no está libre"
+// @Failure 422 {object} models.ApiResponse "La mesa de destino no existe"
+// @Security BearerAuth
+// @Router /v2/mesas/{id}/mover [post]
+func (c *MesaController) PostMover() {
+	c.trasladar(false, "Mesa movida exitosamente")
+}
+
+// @Title PostUnir
+// @Summary Unir dos mesas (v2)
+// @Description Pasa las rondas abiertas de la mesa a la mesa OCUPADA indicada para atenderlas juntas; la mesa de origen queda POR LIMPIAR.
+// @Tags v2 mesas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la mesa de origen"
+// @Param body body models.TrasladoMesa true "Mesa de destino"
+// @Success 200 {object} models.ApiResponse "Mesa de destino con todas las rondas"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden unir las mesas"
+// @Failure 404 {object} models.ApiResponse "Mesa no encontrada"
+// @Failure 409 {object} models.ApiResponse "La mesa no tiene rondas abiertas o el destino no está ocupado"
+// @Failure 422 {object} models.ApiResponse "La mesa de destino no existe"
+// @Security BearerAuth
+// @Router /v2/mesas/{id}/unir [post]
+func (c *MesaController) PostUnir() {
+	c.trasladar(true, "Mesas unidas exitosamente")
+}
+
+// trasladar atiende mover y unir, que solo se diferencian en el estado que exigen al destino
+func (c *MesaController) trasladar(unir bool, mensaje string) {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input models.TrasladoMesa
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	mesa, err := services.NewMesaService(newStore()).Trasladar(id, input.PK_ID_MESA_DESTINO, unir, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, mensaje, mesa)
+}
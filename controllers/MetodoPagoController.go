@@ -23,7 +23,7 @@ type MetodoPagoController struct {
 // @Success 200 {array} models.MetodoPago "Lista de métodos de pago"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /metodos_pago [get]
+// @Router /v1/metodos_pago [get]
 func (c *MetodoPagoController) GetAll() {
 	o := orm.NewOrm()
 	var metodos []models.MetodoPago
@@ -59,7 +59,7 @@ func (c *MetodoPagoController) GetAll() {
 // @Success 200 {object} models.MetodoPago "Método de pago encontrado"
 // @Failure 404 {object} models.ApiResponse "Método de pago no encontrado"
 // @Security BearerAuth
-// @Router /metodos_pago/search [get]
+// @Router /v1/metodos_pago/search [get]
 func (c *MetodoPagoController) GetById() {
 	o := orm.NewOrm()
 	id, err := c.GetInt("id")
@@ -108,7 +108,7 @@ func (c *MetodoPagoController) GetById() {
 // @Success 201 {object} models.MetodoPago "Método de pago creado"
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
 // @Security BearerAuth
-// @Router /metodos_pago [post]
+// @Router /v1/metodos_pago [post]
 func (c *MetodoPagoController) Post() {
 	o := orm.NewOrm()
 	var metodo models.MetodoPago
@@ -156,7 +156,7 @@ func (c *MetodoPagoController) Post() {
 // @Success 200 {object} models.MetodoPago "Método de pago actualizado"
 // @Failure 404 {object} models.ApiResponse "Método de pago no encontrado"
 // @Security BearerAuth
-// @Router /metodos_pago [put]
+// @Router /v1/metodos_pago [put]
 func (c *MetodoPagoController) Put() {
 	o := orm.NewOrm()
 
@@ -229,7 +229,7 @@ func (c *MetodoPagoController) Put() {
 // @Success 200 {object} models.ApiResponse "Método de pago eliminado"
 // @Failure 404 {object} models.ApiResponse "Método de pago no encontrado"
 // @Security BearerAuth
-// @Router /metodos_pago [delete]
+// @Router /v1/metodos_pago [delete]
 func (c *MetodoPagoController) Delete() {
 	o := orm.NewOrm()
 
@@ -249,20 +249,31 @@ func (c *MetodoPagoController) Delete() {
 
 	metodo := models.MetodoPago{PK_ID_METODO_PAGO: id}
 
-	if _, err := o.Delete(&metodo); err == nil {
-		c.Ctx.Output.SetStatus(http.StatusOK)
+	num, err := o.Delete(&metodo)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
 		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusOK,
-			Message: "Método de pago eliminado",
+			Code:    http.StatusInternalServerError,
+			Message: "Error al eliminar el método de pago",
+			Cause:   err.Error(),
 		}
 		c.ServeJSON()
-	} else {
+		return
+	}
+	if num == 0 {
 		c.Ctx.Output.SetStatus(http.StatusNotFound)
 		c.Data["json"] = models.ApiResponse{
 			Code:    http.StatusNotFound,
 			Message: "Método de pago no encontrado",
-			Cause:   err.Error(),
 		}
 		c.ServeJSON()
+		return
+	}
+
+	c.Ctx.Output.SetStatus(http.StatusOK)
+	c.Data["json"] = models.ApiResponse{
+		Code:    http.StatusOK,
+		Message: "Método de pago eliminado",
 	}
+	c.ServeJSON()
 }
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// ModificadorController administra los grupos de modificadores de los productos (v2)
+type ModificadorController struct {
+	web.Controller
+}
+
+// @Title GetAll
+// @Summary Listar los modificadores de un producto (v2)
+// @Description Devuelve los grupos de modificadores del producto (por ejemplo "Término" o "Adiciones") con sus opciones, si son obligatorios, el mínimo y el máximo de opciones a elegir y lo que suma cada opción al precio.
+// @Tags v2 modificadores
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del producto"
+// @Success 200 {object} models.ApiResponse "Modificadores obtenidos exitosamente"
+// @Failure 400 {object} models.ApiResponse "ID inválido"
+// @Failure 404 {object} models.ApiResponse "Producto no encontrado"
+// @Router /v2/productos/{id}/modificadores [get]
+func (c *ModificadorController) GetAll() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	grupos, err := services.NewModificadorService(newStore()).List(id)
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Modificadores obtenidos exitosamente", grupos)
+}
+
+// @Title Post
+// @Summary Crear un grupo de modificadores (v2)
+// @Description Registra un grupo de modificadores con sus opciones para un producto. OBLIGATORIO exige al menos una opción y MAX_SELECCION en 0 no limita cuántas se eligen.
+// @Tags v2 modificadores
+// @Accept json
+// @Produce json
+// @Param body body models.GrupoModificador true "Grupo con PK_ID_PRODUCTO y OPCIONES"
+// @Success 201 {object} models.ApiResponse "Grupo de modificadores creado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 422 {object} models.ApiResponse "El producto no existe"
+// @Security BearerAuth
+// @Router /v2/modificadores [post]
+func (c *ModificadorController) Post() {
+	var grupo models.GrupoModificador
+	if err := parseJSONBody(&c.Controller, &grupo); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	if err := services.NewModificadorService(newStore()).Create(&grupo); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.Header("Location", fmt.Sprintf("/restaurante/v2/productos/%d/modificadores", grupo.PK_ID_PRODUCTO))
+	serveData(&c.Controller, http.StatusCreated, "Grupo de modificadores creado exitosamente", grupo)
+}
+
+// @Title Delete
+// @Summary Eliminar un grupo de modificadores (v2)
+// @Description Elimina el grupo y sus opciones. Los pedidos ya registrados conservan las opciones elegidas. No devuelve contenido.
+// @Tags v2 modificadores
+// @Param id path int true "ID del grupo de modificadores"
+// @Success 204 "Grupo eliminado"
+// @Failure 400 {object} models.ApiResponse "ID inválido"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 404 {object} models.ApiResponse "Grupo de modificadores no encontrado"
+// @Security BearerAuth
+// @Router /v2/modificadores/{id} [delete]
+func (c *ModificadorController) Delete() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	if err := services.NewModificadorService(newStore()).Delete(id); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.SetStatus(http.StatusNoContent)
+}
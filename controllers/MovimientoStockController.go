@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+	"restaurante/models"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// MovimientoStockController expone el libro de movimientos de inventario de los productos
+type MovimientoStockController struct {
+	web.Controller
+}
+
+// @Title GetAll
+// @Summary Listar movimientos de inventario
+// @Description Devuelve las entradas y salidas de unidades de un producto, de la más reciente a la más antigua, con su motivo (VENTA, CANCELACION, AJUSTE_PEDIDO, INVENTARIO_INICIAL, REABASTECIMIENTO, MERMA, AJUSTE_MANUAL) y el stock resultante.
+// @Tags movimientos_stock
+// @Accept json
+// @Produce json
+// @Param producto_id query int true "ID del producto"
+// @Success 200 {object} models.ApiResponse "Movimientos obtenidos exitosamente"
+// @Failure 400 {object} models.ApiResponse "ID inválido"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 404 {object} models.ApiResponse "Producto no encontrado"
+// @Security BearerAuth
+// @Router /v1/movimientos_stock [get]
+func (c *MovimientoStockController) GetAll() {
+	productoID, err := c.GetInt64("producto_id")
+	if err != nil || productoID <= 0 {
+		serveError(&c.Controller, badRequestError("El parámetro 'producto_id' es obligatorio y debe ser válido", err))
+		return
+	}
+
+	movimientos, err := services.NewStockService(newStore()).List(productoID)
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Movimientos obtenidos exitosamente", movimientos)
+}
+
+// @Title Post
+// @Summary Registrar un movimiento de inventario
+// @Description Ajusta las unidades de un producto. REABASTECIMIENTO suma unidades, MERMA las resta y AJUSTE_MANUAL acepta cantidades positivas o negativas. Solo aplica a productos con CONTROLA_STOCK. El producto pasa a NO DISPONIBLE al quedar sin unidades y vuelve a DISPONIBLE al reponerse.
+// @Tags movimientos_stock
+// @Accept json
+// @Produce json
+// @Param body body models.MovimientoStockRequest true "Producto, cantidad y motivo"
+// @Success 201 {object} models.ApiResponse "Movimiento registrado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos o motivo desconocido"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 409 {object} models.ApiResponse "El producto no tiene unidades suficientes o no controla inventario"
+// @Failure 422 {object} models.ApiResponse "El producto no existe"
+// @Security BearerAuth
+// @Router /v1/movimientos_stock [post]
+func (c *MovimientoStockController) Post() {
+	var req models.MovimientoStockRequest
+	if err := parseJSONBody(&c.Controller, &req); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	movimiento, err := services.NewStockService(newStore()).Registrar(&req, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusCreated, "Movimiento de inventario registrado exitosamente", movimiento)
+}
@@ -33,7 +33,7 @@ var estadosNominaPermitidos = map[string]bool{
 // @Success 200 {array} models.Nomina "Lista de nóminas"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /nominas [get]
+// @Router /v1/nominas [get]
 func (c *NominaController) GetAll() {
 	o := orm.NewOrm()
 	var nominas []models.Nomina
@@ -98,7 +98,7 @@ func (c *NominaController) GetAll() {
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /nominas [post]
+// @Router /v1/nominas [post]
 func (c *NominaController) Post() {
 	o := orm.NewOrm()
 	var input models.Nomina
@@ -172,7 +172,7 @@ func (c *NominaController) Post() {
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /nominas [put]
+// @Router /v1/nominas [put]
 func (c *NominaController) Put() {
 	o := orm.NewOrm()
 
@@ -246,7 +246,7 @@ func (c *NominaController) Put() {
 // @Success 200 {object} models.ApiResponse "Nómina eliminada lógicamente"
 // @Failure 404 {object} models.ApiResponse "Nómina no encontrada"
 // @Security BearerAuth
-// @Router /nominas [delete]
+// @Router /v1/nominas [delete]
 func (c *NominaController) Delete() {
 	o := orm.NewOrm()
 
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"restaurante/models"
-	"time"
+	"restaurante/services"
 
 	"github.com/beego/beego/v2/client/orm"
 	"github.com/beego/beego/v2/server/web"
@@ -24,7 +24,7 @@ type NominaTrabajadorController struct {
 // @Success 200 {array} models.NominaTrabajador "Listado de relaciones nómina-trabajador"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /nomina_trabajador [get]
+// @Router /v1/nomina_trabajador [get]
 func (c *NominaTrabajadorController) GetAll() {
 	o := orm.NewOrm()
 	var relaciones []models.NominaTrabajador
@@ -60,13 +60,12 @@ func (c *NominaTrabajadorController) GetAll() {
 // @Param body body models.NominaTrabajadorRequest true "Datos de la nómina-trabajador"
 // @Success 201 {object} models.NominaTrabajadorResponse "Nómina-trabajador creada"
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
+// @Failure 422 {object} models.ApiResponse "El trabajador indicado no existe"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /nomina_trabajador [post]
+// @Router /v1/nomina_trabajador [post]
 func (c *NominaTrabajadorController) Post() {
-	o := orm.NewOrm()
 	var input models.NominaTrabajadorRequest
-	var nominaTrabajador models.NominaTrabajador
 
 	// Decodificar la solicitud
 	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &input); err != nil {
@@ -80,108 +79,14 @@ func (c *NominaTrabajadorController) Post() {
 		return
 	}
 
-	// Validar documento del trabajador
-	if input.PK_DOCUMENTO_TRABAJADOR == 0 {
-		c.Ctx.Output.SetStatus(http.StatusBadRequest)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusBadRequest,
-			Message: "El campo PK_DOCUMENTO_TRABAJADOR es obligatorio y debe ser válido",
-		}
-		c.ServeJSON()
-		return
-	}
-	nominaTrabajador.PK_DOCUMENTO_TRABAJADOR = input.PK_DOCUMENTO_TRABAJADOR
-
-	// Calcular el rango de fechas
-	now := time.Now()
-	startDate := time.Date(now.Year(), now.Month()-1, 20, 0, 0, 0, 0, now.Location())
-	endDate := time.Date(now.Year(), now.Month(), 20, 23, 59, 59, 999, now.Location())
-
-	// Consultar incidencias
-	var incidencias []models.Incidencia
-	_, err := o.QueryTable(new(models.Incidencia)).
-		Filter("PK_DOCUMENTO_TRABAJADOR", input.PK_DOCUMENTO_TRABAJADOR).
-		Filter("FECHA__gte", startDate).
-		Filter("FECHA__lte", endDate).
-		All(&incidencias)
-
+	response, err := services.NewNominaTrabajadorService(newStore()).Create(input.PK_DOCUMENTO_TRABAJADOR)
 	if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al consultar incidencias del trabajador",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, err)
 		return
 	}
 
-	// Calcular el monto de incidencias
-	var montoIncidencias int64
-	for _, incidencia := range incidencias {
-		if incidencia.RESTA {
-			montoIncidencias -= incidencia.MONTO
-		} else {
-			montoIncidencias += incidencia.MONTO
-		}
-	}
-	nominaTrabajador.MONTO_INCIDENCIAS = &montoIncidencias
-
-	// Consultar el sueldo del trabajador
-	var trabajador models.Trabajador
-	err = o.QueryTable(new(models.Trabajador)).
-		Filter("PK_DOCUMENTO_TRABAJADOR", input.PK_DOCUMENTO_TRABAJADOR).
-		One(&trabajador)
-	if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al consultar el sueldo del trabajador",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
-		return
-	}
-	nominaTrabajador.SUELDO_BASE = trabajador.SUELDO
-
-	// Calcular el total a pagar
-	total := trabajador.SUELDO + montoIncidencias
-	nominaTrabajador.TOTAL = &total
-
-	// Generar descripción dinámica
-	descripcion := fmt.Sprintf("Nómina del mes de %s más incidencias si aplica", obtenerMesEnEspañol(now.Month()))
-	nominaTrabajador.DETALLES = &descripcion
-
-	// Registrar en la base de datos
-	_, err = o.Insert(&nominaTrabajador)
-	if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al registrar la nómina-trabajador",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
-		return
-	}
-
-	// Preparar la respuesta
-	response := models.NominaTrabajadorResponse{
-		PK_ID_NOMINA_TRABAJADOR: nominaTrabajador.PK_ID_NOMINA_TRABAJADOR,
-		SUELDO_BASE:             trabajador.SUELDO,
-		MONTO_INCIDENCIAS:       montoIncidencias,
-		TOTAL:                   total,
-		DETALLES:                descripcion,
-	}
-
 	// Responder con éxito
-	c.Ctx.Output.SetStatus(http.StatusCreated)
-	c.Data["json"] = models.ApiResponse{
-		Code:    http.StatusCreated,
-		Message: "Nómina-trabajador creada correctamente",
-		Data:    response,
-	}
-	c.ServeJSON()
+	serveData(&c.Controller, http.StatusCreated, "Nómina-trabajador creada correctamente", response)
 }
 
 // @Title GetByTrabajador
@@ -200,9 +105,8 @@ func (c *NominaTrabajadorController) Post() {
 // @Failure 404 {object} models.ApiResponse "Relación nómina-trabajador no encontrada"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /nomina_trabajador/search [get]
+// @Router /v1/nomina_trabajador/search [get]
 func (c *NominaTrabajadorController) GetByTrabajador() {
-	o := orm.NewOrm()
 	documento, _ := c.GetInt64("documento")
 	actual, _ := c.GetBool("actual")
 	pagas, _ := c.GetBool("pagas")
@@ -210,49 +114,20 @@ func (c *NominaTrabajadorController) GetByTrabajador() {
 	mes, _ := c.GetInt("mes")
 	anio, _ := c.GetInt("anio")
 
-	// Validar el documento del trabajador
-	if documento == 0 {
-		c.Ctx.Output.SetStatus(http.StatusBadRequest)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusBadRequest,
-			Message: "El parámetro 'documento' es obligatorio.",
-		}
-		c.ServeJSON()
+	relaciones, err := services.NewNominaTrabajadorService(newStore()).ListByTrabajador(documento, services.NominaFiltros{
+		Actual:  actual,
+		Pagas:   pagas,
+		NoPagas: noPagas,
+		Mes:     mes,
+		Anio:    anio,
+	})
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
-	// Base de la consulta
-	var relaciones []models.NominaTrabajador
-	sql := `
-        SELECT nt.* FROM "NOMINA_TRABAJADOR" nt
-        JOIN "NOMINA" n ON nt."PK_ID_NOMINA" = n."PK_ID_NOMINA"
-        WHERE nt."PK_DOCUMENTO_TRABAJADOR" = ?
-    `
-	params := []interface{}{documento}
-
-	// Filtrar por nómina actual
-	if actual {
-		sql += ` AND n."FECHA" = (SELECT MAX("FECHA") FROM "NOMINA")`
-	}
-
-	// Filtrar por nóminas pagas o no pagas
-	if pagas {
-		sql += ` AND n."ESTADO_NOMINA" = 'PAGO'`
-	} else if noPagas {
-		sql += ` AND n."ESTADO_NOMINA" = 'NO PAGO'`
-	}
-
-	// Filtrar por mes y año
-	if mes > 0 && anio > 0 {
-		sql += ` AND EXTRACT(MONTH FROM n."FECHA") = ? AND EXTRACT(YEAR FROM n."FECHA") = ?`
-		params = append(params, mes, anio)
-	}
-
-	// Ejecutar la consulta
-	_, err := o.Raw(sql, params...).QueryRows(&relaciones)
-
 	// Validar si hay resultados
-	if err == orm.ErrNoRows || len(relaciones) == 0 {
+	if len(relaciones) == 0 {
 		c.Ctx.Output.SetStatus(http.StatusNotFound)
 		c.Data["json"] = models.ApiResponse{
 			Code:    http.StatusNotFound,
@@ -260,43 +135,10 @@ func (c *NominaTrabajadorController) GetByTrabajador() {
 		}
 		c.ServeJSON()
 		return
-	} else if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al buscar las relaciones nómina-trabajador.",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
-		return
 	}
 
 	// Responder con éxito
-	c.Ctx.Output.SetStatus(http.StatusOK)
-	c.Data["json"] = models.ApiResponse{
-		Code:    http.StatusOK,
-		Message: "Relaciones nómina-trabajador encontradas.",
-		Data:    relaciones,
-	}
-	c.ServeJSON()
-}
-
-func obtenerMesEnEspañol(mes time.Month) string {
-	meses := map[time.Month]string{
-		time.January:   "Enero",
-		time.February:  "Febrero",
-		time.March:     "Marzo",
-		time.April:     "Abril",
-		time.May:       "Mayo",
-		time.June:      "Junio",
-		time.July:      "Julio",
-		time.August:    "Agosto",
-		time.September: "Septiembre",
-		time.October:   "Octubre",
-		time.November:  "Noviembre",
-		time.December:  "Diciembre",
-	}
-	return meses[mes]
+	serveData(&c.Controller, http.StatusOK, "Relaciones nómina-trabajador encontradas.", relaciones)
 }
 
 // @Title GetNominasByMes
@@ -311,7 +153,7 @@ func obtenerMesEnEspañol(mes time.Month) string {
 // @Failure 404 {object} models.ApiResponse "No se encontraron relaciones nómina-trabajador"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /nomina_trabajador/mes [get]
+// @Router /v1/nomina_trabajador/mes [get]
 func (c *NominaTrabajadorController) GetNominasByMes() {
 	o := orm.NewOrm()
 	mes, _ := c.GetInt("mes")
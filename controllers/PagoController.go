@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"restaurante/database"
 	"restaurante/models"
+	"restaurante/services"
 	"strconv"
 	"time"
 
@@ -38,7 +39,7 @@ var estadosPagoPermitidos = map[string]bool{
 // @Success 200 {array} models.Pago "Lista de pagos"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /pagos [get]
+// @Router /v1/pagos [get]
 func (c *PagoController) GetAll() {
 	o := orm.NewOrm()
 	var pagos []models.Pago
@@ -130,9 +131,8 @@ func (c *PagoController) GetAll() {
 // @Success 200 {object} models.Pago "Pago encontrado"
 // @Failure 404 {object} models.ApiResponse "Pago no encontrado"
 // @Security BearerAuth
-// @Router /pagos/search [get]
+// @Router /v1/pagos/search [get]
 func (c *PagoController) GetById() {
-	o := orm.NewOrm()
 	id, err := c.GetInt("id")
 
 	if err != nil || id == 0 {
@@ -146,35 +146,14 @@ func (c *PagoController) GetById() {
 		return
 	}
 
-	pago := models.Pago{PK_ID_PAGO: id}
-	err = o.Read(&pago)
-	if err == orm.ErrNoRows {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Pago no encontrado",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	pago, err := services.NewPagoService(newStore()).GetByID(id)
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
-	// Ajustar fechas y hora
-	pago.FECHA = pago.FECHA.In(database.BogotaZone)
-	pago.UPDATED_AT = pago.UPDATED_AT.In(database.BogotaZone)
-
-	// Formatear HORA
-	if len(pago.HORA) >= 19 {
-		pago.HORA = pago.HORA[11:19] // Formato HH:mm:ss
-	}
-
-	c.Ctx.Output.SetStatus(http.StatusOK)
-	c.Data["json"] = models.ApiResponse{
-		Code:    http.StatusOK,
-		Message: "Pago encontrado",
-		Data:    pago,
-	}
-	c.ServeJSON()
+	setETag(&c.Controller, pago)
+	serveData(&c.Controller, http.StatusOK, "Pago encontrado", pago)
 }
 
 // @Title Create
@@ -187,7 +166,7 @@ func (c *PagoController) GetById() {
 // @Success 201 {object} models.Pago "Pago creado"
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
 // @Security BearerAuth
-// @Router /pagos [post]
+// @Router /v1/pagos [post]
 func (c *PagoController) Post() {
 	o := orm.NewOrm()
 	var input map[string]interface{}
@@ -309,6 +288,7 @@ func (c *PagoController) Post() {
 		c.ServeJSON()
 		return
 	}
+	services.PublicarPago(newStore(), &pago, services.AccionCreado)
 
 	c.Ctx.Output.SetStatus(http.StatusCreated)
 	c.Data["json"] = models.ApiResponse{
@@ -326,14 +306,16 @@ func (c *PagoController) Post() {
 // @Accept json
 // @Produce json
 // @Param   id    query    int  true   "ID del Pago"
+// @Param   If-Match  header  string  true  "ETag obtenido al consultar el pago"
 // @Param   body  body   models.Pago true  "Datos del pago a actualizar"
 // @Success 200 {object} models.Pago "Pago actualizado"
 // @Failure 404 {object} models.ApiResponse "Pago no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pago cambió durante la actualización"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
 // @Security BearerAuth
-// @Router /pagos [put]
+// @Router /v1/pagos [put]
 func (c *PagoController) Put() {
-	o := orm.NewOrm()
-
 	// Obtener el ID del pago desde los parámetros
 	idStr := c.GetString("id")
 	id, err := strconv.Atoi(idStr)
@@ -349,14 +331,17 @@ func (c *PagoController) Put() {
 	}
 
 	// Buscar el pago por ID
-	pago := models.Pago{PK_ID_PAGO: id}
-	if err := o.Read(&pago); err == orm.ErrNoRows {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Pago no encontrado",
-		}
-		c.ServeJSON()
+	service := services.NewPagoService(newStore())
+	pago, err := service.GetForUpdate(id)
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	// Verificar que se está editando la última versión del pago
+	version, err := checkIfMatch(&c.Controller, pago)
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
@@ -452,16 +437,11 @@ func (c *PagoController) Put() {
 	}
 
 	// Guardar los cambios en la base de datos
-	if _, err := o.Update(&pago); err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al actualizar el pago",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	if err := service.Update(pago, version); err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
+	setETag(&c.Controller, pago)
 
 	// Responder con los datos actualizados
 	c.Ctx.Output.SetStatus(http.StatusOK)
@@ -475,18 +455,17 @@ func (c *PagoController) Put() {
 
 // @Title Delete
 // @Summary Eliminar un pago
-// @Description Elimina un pago de la base de datos.
+// @Description Elimina un pago que aún no se ha cobrado. Los pagos cobrados se reembolsan cancelando el pedido.
 // @Tags pagos
 // @Accept json
 // @Produce json
 // @Param   id     query    int     true        "ID del Pago"
 // @Success 200 {object} models.ApiResponse "Pago eliminado"
 // @Failure 404 {object} models.ApiResponse "Pago no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pago ya se cobró o se reembolsó"
 // @Security BearerAuth
-// @Router /pagos [delete]
+// @Router /v1/pagos [delete]
 func (c *PagoController) Delete() {
-	o := orm.NewOrm()
-
 	idStr := c.GetString("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id == 0 {
@@ -500,22 +479,10 @@ func (c *PagoController) Delete() {
 		return
 	}
 
-	pago := models.Pago{PK_ID_PAGO: id}
-
-	if _, err := o.Delete(&pago); err == nil {
-		c.Ctx.Output.SetStatus(http.StatusOK)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusOK,
-			Message: "Pago eliminado",
-		}
-		c.ServeJSON()
-	} else {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Pago no encontrado",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	if err := services.NewPagoService(newStore()).Delete(id); err != nil {
+		serveError(&c.Controller, err)
+		return
 	}
+
+	serveData(&c.Controller, http.StatusOK, "Pago eliminado", nil)
 }
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"net/http"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// PagoV2Controller expone los pagos como recurso REST con parámetros de ruta
+type PagoV2Controller struct {
+	web.Controller
+}
+
+// @Title Get
+// @Summary Obtener un pago (v2)
+// @Description Devuelve un pago específico por ID.
+// @Tags v2 pagos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pago"
+// @Success 200 {object} models.Pago "Pago encontrado"
+// @Failure 400 {object} models.ApiResponse "ID inválido"
+// @Failure 404 {object} models.ApiResponse "Pago no encontrado"
+// @Security BearerAuth
+// @Router /v2/pagos/{id} [get]
+func (c *PagoV2Controller) Get() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	pago, err := services.NewPagoService(newStore()).GetByID(int(id))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, pago)
+	serveData(&c.Controller, http.StatusOK, "Pago encontrado", pago)
+}
+
+// @Title Delete
+// @Summary Eliminar un pago (v2)
+// @Description Elimina un pago que aún no se ha cobrado. No devuelve contenido. Los pagos cobrados se reembolsan cancelando el pedido.
+// @Tags v2 pagos
+// @Param id path int true "ID del pago"
+// @Success 204 "Pago eliminado"
+// @Failure 400 {object} models.ApiResponse "ID inválido"
+// @Failure 404 {object} models.ApiResponse "Pago no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pago ya se cobró o se reembolsó"
+// @Security BearerAuth
+// @Router /v2/pagos/{id} [delete]
+func (c *PagoV2Controller) Delete() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	if err := services.NewPagoService(newStore()).Delete(int(id)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.SetStatus(http.StatusNoContent)
+}
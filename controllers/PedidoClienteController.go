@@ -1,10 +1,10 @@
 package controllers
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 	"restaurante/models"
+	"restaurante/services"
 
 	"github.com/beego/beego/v2/client/orm"
 	"github.com/beego/beego/v2/server/web"
@@ -23,7 +23,7 @@ type PedidoClienteController struct {
 // @Success 200 {array} models.PedidoCliente "Lista de relaciones"
 // @Security BearerAuth
 // @Failure 500 {object} models.ApiResponse "Error interno del servidor"
-// @Router /pedido_clientes [get]
+// @Router /v1/pedido_clientes [get]
 func (c *PedidoClienteController) GetAll() {
 	o := orm.NewOrm()
 	var relaciones []models.PedidoCliente
@@ -58,12 +58,11 @@ func (c *PedidoClienteController) GetAll() {
 // @Param body body models.PedidoCliente true "Datos de la relación a crear"
 // @Success 201 {object} models.ApiResponse "Relación creada"
 // @Failure 400 {object} models.ApiResponse "Datos inválidos o relación ya existente"
-// @Failure 404 {object} models.ApiResponse "Cliente o pedido no encontrado"
+// @Failure 422 {object} models.ApiResponse "Cliente o pedido no encontrado"
 // @Failure 500 {object} models.ApiResponse "Error interno del servidor"
 // @Security BearerAuth
-// @Router /pedido_clientes [post]
+// @Router /v1/pedido_clientes [post]
 func (c *PedidoClienteController) Post() {
-	o := orm.NewOrm()
 	var relacion models.PedidoCliente
 
 	// Parsear el cuerpo de la solicitud
@@ -78,76 +77,11 @@ func (c *PedidoClienteController) Post() {
 		return
 	}
 
-	// Ejecutar transacción
-	err := o.DoTx(func(ctx context.Context, txOrm orm.TxOrmer) error {
-		// Validar que el cliente existe
-		cliente := models.Cliente{PK_DOCUMENTO_CLIENTE: int(*relacion.PK_DOCUMENTO_CLIENTE)}
-		if err := txOrm.Read(&cliente); err != nil {
-			c.Ctx.Output.SetStatus(http.StatusNotFound)
-			c.Data["json"] = models.ApiResponse{
-				Code:    http.StatusNotFound,
-				Message: "Cliente no encontrado",
-				Cause:   err.Error(),
-			}
-			c.ServeJSON()
-			return err
-		}
-
-		// Validar que el pedido existe
-		pedido := models.Pedido{PK_ID_PEDIDO: *relacion.PK_ID_PEDIDO}
-		if err := txOrm.Read(&pedido); err != nil {
-			c.Ctx.Output.SetStatus(http.StatusNotFound)
-			c.Data["json"] = models.ApiResponse{
-				Code:    http.StatusNotFound,
-				Message: "Pedido no encontrado",
-				Cause:   err.Error(),
-			}
-			c.ServeJSON()
-			return err
-		}
-
-		// Validar que el pedido no pertenece ya a otro cliente
-		existingRelacion := models.PedidoCliente{}
-		err := txOrm.QueryTable(new(models.PedidoCliente)).
-			Filter("PK_ID_PEDIDO", *relacion.PK_ID_PEDIDO).
-			One(&existingRelacion)
-		if err == nil {
-			c.Ctx.Output.SetStatus(http.StatusBadRequest)
-			c.Data["json"] = models.ApiResponse{
-				Code:    http.StatusBadRequest,
-				Message: "El pedido ya pertenece a otro cliente",
-			}
-			c.ServeJSON()
-			return err
-		}
-
-		// Crear la relación
-		id, err := txOrm.Insert(&relacion)
-		if err != nil {
-			c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-			c.Data["json"] = models.ApiResponse{
-				Code:    http.StatusInternalServerError,
-				Message: "Error al crear la relación",
-				Cause:   err.Error(),
-			}
-			c.ServeJSON()
-			return err
-		}
-		relacion.PK_ID_PEDIDO_CLIENTE = id
-		return nil
-	})
-
-	// Manejo de errores
-	if err != nil {
+	if err := services.NewPedidoClienteService(newStore()).Create(&relacion); err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
 	// Respuesta exitosa
-	c.Ctx.Output.SetStatus(http.StatusCreated)
-	c.Data["json"] = models.ApiResponse{
-		Code:    http.StatusCreated,
-		Message: "Relación creada correctamente",
-		Data:    relacion,
-	}
-	c.ServeJSON()
+	serveData(&c.Controller, http.StatusCreated, "Relación creada correctamente", relacion)
 }
@@ -1,10 +1,10 @@
 package controllers
 
 import (
+	"net/http"
 	"restaurante/models" // Ajusta la ruta según tu proyecto
-	"time"
+	"restaurante/services"
 
-	"github.com/beego/beego/v2/client/orm"
 	"github.com/beego/beego/v2/server/web"
 )
 
@@ -14,7 +14,7 @@ type PedidoController struct {
 
 // @Title GetAll
 // @Summary Obtener pedidos con múltiples filtros
-// @Description Devuelve pedidos filtrados según varios criterios: fecha, rango de fechas, usuario (cliente), tipo de método de pago, si tienen domicilio, etc.
+// @Description Devuelve pedidos filtrados según varios criterios: fecha, rango de fechas, usuario (cliente), tipo de método de pago, si tienen domicilio, etc. A un cliente solo se le listan sus propios pedidos.
 // @Tags pedido
 // @Accept json
 // @Produce json
@@ -30,79 +30,28 @@ type PedidoController struct {
 // @Failure 400 {object} models.ApiResponse "Error en los parámetros de filtro"
 // @Failure 500 {object} models.ApiResponse "Error al obtener los pedidos"
 // @Security BearerAuth
-// @Router /pedidos [get]
+// @Router /v1/pedidos [get]
 func (c *PedidoController) GetAll() {
-	o := orm.NewOrm()
-
-	// Construcción de la consulta SQL
-	query := `
-        SELECT p.* 
-        FROM "PEDIDO" p
-        LEFT JOIN "PEDIDO_CLIENTE" pc ON p."PK_ID_PEDIDO" = pc."PK_ID_PEDIDO"
-        LEFT JOIN "PAGO" pa ON p."PK_ID_PAGO" = pa."PK_ID_PAGO"
-        LEFT JOIN "METODO_PAGO" mp ON pa."PK_ID_METODO_PAGO" = mp."PK_ID_METODO_PAGO"
-        WHERE 1 = 1
-    `
-
 	// Parámetros de filtro
-	params := []interface{}{}
-	fecha := c.GetString("fecha")
-	desde := c.GetString("desde")
-	hasta := c.GetString("hasta")
 	mes, _ := c.GetInt("mes")
 	anio, _ := c.GetInt("anio")
 	cliente, _ := c.GetInt("cliente")
-	metodoPago := c.GetString("metodo_pago")
-	domicilio, errDomicilio := c.GetBool("domicilio")
-
-	// Agregar filtros según los parámetros proporcionados
-	if fecha != "" {
-		query += ` AND p."FECHA" = ?`
-		params = append(params, fecha)
-	}
-
-	if desde != "" && hasta != "" {
-		query += ` AND p."FECHA" BETWEEN ? AND ?`
-		params = append(params, desde, hasta)
+	filtros := services.PedidoFiltros{
+		Fecha:      c.GetString("fecha"),
+		Desde:      c.GetString("desde"),
+		Hasta:      c.GetString("hasta"),
+		Mes:        mes,
+		Anio:       anio,
+		Cliente:    cliente,
+		MetodoPago: c.GetString("metodo_pago"),
 	}
-
-	if mes > 0 && mes <= 12 {
-		query += ` AND EXTRACT(MONTH FROM p."FECHA") = ?`
-		params = append(params, mes)
-		if anio > 0 {
-			query += ` AND EXTRACT(YEAR FROM p."FECHA") = ?`
-			params = append(params, anio)
-		}
-	}
-
-	if cliente > 0 {
-		query += ` AND pc."PK_DOCUMENTO_CLIENTE" = ?`
-		params = append(params, cliente)
+	if domicilio, err := c.GetBool("domicilio"); err == nil {
+		filtros.Domicilio = &domicilio
 	}
 
-	if metodoPago != "" {
-		query += ` AND mp."TIPO" ILIKE ?`
-		params = append(params, metodoPago)
-	}
-
-	if errDomicilio == nil {
-		if domicilio {
-			query += ` AND p."PK_ID_DOMICILIO" IS NOT NULL`
-		} else {
-			query += ` AND p."PK_ID_DOMICILIO" IS NULL`
-		}
-	}
-
-	// Ejecutar la consulta y obtener los resultados
-	var pedidos []models.Pedido
-	_, err := o.Raw(query, params...).QueryRows(&pedidos)
+	pedidos, err := services.NewPedidoService(newStore()).List(filtros, currentActor(&c.Controller))
 	if err != nil {
-		c.Data["json"] = models.ApiResponse{
-			Code:    500,
-			Message: "Error al obtener los pedidos",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, err)
 		return
 	}
 
@@ -117,12 +66,7 @@ func (c *PedidoController) GetAll() {
 	}
 
 	// Responder con los pedidos obtenidos
-	c.Data["json"] = models.ApiResponse{
-		Code:    200,
-		Message: "Pedidos obtenidos exitosamente",
-		Data:    pedidos,
-	}
-	c.ServeJSON()
+	serveData(&c.Controller, http.StatusOK, "Pedidos obtenidos exitosamente", pedidos)
 }
 
 // @Title CreatePedido
@@ -136,7 +80,7 @@ func (c *PedidoController) GetAll() {
 // @Failure 400 {object} models.ApiResponse "Datos inválidos"
 // @Failure 500 {object} models.ApiResponse "Error al crear el pedido"
 // @Security BearerAuth
-// @Router /pedidos [post]
+// @Router /v1/pedidos [post]
 func (c *PedidoController) CreatePedido() {
 	var pedido models.Pedido
 
@@ -151,228 +95,215 @@ func (c *PedidoController) CreatePedido() {
 		return
 	}
 
-	pedido.FECHA = time.Now()
-	pedido.ESTADO_PEDIDO = "INICIADO"
-	o := orm.NewOrm()
-	if _, err := o.Insert(&pedido); err != nil {
-		c.Ctx.Output.SetStatus(500)
-		c.Data["json"] = models.ApiResponse{
-			Code:    500,
-			Message: "Error al crear el pedido",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	if err := services.NewPedidoService(newStore()).Create(&pedido, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
-	c.Ctx.Output.SetStatus(200)
-	c.Data["json"] = models.ApiResponse{
-		Code:    200,
-		Message: "Pedido creado exitosamente",
-		Data:    pedido,
+	serveData(&c.Controller, http.StatusOK, "Pedido creado exitosamente", pedido)
+}
+
+// @Title Put
+// @Summary Actualizar un pedido
+// @Description Actualiza la hora, el tipo de entrega, el restaurante o el responsable de un pedido que no ha terminado. Si el pedido ya tiene pagos sus totales no cambian y el tipo de entrega tampoco. Requiere el ETag de la última consulta en If-Match.
+// @Tags pedido
+// @Accept json
+// @Produce json
+// @Param id query int true "ID del pedido"
+// @Param If-Match header string true "ETag obtenido al consultar el pedido"
+// @Param body body models.Pedido true "Campos del pedido a actualizar"
+// @Success 200 {object} models.ApiResponse "Pedido actualizado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido cambió durante la actualización, ya terminó o tiene pagos y cambia el tipo de entrega"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
+// @Security BearerAuth
+// @Router /v1/pedidos [put]
+func (c *PedidoController) Put() {
+	id, err := c.GetInt("id")
+	if err != nil || id <= 0 {
+		serveError(&c.Controller, badRequestError("El parámetro 'id' es inválido o está ausente", err))
+		return
+	}
+
+	pedido, err := updatePedido(&c.Controller, id)
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
 	}
-	c.ServeJSON()
+
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Pedido actualizado correctamente", pedido)
+}
+
+// updatePedido aplica el cuerpo de la solicitud al pedido verificando la cabecera If-Match
+func updatePedido(c *web.Controller, id int) (*models.Pedido, error) {
+	service := services.NewPedidoService(newStore())
+	pedido, err := service.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := checkIfMatch(c, pedido)
+	if err != nil {
+		return nil, err
+	}
+
+	var input struct {
+		HORA              *string `json:"HORA"`
+		DELIVERY          *bool   `json:"DELIVERY"`
+		PK_ID_RESTAURANTE *int    `json:"PK_ID_RESTAURANTE"`
+		UPDATED_BY        *string `json:"UPDATED_BY"`
+	}
+	if err := parseJSONBody(c, &input); err != nil {
+		return nil, err
+	}
+	if input.HORA != nil {
+		pedido.HORA = *input.HORA
+	}
+	if input.DELIVERY != nil {
+		pedido.DELIVERY = *input.DELIVERY
+	}
+	if input.PK_ID_RESTAURANTE != nil {
+		pedido.PK_ID_RESTAURANTE = input.PK_ID_RESTAURANTE
+	}
+	if input.UPDATED_BY != nil {
+		pedido.UPDATED_BY = *input.UPDATED_BY
+	}
+
+	if err := service.Update(pedido, version, currentActor(c)); err != nil {
+		return nil, err
+	}
+	return pedido, nil
 }
 
 // @Title AssignDomicilio
 // @Summary Asignar un domicilio a un pedido
-// @Description Asigna un domicilio existente a un pedido y actualiza su estado a "EN CAMINO".
+// @Description Asigna un domicilio existente a un pedido. Si el pedido está "LISTO" pasa a "EN CAMINO", lo que solo puede hacer el personal.
 // @Tags pedido
 // @Accept json
 // @Produce json
 // @Param pedido_id query int true "ID del pedido"
 // @Param domicilio_id query int true "ID del domicilio"
 // @Success 200 {object} models.ApiResponse "Domicilio asignado al pedido"
-// @Failure 404 {object} models.ApiResponse "Pedido o domicilio no encontrado"
+// @Failure 403 {object} models.ApiResponse "Un cliente no puede despachar el pedido"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido ya fue entregado o cancelado"
+// @Failure 422 {object} models.ApiResponse "El domicilio indicado no existe"
 // @Failure 500 {object} models.ApiResponse "Error al asignar domicilio"
 // @Security BearerAuth
-// @Router /pedidos/asignar-domicilio [post]
+// @Router /v1/pedidos/asignar-domicilio [post]
 func (c *PedidoController) AssignDomicilio() {
 	pedidoID, _ := c.GetInt("pedido_id")
 	domicilioID, _ := c.GetInt("domicilio_id")
 
-	o := orm.NewOrm()
-
-	// Buscar el pedido
-	pedido := models.Pedido{PK_ID_PEDIDO: pedidoID}
-	if err := o.Read(&pedido); err != nil {
-		c.Ctx.Output.SetStatus(404)
-		c.Data["json"] = models.ApiResponse{
-			Code:    404,
-			Message: "Pedido no encontrado",
-		}
-		c.ServeJSON()
-		return
-	}
-
-	// Actualizar el domicilio y el estado del pedido
-	pedido.PK_ID_DOMICILIO = &domicilioID
-	pedido.ESTADO_PEDIDO = "EN CAMINO"
-
-	if _, err := o.Update(&pedido, "PK_ID_DOMICILIO", "ESTADO_PEDIDO"); err != nil {
-		c.Ctx.Output.SetStatus(500)
-		c.Data["json"] = models.ApiResponse{
-			Code:    500,
-			Message: "Error al asignar domicilio",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	pedido, err := services.NewPedidoService(newStore()).AssignDomicilio(pedidoID, domicilioID, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
-	// Actualizar el estado del domicilio
-	domicilio := models.Domicilio{PK_ID_DOMICILIO: domicilioID}
-	if err := o.Read(&domicilio); err == nil {
-		domicilio.ENTREGADO = false
-		if _, err := o.Update(&domicilio, "ENTREGADO"); err != nil {
-			c.Ctx.Output.SetStatus(500)
-			c.Data["json"] = models.ApiResponse{
-				Code:    500,
-				Message: "Error al actualizar el domicilio",
-				Cause:   err.Error(),
-			}
-			c.ServeJSON()
-			return
-		}
-	}
-
-	c.Ctx.Output.SetStatus(200)
-	c.Data["json"] = models.ApiResponse{
-		Code:    200,
-		Message: "Domicilio asignado correctamente",
-		Data:    pedido,
-	}
-	c.ServeJSON()
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Domicilio asignado correctamente", pedido)
 }
 
 // @Title AssignPago
 // @Summary Asignar un pago a un pedido
-// @Description Asigna un pago existente a un pedido y actualiza su estado a "PAGADO".
+// @Description Asigna un pago existente a un pedido con productos. Si el pedido está "INICIADO" pasa a "PAGADO". Un cliente solo puede cobrar los pagos a su nombre.
 // @Tags pedido
 // @Accept json
 // @Produce json
 // @Param pedido_id query int true "ID del pedido"
 // @Param pago_id query int true "ID del pago"
 // @Success 200 {object} models.ApiResponse "Pago asignado al pedido"
-// @Failure 404 {object} models.ApiResponse "Pedido o pago no encontrado"
+// @Failure 403 {object} models.ApiResponse "El pago no está a nombre del cliente"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido está cancelado o no tiene productos"
+// @Failure 422 {object} models.ApiResponse "El pago indicado no existe"
 // @Failure 500 {object} models.ApiResponse "Error al asignar pago"
 // @Security BearerAuth
-// @Router /pedidos/asignar-pago [post]
+// @Router /v1/pedidos/asignar-pago [post]
 func (c *PedidoController) AssignPago() {
 	pedidoID, _ := c.GetInt("pedido_id")
 	pagoID, _ := c.GetInt("pago_id")
 
-	o := orm.NewOrm()
-
-	// Buscar el pedido
-	pedido := models.Pedido{PK_ID_PEDIDO: pedidoID}
-	if err := o.Read(&pedido); err != nil {
-		c.Ctx.Output.SetStatus(404)
-		c.Data["json"] = models.ApiResponse{
-			Code:    404,
-			Message: "Pedido no encontrado",
-		}
-		c.ServeJSON()
-		return
-	}
-
-	// Actualizar el pago y el estado del pedido
-	pedido.PK_ID_PAGO = &pagoID
-	pedido.ESTADO_PEDIDO = "PAGADO"
-
-	if _, err := o.Update(&pedido, "PK_ID_PAGO", "ESTADO_PEDIDO"); err != nil {
-		c.Ctx.Output.SetStatus(500)
-		c.Data["json"] = models.ApiResponse{
-			Code:    500,
-			Message: "Error al asignar pago",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	pedido, err := services.NewPedidoService(newStore()).AssignPago(pedidoID, pagoID, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
-	// Actualizar el estado del pago
-	pago := models.Pago{PK_ID_PAGO: pagoID}
-	if err := o.Read(&pago); err == nil {
-		pago.ESTADO_PAGO = "PAGADO"
-		if _, err := o.Update(&pago, "ESTADO_PAGO"); err != nil {
-			c.Ctx.Output.SetStatus(500)
-			c.Data["json"] = models.ApiResponse{
-				Code:    500,
-				Message: "Error al actualizar el pago",
-				Cause:   err.Error(),
-			}
-			c.ServeJSON()
-			return
-		}
-	}
-
-	c.Ctx.Output.SetStatus(200)
-	c.Data["json"] = models.ApiResponse{
-		Code:    200,
-		Message: "Pago asignado correctamente",
-		Data:    pedido,
-	}
-	c.ServeJSON()
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Pago asignado correctamente", pedido)
 }
 
 // @Title UpdateEstadoPedido
 // @Summary Actualizar el estado de un pedido
-// @Description Actualiza el estado de un pedido existente.
+// @Description Mueve el pedido al estado indicado siguiendo su ciclo de vida: INICIADO → (PAGADO) → EN PREPARACION → LISTO → EN CAMINO (domicilios) → ENTREGADO. CANCELADO sigue las reglas de la cancelación con el motivo OTRO. Cada cambio queda en el historial del pedido.
 // @Tags pedido
 // @Accept json
 // @Produce json
 // @Param pedido_id query int true "ID del pedido"
-// @Param estado query string true "Nuevo estado del pedido"
+// @Param estado query string true "Nuevo estado del pedido" Enums(INICIADO, PAGADO, EN PREPARACION, LISTO, EN CAMINO, ENTREGADO, CANCELADO)
 // @Success 200 {object} models.ApiResponse "Estado actualizado"
+// @Failure 400 {object} models.ApiResponse "Estado de pedido inválido"
+// @Failure 403 {object} models.ApiResponse "Un cliente solo puede cancelar el pedido"
 // @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "Transición de estado no permitida"
 // @Failure 500 {object} models.ApiResponse "Error al actualizar estado del pedido"
 // @Security BearerAuth
-// @Router /pedidos/actualizar-estado [put]
+// @Router /v1/pedidos/actualizar-estado [put]
 func (c *PedidoController) UpdateEstadoPedido() {
 	pedidoID, _ := c.GetInt("pedido_id")
 	estado := c.GetString("estado")
 
-	o := orm.NewOrm()
-
-	// Buscar el pedido
-	pedido := models.Pedido{PK_ID_PEDIDO: pedidoID}
-	if err := o.Read(&pedido); err != nil {
-		c.Ctx.Output.SetStatus(404)
-		c.Data["json"] = models.ApiResponse{
-			Code:    404,
-			Message: "Pedido no encontrado",
-		}
-		c.ServeJSON()
+	pedido, err := services.NewPedidoService(newStore()).UpdateEstado(pedidoID, estado, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
-	// Actualizar el estado del pedido
-	pedido.ESTADO_PEDIDO = estado
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Estado del pedido actualizado correctamente", pedido)
+}
 
-	if _, err := o.Update(&pedido, "ESTADO_PEDIDO"); err != nil {
-		c.Ctx.Output.SetStatus(500)
-		c.Data["json"] = models.ApiResponse{
-			Code:    500,
-			Message: "Error al actualizar estado del pedido",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+// @Title Delete
+// @Summary Cancelar un pedido
+// @Description Cancela el pedido: si ya estaba confirmado sus productos vuelven al inventario, el pago cobrado queda con un reembolso PENDIENTE y se registra el motivo. El administrador puede cancelar en cualquier estado; los clientes solo sus pedidos antes de que entren a cocina y dentro del plazo cancelacion_cliente_minutos.
+// @Tags pedido
+// @Accept json
+// @Produce json
+// @Param id query int true "ID del pedido"
+// @Param motivo query string false "Motivo de la cancelación (por defecto OTRO)" Enums(CLIENTE_DESISTE, DEMORA, SIN_PRODUCTO, ERROR_PEDIDO, PAGO_RECHAZADO, OTRO)
+// @Param detalle query string false "Explicación de la cancelación"
+// @Success 200 {object} models.ApiResponse "Pedido cancelado"
+// @Failure 400 {object} models.ApiResponse "ID o motivo inválido"
+// @Failure 403 {object} models.ApiResponse "El cliente no puede cancelar este pedido"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido ya no se puede cancelar"
+// @Security BearerAuth
+// @Router /v1/pedidos [delete]
+func (c *PedidoController) Delete() {
+	id, err := c.GetInt("id")
+	if err != nil || id == 0 {
+		serveError(&c.Controller, badRequestError("El parámetro 'id' es inválido o está ausente", err))
 		return
 	}
+	motivo := c.GetString("motivo", services.CancelacionOtro)
 
-	c.Ctx.Output.SetStatus(200)
-	c.Data["json"] = models.ApiResponse{
-		Code:    200,
-		Message: "Estado del pedido actualizado correctamente",
-		Data:    pedido,
+	cancelacion, err := services.NewCancelacionService(newStore()).Cancelar(id, motivo, c.GetString("detalle"), currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
 	}
-	c.ServeJSON()
+
+	serveData(&c.Controller, http.StatusOK, "Pedido cancelado correctamente", cancelacion)
 }
 
 // @Title GetPedidoDetails
 // @Summary Obtener detalles completos de un pedido
-// @Description Devuelve la información del pedido, tipo de pago y los productos asociados.
+// @Description Devuelve la información del pedido, tipo de pago, los productos asociados y el historial de cambios de estado (HISTORIAL).
 // @Tags pedido
 // @Accept json
 // @Produce json
@@ -382,13 +313,12 @@ func (c *PedidoController) UpdateEstadoPedido() {
 // @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
 // @Failure 500 {object} models.ApiResponse "Error al obtener los detalles del pedido"
 // @Security BearerAuth
-// @Router /pedidos/detalles [get]
+// @Router /v1/pedidos/detalles [get]
 func (c *PedidoController) GetPedidoDetails() {
-	o := orm.NewOrm()
-
 	// Parámetro
 	pedidoID, _ := c.GetInt64("pedido_id")
 	if pedidoID == 0 {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
 		c.Data["json"] = models.ApiResponse{
 			Code:    400,
 			Message: "El parámetro 'pedido_id' es obligatorio.",
@@ -397,53 +327,13 @@ func (c *PedidoController) GetPedidoDetails() {
 		return
 	}
 
-	// Consulta para obtener detalles del pedido
-	query := `
-        SELECT 
-            p."PK_ID_PEDIDO",
-            p."FECHA",
-            p."HORA",
-            p."DELIVERY",
-            p."ESTADO_PEDIDO",
-            mp."TIPO" AS metodo_pago,
-            COALESCE((SELECT jsonb_agg(elementos)::text FROM (
-                SELECT jsonb_array_elements(pp."DETALLES_PRODUCTOS") AS elementos
-                FROM "PRODUCTO_PEDIDO" pp
-                WHERE pp."PK_ID_PEDIDO" = p."PK_ID_PEDIDO"
-            ) subquery), '[]') AS productos
-        FROM "PEDIDO" p
-        LEFT JOIN "PAGO" pa ON p."PK_ID_PAGO" = pa."PK_ID_PAGO"
-        LEFT JOIN "METODO_PAGO" mp ON pa."PK_ID_METODO_PAGO" = mp."PK_ID_METODO_PAGO"
-        WHERE p."PK_ID_PEDIDO" = ?;
-    `
-
-	var details struct {
-		PKIDPedido   int64  `json:"PK_ID_PEDIDO"`
-		Fecha        string `json:"FECHA"`
-		Hora         string `json:"HORA"`
-		Delivery     bool   `json:"DELIVERY"`
-		EstadoPedido string `json:"ESTADO_PEDIDO"`
-		MetodoPago   string `json:"METODO_PAGO"`
-		Productos    string `json:"PRODUCTOS"`
-	}
-
-	// Ejecutar consulta
-	err := o.Raw(query, pedidoID).QueryRow(&details)
+	details, err := services.NewPedidoService(newStore()).GetDetails(pedidoID, currentActor(&c.Controller))
 	if err != nil {
-		c.Data["json"] = models.ApiResponse{
-			Code:    500,
-			Message: "Error al obtener los detalles del pedido",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, err)
 		return
 	}
 
 	// Respuesta exitosa
-	c.Data["json"] = models.ApiResponse{
-		Code:    200,
-		Message: "Detalles del pedido obtenidos exitosamente",
-		Data:    details,
-	}
-	c.ServeJSON()
+	setETag(&c.Controller, details)
+	serveData(&c.Controller, http.StatusOK, "Detalles del pedido obtenidos exitosamente", details)
 }
@@ -0,0 +1,687 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/repositories"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// PedidoV2Controller expone los pedidos como recurso REST con parámetros de ruta
+type PedidoV2Controller struct {
+	web.Controller
+}
+
+// @Title GetAll
+// @Summary Listar pedidos (v2)
+// @Description Devuelve los pedidos filtrados por fecha, rango, mes, cliente, método de pago o domicilio. A un cliente solo se le listan sus propios pedidos. Una lista vacía responde 200.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param fecha query string false "Fecha específica en formato YYYY-MM-DD"
+// @Param desde query string false "Fecha inicial del rango en formato YYYY-MM-DD"
+// @Param hasta query string false "Fecha final del rango en formato YYYY-MM-DD"
+// @Param mes query int false "Mes del año (1-12)"
+// @Param anio query int false "Año para el filtro de mes"
+// @Param cliente query int false "ID del cliente (PK_DOCUMENTO_CLIENTE)"
+// @Param metodo_pago query string false "Tipo de método de pago (NEQUI, DAVIPLATA, EFECTIVO)"
+// @Param domicilio query bool false "Indica si el pedido tiene domicilio (true/false)"
+// @Success 200 {object} models.ApiResponse "Pedidos obtenidos exitosamente"
+// @Failure 500 {object} models.ApiResponse "Error al obtener los pedidos"
+// @Security BearerAuth
+// @Router /v2/pedidos [get]
+func (c *PedidoV2Controller) GetAll() {
+	mes, _ := c.GetInt("mes")
+	anio, _ := c.GetInt("anio")
+	cliente, _ := c.GetInt("cliente")
+	filtros := services.PedidoFiltros{
+		Fecha:      c.GetString("fecha"),
+		Desde:      c.GetString("desde"),
+		Hasta:      c.GetString("hasta"),
+		Mes:        mes,
+		Anio:       anio,
+		Cliente:    cliente,
+		MetodoPago: c.GetString("metodo_pago"),
+	}
+	if domicilio, err := c.GetBool("domicilio"); err == nil {
+		filtros.Domicilio = &domicilio
+	}
+
+	pedidos, err := services.NewPedidoService(newStore()).List(filtros, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	if pedidos == nil {
+		pedidos = []models.Pedido{}
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Pedidos obtenidos exitosamente", pedidos)
+}
+
+// @Title Post
+// @Summary Crear un pedido (v2)
+// @Description Crea un pedido en estado INICIADO a partir de un cuerpo JSON y devuelve su ubicación.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param body body models.Pedido true "Datos del pedido"
+// @Success 201 {object} models.ApiResponse "Pedido creado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 500 {object} models.ApiResponse "Error al crear el pedido"
+// @Security BearerAuth
+// @Router /v2/pedidos [post]
+func (c *PedidoV2Controller) Post() {
+	var pedido models.Pedido
+	if err := parseJSONBody(&c.Controller, &pedido); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	if err := services.NewPedidoService(newStore()).Create(&pedido, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.Header("Location", fmt.Sprintf("/restaurante/v2/pedidos/%d", pedido.PK_ID_PEDIDO))
+	serveData(&c.Controller, http.StatusCreated, "Pedido creado exitosamente", pedido)
+}
+
+// @Title Get
+// @Summary Obtener un pedido (v2)
+// @Description Devuelve el pedido con su método de pago, los productos asociados y el historial de cambios de estado.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Success 200 {object} models.ApiResponse "Detalles del pedido obtenidos exitosamente"
+// @Failure 400 {object} models.ApiResponse "ID inválido"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id} [get]
+func (c *PedidoV2Controller) Get() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	details, err := services.NewPedidoService(newStore()).GetDetails(id, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, details)
+	serveData(&c.Controller, http.StatusOK, "Detalles del pedido obtenidos exitosamente", details)
+}
+
+// @Title Put
+// @Summary Actualizar un pedido (v2)
+// @Description Actualiza la hora, el tipo de entrega, el restaurante o el responsable de un pedido que no ha terminado. Si el pedido ya tiene pagos sus totales no cambian y el tipo de entrega tampoco. Requiere el ETag de la última consulta en If-Match.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Param If-Match header string true "ETag obtenido al consultar el pedido"
+// @Param body body models.Pedido true "Campos del pedido a actualizar"
+// @Success 200 {object} models.ApiResponse "Pedido actualizado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido cambió durante la actualización, ya terminó o tiene pagos y cambia el tipo de entrega"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id} [put]
+func (c *PedidoV2Controller) Put() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	pedido, err := updatePedido(&c.Controller, int(id))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Pedido actualizado correctamente", pedido)
+}
+
+// @Title PutPago
+// @Summary Asignar el pago de un pedido (v2)
+// @Description Asocia un pago existente al pedido y lo marca como PAGADO. Con la cuenta dividida el pedido tiene varios pagos y pasa a PAGADO cuando lo cobrado cubre el total, solo si está INICIADO. Un pedido sin productos no se cobra y un cliente solo puede cobrar los pagos a su nombre. Requiere el ETag de la última consulta en If-Match.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Param If-Match header string true "ETag obtenido al consultar el pedido"
+// @Param body body object true "Cuerpo con PK_ID_PAGO"
+// @Success 200 {object} models.ApiResponse "Pago asignado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "El pago no está a nombre del cliente"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido está cancelado o no tiene productos, o el pago es de otro pedido o ya se reembolsó"
+// @Failure 422 {object} models.ApiResponse "El pago indicado no existe"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/pago [put]
+func (c *PedidoV2Controller) PutPago() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input struct {
+		PK_ID_PAGO int `json:"PK_ID_PAGO"`
+	}
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	if input.PK_ID_PAGO <= 0 {
+		serveError(&c.Controller, badRequestError("El campo PK_ID_PAGO es obligatorio", nil))
+		return
+	}
+
+	pedido, err := pedidoConIfMatch(&c.Controller, int(id), func(tx repositories.Store) (*models.Pedido, error) {
+		return services.NewPedidoService(tx).AssignPago(int(id), input.PK_ID_PAGO, currentActor(&c.Controller))
+	})
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Pago asignado correctamente", pedido)
+}
+
+// @Title GetCuenta
+// @Summary Estado de cuenta de un pedido (v2)
+// @Description Devuelve el total del pedido, lo cobrado, los pagos pendientes, el saldo por cobrar y los pagos con el cliente que pagó cada uno. Un cliente solo ve la cuenta de sus propios pedidos.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Success 200 {object} models.ApiResponse "Cuenta del pedido"
+// @Failure 400 {object} models.ApiResponse "ID inválido"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/cuenta [get]
+func (c *PedidoV2Controller) GetCuenta() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	cuenta, err := services.NewCuentaService(newStore()).Cuenta(int(id), currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Cuenta del pedido obtenida exitosamente", cuenta)
+}
+
+// @Title PostDivision
+// @Summary Dividir la cuenta de un pedido (v2)
+// @Description Reparte el saldo del pedido en un pago PENDIENTE por cada parte: en partes IGUALES, POR_PRODUCTO (cada parte paga la proporción de sus líneas, con impuestos y propina) o por MONTO. Cada parte puede indicar el cliente que la paga; solo el personal puede indicar clientes que aún no están en el pedido. Los pagos se cobran con PUT /pago y el pedido pasa a PAGADO cuando quedan cubiertos.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Param body body models.DivisionCuenta true "Modo y partes de la división"
+// @Success 201 {object} models.ApiResponse "Cuenta dividida"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos o líneas sin asignar"
+// @Failure 403 {object} models.ApiResponse "Un cliente indicó a otro cliente que no está en el pedido"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido está cancelado, ya está pagado o tiene pagos pendientes"
+// @Failure 422 {object} models.ApiResponse "El cliente, el método de pago o una línea no existen"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/division [post]
+func (c *PedidoV2Controller) PostDivision() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var division models.DivisionCuenta
+	if err := parseJSONBody(&c.Controller, &division); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	cuenta, err := services.NewCuentaService(newStore()).Dividir(int(id), &division, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusCreated, "Cuenta del pedido dividida exitosamente", cuenta)
+}
+
+// @Title PutDomicilio
+// @Summary Asignar el domicilio de un pedido (v2)
+// @Description Asocia un domicilio existente al pedido. Si el pedido está LISTO pasa a EN CAMINO, lo que solo puede hacer el personal. Un cliente solo asigna el domicilio de sus propios pedidos. Requiere el ETag de la última consulta en If-Match.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Param If-Match header string true "ETag obtenido al consultar el pedido"
+// @Param body body object true "Cuerpo con PK_ID_DOMICILIO"
+// @Success 200 {object} models.ApiResponse "Domicilio asignado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Un cliente no puede despachar el pedido"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido ya fue entregado o cancelado"
+// @Failure 422 {object} models.ApiResponse "El domicilio indicado no existe"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/domicilio [put]
+func (c *PedidoV2Controller) PutDomicilio() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input struct {
+		PK_ID_DOMICILIO int `json:"PK_ID_DOMICILIO"`
+	}
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	if input.PK_ID_DOMICILIO <= 0 {
+		serveError(&c.Controller, badRequestError("El campo PK_ID_DOMICILIO es obligatorio", nil))
+		return
+	}
+
+	pedido, err := pedidoConIfMatch(&c.Controller, int(id), func(tx repositories.Store) (*models.Pedido, error) {
+		return services.NewPedidoService(tx).AssignDomicilio(int(id), input.PK_ID_DOMICILIO, currentActor(&c.Controller))
+	})
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Domicilio asignado correctamente", pedido)
+}
+
+// @Title PutEstado
+// @Summary Cambiar el estado de un pedido (v2)
+// @Description Mueve el pedido al estado enviado en el cuerpo siguiendo su ciclo de vida (INICIADO, PAGADO, EN PREPARACION, LISTO, EN CAMINO, ENTREGADO, CANCELADO) y lo registra en el historial. Para cancelar con un motivo use /cancelacion. Requiere el ETag de la última consulta en If-Match.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Param If-Match header string true "ETag obtenido al consultar el pedido"
+// @Param body body object true "Cuerpo con ESTADO_PEDIDO"
+// @Success 200 {object} models.ApiResponse "Estado actualizado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos o estado desconocido"
+// @Failure 403 {object} models.ApiResponse "Un cliente solo puede cancelar el pedido"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "Transición de estado no permitida"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/estado [put]
+func (c *PedidoV2Controller) PutEstado() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input struct {
+		ESTADO_PEDIDO string `json:"ESTADO_PEDIDO"`
+	}
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	pedido, err := pedidoConIfMatch(&c.Controller, int(id), func(tx repositories.Store) (*models.Pedido, error) {
+		return services.NewPedidoService(tx).UpdateEstado(int(id), input.ESTADO_PEDIDO, currentActor(&c.Controller))
+	})
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Estado del pedido actualizado correctamente", pedido)
+}
+
+// @Title PutPropina
+// @Summary Aceptar o rechazar la propina sugerida (v2)
+// @Description Registra si el cliente acepta la propina sugerida (porcentaje propina_sugerida del valor sin el impuesto incluido en los precios) y recalcula el total. Solo aplica a pedidos sin domicilio. Requiere el ETag de la última consulta en If-Match.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Param If-Match header string true "ETag obtenido al consultar el pedido"
+// @Param body body object true "Cuerpo con PROPINA_ACEPTADA"
+// @Success 200 {object} models.ApiResponse "Propina actualizada"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido tiene domicilio o ya terminó"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/propina [put]
+func (c *PedidoV2Controller) PutPropina() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input struct {
+		PROPINA_ACEPTADA *bool `json:"PROPINA_ACEPTADA"`
+	}
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	if input.PROPINA_ACEPTADA == nil {
+		serveError(&c.Controller, badRequestError("El campo PROPINA_ACEPTADA es obligatorio", nil))
+		return
+	}
+
+	pedido, err := pedidoConIfMatch(&c.Controller, int(id), func(tx repositories.Store) (*models.Pedido, error) {
+		return services.NewPedidoService(tx).UpdatePropina(int(id), *input.PROPINA_ACEPTADA, currentActor(&c.Controller))
+	})
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Propina del pedido actualizada correctamente", pedido)
+}
+
+// @Title PutPromocion
+// @Summary Registrar un código de promoción (v2)
+// @Description Registra el código de promoción del pedido y recalcula sus totales junto con las promociones automáticas. Un CODIGO_PROMOCION vacío lo quita. Requiere el ETag de la última consulta en If-Match.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Param If-Match header string true "ETag obtenido al consultar el pedido"
+// @Param body body object true "Cuerpo con CODIGO_PROMOCION"
+// @Success 200 {object} models.ApiResponse "Código de promoción registrado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido ya terminó"
+// @Failure 422 {object} models.ApiResponse "El código no existe o no aplica al pedido"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/promocion [put]
+func (c *PedidoV2Controller) PutPromocion() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input struct {
+		CODIGO_PROMOCION *string `json:"CODIGO_PROMOCION"`
+	}
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	if input.CODIGO_PROMOCION == nil {
+		serveError(&c.Controller, badRequestError("El campo CODIGO_PROMOCION es obligatorio", nil))
+		return
+	}
+
+	pedido, err := pedidoConIfMatch(&c.Controller, int(id), func(tx repositories.Store) (*models.Pedido, error) {
+		return services.NewPedidoService(tx).UpdatePromocion(int(id), *input.CODIGO_PROMOCION, currentActor(&c.Controller))
+	})
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Código de promoción del pedido actualizado correctamente", pedido)
+}
+
+// @Title PutMesa
+// @Summary Asignar una mesa a un pedido (v2)
+// @Description Pasa el pedido a la mesa indicada, que queda OCUPADA. Si el pedido no tenía mesero queda a nombre de quien lo asigna. Requiere el ETag de la última consulta en If-Match.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Param If-Match header string true "ETag obtenido al consultar el pedido"
+// @Param body body object true "Cuerpo con PK_ID_MESA"
+// @Success 200 {object} models.ApiResponse "Mesa asignada"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido ya terminó, es a domicilio o la mesa está por limpiar"
+// @Failure 422 {object} models.ApiResponse "La mesa no existe"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/mesa [put]
+func (c *PedidoV2Controller) PutMesa() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input struct {
+		PK_ID_MESA int64 `json:"PK_ID_MESA"`
+	}
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	pedido, err := pedidoConIfMatch(&c.Controller, int(id), func(tx repositories.Store) (*models.Pedido, error) {
+		return services.NewPedidoService(tx).AssignMesa(int(id), input.PK_ID_MESA, currentActor(&c.Controller))
+	})
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Mesa del pedido actualizada correctamente", pedido)
+}
+
+// @Title PutProgramacion
+// @Summary Programar un pedido (v2)
+// @Description Fija la hora (DD-MM-YYYY HH:mm:ss) a la que el cliente quiere recibir o recoger el pedido. Debe quedar después de programados_anticipacion_minutos, dentro de programados_dias_maximos y en el horario del restaurante o en el cambio de horario de ese día. El pedido espera fuera de la cocina hasta esa anticipación antes de su hora, cuando entra solo a EN PREPARACION. Un PROGRAMADO_PARA vacío lo vuelve un pedido inmediato. Requiere el ETag de la última consulta en If-Match.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Param If-Match header string true "ETag obtenido al consultar el pedido"
+// @Param body body object true "Cuerpo con PROGRAMADO_PARA"
+// @Success 200 {object} models.ApiResponse "Pedido programado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "El pedido es de otro cliente"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido ya entró a la cocina"
+// @Failure 422 {object} models.ApiResponse "La hora está muy cerca, muy lejos o fuera del horario del restaurante"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/programacion [put]
+func (c *PedidoV2Controller) PutProgramacion() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input struct {
+		PROGRAMADO_PARA *string `json:"PROGRAMADO_PARA"`
+	}
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	if input.PROGRAMADO_PARA == nil {
+		serveError(&c.Controller, badRequestError("El campo PROGRAMADO_PARA es obligatorio", nil))
+		return
+	}
+
+	pedido, err := pedidoConIfMatch(&c.Controller, int(id), func(tx repositories.Store) (*models.Pedido, error) {
+		return services.NewPedidoService(tx).Programar(int(id), *input.PROGRAMADO_PARA, currentActor(&c.Controller))
+	})
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	setETag(&c.Controller, pedido)
+	serveData(&c.Controller, http.StatusOK, "Programación del pedido actualizada correctamente", pedido)
+}
+
+// @Title PostRepetir
+// @Summary Repetir un pedido anterior (v2)
+// @Description Crea un pedido nuevo con los productos de uno anterior, para el mismo cliente y, si era a domicilio, a la misma dirección. Los productos se cotizan con el precio vigente; los que ya no existen, no están disponibles, perdieron alguna de sus opciones o no tienen unidades suficientes quedan por fuera y se listan en NO_DISPONIBLES. Un cliente solo puede repetir sus propios pedidos.
+// @Tags v2 pedidos
+// @Produce json
+// @Param id path int true "ID del pedido a repetir"
+// @Success 201 {object} models.PedidoRepetido "Pedido creado"
+// @Failure 403 {object} models.ApiResponse "El pedido es de otro cliente"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 422 {object} models.ApiResponse "Ninguno de los productos está disponible"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/repetir [post]
+func (c *PedidoV2Controller) PostRepetir() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	repetido, err := services.NewRecompraService(newStore()).Repetir(int(id), currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	c.Ctx.Output.Header("Location", fmt.Sprintf("/restaurante/v2/pedidos/%d", repetido.PEDIDO.PK_ID_PEDIDO))
+	serveData(&c.Controller, http.StatusCreated, "Pedido repetido exitosamente", repetido)
+}
+
+// @Title GetProgramados
+// @Summary Listar los pedidos programados (v2)
+// @Description Devuelve los pedidos programados que todavía esperan para entrar a la cocina, del más próximo al más lejano. Solo para el personal del restaurante.
+// @Tags v2 pedidos
+// @Produce json
+// @Success 200 {object} models.ApiResponse "Pedidos programados"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden ver los pedidos programados"
+// @Security BearerAuth
+// @Router /v2/pedidos/programados [get]
+func (c *PedidoV2Controller) GetProgramados() {
+	pedidos, err := services.NewPedidoService(newStore()).Programados(currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Pedidos programados obtenidos exitosamente", pedidos)
+}
+
+// @Title PostCancelacion
+// @Summary Cancelar un pedido (v2)
+// @Description Cancela el pedido con un motivo: si ya estaba confirmado sus productos vuelven al inventario (salvo que ya se hubiera entregado), cada pago cobrado queda con un reembolso PENDIENTE por el mismo método y se registra quién lo canceló. El administrador puede cancelar en cualquier estado, el resto del personal mientras el ciclo de vida lo permita y los clientes solo sus pedidos, antes de que entren a cocina y dentro del plazo cancelacion_cliente_minutos.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del pedido"
+// @Param body body models.CancelacionRequest true "Motivo (CLIENTE_DESISTE, DEMORA, SIN_PRODUCTO, ERROR_PEDIDO, PAGO_RECHAZADO, OTRO) y detalle"
+// @Success 201 {object} models.ApiResponse "Pedido cancelado"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos o motivo desconocido"
+// @Failure 403 {object} models.ApiResponse "El cliente no puede cancelar este pedido"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido ya está cancelado o no se puede cancelar"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/cancelacion [post]
+func (c *PedidoV2Controller) PostCancelacion() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var input models.CancelacionRequest
+	if err := parseJSONBody(&c.Controller, &input); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	cancelacion, err := services.NewCancelacionService(newStore()).Cancelar(int(id), input.MOTIVO, input.DETALLE, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusCreated, "Pedido cancelado correctamente", cancelacion)
+}
+
+// @Title GetRecibo
+// @Summary Recibo de un pedido (v2)
+// @Description Devuelve los productos del pedido, el desglose de impuestos por categoría, la propina sugerida y si fue aceptada, el domicilio y el total. Con 'formato' devuelve el tiquete listo para imprimir: texto plano, PDF o los bytes ESC/POS de una impresora térmica. Un cliente solo ve el recibo de sus propios pedidos.
+// @Tags v2 pedidos
+// @Accept json
+// @Produce json,plain,application/pdf,application/octet-stream
+// @Param id path int true "ID del pedido"
+// @Param formato query string false "Formato del tiquete; JSON si se omite" Enums(texto, pdf, escpos)
+// @Param ancho query int false "Ancho del papel en mm (por defecto 80)" Enums(58, 80)
+// @Success 200 {object} models.ApiResponse "Recibo del pedido"
+// @Failure 400 {object} models.ApiResponse "ID, formato o ancho inválido"
+// @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Security BearerAuth
+// @Router /v2/pedidos/{id}/recibo [get]
+func (c *PedidoV2Controller) GetRecibo() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	if formato := c.GetString("formato"); formato != "" {
+		ancho, err := anchoDocumento(&c.Controller)
+		if err != nil {
+			serveError(&c.Controller, err)
+			return
+		}
+		documento, err := services.NewPedidoService(newStore()).Recibo(int(id), ancho, currentActor(&c.Controller))
+		if err != nil {
+			serveError(&c.Controller, err)
+			return
+		}
+		serveDocumento(&c.Controller, documento, formato, fmt.Sprintf("recibo-%d", id))
+		return
+	}
+
+	recibo, err := services.NewPedidoService(newStore()).GetRecibo(int(id), currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Recibo del pedido obtenido exitosamente", recibo)
+}
@@ -6,9 +6,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"restaurante/models"
+	"restaurante/services"
 	"strconv"
 
-	"github.com/beego/beego/v2/client/orm"
 	"github.com/beego/beego/v2/server/web"
 )
 
@@ -26,31 +26,15 @@ type ProductoController struct {
 // @Param   onlyActive    query    bool   false  "Filtrar solo productos disponibles (true o false, por defecto es false)"
 // @Success 200 {array} models.Producto "Lista de productos"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
-// @Router /productos [get]
+// @Router /v1/productos [get]
 func (c *ProductoController) GetAll() {
-	o := orm.NewOrm()
-	var productos []models.Producto
-
 	// Obtener valores de los parámetros
 	includeImage, _ := c.GetBool("includeImage", false)
 	onlyActive, _ := c.GetBool("onlyActive", false)
 
-	// Construir la consulta con filtros
-	query := o.QueryTable(new(models.Producto))
-	if onlyActive {
-		query = query.Filter("ESTADO_PRODUCTO", "DISPONIBLE")
-	}
-
-	// Ejecutar la consulta
-	_, err := query.All(&productos)
+	productos, err := services.NewProductoService(newStore()).List(onlyActive)
 	if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al obtener productos de la base de datos",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, err)
 		return
 	}
 
@@ -81,9 +65,8 @@ func (c *ProductoController) GetAll() {
 // @Param   id     query    int     true        "ID del Producto"
 // @Success 200 {object} models.Producto "Producto encontrado"
 // @Failure 404 {object} models.ApiResponse "Producto no encontrado"
-// @Router /productos/search [get]
+// @Router /v1/productos/search [get]
 func (c *ProductoController) GetById() {
-	o := orm.NewOrm()
 	id, err := c.GetInt("id")
 
 	if err != nil || id == 0 {
@@ -97,17 +80,13 @@ func (c *ProductoController) GetById() {
 		return
 	}
 
-	producto, err := getProductoByID(int64(id), o)
+	producto, err := services.NewProductoService(newStore()).GetByID(int64(id))
 	if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: err.Error(),
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, err)
 		return
 	}
 
+	setETag(&c.Controller, producto)
 	c.Ctx.Output.SetStatus(http.StatusOK)
 	c.Data["json"] = models.ApiResponse{
 		Code:    http.StatusOK,
@@ -130,11 +109,14 @@ func (c *ProductoController) GetById() {
 // @Param   PRECIO        formData  int     true   "Precio del producto"
 // @Param   IMAGEN        formData  file    false  "Imagen del producto (opcional)"
 // @Param   CANTIDAD        formData  int     false   "Cantidad del producto"
+// @Param   CONTROLA_STOCK formData bool false "Si los pedidos descuentan CANTIDAD; por defecto false"
+// @Param   CATEGORIA_IMPUESTO formData string false "Categoría de impuesto (IMPOCONSUMO, IVA, EXENTO); por defecto EXENTO; el PRECIO incluye el impuesto"
+// @Param   ESTACION      formData  string  false  "Estación de la cocina que lo prepara (PARRILLA, FRITOS, BEBIDAS...); por defecto COCINA"
+// @Param   TIEMPO_PREPARACION formData int false "Minutos de preparación; por defecto el tiempo del restaurante"
 // @Success 201 {object} models.Producto "Producto creado"
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
-// @Router /productos [post]
+// @Router /v1/productos [post]
 func (c *ProductoController) Post() {
-	o := orm.NewOrm()
 	var producto models.Producto
 
 	// Validar campos obligatorios
@@ -145,6 +127,10 @@ func (c *ProductoController) Post() {
 	producto.DESCRIPCION = c.GetString("DESCRIPCION")
 	producto.PRECIO, _ = c.GetInt64("PRECIO")
 	producto.CANTIDAD, _ = c.GetInt("CANTIDAD")
+	producto.CONTROLA_STOCK, _ = c.GetBool("CONTROLA_STOCK")
+	producto.CATEGORIA_IMPUESTO = c.GetString("CATEGORIA_IMPUESTO")
+	producto.ESTACION = c.GetString("ESTACION")
+	producto.TIEMPO_PREPARACION, _ = c.GetInt("TIEMPO_PREPARACION")
 
 	if err := validateProducto(&producto); err != nil {
 		c.Ctx.Output.SetStatus(http.StatusBadRequest)
@@ -170,15 +156,8 @@ func (c *ProductoController) Post() {
 	producto.IMAGEN = imagen
 
 	// Insertar en la base de datos
-	_, err = o.Insert(&producto)
-	if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al crear el producto",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	if err := services.NewProductoService(newStore()).Create(&producto); err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
@@ -205,12 +184,18 @@ func (c *ProductoController) Post() {
 // @Param   PRECIO        formData  int     true   "Precio del producto"
 // @Param   IMAGEN        formData  file    false  "Imagen del producto (opcional)"
 // @Param   CANTIDAD        formData  int     false   "Cantidad del producto"
+// @Param   CONTROLA_STOCK formData bool false "Si los pedidos descuentan CANTIDAD; si se omite conserva el actual"
+// @Param   CATEGORIA_IMPUESTO formData string false "Categoría de impuesto (IMPOCONSUMO, IVA, EXENTO); si se omite conserva la actual"
+// @Param   ESTACION      formData  string  false  "Estación de la cocina que lo prepara; si se omite conserva la actual"
+// @Param   TIEMPO_PREPARACION formData int false "Minutos de preparación; si se omite conserva el actual"
+// @Param   If-Match      header    string  true   "ETag obtenido al consultar el producto"
 // @Success 200 {object} models.Producto "Producto actualizado"
 // @Failure 404 {object} models.ApiResponse "Producto no encontrado"
-// @Router /productos [put]
+// @Failure 409 {object} models.ApiResponse "El producto cambió durante la actualización"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
+// @Router /v1/productos [put]
 func (c *ProductoController) Put() {
-	o := orm.NewOrm()
-
 	// Obtener el ID del query parameter
 	idStr := c.GetString("id")
 	id, err := strconv.Atoi(idStr)
@@ -225,86 +210,95 @@ func (c *ProductoController) Put() {
 		return
 	}
 
-	producto := models.Producto{PK_ID_PRODUCTO: int64(id)}
-
-	if o.Read(&producto) == nil {
-		// Copiar los valores actuales para comparación
-		original := producto
-
-		// Actualizar los campos
-		producto.NOMBRE = c.GetString("NOMBRE")
-		calorias, _ := c.GetInt64("CALORIAS")
-		producto.CALORIAS = &calorias
-		producto.DESCRIPCION = c.GetString("DESCRIPCION")
-		producto.PRECIO, _ = c.GetInt64("PRECIO")
-		producto.ESTADO_PRODUCTO = c.GetString("ESTADO_PRODUCTO")
-		producto.CANTIDAD, _ = c.GetInt("CANTIDAD")
-
-		// Validar datos
-		if err := validateProducto(&producto); err != nil {
-			c.Ctx.Output.SetStatus(http.StatusBadRequest)
-			c.Data["json"] = models.ApiResponse{
-				Code:    http.StatusBadRequest,
-				Message: err.Error(),
-			}
-			c.ServeJSON()
-			return
-		}
+	service := services.NewProductoService(newStore())
+	producto, err := service.GetByID(int64(id))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
 
-		// Manejar imagen opcional
-		imagen, err := handleImageUpload(&c.Controller)
-		if err != nil {
-			c.Ctx.Output.SetStatus(http.StatusBadRequest)
-			c.Data["json"] = models.ApiResponse{
-				Code:    http.StatusBadRequest,
-				Message: err.Error(),
-			}
-			c.ServeJSON()
-			return
-		}
-		if imagen != "" {
-			producto.IMAGEN = imagen
-		}
+	// Copiar los valores actuales para comparación
+	original := *producto
 
-		// Verificar si hubo cambios
-		if producto == original {
-			c.Ctx.Output.SetStatus(http.StatusNotModified)
-			c.Data["json"] = models.ApiResponse{
-				Code:    http.StatusNotModified,
-				Message: "No se realizaron cambios en el producto",
-			}
-			c.ServeJSON()
-			return
-		}
+	// Verificar que se está editando la última versión del producto
+	version, err := checkIfMatch(&c.Controller, &original)
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	// Actualizar los campos
+	producto.NOMBRE = c.GetString("NOMBRE")
+	calorias, _ := c.GetInt64("CALORIAS")
+	producto.CALORIAS = &calorias
+	producto.DESCRIPCION = c.GetString("DESCRIPCION")
+	producto.PRECIO, _ = c.GetInt64("PRECIO")
+	producto.ESTADO_PRODUCTO = c.GetString("ESTADO_PRODUCTO")
+	producto.CANTIDAD, _ = c.GetInt("CANTIDAD")
+	if c.GetString("CONTROLA_STOCK") != "" {
+		producto.CONTROLA_STOCK, _ = c.GetBool("CONTROLA_STOCK")
+	}
+	if categoria := c.GetString("CATEGORIA_IMPUESTO"); categoria != "" {
+		producto.CATEGORIA_IMPUESTO = categoria
+	}
+	if estacion := c.GetString("ESTACION"); estacion != "" {
+		producto.ESTACION = estacion
+	}
+	if c.GetString("TIEMPO_PREPARACION") != "" {
+		producto.TIEMPO_PREPARACION, _ = c.GetInt("TIEMPO_PREPARACION")
+	}
 
-		// Actualizar en base de datos
-		if _, err = o.Update(&producto); err != nil {
-			c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-			c.Data["json"] = models.ApiResponse{
-				Code:    http.StatusInternalServerError,
-				Message: "Error al actualizar el producto.",
-				Cause:   err.Error(),
-			}
-			c.ServeJSON()
-			return
+	// Validar datos
+	if err := validateProducto(producto); err != nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = models.ApiResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
 		}
+		c.ServeJSON()
+		return
+	}
 
-		c.Ctx.Output.SetStatus(http.StatusOK)
+	// Manejar imagen opcional
+	imagen, err := handleImageUpload(&c.Controller)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
 		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusOK,
-			Message: "Producto actualizado",
-			Data:    producto,
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
 		}
 		c.ServeJSON()
-	} else {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
+		return
+	}
+	if imagen != "" {
+		producto.IMAGEN = imagen
+	}
+
+	// Verificar si hubo cambios
+	if *producto == original {
+		c.Ctx.Output.SetStatus(http.StatusNotModified)
 		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Producto no encontrado.",
+			Code:    http.StatusNotModified,
+			Message: "No se realizaron cambios en el producto",
 		}
 		c.ServeJSON()
+		return
 	}
 
+	// Actualizar en base de datos
+	if err := service.Update(producto, version); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	setETag(&c.Controller, producto)
+
+	c.Ctx.Output.SetStatus(http.StatusOK)
+	c.Data["json"] = models.ApiResponse{
+		Code:    http.StatusOK,
+		Message: "Producto actualizado",
+		Data:    producto,
+	}
+	c.ServeJSON()
 }
 
 // @Title Delete
@@ -317,10 +311,8 @@ func (c *ProductoController) Put() {
 // @Success 200 {object} models.ApiResponse "Producto desactivado"
 // @Failure 404 {object} models.ApiResponse "Producto no encontrado"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
-// @Router /productos [delete]
+// @Router /v1/productos [delete]
 func (c *ProductoController) Delete() {
-	o := orm.NewOrm()
-
 	// Obtener el ID del query parameter
 	idStr := c.GetString("id")
 	id, err := strconv.Atoi(idStr)
@@ -335,36 +327,9 @@ func (c *ProductoController) Delete() {
 		return
 	}
 
-	// Buscar el producto
-	producto, err := getProductoByID(int64(id), o)
-	if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: err.Error(),
-		}
-		c.ServeJSON()
-		return
-	}
-	if producto.ESTADO_PRODUCTO == "NO DISPONIBLE" {
-		c.Ctx.Output.SetStatus(http.StatusBadRequest)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusBadRequest,
-			Message: "El producto ya está desactivado.",
-		}
-		c.ServeJSON()
-		return
-	}
 	// Cambiar el estado del producto a "NO DISPONIBLE" para el borrado lógico
-	producto.ESTADO_PRODUCTO = "NO DISPONIBLE"
-	if _, err := o.Update(producto, "ESTADO_PRODUCTO"); err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al desactivar el producto.",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	if _, err := services.NewProductoService(newStore()).Deactivate(int64(id)); err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
@@ -405,6 +370,12 @@ func validateProducto(producto *models.Producto) error {
 	if producto.PRECIO <= 0 {
 		return fmt.Errorf("el campo 'PRECIO' debe ser un número mayor a 0")
 	}
+	if producto.CANTIDAD < 0 {
+		return fmt.Errorf("el campo 'CANTIDAD' no puede ser negativo")
+	}
+	if producto.TIEMPO_PREPARACION < 0 {
+		return fmt.Errorf("el campo 'TIEMPO_PREPARACION' no puede ser negativo")
+	}
 	if producto.CALORIAS != nil && *producto.CALORIAS < 0 {
 		return fmt.Errorf("el campo 'CALORIAS' debe ser un número positivo")
 	}
@@ -413,14 +384,3 @@ func validateProducto(producto *models.Producto) error {
 	}
 	return nil
 }
-
-func getProductoByID(id int64, o orm.Ormer) (*models.Producto, error) {
-	producto := &models.Producto{PK_ID_PRODUCTO: id}
-	if err := o.Read(producto); err != nil {
-		if err == orm.ErrNoRows {
-			return nil, fmt.Errorf("producto no encontrado")
-		}
-		return nil, fmt.Errorf("error al buscar el producto: %v", err)
-	}
-	return producto, nil
-}
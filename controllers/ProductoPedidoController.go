@@ -4,8 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"restaurante/models"
+	"restaurante/services"
 
-	"github.com/beego/beego/v2/client/orm"
 	"github.com/beego/beego/v2/server/web"
 )
 
@@ -15,244 +15,100 @@ type ProductoPedidoController struct {
 
 // @Title GetAll
 // @Summary Obtener los productos de un pedido
-// @Description Devuelve los productos consolidados en un pedido específico
+// @Description Devuelve las líneas de un pedido (producto, nombre, cantidad, precio unitario, subtotal y notas).
 // @Tags producto_pedido
 // @Accept json
 // @Produce json
 // @Param pedido_id query int true "ID del pedido"
 // @Success 200 {object} models.ApiResponse "Lista de productos del pedido"
+// @Failure 400 {object} models.ApiResponse "El parámetro 'pedido_id' es obligatorio"
 // @Failure 404 {object} models.ApiResponse "No se encontraron productos asociados a este pedido"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /producto_pedido [get]
+// @Router /v1/producto_pedido [get]
 func (c *ProductoPedidoController) GetAll() {
 	pedidoID, err := c.GetInt64("pedido_id")
 	if err != nil || pedidoID == 0 {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusBadRequest,
-			Message: "El parámetro 'pedido_id' es obligatorio y debe ser válido",
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, badRequestError("El parámetro 'pedido_id' es obligatorio y debe ser válido", err))
 		return
 	}
 
-	o := orm.NewOrm()
-	var productoPedido models.ProductoPedido
-
-	err = o.QueryTable(new(models.ProductoPedido)).
-		Filter("PK_ID_PEDIDO", pedidoID).
-		One(&productoPedido)
-
-	if err == orm.ErrNoRows {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "No se encontraron productos asociados a este pedido",
-		}
-		c.ServeJSON()
-		return
-	} else if err != nil {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al obtener los productos del pedido",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
-		return
-	}
-
-	// Convertir el JSONB a un formato de salida legible
-	var detalles []map[string]interface{}
-	if err := json.Unmarshal([]byte(productoPedido.DETALLES_PRODUCTOS), &detalles); err != nil {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al procesar los detalles del pedido",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	lineas, err := services.NewProductoPedidoService(newStore()).List(pedidoID, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
-	c.Data["json"] = models.ApiResponse{
-		Code:    http.StatusOK,
-		Message: "Productos del pedido obtenidos exitosamente",
-		Data:    detalles,
-	}
-	c.ServeJSON()
+	serveData(&c.Controller, http.StatusOK, "Productos del pedido obtenidos exitosamente", lineas)
 }
 
 // @Title Create
-// @Summary Crear un pedido con productos consolidados
-// @Description Crea un registro de productos consolidados en un pedido
+// @Summary Agregar productos a un pedido
+// @Description Agrega productos a un pedido. Cada línea toma el nombre y el precio vigentes del producto (los precios enviados se ignoran) y el pedido recalcula sus totales.
 // @Tags producto_pedido
 // @Accept json
 // @Produce json
-// @Param body body models.ProductoPedido true "Datos del pedido con productos"
+// @Param body body models.ProductoPedidoRequest true "Pedido y productos con su cantidad"
 // @Success 201 {object} models.ApiResponse "Pedido con productos agregado exitosamente"
 // @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 404 {object} models.ApiResponse "Pedido de otro cliente"
+// @Failure 409 {object} models.ApiResponse "El pedido ya se pagó, salió de la cocina o terminó, o no hay stock suficiente"
+// @Failure 422 {object} models.ApiResponse "El pedido o algún producto no existe o no está disponible"
 // @Failure 500 {object} models.ApiResponse "Error interno del servidor"
 // @Security BearerAuth
-// @Router /producto_pedido [post]
+// @Router /v1/producto_pedido [post]
 func (c *ProductoPedidoController) Create() {
-	var input struct {
-		PK_ID_PEDIDO       int64                    `json:"PK_ID_PEDIDO"`
-		DETALLES_PRODUCTOS []map[string]interface{} `json:"DETALLES_PRODUCTOS"`
-	}
+	var input models.ProductoPedidoRequest
 
 	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &input); err != nil {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusBadRequest,
-			Message: "Datos inválidos",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
-		return
-	}
-
-	// Validar que se proporcione el pedido y los detalles
-	if input.PK_ID_PEDIDO == 0 || len(input.DETALLES_PRODUCTOS) == 0 {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusBadRequest,
-			Message: "El pedido y los detalles de los productos son obligatorios",
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, badRequestError("Datos inválidos", err))
 		return
 	}
 
-	// Convertir los detalles a JSON
-	detallesJSON, err := json.Marshal(input.DETALLES_PRODUCTOS)
+	productoPedido, err := services.NewProductoPedidoService(newStore()).Create(input.PK_ID_PEDIDO, input.DETALLES_PRODUCTOS, currentActor(&c.Controller))
 	if err != nil {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al procesar los detalles del pedido",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, err)
 		return
 	}
 
-	productoPedido := models.ProductoPedido{
-		PK_ID_PEDIDO:       input.PK_ID_PEDIDO,
-		DETALLES_PRODUCTOS: string(detallesJSON),
-	}
-
-	o := orm.NewOrm()
-	_, err = o.Insert(&productoPedido)
-	if err != nil {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al crear el pedido con productos",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
-		return
-	}
-
-	c.Data["json"] = models.ApiResponse{
-		Code:    http.StatusCreated,
-		Message: "Pedido con productos agregado exitosamente",
-		Data:    productoPedido,
-	}
-	c.ServeJSON()
+	serveData(&c.Controller, http.StatusCreated, "Pedido con productos agregado exitosamente", productoPedido)
 }
 
 // @Title Update
-// @Summary Actualizar productos en un pedido consolidado
-// @Description Permite agregar o modificar productos en un pedido consolidado
+// @Summary Reemplazar los productos de un pedido
+// @Description Deja en un pedido que ya tiene productos exactamente las líneas enviadas y recalcula sus totales. Las líneas que no cambian (mismo producto, notas y opciones) se conservan con sus tickets de cocina; las unidades de más se agregan con el precio vigente y van a la cocina y las de menos se quitan si la cocina no las ha empezado. Solo la diferencia entra o sale del inventario.
 // @Tags producto_pedido
 // @Accept json
 // @Produce json
 // @Param pedido_id query int true "ID del pedido a actualizar"
-// @Param body body []map[string]interface{} true "Lista actualizada de productos"
+// @Param body body []models.ItemPedido true "Lista actualizada de productos con su cantidad"
 // @Success 200 {object} models.ApiResponse "Productos actualizados exitosamente"
 // @Failure 400 {object} models.ApiResponse "Datos inválidos"
 // @Failure 404 {object} models.ApiResponse "Pedido no encontrado"
+// @Failure 409 {object} models.ApiResponse "El pedido ya se pagó, salió de la cocina o terminó, la cocina ya empezó una línea que se quita o no hay stock suficiente"
+// @Failure 422 {object} models.ApiResponse "Algún producto no existe"
 // @Failure 500 {object} models.ApiResponse "Error interno del servidor"
 // @Security BearerAuth
-// @Router /producto_pedido [put]
+// @Router /v1/producto_pedido [put]
 func (c *ProductoPedidoController) Update() {
 	pedidoID, err := c.GetInt64("pedido_id")
 	if err != nil || pedidoID == 0 {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusBadRequest,
-			Message: "El parámetro 'pedido_id' es obligatorio y debe ser válido",
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, badRequestError("El parámetro 'pedido_id' es obligatorio y debe ser válido", err))
 		return
 	}
 
 	// Parsear los datos del cuerpo de la solicitud
-	var nuevosProductos []map[string]interface{}
+	var nuevosProductos []models.ItemPedido
 	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &nuevosProductos); err != nil {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusBadRequest,
-			Message: "Datos inválidos",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
-		return
-	}
-
-	if len(nuevosProductos) == 0 {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusBadRequest,
-			Message: "La lista de productos no puede estar vacía",
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, badRequestError("Datos inválidos", err))
 		return
 	}
 
-	o := orm.NewOrm()
-	productoPedido := models.ProductoPedido{}
-
-	// Verificar si existe el pedido en la base de datos
-	err = o.QueryTable(new(models.ProductoPedido)).
-		Filter("PK_ID_PEDIDO", pedidoID).
-		One(&productoPedido)
-	if err == orm.ErrNoRows {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Pedido no encontrado",
-		}
-		c.ServeJSON()
-		return
-	} else if err != nil {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al buscar el pedido",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
-		return
-	}
-
-	// Convertir la nueva lista de productos a JSON
-	nuevosDetallesJSON, err := json.Marshal(nuevosProductos)
+	productoPedido, err := services.NewProductoPedidoService(newStore()).Update(pedidoID, nuevosProductos, currentActor(&c.Controller))
 	if err != nil {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al procesar los detalles actualizados",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, err)
 		return
 	}
 
-	// Actualizar los detalles en la base de datos
-	productoPedido.DETALLES_PRODUCTOS = string(nuevosDetallesJSON)
-	if _, err := o.Update(&productoPedido, "DETALLES_PRODUCTOS"); err != nil {
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al actualizar los productos del pedido",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
-		return
-	}
-
-	c.Data["json"] = models.ApiResponse{
-		Code:    http.StatusOK,
-		Message: "Productos del pedido actualizados exitosamente",
-		Data:    nuevosProductos,
-	}
-	c.ServeJSON()
+	serveData(&c.Controller, http.StatusOK, "Productos del pedido actualizados exitosamente", productoPedido)
 }
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"net/http"
+	"restaurante/models"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// PromocionController administra las promociones y los códigos de descuento (v2)
+type PromocionController struct {
+	web.Controller
+}
+
+// @Title GetAll
+// @Summary Listar promociones (v2)
+// @Description Devuelve las promociones configuradas. Los clientes solo ven las activas que se aplican sin código.
+// @Tags v2 promociones
+// @Accept json
+// @Produce json
+// @Param activas query bool false "Solo las promociones activas"
+// @Success 200 {object} models.ApiResponse "Promociones obtenidas"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Security BearerAuth
+// @Router /v2/promociones [get]
+func (c *PromocionController) GetAll() {
+	activas, _ := c.GetBool("activas", false)
+
+	promociones, err := services.NewPromocionService(newStore()).List(activas, currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Promociones obtenidas exitosamente", promociones)
+}
+
+// @Title Post
+// @Summary Crear una promoción (v2)
+// @Description Crea una promoción PORCENTAJE, VALOR_FIJO, LLEVA_PAGA o DOMICILIO_GRATIS con su vigencia (hora de Bogotá), días de la semana, compra mínima, límites de uso y si es acumulable. Sin CODIGO se aplica sola a los pedidos que cumplan las condiciones.
+// @Tags v2 promociones
+// @Accept json
+// @Produce json
+// @Param body body models.Promocion true "Promoción"
+// @Success 201 {object} models.ApiResponse "Promoción creada"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden administrar promociones"
+// @Failure 409 {object} models.ApiResponse "El código ya está en uso"
+// @Failure 422 {object} models.ApiResponse "El producto indicado no existe"
+// @Security BearerAuth
+// @Router /v2/promociones [post]
+func (c *PromocionController) Post() {
+	promocion := models.Promocion{ACTIVA: true}
+	if err := parseJSONBody(&c.Controller, &promocion); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	promocion.PK_ID_PROMOCION = 0
+
+	if err := services.NewPromocionService(newStore()).Create(&promocion, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusCreated, "Promoción creada exitosamente", promocion)
+}
+
+// @Title Put
+// @Summary Actualizar una promoción (v2)
+// @Description Reemplaza la configuración de la promoción; con ACTIVA en false deja de aplicarse. Los pedidos ya cobrados conservan su descuento.
+// @Tags v2 promociones
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la promoción"
+// @Param body body models.Promocion true "Promoción"
+// @Success 200 {object} models.ApiResponse "Promoción actualizada"
+// @Failure 400 {object} models.ApiResponse "Datos inválidos"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden administrar promociones"
+// @Failure 404 {object} models.ApiResponse "Promoción no encontrada"
+// @Failure 409 {object} models.ApiResponse "El código ya está en uso"
+// @Security BearerAuth
+// @Router /v2/promociones/{id} [put]
+func (c *PromocionController) Put() {
+	id, err := pathInt64(&c.Controller, ":id")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	var promocion models.Promocion
+	if err := parseJSONBody(&c.Controller, &promocion); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	promocion.PK_ID_PROMOCION = id
+
+	if err := services.NewPromocionService(newStore()).Update(&promocion, currentActor(&c.Controller)); err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Promoción actualizada exitosamente", promocion)
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"net/http"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// ReporteController expone los reportes de ventas, cancelaciones y tiempos de entrega (v2)
+type ReporteController struct {
+	web.Controller
+}
+
+// @Title GetVentas
+// @Summary Reporte de ventas (v2)
+// @Description Suma los pedidos no cancelados del rango de fechas: subtotal, descuentos, impuestos con su desglose por categoría, propinas, domicilios y total.
+// @Tags v2 reportes
+// @Accept json
+// @Produce json
+// @Param desde query string true "Fecha inicial en formato YYYY-MM-DD"
+// @Param hasta query string true "Fecha final en formato YYYY-MM-DD"
+// @Success 200 {object} models.ApiResponse "Reporte de ventas generado"
+// @Failure 400 {object} models.ApiResponse "Fechas inválidas"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden consultar las ventas"
+// @Security BearerAuth
+// @Router /v2/reportes/ventas [get]
+func (c *ReporteController) GetVentas() {
+	reporte, err := services.NewReporteService(newStore()).Ventas(c.GetString("desde"), c.GetString("hasta"), currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Reporte de ventas generado exitosamente", reporte)
+}
+
+// @Title GetCancelaciones
+// @Summary Reporte de cancelaciones (v2)
+// @Description Resume las cancelaciones del rango de fechas: cantidad y valor por motivo, valor total de los pedidos cancelados, lo reembolsado y el detalle de cada cancelación.
+// @Tags v2 reportes
+// @Accept json
+// @Produce json
+// @Param desde query string true "Fecha inicial en formato YYYY-MM-DD"
+// @Param hasta query string true "Fecha final en formato YYYY-MM-DD"
+// @Success 200 {object} models.ApiResponse "Reporte de cancelaciones generado"
+// @Failure 400 {object} models.ApiResponse "Fechas inválidas"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden consultar las cancelaciones"
+// @Security BearerAuth
+// @Router /v2/reportes/cancelaciones [get]
+func (c *ReporteController) GetCancelaciones() {
+	reporte, err := services.NewReporteService(newStore()).Cancelaciones(c.GetString("desde"), c.GetString("hasta"), currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Reporte de cancelaciones generado exitosamente", reporte)
+}
+
+// @Title GetTiempos
+// @Summary Reporte de tiempos de preparación y entrega (v2)
+// @Description Compara los minutos estimados con los reales de los pedidos que entraron a la cocina en el rango de fechas: promedios, desviación (real menos estimado) y el detalle de cada pedido con la cola y la distancia usadas al estimar.
+// @Tags v2 reportes
+// @Accept json
+// @Produce json
+// @Param desde query string true "Fecha inicial en formato YYYY-MM-DD"
+// @Param hasta query string true "Fecha final en formato YYYY-MM-DD"
+// @Success 200 {object} models.ApiResponse "Reporte de tiempos generado"
+// @Failure 400 {object} models.ApiResponse "Fechas inválidas"
+// @Failure 401 {object} models.ApiResponse "Token no proporcionado o inválido"
+// @Failure 403 {object} models.ApiResponse "Los clientes no pueden consultar los tiempos"
+// @Security BearerAuth
+// @Router /v2/reportes/tiempos [get]
+func (c *ReporteController) GetTiempos() {
+	reporte, err := services.NewReporteService(newStore()).Tiempos(c.GetString("desde"), c.GetString("hasta"), currentActor(&c.Controller))
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Reporte de tiempos generado exitosamente", reporte)
+}
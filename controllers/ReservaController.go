@@ -3,12 +3,11 @@ package controllers
 import (
 	"encoding/json"
 	"net/http"
-	"restaurante/database"
 	"restaurante/models"
+	"restaurante/services"
 	"strconv"
 	"time"
 
-	"github.com/beego/beego/v2/client/orm"
 	"github.com/beego/beego/v2/server/web"
 )
 
@@ -16,14 +15,6 @@ type ReservaController struct {
 	web.Controller
 }
 
-// Estados permitidos para la reserva
-var estadosPermitidos = map[string]bool{
-	"PENDIENTE":  true,
-	"CONFIRMADA": true,
-	"CANCELADA":  true,
-	"CUMPLIDA":   true,
-}
-
 // @Title GetAll
 // @Summary Obtener todas las reservas
 // @Description Devuelve todas las reservas registradas en la base de datos.
@@ -32,41 +23,15 @@ var estadosPermitidos = map[string]bool{
 // @Produce json
 // @Success 200 {array} models.Reserva "Lista de reservas"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
-// @Router /reservas [get]
+// @Router /v1/reservas [get]
 func (c *ReservaController) GetAll() {
-	o := orm.NewOrm()
-	var reservas []models.Reserva
-
-	_, err := o.QueryTable(new(models.Reserva)).All(&reservas)
+	reservas, err := services.NewReservaService(newStore()).List()
 	if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al obtener reservas de la base de datos",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, err)
 		return
 	}
 
-	// Ajustar las fechas y horas al formato y zona horaria correcta
-	for i := range reservas {
-		reservas[i].CREATED_AT = reservas[i].CREATED_AT.In(database.BogotaZone)
-		reservas[i].UPDATED_AT = reservas[i].UPDATED_AT.In(database.BogotaZone)
-		reservas[i].FECHA = reservas[i].FECHA.In(database.BogotaZone)
-
-		if len(reservas[i].HORA) >= 19 {
-			reservas[i].HORA = reservas[i].HORA[11:19] // Asegurar formato HH:MM:SS
-		}
-	}
-
-	c.Ctx.Output.SetStatus(http.StatusOK)
-	c.Data["json"] = models.ApiResponse{
-		Code:    http.StatusOK,
-		Message: "Reservas obtenidas exitosamente",
-		Data:    reservas,
-	}
-	c.ServeJSON()
+	serveData(&c.Controller, http.StatusOK, "Reservas obtenidas exitosamente", reservas)
 }
 
 // @Title GetById
@@ -78,9 +43,8 @@ func (c *ReservaController) GetAll() {
 // @Param   id     query    int     true        "ID de la Reserva"
 // @Success 200 {object} models.Reserva "Reserva encontrada"
 // @Failure 404 {object} models.ApiResponse "Reserva no encontrada"
-// @Router /reservas/search [get]
+// @Router /v1/reservas/search [get]
 func (c *ReservaController) GetById() {
-	o := orm.NewOrm()
 	id, err := c.GetInt("id")
 
 	if err != nil || id == 0 {
@@ -94,26 +58,13 @@ func (c *ReservaController) GetById() {
 		return
 	}
 
-	reserva := models.Reserva{PK_ID_RESERVA: id}
-
-	err = o.Read(&reserva)
-	if err == orm.ErrNoRows {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Reserva no encontrada",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	reserva, err := services.NewReservaService(newStore()).GetByID(id)
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
-	reserva.FECHA = reserva.FECHA.In(database.BogotaZone)
-	reserva.CREATED_AT = reserva.CREATED_AT.In(database.BogotaZone)
-	reserva.UPDATED_AT = reserva.UPDATED_AT.In(database.BogotaZone)
-	if len(reserva.HORA) >= 19 {
-		reserva.HORA = reserva.HORA[11:19] // Formato HH:MM:SS
-	}
 
+	setETag(&c.Controller, reserva)
 	c.Ctx.Output.SetStatus(http.StatusOK)
 	c.Data["json"] = models.ApiResponse{
 		Code:    http.StatusOK,
@@ -132,9 +83,8 @@ func (c *ReservaController) GetById() {
 // @Param   body  body   models.Reserva true  "Datos de la reserva a crear"
 // @Success 201 {object} models.Reserva "Reserva creada"
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
-// @Router /reservas [post]
+// @Router /v1/reservas [post]
 func (c *ReservaController) Post() {
-	o := orm.NewOrm()
 	var input map[string]interface{}
 
 	// Decodificar la solicitud
@@ -215,7 +165,7 @@ func (c *ReservaController) Post() {
 
 	// Procesar ESTADO_RESERVA si existe
 	if estado, ok := input["ESTADO_RESERVA"].(string); ok && estado != "" {
-		if !estadosPermitidos[estado] {
+		if !services.EstadosReserva[estado] {
 			c.Ctx.Output.SetStatus(http.StatusBadRequest)
 			c.Data["json"] = models.ApiResponse{
 				Code:    http.StatusBadRequest,
@@ -238,20 +188,9 @@ func (c *ReservaController) Post() {
 		reserva.CREATED_BY = &createdBy
 	}
 
-	// Establecer valores automáticos
-	reserva.CREATED_AT = time.Now().UTC()
-	reserva.UPDATED_AT = time.Time{}
-
 	// Insertar en la base de datos
-	_, err := o.Insert(&reserva)
-	if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al crear la reserva",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	if err := services.NewReservaService(newStore()).Create(&reserva); err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
@@ -272,13 +211,15 @@ func (c *ReservaController) Post() {
 // @Accept json
 // @Produce json
 // @Param   id    query    int  true   "ID de la Reserva"
+// @Param   If-Match  header  string  true  "ETag obtenido al consultar la reserva"
 // @Param   body  body   models.Reserva true  "Datos de la reserva a actualizar"
 // @Success 200 {object} models.Reserva "Reserva actualizada"
 // @Failure 404 {object} models.ApiResponse "Reserva no encontrada"
-// @Router /reservas [put]
+// @Failure 409 {object} models.ApiResponse "La reserva cambió durante la actualización"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
+// @Router /v1/reservas [put]
 func (c *ReservaController) Put() {
-	o := orm.NewOrm()
-
 	// Obtener el ID de la reserva desde los parámetros
 	idStr := c.GetString("id")
 	id, err := strconv.Atoi(idStr)
@@ -294,14 +235,17 @@ func (c *ReservaController) Put() {
 	}
 
 	// Buscar la reserva por ID
-	reserva := models.Reserva{PK_ID_RESERVA: id}
-	if err := o.Read(&reserva); err != nil {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Reserva no encontrada",
-		}
-		c.ServeJSON()
+	service := services.NewReservaService(newStore())
+	reserva, err := service.GetForUpdate(id)
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	// Verificar que se está editando la última versión de la reserva
+	version, err := checkIfMatch(&c.Controller, reserva)
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
@@ -353,7 +297,7 @@ func (c *ReservaController) Put() {
 		reserva.PERSONAS = int(personas)
 	}
 
-	if estado, ok := input["ESTADO_RESERVA"].(string); ok && estadosPermitidos[estado] {
+	if estado, ok := input["ESTADO_RESERVA"].(string); ok && services.EstadosReserva[estado] {
 		reserva.ESTADO_RESERVA = &estado
 	}
 
@@ -365,20 +309,12 @@ func (c *ReservaController) Put() {
 		reserva.UPDATED_BY = &updatedBy
 	}
 
-	// Actualizar la fecha de modificación
-	reserva.UPDATED_AT = time.Now().UTC()
-
 	// Actualizar los datos en la base de datos
-	if _, err := o.Update(&reserva); err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al actualizar la reserva",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	if err := service.Update(reserva, version); err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
+	setETag(&c.Controller, reserva)
 
 	// Responder con los datos actualizados
 	c.Ctx.Output.SetStatus(http.StatusOK)
@@ -399,10 +335,8 @@ func (c *ReservaController) Put() {
 // @Param   id     query    int     true        "ID de la Reserva"
 // @Success 200 {object} models.ApiResponse "Reserva cancelada"
 // @Failure 404 {object} models.ApiResponse "Reserva no encontrada"
-// @Router /reservas [delete]
+// @Router /v1/reservas [delete]
 func (c *ReservaController) Delete() {
-	o := orm.NewOrm()
-
 	// Obtener el ID de la reserva desde los parámetros
 	idStr := c.GetString("id")
 	id, err := strconv.Atoi(idStr)
@@ -417,32 +351,10 @@ func (c *ReservaController) Delete() {
 		return
 	}
 
-	// Buscar la reserva por ID
-	reserva := models.Reserva{PK_ID_RESERVA: id}
-	if err := o.Read(&reserva); err != nil {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Reserva no encontrada",
-		}
-		c.ServeJSON()
-		return
-	}
-
 	// Actualizar el estado a CANCELADA
-	estadoCancelada := "CANCELADA"
-	reserva.ESTADO_RESERVA = &estadoCancelada
-	reserva.UPDATED_AT = time.Now() // Actualizar la fecha de modificación
-
-	// Guardar los cambios en la base de datos
-	if _, err := o.Update(&reserva, "ESTADO_RESERVA", "UPDATED_AT"); err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al cancelar la reserva",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	reserva, err := services.NewReservaService(newStore()).Cancel(id)
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
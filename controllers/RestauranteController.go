@@ -22,7 +22,7 @@ type RestauranteController struct {
 // @Produce json
 // @Success 200 {array} models.Restaurante "Lista de restaurantes"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
-// @Router /restaurantes [get]
+// @Router /v1/restaurantes [get]
 func (c *RestauranteController) GetAll() {
 	o := orm.NewOrm()
 	var restaurantes []models.Restaurante
@@ -43,6 +43,10 @@ func (c *RestauranteController) GetAll() {
 		if len(restaurantes[i].HORA_APERTURA) > 19 {
 			restaurantes[i].HORA_APERTURA = restaurantes[i].HORA_APERTURA[11:19] // Solo toma HH:mm:ss
 		}
+		if cierre := restaurantes[i].HORA_CIERRE; cierre != nil && len(*cierre) > 19 {
+			hora := (*cierre)[11:19]
+			restaurantes[i].HORA_CIERRE = &hora
+		}
 	}
 
 	c.Ctx.Output.SetStatus(http.StatusOK)
@@ -63,7 +67,7 @@ func (c *RestauranteController) GetAll() {
 // @Param   id     query    int     true        "ID del Restaurante"
 // @Success 200 {object} models.Restaurante "Restaurante encontrado"
 // @Failure 404 {object} models.ApiResponse "Restaurante no encontrado"
-// @Router /restaurantes/search [get]
+// @Router /v1/restaurantes/search [get]
 func (c *RestauranteController) GetById() {
 	o := orm.NewOrm()
 	id, err := c.GetInt("id")
@@ -111,7 +115,7 @@ func (c *RestauranteController) GetById() {
 // @Param   body  body   models.Restaurante true  "Datos del restaurante a crear"
 // @Success 201 {object} models.Restaurante "Restaurante creado"
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
-// @Router /restaurantes [post]
+// @Router /v1/restaurantes [post]
 func (c *RestauranteController) Post() {
 	o := orm.NewOrm()
 	var restaurante models.Restaurante
@@ -167,7 +171,7 @@ func (c *RestauranteController) Post() {
 // @Param   body  body   models.Restaurante true  "Datos del restaurante a actualizar"
 // @Success 200 {object} models.Restaurante "Restaurante actualizado"
 // @Failure 404 {object} models.ApiResponse "Restaurante no encontrado"
-// @Router /restaurantes [put]
+// @Router /v1/restaurantes [put]
 func (c *RestauranteController) Put() {
 	o := orm.NewOrm()
 
@@ -249,7 +253,7 @@ func (c *RestauranteController) Put() {
 // @Param   id     query    int     true        "ID del Restaurante"
 // @Success 204 {object} nil "Restaurante eliminado"
 // @Failure 404 {object} models.ApiResponse "Restaurante no encontrado"
-// @Router /restaurantes [delete]
+// @Router /v1/restaurantes [delete]
 func (c *RestauranteController) Delete() {
 	o := orm.NewOrm()
 
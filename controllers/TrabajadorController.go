@@ -4,8 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"restaurante/database"
 	"restaurante/models"
+	"restaurante/services"
 	"strconv"
 	"time"
 
@@ -50,49 +50,25 @@ func validateDates(fechaIngreso, fechaRetiro *time.Time) error {
 // @Success 200 {array} models.Trabajador "Lista de trabajadores"
 // @Failure 500 {object} models.ApiResponse "Error en la base de datos"
 // @Security BearerAuth
-// @Router /trabajadores [get]
+// @Router /v1/trabajadores [get]
 func (c *TrabajadorController) GetAll() {
-	o := orm.NewOrm()
-	var trabajadores []models.Trabajador
-
 	// Leer parámetros de la URL
-	fechaIngreso := c.GetString("fecha_ingreso")
-	rol := c.GetString("rol")
 	incluirRetirados, _ := c.GetBool("incluir_retirados", false) // Por defecto, no incluir retirados
 	soloRetirados, _ := c.GetBool("solo_retirados", false)       // Por defecto, no mostrar solo retirados
 
-	// Priorizar "solo retirados" sobre "incluir retirados"
-	query := o.QueryTable(new(models.Trabajador))
-	if soloRetirados {
-		query = query.Filter("FECHA_RETIRO__isnull", false)
-	} else if !incluirRetirados {
-		query = query.Filter("FECHA_RETIRO__isnull", true)
-	}
-
-	// Aplicar filtros adicionales
-	if fechaIngreso != "" {
-		query = query.Filter("FECHA_INGRESO__exact", fechaIngreso)
-	}
-	if rol != "" {
-		query = query.Filter("ROL__exact", rol)
-	}
-
-	// Ejecutar consulta
-	_, err := query.All(&trabajadores)
+	trabajadores, err := services.NewTrabajadorService(newStore()).List(services.TrabajadorFiltros{
+		FechaIngreso:     c.GetString("fecha_ingreso"),
+		Rol:              c.GetString("rol"),
+		IncluirRetirados: incluirRetirados,
+		SoloRetirados:    soloRetirados,
+	})
 	if err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al obtener trabajadores de la base de datos",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+		serveError(&c.Controller, err)
 		return
 	}
 
-	// Excluir contraseñas y ajustar fechas
+	// Ajustar fechas
 	for i := range trabajadores {
-		trabajadores[i].PASSWORD = "" // Excluir contraseña
 		if trabajadores[i].FECHA_RETIRO != nil {
 			fechaRetiroUTC := trabajadores[i].FECHA_RETIRO.UTC()
 			trabajadores[i].FECHA_RETIRO = &fechaRetiroUTC // UTC sin ajuste
@@ -135,9 +111,8 @@ func (c *TrabajadorController) GetAll() {
 // @Success 200 {object} models.Trabajador "Trabajador encontrado"
 // @Failure 404 {object} models.ApiResponse "Trabajador no encontrado"
 // @Security BearerAuth
-// @Router /trabajadores/search [get]
+// @Router /v1/trabajadores/search [get]
 func (c *TrabajadorController) GetById() {
-	o := orm.NewOrm()
 	id, err := c.GetInt64("id")
 
 	if err != nil || id == 0 {
@@ -150,19 +125,13 @@ func (c *TrabajadorController) GetById() {
 		return
 	}
 
-	trabajador := models.Trabajador{PK_DOCUMENTO_TRABAJADOR: id}
-	err = o.Read(&trabajador)
-	if err == orm.ErrNoRows {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Trabajador no encontrado",
-		}
-		c.ServeJSON()
+	trabajador, err := services.NewTrabajadorService(newStore()).GetByID(id)
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
-	trabajador.PASSWORD = ""
+	setETag(&c.Controller, trabajador)
 	c.Ctx.Output.SetStatus(http.StatusOK)
 	c.Data["json"] = models.ApiResponse{
 		Code:    http.StatusOK,
@@ -182,7 +151,7 @@ func (c *TrabajadorController) GetById() {
 // @Success 201 {object} models.Trabajador "Trabajador creado"
 // @Failure 400 {object} models.ApiResponse "Error en la solicitud"
 // @Security BearerAuth
-// @Router /trabajadores [post]
+// @Router /v1/trabajadores [post]
 func (c *TrabajadorController) Post() {
 	o := orm.NewOrm()
 	var input map[string]interface{}
@@ -374,13 +343,16 @@ func (c *TrabajadorController) Post() {
 // @Accept json
 // @Produce json
 // @Param   id    query    int  true   "ID del Trabajador"
+// @Param   If-Match  header  string  true  "ETag obtenido al consultar el trabajador"
 // @Param   body  body   models.Trabajador true  "Datos del trabajador a actualizar"
 // @Success 200 {object} models.Trabajador "Trabajador actualizado"
 // @Failure 404 {object} models.ApiResponse "Trabajador no encontrado"
+// @Failure 409 {object} models.ApiResponse "El trabajador cambió durante la actualización"
+// @Failure 412 {object} models.ApiResponse "El ETag no coincide con la versión actual"
+// @Failure 428 {object} models.ApiResponse "Falta la cabecera If-Match"
 // @Security BearerAuth
-// @Router /trabajadores [put]
+// @Router /v1/trabajadores [put]
 func (c *TrabajadorController) Put() {
-	o := orm.NewOrm()
 	id, err := c.GetInt64("id")
 
 	if err != nil || id == 0 {
@@ -394,14 +366,19 @@ func (c *TrabajadorController) Put() {
 	}
 
 	// Buscar trabajador existente
-	trabajador := models.Trabajador{PK_DOCUMENTO_TRABAJADOR: id}
-	if err := o.Read(&trabajador); err != nil {
-		c.Ctx.Output.SetStatus(http.StatusNotFound)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusNotFound,
-			Message: "Trabajador no encontrado",
-		}
-		c.ServeJSON()
+	service := services.NewTrabajadorService(newStore())
+	trabajador, err := service.GetForUpdate(id)
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	// Verificar que se está editando la última versión del trabajador, sin exponer la contraseña
+	publico := *trabajador
+	publico.PASSWORD = ""
+	version, err := checkIfMatch(&c.Controller, &publico)
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
@@ -520,20 +497,12 @@ func (c *TrabajadorController) Put() {
 		return
 	}
 
-	// Actualizar en la base de datos
-	if _, err := o.Update(&trabajador); err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al actualizar el trabajador",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
+	// Actualizar en la base de datos; la respuesta no incluye la contraseña
+	if err := service.Update(trabajador, version); err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
-
-	// Excluir contraseña de la respuesta
-	trabajador.PASSWORD = ""
+	setETag(&c.Controller, trabajador)
 
 	// Responder con éxito
 	c.Ctx.Output.SetStatus(http.StatusOK)
@@ -555,10 +524,8 @@ func (c *TrabajadorController) Put() {
 // @Success 200 {object} models.ApiResponse "Trabajador eliminado"
 // @Failure 404 {object} models.ApiResponse "Trabajador no encontrado"
 // @Security BearerAuth
-// @Router /trabajadores [delete]
+// @Router /v1/trabajadores [delete]
 func (c *TrabajadorController) Delete() {
-	o := orm.NewOrm()
-
 	// Obtener el ID del query parameter
 	idStr := c.GetString("id")
 	id, err := strconv.Atoi(idStr)
@@ -573,43 +540,13 @@ func (c *TrabajadorController) Delete() {
 		return
 	}
 
-	// Buscar al trabajador
-	trabajador := models.Trabajador{PK_DOCUMENTO_TRABAJADOR: int64(id)}
-	if err := o.Read(&trabajador); err != nil {
-		if err == orm.ErrNoRows {
-			c.Ctx.Output.SetStatus(http.StatusNotFound)
-			c.Data["json"] = models.ApiResponse{
-				Code:    http.StatusNotFound,
-				Message: "Trabajador no encontrado",
-			}
-		} else {
-			c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-			c.Data["json"] = models.ApiResponse{
-				Code:    http.StatusInternalServerError,
-				Message: "Error al buscar el trabajador",
-				Cause:   err.Error(),
-			}
-		}
-		c.ServeJSON()
+	// Registrar la fecha de retiro con la fecha actual en zona horaria de Bogotá
+	trabajador, err := services.NewTrabajadorService(newStore()).Retire(int64(id))
+	if err != nil {
+		serveError(&c.Controller, err)
 		return
 	}
 
-	// Actualizar la fecha de retiro a la fecha actual en zona horaria de Bogotá
-	fechaRetiro := time.Now().In(database.BogotaZone)
-	trabajador.FECHA_RETIRO = &fechaRetiro
-
-	// Actualizar el registro en la base de datos
-	if _, err := o.Update(&trabajador, "FECHA_RETIRO"); err != nil {
-		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
-		c.Data["json"] = models.ApiResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Error al actualizar la fecha de retiro del trabajador",
-			Cause:   err.Error(),
-		}
-		c.ServeJSON()
-		return
-	}
-	trabajador.PASSWORD = ""
 	// Responder con éxito
 	c.Ctx.Output.SetStatus(http.StatusOK)
 	c.Data["json"] = models.ApiResponse{
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"net/http"
+	"restaurante/models"
+	"restaurante/services"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// TrabajadorV2Controller expone los recursos anidados de un trabajador con parámetros de ruta
+type TrabajadorV2Controller struct {
+	web.Controller
+}
+
+// @Title GetNominas
+// @Summary Obtener las nóminas de un trabajador (v2)
+// @Description Devuelve las relaciones nómina-trabajador del trabajador indicado en la ruta. Una lista vacía responde 200.
+// @Tags v2 trabajadores
+// @Accept json
+// @Produce json
+// @Param documento path int true "Documento del trabajador"
+// @Param actual query bool false "Consultar solo la nómina actual"
+// @Param pagas query bool false "Consultar solo nóminas pagadas"
+// @Param no_pagas query bool false "Consultar solo nóminas no pagadas"
+// @Param mes query int false "Mes (1-12) para filtrar nóminas"
+// @Param anio query int false "Año (YYYY) para filtrar nóminas"
+// @Success 200 {array} models.NominaTrabajador "Nóminas del trabajador"
+// @Failure 400 {object} models.ApiResponse "Documento inválido"
+// @Failure 500 {object} models.ApiResponse "Error en la base de datos"
+// @Security BearerAuth
+// @Router /v2/trabajadores/{documento}/nominas [get]
+func (c *TrabajadorV2Controller) GetNominas() {
+	documento, err := pathInt64(&c.Controller, ":documento")
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+
+	actual, _ := c.GetBool("actual")
+	pagas, _ := c.GetBool("pagas")
+	noPagas, _ := c.GetBool("no_pagas")
+	mes, _ := c.GetInt("mes")
+	anio, _ := c.GetInt("anio")
+
+	relaciones, err := services.NewNominaTrabajadorService(newStore()).ListByTrabajador(documento, services.NominaFiltros{
+		Actual:  actual,
+		Pagas:   pagas,
+		NoPagas: noPagas,
+		Mes:     mes,
+		Anio:    anio,
+	})
+	if err != nil {
+		serveError(&c.Controller, err)
+		return
+	}
+	if relaciones == nil {
+		relaciones = []models.NominaTrabajador{}
+	}
+
+	serveData(&c.Controller, http.StatusOK, "Relaciones nómina-trabajador encontradas.", relaciones)
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// Los archivos .sql de la carpeta migrations se aplican en orden alfabético,
+// una sola vez, y quedan registrados en la tabla SCHEMA_MIGRATIONS
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+func RunMigrations() {
+	if err := ApplyMigrations(orm.NewOrm()); err != nil {
+		log.Fatal("Error al aplicar las migraciones:", err)
+	}
+}
+
+// ApplyMigrations aplica sobre o las migraciones que aún no estén en SCHEMA_MIGRATIONS
+func ApplyMigrations(o orm.Ormer) error {
+	if _, err := o.Raw(`CREATE TABLE IF NOT EXISTS "SCHEMA_MIGRATIONS" (
+		"VERSION" TEXT PRIMARY KEY,
+		"APPLIED_AT" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`).Exec(); err != nil {
+		return err
+	}
+
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var applied int
+		if err := o.Raw(`SELECT COUNT(*) FROM "SCHEMA_MIGRATIONS" WHERE "VERSION" = ?`, name).QueryRow(&applied); err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		script, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		err = o.DoTx(func(ctx context.Context, txOrm orm.TxOrmer) error {
+			if _, err := txOrm.Raw(string(script)).Exec(); err != nil {
+				return err
+			}
+			_, err := txOrm.Raw(`INSERT INTO "SCHEMA_MIGRATIONS" ("VERSION") VALUES (?)`, name).Exec()
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		fmt.Println("Migración aplicada:", name)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+-- Respuestas almacenadas para las solicitudes POST con cabecera Idempotency-Key
+CREATE TABLE IF NOT EXISTS "IDEMPOTENCIA" (
+    "PK_ID_IDEMPOTENCIA" SERIAL PRIMARY KEY,
+    "CLAVE" VARCHAR(255) NOT NULL UNIQUE,
+    "METODO" VARCHAR(10) NOT NULL,
+    "RUTA" TEXT NOT NULL,
+    "HASH_SOLICITUD" VARCHAR(64) NOT NULL,
+    "CODIGO_RESPUESTA" INTEGER NOT NULL DEFAULT 0,
+    "RESPUESTA" TEXT NOT NULL DEFAULT '',
+    "CABECERAS" TEXT NOT NULL DEFAULT '{}',
+    "CREATED_AT" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_IDEMPOTENCIA_CREATED_AT" ON "IDEMPOTENCIA" ("CREATED_AT");
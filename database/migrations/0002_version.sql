@@ -0,0 +1,7 @@
+-- Versión de cada registro para el control de concurrencia optimista (ETag / If-Match)
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "VERSION" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "PAGO" ADD COLUMN IF NOT EXISTS "VERSION" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "DOMICILIO" ADD COLUMN IF NOT EXISTS "VERSION" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "RESERVA" ADD COLUMN IF NOT EXISTS "VERSION" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "TRABAJADOR" ADD COLUMN IF NOT EXISTS "VERSION" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "PRODUCTO" ADD COLUMN IF NOT EXISTS "VERSION" INTEGER NOT NULL DEFAULT 0;
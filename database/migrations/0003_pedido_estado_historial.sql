@@ -0,0 +1,12 @@
+-- Línea de tiempo de los cambios de estado de cada pedido
+CREATE TABLE IF NOT EXISTS "PEDIDO_ESTADO_HISTORIAL" (
+    "PK_ID_HISTORIAL" SERIAL PRIMARY KEY,
+    "PK_ID_PEDIDO" INTEGER NOT NULL REFERENCES "PEDIDO" ("PK_ID_PEDIDO") ON DELETE CASCADE,
+    "ESTADO_ANTERIOR" TEXT,
+    "ESTADO_NUEVO" TEXT NOT NULL,
+    "DOCUMENTO_ACTOR" BIGINT,
+    "ROL_ACTOR" TEXT,
+    "FECHA" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_PEDIDO_ESTADO_HISTORIAL_PEDIDO" ON "PEDIDO_ESTADO_HISTORIAL" ("PK_ID_PEDIDO", "FECHA");
@@ -0,0 +1,6 @@
+-- Totales del pedido calculados en el servidor a partir de los precios de los productos
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "SUBTOTAL" BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "DESCUENTO" BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "IMPUESTO" BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "VALOR_DOMICILIO" BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "TOTAL" BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,43 @@
+-- Líneas de pedido normalizadas en lugar del arreglo JSONB de PRODUCTO_PEDIDO
+CREATE TABLE IF NOT EXISTS "DETALLE_PEDIDO" (
+    "PK_ID_DETALLE_PEDIDO" SERIAL PRIMARY KEY,
+    "PK_ID_PEDIDO" INTEGER NOT NULL REFERENCES "PEDIDO" ("PK_ID_PEDIDO") ON DELETE CASCADE,
+    "PK_ID_PRODUCTO" BIGINT NOT NULL REFERENCES "PRODUCTO" ("PK_ID_PRODUCTO"),
+    "NOMBRE" TEXT NOT NULL,
+    "CANTIDAD" INTEGER NOT NULL CHECK ("CANTIDAD" > 0),
+    "PRECIO_UNITARIO" BIGINT NOT NULL,
+    "NOTAS" TEXT
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_DETALLE_PEDIDO_PEDIDO" ON "DETALLE_PEDIDO" ("PK_ID_PEDIDO");
+CREATE INDEX IF NOT EXISTS "IDX_DETALLE_PEDIDO_PRODUCTO" ON "DETALLE_PEDIDO" ("PK_ID_PRODUCTO");
+
+-- Convierte los arreglos existentes. Los elementos sin un producto válido se descartan; el nombre y
+-- el precio se toman del producto cuando el elemento no los trae.
+INSERT INTO "DETALLE_PEDIDO" ("PK_ID_PEDIDO", "PK_ID_PRODUCTO", "NOMBRE", "CANTIDAD", "PRECIO_UNITARIO", "NOTAS")
+SELECT
+    pp."PK_ID_PEDIDO",
+    pr."PK_ID_PRODUCTO",
+    COALESCE(e ->> 'NOMBRE', pr."NOMBRE"),
+    CASE WHEN jsonb_typeof(e -> 'CANTIDAD') = 'number' AND (e ->> 'CANTIDAD')::NUMERIC >= 1
+        THEN (e ->> 'CANTIDAD')::NUMERIC::INTEGER ELSE 1 END,
+    CASE WHEN jsonb_typeof(e -> 'PRECIO_UNITARIO') = 'number'
+        THEN (e ->> 'PRECIO_UNITARIO')::NUMERIC::BIGINT ELSE pr."PRECIO" END,
+    e ->> 'NOTAS'
+FROM "PRODUCTO_PEDIDO" pp
+CROSS JOIN LATERAL jsonb_array_elements(pp."DETALLES_PRODUCTOS") AS e
+JOIN "PEDIDO" p ON p."PK_ID_PEDIDO" = pp."PK_ID_PEDIDO"
+JOIN "PRODUCTO" pr ON pr."PK_ID_PRODUCTO"::TEXT = e ->> 'PK_ID_PRODUCTO'
+WHERE jsonb_typeof(pp."DETALLES_PRODUCTOS") = 'array'
+ORDER BY pp."PK_ID_PRODUCTO_PEDIDO";
+
+-- Los pedidos anteriores a los totales calculados en el servidor toman el subtotal de sus líneas
+UPDATE "PEDIDO" p
+SET "SUBTOTAL" = l.subtotal,
+    "TOTAL" = l.subtotal - p."DESCUENTO" + p."IMPUESTO" + p."VALOR_DOMICILIO"
+FROM (
+    SELECT "PK_ID_PEDIDO", SUM("CANTIDAD" * "PRECIO_UNITARIO") AS subtotal
+    FROM "DETALLE_PEDIDO"
+    GROUP BY "PK_ID_PEDIDO"
+) l
+WHERE l."PK_ID_PEDIDO" = p."PK_ID_PEDIDO" AND p."SUBTOTAL" = 0;
@@ -0,0 +1,18 @@
+-- Solo los productos que lo activen descuentan inventario con los pedidos; los existentes se
+-- siguen vendiendo aunque su CANTIDAD esté en cero hasta que se registre su inventario
+ALTER TABLE "PRODUCTO" ADD COLUMN IF NOT EXISTS "CONTROLA_STOCK" BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Libro de movimientos de inventario de cada producto
+CREATE TABLE IF NOT EXISTS "MOVIMIENTO_STOCK" (
+    "PK_ID_MOVIMIENTO" SERIAL PRIMARY KEY,
+    "PK_ID_PRODUCTO" BIGINT NOT NULL REFERENCES "PRODUCTO" ("PK_ID_PRODUCTO"),
+    "PK_ID_PEDIDO" INTEGER REFERENCES "PEDIDO" ("PK_ID_PEDIDO") ON DELETE SET NULL,
+    "CANTIDAD" INTEGER NOT NULL,
+    "MOTIVO" TEXT NOT NULL,
+    "STOCK_RESULTANTE" INTEGER NOT NULL,
+    "DOCUMENTO_ACTOR" BIGINT,
+    "OBSERVACIONES" TEXT,
+    "FECHA" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_MOVIMIENTO_STOCK_PRODUCTO" ON "MOVIMIENTO_STOCK" ("PK_ID_PRODUCTO", "FECHA");
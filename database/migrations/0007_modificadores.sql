@@ -0,0 +1,33 @@
+-- Grupos de modificadores de los productos ("Término", "Adiciones") y sus opciones
+CREATE TABLE IF NOT EXISTS "GRUPO_MODIFICADOR" (
+    "PK_ID_GRUPO_MODIFICADOR" SERIAL PRIMARY KEY,
+    "PK_ID_PRODUCTO" BIGINT NOT NULL REFERENCES "PRODUCTO" ("PK_ID_PRODUCTO") ON DELETE CASCADE,
+    "NOMBRE" TEXT NOT NULL,
+    "OBLIGATORIO" BOOLEAN NOT NULL DEFAULT FALSE,
+    "MIN_SELECCION" INTEGER NOT NULL DEFAULT 0 CHECK ("MIN_SELECCION" >= 0),
+    "MAX_SELECCION" INTEGER NOT NULL DEFAULT 0 CHECK ("MAX_SELECCION" >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_GRUPO_MODIFICADOR_PRODUCTO" ON "GRUPO_MODIFICADOR" ("PK_ID_PRODUCTO");
+
+CREATE TABLE IF NOT EXISTS "OPCION_MODIFICADOR" (
+    "PK_ID_OPCION_MODIFICADOR" SERIAL PRIMARY KEY,
+    "PK_ID_GRUPO_MODIFICADOR" INTEGER NOT NULL REFERENCES "GRUPO_MODIFICADOR" ("PK_ID_GRUPO_MODIFICADOR") ON DELETE CASCADE,
+    "NOMBRE" TEXT NOT NULL,
+    "PRECIO_ADICIONAL" BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_OPCION_MODIFICADOR_GRUPO" ON "OPCION_MODIFICADOR" ("PK_ID_GRUPO_MODIFICADOR");
+
+-- Opciones elegidas en cada línea de pedido. No referencia OPCION_MODIFICADOR: el grupo, el nombre y
+-- el precio quedan copiados para que borrar una opción no cambie los pedidos ya hechos.
+CREATE TABLE IF NOT EXISTS "DETALLE_PEDIDO_MODIFICADOR" (
+    "PK_ID_DETALLE_MODIFICADOR" SERIAL PRIMARY KEY,
+    "PK_ID_DETALLE_PEDIDO" INTEGER NOT NULL REFERENCES "DETALLE_PEDIDO" ("PK_ID_DETALLE_PEDIDO") ON DELETE CASCADE,
+    "PK_ID_OPCION_MODIFICADOR" INTEGER NOT NULL,
+    "GRUPO" TEXT NOT NULL,
+    "NOMBRE" TEXT NOT NULL,
+    "PRECIO_ADICIONAL" BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_DETALLE_PEDIDO_MODIFICADOR_DETALLE" ON "DETALLE_PEDIDO_MODIFICADOR" ("PK_ID_DETALLE_PEDIDO");
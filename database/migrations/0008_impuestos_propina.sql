@@ -0,0 +1,25 @@
+-- Tarifas de impuesto por categoría tributaria de los productos
+CREATE TABLE IF NOT EXISTS "REGLA_IMPUESTO" (
+    "CATEGORIA" TEXT PRIMARY KEY,
+    "NOMBRE" TEXT NOT NULL,
+    "TARIFA" INTEGER NOT NULL CHECK ("TARIFA" BETWEEN 0 AND 100)
+);
+
+INSERT INTO "REGLA_IMPUESTO" ("CATEGORIA", "NOMBRE", "TARIFA") VALUES
+    ('IMPOCONSUMO', 'Impuesto nacional al consumo', 8),
+    ('IVA', 'Impuesto al valor agregado', 19),
+    ('EXENTO', 'Exento', 0)
+ON CONFLICT ("CATEGORIA") DO NOTHING;
+
+-- Los precios incluyen el impuesto. Los productos existentes quedan EXENTO para que sus precios y
+-- sus totales no cambien; el administrador les asigna su categoría después
+ALTER TABLE "PRODUCTO" ADD COLUMN IF NOT EXISTS "CATEGORIA_IMPUESTO" TEXT NOT NULL DEFAULT 'EXENTO' REFERENCES "REGLA_IMPUESTO" ("CATEGORIA");
+
+-- Las líneas ya registradas se cobraron sin impuesto y así se conservan
+ALTER TABLE "DETALLE_PEDIDO" ADD COLUMN IF NOT EXISTS "CATEGORIA_IMPUESTO" TEXT NOT NULL DEFAULT 'EXENTO';
+ALTER TABLE "DETALLE_PEDIDO" ADD COLUMN IF NOT EXISTS "TARIFA_IMPUESTO" INTEGER NOT NULL DEFAULT 0;
+
+-- Propina sugerida de los pedidos en el restaurante
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "PROPINA_SUGERIDA" BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "PROPINA_ACEPTADA" BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "PROPINA" BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,39 @@
+-- Promociones: porcentaje, valor fijo, "lleve X pague Y" y domicilio gratis. Sin CODIGO se aplican
+-- solas; con CODIGO solo a los pedidos que lo registran.
+CREATE TABLE IF NOT EXISTS "PROMOCION" (
+    "PK_ID_PROMOCION" SERIAL PRIMARY KEY,
+    "NOMBRE" TEXT NOT NULL,
+    "CODIGO" TEXT UNIQUE,
+    "TIPO" TEXT NOT NULL CHECK ("TIPO" IN ('PORCENTAJE', 'VALOR_FIJO', 'LLEVA_PAGA', 'DOMICILIO_GRATIS')),
+    "VALOR" BIGINT NOT NULL DEFAULT 0 CHECK ("VALOR" >= 0),
+    "PK_ID_PRODUCTO" BIGINT REFERENCES "PRODUCTO" ("PK_ID_PRODUCTO") ON DELETE CASCADE,
+    "LLEVA" INTEGER NOT NULL DEFAULT 0,
+    "PAGA" INTEGER NOT NULL DEFAULT 0,
+    "MINIMO_COMPRA" BIGINT NOT NULL DEFAULT 0,
+    "VIGENTE_DESDE" TIMESTAMP,
+    "VIGENTE_HASTA" TIMESTAMP,
+    "DIAS_SEMANA" TEXT NOT NULL DEFAULT '',
+    "LIMITE_USOS" INTEGER NOT NULL DEFAULT 0 CHECK ("LIMITE_USOS" >= 0),
+    "LIMITE_POR_CLIENTE" INTEGER NOT NULL DEFAULT 0 CHECK ("LIMITE_POR_CLIENTE" >= 0),
+    "ACUMULABLE" BOOLEAN NOT NULL DEFAULT FALSE,
+    "ACTIVA" BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+-- Promociones aplicadas a cada pedido; se reemplazan cada vez que se recalculan sus totales
+CREATE TABLE IF NOT EXISTS "PEDIDO_PROMOCION" (
+    "PK_ID_PEDIDO_PROMOCION" SERIAL PRIMARY KEY,
+    "PK_ID_PEDIDO" INTEGER NOT NULL REFERENCES "PEDIDO" ("PK_ID_PEDIDO") ON DELETE CASCADE,
+    "PK_ID_PROMOCION" INTEGER NOT NULL REFERENCES "PROMOCION" ("PK_ID_PROMOCION"),
+    "PK_DOCUMENTO_CLIENTE" BIGINT,
+    "NOMBRE" TEXT NOT NULL,
+    "TIPO" TEXT NOT NULL,
+    "DESCUENTO" BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_PEDIDO_PROMOCION_PEDIDO" ON "PEDIDO_PROMOCION" ("PK_ID_PEDIDO");
+CREATE INDEX IF NOT EXISTS "IDX_PEDIDO_PROMOCION_PROMOCION" ON "PEDIDO_PROMOCION" ("PK_ID_PROMOCION");
+
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "CODIGO_PROMOCION" TEXT;
+
+-- Parte del descuento que le toca a cada línea; el impuesto se liquida sobre el valor descontado
+ALTER TABLE "DETALLE_PEDIDO" ADD COLUMN IF NOT EXISTS "DESCUENTO" BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,28 @@
+-- Reembolsos de los pagos cobrados de pedidos cancelados. Quedan PENDIENTE hasta que se devuelve
+-- el dinero por el mismo método de pago.
+CREATE TABLE IF NOT EXISTS "REEMBOLSO" (
+    "PK_ID_REEMBOLSO" SERIAL PRIMARY KEY,
+    "PK_ID_PAGO" INTEGER NOT NULL REFERENCES "PAGO" ("PK_ID_PAGO"),
+    "PK_ID_PEDIDO" INTEGER NOT NULL REFERENCES "PEDIDO" ("PK_ID_PEDIDO") ON DELETE CASCADE,
+    "PK_ID_METODO_PAGO" INTEGER REFERENCES "METODO_PAGO" ("PK_ID_METODO_PAGO"),
+    "MONTO" BIGINT NOT NULL CHECK ("MONTO" >= 0),
+    "ESTADO" TEXT NOT NULL DEFAULT 'PENDIENTE',
+    "FECHA" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Una cancelación por pedido con su motivo, el estado en que estaba y quién lo canceló
+CREATE TABLE IF NOT EXISTS "CANCELACION_PEDIDO" (
+    "PK_ID_CANCELACION" SERIAL PRIMARY KEY,
+    "PK_ID_PEDIDO" INTEGER NOT NULL UNIQUE REFERENCES "PEDIDO" ("PK_ID_PEDIDO") ON DELETE CASCADE,
+    "MOTIVO" TEXT NOT NULL CHECK ("MOTIVO" IN ('CLIENTE_DESISTE', 'DEMORA', 'SIN_PRODUCTO', 'ERROR_PEDIDO', 'PAGO_RECHAZADO', 'OTRO')),
+    "DETALLE" TEXT,
+    "ESTADO_ANTERIOR" TEXT NOT NULL,
+    "TOTAL" BIGINT NOT NULL DEFAULT 0,
+    "DOCUMENTO_ACTOR" BIGINT,
+    "ROL_ACTOR" TEXT,
+    "FECHA" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_CANCELACION_PEDIDO_FECHA" ON "CANCELACION_PEDIDO" ("FECHA");
+CREATE INDEX IF NOT EXISTS "IDX_REEMBOLSO_PAGO" ON "REEMBOLSO" ("PK_ID_PAGO");
+CREATE INDEX IF NOT EXISTS "IDX_REEMBOLSO_PEDIDO" ON "REEMBOLSO" ("PK_ID_PEDIDO");
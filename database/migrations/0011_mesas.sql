@@ -0,0 +1,15 @@
+-- Mesas del salón. Una mesa OCUPADA puede tener varios pedidos abiertos (rondas); al irse los
+-- clientes queda POR LIMPIAR hasta que vuelve a estar LIBRE.
+CREATE TABLE IF NOT EXISTS "MESA" (
+    "PK_ID_MESA" SERIAL PRIMARY KEY,
+    "NUMERO" INTEGER NOT NULL UNIQUE CHECK ("NUMERO" > 0),
+    "CAPACIDAD" INTEGER NOT NULL CHECK ("CAPACIDAD" > 0),
+    "ZONA" TEXT NOT NULL,
+    "ESTADO" TEXT NOT NULL DEFAULT 'LIBRE' CHECK ("ESTADO" IN ('LIBRE', 'OCUPADA', 'POR LIMPIAR'))
+);
+
+-- Mesa y mesero de los pedidos en el salón
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "PK_ID_MESA" INTEGER REFERENCES "MESA" ("PK_ID_MESA");
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "PK_DOCUMENTO_MESERO" BIGINT REFERENCES "TRABAJADOR" ("PK_DOCUMENTO_TRABAJADOR");
+
+CREATE INDEX IF NOT EXISTS "IDX_PEDIDO_MESA" ON "PEDIDO" ("PK_ID_MESA");
@@ -0,0 +1,13 @@
+-- Un pedido puede tener varios pagos cuando los clientes dividen la cuenta. Cada pago indica el
+-- pedido que cubre y la relación PEDIDO_CLIENTE del cliente que lo pagó. Los pagos son registros de
+-- dinero y no se borran con el pedido: un pedido con pagos no se puede eliminar, se cancela.
+ALTER TABLE "PAGO" ADD COLUMN IF NOT EXISTS "PK_ID_PEDIDO" INTEGER REFERENCES "PEDIDO" ("PK_ID_PEDIDO") ON DELETE RESTRICT;
+ALTER TABLE "PAGO" ADD COLUMN IF NOT EXISTS "PK_ID_PEDIDO_CLIENTE" BIGINT REFERENCES "PEDIDO_CLIENTE" ("PK_ID_PEDIDO_CLIENTE");
+
+-- Los pagos ya asignados con PEDIDO.PK_ID_PAGO pasan a ser pagos del pedido
+UPDATE "PAGO" pa
+SET "PK_ID_PEDIDO" = p."PK_ID_PEDIDO"
+FROM "PEDIDO" p
+WHERE p."PK_ID_PAGO" = pa."PK_ID_PAGO" AND pa."PK_ID_PEDIDO" IS NULL;
+
+CREATE INDEX IF NOT EXISTS "IDX_PAGO_PEDIDO" ON "PAGO" ("PK_ID_PEDIDO");
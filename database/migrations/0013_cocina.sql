@@ -0,0 +1,28 @@
+-- Estación de la cocina que prepara cada producto (PARRILLA, FRITOS, BEBIDAS...)
+ALTER TABLE "PRODUCTO" ADD COLUMN IF NOT EXISTS "ESTACION" TEXT NOT NULL DEFAULT 'COCINA';
+
+-- Tickets de cocina: al pasar a EN PREPARACION cada pedido se reparte en un ticket por estación
+-- con un ítem por línea. El ticket queda LISTO cuando lo están todos sus ítems.
+CREATE TABLE IF NOT EXISTS "TICKET_COCINA" (
+    "PK_ID_TICKET" SERIAL PRIMARY KEY,
+    "PK_ID_PEDIDO" INTEGER NOT NULL REFERENCES "PEDIDO" ("PK_ID_PEDIDO") ON DELETE CASCADE,
+    "ESTACION" TEXT NOT NULL,
+    "ESTADO" TEXT NOT NULL DEFAULT 'PENDIENTE' CHECK ("ESTADO" IN ('PENDIENTE', 'PREPARANDO', 'LISTO')),
+    "CREADO" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "LISTO_EN" TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "TICKET_COCINA_ITEM" (
+    "PK_ID_ITEM_TICKET" SERIAL PRIMARY KEY,
+    "PK_ID_TICKET" INTEGER NOT NULL REFERENCES "TICKET_COCINA" ("PK_ID_TICKET") ON DELETE CASCADE,
+    "PK_ID_DETALLE_PEDIDO" INTEGER NOT NULL REFERENCES "DETALLE_PEDIDO" ("PK_ID_DETALLE_PEDIDO") ON DELETE CASCADE,
+    "NOMBRE" TEXT NOT NULL,
+    "CANTIDAD" INTEGER NOT NULL CHECK ("CANTIDAD" > 0),
+    "NOTAS" TEXT,
+    "MODIFICADORES" TEXT,
+    "ESTADO" TEXT NOT NULL DEFAULT 'PENDIENTE' CHECK ("ESTADO" IN ('PENDIENTE', 'PREPARANDO', 'LISTO'))
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_TICKET_COCINA_ESTACION" ON "TICKET_COCINA" ("ESTACION", "ESTADO");
+CREATE INDEX IF NOT EXISTS "IDX_TICKET_COCINA_PEDIDO" ON "TICKET_COCINA" ("PK_ID_PEDIDO");
+CREATE INDEX IF NOT EXISTS "IDX_TICKET_COCINA_ITEM_TICKET" ON "TICKET_COCINA_ITEM" ("PK_ID_TICKET");
@@ -0,0 +1,5 @@
+-- Trabajador que lleva cada domicilio. Los domiciliarios reciben en /v2/eventos los cambios de los
+-- domicilios y pedidos que tienen asignados.
+ALTER TABLE "DOMICILIO" ADD COLUMN IF NOT EXISTS "PK_DOCUMENTO_DOMICILIARIO" BIGINT REFERENCES "TRABAJADOR" ("PK_DOCUMENTO_TRABAJADOR");
+
+CREATE INDEX IF NOT EXISTS "IDX_DOMICILIO_DOMICILIARIO" ON "DOMICILIO" ("PK_DOCUMENTO_DOMICILIARIO");
@@ -0,0 +1,24 @@
+-- Minutos de preparación de cada producto; 0 usa el tiempo por defecto del restaurante
+ALTER TABLE "PRODUCTO" ADD COLUMN IF NOT EXISTS "TIEMPO_PREPARACION" INTEGER NOT NULL DEFAULT 0 CHECK ("TIEMPO_PREPARACION" >= 0);
+
+-- Distancia del domicilio al restaurante, para estimar el tiempo de entrega
+ALTER TABLE "DOMICILIO" ADD COLUMN IF NOT EXISTS "DISTANCIA_KM" DOUBLE PRECISION CHECK ("DISTANCIA_KM" >= 0);
+
+-- Hora estimada de entrega del pedido; se recalcula cuando cambian sus productos o su estado
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "ENTREGA_ESTIMADA" TIMESTAMP;
+
+-- Minutos estimados y reales de cada pedido desde que entra a la cocina, para ajustar las estimaciones
+CREATE TABLE IF NOT EXISTS "TIEMPO_PEDIDO" (
+    "PK_ID_PEDIDO" INTEGER PRIMARY KEY REFERENCES "PEDIDO" ("PK_ID_PEDIDO") ON DELETE CASCADE,
+    "COLA" INTEGER NOT NULL DEFAULT 0,
+    "DISTANCIA_KM" DOUBLE PRECISION,
+    "PREPARACION_ESTIMADA" INTEGER NOT NULL,
+    "ENTREGA_ESTIMADA" INTEGER NOT NULL DEFAULT 0,
+    "PREPARACION_REAL" INTEGER,
+    "ENTREGA_REAL" INTEGER,
+    "INICIO" TIMESTAMP NOT NULL,
+    "LISTO_EN" TIMESTAMP,
+    "ENTREGADO_EN" TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_TIEMPO_PEDIDO_INICIO" ON "TIEMPO_PEDIDO" ("INICIO");
@@ -0,0 +1,27 @@
+-- Impresoras térmicas de red: las de caja imprimen recibos y las que tienen ESTACION las comandas
+CREATE TABLE IF NOT EXISTS "IMPRESORA" (
+    "PK_ID_IMPRESORA" SERIAL PRIMARY KEY,
+    "NOMBRE" TEXT NOT NULL,
+    "HOST" TEXT NOT NULL,
+    "PUERTO" INTEGER NOT NULL DEFAULT 9100 CHECK ("PUERTO" BETWEEN 1 AND 65535),
+    "ANCHO" INTEGER NOT NULL DEFAULT 80 CHECK ("ANCHO" IN (58, 80)),
+    "ESTACION" TEXT,
+    "CAJON" BOOLEAN NOT NULL DEFAULT FALSE,
+    "ACTIVA" BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+-- Cola de impresión de recibos y comandas
+CREATE TABLE IF NOT EXISTS "TRABAJO_IMPRESION" (
+    "PK_ID_TRABAJO" SERIAL PRIMARY KEY,
+    "PK_ID_IMPRESORA" INTEGER NOT NULL REFERENCES "IMPRESORA" ("PK_ID_IMPRESORA") ON DELETE CASCADE,
+    "PK_ID_PEDIDO" INTEGER NOT NULL REFERENCES "PEDIDO" ("PK_ID_PEDIDO") ON DELETE CASCADE,
+    "PK_ID_TICKET" INTEGER REFERENCES "TICKET_COCINA" ("PK_ID_TICKET") ON DELETE SET NULL,
+    "TIPO" TEXT NOT NULL CHECK ("TIPO" IN ('RECIBO', 'COMANDA')),
+    "ESTADO" TEXT NOT NULL DEFAULT 'PENDIENTE' CHECK ("ESTADO" IN ('PENDIENTE', 'IMPRESO', 'FALLIDO')),
+    "INTENTOS" INTEGER NOT NULL DEFAULT 0,
+    "ERROR" TEXT,
+    "CREADO" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "IMPRESO_EN" TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_TRABAJO_IMPRESION_ESTADO" ON "TRABAJO_IMPRESION" ("ESTADO", "CREADO");
@@ -0,0 +1,39 @@
+-- Rangos de numeración autorizados por la DIAN para la factura electrónica y el documento equivalente POS
+CREATE TABLE IF NOT EXISTS "RESOLUCION_FACTURACION" (
+    "PK_ID_RESOLUCION" SERIAL PRIMARY KEY,
+    "NUMERO" TEXT NOT NULL,
+    "TIPO_DOCUMENTO" TEXT NOT NULL CHECK ("TIPO_DOCUMENTO" IN ('FACTURA', 'POS')),
+    "PREFIJO" TEXT NOT NULL DEFAULT '',
+    "DESDE" BIGINT NOT NULL CHECK ("DESDE" > 0),
+    "HASTA" BIGINT NOT NULL,
+    "SIGUIENTE" BIGINT NOT NULL,
+    "FECHA_DESDE" DATE NOT NULL,
+    "FECHA_HASTA" DATE NOT NULL,
+    "CLAVE_TECNICA" TEXT NOT NULL DEFAULT '',
+    "ACTIVA" BOOLEAN NOT NULL DEFAULT TRUE,
+    CHECK ("HASTA" >= "DESDE"),
+    CHECK ("SIGUIENTE" BETWEEN "DESDE" AND "HASTA" + 1),
+    CHECK ("FECHA_HASTA" >= "FECHA_DESDE")
+);
+
+-- Documentos electrónicos firmados de los pedidos pagados: una venta se soporta con un solo documento
+CREATE TABLE IF NOT EXISTS "DOCUMENTO_ELECTRONICO" (
+    "PK_ID_DOCUMENTO" SERIAL PRIMARY KEY,
+    "PK_ID_PEDIDO" INTEGER NOT NULL UNIQUE REFERENCES "PEDIDO" ("PK_ID_PEDIDO"),
+    "PK_ID_RESOLUCION" INTEGER NOT NULL REFERENCES "RESOLUCION_FACTURACION" ("PK_ID_RESOLUCION"),
+    "TIPO_DOCUMENTO" TEXT NOT NULL CHECK ("TIPO_DOCUMENTO" IN ('FACTURA', 'POS')),
+    "PREFIJO" TEXT NOT NULL DEFAULT '',
+    "NUMERO" BIGINT NOT NULL,
+    "CODIGO" TEXT NOT NULL UNIQUE,
+    "FECHA_EMISION" TIMESTAMP NOT NULL,
+    "TOTAL" BIGINT NOT NULL,
+    "DOCUMENTO_ADQUIRENTE" TEXT NOT NULL,
+    "NOMBRE_ADQUIRENTE" TEXT NOT NULL,
+    "ESTADO" TEXT NOT NULL DEFAULT 'GENERADO' CHECK ("ESTADO" IN ('GENERADO', 'ACEPTADO', 'RECHAZADO')),
+    "ID_ENVIO" TEXT,
+    "RESPUESTA" TEXT,
+    "XML" TEXT NOT NULL,
+    UNIQUE ("PREFIJO", "NUMERO")
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_DOCUMENTO_ELECTRONICO_ESTADO" ON "DOCUMENTO_ELECTRONICO" ("ESTADO");
@@ -0,0 +1,8 @@
+-- Hora a la que el cliente pidió recibir o recoger el pedido; mientras falte más de la
+-- anticipación configurada el pedido no entra a la cocina
+ALTER TABLE "PEDIDO" ADD COLUMN IF NOT EXISTS "PROGRAMADO_PARA" TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS "IDX_PEDIDO_PROGRAMADO_PARA" ON "PEDIDO" ("PROGRAMADO_PARA") WHERE "PROGRAMADO_PARA" IS NOT NULL;
+
+-- Hora de cierre del horario habitual; sin ella el restaurante atiende hasta la medianoche
+ALTER TABLE "RESTAURANTE" ADD COLUMN IF NOT EXISTS "HORA_CIERRE" TIME;
@@ -0,0 +1,11 @@
+-- Pedidos anteriores que cada cliente guarda para repetirlos con un toque
+CREATE TABLE IF NOT EXISTS "PEDIDO_FAVORITO" (
+    "PK_ID_FAVORITO" SERIAL PRIMARY KEY,
+    "PK_DOCUMENTO_CLIENTE" BIGINT NOT NULL REFERENCES "CLIENTE" ("PK_DOCUMENTO_CLIENTE") ON DELETE CASCADE,
+    "PK_ID_PEDIDO" INTEGER NOT NULL REFERENCES "PEDIDO" ("PK_ID_PEDIDO") ON DELETE CASCADE,
+    "NOMBRE" TEXT NOT NULL,
+    "CREADO" TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE ("PK_DOCUMENTO_CLIENTE", "PK_ID_PEDIDO")
+);
+
+CREATE INDEX IF NOT EXISTS "IDX_PEDIDO_FAVORITO_CLIENTE" ON "PEDIDO_FAVORITO" ("PK_DOCUMENTO_CLIENTE");
@@ -0,0 +1,8 @@
+-- Las claves de idempotencia son únicas por usuario y no en toda la API: dos usuarios que
+-- generen la misma clave no se bloquean entre sí. Las claves guardadas quedan sin usuario y
+-- vencen con idempotencia_ventana.
+ALTER TABLE "IDEMPOTENCIA" ADD COLUMN IF NOT EXISTS "USUARIO" VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE "IDEMPOTENCIA" DROP CONSTRAINT IF EXISTS "IDEMPOTENCIA_CLAVE_key";
+
+CREATE UNIQUE INDEX IF NOT EXISTS "IDX_IDEMPOTENCIA_USUARIO_CLAVE" ON "IDEMPOTENCIA" ("USUARIO", "CLAVE");
@@ -17,7 +17,7 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/cambios_horario": {
+        "/v1/cambios_horario": {
             "get": {
                 "description": "Obtiene un listado de todos los cambios de horario registrados en la base de datos",
                 "consumes": [
@@ -195,7 +195,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/cambios_horario/actual": {
+        "/v1/cambios_horario/actual": {
             "get": {
                 "description": "Obtiene el cambio de horario que aplica para la fecha actual, si existe.",
                 "consumes": [
@@ -231,7 +231,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/clientes": {
+        "/v1/clientes": {
             "get": {
                 "security": [
                     {
@@ -274,9 +274,7 @@ const docTemplate = `{
                         "description": "Lista de clientes con los campos especificados",
                         "schema": {
                             "type": "array",
-                            "items": {
-                                "type": "object"
-                            }
+                            "items": {}
                         }
                     },
                     "500": {
@@ -422,7 +420,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/clientes/search": {
+        "/v1/clientes/search": {
             "get": {
                 "security": [
                     {
@@ -465,7 +463,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/domicilios": {
+        "/v1/domicilios": {
             "get": {
                 "security": [
                     {
@@ -552,6 +550,13 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el domicilio",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
                     {
                         "description": "Datos del domicilio a actualizar",
                         "name": "body",
@@ -574,6 +579,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
+                    },
+                    "409": {
+                        "description": "El domicilio cambió durante la actualización",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
                     }
                 }
             },
@@ -659,7 +682,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/domicilios/search": {
+        "/v1/domicilios/search": {
             "get": {
                 "security": [
                     {
@@ -702,7 +725,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/incidencias": {
+        "/v1/incidencias": {
             "get": {
                 "security": [
                     {
@@ -893,7 +916,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/incidencias/search": {
+        "/v1/incidencias/search": {
             "get": {
                 "security": [
                     {
@@ -965,7 +988,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/login": {
+        "/v1/login": {
             "post": {
                 "description": "Permite iniciar sesión utilizando el documento y la contraseña, devuelve un JWT con el rol.",
                 "consumes": [
@@ -1011,7 +1034,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/metodos_pago": {
+        "/v1/metodos_pago": {
             "get": {
                 "security": [
                     {
@@ -1182,7 +1205,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/metodos_pago/search": {
+        "/v1/metodos_pago/search": {
             "get": {
                 "security": [
                     {
@@ -1225,7 +1248,123 @@ const docTemplate = `{
                 }
             }
         },
-        "/nomina_trabajador": {
+        "/v1/movimientos_stock": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve las entradas y salidas de unidades de un producto, de la más reciente a la más antigua, con su motivo (VENTA, CANCELACION, AJUSTE_PEDIDO, INVENTARIO_INICIAL, REABASTECIMIENTO, MERMA, AJUSTE_MANUAL) y el stock resultante.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "movimientos_stock"
+                ],
+                "summary": "Listar movimientos de inventario",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del producto",
+                        "name": "producto_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Movimientos obtenidos exitosamente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Producto no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ajusta las unidades de un producto. REABASTECIMIENTO suma unidades, MERMA las resta y AJUSTE_MANUAL acepta cantidades positivas o negativas. Solo aplica a productos con CONTROLA_STOCK. El producto pasa a NO DISPONIBLE al quedar sin unidades y vuelve a DISPONIBLE al reponerse.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "movimientos_stock"
+                ],
+                "summary": "Registrar un movimiento de inventario",
+                "parameters": [
+                    {
+                        "description": "Producto, cantidad y motivo",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MovimientoStockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Movimiento registrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos o motivo desconocido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El producto no tiene unidades suficientes o no controla inventario",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El producto no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/nomina_trabajador": {
             "get": {
                 "security": [
                     {
@@ -1302,6 +1441,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
+                    "422": {
+                        "description": "El trabajador indicado no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
                     "500": {
                         "description": "Error en la base de datos",
                         "schema": {
@@ -1311,7 +1456,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/nomina_trabajador/mes": {
+        "/v1/nomina_trabajador/mes": {
             "get": {
                 "security": [
                     {
@@ -1369,7 +1514,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/nomina_trabajador/search": {
+        "/v1/nomina_trabajador/search": {
             "get": {
                 "security": [
                     {
@@ -1451,7 +1596,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/nominas": {
+        "/v1/nominas": {
             "get": {
                 "security": [
                     {
@@ -1651,7 +1796,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/pagos": {
+        "/v1/pagos": {
             "get": {
                 "security": [
                     {
@@ -1750,6 +1895,13 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el pago",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
                     {
                         "description": "Datos del pago a actualizar",
                         "name": "body",
@@ -1772,6 +1924,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
+                    },
+                    "409": {
+                        "description": "El pago cambió durante la actualización",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
                     }
                 }
             },
@@ -1824,7 +1994,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Elimina un pago de la base de datos.",
+                "description": "Elimina un pago que aún no se ha cobrado. Los pagos cobrados se reembolsan cancelando el pedido.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1856,11 +2026,17 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
+                    },
+                    "409": {
+                        "description": "El pago ya se cobró o se reembolsó",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
                     }
                 }
             }
         },
-        "/pagos/search": {
+        "/v1/pagos/search": {
             "get": {
                 "security": [
                     {
@@ -1903,7 +2079,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/pedido_clientes": {
+        "/v1/pedido_clientes": {
             "get": {
                 "security": [
                     {
@@ -1980,7 +2156,7 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
-                    "404": {
+                    "422": {
                         "description": "Cliente o pedido no encontrado",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
@@ -1995,14 +2171,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/pedidos": {
+        "/v1/pedidos": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Devuelve pedidos filtrados según varios criterios: fecha, rango de fechas, usuario (cliente), tipo de método de pago, si tienen domicilio, etc.",
+                "description": "Devuelve pedidos filtrados según varios criterios: fecha, rango de fechas, usuario (cliente), tipo de método de pago, si tienen domicilio, etc. A un cliente solo se le listan sus propios pedidos.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2084,13 +2260,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Crea un nuevo pedido en el sistema sin domicilio ni pago asociados.",
+                "description": "Actualiza la hora, el tipo de entrega, el restaurante o el responsable de un pedido que no ha terminado. Si el pedido ya tiene pagos sus totales no cambian y el tipo de entrega tampoco. Requiere el ETag de la última consulta en If-Match.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2100,10 +2276,24 @@ const docTemplate = `{
                 "tags": [
                     "pedido"
                 ],
-                "summary": "Crear un nuevo pedido",
+                "summary": "Actualizar un pedido",
                 "parameters": [
                     {
-                        "description": "Datos del pedido",
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el pedido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Campos del pedido a actualizar",
                         "name": "body",
                         "in": "body",
                         "required": true,
@@ -2114,7 +2304,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Pedido creado",
+                        "description": "Pedido actualizado",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
@@ -2125,23 +2315,39 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
-                    "500": {
-                        "description": "Error al crear el pedido",
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido cambió durante la actualización, ya terminó o tiene pagos y cambia el tipo de entrega",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     }
                 }
-            }
-        },
-        "/pedidos/actualizar-estado": {
-            "put": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Actualiza el estado de un pedido existente.",
+                "description": "Crea un nuevo pedido en el sistema sin domicilio ni pago asociados.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2151,53 +2357,46 @@ const docTemplate = `{
                 "tags": [
                     "pedido"
                 ],
-                "summary": "Actualizar el estado de un pedido",
+                "summary": "Crear un nuevo pedido",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID del pedido",
-                        "name": "pedido_id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Nuevo estado del pedido",
-                        "name": "estado",
-                        "in": "query",
-                        "required": true
+                        "description": "Datos del pedido",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Pedido"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Estado actualizado",
+                        "description": "Pedido creado",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
-                    "404": {
-                        "description": "Pedido no encontrado",
+                    "400": {
+                        "description": "Datos inválidos",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
                     "500": {
-                        "description": "Error al actualizar estado del pedido",
+                        "description": "Error al crear el pedido",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     }
                 }
-            }
-        },
-        "/pedidos/asignar-domicilio": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Asigna un domicilio existente a un pedido y actualiza su estado a \"EN CAMINO\".",
+                "description": "Cancela el pedido: si ya estaba confirmado sus productos vuelven al inventario, el pago cobrado queda con un reembolso PENDIENTE y se registra el motivo. El administrador puede cancelar en cualquier estado; los clientes solo sus pedidos antes de que entren a cocina y dentro del plazo cancelacion_cliente_minutos.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2207,38 +2406,63 @@ const docTemplate = `{
                 "tags": [
                     "pedido"
                 ],
-                "summary": "Asignar un domicilio a un pedido",
+                "summary": "Cancelar un pedido",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "ID del pedido",
-                        "name": "pedido_id",
+                        "name": "id",
                         "in": "query",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "ID del domicilio",
-                        "name": "domicilio_id",
-                        "in": "query",
-                        "required": true
+                        "enum": [
+                            "CLIENTE_DESISTE",
+                            "DEMORA",
+                            "SIN_PRODUCTO",
+                            "ERROR_PEDIDO",
+                            "PAGO_RECHAZADO",
+                            "OTRO"
+                        ],
+                        "type": "string",
+                        "description": "Motivo de la cancelación (por defecto OTRO)",
+                        "name": "motivo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Explicación de la cancelación",
+                        "name": "detalle",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Domicilio asignado al pedido",
+                        "description": "Pedido cancelado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "ID o motivo inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "El cliente no puede cancelar este pedido",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
                     "404": {
-                        "description": "Pedido o domicilio no encontrado",
+                        "description": "Pedido no encontrado",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
-                    "500": {
-                        "description": "Error al asignar domicilio",
+                    "409": {
+                        "description": "El pedido ya no se puede cancelar",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
@@ -2246,14 +2470,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/pedidos/asignar-pago": {
-            "post": {
+        "/v1/pedidos/actualizar-estado": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Asigna un pago existente a un pedido y actualiza su estado a \"PAGADO\".",
+                "description": "Mueve el pedido al estado indicado siguiendo su ciclo de vida: INICIADO → (PAGADO) → EN PREPARACION → LISTO → EN CAMINO (domicilios) → ENTREGADO. CANCELADO sigue las reglas de la cancelación con el motivo OTRO. Cada cambio queda en el historial del pedido.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2263,7 +2487,7 @@ const docTemplate = `{
                 "tags": [
                     "pedido"
                 ],
-                "summary": "Asignar un pago a un pedido",
+                "summary": "Actualizar el estado de un pedido",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2273,43 +2497,218 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "ID del pago",
-                        "name": "pago_id",
+                        "enum": [
+                            "INICIADO",
+                            "PAGADO",
+                            "EN PREPARACION",
+                            "LISTO",
+                            "EN CAMINO",
+                            "ENTREGADO",
+                            "CANCELADO"
+                        ],
+                        "type": "string",
+                        "description": "Nuevo estado del pedido",
+                        "name": "estado",
                         "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Pago asignado al pedido",
+                        "description": "Estado actualizado",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
-                    "404": {
-                        "description": "Pedido o pago no encontrado",
+                    "400": {
+                        "description": "Estado de pedido inválido",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
-                    "500": {
-                        "description": "Error al asignar pago",
+                    "403": {
+                        "description": "Un cliente solo puede cancelar el pedido",
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
-                    }
-                }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transición de estado no permitida",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error al actualizar estado del pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/pedidos/asignar-domicilio": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Asigna un domicilio existente a un pedido. Si el pedido está \"LISTO\" pasa a \"EN CAMINO\", lo que solo puede hacer el personal.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pedido"
+                ],
+                "summary": "Asignar un domicilio a un pedido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "pedido_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del domicilio",
+                        "name": "domicilio_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Domicilio asignado al pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Un cliente no puede despachar el pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido ya fue entregado o cancelado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El domicilio indicado no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error al asignar domicilio",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/pedidos/asignar-pago": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Asigna un pago existente a un pedido con productos. Si el pedido está \"INICIADO\" pasa a \"PAGADO\". Un cliente solo puede cobrar los pagos a su nombre.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pedido"
+                ],
+                "summary": "Asignar un pago a un pedido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "pedido_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del pago",
+                        "name": "pago_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pago asignado al pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "El pago no está a nombre del cliente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido está cancelado o no tiene productos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El pago indicado no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error al asignar pago",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
             }
         },
-        "/pedidos/detalles": {
+        "/v1/pedidos/detalles": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Devuelve la información del pedido, tipo de pago y los productos asociados.",
+                "description": "Devuelve la información del pedido, tipo de pago, los productos asociados y el historial de cambios de estado (HISTORIAL).",
                 "consumes": [
                     "application/json"
                 ],
@@ -2356,14 +2755,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/producto_pedido": {
+        "/v1/producto_pedido": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Devuelve los productos consolidados en un pedido específico",
+                "description": "Devuelve las líneas de un pedido (producto, nombre, cantidad, precio unitario, subtotal y notas).",
                 "consumes": [
                     "application/json"
                 ],
@@ -2390,6 +2789,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
+                    "400": {
+                        "description": "El parámetro 'pedido_id' es obligatorio",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
                     "404": {
                         "description": "No se encontraron productos asociados a este pedido",
                         "schema": {
@@ -2410,7 +2815,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Permite agregar o modificar productos en un pedido consolidado",
+                "description": "Deja en un pedido que ya tiene productos exactamente las líneas enviadas y recalcula sus totales. Las líneas que no cambian (mismo producto, notas y opciones) se conservan con sus tickets de cocina; las unidades de más se agregan con el precio vigente y van a la cocina y las de menos se quitan si la cocina no las ha empezado. Solo la diferencia entra o sale del inventario.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2420,7 +2825,7 @@ const docTemplate = `{
                 "tags": [
                     "producto_pedido"
                 ],
-                "summary": "Actualizar productos en un pedido consolidado",
+                "summary": "Reemplazar los productos de un pedido",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2430,15 +2835,14 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Lista actualizada de productos",
+                        "description": "Lista actualizada de productos con su cantidad",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "object",
-                                "additionalProperties": true
+                                "$ref": "#/definitions/models.ItemPedido"
                             }
                         }
                     }
@@ -2462,6 +2866,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
+                    "409": {
+                        "description": "El pedido ya se pagó, salió de la cocina o terminó, la cocina ya empezó una línea que se quita o no hay stock suficiente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Algún producto no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
                     "500": {
                         "description": "Error interno del servidor",
                         "schema": {
@@ -2476,7 +2892,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Crea un registro de productos consolidados en un pedido",
+                "description": "Agrega productos a un pedido. Cada línea toma el nombre y el precio vigentes del producto (los precios enviados se ignoran) y el pedido recalcula sus totales.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2486,15 +2902,15 @@ const docTemplate = `{
                 "tags": [
                     "producto_pedido"
                 ],
-                "summary": "Crear un pedido con productos consolidados",
+                "summary": "Agregar productos a un pedido",
                 "parameters": [
                     {
-                        "description": "Datos del pedido con productos",
+                        "description": "Pedido y productos con su cantidad",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ProductoPedido"
+                            "$ref": "#/definitions/models.ProductoPedidoRequest"
                         }
                     }
                 ],
@@ -2511,6 +2927,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ApiResponse"
                         }
                     },
+                    "404": {
+                        "description": "Pedido de otro cliente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido ya se pagó, salió de la cocina o terminó, o no hay stock suficiente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El pedido o algún producto no existe o no está disponible",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
                     "500": {
                         "description": "Error interno del servidor",
                         "schema": {
@@ -2520,7 +2954,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/productos": {
+        "/v1/productos": {
             "get": {
                 "description": "Devuelve todos los productos registrados en la base de datos. Puedes incluir o excluir las imágenes con el parámetro ` + "`" + `includeImage` + "`" + ` y filtrar los productos activos con ` + "`" + `onlyActive` + "`" + `.",
                 "consumes": [
@@ -2629,6 +3063,37 @@ const docTemplate = `{
                         "description": "Cantidad del producto",
                         "name": "CANTIDAD",
                         "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Si los pedidos descuentan CANTIDAD; si se omite conserva el actual",
+                        "name": "CONTROLA_STOCK",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Categoría de impuesto (IMPOCONSUMO, IVA, EXENTO); si se omite conserva la actual",
+                        "name": "CATEGORIA_IMPUESTO",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Estación de la cocina que lo prepara; si se omite conserva la actual",
+                        "name": "ESTACION",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minutos de preparación; si se omite conserva el actual",
+                        "name": "TIEMPO_PREPARACION",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el producto",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -2643,6 +3108,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
+                    },
+                    "409": {
+                        "description": "El producto cambió durante la actualización",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
                     }
                 }
             },
@@ -2703,6 +3186,30 @@ const docTemplate = `{
                         "description": "Cantidad del producto",
                         "name": "CANTIDAD",
                         "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Si los pedidos descuentan CANTIDAD; por defecto false",
+                        "name": "CONTROLA_STOCK",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Categoría de impuesto (IMPOCONSUMO, IVA, EXENTO); por defecto EXENTO; el PRECIO incluye el impuesto",
+                        "name": "CATEGORIA_IMPUESTO",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Estación de la cocina que lo prepara (PARRILLA, FRITOS, BEBIDAS...); por defecto COCINA",
+                        "name": "ESTACION",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minutos de preparación; por defecto el tiempo del restaurante",
+                        "name": "TIEMPO_PREPARACION",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -2763,7 +3270,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/productos/search": {
+        "/v1/productos/search": {
             "get": {
                 "description": "Devuelve un producto específico por ID, incluyendo la imagen en formato Base64.",
                 "consumes": [
@@ -2801,7 +3308,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/reservas": {
+        "/v1/reservas": {
             "get": {
                 "description": "Devuelve todas las reservas registradas en la base de datos.",
                 "consumes": [
@@ -2852,6 +3359,13 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar la reserva",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
                     {
                         "description": "Datos de la reserva a actualizar",
                         "name": "body",
@@ -2874,6 +3388,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
+                    },
+                    "409": {
+                        "description": "La reserva cambió durante la actualización",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
                     }
                 }
             },
@@ -2952,7 +3484,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/reservas/search": {
+        "/v1/reservas/search": {
             "get": {
                 "description": "Devuelve una reserva específica por ID utilizando query parameters.",
                 "consumes": [
@@ -2990,7 +3522,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurantes": {
+        "/v1/restaurantes": {
             "get": {
                 "description": "Devuelve todos los restaurantes registrados en la base de datos.",
                 "consumes": [
@@ -3138,7 +3670,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurantes/search": {
+        "/v1/restaurantes/search": {
             "get": {
                 "description": "Devuelve un restaurante específico por ID utilizando query parameters.",
                 "consumes": [
@@ -3176,7 +3708,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/trabajadores": {
+        "/v1/trabajadores": {
             "get": {
                 "security": [
                     {
@@ -3263,6 +3795,13 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el trabajador",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
                     {
                         "description": "Datos del trabajador a actualizar",
                         "name": "body",
@@ -3285,16 +3824,34 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ApiResponse"
                         }
-                    }
-                }
-            },
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Crea un nuevo trabajador en la base de datos.",
+                    },
+                    "409": {
+                        "description": "El trabajador cambió durante la actualización",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Crea un nuevo trabajador en la base de datos.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3373,7 +3930,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/trabajadores/search": {
+        "/v1/trabajadores/search": {
             "get": {
                 "security": [
                     {
@@ -3415,355 +3972,5413 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.ApiResponse": {
-            "type": "object",
-            "properties": {
-                "cause": {
-                    "type": "string"
-                },
-                "code": {
-                    "type": "integer"
-                },
-                "data": {},
-                "message": {
-                    "type": "string"
+        },
+        "/v2/clientes/telefono": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Busca al cliente por su teléfono, con o sin +57 y con espacios o guiones, y devuelve el número en E.164, sus datos, las direcciones a las que ha pedido y sus últimos pedidos. Solo para el personal del restaurante.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 clientes"
+                ],
+                "summary": "Identificar al cliente que llama (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Teléfono desde el que llama el cliente",
+                        "name": "numero",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cliente que llama",
+                        "schema": {
+                            "$ref": "#/definitions/models.ClienteLlamada"
+                        }
+                    },
+                    "400": {
+                        "description": "Teléfono inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Solo para el personal del restaurante",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No hay un cliente con ese teléfono",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
                 }
             }
         },
-        "models.CambiosHorario": {
-            "type": "object",
-            "properties": {
-                "ABIERTO": {
-                    "type": "boolean"
-                },
-                "FECHA": {
-                    "type": "string"
-                },
-                "HORA_APERTURA": {
-                    "type": "string"
-                },
-                "HORA_CIERRE": {
-                    "type": "string"
-                },
-                "PK_ID_CAMBIO_HORARIO": {
-                    "type": "integer"
+        "/v2/clientes/telefono/pedidos": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registra el pedido de quien llama con el mismo flujo del checkout. El cliente se identifica por TELEFONO; si el número no está registrado se crea el cliente con los datos de CLIENTE y sin contraseña. Un domicilio sin teléfono queda con el número de la llamada. El pago y el domicilio quedan PENDIENTE y el pedido INICIADO hasta que se cobre. Solo para el personal del restaurante.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 clientes"
+                ],
+                "summary": "Tomar un pedido por teléfono (v2)",
+                "parameters": [
+                    {
+                        "description": "Teléfono, cliente nuevo y pedido",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PedidoTelefonicoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Pedido registrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.PedidoTelefonicoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos o faltan los datos del cliente nuevo",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Solo para el personal del restaurante",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El documento ya está registrado con otro teléfono",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Producto, método de pago o cliente inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
                 }
             }
         },
-        "models.Cliente": {
-            "type": "object",
-            "properties": {
-                "APELLIDO": {
-                    "type": "string"
-                },
-                "DIRECCION": {
-                    "type": "string"
-                },
-                "NOMBRE": {
-                    "type": "string"
-                },
-                "OBSERVACIONES": {
-                    "type": "string"
-                },
-                "PASSWORD": {
-                    "type": "string"
-                },
-                "PK_DOCUMENTO_CLIENTE": {
-                    "type": "integer"
-                },
-                "TELEFONO": {
-                    "type": "string"
+        "/v2/clientes/{documento}/favoritos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los pedidos que el cliente guardó como favoritos, con sus productos. Para pedirlos de nuevo se usa POST /v2/pedidos/{id}/repetir con el PK_ID_PEDIDO del favorito.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 clientes"
+                ],
+                "summary": "Pedidos favoritos de un cliente (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Documento del cliente",
+                        "name": "documento",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Favoritos del cliente",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PedidoFavorito"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Los favoritos son de otro cliente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Guarda como favorito un pedido anterior del cliente con un nombre opcional. Los pedidos cancelados y los que ya son favoritos responden 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 clientes"
+                ],
+                "summary": "Guardar un pedido como favorito (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Documento del cliente",
+                        "name": "documento",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pedido y nombre del favorito",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FavoritoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Favorito guardado",
+                        "schema": {
+                            "$ref": "#/definitions/models.PedidoFavorito"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los favoritos son de otro cliente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido está cancelado o ya es favorito",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El pedido no existe o no es del cliente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
                 }
             }
         },
-        "models.Domicilio": {
-            "type": "object",
-            "properties": {
-                "CREATED_AT": {
-                    "type": "string"
-                },
-                "CREATED_BY": {
-                    "type": "string"
-                },
-                "DIRECCION": {
-                    "type": "string"
-                },
-                "ENTREGADO": {
+        "/v2/clientes/{documento}/favoritos/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Quita el pedido de los favoritos del cliente; el pedido no cambia.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 clientes"
+                ],
+                "summary": "Quitar un favorito (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Documento del cliente",
+                        "name": "documento",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del favorito",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Favorito eliminado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los favoritos son de otro cliente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Favorito no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/clientes/{documento}/pedidos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los últimos pedidos del cliente, del más reciente al más antiguo, con los productos tal como se cobraron y el favorito que los guarda. Un cliente solo ve su propio historial.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 clientes"
+                ],
+                "summary": "Historial de pedidos de un cliente (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Documento del cliente",
+                        "name": "documento",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Cuántos pedidos devolver (por defecto historial_pedidos_cliente)",
+                        "name": "limite",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pedidos anteriores del cliente",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PedidoAnterior"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Límite inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "El historial es de otro cliente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Cliente no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/cocina/tickets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los tickets de la estación del más antiguo al más reciente, con sus ítems, la mesa y si el pedido es a domicilio. Sin estado devuelve los PENDIENTE y PREPARANDO.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 cocina"
+                ],
+                "summary": "Tickets de cocina (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Estación (PARRILLA, FRITOS, BEBIDAS...); por defecto todas",
+                        "name": "estacion",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "PENDIENTE",
+                            "PREPARANDO",
+                            "LISTO"
+                        ],
+                        "type": "string",
+                        "description": "Estado de los tickets",
+                        "name": "estado",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tickets obtenidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Estado desconocido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden ver la cocina",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/cocina/tickets/{id}/bump": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marca listos todos los ítems del ticket y lo saca de la pantalla de la estación. Si era el último ticket pendiente, el pedido pasa a LISTO.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 cocina"
+                ],
+                "summary": "Despachar un ticket (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del ticket",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ticket listo",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar la cocina",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ticket no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El ticket ya está listo o el pedido ya no está en preparación",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/cocina/tickets/{id}/comanda": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve la comanda del ticket para la estación, con la mesa o el domicilio, los ítems, sus modificadores y notas, en texto plano, PDF o ESC/POS.",
+                "produces": [
+                    "text/plain",
+                    "application/pdf",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "v2 cocina"
+                ],
+                "summary": "Comanda de un ticket (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del ticket",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "texto",
+                            "pdf",
+                            "escpos"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la comanda (por defecto texto)",
+                        "name": "formato",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            58,
+                            80
+                        ],
+                        "type": "integer",
+                        "description": "Ancho del papel en mm (por defecto 80)",
+                        "name": "ancho",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Comanda",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Formato o ancho inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden ver las comandas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ticket no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/cocina/tickets/{id}/items/{item}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marca el ítem como PENDIENTE, PREPARANDO o LISTO. El ticket toma el estado de sus ítems y el pedido pasa a LISTO cuando están listos todos sus tickets.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 cocina"
+                ],
+                "summary": "Cambiar el estado de un ítem (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del ticket",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del ítem del ticket",
+                        "name": "item",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cuerpo con ESTADO",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ticket actualizado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Estado desconocido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar la cocina",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ticket o ítem no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido ya no está en preparación",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/cocina/tickets/{id}/recall": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Vuelve a mostrar un ticket LISTO con sus ítems en PREPARANDO. Si el pedido ya estaba LISTO regresa a EN PREPARACION.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 cocina"
+                ],
+                "summary": "Devolver un ticket a la cocina (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del ticket",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ticket devuelto a la cocina",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar la cocina",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ticket no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El ticket no está listo o el pedido ya salió de la cocina",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/domicilios/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve un domicilio específico por ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 domicilios"
+                ],
+                "summary": "Obtener un domicilio (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del domicilio",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Domicilio encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.Domicilio"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Domicilio no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Elimina un domicilio por ID. No devuelve contenido.",
+                "tags": [
+                    "v2 domicilios"
+                ],
+                "summary": "Eliminar un domicilio (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del domicilio",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Domicilio eliminado"
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Domicilio no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/domicilios/{id}/domiciliario": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Asigna el trabajador que lleva el domicilio. El domiciliario recibe desde entonces los eventos del domicilio en /v2/eventos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 domicilios"
+                ],
+                "summary": "Asignar el domiciliario (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del domicilio",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cuerpo con PK_DOCUMENTO_DOMICILIARIO",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Domiciliario asignado",
+                        "schema": {
+                            "$ref": "#/definitions/models.Domicilio"
+                        }
+                    },
+                    "400": {
+                        "description": "Documento ausente o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden asignar domiciliarios",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Domicilio no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El trabajador no existe o está retirado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/eventos/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Abre un stream text/event-stream con los cambios de pedidos, domicilios, pagos y reservas. Cada evento lleva id, event (el TIPO) y data (el evento en JSON). Los clientes solo reciben sus pedidos y pagos, los domiciliarios sus domicilios y pedidos asignados y el resto del personal todo. El token se puede enviar en el parámetro 'token'.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "v2 eventos"
+                ],
+                "summary": "Eventos en tiempo real por SSE (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tipos separados por coma: PEDIDO, DOMICILIO, PAGO, RESERVA (todos si se omite)",
+                        "name": "tipos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token JWT cuando no se puede enviar la cabecera Authorization",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream de eventos",
+                        "schema": {
+                            "$ref": "#/definitions/models.Evento"
+                        }
+                    },
+                    "400": {
+                        "description": "Tipo de evento inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/eventos/ws": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Abre un WebSocket que envía cada evento como un mensaje JSON, con los mismos filtros por rol que /v2/eventos/stream. El token se puede enviar en el parámetro 'token'.",
+                "tags": [
+                    "v2 eventos"
+                ],
+                "summary": "Eventos en tiempo real por WebSocket (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tipos separados por coma: PEDIDO, DOMICILIO, PAGO, RESERVA (todos si se omite)",
+                        "name": "tipos",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token JWT cuando no se puede enviar la cabecera Authorization",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Conexión establecida",
+                        "schema": {
+                            "$ref": "#/definitions/models.Evento"
+                        }
+                    },
+                    "400": {
+                        "description": "Tipo de evento inválido o la solicitud no es un WebSocket",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/facturacion/documentos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve las facturas y documentos equivalentes emitidos en orden de emisión, con su CUFE o CUDE y el estado del envío.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 facturacion"
+                ],
+                "summary": "Listar documentos electrónicos (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Solo los documentos de este pedido",
+                        "name": "pedido",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Documentos obtenidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Pedido inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar la facturación",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Genera el XML UBL 2.1 del pedido pagado como FACTURA o POS (por defecto), con el consecutivo de la resolución vigente, su CUFE o CUDE y la firma XAdES, lo guarda y lo envía al proveedor tecnológico. Sin ADQUIRENTE se factura al cliente del pedido o al consumidor final. Si el proveedor no responde el documento queda GENERADO para reenviarlo.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 facturacion"
+                ],
+                "summary": "Emitir el documento electrónico de un pedido (v2)",
+                "parameters": [
+                    {
+                        "description": "Pedido y adquirente",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DocumentoElectronicoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Documento emitido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar la facturación",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido está cancelado, tiene saldo o ya tiene documento",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "No hay una resolución vigente con números disponibles",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/facturacion/documentos/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los datos de un documento emitido; el XML firmado se descarga en /xml.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 facturacion"
+                ],
+                "summary": "Obtener un documento electrónico (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del documento",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Documento obtenido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar la facturación",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Documento no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/facturacion/documentos/{id}/enviar": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Vuelve a enviar al proveedor tecnológico un documento GENERADO o RECHAZADO y guarda su respuesta.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 facturacion"
+                ],
+                "summary": "Reenviar un documento electrónico (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del documento",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Documento enviado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar la facturación",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Documento no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El documento ya fue aceptado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/facturacion/documentos/{id}/xml": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Descarga el documento UBL 2.1 firmado tal como se envió al proveedor.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "v2 facturacion"
+                ],
+                "summary": "Descargar el XML de un documento electrónico (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del documento",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "XML firmado",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar la facturación",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Documento no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/facturacion/resoluciones": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los rangos de numeración autorizados por la DIAN con el consecutivo SIGUIENTE de cada uno.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 facturacion"
+                ],
+                "summary": "Listar resoluciones de facturación (v2)",
+                "responses": {
+                    "200": {
+                        "description": "Resoluciones obtenidas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar la facturación",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registra un rango de numeración de FACTURA o POS. El primer consecutivo es DESDE; las resoluciones de factura requieren la CLAVE_TECNICA que entra en el CUFE.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 facturacion"
+                ],
+                "summary": "Registrar una resolución de facturación (v2)",
+                "parameters": [
+                    {
+                        "description": "Resolución",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ResolucionFacturacion"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Resolución registrada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Solo un administrador puede registrar resoluciones",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/facturacion/resoluciones/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cambia los datos de una resolución; con ACTIVA en false deja de asignar consecutivos. SIGUIENTE no se modifica y el rango debe incluir los números ya asignados.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 facturacion"
+                ],
+                "summary": "Actualizar una resolución de facturación (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la resolución",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resolución",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ResolucionFacturacion"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resolución actualizada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Solo un administrador puede modificar resoluciones",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Resolución no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El rango no incluye los números ya asignados",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/impresion/trabajos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los trabajos de impresión del más antiguo al más reciente con su estado, los intentos hechos y el último error.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 impresion"
+                ],
+                "summary": "Cola de impresión (v2)",
+                "parameters": [
+                    {
+                        "enum": [
+                            "PENDIENTE",
+                            "IMPRESO",
+                            "FALLIDO"
+                        ],
+                        "type": "string",
+                        "description": "Estado de los trabajos",
+                        "name": "estado",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Trabajos obtenidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Estado desconocido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar las impresoras",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Encola el RECIBO del pedido en la impresora de caja o las COMANDA de sus tickets en la impresora de cada estación, y responde sin esperar a la impresora. Los trabajos se envían por TCP (puerto 9100) en ESC/POS con reintentos; su resultado se consulta en /v2/impresion/trabajos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 impresion"
+                ],
+                "summary": "Imprimir un recibo o las comandas de un pedido (v2)",
+                "parameters": [
+                    {
+                        "description": "Trabajo de impresión",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TrabajoImpresionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Trabajos encolados",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Tipo desconocido o pedido faltante",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar las impresoras",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "No hay una impresora activa para el trabajo o el pedido no tiene comandas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/impresion/trabajos/{id}/reintentar": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Vuelve a encolar un trabajo PENDIENTE o FALLIDO, por ejemplo después de cambiar el papel o la dirección de la impresora.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 impresion"
+                ],
+                "summary": "Reintentar un trabajo de impresión (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del trabajo",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Trabajo encolado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar las impresoras",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Trabajo no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El trabajo ya se imprimió",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/impresoras": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve las impresoras de red registradas. Las que tienen ESTACION imprimen las comandas de esa estación; las demás son de caja.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 impresion"
+                ],
+                "summary": "Listar impresoras (v2)",
+                "responses": {
+                    "200": {
+                        "description": "Impresoras obtenidas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden manejar las impresoras",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registra una impresora térmica de red. Sin PUERTO usa el 9100, sin ANCHO papel de 80 mm y sin ACTIVA queda activa. CAJON indica que abre el cajón monedero al imprimir recibos. Solo para administradores.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 impresion"
+                ],
+                "summary": "Registrar una impresora (v2)",
+                "parameters": [
+                    {
+                        "description": "Impresora",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Impresora"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Impresora registrada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Solo para administradores",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/impresoras/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cambia los datos de una impresora; con ACTIVA en false deja de recibir trabajos. Solo para administradores.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 impresion"
+                ],
+                "summary": "Actualizar una impresora (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la impresora",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Impresora",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Impresora"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Impresora actualizada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Solo para administradores",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Impresora no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Elimina la impresora junto con su historial de trabajos. Solo para administradores.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 impresion"
+                ],
+                "summary": "Eliminar una impresora (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la impresora",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Impresora eliminada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Solo para administradores",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Impresora no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/impuestos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve la tarifa de cada categoría de impuesto (IMPOCONSUMO, IVA, EXENTO y las que se agreguen).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 impuestos"
+                ],
+                "summary": "Listar reglas de impuesto (v2)",
+                "responses": {
+                    "200": {
+                        "description": "Reglas de impuesto obtenidas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/impuestos/{categoria}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Fija el nombre y la tarifa (porcentaje entre 0 y 100) de la categoría. Los pedidos ya registrados conservan la tarifa con la que se cobraron. Los precios de los productos incluyen el impuesto. Solo para administradores.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 impuestos"
+                ],
+                "summary": "Crear o actualizar una regla de impuesto (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Categoría de impuesto",
+                        "name": "categoria",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nombre y tarifa",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReglaImpuesto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Regla de impuesto guardada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Solo para administradores",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/mesas": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve las mesas ordenadas por zona y número con su estado (LIBRE, OCUPADA, POR LIMPIAR).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 mesas"
+                ],
+                "summary": "Listar mesas (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Solo las mesas de la zona",
+                        "name": "zona",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mesas obtenidas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registra una mesa LIBRE con su número, capacidad y zona.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 mesas"
+                ],
+                "summary": "Crear una mesa (v2)",
+                "parameters": [
+                    {
+                        "description": "Mesa",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Mesa"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Mesa creada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden administrar las mesas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Ya existe una mesa con ese número",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/mesas/plano": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve cuántas mesas están libres, ocupadas y por limpiar, y las mesas de cada zona con sus rondas abiertas, el mesero de cada una y el total pendiente.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 mesas"
+                ],
+                "summary": "Plano del salón (v2)",
+                "responses": {
+                    "200": {
+                        "description": "Plano del salón",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/mesas/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cambia el número, la capacidad y la zona de la mesa. El estado se cambia con /estado.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 mesas"
+                ],
+                "summary": "Actualizar una mesa (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la mesa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Mesa",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Mesa"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mesa actualizada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden administrar las mesas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mesa no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Ya existe una mesa con ese número",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/mesas/{id}/estado": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marca la mesa como LIBRE, OCUPADA o POR LIMPIAR. Una mesa con pedidos abiertos sigue OCUPADA hasta que se entregan o cancelan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 mesas"
+                ],
+                "summary": "Cambiar el estado de una mesa (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la mesa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cuerpo con ESTADO",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Estado de la mesa actualizado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Estado desconocido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden cambiar el estado de las mesas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mesa no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cambio no permitido o mesa con pedidos abiertos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/mesas/{id}/mover": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pasa las rondas abiertas de la mesa a la mesa LIBRE indicada, que queda OCUPADA; la mesa de origen queda POR LIMPIAR.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 mesas"
+                ],
+                "summary": "Mover una mesa (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la mesa de origen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Mesa de destino",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TrasladoMesa"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mesa de destino con sus rondas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden mover las mesas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mesa no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "La mesa no tiene rondas abiertas o el destino no está libre",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "La mesa de destino no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/mesas/{id}/pedidos": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Crea un pedido INICIADO en la mesa a nombre del mesero que lo toma (o del PK_DOCUMENTO_MESERO enviado) y la marca OCUPADA. Una mesa puede tener varias rondas abiertas.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 mesas"
+                ],
+                "summary": "Abrir una ronda en una mesa (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la mesa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Datos del pedido",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Pedido"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Ronda abierta",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "La mesa está por limpiar o el pedido es a domicilio",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "La mesa o el mesero no existen",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/mesas/{id}/unir": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pasa las rondas abiertas de la mesa a la mesa OCUPADA indicada para atenderlas juntas; la mesa de origen queda POR LIMPIAR.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 mesas"
+                ],
+                "summary": "Unir dos mesas (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la mesa de origen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Mesa de destino",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TrasladoMesa"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mesa de destino con todas las rondas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden unir las mesas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mesa no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "La mesa no tiene rondas abiertas o el destino no está ocupado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "La mesa de destino no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/modificadores": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registra un grupo de modificadores con sus opciones para un producto. OBLIGATORIO exige al menos una opción y MAX_SELECCION en 0 no limita cuántas se eligen.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 modificadores"
+                ],
+                "summary": "Crear un grupo de modificadores (v2)",
+                "parameters": [
+                    {
+                        "description": "Grupo con PK_ID_PRODUCTO y OPCIONES",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GrupoModificador"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Grupo de modificadores creado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El producto no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/modificadores/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Elimina el grupo y sus opciones. Los pedidos ya registrados conservan las opciones elegidas. No devuelve contenido.",
+                "tags": [
+                    "v2 modificadores"
+                ],
+                "summary": "Eliminar un grupo de modificadores (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del grupo de modificadores",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Grupo eliminado"
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Grupo de modificadores no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pagos/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve un pago específico por ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pagos"
+                ],
+                "summary": "Obtener un pago (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pago",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pago encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.Pago"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pago no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Elimina un pago que aún no se ha cobrado. No devuelve contenido. Los pagos cobrados se reembolsan cancelando el pedido.",
+                "tags": [
+                    "v2 pagos"
+                ],
+                "summary": "Eliminar un pago (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pago",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Pago eliminado"
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pago no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pago ya se cobró o se reembolsó",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los pedidos filtrados por fecha, rango, mes, cliente, método de pago o domicilio. A un cliente solo se le listan sus propios pedidos. Una lista vacía responde 200.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Listar pedidos (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Fecha específica en formato YYYY-MM-DD",
+                        "name": "fecha",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha inicial del rango en formato YYYY-MM-DD",
+                        "name": "desde",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha final del rango en formato YYYY-MM-DD",
+                        "name": "hasta",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Mes del año (1-12)",
+                        "name": "mes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Año para el filtro de mes",
+                        "name": "anio",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del cliente (PK_DOCUMENTO_CLIENTE)",
+                        "name": "cliente",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tipo de método de pago (NEQUI, DAVIPLATA, EFECTIVO)",
+                        "name": "metodo_pago",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Indica si el pedido tiene domicilio (true/false)",
+                        "name": "domicilio",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pedidos obtenidos exitosamente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error al obtener los pedidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Crea un pedido en estado INICIADO a partir de un cuerpo JSON y devuelve su ubicación.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Crear un pedido (v2)",
+                "parameters": [
+                    {
+                        "description": "Datos del pedido",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Pedido"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Pedido creado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error al crear el pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/checkout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Crea en una sola transacción el pedido, sus productos, la relación con el cliente, el domicilio (si se envía DOMICILIO) y el pago por el total con el método indicado. Si algo falla no queda ningún registro. Admite la cabecera Idempotency-Key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Registrar un pedido completo (checkout)",
+                "parameters": [
+                    {
+                        "description": "Cliente, productos, domicilio y método de pago",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CheckoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Pedido registrado con todos sus datos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Un cliente solo puede hacer pedidos a su nombre",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Cliente, producto o método de pago inexistente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error al registrar el pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/programados": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los pedidos programados que todavía esperan para entrar a la cocina, del más próximo al más lejano. Solo para el personal del restaurante.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Listar los pedidos programados (v2)",
+                "responses": {
+                    "200": {
+                        "description": "Pedidos programados",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden ver los pedidos programados",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve el pedido con su método de pago, los productos asociados y el historial de cambios de estado.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Obtener un pedido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Detalles del pedido obtenidos exitosamente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Actualiza la hora, el tipo de entrega, el restaurante o el responsable de un pedido que no ha terminado. Si el pedido ya tiene pagos sus totales no cambian y el tipo de entrega tampoco. Requiere el ETag de la última consulta en If-Match.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Actualizar un pedido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el pedido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Campos del pedido a actualizar",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Pedido"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pedido actualizado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido cambió durante la actualización, ya terminó o tiene pagos y cambia el tipo de entrega",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/cancelacion": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancela el pedido con un motivo: si ya estaba confirmado sus productos vuelven al inventario (salvo que ya se hubiera entregado), cada pago cobrado queda con un reembolso PENDIENTE por el mismo método y se registra quién lo canceló. El administrador puede cancelar en cualquier estado, el resto del personal mientras el ciclo de vida lo permita y los clientes solo sus pedidos, antes de que entren a cocina y dentro del plazo cancelacion_cliente_minutos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Cancelar un pedido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Motivo (CLIENTE_DESISTE, DEMORA, SIN_PRODUCTO, ERROR_PEDIDO, PAGO_RECHAZADO, OTRO) y detalle",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CancelacionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Pedido cancelado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos o motivo desconocido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "El cliente no puede cancelar este pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido ya está cancelado o no se puede cancelar",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/cuenta": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve el total del pedido, lo cobrado, los pagos pendientes, el saldo por cobrar y los pagos con el cliente que pagó cada uno. Un cliente solo ve la cuenta de sus propios pedidos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Estado de cuenta de un pedido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cuenta del pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/division": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reparte el saldo del pedido en un pago PENDIENTE por cada parte: en partes IGUALES, POR_PRODUCTO (cada parte paga la proporción de sus líneas, con impuestos y propina) o por MONTO. Cada parte puede indicar el cliente que la paga; solo el personal puede indicar clientes que aún no están en el pedido. Los pagos se cobran con PUT /pago y el pedido pasa a PAGADO cuando quedan cubiertos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Dividir la cuenta de un pedido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Modo y partes de la división",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DivisionCuenta"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Cuenta dividida",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos o líneas sin asignar",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Un cliente indicó a otro cliente que no está en el pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido está cancelado, ya está pagado o tiene pagos pendientes",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El cliente, el método de pago o una línea no existen",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/domicilio": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Asocia un domicilio existente al pedido. Si el pedido está LISTO pasa a EN CAMINO, lo que solo puede hacer el personal. Un cliente solo asigna el domicilio de sus propios pedidos. Requiere el ETag de la última consulta en If-Match.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Asignar el domicilio de un pedido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el pedido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Cuerpo con PK_ID_DOMICILIO",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Domicilio asignado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Un cliente no puede despachar el pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido ya fue entregado o cancelado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El domicilio indicado no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/estado": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mueve el pedido al estado enviado en el cuerpo siguiendo su ciclo de vida (INICIADO, PAGADO, EN PREPARACION, LISTO, EN CAMINO, ENTREGADO, CANCELADO) y lo registra en el historial. Para cancelar con un motivo use /cancelacion. Requiere el ETag de la última consulta en If-Match.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Cambiar el estado de un pedido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el pedido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Cuerpo con ESTADO_PEDIDO",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Estado actualizado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos o estado desconocido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Un cliente solo puede cancelar el pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transición de estado no permitida",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/mesa": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pasa el pedido a la mesa indicada, que queda OCUPADA. Si el pedido no tenía mesero queda a nombre de quien lo asigna. Requiere el ETag de la última consulta en If-Match.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Asignar una mesa a un pedido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el pedido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Cuerpo con PK_ID_MESA",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mesa asignada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido ya terminó, es a domicilio o la mesa está por limpiar",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "La mesa no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/pago": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Asocia un pago existente al pedido y lo marca como PAGADO. Con la cuenta dividida el pedido tiene varios pagos y pasa a PAGADO cuando lo cobrado cubre el total, solo si está INICIADO. Un pedido sin productos no se cobra y un cliente solo puede cobrar los pagos a su nombre. Requiere el ETag de la última consulta en If-Match.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Asignar el pago de un pedido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el pedido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Cuerpo con PK_ID_PAGO",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pago asignado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "El pago no está a nombre del cliente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido está cancelado o no tiene productos, o el pago es de otro pedido o ya se reembolsó",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El pago indicado no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/programacion": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Fija la hora (DD-MM-YYYY HH:mm:ss) a la que el cliente quiere recibir o recoger el pedido. Debe quedar después de programados_anticipacion_minutos, dentro de programados_dias_maximos y en el horario del restaurante o en el cambio de horario de ese día. El pedido espera fuera de la cocina hasta esa anticipación antes de su hora, cuando entra solo a EN PREPARACION. Un PROGRAMADO_PARA vacío lo vuelve un pedido inmediato. Requiere el ETag de la última consulta en If-Match.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Programar un pedido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el pedido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Cuerpo con PROGRAMADO_PARA",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pedido programado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "El pedido es de otro cliente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido ya entró a la cocina",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "La hora está muy cerca, muy lejos o fuera del horario del restaurante",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/promocion": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registra el código de promoción del pedido y recalcula sus totales junto con las promociones automáticas. Un CODIGO_PROMOCION vacío lo quita. Requiere el ETag de la última consulta en If-Match.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Registrar un código de promoción (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el pedido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Cuerpo con CODIGO_PROMOCION",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Código de promoción registrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido ya terminó",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El código no existe o no aplica al pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/propina": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registra si el cliente acepta la propina sugerida (porcentaje propina_sugerida del valor sin el impuesto incluido en los precios) y recalcula el total. Solo aplica a pedidos sin domicilio. Requiere el ETag de la última consulta en If-Match.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Aceptar o rechazar la propina sugerida (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el pedido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Cuerpo con PROPINA_ACEPTADA",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Propina actualizada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El pedido tiene domicilio o ya terminó",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "El ETag no coincide con la versión actual",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Falta la cabecera If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/recibo": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los productos del pedido, el desglose de impuestos por categoría, la propina sugerida y si fue aceptada, el domicilio y el total. Con 'formato' devuelve el tiquete listo para imprimir: texto plano, PDF o los bytes ESC/POS de una impresora térmica. Un cliente solo ve el recibo de sus propios pedidos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain",
+                    "application/pdf",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Recibo de un pedido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "texto",
+                            "pdf",
+                            "escpos"
+                        ],
+                        "type": "string",
+                        "description": "Formato del tiquete; JSON si se omite",
+                        "name": "formato",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            58,
+                            80
+                        ],
+                        "type": "integer",
+                        "description": "Ancho del papel en mm (por defecto 80)",
+                        "name": "ancho",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recibo del pedido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "ID, formato o ancho inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/pedidos/{id}/repetir": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Crea un pedido nuevo con los productos de uno anterior, para el mismo cliente y, si era a domicilio, a la misma dirección. Los productos se cotizan con el precio vigente; los que ya no existen, no están disponibles, perdieron alguna de sus opciones o no tienen unidades suficientes quedan por fuera y se listan en NO_DISPONIBLES. Un cliente solo puede repetir sus propios pedidos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 pedidos"
+                ],
+                "summary": "Repetir un pedido anterior (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del pedido a repetir",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Pedido creado",
+                        "schema": {
+                            "$ref": "#/definitions/models.PedidoRepetido"
+                        }
+                    },
+                    "403": {
+                        "description": "El pedido es de otro cliente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Pedido no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Ninguno de los productos está disponible",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/productos/{id}/modificadores": {
+            "get": {
+                "description": "Devuelve los grupos de modificadores del producto (por ejemplo \"Término\" o \"Adiciones\") con sus opciones, si son obligatorios, el mínimo y el máximo de opciones a elegir y lo que suma cada opción al precio.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 modificadores"
+                ],
+                "summary": "Listar los modificadores de un producto (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del producto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Modificadores obtenidos exitosamente",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Producto no encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/promociones": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve las promociones configuradas. Los clientes solo ven las activas que se aplican sin código.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 promociones"
+                ],
+                "summary": "Listar promociones (v2)",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Solo las promociones activas",
+                        "name": "activas",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Promociones obtenidas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Crea una promoción PORCENTAJE, VALOR_FIJO, LLEVA_PAGA o DOMICILIO_GRATIS con su vigencia (hora de Bogotá), días de la semana, compra mínima, límites de uso y si es acumulable. Sin CODIGO se aplica sola a los pedidos que cumplan las condiciones.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 promociones"
+                ],
+                "summary": "Crear una promoción (v2)",
+                "parameters": [
+                    {
+                        "description": "Promoción",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Promocion"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Promoción creada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden administrar promociones",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El código ya está en uso",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "El producto indicado no existe",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/promociones/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reemplaza la configuración de la promoción; con ACTIVA en false deja de aplicarse. Los pedidos ya cobrados conservan su descuento.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 promociones"
+                ],
+                "summary": "Actualizar una promoción (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la promoción",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Promoción",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Promocion"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Promoción actualizada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden administrar promociones",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Promoción no encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El código ya está en uso",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/reportes/cancelaciones": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Resume las cancelaciones del rango de fechas: cantidad y valor por motivo, valor total de los pedidos cancelados, lo reembolsado y el detalle de cada cancelación.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 reportes"
+                ],
+                "summary": "Reporte de cancelaciones (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Fecha inicial en formato YYYY-MM-DD",
+                        "name": "desde",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha final en formato YYYY-MM-DD",
+                        "name": "hasta",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reporte de cancelaciones generado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Fechas inválidas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden consultar las cancelaciones",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/reportes/tiempos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compara los minutos estimados con los reales de los pedidos que entraron a la cocina en el rango de fechas: promedios, desviación (real menos estimado) y el detalle de cada pedido con la cola y la distancia usadas al estimar.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 reportes"
+                ],
+                "summary": "Reporte de tiempos de preparación y entrega (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Fecha inicial en formato YYYY-MM-DD",
+                        "name": "desde",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha final en formato YYYY-MM-DD",
+                        "name": "hasta",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reporte de tiempos generado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Fechas inválidas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden consultar los tiempos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/reportes/ventas": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Suma los pedidos no cancelados del rango de fechas: subtotal, descuentos, impuestos con su desglose por categoría, propinas, domicilios y total.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 reportes"
+                ],
+                "summary": "Reporte de ventas (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Fecha inicial en formato YYYY-MM-DD",
+                        "name": "desde",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha final en formato YYYY-MM-DD",
+                        "name": "hasta",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reporte de ventas generado",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Fechas inválidas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token no proporcionado o inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Los clientes no pueden consultar las ventas",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/trabajadores/{documento}/nominas": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve las relaciones nómina-trabajador del trabajador indicado en la ruta. Una lista vacía responde 200.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2 trabajadores"
+                ],
+                "summary": "Obtener las nóminas de un trabajador (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Documento del trabajador",
+                        "name": "documento",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Consultar solo la nómina actual",
+                        "name": "actual",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Consultar solo nóminas pagadas",
+                        "name": "pagas",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Consultar solo nóminas no pagadas",
+                        "name": "no_pagas",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Mes (1-12) para filtrar nóminas",
+                        "name": "mes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Año (YYYY) para filtrar nóminas",
+                        "name": "anio",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Nóminas del trabajador",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NominaTrabajador"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Documento inválido",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error en la base de datos",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApiResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AdquirenteRequest": {
+            "type": "object",
+            "properties": {
+                "CORREO": {
+                    "type": "string"
+                },
+                "NOMBRE": {
+                    "type": "string"
+                },
+                "NUMERO": {
+                    "type": "string"
+                },
+                "TIPO_DOCUMENTO": {
+                    "description": "13 cédula, 31 NIT, 22 cédula de extranjería",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ApiResponse": {
+            "type": "object",
+            "properties": {
+                "cause": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "integer"
+                },
+                "data": {},
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CambiosHorario": {
+            "type": "object",
+            "properties": {
+                "ABIERTO": {
+                    "type": "boolean"
+                },
+                "FECHA": {
+                    "type": "string"
+                },
+                "HORA_APERTURA": {
+                    "type": "string"
+                },
+                "HORA_CIERRE": {
+                    "type": "string"
+                },
+                "PK_ID_CAMBIO_HORARIO": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CancelacionRequest": {
+            "type": "object",
+            "properties": {
+                "DETALLE": {
+                    "type": "string"
+                },
+                "MOTIVO": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CheckoutDomicilio": {
+            "type": "object",
+            "properties": {
+                "DIRECCION": {
+                    "type": "string"
+                },
+                "DISTANCIA_KM": {
+                    "type": "number"
+                },
+                "OBSERVACIONES": {
+                    "type": "string"
+                },
+                "TELEFONO": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CheckoutRequest": {
+            "type": "object",
+            "properties": {
+                "ACEPTA_PROPINA": {
+                    "description": "ACEPTA_PROPINA suma la propina sugerida a los pedidos sin domicilio",
+                    "type": "boolean"
+                },
+                "CODIGO_PROMOCION": {
+                    "description": "CODIGO_PROMOCION es opcional; si se envía debe aplicar al pedido",
+                    "type": "string"
+                },
+                "DOMICILIO": {
+                    "$ref": "#/definitions/models.CheckoutDomicilio"
+                },
+                "HORA": {
+                    "type": "string"
+                },
+                "PK_DOCUMENTO_CLIENTE": {
+                    "type": "integer"
+                },
+                "PK_ID_METODO_PAGO": {
+                    "type": "integer"
+                },
+                "PRODUCTOS": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItemPedido"
+                    }
+                },
+                "PROGRAMADO_PARA": {
+                    "description": "PROGRAMADO_PARA (DD-MM-YYYY HH:mm:ss) programa el pedido para más tarde; vacío es para ya",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Cliente": {
+            "type": "object",
+            "properties": {
+                "APELLIDO": {
+                    "type": "string"
+                },
+                "DIRECCION": {
+                    "type": "string"
+                },
+                "NOMBRE": {
+                    "type": "string"
+                },
+                "OBSERVACIONES": {
+                    "type": "string"
+                },
+                "PASSWORD": {
+                    "type": "string"
+                },
+                "PK_DOCUMENTO_CLIENTE": {
+                    "type": "integer"
+                },
+                "TELEFONO": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ClienteLlamada": {
+            "type": "object",
+            "properties": {
+                "CLIENTE": {
+                    "$ref": "#/definitions/models.Cliente"
+                },
+                "DIRECCIONES": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "PEDIDOS": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PedidoAnterior"
+                    }
+                },
+                "TELEFONO": {
+                    "description": "Número normalizado en formato E.164",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ClienteNuevoRequest": {
+            "type": "object",
+            "properties": {
+                "APELLIDO": {
+                    "type": "string"
+                },
+                "DIRECCION": {
+                    "type": "string"
+                },
+                "NOMBRE": {
+                    "type": "string"
+                },
+                "OBSERVACIONES": {
+                    "type": "string"
+                },
+                "PK_DOCUMENTO_CLIENTE": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DivisionCuenta": {
+            "type": "object",
+            "properties": {
+                "MODO": {
+                    "type": "string"
+                },
+                "PARTES": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ParteCuenta"
+                    }
+                }
+            }
+        },
+        "models.DocumentoElectronicoRequest": {
+            "type": "object",
+            "properties": {
+                "ADQUIRENTE": {
+                    "$ref": "#/definitions/models.AdquirenteRequest"
+                },
+                "PK_ID_PEDIDO": {
+                    "type": "integer"
+                },
+                "TIPO_DOCUMENTO": {
+                    "description": "FACTURA o POS (por defecto)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Domicilio": {
+            "type": "object",
+            "properties": {
+                "CREATED_AT": {
+                    "type": "string"
+                },
+                "CREATED_BY": {
+                    "type": "string"
+                },
+                "DIRECCION": {
+                    "type": "string"
+                },
+                "DISTANCIA_KM": {
+                    "description": "DISTANCIA_KM es la distancia desde el restaurante; con ella se estima el tiempo de entrega",
+                    "type": "number"
+                },
+                "ENTREGADO": {
+                    "type": "boolean"
+                },
+                "ESTADO_PAGO": {
+                    "type": "string"
+                },
+                "FECHA": {
+                    "type": "string"
+                },
+                "OBSERVACIONES": {
+                    "type": "string"
+                },
+                "PK_DOCUMENTO_DOMICILIARIO": {
+                    "description": "PK_DOCUMENTO_DOMICILIARIO es el trabajador que lleva el domicilio",
+                    "type": "integer"
+                },
+                "PK_ID_DOMICILIO": {
+                    "type": "integer"
+                },
+                "TELEFONO": {
+                    "type": "string"
+                },
+                "UPDATED_AT": {
+                    "type": "string"
+                },
+                "UPDATED_BY": {
+                    "type": "string"
+                },
+                "VERSION": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Evento": {
+            "type": "object",
+            "properties": {
+                "ACCION": {
+                    "type": "string"
+                },
+                "DATOS": {},
+                "ESTADO": {
+                    "type": "string"
+                },
+                "ID": {
+                    "description": "Secuencia del servidor; se reenvía como Last-Event-ID",
+                    "type": "integer"
+                },
+                "PK_ID": {
+                    "type": "integer"
+                },
+                "TIPO": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FavoritoRequest": {
+            "type": "object",
+            "properties": {
+                "NOMBRE": {
+                    "description": "Por defecto \"Pedido \u003cid\u003e\"",
+                    "type": "string"
+                },
+                "PK_ID_PEDIDO": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GrupoModificador": {
+            "type": "object",
+            "properties": {
+                "MAX_SELECCION": {
+                    "description": "0 sin límite",
+                    "type": "integer"
+                },
+                "MIN_SELECCION": {
+                    "type": "integer"
+                },
+                "NOMBRE": {
+                    "type": "string"
+                },
+                "OBLIGATORIO": {
+                    "type": "boolean"
+                },
+                "OPCIONES": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OpcionModificador"
+                    }
+                },
+                "PK_ID_GRUPO_MODIFICADOR": {
+                    "type": "integer"
+                },
+                "PK_ID_PRODUCTO": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Impresora": {
+            "type": "object",
+            "properties": {
+                "ACTIVA": {
+                    "type": "boolean"
+                },
+                "ANCHO": {
+                    "description": "Milímetros del papel: 58 u 80",
+                    "type": "integer"
+                },
+                "CAJON": {
+                    "description": "CAJON indica que la impresora tiene conectado el cajón monedero y lo abre al imprimir un recibo",
+                    "type": "boolean"
+                },
+                "ESTACION": {
+                    "type": "string"
+                },
+                "HOST": {
+                    "type": "string"
+                },
+                "NOMBRE": {
+                    "type": "string"
+                },
+                "PK_ID_IMPRESORA": {
+                    "type": "integer"
+                },
+                "PUERTO": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Incidencia": {
+            "type": "object",
+            "properties": {
+                "FECHA": {
+                    "type": "string"
+                },
+                "MONTO": {
+                    "type": "integer"
+                },
+                "MOTIVO": {
+                    "type": "string"
+                },
+                "PK_DOCUMENTO_TRABAJADOR": {
+                    "type": "integer"
+                },
+                "PK_ID_INCIDENCIA": {
+                    "type": "integer"
+                },
+                "RESTA": {
                     "type": "boolean"
+                }
+            }
+        },
+        "models.ItemPedido": {
+            "type": "object",
+            "properties": {
+                "CANTIDAD": {
+                    "type": "integer"
+                },
+                "MODIFICADORES": {
+                    "description": "PK_ID_OPCION_MODIFICADOR de cada opción elegida",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "NOTAS": {
+                    "type": "string"
+                },
+                "PK_ID_PRODUCTO": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LineaModificador": {
+            "type": "object",
+            "properties": {
+                "GRUPO": {
+                    "type": "string"
+                },
+                "NOMBRE": {
+                    "type": "string"
+                },
+                "PK_ID_OPCION_MODIFICADOR": {
+                    "type": "integer"
+                },
+                "PRECIO_ADICIONAL": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LineaProducto": {
+            "type": "object",
+            "properties": {
+                "CANTIDAD": {
+                    "type": "integer"
+                },
+                "CATEGORIA_IMPUESTO": {
+                    "type": "string"
+                },
+                "DESCUENTO": {
+                    "type": "integer"
                 },
-                "ESTADO_PAGO": {
+                "IMPUESTO": {
+                    "type": "integer"
+                },
+                "MODIFICADORES": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LineaModificador"
+                    }
+                },
+                "NOMBRE": {
                     "type": "string"
                 },
-                "FECHA": {
+                "NOTAS": {
+                    "type": "string"
+                },
+                "PK_ID_PRODUCTO": {
+                    "type": "integer"
+                },
+                "PRECIO_UNITARIO": {
+                    "type": "integer"
+                },
+                "SUBTOTAL": {
+                    "type": "integer"
+                },
+                "TARIFA_IMPUESTO": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "documento": {
+                    "type": "integer"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Mesa": {
+            "type": "object",
+            "properties": {
+                "CAPACIDAD": {
+                    "type": "integer"
+                },
+                "ESTADO": {
+                    "type": "string"
+                },
+                "NUMERO": {
+                    "type": "integer"
+                },
+                "PEDIDOS": {
+                    "description": "PEDIDOS son las rondas abiertas de la mesa y TOTAL la suma de sus totales",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Pedido"
+                    }
+                },
+                "PK_ID_MESA": {
+                    "type": "integer"
+                },
+                "TOTAL": {
+                    "type": "integer"
+                },
+                "ZONA": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MetodoPago": {
+            "type": "object",
+            "properties": {
+                "DETALLE": {
+                    "type": "string"
+                },
+                "PK_ID_METODO_PAGO": {
+                    "type": "integer"
+                },
+                "TIPO": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MovimientoStockRequest": {
+            "type": "object",
+            "properties": {
+                "CANTIDAD": {
+                    "type": "integer"
+                },
+                "MOTIVO": {
                     "type": "string"
                 },
                 "OBSERVACIONES": {
                     "type": "string"
                 },
-                "PK_ID_DOMICILIO": {
+                "PK_ID_PRODUCTO": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Nomina": {
+            "type": "object",
+            "properties": {
+                "ESTADO_NOMINA": {
+                    "type": "string"
+                },
+                "FECHA": {
+                    "type": "string"
+                },
+                "MONTO": {
                     "type": "integer"
                 },
-                "TELEFONO": {
+                "PK_ID_NOMINA": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.NominaTrabajador": {
+            "type": "object",
+            "properties": {
+                "DETALLES": {
+                    "type": "string"
+                },
+                "MONTO_INCIDENCIAS": {
+                    "type": "integer"
+                },
+                "PK_DOCUMENTO_TRABAJADOR": {
+                    "type": "integer"
+                },
+                "PK_ID_NOMINA": {
+                    "type": "integer"
+                },
+                "PK_ID_NOMINA_TRABAJADOR": {
+                    "type": "integer"
+                },
+                "SUELDO_BASE": {
+                    "type": "integer"
+                },
+                "TOTAL": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.NominaTrabajadorRequest": {
+            "type": "object",
+            "properties": {
+                "DETALLES": {
+                    "type": "string",
+                    "example": "Pago correspondiente al mes de enero"
+                },
+                "PK_DOCUMENTO_TRABAJADOR": {
+                    "type": "integer",
+                    "example": 1015466494
+                }
+            }
+        },
+        "models.NominaTrabajadorResponse": {
+            "type": "object",
+            "properties": {
+                "DETALLES": {
+                    "type": "string",
+                    "example": "Pago correspondiente al mes de enero"
+                },
+                "MONTO_INCIDENCIAS": {
+                    "type": "integer",
+                    "example": 50000
+                },
+                "PK_ID_NOMINA_TRABAJADOR": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "SUELDO_BASE": {
+                    "type": "integer",
+                    "example": 2000000
+                },
+                "TOTAL": {
+                    "type": "integer",
+                    "example": 2050000
+                }
+            }
+        },
+        "models.OpcionModificador": {
+            "type": "object",
+            "properties": {
+                "NOMBRE": {
+                    "type": "string"
+                },
+                "PK_ID_GRUPO_MODIFICADOR": {
+                    "type": "integer"
+                },
+                "PK_ID_OPCION_MODIFICADOR": {
+                    "type": "integer"
+                },
+                "PRECIO_ADICIONAL": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Pago": {
+            "type": "object",
+            "properties": {
+                "ESTADO_PAGO": {
+                    "type": "string"
+                },
+                "FECHA": {
                     "type": "string"
                 },
+                "HORA": {
+                    "type": "string"
+                },
+                "MONTO": {
+                    "type": "integer"
+                },
+                "PK_ID_METODO_PAGO": {
+                    "type": "integer"
+                },
+                "PK_ID_PAGO": {
+                    "type": "integer"
+                },
+                "PK_ID_PEDIDO": {
+                    "description": "PK_ID_PEDIDO es el pedido que cubre el pago; un pedido puede tener varios pagos cuando la\ncuenta se divide y PK_ID_PEDIDO_CLIENTE indica el cliente que pagó cada parte",
+                    "type": "integer"
+                },
+                "PK_ID_PEDIDO_CLIENTE": {
+                    "type": "integer"
+                },
                 "UPDATED_AT": {
                     "type": "string"
                 },
                 "UPDATED_BY": {
                     "type": "string"
+                },
+                "VERSION": {
+                    "type": "integer"
                 }
             }
         },
-        "models.Incidencia": {
+        "models.ParteCuenta": {
+            "type": "object",
+            "properties": {
+                "DETALLES": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "MONTO": {
+                    "type": "integer"
+                },
+                "PK_DOCUMENTO_CLIENTE": {
+                    "type": "integer"
+                },
+                "PK_ID_METODO_PAGO": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Pedido": {
             "type": "object",
             "properties": {
+                "CODIGO_PROMOCION": {
+                    "description": "CODIGO_PROMOCION es el código de promoción que registró el cliente; se evalúa junto con las\npromociones automáticas cada vez que se recalculan los totales",
+                    "type": "string"
+                },
+                "DELIVERY": {
+                    "type": "boolean"
+                },
+                "DESCUENTO": {
+                    "type": "integer"
+                },
                 "FECHA": {
                     "type": "string"
                 },
-                "MONTO": {
+                "HORA": {
+                    "type": "string"
+                },
+                "IMPUESTO": {
                     "type": "integer"
                 },
-                "MOTIVO": {
+                "PK_DOCUMENTO_MESERO": {
+                    "type": "integer"
+                },
+                "PK_ID_MESA": {
+                    "description": "PK_ID_MESA es la mesa de los pedidos en el salón y PK_DOCUMENTO_MESERO el trabajador que\nlos tomó; se asignan al abrir la ronda o al cambiar el pedido de mesa",
+                    "type": "integer"
+                },
+                "PK_ID_PEDIDO": {
+                    "type": "integer"
+                },
+                "PROPINA": {
+                    "type": "integer"
+                },
+                "PROPINA_ACEPTADA": {
+                    "type": "boolean"
+                },
+                "PROPINA_SUGERIDA": {
+                    "description": "La propina sugerida se calcula para los pedidos en el restaurante y solo suma al total si\nel cliente la acepta",
+                    "type": "integer"
+                },
+                "SUBTOTAL": {
+                    "type": "integer"
+                },
+                "TOTAL": {
+                    "type": "integer"
+                },
+                "UPDATED_AT": {
                     "type": "string"
                 },
-                "PK_DOCUMENTO_TRABAJADOR": {
+                "UPDATED_BY": {
+                    "type": "string"
+                },
+                "VALOR_DOMICILIO": {
+                    "type": "integer"
+                },
+                "VERSION": {
+                    "type": "integer"
+                },
+                "estado_PEDIDO": {
+                    "type": "string"
+                },
+                "pk_ID_DOMICILIO": {
                     "type": "integer"
                 },
-                "PK_ID_INCIDENCIA": {
+                "pk_ID_PAGO": {
                     "type": "integer"
                 },
-                "RESTA": {
-                    "type": "boolean"
+                "pk_ID_RESTAURANTE": {
+                    "type": "integer"
                 }
             }
         },
-        "models.LoginRequest": {
+        "models.PedidoAnterior": {
             "type": "object",
             "properties": {
-                "documento": {
+                "PEDIDO": {
+                    "$ref": "#/definitions/models.Pedido"
+                },
+                "PK_ID_FAVORITO": {
+                    "description": "PK_ID_FAVORITO indica si el cliente guardó el pedido como favorito",
                     "type": "integer"
                 },
-                "password": {
-                    "type": "string"
+                "PRODUCTOS": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LineaProducto"
+                    }
                 }
             }
         },
-        "models.MetodoPago": {
+        "models.PedidoCliente": {
             "type": "object",
             "properties": {
-                "DETALLE": {
-                    "type": "string"
+                "PK_DOCUMENTO_CLIENTE": {
+                    "type": "integer"
                 },
-                "PK_ID_METODO_PAGO": {
+                "PK_ID_PEDIDO": {
                     "type": "integer"
                 },
-                "TIPO": {
-                    "type": "string"
+                "PK_ID_PEDIDO_CLIENTE": {
+                    "type": "integer"
                 }
             }
         },
-        "models.Nomina": {
+        "models.PedidoFavorito": {
             "type": "object",
             "properties": {
-                "ESTADO_NOMINA": {
+                "NOMBRE": {
                     "type": "string"
                 },
-                "FECHA": {
-                    "type": "string"
+                "PK_DOCUMENTO_CLIENTE": {
+                    "type": "integer"
                 },
-                "MONTO": {
+                "PK_ID_FAVORITO": {
                     "type": "integer"
                 },
-                "PK_ID_NOMINA": {
+                "PK_ID_PEDIDO": {
                     "type": "integer"
+                },
+                "PRODUCTOS": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LineaProducto"
+                    }
                 }
             }
         },
-        "models.NominaTrabajador": {
+        "models.PedidoPromocion": {
             "type": "object",
             "properties": {
-                "DETALLES": {
-                    "type": "string"
-                },
-                "MONTO_INCIDENCIAS": {
+                "DESCUENTO": {
                     "type": "integer"
                 },
-                "PK_DOCUMENTO_TRABAJADOR": {
-                    "type": "integer"
+                "NOMBRE": {
+                    "type": "string"
                 },
-                "PK_ID_NOMINA": {
+                "PK_DOCUMENTO_CLIENTE": {
                     "type": "integer"
                 },
-                "PK_ID_NOMINA_TRABAJADOR": {
+                "PK_ID_PEDIDO": {
                     "type": "integer"
                 },
-                "SUELDO_BASE": {
+                "PK_ID_PEDIDO_PROMOCION": {
                     "type": "integer"
                 },
-                "TOTAL": {
+                "PK_ID_PROMOCION": {
                     "type": "integer"
-                }
-            }
-        },
-        "models.NominaTrabajadorRequest": {
-            "type": "object",
-            "properties": {
-                "DETALLES": {
-                    "type": "string",
-                    "example": "Pago correspondiente al mes de enero"
                 },
-                "PK_DOCUMENTO_TRABAJADOR": {
-                    "type": "integer",
-                    "example": 1015466494
+                "TIPO": {
+                    "type": "string"
                 }
             }
         },
-        "models.NominaTrabajadorResponse": {
+        "models.PedidoRepetido": {
             "type": "object",
             "properties": {
-                "DETALLES": {
-                    "type": "string",
-                    "example": "Pago correspondiente al mes de enero"
+                "DOMICILIO": {
+                    "$ref": "#/definitions/models.Domicilio"
                 },
-                "MONTO_INCIDENCIAS": {
-                    "type": "integer",
-                    "example": 50000
+                "NO_DISPONIBLES": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductoNoDisponible"
+                    }
                 },
-                "PK_ID_NOMINA_TRABAJADOR": {
-                    "type": "integer",
-                    "example": 1
+                "PEDIDO": {
+                    "$ref": "#/definitions/models.Pedido"
                 },
-                "SUELDO_BASE": {
-                    "type": "integer",
-                    "example": 2000000
+                "PK_ID_PEDIDO_ORIGEN": {
+                    "type": "integer"
                 },
-                "TOTAL": {
-                    "type": "integer",
-                    "example": 2050000
+                "PRODUCTOS": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LineaProducto"
+                    }
                 }
             }
         },
-        "models.Pago": {
+        "models.PedidoTelefonicoRequest": {
             "type": "object",
             "properties": {
-                "ESTADO_PAGO": {
-                    "type": "string"
+                "ACEPTA_PROPINA": {
+                    "description": "ACEPTA_PROPINA suma la propina sugerida a los pedidos sin domicilio",
+                    "type": "boolean"
                 },
-                "FECHA": {
+                "CLIENTE": {
+                    "$ref": "#/definitions/models.ClienteNuevoRequest"
+                },
+                "CODIGO_PROMOCION": {
+                    "description": "CODIGO_PROMOCION es opcional; si se envía debe aplicar al pedido",
                     "type": "string"
                 },
+                "DOMICILIO": {
+                    "$ref": "#/definitions/models.CheckoutDomicilio"
+                },
                 "HORA": {
                     "type": "string"
                 },
-                "MONTO": {
+                "PK_DOCUMENTO_CLIENTE": {
                     "type": "integer"
                 },
                 "PK_ID_METODO_PAGO": {
                     "type": "integer"
                 },
-                "PK_ID_PAGO": {
-                    "type": "integer"
+                "PRODUCTOS": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItemPedido"
+                    }
                 },
-                "UPDATED_AT": {
+                "PROGRAMADO_PARA": {
+                    "description": "PROGRAMADO_PARA (DD-MM-YYYY HH:mm:ss) programa el pedido para más tarde; vacío es para ya",
                     "type": "string"
                 },
-                "UPDATED_BY": {
+                "TELEFONO": {
                     "type": "string"
                 }
             }
         },
-        "models.Pedido": {
+        "models.PedidoTelefonicoResponse": {
             "type": "object",
             "properties": {
-                "DELIVERY": {
+                "CLIENTE": {
+                    "$ref": "#/definitions/models.Cliente"
+                },
+                "CLIENTE_NUEVO": {
                     "type": "boolean"
                 },
-                "FECHA": {
+                "DOMICILIO": {
+                    "$ref": "#/definitions/models.Domicilio"
+                },
+                "PAGO": {
+                    "$ref": "#/definitions/models.Pago"
+                },
+                "PEDIDO": {
+                    "$ref": "#/definitions/models.Pedido"
+                },
+                "PK_ID_PEDIDO_CLIENTE": {
+                    "type": "integer"
+                },
+                "PRODUCTOS": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LineaProducto"
+                    }
+                },
+                "PROMOCIONES": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PedidoPromocion"
+                    }
+                },
+                "TOTAL": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Producto": {
+            "type": "object",
+            "properties": {
+                "CALORIAS": {
+                    "type": "integer"
+                },
+                "CANTIDAD": {
+                    "type": "integer"
+                },
+                "CATEGORIA_IMPUESTO": {
+                    "description": "CATEGORIA_IMPUESTO es la ReglaImpuesto que se aplica al venderlo",
                     "type": "string"
                 },
-                "HORA": {
+                "CONTROLA_STOCK": {
+                    "description": "CONTROLA_STOCK indica si los pedidos descuentan CANTIDAD; sin control el producto se vende\nsin importar las unidades registradas",
+                    "type": "boolean"
+                },
+                "DESCRIPCION": {
                     "type": "string"
                 },
-                "PK_ID_PAGO": {
-                    "type": "integer"
+                "ESTACION": {
+                    "description": "ESTACION es la estación de la cocina que lo prepara (PARRILLA, FRITOS, BEBIDAS...)",
+                    "type": "string"
                 },
-                "UPDATED_AT": {
+                "ESTADO_PRODUCTO": {
                     "type": "string"
                 },
-                "UPDATED_BY": {
+                "IMAGEN": {
                     "type": "string"
                 },
-                "estado_PEDIDO": {
+                "NOMBRE": {
                     "type": "string"
                 },
-                "pk_ID_DOMICILIO": {
+                "PK_ID_PRODUCTO": {
                     "type": "integer"
                 },
-                "pk_ID_PAGO": {
+                "PRECIO": {
                     "type": "integer"
                 },
-                "pk_ID_RESTAURANTE": {
+                "TIEMPO_PREPARACION": {
+                    "description": "TIEMPO_PREPARACION son los minutos que tarda la cocina en prepararlo; 0 usa el tiempo por\ndefecto del restaurante al estimar la entrega",
+                    "type": "integer"
+                },
+                "VERSION": {
                     "type": "integer"
                 }
             }
         },
-        "models.PedidoCliente": {
+        "models.ProductoNoDisponible": {
             "type": "object",
             "properties": {
-                "PK_DOCUMENTO_CLIENTE": {
+                "CANTIDAD": {
                     "type": "integer"
                 },
-                "PK_ID_PEDIDO": {
-                    "type": "integer"
+                "MOTIVO": {
+                    "type": "string"
                 },
-                "PK_ID_PEDIDO_CLIENTE": {
+                "NOMBRE": {
+                    "type": "string"
+                },
+                "PK_ID_PRODUCTO": {
                     "type": "integer"
                 }
             }
         },
-        "models.Producto": {
+        "models.ProductoPedidoRequest": {
             "type": "object",
             "properties": {
-                "CALORIAS": {
-                    "type": "integer"
+                "DETALLES_PRODUCTOS": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItemPedido"
+                    }
                 },
-                "CANTIDAD": {
+                "PK_ID_PEDIDO": {
                     "type": "integer"
+                }
+            }
+        },
+        "models.Promocion": {
+            "type": "object",
+            "properties": {
+                "ACTIVA": {
+                    "type": "boolean"
                 },
-                "DESCRIPCION": {
-                    "type": "string"
+                "ACUMULABLE": {
+                    "type": "boolean"
                 },
-                "ESTADO_PRODUCTO": {
+                "CODIGO": {
                     "type": "string"
                 },
-                "IMAGEN": {
+                "DIAS_SEMANA": {
+                    "description": "DIAS_SEMANA limita la promoción a unos días, por ejemplo \"MARTES,JUEVES\"; vacío es todos",
                     "type": "string"
                 },
+                "LIMITE_POR_CLIENTE": {
+                    "description": "0 sin límite",
+                    "type": "integer"
+                },
+                "LIMITE_USOS": {
+                    "description": "0 sin límite",
+                    "type": "integer"
+                },
+                "LLEVA": {
+                    "description": "LLEVA y PAGA definen las promociones LLEVA_PAGA: por cada LLEVA unidades se cobran PAGA",
+                    "type": "integer"
+                },
+                "MINIMO_COMPRA": {
+                    "type": "integer"
+                },
                 "NOMBRE": {
                     "type": "string"
                 },
+                "PAGA": {
+                    "type": "integer"
+                },
                 "PK_ID_PRODUCTO": {
                     "type": "integer"
                 },
-                "PRECIO": {
+                "PK_ID_PROMOCION": {
+                    "type": "integer"
+                },
+                "TIPO": {
+                    "type": "string"
+                },
+                "VALOR": {
+                    "description": "VALOR es el porcentaje (PORCENTAJE) o los pesos (VALOR_FIJO) que se descuentan",
                     "type": "integer"
+                },
+                "VIGENTE_DESDE": {
+                    "type": "string"
+                },
+                "VIGENTE_HASTA": {
+                    "type": "string"
                 }
             }
         },
-        "models.ProductoPedido": {
+        "models.ReglaImpuesto": {
             "type": "object",
             "properties": {
-                "DETALLES_PRODUCTOS": {
-                    "description": "JSONB para consolidar productos",
+                "CATEGORIA": {
                     "type": "string"
                 },
-                "PK_ID_PEDIDO": {
-                    "type": "integer"
+                "NOMBRE": {
+                    "type": "string"
                 },
-                "PK_ID_PRODUCTO_PEDIDO": {
+                "TARIFA": {
+                    "description": "Porcentaje sobre el valor antes de impuestos",
                     "type": "integer"
                 }
             }
@@ -3800,6 +9415,49 @@ const docTemplate = `{
                 },
                 "UPDATED_BY": {
                     "type": "string"
+                },
+                "VERSION": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResolucionFacturacion": {
+            "type": "object",
+            "properties": {
+                "ACTIVA": {
+                    "type": "boolean"
+                },
+                "CLAVE_TECNICA": {
+                    "description": "CLAVE_TECNICA entra en el CUFE de las facturas; la entrega la DIAN con la resolución",
+                    "type": "string"
+                },
+                "DESDE": {
+                    "type": "integer"
+                },
+                "FECHA_DESDE": {
+                    "type": "string"
+                },
+                "FECHA_HASTA": {
+                    "type": "string"
+                },
+                "HASTA": {
+                    "type": "integer"
+                },
+                "NUMERO": {
+                    "type": "string"
+                },
+                "PK_ID_RESOLUCION": {
+                    "type": "integer"
+                },
+                "PREFIJO": {
+                    "type": "string"
+                },
+                "SIGUIENTE": {
+                    "type": "integer"
+                },
+                "TIPO_DOCUMENTO": {
+                    "description": "FACTURA o POS",
+                    "type": "string"
                 }
             }
         },
@@ -3809,6 +9467,10 @@ const docTemplate = `{
                 "HORA_APERTURA": {
                     "type": "string"
                 },
+                "HORA_CIERRE": {
+                    "description": "HORA_CIERRE es opcional; sin ella el restaurante atiende hasta la medianoche",
+                    "type": "string"
+                },
                 "dias_laborales": {
                     "type": "string"
                 },
@@ -3861,6 +9523,37 @@ const docTemplate = `{
                 },
                 "TELEFONO": {
                     "type": "string"
+                },
+                "VERSION": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TrabajoImpresionRequest": {
+            "type": "object",
+            "properties": {
+                "PK_ID_IMPRESORA": {
+                    "description": "Por defecto la de caja o la de la estación",
+                    "type": "integer"
+                },
+                "PK_ID_PEDIDO": {
+                    "type": "integer"
+                },
+                "PK_ID_TICKET": {
+                    "description": "Solo la comanda de este ticket",
+                    "type": "integer"
+                },
+                "TIPO": {
+                    "description": "RECIBO o COMANDA",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TrasladoMesa": {
+            "type": "object",
+            "properties": {
+                "PK_ID_MESA_DESTINO": {
+                    "type": "integer"
                 }
             }
         }
@@ -3878,7 +9571,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "2.0.0",
 	Host:             "",
-	BasePath:         "/restaurante/v1",
+	BasePath:         "/restaurante",
 	Schemes:          []string{},
 	Title:            "Restaurante API",
 	Description:      "API para gestionar el sistema de un restaurante para \"El fogón de María\"",
@@ -0,0 +1,265 @@
+// Package facturacion genera la factura electrónica de venta y el documento equivalente
+// electrónico POS en UBL 2.1 con las extensiones de la DIAN, calcula su CUFE o CUDE y los firma
+// con XAdES para enviarlos a través de un proveedor tecnológico.
+package facturacion
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tipos de documento (InvoiceTypeCode)
+const (
+	TipoFactura = "01" // Factura electrónica de venta
+	TipoPOS     = "20" // Documento equivalente electrónico del tiquete POS
+)
+
+// Ambientes de la DIAN
+const (
+	AmbienteProduccion = "1"
+	AmbientePruebas    = "2"
+)
+
+// Códigos de los tributos que entran en el CUFE
+const (
+	TributoIVA = "01"
+	TributoICA = "03"
+	TributoINC = "04" // Impuesto nacional al consumo
+)
+
+// nombresTributo es el nombre de cada tributo en el TaxScheme
+var nombresTributo = map[string]string{
+	TributoIVA: "IVA",
+	TributoICA: "ICA",
+	TributoINC: "INC",
+}
+
+// ConsumidorFinal es el número de documento del adquirente cuando el cliente no se identifica
+const ConsumidorFinal = "222222222222"
+
+// Emisor es el restaurante que factura
+type Emisor struct {
+	NIT             string // Sin dígito de verificación
+	RazonSocial     string
+	NombreComercial string
+	Direccion       string
+	Municipio       string // Código DANE del municipio, por ejemplo 11001
+	Ciudad          string
+	Departamento    string
+	Correo          string
+	Responsabilidad string // Responsabilidad fiscal, por ejemplo R-99-PN
+}
+
+// Adquirente es el cliente al que se le factura
+type Adquirente struct {
+	TipoDocumento string // 13 cédula, 31 NIT, 22 cédula de extranjería
+	Numero        string
+	Nombre        string
+	Correo        string
+}
+
+// Resolucion es el rango de numeración autorizado por la DIAN
+type Resolucion struct {
+	Numero       string
+	Prefijo      string
+	Desde        int64
+	Hasta        int64
+	FechaDesde   time.Time
+	FechaHasta   time.Time
+	ClaveTecnica string
+}
+
+// Software identifica el software de facturación registrado ante la DIAN
+type Software struct {
+	ID           string
+	PIN          string
+	ProveedorNIT string // NIT del proveedor tecnológico, o del mismo emisor si el software es propio
+}
+
+// Impuesto es el tributo de una línea
+type Impuesto struct {
+	Codigo string
+	Tarifa int
+	Base   int64
+	Valor  int64
+}
+
+// Linea es un producto facturado. Total es el valor de la línea antes de impuestos y ya con el
+// descuento aplicado.
+type Linea struct {
+	Codigo         string
+	Descripcion    string
+	Cantidad       int
+	PrecioUnitario int64
+	Descuento      int64
+	Total          int64
+	Impuesto       *Impuesto
+}
+
+// Cargo es un valor que se cobra fuera de las líneas, como la propina o el domicilio
+type Cargo struct {
+	Motivo string
+	Valor  int64
+}
+
+// Factura reúne los datos de un documento electrónico. Total es lo que paga el cliente; si no
+// coincide con la suma de líneas, impuestos y cargos la diferencia se reporta como redondeo.
+type Factura struct {
+	Tipo       string
+	Ambiente   string
+	Numero     int64
+	Resolucion Resolucion
+	Emision    time.Time
+	Emisor     Emisor
+	Adquirente Adquirente
+	Software   Software
+	Lineas     []Linea
+	Cargos     []Cargo
+	MedioPago  string // Código DIAN del medio de pago: 10 efectivo, 48 tarjeta crédito, 49 tarjeta débito...
+	Total      int64
+}
+
+// ID es el número completo del documento: prefijo y consecutivo
+func (f *Factura) ID() string {
+	return fmt.Sprintf("%s%d", f.Resolucion.Prefijo, f.Numero)
+}
+
+// Esquema es el nombre del código único del documento: CUFE para las facturas y CUDE para los
+// documentos equivalentes
+func (f *Factura) Esquema() string {
+	if f.Tipo == TipoFactura {
+		return "CUFE-SHA384"
+	}
+	return "CUDE-SHA384"
+}
+
+// Codigo calcula el CUFE o el CUDE: SHA-384 de número, fecha, hora, valor sin impuestos, IVA,
+// INC, ICA, total, NIT del emisor, documento del adquirente, clave técnica (o PIN del software en
+// el CUDE) y ambiente
+func (f *Factura) Codigo() string {
+	t := f.totales()
+	clave := f.Resolucion.ClaveTecnica
+	if f.Tipo != TipoFactura {
+		clave = f.Software.PIN
+	}
+	cadena := f.ID() + f.fecha() + f.hora() + monto(t.lineas) +
+		TributoIVA + monto(t.porTributo[TributoIVA]) +
+		TributoINC + monto(t.porTributo[TributoINC]) +
+		TributoICA + monto(t.porTributo[TributoICA]) +
+		monto(f.Total) + f.Emisor.NIT + f.Adquirente.Numero + clave + f.Ambiente
+	return sha384(cadena)
+}
+
+// CodigoSeguridad es el SoftwareSecurityCode: SHA-384 del ID del software, su PIN y el número
+func (f *Factura) CodigoSeguridad() string {
+	return sha384(f.Software.ID + f.Software.PIN + f.ID())
+}
+
+// QR es el contenido del código QR que se imprime en la representación gráfica
+func (f *Factura) QR() string {
+	t := f.totales()
+	otros := t.impuestos - t.porTributo[TributoIVA]
+	return strings.Join([]string{
+		"NumFac: " + f.ID(),
+		"FecFac: " + f.fecha(),
+		"HorFac: " + f.hora(),
+		"NitFac: " + f.Emisor.NIT,
+		"DocAdq: " + f.Adquirente.Numero,
+		"ValFac: " + monto(t.lineas),
+		"ValIva: " + monto(t.porTributo[TributoIVA]),
+		"ValOtroIm: " + monto(otros),
+		"ValTolFac: " + monto(f.Total),
+		strings.TrimSuffix(f.Esquema(), "-SHA384") + ": " + f.Codigo(),
+		"QRCode: " + f.URLConsulta(),
+	}, "\n")
+}
+
+// URLConsulta es la dirección del documento en el catálogo de la DIAN
+func (f *Factura) URLConsulta() string {
+	catalogo := "https://catalogo-vpfe.dian.gov.co"
+	if f.Ambiente != AmbienteProduccion {
+		catalogo = "https://catalogo-vpfe-hab.dian.gov.co"
+	}
+	return catalogo + "/document/searchqr?documentkey=" + f.Codigo()
+}
+
+// totales son los valores del LegalMonetaryTotal y de los TaxTotal del documento
+type totales struct {
+	lineas     int64 // LineExtensionAmount
+	gravado    int64 // TaxExclusiveAmount: base de las líneas con impuesto
+	impuestos  int64
+	cargos     int64
+	redondeo   int64
+	porTributo map[string]int64
+	// subtotales agrupa los impuestos por tributo y tarifa, en el orden en que aparecen
+	subtotales []Impuesto
+}
+
+func (f *Factura) totales() totales {
+	t := totales{porTributo: map[string]int64{}}
+	for _, linea := range f.Lineas {
+		t.lineas += linea.Total
+		if linea.Impuesto == nil {
+			continue
+		}
+		t.gravado += linea.Impuesto.Base
+		t.impuestos += linea.Impuesto.Valor
+		t.porTributo[linea.Impuesto.Codigo] += linea.Impuesto.Valor
+		t.subtotales = sumarImpuesto(t.subtotales, *linea.Impuesto)
+	}
+	for _, cargo := range f.Cargos {
+		t.cargos += cargo.Valor
+	}
+	t.redondeo = f.Total - (t.lineas + t.impuestos + t.cargos)
+	return t
+}
+
+func sumarImpuesto(subtotales []Impuesto, impuesto Impuesto) []Impuesto {
+	for i := range subtotales {
+		if subtotales[i].Codigo == impuesto.Codigo && subtotales[i].Tarifa == impuesto.Tarifa {
+			subtotales[i].Base += impuesto.Base
+			subtotales[i].Valor += impuesto.Valor
+			return subtotales
+		}
+	}
+	return append(subtotales, impuesto)
+}
+
+func (f *Factura) fecha() string {
+	return f.Emision.Format("2006-01-02")
+}
+
+func (f *Factura) hora() string {
+	return f.Emision.Format("15:04:05-07:00")
+}
+
+// monto escribe pesos con los dos decimales que exige la DIAN
+func monto(valor int64) string {
+	return fmt.Sprintf("%d.00", valor)
+}
+
+func sha384(cadena string) string {
+	suma := sha512.Sum384([]byte(cadena))
+	return hex.EncodeToString(suma[:])
+}
+
+// DigitoVerificacion calcula el dígito de verificación de un NIT con los pesos de la DIAN
+func DigitoVerificacion(nit string) string {
+	pesos := []int{3, 7, 13, 17, 19, 23, 29, 37, 41, 43, 47, 53, 59, 67, 71}
+	suma, j := 0, 0
+	for i := len(nit) - 1; i >= 0 && j < len(pesos); i-- {
+		if nit[i] < '0' || nit[i] > '9' {
+			continue
+		}
+		suma += int(nit[i]-'0') * pesos[j]
+		j++
+	}
+	residuo := suma % 11
+	if residuo > 1 {
+		return fmt.Sprint(11 - residuo)
+	}
+	return fmt.Sprint(residuo)
+}
@@ -0,0 +1,302 @@
+package facturacion
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// Algoritmos de la firma XAdES que exige la DIAN
+const (
+	algoritmoC14N       = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315"
+	algoritmoFirma      = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algoritmoDigest     = "http://www.w3.org/2001/04/xmlenc#sha256"
+	transformEnvolvente = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	tipoSignedProps     = "http://uri.etsi.org/01903#SignedProperties"
+
+	// Política de firma de la DIAN y el SHA-256 del PDF que la publica
+	politicaFirma     = "https://facturaelectronica.dian.gov.co/politicadefirma/v2/politicadefirmav2.pdf"
+	hashPoliticaFirma = "dMoMvtcG5aIzgYo0tIsSQeVJBDnUnfSOfBpxXrmor0Y="
+)
+
+// Firmante es el certificado digital con el que se firman los documentos
+type Firmante struct {
+	Certificado *x509.Certificate
+	Clave       *rsa.PrivateKey
+}
+
+// CargarFirmante lee el certificado de un archivo PKCS#12 (.p12 o .pfx) protegido con clave, o de
+// un PEM con el certificado y la llave privada
+func CargarFirmante(ruta, clave string) (*Firmante, error) {
+	datos, err := os.ReadFile(ruta)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Contains(datos, []byte("-----BEGIN")) {
+		llave, certificado, err := pkcs12.Decode(datos, clave)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo abrir el certificado PKCS#12: %w", err)
+		}
+		rsaLlave, ok := llave.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("la llave del certificado no es RSA")
+		}
+		return &Firmante{Certificado: certificado, Clave: rsaLlave}, nil
+	}
+
+	firmante := &Firmante{}
+	for bloque, resto := pem.Decode(datos); bloque != nil; bloque, resto = pem.Decode(resto) {
+		switch bloque.Type {
+		case "CERTIFICATE":
+			if firmante.Certificado == nil {
+				if firmante.Certificado, err = x509.ParseCertificate(bloque.Bytes); err != nil {
+					return nil, err
+				}
+			}
+		case "RSA PRIVATE KEY":
+			if firmante.Clave, err = x509.ParsePKCS1PrivateKey(bloque.Bytes); err != nil {
+				return nil, err
+			}
+		case "PRIVATE KEY":
+			llave, err := x509.ParsePKCS8PrivateKey(bloque.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			var ok bool
+			if firmante.Clave, ok = llave.(*rsa.PrivateKey); !ok {
+				return nil, errors.New("la llave del certificado no es RSA")
+			}
+		}
+	}
+	if firmante.Certificado == nil || firmante.Clave == nil {
+		return nil, errors.New("el PEM debe tener el certificado y la llave privada")
+	}
+	return firmante, nil
+}
+
+// Autofirmado crea un certificado de un año firmado por sí mismo, para el ambiente de pruebas
+func Autofirmado(nombre string) (*Firmante, error) {
+	llave, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	serie, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+	ahora := time.Now()
+	plantilla := &x509.Certificate{
+		SerialNumber: serie,
+		Subject:      pkix.Name{CommonName: nombre, Country: []string{"CO"}},
+		NotBefore:    ahora.Add(-time.Hour),
+		NotAfter:     ahora.AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, plantilla, plantilla, &llave.PublicKey, llave)
+	if err != nil {
+		return nil, err
+	}
+	certificado, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &Firmante{Certificado: certificado, Clave: llave}, nil
+}
+
+// XML genera el documento firmado: la firma XAdES-EPES va en la segunda UBLExtension con tres
+// referencias (el documento sin la firma, el KeyInfo y las SignedProperties) y la política de
+// firma de la DIAN
+func (f *Factura) XML(firmante *Firmante) ([]byte, error) {
+	if firmante == nil || firmante.Certificado == nil || firmante.Clave == nil {
+		return nil, errors.New("no hay un certificado para firmar el documento")
+	}
+	raiz, contenedor := f.documento()
+	// El documento se resume antes de agregar la firma, como lo deja la transformación envolvente
+	digestDocumento := digest(raiz.canonico(true))
+
+	id := "xmldsig-" + sha384(f.ID() + f.Emision.String())[:32]
+	certificado := base64.StdEncoding.EncodeToString(firmante.Certificado.Raw)
+	huella := sha256.Sum256(firmante.Certificado.Raw)
+
+	keyInfo := elemento("ds:KeyInfo",
+		elemento("ds:X509Data", valor("ds:X509Certificate", certificado)),
+	).con("Id", id+"-keyinfo")
+	signedProps := elemento("xades:SignedProperties",
+		elemento("xades:SignedSignatureProperties",
+			valor("xades:SigningTime", f.Emision.Format("2006-01-02T15:04:05.000-07:00")),
+			elemento("xades:SigningCertificate",
+				elemento("xades:Cert",
+					elemento("xades:CertDigest",
+						valor("ds:DigestMethod", "").con("Algorithm", algoritmoDigest),
+						valor("ds:DigestValue", base64.StdEncoding.EncodeToString(huella[:])),
+					),
+					elemento("xades:IssuerSerial",
+						valor("ds:X509IssuerName", firmante.Certificado.Issuer.String()),
+						valor("ds:X509SerialNumber", firmante.Certificado.SerialNumber.String()),
+					),
+				),
+			),
+			elemento("xades:SignaturePolicyIdentifier",
+				elemento("xades:SignaturePolicyId",
+					elemento("xades:SigPolicyId", valor("xades:Identifier", politicaFirma)),
+					elemento("xades:SigPolicyHash",
+						valor("ds:DigestMethod", "").con("Algorithm", algoritmoDigest),
+						valor("ds:DigestValue", hashPoliticaFirma),
+					),
+				),
+			),
+			elemento("xades:SignerRole",
+				elemento("xades:ClaimedRoles", valor("xades:ClaimedRole", "supplier")),
+			),
+		),
+	).con("Id", id+"-signedprops")
+
+	signedInfo := elemento("ds:SignedInfo",
+		valor("ds:CanonicalizationMethod", "").con("Algorithm", algoritmoC14N),
+		valor("ds:SignatureMethod", "").con("Algorithm", algoritmoFirma),
+		referencia(id+"-ref0", "", "", digestDocumento, transformEnvolvente),
+		referencia("", "#"+id+"-keyinfo", "", digest(keyInfo.canonico(true)), ""),
+		referencia("", "#"+id+"-signedprops", tipoSignedProps, digest(signedProps.canonico(true)), ""),
+	)
+	resumen := sha256.Sum256([]byte(signedInfo.canonico(true)))
+	firma, err := rsa.SignPKCS1v15(rand.Reader, firmante.Clave, crypto.SHA256, resumen[:])
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo firmar el documento: %w", err)
+	}
+
+	contenedor.agregar(elemento("ds:Signature",
+		signedInfo,
+		valor("ds:SignatureValue", base64.StdEncoding.EncodeToString(firma)).con("Id", id+"-sigvalue"),
+		keyInfo,
+		elemento("ds:Object",
+			elemento("xades:QualifyingProperties", signedProps).con("Target", "#"+id),
+		),
+	).con("Id", id))
+	return []byte(declaracionXML + raiz.canonico(true)), nil
+}
+
+func referencia(id, uri, tipo, digestValue, transform string) *nodo {
+	ref := elemento("ds:Reference")
+	if id != "" {
+		ref.con("Id", id)
+	}
+	if tipo != "" {
+		ref.con("Type", tipo)
+	}
+	ref.con("URI", uri)
+	if transform != "" {
+		ref.agregar(elemento("ds:Transforms", valor("ds:Transform", "").con("Algorithm", transform)))
+	}
+	return ref.agregar(
+		valor("ds:DigestMethod", "").con("Algorithm", algoritmoDigest),
+		valor("ds:DigestValue", digestValue),
+	)
+}
+
+func digest(canonico string) string {
+	suma := sha256.Sum256([]byte(canonico))
+	return base64.StdEncoding.EncodeToString(suma[:])
+}
+
+// Verificar comprueba un documento firmado por este paquete: que el documento, el KeyInfo y las
+// SignedProperties no cambiaron desde la firma y que la firma corresponde al certificado incluido.
+// Devuelve el certificado del firmante.
+func Verificar(documento []byte) (*x509.Certificate, error) {
+	xml := strings.TrimPrefix(string(documento), declaracionXML)
+	inicio, fin := strings.Index(xml, "<ds:Signature "), strings.Index(xml, "</ds:Signature>")
+	if inicio < 0 || fin < inicio {
+		return nil, errors.New("el documento no está firmado")
+	}
+	firma := xml[inicio : fin+len("</ds:Signature>")]
+	sinFirma := xml[:inicio] + xml[fin+len("</ds:Signature>"):]
+
+	// Las declaraciones de la raíz son las que hereda cada nodo firmado
+	cierreRaiz := strings.Index(xml, ">")
+	declaraciones := xml[len("<Invoice"):cierreRaiz]
+	if i := strings.Index(declaraciones, " xsi:schemaLocation"); i >= 0 {
+		declaraciones = declaraciones[:i]
+	}
+	conEspacios := func(fragmento, etiqueta string) string {
+		return "<" + etiqueta + declaraciones + strings.TrimPrefix(fragmento, "<"+etiqueta)
+	}
+
+	signedInfo := fragmento(firma, "ds:SignedInfo")
+	digests := valores(signedInfo, "ds:DigestValue")
+	if len(digests) != 3 {
+		return nil, errors.New("la firma no tiene las tres referencias esperadas")
+	}
+	if digest(sinFirma) != digests[0] {
+		return nil, errors.New("el documento cambió después de firmarlo")
+	}
+	if digest(conEspacios(fragmento(firma, "ds:KeyInfo"), "ds:KeyInfo")) != digests[1] {
+		return nil, errors.New("el KeyInfo cambió después de firmarlo")
+	}
+	if digest(conEspacios(fragmento(firma, "xades:SignedProperties"), "xades:SignedProperties")) != digests[2] {
+		return nil, errors.New("las propiedades de la firma cambiaron después de firmarlo")
+	}
+
+	certificados := valores(firma, "ds:X509Certificate")
+	firmas := valores(firma, "ds:SignatureValue")
+	if len(certificados) == 0 || len(firmas) == 0 {
+		return nil, errors.New("la firma no tiene el certificado o el valor de la firma")
+	}
+	der, err := base64.StdEncoding.DecodeString(certificados[0])
+	if err != nil {
+		return nil, err
+	}
+	certificado, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	valorFirma, err := base64.StdEncoding.DecodeString(firmas[0])
+	if err != nil {
+		return nil, err
+	}
+	llave, ok := certificado.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("la llave del certificado no es RSA")
+	}
+	resumen := sha256.Sum256([]byte(conEspacios(signedInfo, "ds:SignedInfo")))
+	if err := rsa.VerifyPKCS1v15(llave, crypto.SHA256, resumen[:], valorFirma); err != nil {
+		return nil, errors.New("la firma no corresponde al certificado")
+	}
+	return certificado, nil
+}
+
+// fragmento devuelve el primer elemento con la etiqueta, completo
+func fragmento(xml, etiqueta string) string {
+	inicio := strings.Index(xml, "<"+etiqueta)
+	fin := strings.Index(xml, "</"+etiqueta+">")
+	if inicio < 0 || fin < inicio {
+		return ""
+	}
+	return xml[inicio : fin+len("</"+etiqueta+">")]
+}
+
+// valores devuelve el texto de cada elemento con la etiqueta
+func valores(xml, etiqueta string) []string {
+	encontrados := []string{}
+	for {
+		elemento := fragmento(xml, etiqueta)
+		if elemento == "" {
+			return encontrados
+		}
+		contenido := elemento[strings.Index(elemento, ">")+1 : len(elemento)-len("</"+etiqueta+">")]
+		encontrados = append(encontrados, contenido)
+		xml = xml[strings.Index(xml, elemento)+len(elemento):]
+	}
+}
@@ -0,0 +1,68 @@
+package facturacion
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Envio es un documento firmado listo para transmitirlo a la DIAN
+type Envio struct {
+	Codigo  string // CUFE o CUDE
+	Archivo string
+	XML     []byte
+}
+
+// Respuesta es el resultado de la validación del documento
+type Respuesta struct {
+	Aceptado bool
+	IDEnvio  string // Identificador que asigna el proveedor para consultar el envío
+	Mensaje  string
+}
+
+// Proveedor transmite los documentos a la DIAN, directamente o a través de un proveedor
+// tecnológico. El error se reserva para las fallas de comunicación; un documento rechazado
+// responde sin error y con Aceptado en falso.
+type Proveedor interface {
+	Enviar(envio Envio) (Respuesta, error)
+}
+
+// ProveedorLocal valida los documentos sin salir del servidor, para desarrollo y pruebas:
+// acepta los que tienen una firma válida y el mismo código que su UUID
+type ProveedorLocal struct {
+	mu        sync.Mutex
+	recibidos map[string]Envio
+}
+
+func NewProveedorLocal() *ProveedorLocal {
+	return &ProveedorLocal{recibidos: map[string]Envio{}}
+}
+
+func (p *ProveedorLocal) Enviar(envio Envio) (Respuesta, error) {
+	if _, err := Verificar(envio.XML); err != nil {
+		return Respuesta{Mensaje: "Documento rechazado: " + err.Error()}, nil
+	}
+	if uuid := valores(string(envio.XML), "cbc:UUID"); len(uuid) == 0 || uuid[0] != envio.Codigo {
+		return Respuesta{Mensaje: "Documento rechazado: el código no coincide con el UUID del documento"}, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.recibidos[envio.Codigo]; ok {
+		return Respuesta{Mensaje: "Documento rechazado: el código ya fue enviado"}, nil
+	}
+	p.recibidos[envio.Codigo] = envio
+	return Respuesta{
+		Aceptado: true,
+		IDEnvio:  fmt.Sprintf("LOCAL-%s", strings.ToUpper(envio.Codigo[:16])),
+		Mensaje:  "Documento validado por el proveedor local",
+	}, nil
+}
+
+// Recibido devuelve el documento aceptado con ese código
+func (p *ProveedorLocal) Recibido(codigo string) (Envio, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	envio, ok := p.recibidos[codigo]
+	return envio, ok
+}
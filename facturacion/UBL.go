@@ -0,0 +1,288 @@
+package facturacion
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Atributos de la DIAN como agencia de los esquemas de identificación
+const (
+	agenciaDIAN       = "195"
+	nombreAgenciaDIAN = "CO, DIAN (Dirección de Impuestos y Aduanas Nacionales)"
+)
+
+// declaracionXML encabeza el archivo; no hace parte de la forma canónica que se firma
+const declaracionXML = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n"
+
+// documento arma el árbol UBL 2.1 del documento. firma es el ExtensionContent vacío donde va
+// la firma XAdES.
+func (f *Factura) documento() (raiz, firma *nodo) {
+	t := f.totales()
+	firma = elemento("ext:ExtensionContent")
+
+	raiz = elemento("Invoice").con("xsi:schemaLocation", nsInvoice+" http://docs.oasis-open.org/ubl/os-UBL-2.1/xsd/maindoc/UBL-Invoice-2.1.xsd")
+	raiz.agregar(
+		elemento("ext:UBLExtensions",
+			elemento("ext:UBLExtension", elemento("ext:ExtensionContent", f.extensionesDIAN())),
+			elemento("ext:UBLExtension", firma),
+		),
+		valor("cbc:UBLVersionID", "UBL 2.1"),
+		valor("cbc:CustomizationID", "10"),
+		valor("cbc:ProfileID", f.perfil()),
+		valor("cbc:ProfileExecutionID", f.Ambiente),
+		valor("cbc:ID", f.ID()),
+		valor("cbc:UUID", f.Codigo()).con("schemeID", f.Ambiente, "schemeName", f.Esquema()),
+		valor("cbc:IssueDate", f.fecha()),
+		valor("cbc:IssueTime", f.hora()),
+		valor("cbc:InvoiceTypeCode", f.Tipo),
+		valor("cbc:DocumentCurrencyCode", "COP"),
+		valor("cbc:LineCountNumeric", fmt.Sprint(len(f.Lineas))),
+		elemento("cac:AccountingSupplierParty",
+			valor("cbc:AdditionalAccountID", "1"),
+			f.emisor(),
+		),
+		elemento("cac:AccountingCustomerParty",
+			valor("cbc:AdditionalAccountID", f.tipoPersonaAdquirente()),
+			f.adquirente(),
+		),
+		elemento("cac:PaymentMeans",
+			valor("cbc:ID", "1"),
+			valor("cbc:PaymentMeansCode", f.medioPago()),
+		),
+	)
+	for i, cargo := range f.Cargos {
+		raiz.agregar(elemento("cac:AllowanceCharge",
+			valor("cbc:ID", fmt.Sprint(i+1)),
+			valor("cbc:ChargeIndicator", "true"),
+			valor("cbc:AllowanceChargeReason", cargo.Motivo),
+			valor("cbc:MultiplierFactorNumeric", "100.00"),
+			pesos("cbc:Amount", cargo.Valor),
+			pesos("cbc:BaseAmount", cargo.Valor),
+		))
+	}
+	for _, codigo := range []string{TributoIVA, TributoINC, TributoICA} {
+		raiz.agregar(totalImpuesto(codigo, t.subtotales))
+	}
+
+	monetario := elemento("cac:LegalMonetaryTotal",
+		pesos("cbc:LineExtensionAmount", t.lineas),
+		pesos("cbc:TaxExclusiveAmount", t.gravado),
+		pesos("cbc:TaxInclusiveAmount", t.lineas+t.impuestos),
+		pesos("cbc:ChargeTotalAmount", t.cargos),
+	)
+	if t.redondeo != 0 {
+		monetario.agregar(pesos("cbc:PayableRoundingAmount", t.redondeo))
+	}
+	raiz.agregar(monetario.agregar(pesos("cbc:PayableAmount", f.Total)))
+
+	for i, linea := range f.Lineas {
+		raiz.agregar(f.linea(i+1, linea))
+	}
+	return raiz, firma
+}
+
+func (f *Factura) perfil() string {
+	if f.Tipo == TipoFactura {
+		return "DIAN 2.1: Factura Electrónica de Venta"
+	}
+	return "DIAN 2.1: documento equivalente electrónico del tiquete de máquina registradora con sistema P.O.S."
+}
+
+// extensionesDIAN son los datos de la resolución, el software y el QR que exige la DIAN
+func (f *Factura) extensionesDIAN() *nodo {
+	agencia := []string{"schemeAgencyID", agenciaDIAN, "schemeAgencyName", nombreAgenciaDIAN}
+	return elemento("sts:DianExtensions",
+		elemento("sts:InvoiceControl",
+			valor("sts:InvoiceAuthorization", f.Resolucion.Numero),
+			elemento("sts:AuthorizationPeriod",
+				valor("cbc:StartDate", f.Resolucion.FechaDesde.Format("2006-01-02")),
+				valor("cbc:EndDate", f.Resolucion.FechaHasta.Format("2006-01-02")),
+			),
+			elemento("sts:AuthorizedInvoices",
+				valor("sts:Prefix", f.Resolucion.Prefijo),
+				valor("sts:From", fmt.Sprint(f.Resolucion.Desde)),
+				valor("sts:To", fmt.Sprint(f.Resolucion.Hasta)),
+			),
+		),
+		elemento("sts:InvoiceSource",
+			valor("cbc:IdentificationCode", "CO").con(
+				"listAgencyID", "6",
+				"listAgencyName", "United Nations Economic Commission for Europe",
+				"listSchemeURI", "urn:oasis:names:specification:ubl:codelist:gc:CountryIdentificationCode-2.1",
+			),
+		),
+		elemento("sts:SoftwareProvider",
+			valor("sts:ProviderID", f.Software.ProveedorNIT).con(append(agencia, "schemeID", DigitoVerificacion(f.Software.ProveedorNIT), "schemeName", "31")...),
+			valor("sts:SoftwareID", f.Software.ID).con(agencia...),
+		),
+		valor("sts:SoftwareSecurityCode", f.CodigoSeguridad()).con(agencia...),
+		elemento("sts:AuthorizationProvider",
+			valor("sts:AuthorizationProviderID", "800197268").con(append(agencia, "schemeID", "4", "schemeName", "31")...),
+		),
+		valor("sts:QRCode", f.QR()),
+	)
+}
+
+func (f *Factura) emisor() *nodo {
+	nit := func() *nodo {
+		return valor("cbc:CompanyID", f.Emisor.NIT).con(
+			"schemeAgencyID", agenciaDIAN, "schemeAgencyName", nombreAgenciaDIAN,
+			"schemeID", DigitoVerificacion(f.Emisor.NIT), "schemeName", "31")
+	}
+	nombre := f.Emisor.NombreComercial
+	if nombre == "" {
+		nombre = f.Emisor.RazonSocial
+	}
+	party := elemento("cac:Party",
+		elemento("cac:PartyName", valor("cbc:Name", nombre)),
+		elemento("cac:PhysicalLocation", f.direccion("cac:Address")),
+		elemento("cac:PartyTaxScheme",
+			valor("cbc:RegistrationName", f.Emisor.RazonSocial),
+			nit(),
+			valor("cbc:TaxLevelCode", f.Emisor.Responsabilidad).con("listName", "48"),
+			f.direccion("cac:RegistrationAddress"),
+			esquemaTributario(TributoIVA, "IVA"),
+		),
+		elemento("cac:PartyLegalEntity",
+			valor("cbc:RegistrationName", f.Emisor.RazonSocial),
+			nit(),
+			elemento("cac:CorporateRegistrationScheme", valor("cbc:ID", f.Resolucion.Prefijo)),
+		),
+	)
+	if f.Emisor.Correo != "" {
+		party.agregar(elemento("cac:Contact", valor("cbc:ElectronicMail", f.Emisor.Correo)))
+	}
+	return party
+}
+
+func (f *Factura) direccion(nombre string) *nodo {
+	departamento := f.Emisor.Municipio
+	if len(departamento) > 2 {
+		departamento = departamento[:2]
+	}
+	return elemento(nombre,
+		valor("cbc:ID", f.Emisor.Municipio),
+		valor("cbc:CityName", f.Emisor.Ciudad),
+		valor("cbc:CountrySubentity", f.Emisor.Departamento),
+		valor("cbc:CountrySubentityCode", departamento),
+		elemento("cac:AddressLine", valor("cbc:Line", f.Emisor.Direccion)),
+		elemento("cac:Country",
+			valor("cbc:IdentificationCode", "CO"),
+			valor("cbc:Name", "Colombia").con("languageID", "es"),
+		),
+	)
+}
+
+// tipoPersonaAdquirente es 1 para las personas jurídicas (NIT) y 2 para las naturales
+func (f *Factura) tipoPersonaAdquirente() string {
+	if f.Adquirente.TipoDocumento == "31" {
+		return "1"
+	}
+	return "2"
+}
+
+func (f *Factura) adquirente() *nodo {
+	documento := func() *nodo {
+		id := valor("cbc:CompanyID", f.Adquirente.Numero).con("schemeAgencyID", agenciaDIAN, "schemeAgencyName", nombreAgenciaDIAN, "schemeName", f.Adquirente.TipoDocumento)
+		if f.Adquirente.TipoDocumento == "31" {
+			id.con("schemeID", DigitoVerificacion(f.Adquirente.Numero))
+		}
+		return id
+	}
+	party := elemento("cac:Party",
+		elemento("cac:PartyIdentification", valor("cbc:ID", f.Adquirente.Numero).con("schemeName", f.Adquirente.TipoDocumento)),
+		elemento("cac:PartyName", valor("cbc:Name", f.Adquirente.Nombre)),
+		elemento("cac:PartyTaxScheme",
+			valor("cbc:RegistrationName", f.Adquirente.Nombre),
+			documento(),
+			valor("cbc:TaxLevelCode", "R-99-PN").con("listName", "48"),
+			esquemaTributario("ZZ", "No aplica"),
+		),
+		elemento("cac:PartyLegalEntity",
+			valor("cbc:RegistrationName", f.Adquirente.Nombre),
+			documento(),
+		),
+	)
+	if f.Adquirente.Correo != "" {
+		party.agregar(elemento("cac:Contact", valor("cbc:ElectronicMail", f.Adquirente.Correo)))
+	}
+	return party
+}
+
+func (f *Factura) medioPago() string {
+	if f.MedioPago == "" {
+		return "10"
+	}
+	return f.MedioPago
+}
+
+func (f *Factura) linea(id int, linea Linea) *nodo {
+	n := elemento("cac:InvoiceLine",
+		valor("cbc:ID", fmt.Sprint(id)),
+		valor("cbc:InvoicedQuantity", fmt.Sprintf("%d.00", linea.Cantidad)).con("unitCode", "94"),
+		pesos("cbc:LineExtensionAmount", linea.Total),
+	)
+	if linea.Descuento > 0 {
+		n.agregar(elemento("cac:AllowanceCharge",
+			valor("cbc:ID", "1"),
+			valor("cbc:ChargeIndicator", "false"),
+			valor("cbc:AllowanceChargeReason", "Descuento"),
+			pesos("cbc:Amount", linea.Descuento),
+			pesos("cbc:BaseAmount", linea.Total+linea.Descuento),
+		))
+	}
+	if linea.Impuesto != nil {
+		n.agregar(totalImpuesto(linea.Impuesto.Codigo, []Impuesto{*linea.Impuesto}))
+	}
+	codigo := linea.Codigo
+	if codigo == "" {
+		codigo = fmt.Sprint(id)
+	}
+	return n.agregar(
+		elemento("cac:Item",
+			valor("cbc:Description", linea.Descripcion),
+			elemento("cac:StandardItemIdentification", valor("cbc:ID", codigo).con("schemeID", "999")),
+		),
+		elemento("cac:Price",
+			pesos("cbc:PriceAmount", linea.PrecioUnitario),
+			valor("cbc:BaseQuantity", "1.00").con("unitCode", "94"),
+		),
+	)
+}
+
+// totalImpuesto es el TaxTotal de un tributo con un subtotal por tarifa; nil si no hay ninguno
+func totalImpuesto(codigo string, impuestos []Impuesto) *nodo {
+	var total int64
+	subtotales := []*nodo{}
+	for _, impuesto := range impuestos {
+		if impuesto.Codigo != codigo {
+			continue
+		}
+		total += impuesto.Valor
+		subtotales = append(subtotales, elemento("cac:TaxSubtotal",
+			pesos("cbc:TaxableAmount", impuesto.Base),
+			pesos("cbc:TaxAmount", impuesto.Valor),
+			elemento("cac:TaxCategory",
+				valor("cbc:Percent", fmt.Sprintf("%d.00", impuesto.Tarifa)),
+				esquemaTributario(codigo, nombresTributo[codigo]),
+			),
+		))
+	}
+	if len(subtotales) == 0 {
+		return nil
+	}
+	return elemento("cac:TaxTotal", append([]*nodo{pesos("cbc:TaxAmount", total)}, subtotales...)...)
+}
+
+func esquemaTributario(codigo, nombre string) *nodo {
+	return elemento("cac:TaxScheme", valor("cbc:ID", codigo), valor("cbc:Name", nombre))
+}
+
+func pesos(nombre string, v int64) *nodo {
+	return valor(nombre, monto(v)).con("currencyID", "COP")
+}
+
+// NombreArchivo es el nombre con que se guarda o descarga el XML del documento
+func (f *Factura) NombreArchivo() string {
+	return strings.ToLower(f.ID()) + ".xml"
+}
@@ -0,0 +1,133 @@
+package facturacion
+
+import (
+	"sort"
+	"strings"
+)
+
+// Espacios de nombres del documento UBL con las extensiones de la DIAN y la firma XAdES
+const (
+	nsInvoice  = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	nsCAC      = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	nsCBC      = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+	nsEXT      = "urn:oasis:names:specification:ubl:schema:xsd:CommonExtensionComponents-2"
+	nsSTS      = "dian:gov:co:facturaelectronica:Structures-2-1"
+	nsDS       = "http://www.w3.org/2000/09/xmldsig#"
+	nsXAdES    = "http://uri.etsi.org/01903/v1.3.2#"
+	nsXAdES141 = "http://uri.etsi.org/01903/v1.4.1#"
+	nsXSI      = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// espacios son los prefijos que se declaran en la raíz del documento. Todos se declaran ahí, y
+// solo ahí, para que el documento ya salga en su forma canónica (C14N inclusiva): los nodos que
+// se firman por separado heredan exactamente estas declaraciones.
+var espacios = map[string]string{
+	"":         nsInvoice,
+	"cac":      nsCAC,
+	"cbc":      nsCBC,
+	"ds":       nsDS,
+	"ext":      nsEXT,
+	"sts":      nsSTS,
+	"xades":    nsXAdES,
+	"xades141": nsXAdES141,
+	"xsi":      nsXSI,
+}
+
+// nodo es un elemento XML que se escribe directamente en forma canónica: sin elementos vacíos
+// abreviados, con los atributos ordenados y con el escape de C14N
+type nodo struct {
+	nombre    string
+	atributos [][2]string
+	texto     string
+	hijos     []*nodo
+}
+
+func elemento(nombre string, hijos ...*nodo) *nodo {
+	return &nodo{nombre: nombre, hijos: hijos}
+}
+
+func valor(nombre, texto string) *nodo {
+	return &nodo{nombre: nombre, texto: texto}
+}
+
+// con agrega atributos en pares nombre, valor
+func (n *nodo) con(pares ...string) *nodo {
+	for i := 0; i+1 < len(pares); i += 2 {
+		n.atributos = append(n.atributos, [2]string{pares[i], pares[i+1]})
+	}
+	return n
+}
+
+func (n *nodo) agregar(hijos ...*nodo) *nodo {
+	for _, hijo := range hijos {
+		if hijo != nil {
+			n.hijos = append(n.hijos, hijo)
+		}
+	}
+	return n
+}
+
+// canonico escribe el nodo; si declarar es verdadero lleva las declaraciones de espacios de nombres
+// de la raíz, como en la raíz del documento o en un nodo canonicalizado fuera de él
+func (n *nodo) canonico(declarar bool) string {
+	var b strings.Builder
+	n.escribir(&b, declarar)
+	return b.String()
+}
+
+func (n *nodo) escribir(b *strings.Builder, declarar bool) {
+	b.WriteString("<" + n.nombre)
+	if declarar {
+		prefijos := make([]string, 0, len(espacios))
+		for prefijo := range espacios {
+			prefijos = append(prefijos, prefijo)
+		}
+		sort.Strings(prefijos)
+		for _, prefijo := range prefijos {
+			if prefijo == "" {
+				b.WriteString(` xmlns="` + escaparAtributo(espacios[prefijo]) + `"`)
+			} else {
+				b.WriteString(" xmlns:" + prefijo + `="` + escaparAtributo(espacios[prefijo]) + `"`)
+			}
+		}
+	}
+	for _, atributo := range ordenarAtributos(n.atributos) {
+		b.WriteString(" " + atributo[0] + `="` + escaparAtributo(atributo[1]) + `"`)
+	}
+	b.WriteString(">")
+	b.WriteString(escaparTexto(n.texto))
+	for _, hijo := range n.hijos {
+		hijo.escribir(b, false)
+	}
+	b.WriteString("</" + n.nombre + ">")
+}
+
+// ordenarAtributos aplica el orden de C14N: primero los atributos sin prefijo por nombre y luego
+// los calificados por espacio de nombres y nombre local
+func ordenarAtributos(atributos [][2]string) [][2]string {
+	ordenados := append([][2]string(nil), atributos...)
+	clave := func(nombre string) (string, string) {
+		prefijo, local, ok := strings.Cut(nombre, ":")
+		if !ok {
+			return "", nombre
+		}
+		return espacios[prefijo], local
+	}
+	sort.SliceStable(ordenados, func(i, j int) bool {
+		nsI, localI := clave(ordenados[i][0])
+		nsJ, localJ := clave(ordenados[j][0])
+		if nsI != nsJ {
+			return nsI < nsJ
+		}
+		return localI < localJ
+	})
+	return ordenados
+}
+
+func escaparTexto(texto string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;").Replace(texto)
+}
+
+func escaparAtributo(texto string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;").Replace(texto)
+}
@@ -0,0 +1,169 @@
+// Package impresion arma los tiquetes y comandas del restaurante y los convierte a texto plano,
+// PDF o comandos ESC/POS para las impresoras térmicas de 58 y 80 mm.
+package impresion
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Anchos de papel soportados, en milímetros
+const (
+	Ancho58 = 58
+	Ancho80 = 80
+)
+
+// Alineacion de una línea dentro del ancho del papel
+type Alineacion int
+
+const (
+	Izquierda Alineacion = iota
+	Centro
+	Derecha
+)
+
+// Linea es un renglón del documento. Las líneas dobles se imprimen al doble de alto y de ancho,
+// así que en ellas cabe la mitad de caracteres.
+type Linea struct {
+	Texto      string
+	Alineacion Alineacion
+	Negrita    bool
+	Doble      bool
+}
+
+// Documento es un tiquete o una comanda armado renglón por renglón, independiente del formato en
+// que se va a imprimir. Cortar y AbrirCajon solo tienen efecto en ESC/POS.
+type Documento struct {
+	Ancho      int
+	Lineas     []Linea
+	Cortar     bool
+	AbrirCajon bool
+}
+
+// Nuevo crea un documento vacío para el ancho de papel indicado; un ancho no soportado usa 80 mm
+func Nuevo(ancho int) *Documento {
+	if !AnchoValido(ancho) {
+		ancho = Ancho80
+	}
+	return &Documento{Ancho: ancho, Cortar: true}
+}
+
+// AnchoValido indica si la impresora puede usar papel de ese ancho
+func AnchoValido(ancho int) bool {
+	return ancho == Ancho58 || ancho == Ancho80
+}
+
+// Columnas es cuántos caracteres de la fuente normal caben en un renglón
+func (d *Documento) Columnas() int {
+	if d.Ancho == Ancho58 {
+		return 32
+	}
+	return 48
+}
+
+// Titulo agrega un renglón centrado al doble de tamaño
+func (d *Documento) Titulo(texto string) {
+	d.Lineas = append(d.Lineas, Linea{Texto: texto, Alineacion: Centro, Negrita: true, Doble: true})
+}
+
+// Centrado agrega un renglón centrado
+func (d *Documento) Centrado(texto string) {
+	d.Lineas = append(d.Lineas, Linea{Texto: texto, Alineacion: Centro})
+}
+
+// Texto agrega uno o varios renglones alineados a la izquierda; los textos largos se parten por palabras
+func (d *Documento) Texto(texto string, negrita bool) {
+	for _, renglon := range partir(texto, d.Columnas()) {
+		d.Lineas = append(d.Lineas, Linea{Texto: renglon, Negrita: negrita})
+	}
+}
+
+// Par agrega un renglón con la etiqueta a la izquierda y el valor a la derecha, como los totales
+func (d *Documento) Par(etiqueta, valor string, negrita bool) {
+	columnas := d.Columnas()
+	espacio := columnas - largo(valor) - 1
+	renglones := partir(etiqueta, espacio)
+	for _, renglon := range renglones[:len(renglones)-1] {
+		d.Lineas = append(d.Lineas, Linea{Texto: renglon, Negrita: negrita})
+	}
+	ultimo := renglones[len(renglones)-1]
+	relleno := max(columnas-largo(ultimo)-largo(valor), 1)
+	d.Lineas = append(d.Lineas, Linea{Texto: ultimo + strings.Repeat(" ", relleno) + valor, Negrita: negrita})
+}
+
+// Separador agrega una raya de lado a lado
+func (d *Documento) Separador() {
+	d.Lineas = append(d.Lineas, Linea{Texto: strings.Repeat("-", d.Columnas())})
+}
+
+// Blanco agrega un renglón vacío
+func (d *Documento) Blanco() {
+	d.Lineas = append(d.Lineas, Linea{})
+}
+
+// columnasLinea es cuántos caracteres caben en la línea según su tamaño
+func (d *Documento) columnasLinea(linea Linea) int {
+	if linea.Doble {
+		return d.Columnas() / 2
+	}
+	return d.Columnas()
+}
+
+// alinear rellena con espacios el texto de la línea para ubicarlo en el renglón; los formatos sin
+// comandos de alineación (texto y PDF) imprimen el resultado tal cual
+func (d *Documento) alinear(linea Linea) string {
+	columnas := d.columnasLinea(linea)
+	texto := recortar(linea.Texto, columnas)
+	switch linea.Alineacion {
+	case Centro:
+		return strings.Repeat(" ", (columnas-largo(texto))/2) + texto
+	case Derecha:
+		return strings.Repeat(" ", columnas-largo(texto)) + texto
+	default:
+		return texto
+	}
+}
+
+// partir divide el texto en renglones de a lo sumo columnas caracteres sin cortar palabras, salvo
+// las que no caben solas
+func partir(texto string, columnas int) []string {
+	if columnas < 1 {
+		columnas = 1
+	}
+	renglones := []string{}
+	actual := ""
+	for _, palabra := range strings.Fields(texto) {
+		for largo(palabra) > columnas {
+			if actual != "" {
+				renglones = append(renglones, actual)
+				actual = ""
+			}
+			corte := recortar(palabra, columnas)
+			renglones = append(renglones, corte)
+			palabra = palabra[len(corte):]
+		}
+		switch {
+		case actual == "":
+			actual = palabra
+		case largo(actual)+1+largo(palabra) <= columnas:
+			actual += " " + palabra
+		default:
+			renglones = append(renglones, actual)
+			actual = palabra
+		}
+	}
+	return append(renglones, actual)
+}
+
+// recortar deja los primeros columnas caracteres del texto
+func recortar(texto string, columnas int) string {
+	if largo(texto) <= columnas {
+		return texto
+	}
+	runas := []rune(texto)
+	return string(runas[:columnas])
+}
+
+func largo(texto string) int {
+	return utf8.RuneCountInString(texto)
+}
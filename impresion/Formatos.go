@@ -0,0 +1,184 @@
+package impresion
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Formatos de salida de un documento
+const (
+	FormatoTexto  = "texto"
+	FormatoPDF    = "pdf"
+	FormatoEscPos = "escpos"
+)
+
+// Formatos son los formatos que se pueden pedir al renderizar
+var Formatos = []string{FormatoTexto, FormatoPDF, FormatoEscPos}
+
+// Renderizar convierte el documento al formato indicado y devuelve también su Content-Type. El
+// segundo valor es falso si el formato no existe.
+func Renderizar(d *Documento, formato string) ([]byte, string, bool) {
+	switch strings.ToLower(formato) {
+	case FormatoTexto:
+		return Texto(d), "text/plain; charset=utf-8", true
+	case FormatoPDF:
+		return PDF(d), "application/pdf", true
+	case FormatoEscPos:
+		return EscPos(d), "application/octet-stream", true
+	}
+	return nil, "", false
+}
+
+// Texto devuelve el documento como texto plano en UTF-8, un renglón por línea
+func Texto(d *Documento) []byte {
+	var b bytes.Buffer
+	for _, linea := range d.Lineas {
+		b.WriteString(strings.TrimRight(d.alinear(linea), " "))
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// Comandos ESC/POS
+var (
+	escInicializar = []byte{0x1B, '@'}
+	// Tabla de caracteres WPC1252, que incluye las tildes y la eñe
+	escTablaLatina = []byte{0x1B, 't', 16}
+	escAlinear     = []byte{0x1B, 'a'}
+	escNegrita     = []byte{0x1B, 'E'}
+	gsTamano       = []byte{0x1D, '!'}
+	// Avanza el papel hasta la cuchilla y hace un corte parcial
+	gsCortar = []byte{0x1D, 'V', 66, 0}
+	// Pulso de 50 ms / 500 ms en el pin 2 del conector del cajón monedero
+	escAbrirCajon = []byte{0x1B, 'p', 0, 25, 250}
+)
+
+// EscPos devuelve los bytes que entiende una impresora térmica ESC/POS: inicialización, cada línea
+// con su alineación, negrita y tamaño, el corte del papel y la apertura del cajón si se pidieron
+func EscPos(d *Documento) []byte {
+	var b bytes.Buffer
+	b.Write(escInicializar)
+	b.Write(escTablaLatina)
+	for _, linea := range d.Lineas {
+		b.Write(escAlinear)
+		b.WriteByte(byte(linea.Alineacion))
+		b.Write(escNegrita)
+		b.WriteByte(bit(linea.Negrita))
+		b.Write(gsTamano)
+		if linea.Doble {
+			b.WriteByte(0x11)
+		} else {
+			b.WriteByte(0x00)
+		}
+		b.Write(latin1(recortar(linea.Texto, d.columnasLinea(linea))))
+		b.WriteByte('\n')
+	}
+	// Restablece el formato para que el siguiente trabajo no lo herede
+	b.Write(escInicializar)
+	if d.Cortar {
+		b.Write(gsCortar)
+	}
+	if d.AbrirCajon {
+		b.Write(escAbrirCajon)
+	}
+	return b.Bytes()
+}
+
+func bit(valor bool) byte {
+	if valor {
+		return 1
+	}
+	return 0
+}
+
+// latin1 codifica el texto en Windows-1252; los caracteres que no existen ahí se imprimen como '?'
+func latin1(texto string) []byte {
+	salida := make([]byte, 0, len(texto))
+	for _, r := range texto {
+		if r < 0x100 {
+			salida = append(salida, byte(r))
+		} else {
+			salida = append(salida, '?')
+		}
+	}
+	return salida
+}
+
+// Medidas del PDF en puntos (1 mm = 2,835 pt)
+const (
+	puntosPorMm = 72 / 25.4
+	margenPDF   = 8.0
+	// El ancho de un carácter de Courier es 0,6 veces el tamaño de la fuente
+	anchoCourier = 0.6
+)
+
+// PDF devuelve el documento como un PDF de una sola página del ancho del rollo y el alto que ocupen
+// sus líneas, en Courier para conservar las columnas del tiquete
+func PDF(d *Documento) []byte {
+	ancho := float64(d.Ancho) * puntosPorMm
+	tamano := (ancho - 2*margenPDF) / (float64(d.Columnas()) * anchoCourier)
+	interlineado := tamano * 1.25
+
+	alto := 2 * margenPDF
+	for _, linea := range d.Lineas {
+		alto += interlineado * escala(linea)
+	}
+
+	var contenido bytes.Buffer
+	contenido.WriteString("BT\n")
+	y := alto - margenPDF
+	for _, linea := range d.Lineas {
+		y -= interlineado * escala(linea)
+		fuente := "/F1"
+		if linea.Negrita {
+			fuente = "/F2"
+		}
+		fmt.Fprintf(&contenido, "%s %.2f Tf 1 0 0 1 %.2f %.2f Tm (%s) Tj\n",
+			fuente, tamano*escala(linea), margenPDF, y+interlineado*0.2, textoPDF(d.alinear(linea)))
+	}
+	contenido.WriteString("ET\n")
+
+	objetos := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", ancho, alto),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", contenido.Len(), contenido.String()),
+	}
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	posiciones := make([]int, len(objetos))
+	for i, objeto := range objetos {
+		posiciones[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, objeto)
+	}
+	inicioXref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objetos)+1)
+	for _, posicion := range posiciones {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", posicion)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objetos)+1, inicioXref)
+	return pdf.Bytes()
+}
+
+func escala(linea Linea) float64 {
+	if linea.Doble {
+		return 2
+	}
+	return 1
+}
+
+// textoPDF codifica el texto en WinAnsi y escapa los caracteres especiales de las cadenas del PDF
+func textoPDF(texto string) string {
+	var b strings.Builder
+	for _, c := range latin1(texto) {
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
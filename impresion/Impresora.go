@@ -0,0 +1,54 @@
+package impresion
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// PuertoRaw es el puerto en el que las impresoras de red reciben los bytes sin procesar (JetDirect)
+const PuertoRaw = 9100
+
+// Impresora envía documentos a una impresora de red por TCP. Cada intento abre una conexión nueva;
+// entre intentos espera Espera, que se duplica en cada reintento.
+type Impresora struct {
+	Direccion string // host:puerto
+	Intentos  int
+	Espera    time.Duration
+	Timeout   time.Duration
+}
+
+// Enviar escribe los datos en la impresora reintentando si la conexión o la escritura fallan.
+// Devuelve cuántos intentos hizo y el error del último si ninguno funcionó.
+func (i Impresora) Enviar(datos []byte) (int, error) {
+	intentos := max(i.Intentos, 1)
+	espera := i.Espera
+	var err error
+	for intento := 1; intento <= intentos; intento++ {
+		if err = i.enviar(datos); err == nil {
+			return intento, nil
+		}
+		if intento < intentos {
+			time.Sleep(espera)
+			espera *= 2
+		}
+	}
+	return intentos, fmt.Errorf("la impresora %s no respondió después de %d intentos: %w", i.Direccion, intentos, err)
+}
+
+func (i Impresora) enviar(datos []byte) error {
+	timeout := i.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	conn, err := net.DialTimeout("tcp", i.Direccion, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	_, err = conn.Write(datos)
+	return err
+}
@@ -2,9 +2,12 @@ package main
 
 import (
 	"fmt"
+	"restaurante/controllers"
 	"restaurante/database"
 	_ "restaurante/docs"
+	"restaurante/repositories"
 	_ "restaurante/routers"
+	"restaurante/services"
 	"time"
 
 	"github.com/beego/beego/v2/client/orm"
@@ -18,6 +21,7 @@ func init() {
 	// Inicializar la base de datos y la zona horaria
 	database.InitDB()
 	database.InitTimezone()
+	database.RunMigrations()
 	fmt.Println("Loaded timezone:", database.BogotaZone)
 }
 
@@ -45,11 +49,52 @@ func generarNominaAutomatica() {
 	}
 }
 
+// Función para enviar a la cocina los pedidos programados cuando llega su hora de liberación
+func liberarPedidosProgramados() {
+	pedidos := services.NewPedidoService(repositories.NewOrmStore(orm.NewOrm()))
+
+	for {
+		liberados, err := pedidos.LiberarProgramados(time.Now().In(database.BogotaZone))
+		if err != nil {
+			fmt.Println("Error al liberar los pedidos programados:", err)
+		}
+		for _, pedido := range liberados {
+			fmt.Printf("Pedido programado %d enviado a la cocina\n", pedido.PK_ID_PEDIDO)
+		}
+
+		// Esperar 1 minuto antes de verificar de nuevo
+		time.Sleep(1 * time.Minute)
+	}
+}
+
+// Función para volver a encolar los trabajos de impresión que quedaron pendientes al reiniciar
+func reanudarImpresiones() {
+	pendientes, err := services.NewImpresionService(repositories.NewOrmStore(orm.NewOrm())).ReanudarPendientes()
+	if err != nil {
+		fmt.Println("Error al reanudar los trabajos de impresión:", err)
+		return
+	}
+	if pendientes > 0 {
+		fmt.Printf("%d trabajos de impresión pendientes vueltos a encolar\n", pendientes)
+	}
+}
+
+// Función para borrar cada hora las claves de idempotencia vencidas
+func limpiarClavesIdempotencia() {
+	for {
+		if _, err := controllers.LimpiarClavesIdempotencia(); err != nil {
+			fmt.Println("Error al limpiar las claves de idempotencia:", err)
+		}
+
+		time.Sleep(1 * time.Hour)
+	}
+}
+
 // @title Restaurante API
 // @version 2.0.0
 // @description API para gestionar el sistema de un restaurante para "El fogón de María"
 // @contact.email baluisto96@gmail.com
-// @basePath /restaurante/v1
+// @basePath /restaurante
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
@@ -59,8 +104,8 @@ func main() {
 	web.InsertFilter("*", web.BeforeRouter, cors.Allow(&cors.Options{
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Access-Control-Allow-Origin", "Content-Type", "Accept"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Authorization", "Access-Control-Allow-Origin", "Content-Type", "Accept", "Idempotency-Key", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "Location", "Idempotent-Replayed", "ETag"},
 		AllowCredentials: true,
 	}))
 
@@ -70,6 +115,9 @@ func main() {
 
 	// Iniciar el cron job en un goroutine
 	go generarNominaAutomatica()
+	go liberarPedidosProgramados()
+	reanudarImpresiones()
+	go limpiarClavesIdempotencia()
 
 	// Iniciar el servidor
 	web.Run()
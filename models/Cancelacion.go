@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// CancelacionPedido registra quién canceló un pedido, en qué estado estaba y el motivo. Los
+// reembolsos de los pagos ya cobrados se consultan por el pedido.
+type CancelacionPedido struct {
+	PK_ID_CANCELACION int64     `orm:"column(PK_ID_CANCELACION);pk;auto" json:"PK_ID_CANCELACION"`
+	PK_ID_PEDIDO      int       `orm:"column(PK_ID_PEDIDO);unique" json:"PK_ID_PEDIDO"`
+	MOTIVO            string    `orm:"column(MOTIVO);type(text)" json:"MOTIVO"`
+	DETALLE           *string   `orm:"column(DETALLE);type(text);null" json:"DETALLE,omitempty"`
+	ESTADO_ANTERIOR   string    `orm:"column(ESTADO_ANTERIOR);type(text)" json:"ESTADO_ANTERIOR"`
+	TOTAL             int64     `orm:"column(TOTAL)" json:"TOTAL"` // Total del pedido al cancelarlo
+	DOCUMENTO_ACTOR   *int64    `orm:"column(DOCUMENTO_ACTOR);null" json:"DOCUMENTO_ACTOR,omitempty"`
+	ROL_ACTOR         *string   `orm:"column(ROL_ACTOR);type(text);null" json:"ROL_ACTOR,omitempty"`
+	FECHA             time.Time `orm:"column(FECHA);type(timestamp)" json:"FECHA"`
+
+	PEDIDO     *Pedido     `orm:"-" json:"PEDIDO,omitempty"`
+	REEMBOLSOS []Reembolso `orm:"-" json:"REEMBOLSOS,omitempty"`
+}
+
+// Reembolso es la devolución del dinero de un pago cuyo pedido se canceló. Queda PENDIENTE
+// hasta que se devuelve por el mismo método de pago.
+type Reembolso struct {
+	PK_ID_REEMBOLSO   int64     `orm:"column(PK_ID_REEMBOLSO);pk;auto" json:"PK_ID_REEMBOLSO"`
+	PK_ID_PAGO        int       `orm:"column(PK_ID_PAGO)" json:"PK_ID_PAGO"`
+	PK_ID_PEDIDO      int       `orm:"column(PK_ID_PEDIDO)" json:"PK_ID_PEDIDO"`
+	PK_ID_METODO_PAGO int       `orm:"column(PK_ID_METODO_PAGO);null" json:"PK_ID_METODO_PAGO"`
+	MONTO             int64     `orm:"column(MONTO)" json:"MONTO"`
+	ESTADO            string    `orm:"column(ESTADO);type(text)" json:"ESTADO"`
+	FECHA             time.Time `orm:"column(FECHA);type(timestamp)" json:"FECHA"`
+}
+
+// CancelacionRequest es el cuerpo para cancelar un pedido
+type CancelacionRequest struct {
+	MOTIVO  string `json:"MOTIVO"`
+	DETALLE string `json:"DETALLE,omitempty"`
+}
+
+// ReporteCancelaciones resume las cancelaciones de un rango de fechas por motivo
+type ReporteCancelaciones struct {
+	DESDE         string              `json:"DESDE"`
+	HASTA         string              `json:"HASTA"`
+	CANCELACIONES int                 `json:"CANCELACIONES"`
+	VALOR         int64               `json:"VALOR"`       // Suma de los totales de los pedidos cancelados
+	REEMBOLSADO   int64               `json:"REEMBOLSADO"` // Suma de los reembolsos generados
+	POR_MOTIVO    []CancelacionMotivo `json:"POR_MOTIVO"`
+	DETALLE       []CancelacionPedido `json:"DETALLE"`
+}
+
+// CancelacionMotivo es la cantidad y el valor de las cancelaciones de un motivo
+type CancelacionMotivo struct {
+	MOTIVO   string `json:"MOTIVO"`
+	CANTIDAD int    `json:"CANTIDAD"`
+	VALOR    int64  `json:"VALOR"`
+}
+
+func (c *CancelacionPedido) TableName() string {
+	return "CANCELACION_PEDIDO"
+}
+
+func (r *Reembolso) TableName() string {
+	return "REEMBOLSO"
+}
+
+func init() {
+	orm.RegisterModel(new(CancelacionPedido), new(Reembolso))
+}
+
+func (c CancelacionPedido) MarshalJSON() ([]byte, error) {
+	type Alias CancelacionPedido
+	return json.Marshal(&struct {
+		FECHA string `json:"FECHA"`
+		Alias
+	}{
+		FECHA: c.FECHA.Format("02-01-2006 15:04:05"),
+		Alias: (Alias)(c),
+	})
+}
+
+func (r Reembolso) MarshalJSON() ([]byte, error) {
+	type Alias Reembolso
+	return json.Marshal(&struct {
+		FECHA string `json:"FECHA"`
+		Alias
+	}{
+		FECHA: r.FECHA.Format("02-01-2006 15:04:05"),
+		Alias: (Alias)(r),
+	})
+}
@@ -0,0 +1,35 @@
+package models
+
+// CheckoutRequest reúne en un solo cuerpo todo lo necesario para registrar un pedido completo
+type CheckoutRequest struct {
+	PK_DOCUMENTO_CLIENTE int                `json:"PK_DOCUMENTO_CLIENTE"`
+	PRODUCTOS            []ItemPedido       `json:"PRODUCTOS"`
+	DOMICILIO            *CheckoutDomicilio `json:"DOMICILIO,omitempty"`
+	PK_ID_METODO_PAGO    int                `json:"PK_ID_METODO_PAGO"`
+	HORA                 string             `json:"HORA,omitempty"`
+	// ACEPTA_PROPINA suma la propina sugerida a los pedidos sin domicilio
+	ACEPTA_PROPINA bool `json:"ACEPTA_PROPINA"`
+	// CODIGO_PROMOCION es opcional; si se envía debe aplicar al pedido
+	CODIGO_PROMOCION string `json:"CODIGO_PROMOCION,omitempty"`
+	// PROGRAMADO_PARA (DD-MM-YYYY HH:mm:ss) programa el pedido para más tarde; vacío es para ya
+	PROGRAMADO_PARA string `json:"PROGRAMADO_PARA,omitempty"`
+}
+
+// CheckoutDomicilio son los datos de entrega; los campos vacíos se toman del cliente
+type CheckoutDomicilio struct {
+	DIRECCION     string   `json:"DIRECCION"`
+	TELEFONO      string   `json:"TELEFONO"`
+	OBSERVACIONES string   `json:"OBSERVACIONES"`
+	DISTANCIA_KM  *float64 `json:"DISTANCIA_KM,omitempty"`
+}
+
+// CheckoutResponse es el pedido creado junto con todos los registros que lo acompañan
+type CheckoutResponse struct {
+	PEDIDO               *Pedido           `json:"PEDIDO"`
+	PRODUCTOS            []LineaProducto   `json:"PRODUCTOS"`
+	PK_ID_PEDIDO_CLIENTE int64             `json:"PK_ID_PEDIDO_CLIENTE"`
+	DOMICILIO            *Domicilio        `json:"DOMICILIO,omitempty"`
+	PAGO                 *Pago             `json:"PAGO"`
+	PROMOCIONES          []PedidoPromocion `json:"PROMOCIONES"`
+	TOTAL                int64             `json:"TOTAL"`
+}
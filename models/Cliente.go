@@ -10,7 +10,7 @@ type Cliente struct {
 	APELLIDO             string  `orm:"column(APELLIDO);type(text)" json:"APELLIDO"`
 	DIRECCION            string  `orm:"column(DIRECCION);type(text)" json:"DIRECCION"`
 	TELEFONO             string  `orm:"column(TELEFONO);type(text)" json:"TELEFONO"`
-	OBSERVACIONES        *string `orm:"column(OBSERVACIONES);type(text)" json:"OBSERVACIONES"`
+	OBSERVACIONES        *string `orm:"column(OBSERVACIONES);type(text);null" json:"OBSERVACIONES"`
 	PASSWORD             string  `orm:"column(PASSWORD);type(text)" json:"PASSWORD"`
 }
 
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// TicketCocina es la parte de un pedido que prepara una estación de la cocina (PARRILLA, FRITOS,
+// BEBIDAS...). Un pedido se reparte en un ticket por estación y el ticket queda LISTO cuando lo
+// están todos sus ITEMS.
+type TicketCocina struct {
+	PK_ID_TICKET int64      `orm:"column(PK_ID_TICKET);pk;auto" json:"PK_ID_TICKET"`
+	PK_ID_PEDIDO int        `orm:"column(PK_ID_PEDIDO)" json:"PK_ID_PEDIDO"`
+	ESTACION     string     `orm:"column(ESTACION);type(text)" json:"ESTACION"`
+	ESTADO       string     `orm:"column(ESTADO);type(text)" json:"ESTADO"`
+	CREADO       time.Time  `orm:"column(CREADO);type(timestamp)" json:"-"`
+	LISTO_EN     *time.Time `orm:"column(LISTO_EN);type(timestamp);null" json:"-"`
+
+	ITEMS []ItemTicket `orm:"-" json:"ITEMS"`
+	// PK_ID_MESA y DELIVERY vienen del pedido para que la cocina sepa a dónde sale el plato
+	PK_ID_MESA *int64 `orm:"-" json:"PK_ID_MESA,omitempty"`
+	DELIVERY   bool   `orm:"-" json:"DELIVERY"`
+}
+
+// ItemTicket es una línea del pedido dentro del ticket de su estación. NOMBRE, NOTAS y
+// MODIFICADORES se copian de la línea para que la pantalla de cocina no tenga que consultarla.
+type ItemTicket struct {
+	PK_ID_ITEM_TICKET    int64   `orm:"column(PK_ID_ITEM_TICKET);pk;auto" json:"PK_ID_ITEM_TICKET"`
+	PK_ID_TICKET         int64   `orm:"column(PK_ID_TICKET)" json:"PK_ID_TICKET"`
+	PK_ID_DETALLE_PEDIDO int64   `orm:"column(PK_ID_DETALLE_PEDIDO)" json:"PK_ID_DETALLE_PEDIDO"`
+	NOMBRE               string  `orm:"column(NOMBRE);type(text)" json:"NOMBRE"`
+	CANTIDAD             int     `orm:"column(CANTIDAD)" json:"CANTIDAD"`
+	NOTAS                *string `orm:"column(NOTAS);type(text);null" json:"NOTAS,omitempty"`
+	MODIFICADORES        *string `orm:"column(MODIFICADORES);type(text);null" json:"MODIFICADORES,omitempty"` // "Término: Tres cuartos, Adiciones: Huevo"
+	ESTADO               string  `orm:"column(ESTADO);type(text)" json:"ESTADO"`
+}
+
+func (t *TicketCocina) TableName() string {
+	return "TICKET_COCINA"
+}
+
+func (i *ItemTicket) TableName() string {
+	return "TICKET_COCINA_ITEM"
+}
+
+func (t TicketCocina) MarshalJSON() ([]byte, error) {
+	type Alias TicketCocina
+	var listo *string
+	if t.LISTO_EN != nil {
+		s := t.LISTO_EN.Format("02-01-2006 15:04:05")
+		listo = &s
+	}
+	return json.Marshal(&struct {
+		CREADO   string  `json:"CREADO"`
+		LISTO_EN *string `json:"LISTO_EN,omitempty"`
+		Alias
+	}{
+		CREADO:   t.CREADO.Format("02-01-2006 15:04:05"),
+		LISTO_EN: listo,
+		Alias:    (Alias)(t),
+	})
+}
+
+func init() {
+	orm.RegisterModel(new(TicketCocina), new(ItemTicket))
+}
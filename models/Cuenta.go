@@ -0,0 +1,27 @@
+package models
+
+// CuentaPedido es el estado de cuenta de un pedido: lo cobrado, lo que falta por pagar y sus pagos
+type CuentaPedido struct {
+	PK_ID_PEDIDO int    `json:"PK_ID_PEDIDO"`
+	TOTAL        int64  `json:"TOTAL"`
+	COBRADO      int64  `json:"COBRADO"`   // Suma de los pagos PAGADO
+	PENDIENTE    int64  `json:"PENDIENTE"` // Suma de los pagos que aún no se cobran
+	SALDO        int64  `json:"SALDO"`     // Lo que falta por cobrar del total
+	PAGOS        []Pago `json:"PAGOS"`
+}
+
+// DivisionCuenta es el cuerpo para dividir el saldo de un pedido entre varios pagadores. MODO es
+// IGUALES (partes iguales), POR_PRODUCTO (cada parte paga sus líneas) o MONTO (montos libres).
+type DivisionCuenta struct {
+	MODO   string        `json:"MODO"`
+	PARTES []ParteCuenta `json:"PARTES"`
+}
+
+// ParteCuenta es lo que paga uno de los clientes de la mesa. DETALLES son las líneas del pedido
+// (PK_ID_DETALLE_PEDIDO) en el modo POR_PRODUCTO y MONTO el valor en el modo MONTO.
+type ParteCuenta struct {
+	PK_DOCUMENTO_CLIENTE int     `json:"PK_DOCUMENTO_CLIENTE,omitempty"`
+	PK_ID_METODO_PAGO    int     `json:"PK_ID_METODO_PAGO"`
+	DETALLES             []int64 `json:"DETALLES,omitempty"`
+	MONTO                int64   `json:"MONTO,omitempty"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "github.com/beego/beego/v2/client/orm"
+
+// DetallePedido es una línea de un pedido: el producto, la cantidad, las opciones elegidas y el
+// nombre, el precio unitario (con las opciones incluidas) y la tarifa de impuesto congelados al
+// momento de pedirlo
+type DetallePedido struct {
+	PK_ID_DETALLE_PEDIDO int64   `orm:"column(PK_ID_DETALLE_PEDIDO);pk;auto" json:"PK_ID_DETALLE_PEDIDO"`
+	PK_ID_PEDIDO         int     `orm:"column(PK_ID_PEDIDO)" json:"PK_ID_PEDIDO"`
+	PK_ID_PRODUCTO       int64   `orm:"column(PK_ID_PRODUCTO)" json:"PK_ID_PRODUCTO"`
+	NOMBRE               string  `orm:"column(NOMBRE);type(text)" json:"NOMBRE"`
+	CANTIDAD             int     `orm:"column(CANTIDAD)" json:"CANTIDAD"`
+	PRECIO_UNITARIO      int64   `orm:"column(PRECIO_UNITARIO)" json:"PRECIO_UNITARIO"`
+	NOTAS                *string `orm:"column(NOTAS);type(text);null" json:"NOTAS,omitempty"`
+	CATEGORIA_IMPUESTO   string  `orm:"column(CATEGORIA_IMPUESTO);type(text);default(EXENTO)" json:"CATEGORIA_IMPUESTO"`
+	TARIFA_IMPUESTO      int     `orm:"column(TARIFA_IMPUESTO);default(0)" json:"TARIFA_IMPUESTO"`
+	// DESCUENTO es la parte del descuento de las promociones que se reparte a esta línea
+	DESCUENTO int64 `orm:"column(DESCUENTO);default(0)" json:"DESCUENTO"`
+
+	MODIFICADORES []DetallePedidoModificador `orm:"-" json:"MODIFICADORES,omitempty"`
+}
+
+func (d *DetallePedido) TableName() string {
+	return "DETALLE_PEDIDO"
+}
+
+func init() {
+	orm.RegisterModel(new(DetallePedido))
+}
+
+// Linea devuelve el detalle con el formato de los elementos de DETALLES_PRODUCTOS
+func (d DetallePedido) Linea() LineaProducto {
+	subtotal := d.PRECIO_UNITARIO * int64(d.CANTIDAD)
+	linea := LineaProducto{
+		PK_ID_PRODUCTO:     d.PK_ID_PRODUCTO,
+		NOMBRE:             d.NOMBRE,
+		CANTIDAD:           d.CANTIDAD,
+		PRECIO_UNITARIO:    d.PRECIO_UNITARIO,
+		SUBTOTAL:           subtotal,
+		CATEGORIA_IMPUESTO: d.CATEGORIA_IMPUESTO,
+		TARIFA_IMPUESTO:    d.TARIFA_IMPUESTO,
+		DESCUENTO:          d.DESCUENTO,
+		IMPUESTO:           ImpuestoIncluido(subtotal-d.DESCUENTO, d.TARIFA_IMPUESTO),
+	}
+	if d.NOTAS != nil {
+		linea.NOTAS = *d.NOTAS
+	}
+	for _, m := range d.MODIFICADORES {
+		linea.MODIFICADORES = append(linea.MODIFICADORES, LineaModificador{
+			PK_ID_OPCION_MODIFICADOR: m.PK_ID_OPCION_MODIFICADOR,
+			GRUPO:                    m.GRUPO,
+			NOMBRE:                   m.NOMBRE,
+			PRECIO_ADICIONAL:         m.PRECIO_ADICIONAL,
+		})
+	}
+	return linea
+}
@@ -17,14 +17,28 @@ type Domicilio struct {
 	OBSERVACIONES   string    `orm:"column(OBSERVACIONES);type(text)" json:"OBSERVACIONES"`
 	CREATED_AT      time.Time `orm:"column(CREATED_AT);type(timestamp);auto_now_add" json:"CREATED_AT"`
 	UPDATED_AT      time.Time `orm:"column(UPDATED_AT);type(timestamp);auto_now" json:"UPDATED_AT"`
-	CREATED_BY      *string   `orm:"column(CREATED_BY);type(date)" json:"CREATED_BY,omitempty"`
-	UPDATED_BY      *string   `orm:"column(UPDATED_BY);type(date)" json:"UPDATED_BY,omitempty"`
+	CREATED_BY      *string   `orm:"column(CREATED_BY);type(date);null" json:"CREATED_BY,omitempty"`
+	UPDATED_BY      *string   `orm:"column(UPDATED_BY);type(date);null" json:"UPDATED_BY,omitempty"`
+	VERSION         int       `orm:"column(VERSION);default(0)" json:"VERSION"`
+
+	// PK_DOCUMENTO_DOMICILIARIO es el trabajador que lleva el domicilio
+	PK_DOCUMENTO_DOMICILIARIO *int64 `orm:"column(PK_DOCUMENTO_DOMICILIARIO);null" json:"PK_DOCUMENTO_DOMICILIARIO,omitempty"`
+	// DISTANCIA_KM es la distancia desde el restaurante; con ella se estima el tiempo de entrega
+	DISTANCIA_KM *float64 `orm:"column(DISTANCIA_KM);null" json:"DISTANCIA_KM,omitempty"`
 }
 
 func (d *Domicilio) TableName() string {
 	return "DOMICILIO"
 }
 
+func (d *Domicilio) GetVersion() int {
+	return d.VERSION
+}
+
+func (d *Domicilio) SetVersion(version int) {
+	d.VERSION = version
+}
+
 func init() {
 	orm.RegisterModel(new(Domicilio))
 }
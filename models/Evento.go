@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Evento es un cambio en un pedido, domicilio, pago o reserva que se envía en tiempo real a los
+// suscriptores de /v2/eventos. TIPO es el recurso (PEDIDO, DOMICILIO, PAGO, RESERVA) y ACCION lo
+// que le pasó (CREADO, ACTUALIZADO, ASIGNADO, CANCELADO).
+type Evento struct {
+	ID     int64       `json:"ID"` // Secuencia del servidor; se reenvía como Last-Event-ID
+	TIPO   string      `json:"TIPO"`
+	ACCION string      `json:"ACCION"`
+	PK_ID  int64       `json:"PK_ID"`
+	ESTADO string      `json:"ESTADO,omitempty"`
+	FECHA  time.Time   `json:"-"`
+	DATOS  interface{} `json:"DATOS,omitempty"`
+
+	// CLIENTES y DOMICILIARIO son los documentos de quienes pueden ver el evento además del personal
+	CLIENTES     []int64 `json:"-"`
+	DOMICILIARIO *int64  `json:"-"`
+}
+
+func (e Evento) MarshalJSON() ([]byte, error) {
+	type Alias Evento
+	return json.Marshal(&struct {
+		FECHA string `json:"FECHA"`
+		Alias
+	}{
+		FECHA: e.FECHA.Format("02-01-2006 15:04:05"),
+		Alias: (Alias)(e),
+	})
+}
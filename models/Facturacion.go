@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// ResolucionFacturacion es un rango de numeración autorizado por la DIAN para un tipo de
+// documento. SIGUIENTE es el consecutivo que se asigna al próximo documento; el rango se agota
+// cuando pasa de HASTA.
+type ResolucionFacturacion struct {
+	PK_ID_RESOLUCION int64     `orm:"column(PK_ID_RESOLUCION);pk;auto" json:"PK_ID_RESOLUCION"`
+	NUMERO           string    `orm:"column(NUMERO);type(text)" json:"NUMERO"`
+	TIPO_DOCUMENTO   string    `orm:"column(TIPO_DOCUMENTO);type(text)" json:"TIPO_DOCUMENTO"` // FACTURA o POS
+	PREFIJO          string    `orm:"column(PREFIJO);type(text)" json:"PREFIJO"`
+	DESDE            int64     `orm:"column(DESDE)" json:"DESDE"`
+	HASTA            int64     `orm:"column(HASTA)" json:"HASTA"`
+	SIGUIENTE        int64     `orm:"column(SIGUIENTE)" json:"SIGUIENTE"`
+	FECHA_DESDE      time.Time `orm:"column(FECHA_DESDE);type(date)" json:"FECHA_DESDE"`
+	FECHA_HASTA      time.Time `orm:"column(FECHA_HASTA);type(date)" json:"FECHA_HASTA"`
+	// CLAVE_TECNICA entra en el CUFE de las facturas; la entrega la DIAN con la resolución
+	CLAVE_TECNICA string `orm:"column(CLAVE_TECNICA);type(text)" json:"CLAVE_TECNICA,omitempty"`
+	ACTIVA        bool   `orm:"column(ACTIVA);default(true)" json:"ACTIVA"`
+}
+
+// DocumentoElectronico es una factura electrónica o un documento equivalente POS emitido para un
+// pedido pagado. Queda GENERADO al firmarse y pasa a ACEPTADO o RECHAZADO según la respuesta del
+// proveedor tecnológico.
+type DocumentoElectronico struct {
+	PK_ID_DOCUMENTO  int64  `orm:"column(PK_ID_DOCUMENTO);pk;auto" json:"PK_ID_DOCUMENTO"`
+	PK_ID_PEDIDO     int    `orm:"column(PK_ID_PEDIDO);unique" json:"PK_ID_PEDIDO"`
+	PK_ID_RESOLUCION int64  `orm:"column(PK_ID_RESOLUCION)" json:"PK_ID_RESOLUCION"`
+	TIPO_DOCUMENTO   string `orm:"column(TIPO_DOCUMENTO);type(text)" json:"TIPO_DOCUMENTO"`
+	PREFIJO          string `orm:"column(PREFIJO);type(text)" json:"PREFIJO"`
+	NUMERO           int64  `orm:"column(NUMERO)" json:"NUMERO"`
+	// CODIGO es el CUFE de las facturas o el CUDE de los documentos equivalentes
+	CODIGO               string    `orm:"column(CODIGO);type(text);unique" json:"CODIGO"`
+	FECHA_EMISION        time.Time `orm:"column(FECHA_EMISION);type(timestamp)" json:"-"`
+	TOTAL                int64     `orm:"column(TOTAL)" json:"TOTAL"`
+	DOCUMENTO_ADQUIRENTE string    `orm:"column(DOCUMENTO_ADQUIRENTE);type(text)" json:"DOCUMENTO_ADQUIRENTE"`
+	NOMBRE_ADQUIRENTE    string    `orm:"column(NOMBRE_ADQUIRENTE);type(text)" json:"NOMBRE_ADQUIRENTE"`
+	ESTADO               string    `orm:"column(ESTADO);type(text)" json:"ESTADO"`
+	ID_ENVIO             *string   `orm:"column(ID_ENVIO);type(text);null" json:"ID_ENVIO,omitempty"`
+	RESPUESTA            *string   `orm:"column(RESPUESTA);type(text);null" json:"RESPUESTA,omitempty"`
+	XML                  string    `orm:"column(XML);type(text)" json:"-"` // Documento firmado; se descarga aparte
+}
+
+// AdquirenteRequest identifica a quien se le factura cuando no es el cliente del pedido
+type AdquirenteRequest struct {
+	TIPO_DOCUMENTO string `json:"TIPO_DOCUMENTO"` // 13 cédula, 31 NIT, 22 cédula de extranjería
+	NUMERO         string `json:"NUMERO"`
+	NOMBRE         string `json:"NOMBRE"`
+	CORREO         string `json:"CORREO,omitempty"`
+}
+
+// DocumentoElectronicoRequest es el cuerpo para emitir el documento electrónico de un pedido
+type DocumentoElectronicoRequest struct {
+	PK_ID_PEDIDO   int                `json:"PK_ID_PEDIDO"`
+	TIPO_DOCUMENTO string             `json:"TIPO_DOCUMENTO,omitempty"` // FACTURA o POS (por defecto)
+	ADQUIRENTE     *AdquirenteRequest `json:"ADQUIRENTE,omitempty"`
+}
+
+func (r *ResolucionFacturacion) TableName() string {
+	return "RESOLUCION_FACTURACION"
+}
+
+func (d *DocumentoElectronico) TableName() string {
+	return "DOCUMENTO_ELECTRONICO"
+}
+
+func (d DocumentoElectronico) MarshalJSON() ([]byte, error) {
+	type Alias DocumentoElectronico
+	return json.Marshal(&struct {
+		FECHA_EMISION string `json:"FECHA_EMISION"`
+		Alias
+	}{
+		FECHA_EMISION: d.FECHA_EMISION.Format("02-01-2006 15:04:05"),
+		Alias:         (Alias)(d),
+	})
+}
+
+func init() {
+	orm.RegisterModel(new(ResolucionFacturacion), new(DocumentoElectronico))
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// PedidoFavorito es un pedido anterior que el cliente guardó para repetirlo; los productos se
+// toman del pedido y se vuelven a cotizar cada vez que se repite
+type PedidoFavorito struct {
+	PK_ID_FAVORITO       int64     `orm:"column(PK_ID_FAVORITO);pk;auto" json:"PK_ID_FAVORITO"`
+	PK_DOCUMENTO_CLIENTE int64     `orm:"column(PK_DOCUMENTO_CLIENTE)" json:"PK_DOCUMENTO_CLIENTE"`
+	PK_ID_PEDIDO         int       `orm:"column(PK_ID_PEDIDO)" json:"PK_ID_PEDIDO"`
+	NOMBRE               string    `orm:"column(NOMBRE);type(text)" json:"NOMBRE"`
+	CREADO               time.Time `orm:"column(CREADO);type(timestamp)" json:"-"`
+
+	PRODUCTOS []LineaProducto `orm:"-" json:"PRODUCTOS"`
+}
+
+// FavoritoRequest es el cuerpo para guardar un pedido como favorito
+type FavoritoRequest struct {
+	PK_ID_PEDIDO int    `json:"PK_ID_PEDIDO"`
+	NOMBRE       string `json:"NOMBRE,omitempty"` // Por defecto "Pedido <id>"
+}
+
+// PedidoAnterior es un pedido del historial del cliente con sus productos tal como se cobraron
+type PedidoAnterior struct {
+	PEDIDO    Pedido          `json:"PEDIDO"`
+	PRODUCTOS []LineaProducto `json:"PRODUCTOS"`
+	// PK_ID_FAVORITO indica si el cliente guardó el pedido como favorito
+	PK_ID_FAVORITO *int64 `json:"PK_ID_FAVORITO,omitempty"`
+}
+
+// ProductoNoDisponible es un producto del pedido anterior que no se pudo repetir
+type ProductoNoDisponible struct {
+	PK_ID_PRODUCTO int64  `json:"PK_ID_PRODUCTO"`
+	NOMBRE         string `json:"NOMBRE"`
+	CANTIDAD       int    `json:"CANTIDAD"`
+	MOTIVO         string `json:"MOTIVO"`
+}
+
+// PedidoRepetido es el pedido nuevo creado a partir de uno anterior, con los productos al precio
+// vigente y los que quedaron por fuera
+type PedidoRepetido struct {
+	PK_ID_PEDIDO_ORIGEN int                    `json:"PK_ID_PEDIDO_ORIGEN"`
+	PEDIDO              *Pedido                `json:"PEDIDO"`
+	PRODUCTOS           []LineaProducto        `json:"PRODUCTOS"`
+	DOMICILIO           *Domicilio             `json:"DOMICILIO,omitempty"`
+	NO_DISPONIBLES      []ProductoNoDisponible `json:"NO_DISPONIBLES"`
+}
+
+func (f *PedidoFavorito) TableName() string {
+	return "PEDIDO_FAVORITO"
+}
+
+func (f PedidoFavorito) MarshalJSON() ([]byte, error) {
+	type Alias PedidoFavorito
+	return json.Marshal(&struct {
+		CREADO string `json:"CREADO"`
+		Alias
+	}{
+		CREADO: f.CREADO.Format("02-01-2006 15:04:05"),
+		Alias:  (Alias)(f),
+	})
+}
+
+func init() {
+	orm.RegisterModel(new(PedidoFavorito))
+}
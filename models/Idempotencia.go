@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// Idempotencia guarda la respuesta de un POST enviado con la cabecera Idempotency-Key.
+// Cada usuario tiene sus propias claves: USUARIO es "rol:documento" o vacío sin sesión.
+// CODIGO_RESPUESTA en 0 indica que la solicitud original todavía se está procesando.
+type Idempotencia struct {
+	PK_ID_IDEMPOTENCIA int       `orm:"column(PK_ID_IDEMPOTENCIA);pk;auto" json:"PK_ID_IDEMPOTENCIA"`
+	USUARIO            string    `orm:"column(USUARIO);size(64)" json:"USUARIO"`
+	CLAVE              string    `orm:"column(CLAVE);size(255)" json:"CLAVE"`
+	METODO             string    `orm:"column(METODO);size(10)" json:"METODO"`
+	RUTA               string    `orm:"column(RUTA);type(text)" json:"RUTA"`
+	HASH_SOLICITUD     string    `orm:"column(HASH_SOLICITUD);size(64)" json:"HASH_SOLICITUD"`
+	CODIGO_RESPUESTA   int       `orm:"column(CODIGO_RESPUESTA)" json:"CODIGO_RESPUESTA"`
+	RESPUESTA          string    `orm:"column(RESPUESTA);type(text)" json:"RESPUESTA"`
+	CABECERAS          string    `orm:"column(CABECERAS);type(text)" json:"CABECERAS"`
+	CREATED_AT         time.Time `orm:"column(CREATED_AT);type(timestamp)" json:"CREATED_AT"`
+}
+
+func (i *Idempotencia) TableName() string {
+	return "IDEMPOTENCIA"
+}
+
+func (i *Idempotencia) TableUnique() [][]string {
+	return [][]string{{"USUARIO", "CLAVE"}}
+}
+
+func init() {
+	orm.RegisterModel(new(Idempotencia))
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// Impresora es una impresora térmica de red. Las que tienen ESTACION imprimen las comandas de esa
+// estación de la cocina; las demás son de caja e imprimen los recibos.
+type Impresora struct {
+	PK_ID_IMPRESORA int64   `orm:"column(PK_ID_IMPRESORA);pk;auto" json:"PK_ID_IMPRESORA"`
+	NOMBRE          string  `orm:"column(NOMBRE);type(text)" json:"NOMBRE"`
+	HOST            string  `orm:"column(HOST);type(text)" json:"HOST"`
+	PUERTO          int     `orm:"column(PUERTO);default(9100)" json:"PUERTO"`
+	ANCHO           int     `orm:"column(ANCHO);default(80)" json:"ANCHO"` // Milímetros del papel: 58 u 80
+	ESTACION        *string `orm:"column(ESTACION);type(text);null" json:"ESTACION,omitempty"`
+	// CAJON indica que la impresora tiene conectado el cajón monedero y lo abre al imprimir un recibo
+	CAJON  bool `orm:"column(CAJON);default(false)" json:"CAJON"`
+	ACTIVA bool `orm:"column(ACTIVA);default(true)" json:"ACTIVA"`
+}
+
+// TrabajoImpresion es un recibo o una comanda en la cola de impresión. Queda PENDIENTE hasta que
+// la impresora lo recibe (IMPRESO) o se agotan los reintentos (FALLIDO).
+type TrabajoImpresion struct {
+	PK_ID_TRABAJO   int64      `orm:"column(PK_ID_TRABAJO);pk;auto" json:"PK_ID_TRABAJO"`
+	PK_ID_IMPRESORA int64      `orm:"column(PK_ID_IMPRESORA)" json:"PK_ID_IMPRESORA"`
+	PK_ID_PEDIDO    int        `orm:"column(PK_ID_PEDIDO)" json:"PK_ID_PEDIDO"`
+	PK_ID_TICKET    *int64     `orm:"column(PK_ID_TICKET);null" json:"PK_ID_TICKET,omitempty"` // Ticket de cocina de las comandas
+	TIPO            string     `orm:"column(TIPO);type(text)" json:"TIPO"`
+	ESTADO          string     `orm:"column(ESTADO);type(text)" json:"ESTADO"`
+	INTENTOS        int        `orm:"column(INTENTOS);default(0)" json:"INTENTOS"`
+	ERROR           *string    `orm:"column(ERROR);type(text);null" json:"ERROR,omitempty"`
+	CREADO          time.Time  `orm:"column(CREADO);type(timestamp)" json:"-"`
+	IMPRESO_EN      *time.Time `orm:"column(IMPRESO_EN);type(timestamp);null" json:"-"`
+}
+
+// TrabajoImpresionRequest es el cuerpo para imprimir un recibo o las comandas de un pedido
+type TrabajoImpresionRequest struct {
+	TIPO            string `json:"TIPO"` // RECIBO o COMANDA
+	PK_ID_PEDIDO    int    `json:"PK_ID_PEDIDO"`
+	PK_ID_TICKET    *int64 `json:"PK_ID_TICKET,omitempty"`    // Solo la comanda de este ticket
+	PK_ID_IMPRESORA *int64 `json:"PK_ID_IMPRESORA,omitempty"` // Por defecto la de caja o la de la estación
+}
+
+func (i *Impresora) TableName() string {
+	return "IMPRESORA"
+}
+
+func (t *TrabajoImpresion) TableName() string {
+	return "TRABAJO_IMPRESION"
+}
+
+func (t TrabajoImpresion) MarshalJSON() ([]byte, error) {
+	type Alias TrabajoImpresion
+	var impreso *string
+	if t.IMPRESO_EN != nil {
+		s := t.IMPRESO_EN.Format("02-01-2006 15:04:05")
+		impreso = &s
+	}
+	return json.Marshal(&struct {
+		CREADO     string  `json:"CREADO"`
+		IMPRESO_EN *string `json:"IMPRESO_EN,omitempty"`
+		Alias
+	}{
+		CREADO:     t.CREADO.Format("02-01-2006 15:04:05"),
+		IMPRESO_EN: impreso,
+		Alias:      (Alias)(t),
+	})
+}
+
+func init() {
+	orm.RegisterModel(new(Impresora), new(TrabajoImpresion))
+}
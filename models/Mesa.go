@@ -0,0 +1,46 @@
+package models
+
+import (
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// Mesa es una mesa del salón. Una mesa OCUPADA puede tener varios pedidos abiertos, uno por cada
+// ronda que se pide; al irse los clientes queda POR LIMPIAR hasta que vuelve a estar LIBRE.
+type Mesa struct {
+	PK_ID_MESA int64  `orm:"column(PK_ID_MESA);pk;auto" json:"PK_ID_MESA"`
+	NUMERO     int    `orm:"column(NUMERO);unique" json:"NUMERO"`
+	CAPACIDAD  int    `orm:"column(CAPACIDAD)" json:"CAPACIDAD"`
+	ZONA       string `orm:"column(ZONA);type(text)" json:"ZONA"`
+	ESTADO     string `orm:"column(ESTADO);type(text)" json:"ESTADO"`
+
+	// PEDIDOS son las rondas abiertas de la mesa y TOTAL la suma de sus totales
+	PEDIDOS []Pedido `orm:"-" json:"PEDIDOS,omitempty"`
+	TOTAL   int64    `orm:"-" json:"TOTAL"`
+}
+
+// TrasladoMesa es el cuerpo para mover o unir los pedidos de una mesa a otra
+type TrasladoMesa struct {
+	PK_ID_MESA_DESTINO int64 `json:"PK_ID_MESA_DESTINO"`
+}
+
+// PlanoMesas es el estado del salón: las mesas de cada zona con sus rondas abiertas
+type PlanoMesas struct {
+	LIBRES      int         `json:"LIBRES"`
+	OCUPADAS    int         `json:"OCUPADAS"`
+	POR_LIMPIAR int         `json:"POR_LIMPIAR"`
+	ZONAS       []ZonaMesas `json:"ZONAS"`
+}
+
+// ZonaMesas agrupa las mesas de una zona del salón
+type ZonaMesas struct {
+	ZONA  string `json:"ZONA"`
+	MESAS []Mesa `json:"MESAS"`
+}
+
+func (m *Mesa) TableName() string {
+	return "MESA"
+}
+
+func init() {
+	orm.RegisterModel(new(Mesa))
+}
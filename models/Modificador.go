@@ -0,0 +1,58 @@
+package models
+
+import "github.com/beego/beego/v2/client/orm"
+
+// GrupoModificador agrupa las opciones con las que se personaliza un producto, por ejemplo
+// "Término" (obligatorio, una opción) o "Adiciones" (opcional, hasta tres)
+type GrupoModificador struct {
+	PK_ID_GRUPO_MODIFICADOR int64               `orm:"column(PK_ID_GRUPO_MODIFICADOR);pk;auto" json:"PK_ID_GRUPO_MODIFICADOR"`
+	PK_ID_PRODUCTO          int64               `orm:"column(PK_ID_PRODUCTO)" json:"PK_ID_PRODUCTO"`
+	NOMBRE                  string              `orm:"column(NOMBRE);type(text)" json:"NOMBRE"`
+	OBLIGATORIO             bool                `orm:"column(OBLIGATORIO);default(false)" json:"OBLIGATORIO"`
+	MIN_SELECCION           int                 `orm:"column(MIN_SELECCION);default(0)" json:"MIN_SELECCION"`
+	MAX_SELECCION           int                 `orm:"column(MAX_SELECCION);default(0)" json:"MAX_SELECCION"` // 0 sin límite
+	OPCIONES                []OpcionModificador `orm:"-" json:"OPCIONES"`
+}
+
+// OpcionModificador es una opción de un grupo y lo que suma (o resta) al precio del producto
+type OpcionModificador struct {
+	PK_ID_OPCION_MODIFICADOR int64  `orm:"column(PK_ID_OPCION_MODIFICADOR);pk;auto" json:"PK_ID_OPCION_MODIFICADOR"`
+	PK_ID_GRUPO_MODIFICADOR  int64  `orm:"column(PK_ID_GRUPO_MODIFICADOR)" json:"PK_ID_GRUPO_MODIFICADOR"`
+	NOMBRE                   string `orm:"column(NOMBRE);type(text)" json:"NOMBRE"`
+	PRECIO_ADICIONAL         int64  `orm:"column(PRECIO_ADICIONAL);default(0)" json:"PRECIO_ADICIONAL"`
+}
+
+// DetallePedidoModificador es una opción elegida en una línea del pedido. El grupo, el nombre y el
+// precio quedan congelados, así que la opción puede borrarse después sin alterar el pedido.
+type DetallePedidoModificador struct {
+	PK_ID_DETALLE_MODIFICADOR int64  `orm:"column(PK_ID_DETALLE_MODIFICADOR);pk;auto" json:"PK_ID_DETALLE_MODIFICADOR"`
+	PK_ID_DETALLE_PEDIDO      int64  `orm:"column(PK_ID_DETALLE_PEDIDO)" json:"PK_ID_DETALLE_PEDIDO"`
+	PK_ID_OPCION_MODIFICADOR  int64  `orm:"column(PK_ID_OPCION_MODIFICADOR)" json:"PK_ID_OPCION_MODIFICADOR"`
+	GRUPO                     string `orm:"column(GRUPO);type(text)" json:"GRUPO"`
+	NOMBRE                    string `orm:"column(NOMBRE);type(text)" json:"NOMBRE"`
+	PRECIO_ADICIONAL          int64  `orm:"column(PRECIO_ADICIONAL)" json:"PRECIO_ADICIONAL"`
+}
+
+// LineaModificador es una opción elegida dentro de un elemento de DETALLES_PRODUCTOS
+type LineaModificador struct {
+	PK_ID_OPCION_MODIFICADOR int64  `json:"PK_ID_OPCION_MODIFICADOR"`
+	GRUPO                    string `json:"GRUPO"`
+	NOMBRE                   string `json:"NOMBRE"`
+	PRECIO_ADICIONAL         int64  `json:"PRECIO_ADICIONAL"`
+}
+
+func (g *GrupoModificador) TableName() string {
+	return "GRUPO_MODIFICADOR"
+}
+
+func (o *OpcionModificador) TableName() string {
+	return "OPCION_MODIFICADOR"
+}
+
+func (d *DetallePedidoModificador) TableName() string {
+	return "DETALLE_PEDIDO_MODIFICADOR"
+}
+
+func init() {
+	orm.RegisterModel(new(GrupoModificador), new(OpcionModificador), new(DetallePedidoModificador))
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// MovimientoStock registra cada entrada o salida de unidades de un producto y su motivo
+type MovimientoStock struct {
+	PK_ID_MOVIMIENTO int64     `orm:"column(PK_ID_MOVIMIENTO);pk;auto" json:"PK_ID_MOVIMIENTO"`
+	PK_ID_PRODUCTO   int64     `orm:"column(PK_ID_PRODUCTO)" json:"PK_ID_PRODUCTO"`
+	PK_ID_PEDIDO     *int      `orm:"column(PK_ID_PEDIDO);null" json:"PK_ID_PEDIDO,omitempty"`
+	CANTIDAD         int       `orm:"column(CANTIDAD)" json:"CANTIDAD"` // Positiva si entra, negativa si sale
+	MOTIVO           string    `orm:"column(MOTIVO);type(text)" json:"MOTIVO"`
+	STOCK_RESULTANTE int       `orm:"column(STOCK_RESULTANTE)" json:"STOCK_RESULTANTE"`
+	DOCUMENTO_ACTOR  *int64    `orm:"column(DOCUMENTO_ACTOR);null" json:"DOCUMENTO_ACTOR,omitempty"`
+	OBSERVACIONES    *string   `orm:"column(OBSERVACIONES);type(text);null" json:"OBSERVACIONES,omitempty"`
+	FECHA            time.Time `orm:"column(FECHA);type(timestamp)" json:"FECHA"`
+}
+
+// MovimientoStockRequest es el cuerpo para registrar un movimiento manual de inventario
+type MovimientoStockRequest struct {
+	PK_ID_PRODUCTO int64  `json:"PK_ID_PRODUCTO"`
+	CANTIDAD       int    `json:"CANTIDAD"`
+	MOTIVO         string `json:"MOTIVO"`
+	OBSERVACIONES  string `json:"OBSERVACIONES"`
+}
+
+func (m *MovimientoStock) TableName() string {
+	return "MOVIMIENTO_STOCK"
+}
+
+func init() {
+	orm.RegisterModel(new(MovimientoStock))
+}
+
+func (m MovimientoStock) MarshalJSON() ([]byte, error) {
+	type Alias MovimientoStock
+	return json.Marshal(&struct {
+		FECHA string `json:"FECHA"`
+		Alias
+	}{
+		FECHA: m.FECHA.Format("02-01-2006 15:04:05"),
+		Alias: (Alias)(m),
+	})
+}
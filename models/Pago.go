@@ -16,12 +16,26 @@ type Pago struct {
 	PK_ID_METODO_PAGO int       `orm:"column(PK_ID_METODO_PAGO);null" json:"PK_ID_METODO_PAGO"`
 	UPDATED_AT        time.Time `orm:"column(UPDATED_AT);type(timestamp);auto_now" json:"UPDATED_AT"`
 	UPDATED_BY        string    `orm:"column(UPDATED_BY)" json:"UPDATED_BY"`
+	VERSION           int       `orm:"column(VERSION);default(0)" json:"VERSION"`
+
+	// PK_ID_PEDIDO es el pedido que cubre el pago; un pedido puede tener varios pagos cuando la
+	// cuenta se divide y PK_ID_PEDIDO_CLIENTE indica el cliente que pagó cada parte
+	PK_ID_PEDIDO         *int   `orm:"column(PK_ID_PEDIDO);null" json:"PK_ID_PEDIDO,omitempty"`
+	PK_ID_PEDIDO_CLIENTE *int64 `orm:"column(PK_ID_PEDIDO_CLIENTE);null" json:"PK_ID_PEDIDO_CLIENTE,omitempty"`
 }
 
 func (p *Pago) TableName() string {
 	return "PAGO"
 }
 
+func (p *Pago) GetVersion() int {
+	return p.VERSION
+}
+
+func (p *Pago) SetVersion(version int) {
+	p.VERSION = version
+}
+
 func init() {
 	orm.RegisterModel(new(Pago))
 }
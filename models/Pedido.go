@@ -8,7 +8,7 @@ import (
 )
 
 type Pedido struct {
-	PK_ID_PEDIDO      int       `orm:"column(PK_ID_PEDIDO);pk;auto" json:"PK_ID_PAGO"`
+	PK_ID_PEDIDO      int       `orm:"column(PK_ID_PEDIDO);pk;auto" json:"PK_ID_PEDIDO"`
 	FECHA             time.Time `orm:"column(FECHA);type(date)" json:"FECHA"`
 	HORA              string    `orm:"column(HORA);type(time)" json:"HORA"`
 	DELIVERY          bool      `orm:"column(DELIVERY); type(boolean)" json:"DELIVERY"`
@@ -16,38 +16,104 @@ type Pedido struct {
 	PK_ID_DOMICILIO   *int      `orm:"column(PK_ID_DOMICILIO);null"`
 	PK_ID_PAGO        *int      `orm:"column(PK_ID_PAGO);null"`
 	PK_ID_RESTAURANTE *int      `orm:"column(PK_ID_RESTAURANTE);null"`
-	UPDATED_AT        time.Time `orm:"column(UPDATED_AT);type(timestamp);auto_now" json:"UPDATED_AT"`
-	UPDATED_BY        string    `orm:"column(UPDATED_BY)" json:"UPDATED_BY"`
+	SUBTOTAL          int64     `orm:"column(SUBTOTAL);default(0)" json:"SUBTOTAL"`
+	DESCUENTO         int64     `orm:"column(DESCUENTO);default(0)" json:"DESCUENTO"`
+	IMPUESTO          int64     `orm:"column(IMPUESTO);default(0)" json:"IMPUESTO"`
+	VALOR_DOMICILIO   int64     `orm:"column(VALOR_DOMICILIO);default(0)" json:"VALOR_DOMICILIO"`
+	// La propina sugerida se calcula para los pedidos en el restaurante y solo suma al total si
+	// el cliente la acepta
+	PROPINA_SUGERIDA int64     `orm:"column(PROPINA_SUGERIDA);default(0)" json:"PROPINA_SUGERIDA"`
+	PROPINA_ACEPTADA bool      `orm:"column(PROPINA_ACEPTADA);default(false)" json:"PROPINA_ACEPTADA"`
+	PROPINA          int64     `orm:"column(PROPINA);default(0)" json:"PROPINA"`
+	TOTAL            int64     `orm:"column(TOTAL);default(0)" json:"TOTAL"`
+	UPDATED_AT       time.Time `orm:"column(UPDATED_AT);type(timestamp);auto_now" json:"UPDATED_AT"`
+	UPDATED_BY       string    `orm:"column(UPDATED_BY)" json:"UPDATED_BY"`
+	VERSION          int       `orm:"column(VERSION);default(0)" json:"VERSION"`
+
+	// CODIGO_PROMOCION es el código de promoción que registró el cliente; se evalúa junto con las
+	// promociones automáticas cada vez que se recalculan los totales
+	CODIGO_PROMOCION *string `orm:"column(CODIGO_PROMOCION);type(text);null" json:"CODIGO_PROMOCION,omitempty"`
+
+	// PK_ID_MESA es la mesa de los pedidos en el salón y PK_DOCUMENTO_MESERO el trabajador que
+	// los tomó; se asignan al abrir la ronda o al cambiar el pedido de mesa
+	PK_ID_MESA          *int64 `orm:"column(PK_ID_MESA);null" json:"PK_ID_MESA,omitempty"`
+	PK_DOCUMENTO_MESERO *int64 `orm:"column(PK_DOCUMENTO_MESERO);null" json:"PK_DOCUMENTO_MESERO,omitempty"`
+
+	// ENTREGA_ESTIMADA es la hora a la que se espera entregar el pedido (o tenerlo listo si no es
+	// a domicilio); se recalcula cuando cambian sus productos o su estado
+	ENTREGA_ESTIMADA *time.Time `orm:"column(ENTREGA_ESTIMADA);type(timestamp);null" json:"-"`
+
+	// PROGRAMADO_PARA es la hora a la que el cliente pidió recibir o recoger el pedido; el pedido
+	// espera fuera de la cocina hasta la anticipación configurada antes de esa hora
+	PROGRAMADO_PARA *time.Time `orm:"column(PROGRAMADO_PARA);type(timestamp);null" json:"-"`
 }
 
 type PedidoDetails struct {
-	PKIDPedido   int64  `json:"PK_ID_PEDIDO" orm:"column(PK_ID_PEDIDO)"`
-	Fecha        string `json:"FECHA" orm:"column(FECHA)"`
-	Hora         string `json:"HORA" orm:"column(HORA)"`
-	Delivery     bool   `json:"DELIVERY" orm:"column(DELIVERY)"`
-	EstadoPedido string `json:"ESTADO_PEDIDO" orm:"column(ESTADO_PEDIDO)"`
-	MetodoPago   string `json:"METODO_PAGO"`
-	Productos    string `json:"PRODUCTOS"`
+	PKIDPedido      int64  `json:"PK_ID_PEDIDO" orm:"column(PK_ID_PEDIDO)"`
+	Fecha           string `json:"FECHA" orm:"column(FECHA)"`
+	Hora            string `json:"HORA" orm:"column(HORA)"`
+	Delivery        bool   `json:"DELIVERY" orm:"column(DELIVERY)"`
+	EstadoPedido    string `json:"ESTADO_PEDIDO" orm:"column(ESTADO_PEDIDO)"`
+	MetodoPago      string `json:"METODO_PAGO"`
+	Productos       string `json:"PRODUCTOS"`
+	Subtotal        int64  `json:"SUBTOTAL" orm:"column(SUBTOTAL)"`
+	Descuento       int64  `json:"DESCUENTO" orm:"column(DESCUENTO)"`
+	Impuesto        int64  `json:"IMPUESTO" orm:"column(IMPUESTO)"`
+	ValorDomicilio  int64  `json:"VALOR_DOMICILIO" orm:"column(VALOR_DOMICILIO)"`
+	PropinaSugerida int64  `json:"PROPINA_SUGERIDA" orm:"column(PROPINA_SUGERIDA)"`
+	PropinaAceptada bool   `json:"PROPINA_ACEPTADA" orm:"column(PROPINA_ACEPTADA)"`
+	Propina         int64  `json:"PROPINA" orm:"column(PROPINA)"`
+	Total           int64  `json:"TOTAL" orm:"column(TOTAL)"`
+	Version         int    `json:"VERSION" orm:"column(VERSION)"`
+	EntregaEstimada string `json:"ENTREGA_ESTIMADA,omitempty" orm:"-"`
+
+	Historial []PedidoEstadoHistorial `json:"HISTORIAL" orm:"-"`
 }
 
 func (p *Pedido) TableName() string {
 	return "PEDIDO"
 }
 
+func (p *Pedido) GetVersion() int {
+	return p.VERSION
+}
+
+func (p *Pedido) SetVersion(version int) {
+	p.VERSION = version
+}
+
+func (d *PedidoDetails) GetVersion() int {
+	return d.Version
+}
+
 func init() {
 	orm.RegisterModel(new(Pedido))
 }
 
 func (d Pedido) MarshalJSON() ([]byte, error) {
 	type Alias Pedido
+	var entrega *string
+	if d.ENTREGA_ESTIMADA != nil {
+		s := d.ENTREGA_ESTIMADA.Format("02-01-2006 15:04:05")
+		entrega = &s
+	}
+	var programado *string
+	if d.PROGRAMADO_PARA != nil {
+		s := d.PROGRAMADO_PARA.Format("02-01-2006 15:04:05")
+		programado = &s
+	}
 	return json.Marshal(&struct {
-		FECHA      string `json:"FECHA"`
-		CREATED_AT string `json:"CREATED_AT"`
-		UPDATED_AT string `json:"UPDATED_AT"`
+		FECHA            string  `json:"FECHA"`
+		CREATED_AT       string  `json:"CREATED_AT"`
+		UPDATED_AT       string  `json:"UPDATED_AT"`
+		ENTREGA_ESTIMADA *string `json:"ENTREGA_ESTIMADA,omitempty"`
+		PROGRAMADO_PARA  *string `json:"PROGRAMADO_PARA,omitempty"`
 		Alias
 	}{
-		FECHA:      d.FECHA.Format("02-01-2006"),
-		UPDATED_AT: d.UPDATED_AT.Format("02-01-2006 15:04:05"),
-		Alias:      (Alias)(d),
+		FECHA:            d.FECHA.Format("02-01-2006"),
+		UPDATED_AT:       d.UPDATED_AT.Format("02-01-2006 15:04:05"),
+		ENTREGA_ESTIMADA: entrega,
+		PROGRAMADO_PARA:  programado,
+		Alias:            (Alias)(d),
 	})
 }
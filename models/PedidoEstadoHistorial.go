@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// PedidoEstadoHistorial registra cada cambio de estado de un pedido y quién lo hizo
+type PedidoEstadoHistorial struct {
+	PK_ID_HISTORIAL int64     `orm:"column(PK_ID_HISTORIAL);pk;auto" json:"PK_ID_HISTORIAL"`
+	PK_ID_PEDIDO    int       `orm:"column(PK_ID_PEDIDO)" json:"PK_ID_PEDIDO"`
+	ESTADO_ANTERIOR *string   `orm:"column(ESTADO_ANTERIOR);type(text);null" json:"ESTADO_ANTERIOR"`
+	ESTADO_NUEVO    string    `orm:"column(ESTADO_NUEVO);type(text)" json:"ESTADO_NUEVO"`
+	DOCUMENTO_ACTOR *int64    `orm:"column(DOCUMENTO_ACTOR);null" json:"DOCUMENTO_ACTOR,omitempty"`
+	ROL_ACTOR       *string   `orm:"column(ROL_ACTOR);type(text);null" json:"ROL_ACTOR,omitempty"`
+	FECHA           time.Time `orm:"column(FECHA);type(timestamp)" json:"FECHA"`
+}
+
+func (h *PedidoEstadoHistorial) TableName() string {
+	return "PEDIDO_ESTADO_HISTORIAL"
+}
+
+func init() {
+	orm.RegisterModel(new(PedidoEstadoHistorial))
+}
+
+func (h PedidoEstadoHistorial) MarshalJSON() ([]byte, error) {
+	type Alias PedidoEstadoHistorial
+	return json.Marshal(&struct {
+		FECHA string `json:"FECHA"`
+		Alias
+	}{
+		FECHA: h.FECHA.Format("02-01-2006 15:04:05"),
+		Alias: (Alias)(h),
+	})
+}
@@ -0,0 +1,34 @@
+package models
+
+// ClienteLlamada es lo que ve quien atiende el teléfono cuando identifica al cliente por su número:
+// sus datos, las direcciones a las que ha pedido y sus últimos pedidos
+type ClienteLlamada struct {
+	TELEFONO    string           `json:"TELEFONO"` // Número normalizado en formato E.164
+	CLIENTE     *Cliente         `json:"CLIENTE"`
+	DIRECCIONES []string         `json:"DIRECCIONES"`
+	PEDIDOS     []PedidoAnterior `json:"PEDIDOS"`
+}
+
+// ClienteNuevoRequest son los datos para registrar, sin contraseña, a quien llama por primera vez
+type ClienteNuevoRequest struct {
+	PK_DOCUMENTO_CLIENTE int     `json:"PK_DOCUMENTO_CLIENTE"`
+	NOMBRE               string  `json:"NOMBRE"`
+	APELLIDO             string  `json:"APELLIDO"`
+	DIRECCION            string  `json:"DIRECCION"`
+	OBSERVACIONES        *string `json:"OBSERVACIONES,omitempty"`
+}
+
+// PedidoTelefonicoRequest es un checkout que toma quien atiende el teléfono. El cliente se busca
+// por TELEFONO; CLIENTE solo se usa cuando el número no está registrado.
+type PedidoTelefonicoRequest struct {
+	TELEFONO string               `json:"TELEFONO"`
+	CLIENTE  *ClienteNuevoRequest `json:"CLIENTE,omitempty"`
+	CheckoutRequest
+}
+
+// PedidoTelefonicoResponse es el checkout del pedido con el cliente al que quedó asociado
+type PedidoTelefonicoResponse struct {
+	CLIENTE       *Cliente `json:"CLIENTE"`
+	CLIENTE_NUEVO bool     `json:"CLIENTE_NUEVO"`
+	*CheckoutResponse
+}
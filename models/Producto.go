@@ -11,12 +11,31 @@ type Producto struct {
 	ESTADO_PRODUCTO string `orm:"column(ESTADO_PRODUCTO);type(text)" json:"ESTADO_PRODUCTO"`
 	IMAGEN          string `orm:"column(IMAGEN);null" json:"IMAGEN"`
 	CANTIDAD        int    `orm:"column(CANTIDAD);type(integer)" json:"CANTIDAD"`
+	// CONTROLA_STOCK indica si los pedidos descuentan CANTIDAD; sin control el producto se vende
+	// sin importar las unidades registradas
+	CONTROLA_STOCK bool `orm:"column(CONTROLA_STOCK);default(false)" json:"CONTROLA_STOCK"`
+	// CATEGORIA_IMPUESTO es la ReglaImpuesto que se aplica al venderlo
+	CATEGORIA_IMPUESTO string `orm:"column(CATEGORIA_IMPUESTO);type(text);default(EXENTO)" json:"CATEGORIA_IMPUESTO"`
+	// ESTACION es la estación de la cocina que lo prepara (PARRILLA, FRITOS, BEBIDAS...)
+	ESTACION string `orm:"column(ESTACION);type(text);default(COCINA)" json:"ESTACION"`
+	// TIEMPO_PREPARACION son los minutos que tarda la cocina en prepararlo; 0 usa el tiempo por
+	// defecto del restaurante al estimar la entrega
+	TIEMPO_PREPARACION int `orm:"column(TIEMPO_PREPARACION);default(0)" json:"TIEMPO_PREPARACION"`
+	VERSION            int `orm:"column(VERSION);default(0)" json:"VERSION"`
 }
 
 func (p *Producto) TableName() string {
 	return "PRODUCTO"
 }
 
+func (p *Producto) GetVersion() int {
+	return p.VERSION
+}
+
+func (p *Producto) SetVersion(version int) {
+	p.VERSION = version
+}
+
 func init() {
 	orm.RegisterModel(new(Producto))
 }
@@ -2,12 +2,55 @@ package models
 
 import "github.com/beego/beego/v2/client/orm"
 
+// ProductoPedido es la tabla anterior con los productos del pedido en un arreglo JSONB. Desde la
+// migración 0005 los productos se guardan en DETALLE_PEDIDO; se conserva solo como respaldo.
 type ProductoPedido struct {
 	PK_ID_PRODUCTO_PEDIDO int64  `orm:"column(PK_ID_PRODUCTO_PEDIDO);pk;auto" json:"PK_ID_PRODUCTO_PEDIDO"`
 	DETALLES_PRODUCTOS    string `orm:"column(DETALLES_PRODUCTOS);type(jsonb)" json:"DETALLES_PRODUCTOS"` // JSONB para consolidar productos
 	PK_ID_PEDIDO          int64  `orm:"column(PK_ID_PEDIDO)" json:"PK_ID_PEDIDO"`
 }
 
+// ItemPedido es un producto solicitado, la cantidad pedida y las opciones elegidas de sus grupos
+// de modificadores. El precio nunca lo envía el cliente.
+type ItemPedido struct {
+	PK_ID_PRODUCTO int64   `json:"PK_ID_PRODUCTO"`
+	CANTIDAD       int     `json:"CANTIDAD"`
+	NOTAS          string  `json:"NOTAS,omitempty"`
+	MODIFICADORES  []int64 `json:"MODIFICADORES,omitempty"` // PK_ID_OPCION_MODIFICADOR de cada opción elegida
+}
+
+// ProductoPedidoRequest es el cuerpo para registrar los productos de un pedido existente
+type ProductoPedidoRequest struct {
+	PK_ID_PEDIDO       int64        `json:"PK_ID_PEDIDO"`
+	DETALLES_PRODUCTOS []ItemPedido `json:"DETALLES_PRODUCTOS"`
+}
+
+// ProductosPedidoResponse son los productos registrados en un pedido
+type ProductosPedidoResponse struct {
+	PK_ID_PEDIDO       int64           `json:"PK_ID_PEDIDO"`
+	DETALLES_PRODUCTOS []LineaProducto `json:"DETALLES_PRODUCTOS"`
+}
+
+// LineaProducto es cada elemento de DETALLES_PRODUCTOS: el nombre y el precio unitario quedan
+// congelados con los valores del producto al momento del pedido. El precio unitario ya incluye
+// lo que suman las opciones elegidas y el impuesto. IMPUESTO es el impuesto incluido en lo que se
+// cobra de la línea (el subtotal menos su parte del descuento de las promociones) según la tarifa
+// de la categoría del producto.
+type LineaProducto struct {
+	PK_ID_PRODUCTO  int64              `json:"PK_ID_PRODUCTO"`
+	NOMBRE          string             `json:"NOMBRE"`
+	CANTIDAD        int                `json:"CANTIDAD"`
+	PRECIO_UNITARIO int64              `json:"PRECIO_UNITARIO"`
+	SUBTOTAL        int64              `json:"SUBTOTAL"`
+	NOTAS           string             `json:"NOTAS,omitempty"`
+	MODIFICADORES   []LineaModificador `json:"MODIFICADORES,omitempty"`
+
+	CATEGORIA_IMPUESTO string `json:"CATEGORIA_IMPUESTO"`
+	TARIFA_IMPUESTO    int    `json:"TARIFA_IMPUESTO"`
+	DESCUENTO          int64  `json:"DESCUENTO,omitempty"`
+	IMPUESTO           int64  `json:"IMPUESTO"`
+}
+
 func (p *ProductoPedido) TableName() string {
 	return "PRODUCTO_PEDIDO"
 }
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// Promocion es una regla de descuento que se evalúa al cotizar un pedido: porcentaje o valor fijo
+// sobre la compra (o sobre un producto), "lleve X pague Y" de un producto y domicilio gratis. Las
+// promociones sin CODIGO se aplican solas; las que tienen código solo si el pedido lo registra.
+type Promocion struct {
+	PK_ID_PROMOCION int64   `orm:"column(PK_ID_PROMOCION);pk;auto" json:"PK_ID_PROMOCION"`
+	NOMBRE          string  `orm:"column(NOMBRE);type(text)" json:"NOMBRE"`
+	CODIGO          *string `orm:"column(CODIGO);type(text);null;unique" json:"CODIGO,omitempty"`
+	TIPO            string  `orm:"column(TIPO);type(text)" json:"TIPO"`
+	// VALOR es el porcentaje (PORCENTAJE) o los pesos (VALOR_FIJO) que se descuentan
+	VALOR          int64  `orm:"column(VALOR);default(0)" json:"VALOR"`
+	PK_ID_PRODUCTO *int64 `orm:"column(PK_ID_PRODUCTO);null" json:"PK_ID_PRODUCTO,omitempty"`
+	// LLEVA y PAGA definen las promociones LLEVA_PAGA: por cada LLEVA unidades se cobran PAGA
+	LLEVA         int        `orm:"column(LLEVA);default(0)" json:"LLEVA"`
+	PAGA          int        `orm:"column(PAGA);default(0)" json:"PAGA"`
+	MINIMO_COMPRA int64      `orm:"column(MINIMO_COMPRA);default(0)" json:"MINIMO_COMPRA"`
+	VIGENTE_DESDE *time.Time `orm:"column(VIGENTE_DESDE);type(timestamp);null" json:"VIGENTE_DESDE,omitempty"`
+	VIGENTE_HASTA *time.Time `orm:"column(VIGENTE_HASTA);type(timestamp);null" json:"VIGENTE_HASTA,omitempty"`
+	// DIAS_SEMANA limita la promoción a unos días, por ejemplo "MARTES,JUEVES"; vacío es todos
+	DIAS_SEMANA        string `orm:"column(DIAS_SEMANA);type(text)" json:"DIAS_SEMANA"`
+	LIMITE_USOS        int    `orm:"column(LIMITE_USOS);default(0)" json:"LIMITE_USOS"`               // 0 sin límite
+	LIMITE_POR_CLIENTE int    `orm:"column(LIMITE_POR_CLIENTE);default(0)" json:"LIMITE_POR_CLIENTE"` // 0 sin límite
+	ACUMULABLE         bool   `orm:"column(ACUMULABLE);default(false)" json:"ACUMULABLE"`
+	ACTIVA             bool   `orm:"column(ACTIVA);default(true)" json:"ACTIVA"`
+}
+
+// PedidoPromocion es una promoción aplicada a un pedido con el descuento que generó. Se reemplaza
+// cada vez que se recalculan los totales del pedido.
+type PedidoPromocion struct {
+	PK_ID_PEDIDO_PROMOCION int64  `orm:"column(PK_ID_PEDIDO_PROMOCION);pk;auto" json:"PK_ID_PEDIDO_PROMOCION"`
+	PK_ID_PEDIDO           int    `orm:"column(PK_ID_PEDIDO)" json:"PK_ID_PEDIDO"`
+	PK_ID_PROMOCION        int64  `orm:"column(PK_ID_PROMOCION)" json:"PK_ID_PROMOCION"`
+	PK_DOCUMENTO_CLIENTE   *int64 `orm:"column(PK_DOCUMENTO_CLIENTE);null" json:"PK_DOCUMENTO_CLIENTE,omitempty"`
+	NOMBRE                 string `orm:"column(NOMBRE);type(text)" json:"NOMBRE"`
+	TIPO                   string `orm:"column(TIPO);type(text)" json:"TIPO"`
+	DESCUENTO              int64  `orm:"column(DESCUENTO)" json:"DESCUENTO"`
+}
+
+func (p *Promocion) TableName() string {
+	return "PROMOCION"
+}
+
+func (p *PedidoPromocion) TableName() string {
+	return "PEDIDO_PROMOCION"
+}
+
+func init() {
+	orm.RegisterModel(new(Promocion), new(PedidoPromocion))
+}
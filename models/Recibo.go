@@ -0,0 +1,37 @@
+package models
+
+// Recibo es el resumen de cobro de un pedido: sus productos, el desglose de impuestos por
+// categoría, las promociones aplicadas, la propina sugerida y si el cliente la aceptó
+type Recibo struct {
+	PK_ID_PEDIDO     int                `json:"PK_ID_PEDIDO"`
+	FECHA            string             `json:"FECHA"`
+	HORA             string             `json:"HORA"`
+	ESTADO_PEDIDO    string             `json:"ESTADO_PEDIDO"`
+	DELIVERY         bool               `json:"DELIVERY"`
+	METODO_PAGO      string             `json:"METODO_PAGO,omitempty"`
+	PRODUCTOS        []LineaProducto    `json:"PRODUCTOS"`
+	IMPUESTOS        []DesgloseImpuesto `json:"IMPUESTOS"`
+	PROMOCIONES      []PedidoPromocion  `json:"PROMOCIONES"`
+	SUBTOTAL         int64              `json:"SUBTOTAL"`
+	DESCUENTO        int64              `json:"DESCUENTO"`
+	IMPUESTO         int64              `json:"IMPUESTO"`
+	PROPINA_SUGERIDA int64              `json:"PROPINA_SUGERIDA"`
+	PROPINA_ACEPTADA bool               `json:"PROPINA_ACEPTADA"`
+	PROPINA          int64              `json:"PROPINA"`
+	VALOR_DOMICILIO  int64              `json:"VALOR_DOMICILIO"`
+	TOTAL            int64              `json:"TOTAL"`
+}
+
+// ReporteVentas suma los pedidos no cancelados de un rango de fechas
+type ReporteVentas struct {
+	DESDE           string             `json:"DESDE"`
+	HASTA           string             `json:"HASTA"`
+	PEDIDOS         int                `json:"PEDIDOS"`
+	SUBTOTAL        int64              `json:"SUBTOTAL"`
+	DESCUENTO       int64              `json:"DESCUENTO"`
+	IMPUESTO        int64              `json:"IMPUESTO"`
+	IMPUESTOS       []DesgloseImpuesto `json:"IMPUESTOS"`
+	PROPINA         int64              `json:"PROPINA"`
+	VALOR_DOMICILIO int64              `json:"VALOR_DOMICILIO"`
+	TOTAL           int64              `json:"TOTAL"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "github.com/beego/beego/v2/client/orm"
+
+// ReglaImpuesto es la tarifa que se cobra a los productos de una categoría tributaria, por
+// ejemplo IMPOCONSUMO (8 %), IVA (19 %) o EXENTO (0 %)
+type ReglaImpuesto struct {
+	CATEGORIA string `orm:"column(CATEGORIA);pk;type(text)" json:"CATEGORIA"`
+	NOMBRE    string `orm:"column(NOMBRE);type(text)" json:"NOMBRE"`
+	TARIFA    int    `orm:"column(TARIFA)" json:"TARIFA"` // Porcentaje sobre el valor antes de impuestos
+}
+
+// DesgloseImpuesto es lo que se cobra de una categoría en un pedido o en un periodo
+type DesgloseImpuesto struct {
+	CATEGORIA string `json:"CATEGORIA"`
+	TARIFA    int    `json:"TARIFA"`
+	BASE      int64  `json:"BASE"`
+	VALOR     int64  `json:"VALOR"`
+}
+
+func (r *ReglaImpuesto) TableName() string {
+	return "REGLA_IMPUESTO"
+}
+
+func init() {
+	orm.RegisterModel(new(ReglaImpuesto))
+}
+
+// ValorImpuesto aplica la tarifa (porcentaje) a la base redondeando al peso más cercano
+func ValorImpuesto(base int64, tarifa int) int64 {
+	return (base*int64(tarifa) + 50) / 100
+}
+
+// BaseGravable es la parte de un valor con el impuesto incluido que corresponde al producto,
+// PRECIO*100/(100+TARIFA), redondeada al peso más cercano
+func BaseGravable(valor int64, tarifa int) int64 {
+	divisor := int64(100 + tarifa)
+	return (valor*100 + divisor/2) / divisor
+}
+
+// ImpuestoIncluido es el impuesto que ya viene dentro de un valor con el impuesto incluido
+func ImpuestoIncluido(valor int64, tarifa int) int64 {
+	return valor - BaseGravable(valor, tarifa)
+}
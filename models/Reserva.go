@@ -16,14 +16,23 @@ type Reserva struct {
 	INDICACIONES   *string   `orm:"column(INDICACIONES);null" json:"INDICACIONES,omitempty"`
 	CREATED_AT     time.Time `orm:"column(CREATED_AT);type(timestamp);auto_now_add" json:"CREATED_AT"`
 	UPDATED_AT     time.Time `orm:"column(UPDATED_AT);type(timestamp);auto_now" json:"UPDATED_AT"`
-	CREATED_BY     *string   `orm:"column(CREATED_BY);type(date)" json:"CREATED_BY,omitempty"`
-	UPDATED_BY     *string   `orm:"column(UPDATED_BY);type(date)" json:"UPDATED_BY,omitempty"`
+	CREATED_BY     *string   `orm:"column(CREATED_BY);type(date);null" json:"CREATED_BY,omitempty"`
+	UPDATED_BY     *string   `orm:"column(UPDATED_BY);type(date);null" json:"UPDATED_BY,omitempty"`
+	VERSION        int       `orm:"column(VERSION);default(0)" json:"VERSION"`
 }
 
 func (r *Reserva) TableName() string {
 	return "RESERVA"
 }
 
+func (r *Reserva) GetVersion() int {
+	return r.VERSION
+}
+
+func (r *Reserva) SetVersion(version int) {
+	r.VERSION = version
+}
+
 func init() {
 	orm.RegisterModel(new(Reserva))
 }
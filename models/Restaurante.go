@@ -7,12 +7,14 @@ import (
 )
 
 type Restaurante struct {
-	PK_ID_RESTAURANTE    int    `orm:"column(PK_ID_RESTAURANTE);pk" json:"pk_id_restaurante"`
-	NOMBRE_RESTAURANTE   string `orm:"column(NOMBRE_RESTAURANTE)" json:"nombre_restaurante"`
-	HORA_APERTURA        string `orm:"column(HORA_APERTURA);type(time)" json:"HORA_APERTURA"`
-	DIAS_LABORALES       string `orm:"column(DIAS_LABORALES)" json:"dias_laborales"`
-	PK_ID_CAMBIO_HORARIO *int   `orm:"column(PK_ID_CAMBIO_HORARIO);null" json:"-"`
-	PK_ID_RESERVA        *int   `orm:"column(PK_ID_RESERVA);null" json:"-"`
+	PK_ID_RESTAURANTE  int    `orm:"column(PK_ID_RESTAURANTE);pk" json:"pk_id_restaurante"`
+	NOMBRE_RESTAURANTE string `orm:"column(NOMBRE_RESTAURANTE)" json:"nombre_restaurante"`
+	HORA_APERTURA      string `orm:"column(HORA_APERTURA);type(time)" json:"HORA_APERTURA"`
+	DIAS_LABORALES     string `orm:"column(DIAS_LABORALES)" json:"dias_laborales"`
+	// HORA_CIERRE es opcional; sin ella el restaurante atiende hasta la medianoche
+	HORA_CIERRE          *string `orm:"column(HORA_CIERRE);type(time);null" json:"HORA_CIERRE,omitempty"`
+	PK_ID_CAMBIO_HORARIO *int    `orm:"column(PK_ID_CAMBIO_HORARIO);null" json:"-"`
+	PK_ID_RESERVA        *int    `orm:"column(PK_ID_RESERVA);null" json:"-"`
 }
 
 func (t *Restaurante) TableName() string {
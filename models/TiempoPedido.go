@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// TiempoPedido guarda, para cada pedido que pasó por la cocina, los minutos estimados al empezar a
+// prepararlo y los que realmente tardó, con la cola y la distancia que se usaron en la estimación.
+// Sirve para ajustar los tiempos de preparación y de entrega.
+type TiempoPedido struct {
+	PK_ID_PEDIDO int `orm:"column(PK_ID_PEDIDO);pk" json:"PK_ID_PEDIDO"`
+	// COLA es cuántos pedidos había en la cocina cuando este empezó a prepararse
+	COLA                 int        `orm:"column(COLA)" json:"COLA"`
+	DISTANCIA_KM         *float64   `orm:"column(DISTANCIA_KM);null" json:"DISTANCIA_KM,omitempty"`
+	PREPARACION_ESTIMADA int        `orm:"column(PREPARACION_ESTIMADA)" json:"PREPARACION_ESTIMADA"`
+	ENTREGA_ESTIMADA     int        `orm:"column(ENTREGA_ESTIMADA)" json:"ENTREGA_ESTIMADA"` // 0 si no es a domicilio
+	PREPARACION_REAL     *int       `orm:"column(PREPARACION_REAL);null" json:"PREPARACION_REAL,omitempty"`
+	ENTREGA_REAL         *int       `orm:"column(ENTREGA_REAL);null" json:"ENTREGA_REAL,omitempty"`
+	INICIO               time.Time  `orm:"column(INICIO);type(timestamp)" json:"-"`
+	LISTO_EN             *time.Time `orm:"column(LISTO_EN);type(timestamp);null" json:"-"`
+	ENTREGADO_EN         *time.Time `orm:"column(ENTREGADO_EN);type(timestamp);null" json:"-"`
+}
+
+// ReporteTiempos compara los minutos estimados con los reales de los pedidos que empezaron a
+// prepararse en el periodo. Los promedios reales solo cuentan los pedidos que ya llegaron a esa etapa.
+type ReporteTiempos struct {
+	DESDE                  string         `json:"DESDE"`
+	HASTA                  string         `json:"HASTA"`
+	PEDIDOS                int            `json:"PEDIDOS"`
+	PREPARACION_ESTIMADA   float64        `json:"PREPARACION_ESTIMADA"`
+	PREPARACION_REAL       float64        `json:"PREPARACION_REAL"`
+	ENTREGA_ESTIMADA       float64        `json:"ENTREGA_ESTIMADA"`
+	ENTREGA_REAL           float64        `json:"ENTREGA_REAL"`
+	DESVIACION_PREPARACION float64        `json:"DESVIACION_PREPARACION"` // Real menos estimado, en minutos
+	DESVIACION_ENTREGA     float64        `json:"DESVIACION_ENTREGA"`
+	DETALLE                []TiempoPedido `json:"DETALLE"`
+}
+
+func (t *TiempoPedido) TableName() string {
+	return "TIEMPO_PEDIDO"
+}
+
+func (t TiempoPedido) MarshalJSON() ([]byte, error) {
+	type Alias TiempoPedido
+	formato := func(fecha *time.Time) *string {
+		if fecha == nil {
+			return nil
+		}
+		s := fecha.Format("02-01-2006 15:04:05")
+		return &s
+	}
+	return json.Marshal(&struct {
+		INICIO       string  `json:"INICIO"`
+		LISTO_EN     *string `json:"LISTO_EN,omitempty"`
+		ENTREGADO_EN *string `json:"ENTREGADO_EN,omitempty"`
+		Alias
+	}{
+		INICIO:       t.INICIO.Format("02-01-2006 15:04:05"),
+		LISTO_EN:     formato(t.LISTO_EN),
+		ENTREGADO_EN: formato(t.ENTREGADO_EN),
+		Alias:        (Alias)(t),
+	})
+}
+
+func init() {
+	orm.RegisterModel(new(TiempoPedido))
+}
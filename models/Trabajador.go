@@ -21,12 +21,21 @@ type Trabajador struct {
 	PASSWORD                string     `orm:"column(PASSWORD)" json:"PASSWORD"`
 	HORARIO                 *string    `orm:"column(HORARIO);type(text);null" json:"HORARIO,omitempty"`
 	PK_ID_RESTAURANTE       *int64     `orm:"column(PK_ID_RESTAURANTE);null" json:"PK_ID_RESTAURANTE,omitempty"`
+	VERSION                 int        `orm:"column(VERSION);default(0)" json:"VERSION"`
 }
 
 func (t *Trabajador) TableName() string {
 	return "TRABAJADOR"
 }
 
+func (t *Trabajador) GetVersion() int {
+	return t.VERSION
+}
+
+func (t *Trabajador) SetVersion(version int) {
+	t.VERSION = version
+}
+
 func init() {
 	orm.RegisterModel(new(Trabajador))
 }
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"restaurante/models"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+type ormNominaRepository struct {
+	s *ormStore
+}
+
+func (r *ormNominaRepository) Get(id int64) (*models.Nomina, error) {
+	nomina := models.Nomina{PK_ID_NOMINA: id}
+	if err := r.s.read(&nomina); err != nil {
+		return nil, err
+	}
+	return &nomina, nil
+}
+
+func (r *ormNominaRepository) Insert(nomina *models.Nomina) error {
+	_, err := r.s.q.Insert(nomina)
+	return err
+}
+
+type ormNominaTrabajadorRepository struct {
+	s *ormStore
+}
+
+func (r *ormNominaTrabajadorRepository) ListByTrabajador(documento int64, filtros NominaFiltros) ([]models.NominaTrabajador, error) {
+	sql := `
+        SELECT nt.* FROM "NOMINA_TRABAJADOR" nt
+        JOIN "NOMINA" n ON nt."PK_ID_NOMINA" = n."PK_ID_NOMINA"
+        WHERE nt."PK_DOCUMENTO_TRABAJADOR" = ?
+    `
+	params := []interface{}{documento}
+
+	// Filtrar por nómina actual
+	if filtros.Actual {
+		sql += ` AND n."FECHA" = (SELECT MAX("FECHA") FROM "NOMINA")`
+	}
+
+	// Filtrar por nóminas pagas o no pagas
+	if filtros.Pagas {
+		sql += ` AND n."ESTADO_NOMINA" = 'PAGO'`
+	} else if filtros.NoPagas {
+		sql += ` AND n."ESTADO_NOMINA" = 'NO PAGO'`
+	}
+
+	// Filtrar por mes y año
+	if filtros.Mes > 0 && filtros.Anio > 0 {
+		sql += ` AND EXTRACT(MONTH FROM n."FECHA") = ? AND EXTRACT(YEAR FROM n."FECHA") = ?`
+		params = append(params, filtros.Mes, filtros.Anio)
+	}
+
+	var relaciones []models.NominaTrabajador
+	if _, err := r.s.q.Raw(sql, params...).QueryRows(&relaciones); err != nil && err != orm.ErrNoRows {
+		return nil, err
+	}
+	return relaciones, nil
+}
+
+func (r *ormNominaTrabajadorRepository) Insert(nominaTrabajador *models.NominaTrabajador) error {
+	_, err := r.s.q.Insert(nominaTrabajador)
+	return err
+}
+
+type ormIncidenciaRepository struct {
+	s *ormStore
+}
+
+func (r *ormIncidenciaRepository) ListByTrabajador(documento int64, desde, hasta time.Time) ([]models.Incidencia, error) {
+	var incidencias []models.Incidencia
+	_, err := r.s.q.QueryTable(new(models.Incidencia)).
+		Filter("PK_DOCUMENTO_TRABAJADOR", documento).
+		Filter("FECHA__gte", desde).
+		Filter("FECHA__lte", hasta).
+		All(&incidencias)
+	return incidencias, err
+}
+
+func (r *ormIncidenciaRepository) Insert(incidencia *models.Incidencia) error {
+	_, err := r.s.q.Insert(incidencia)
+	return err
+}
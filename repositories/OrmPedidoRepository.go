@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"restaurante/models"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+type ormPedidoRepository struct {
+	s *ormStore
+}
+
+func (r *ormPedidoRepository) Get(id int) (*models.Pedido, error) {
+	pedido := models.Pedido{PK_ID_PEDIDO: id}
+	if err := r.s.read(&pedido); err != nil {
+		return nil, err
+	}
+	return &pedido, nil
+}
+
+func (r *ormPedidoRepository) Search(filtros PedidoFiltros) ([]models.Pedido, error) {
+	query := `
+        SELECT p.*
+        FROM "PEDIDO" p
+        LEFT JOIN "PEDIDO_CLIENTE" pc ON p."PK_ID_PEDIDO" = pc."PK_ID_PEDIDO"
+        LEFT JOIN "PAGO" pa ON p."PK_ID_PAGO" = pa."PK_ID_PAGO"
+        LEFT JOIN "METODO_PAGO" mp ON pa."PK_ID_METODO_PAGO" = mp."PK_ID_METODO_PAGO"
+        WHERE 1 = 1
+    `
+	params := []interface{}{}
+
+	if filtros.Fecha != "" {
+		query += ` AND p."FECHA" = ?`
+		params = append(params, filtros.Fecha)
+	}
+
+	if filtros.Desde != "" && filtros.Hasta != "" {
+		query += ` AND p."FECHA" BETWEEN ? AND ?`
+		params = append(params, filtros.Desde, filtros.Hasta)
+	}
+
+	if filtros.Mes > 0 && filtros.Mes <= 12 {
+		query += ` AND EXTRACT(MONTH FROM p."FECHA") = ?`
+		params = append(params, filtros.Mes)
+		if filtros.Anio > 0 {
+			query += ` AND EXTRACT(YEAR FROM p."FECHA") = ?`
+			params = append(params, filtros.Anio)
+		}
+	}
+
+	if filtros.Cliente > 0 {
+		query += ` AND pc."PK_DOCUMENTO_CLIENTE" = ?`
+		params = append(params, filtros.Cliente)
+	}
+
+	if filtros.MetodoPago != "" {
+		query += ` AND mp."TIPO" ILIKE ?`
+		params = append(params, filtros.MetodoPago)
+	}
+
+	if filtros.Domicilio != nil {
+		if *filtros.Domicilio {
+			query += ` AND p."PK_ID_DOMICILIO" IS NOT NULL`
+		} else {
+			query += ` AND p."PK_ID_DOMICILIO" IS NULL`
+		}
+	}
+
+	if filtros.Mesa > 0 {
+		query += ` AND p."PK_ID_MESA" = ?`
+		params = append(params, filtros.Mesa)
+	}
+
+	if filtros.Programados {
+		query += ` AND p."PROGRAMADO_PARA" IS NOT NULL`
+	}
+
+	var pedidos []models.Pedido
+	if _, err := r.s.q.Raw(query, params...).QueryRows(&pedidos); err != nil {
+		return nil, err
+	}
+	return pedidos, nil
+}
+
+func (r *ormPedidoRepository) Details(id int64) (*models.PedidoDetails, error) {
+	query := `
+        SELECT
+            p."PK_ID_PEDIDO",
+            p."FECHA",
+            p."HORA",
+            p."DELIVERY",
+            p."ESTADO_PEDIDO",
+            p."SUBTOTAL",
+            p."DESCUENTO",
+            p."IMPUESTO",
+            p."VALOR_DOMICILIO",
+            p."PROPINA_SUGERIDA",
+            p."PROPINA_ACEPTADA",
+            p."PROPINA",
+            p."TOTAL",
+            p."VERSION",
+            mp."TIPO" AS metodo_pago
+        FROM "PEDIDO" p
+        LEFT JOIN "PAGO" pa ON p."PK_ID_PAGO" = pa."PK_ID_PAGO"
+        LEFT JOIN "METODO_PAGO" mp ON pa."PK_ID_METODO_PAGO" = mp."PK_ID_METODO_PAGO"
+        WHERE p."PK_ID_PEDIDO" = ?;
+    `
+
+	var details models.PedidoDetails
+	if err := r.s.q.Raw(query, id).QueryRow(&details); err == orm.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+func (r *ormPedidoRepository) Insert(pedido *models.Pedido) error {
+	_, err := r.s.q.Insert(pedido)
+	return err
+}
+
+func (r *ormPedidoRepository) Update(pedido *models.Pedido, expected int, cols ...string) error {
+	return r.s.updateVersioned(pedido, "PK_ID_PEDIDO", pedido.PK_ID_PEDIDO, expected, cols...)
+}
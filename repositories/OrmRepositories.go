@@ -0,0 +1,1066 @@
+package repositories
+
+import (
+	"restaurante/models"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+type ormPagoRepository struct {
+	s *ormStore
+}
+
+func (r *ormPagoRepository) Get(id int) (*models.Pago, error) {
+	pago := models.Pago{PK_ID_PAGO: id}
+	if err := r.s.read(&pago); err != nil {
+		return nil, err
+	}
+	return &pago, nil
+}
+
+func (r *ormPagoRepository) List() ([]models.Pago, error) {
+	var pagos []models.Pago
+	_, err := r.s.q.QueryTable(new(models.Pago)).All(&pagos)
+	return pagos, err
+}
+
+func (r *ormPagoRepository) ListByPedido(pedidoID int) ([]models.Pago, error) {
+	pagos := []models.Pago{}
+	_, err := r.s.q.QueryTable(new(models.Pago)).
+		Filter("PK_ID_PEDIDO", pedidoID).
+		OrderBy("PK_ID_PAGO").
+		All(&pagos)
+	return pagos, err
+}
+
+func (r *ormPagoRepository) Insert(pago *models.Pago) error {
+	_, err := r.s.q.Insert(pago)
+	return err
+}
+
+func (r *ormPagoRepository) Update(pago *models.Pago, expected int, cols ...string) error {
+	return r.s.updateVersioned(pago, "PK_ID_PAGO", pago.PK_ID_PAGO, expected, cols...)
+}
+
+func (r *ormPagoRepository) Delete(id int) error {
+	return r.s.deleteByPK(&models.Pago{PK_ID_PAGO: id})
+}
+
+type ormMetodoPagoRepository struct {
+	s *ormStore
+}
+
+func (r *ormMetodoPagoRepository) Get(id int) (*models.MetodoPago, error) {
+	metodo := models.MetodoPago{PK_ID_METODO_PAGO: id}
+	if err := r.s.read(&metodo); err != nil {
+		return nil, err
+	}
+	return &metodo, nil
+}
+
+func (r *ormMetodoPagoRepository) Insert(metodo *models.MetodoPago) error {
+	_, err := r.s.q.Insert(metodo)
+	return err
+}
+
+type ormDomicilioRepository struct {
+	s *ormStore
+}
+
+func (r *ormDomicilioRepository) Get(id int) (*models.Domicilio, error) {
+	domicilio := models.Domicilio{PK_ID_DOMICILIO: id}
+	if err := r.s.read(&domicilio); err != nil {
+		return nil, err
+	}
+	return &domicilio, nil
+}
+
+func (r *ormDomicilioRepository) Insert(domicilio *models.Domicilio) error {
+	_, err := r.s.q.Insert(domicilio)
+	return err
+}
+
+func (r *ormDomicilioRepository) Update(domicilio *models.Domicilio, expected int, cols ...string) error {
+	return r.s.updateVersioned(domicilio, "PK_ID_DOMICILIO", domicilio.PK_ID_DOMICILIO, expected, cols...)
+}
+
+func (r *ormDomicilioRepository) Delete(id int) error {
+	return r.s.deleteByPK(&models.Domicilio{PK_ID_DOMICILIO: id})
+}
+
+type ormClienteRepository struct {
+	s *ormStore
+}
+
+func (r *ormClienteRepository) Get(documento int) (*models.Cliente, error) {
+	cliente := models.Cliente{PK_DOCUMENTO_CLIENTE: documento}
+	if err := r.s.read(&cliente); err != nil {
+		return nil, err
+	}
+	return &cliente, nil
+}
+
+func (r *ormClienteRepository) List() ([]models.Cliente, error) {
+	var clientes []models.Cliente
+	_, err := r.s.q.QueryTable(new(models.Cliente)).All(&clientes)
+	return clientes, err
+}
+
+func (r *ormClienteRepository) ListByTelefono(numeros []string) ([]models.Cliente, error) {
+	var clientes []models.Cliente
+	if len(numeros) == 0 {
+		return clientes, nil
+	}
+	// REPLACE en lugar de regexp_replace para que la consulta también corra en SQLite
+	_, err := r.s.q.Raw(`
+        SELECT * FROM "CLIENTE"
+        WHERE REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE("TELEFONO", ' ', ''), '-', ''), '.', ''), '(', ''), ')', ''), '+', '')
+              IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(numeros)), ", ")+`)
+        ORDER BY "PK_DOCUMENTO_CLIENTE"`, numeros).QueryRows(&clientes)
+	return clientes, err
+}
+
+func (r *ormClienteRepository) Insert(cliente *models.Cliente) error {
+	_, err := r.s.q.Insert(cliente)
+	return err
+}
+
+type ormTrabajadorRepository struct {
+	s *ormStore
+}
+
+func (r *ormTrabajadorRepository) Get(documento int64) (*models.Trabajador, error) {
+	trabajador := models.Trabajador{PK_DOCUMENTO_TRABAJADOR: documento}
+	if err := r.s.read(&trabajador); err != nil {
+		return nil, err
+	}
+	return &trabajador, nil
+}
+
+func (r *ormTrabajadorRepository) List(filtros TrabajadorFiltros) ([]models.Trabajador, error) {
+	// Priorizar "solo retirados" sobre "incluir retirados"
+	query := r.s.q.QueryTable(new(models.Trabajador))
+	if filtros.SoloRetirados {
+		query = query.Filter("FECHA_RETIRO__isnull", false)
+	} else if !filtros.IncluirRetirados {
+		query = query.Filter("FECHA_RETIRO__isnull", true)
+	}
+	if filtros.FechaIngreso != "" {
+		query = query.Filter("FECHA_INGRESO__exact", filtros.FechaIngreso)
+	}
+	if filtros.Rol != "" {
+		query = query.Filter("ROL__exact", filtros.Rol)
+	}
+
+	var trabajadores []models.Trabajador
+	_, err := query.All(&trabajadores)
+	return trabajadores, err
+}
+
+func (r *ormTrabajadorRepository) Insert(trabajador *models.Trabajador) error {
+	_, err := r.s.q.Insert(trabajador)
+	return err
+}
+
+func (r *ormTrabajadorRepository) Update(trabajador *models.Trabajador, expected int, cols ...string) error {
+	return r.s.updateVersioned(trabajador, "PK_DOCUMENTO_TRABAJADOR", trabajador.PK_DOCUMENTO_TRABAJADOR, expected, cols...)
+}
+
+type ormProductoRepository struct {
+	s *ormStore
+}
+
+func (r *ormProductoRepository) Get(id int64) (*models.Producto, error) {
+	producto := models.Producto{PK_ID_PRODUCTO: id}
+	if err := r.s.read(&producto); err != nil {
+		return nil, err
+	}
+	return &producto, nil
+}
+
+func (r *ormProductoRepository) List(onlyActive bool) ([]models.Producto, error) {
+	query := r.s.q.QueryTable(new(models.Producto))
+	if onlyActive {
+		query = query.Filter("ESTADO_PRODUCTO", "DISPONIBLE")
+	}
+
+	var productos []models.Producto
+	_, err := query.All(&productos)
+	return productos, err
+}
+
+func (r *ormProductoRepository) Insert(producto *models.Producto) error {
+	_, err := r.s.q.Insert(producto)
+	return err
+}
+
+func (r *ormProductoRepository) Update(producto *models.Producto, expected int, cols ...string) error {
+	return r.s.updateVersioned(producto, "PK_ID_PRODUCTO", producto.PK_ID_PRODUCTO, expected, cols...)
+}
+
+func (r *ormProductoRepository) AjustarStock(id int64, delta int) (*models.Producto, error) {
+	result, err := r.s.q.Raw(`
+        UPDATE "PRODUCTO"
+        SET "CANTIDAD" = "CANTIDAD" + ?,
+            "ESTADO_PRODUCTO" = CASE
+                WHEN "CANTIDAD" + ? <= 0 THEN 'NO DISPONIBLE'
+                WHEN "CANTIDAD" <= 0 THEN 'DISPONIBLE'
+                ELSE "ESTADO_PRODUCTO"
+            END,
+            "VERSION" = "VERSION" + 1
+        WHERE "PK_ID_PRODUCTO" = ? AND "CANTIDAD" + ? >= 0
+    `, delta, delta, id, delta).Exec()
+	if err != nil {
+		return nil, err
+	}
+
+	producto, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return producto, ErrStockInsuficiente
+	}
+	return producto, nil
+}
+
+type ormReservaRepository struct {
+	s *ormStore
+}
+
+func (r *ormReservaRepository) Get(id int) (*models.Reserva, error) {
+	reserva := models.Reserva{PK_ID_RESERVA: id}
+	if err := r.s.read(&reserva); err != nil {
+		return nil, err
+	}
+	return &reserva, nil
+}
+
+func (r *ormReservaRepository) List() ([]models.Reserva, error) {
+	var reservas []models.Reserva
+	_, err := r.s.q.QueryTable(new(models.Reserva)).All(&reservas)
+	return reservas, err
+}
+
+func (r *ormReservaRepository) Insert(reserva *models.Reserva) error {
+	_, err := r.s.q.Insert(reserva)
+	return err
+}
+
+func (r *ormReservaRepository) Update(reserva *models.Reserva, expected int, cols ...string) error {
+	return r.s.updateVersioned(reserva, "PK_ID_RESERVA", reserva.PK_ID_RESERVA, expected, cols...)
+}
+
+type ormDetallePedidoRepository struct {
+	s *ormStore
+}
+
+func (r *ormDetallePedidoRepository) ListByPedido(pedidoID int) ([]models.DetallePedido, error) {
+	detalles := []models.DetallePedido{}
+	_, err := r.s.q.QueryTable(new(models.DetallePedido)).
+		Filter("PK_ID_PEDIDO", pedidoID).
+		OrderBy("PK_ID_DETALLE_PEDIDO").
+		All(&detalles)
+	if err != nil || len(detalles) == 0 {
+		return detalles, err
+	}
+
+	ids := make([]int64, len(detalles))
+	for i := range detalles {
+		ids[i] = detalles[i].PK_ID_DETALLE_PEDIDO
+	}
+	var modificadores []models.DetallePedidoModificador
+	_, err = r.s.q.QueryTable(new(models.DetallePedidoModificador)).
+		Filter("PK_ID_DETALLE_PEDIDO__in", ids).
+		OrderBy("PK_ID_DETALLE_MODIFICADOR").
+		All(&modificadores)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range modificadores {
+		for i := range detalles {
+			if detalles[i].PK_ID_DETALLE_PEDIDO == m.PK_ID_DETALLE_PEDIDO {
+				detalles[i].MODIFICADORES = append(detalles[i].MODIFICADORES, m)
+			}
+		}
+	}
+	return detalles, nil
+}
+
+func (r *ormDetallePedidoRepository) Insert(detalles []models.DetallePedido) error {
+	for i := range detalles {
+		if _, err := r.s.q.Insert(&detalles[i]); err != nil {
+			return err
+		}
+		for j := range detalles[i].MODIFICADORES {
+			detalles[i].MODIFICADORES[j].PK_ID_DETALLE_PEDIDO = detalles[i].PK_ID_DETALLE_PEDIDO
+			if _, err := r.s.q.Insert(&detalles[i].MODIFICADORES[j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *ormDetallePedidoRepository) UpdateDescuento(id int64, descuento int64) error {
+	_, err := r.s.q.QueryTable(new(models.DetallePedido)).
+		Filter("PK_ID_DETALLE_PEDIDO", id).
+		Update(orm.Params{"DESCUENTO": descuento})
+	return err
+}
+
+func (r *ormDetallePedidoRepository) UpdateCantidad(id int64, cantidad int) error {
+	if _, err := r.s.q.QueryTable(new(models.DetallePedido)).
+		Filter("PK_ID_DETALLE_PEDIDO", id).
+		Update(orm.Params{"CANTIDAD": cantidad}); err != nil {
+		return err
+	}
+	_, err := r.s.q.QueryTable(new(models.ItemTicket)).
+		Filter("PK_ID_DETALLE_PEDIDO", id).
+		Update(orm.Params{"CANTIDAD": cantidad})
+	return err
+}
+
+func (r *ormDetallePedidoRepository) Delete(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := r.s.q.QueryTable(new(models.DetallePedidoModificador)).Filter("PK_ID_DETALLE_PEDIDO__in", ids).Delete(); err != nil {
+		return err
+	}
+	if _, err := r.s.q.QueryTable(new(models.ItemTicket)).Filter("PK_ID_DETALLE_PEDIDO__in", ids).Delete(); err != nil {
+		return err
+	}
+	_, err := r.s.q.QueryTable(new(models.DetallePedido)).Filter("PK_ID_DETALLE_PEDIDO__in", ids).Delete()
+	return err
+}
+
+type ormPedidoClienteRepository struct {
+	s *ormStore
+}
+
+func (r *ormPedidoClienteRepository) List() ([]models.PedidoCliente, error) {
+	var relaciones []models.PedidoCliente
+	_, err := r.s.q.QueryTable(new(models.PedidoCliente)).All(&relaciones)
+	return relaciones, err
+}
+
+func (r *ormPedidoClienteRepository) ExistsForPedido(pedidoID int) (bool, error) {
+	return r.s.q.QueryTable(new(models.PedidoCliente)).
+		Filter("PK_ID_PEDIDO", pedidoID).
+		Exist(), nil
+}
+
+func (r *ormPedidoClienteRepository) GetByPedido(pedidoID int) (*models.PedidoCliente, error) {
+	var relacion models.PedidoCliente
+	err := r.s.q.QueryTable(new(models.PedidoCliente)).
+		Filter("PK_ID_PEDIDO", pedidoID).
+		OrderBy("PK_ID_PEDIDO_CLIENTE").
+		Limit(1).
+		One(&relacion)
+	if err == orm.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &relacion, nil
+}
+
+func (r *ormPedidoClienteRepository) ListByPedido(pedidoID int) ([]models.PedidoCliente, error) {
+	relaciones := []models.PedidoCliente{}
+	_, err := r.s.q.QueryTable(new(models.PedidoCliente)).
+		Filter("PK_ID_PEDIDO", pedidoID).
+		OrderBy("PK_ID_PEDIDO_CLIENTE").
+		All(&relaciones)
+	return relaciones, err
+}
+
+func (r *ormPedidoClienteRepository) Insert(relacion *models.PedidoCliente) error {
+	_, err := r.s.q.Insert(relacion)
+	return err
+}
+
+type ormPedidoEstadoHistorialRepository struct {
+	s *ormStore
+}
+
+func (r *ormPedidoEstadoHistorialRepository) ListByPedido(pedidoID int) ([]models.PedidoEstadoHistorial, error) {
+	historial := []models.PedidoEstadoHistorial{}
+	_, err := r.s.q.QueryTable(new(models.PedidoEstadoHistorial)).
+		Filter("PK_ID_PEDIDO", pedidoID).
+		OrderBy("FECHA", "PK_ID_HISTORIAL").
+		All(&historial)
+	return historial, err
+}
+
+func (r *ormPedidoEstadoHistorialRepository) Insert(historial *models.PedidoEstadoHistorial) error {
+	_, err := r.s.q.Insert(historial)
+	return err
+}
+
+type ormMovimientoStockRepository struct {
+	s *ormStore
+}
+
+func (r *ormMovimientoStockRepository) ListByProducto(productoID int64) ([]models.MovimientoStock, error) {
+	movimientos := []models.MovimientoStock{}
+	_, err := r.s.q.QueryTable(new(models.MovimientoStock)).
+		Filter("PK_ID_PRODUCTO", productoID).
+		OrderBy("-FECHA", "-PK_ID_MOVIMIENTO").
+		All(&movimientos)
+	return movimientos, err
+}
+
+func (r *ormMovimientoStockRepository) Insert(movimiento *models.MovimientoStock) error {
+	_, err := r.s.q.Insert(movimiento)
+	return err
+}
+
+type ormModificadorRepository struct {
+	s *ormStore
+}
+
+func (r *ormModificadorRepository) ListByProducto(productoID int64) ([]models.GrupoModificador, error) {
+	grupos := []models.GrupoModificador{}
+	_, err := r.s.q.QueryTable(new(models.GrupoModificador)).
+		Filter("PK_ID_PRODUCTO", productoID).
+		OrderBy("PK_ID_GRUPO_MODIFICADOR").
+		All(&grupos)
+	if err != nil {
+		return nil, err
+	}
+	for i := range grupos {
+		if err := r.opciones(&grupos[i]); err != nil {
+			return nil, err
+		}
+	}
+	return grupos, nil
+}
+
+func (r *ormModificadorRepository) Get(id int64) (*models.GrupoModificador, error) {
+	grupo := models.GrupoModificador{PK_ID_GRUPO_MODIFICADOR: id}
+	if err := r.s.read(&grupo); err != nil {
+		return nil, err
+	}
+	if err := r.opciones(&grupo); err != nil {
+		return nil, err
+	}
+	return &grupo, nil
+}
+
+func (r *ormModificadorRepository) Insert(grupo *models.GrupoModificador) error {
+	if _, err := r.s.q.Insert(grupo); err != nil {
+		return err
+	}
+	for i := range grupo.OPCIONES {
+		grupo.OPCIONES[i].PK_ID_GRUPO_MODIFICADOR = grupo.PK_ID_GRUPO_MODIFICADOR
+		if _, err := r.s.q.Insert(&grupo.OPCIONES[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ormModificadorRepository) Delete(id int64) error {
+	if _, err := r.s.q.QueryTable(new(models.OpcionModificador)).Filter("PK_ID_GRUPO_MODIFICADOR", id).Delete(); err != nil {
+		return err
+	}
+	return r.s.deleteByPK(&models.GrupoModificador{PK_ID_GRUPO_MODIFICADOR: id})
+}
+
+// opciones carga las OPCIONES del grupo en el orden en que se registraron
+func (r *ormModificadorRepository) opciones(grupo *models.GrupoModificador) error {
+	grupo.OPCIONES = []models.OpcionModificador{}
+	_, err := r.s.q.QueryTable(new(models.OpcionModificador)).
+		Filter("PK_ID_GRUPO_MODIFICADOR", grupo.PK_ID_GRUPO_MODIFICADOR).
+		OrderBy("PK_ID_OPCION_MODIFICADOR").
+		All(&grupo.OPCIONES)
+	return err
+}
+
+type ormReglaImpuestoRepository struct {
+	s *ormStore
+}
+
+func (r *ormReglaImpuestoRepository) List() ([]models.ReglaImpuesto, error) {
+	reglas := []models.ReglaImpuesto{}
+	_, err := r.s.q.QueryTable(new(models.ReglaImpuesto)).OrderBy("CATEGORIA").All(&reglas)
+	return reglas, err
+}
+
+func (r *ormReglaImpuestoRepository) Get(categoria string) (*models.ReglaImpuesto, error) {
+	regla := models.ReglaImpuesto{CATEGORIA: categoria}
+	if err := r.s.read(&regla); err != nil {
+		return nil, err
+	}
+	return &regla, nil
+}
+
+func (r *ormReglaImpuestoRepository) Save(regla *models.ReglaImpuesto) error {
+	if _, err := r.Get(regla.CATEGORIA); err == ErrNotFound {
+		_, err := r.s.q.Insert(regla)
+		return err
+	} else if err != nil {
+		return err
+	}
+	_, err := r.s.q.Update(regla, "NOMBRE", "TARIFA")
+	return err
+}
+
+type ormPromocionRepository struct {
+	s *ormStore
+}
+
+func (r *ormPromocionRepository) Get(id int64) (*models.Promocion, error) {
+	promocion := models.Promocion{PK_ID_PROMOCION: id}
+	if err := r.s.read(&promocion); err != nil {
+		return nil, err
+	}
+	return &promocion, nil
+}
+
+func (r *ormPromocionRepository) GetByCodigo(codigo string) (*models.Promocion, error) {
+	promocion := models.Promocion{CODIGO: &codigo}
+	if err := r.s.q.Read(&promocion, "CODIGO"); err == orm.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &promocion, nil
+}
+
+func (r *ormPromocionRepository) List(soloActivas bool) ([]models.Promocion, error) {
+	promociones := []models.Promocion{}
+	qs := r.s.q.QueryTable(new(models.Promocion))
+	if soloActivas {
+		qs = qs.Filter("ACTIVA", true)
+	}
+	_, err := qs.OrderBy("PK_ID_PROMOCION").All(&promociones)
+	return promociones, err
+}
+
+func (r *ormPromocionRepository) Insert(promocion *models.Promocion) error {
+	_, err := r.s.q.Insert(promocion)
+	return err
+}
+
+func (r *ormPromocionRepository) Update(promocion *models.Promocion, cols ...string) error {
+	num, err := r.s.q.Update(promocion, cols...)
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *ormPromocionRepository) ListByPedido(pedidoID int) ([]models.PedidoPromocion, error) {
+	aplicadas := []models.PedidoPromocion{}
+	_, err := r.s.q.QueryTable(new(models.PedidoPromocion)).
+		Filter("PK_ID_PEDIDO", pedidoID).
+		OrderBy("PK_ID_PEDIDO_PROMOCION").
+		All(&aplicadas)
+	return aplicadas, err
+}
+
+func (r *ormPromocionRepository) ReplaceForPedido(pedidoID int, aplicadas []models.PedidoPromocion) error {
+	if _, err := r.s.q.QueryTable(new(models.PedidoPromocion)).Filter("PK_ID_PEDIDO", pedidoID).Delete(); err != nil {
+		return err
+	}
+	for i := range aplicadas {
+		aplicadas[i].PK_ID_PEDIDO = pedidoID
+		if _, err := r.s.q.Insert(&aplicadas[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ormPromocionRepository) ContarUsos(promocionID int64, documento *int64, excluir int) (int, error) {
+	query := `
+        SELECT COUNT(*)
+        FROM "PEDIDO_PROMOCION" pp
+        JOIN "PEDIDO" p ON p."PK_ID_PEDIDO" = pp."PK_ID_PEDIDO"
+        WHERE pp."PK_ID_PROMOCION" = ? AND pp."PK_ID_PEDIDO" <> ? AND p."ESTADO_PEDIDO" <> 'CANCELADO'`
+	params := []interface{}{promocionID, excluir}
+	if documento != nil {
+		query += ` AND pp."PK_DOCUMENTO_CLIENTE" = ?`
+		params = append(params, *documento)
+	}
+
+	var usos int
+	err := r.s.q.Raw(query, params...).QueryRow(&usos)
+	return usos, err
+}
+
+// Bloquear usa SELECT ... FOR UPDATE; SQLite no lo soporta, pero allí la transacción ya tiene la
+// base de datos para ella sola
+func (r *ormPromocionRepository) Bloquear(promocionID int64) error {
+	return r.s.readForUpdate(&models.Promocion{PK_ID_PROMOCION: promocionID})
+}
+
+type ormCancelacionRepository struct {
+	s *ormStore
+}
+
+func (r *ormCancelacionRepository) GetByPedido(pedidoID int) (*models.CancelacionPedido, error) {
+	cancelacion := models.CancelacionPedido{PK_ID_PEDIDO: pedidoID}
+	if err := r.s.q.Read(&cancelacion, "PK_ID_PEDIDO"); err == orm.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &cancelacion, nil
+}
+
+func (r *ormCancelacionRepository) List(desde, hasta time.Time) ([]models.CancelacionPedido, error) {
+	cancelaciones := []models.CancelacionPedido{}
+	_, err := r.s.q.QueryTable(new(models.CancelacionPedido)).
+		Filter("FECHA__gte", desde).
+		Filter("FECHA__lt", hasta).
+		OrderBy("FECHA", "PK_ID_CANCELACION").
+		All(&cancelaciones)
+	return cancelaciones, err
+}
+
+func (r *ormCancelacionRepository) Insert(cancelacion *models.CancelacionPedido) error {
+	_, err := r.s.q.Insert(cancelacion)
+	return err
+}
+
+type ormReembolsoRepository struct {
+	s *ormStore
+}
+
+func (r *ormReembolsoRepository) ListByPedido(pedidoID int) ([]models.Reembolso, error) {
+	reembolsos := []models.Reembolso{}
+	_, err := r.s.q.QueryTable(new(models.Reembolso)).
+		Filter("PK_ID_PEDIDO", pedidoID).
+		OrderBy("PK_ID_REEMBOLSO").
+		All(&reembolsos)
+	return reembolsos, err
+}
+
+func (r *ormReembolsoRepository) Insert(reembolso *models.Reembolso) error {
+	_, err := r.s.q.Insert(reembolso)
+	return err
+}
+
+type ormMesaRepository struct {
+	s *ormStore
+}
+
+func (r *ormMesaRepository) List() ([]models.Mesa, error) {
+	mesas := []models.Mesa{}
+	_, err := r.s.q.QueryTable(new(models.Mesa)).OrderBy("ZONA", "NUMERO").All(&mesas)
+	return mesas, err
+}
+
+func (r *ormMesaRepository) Get(id int64) (*models.Mesa, error) {
+	mesa := models.Mesa{PK_ID_MESA: id}
+	if err := r.s.read(&mesa); err != nil {
+		return nil, err
+	}
+	return &mesa, nil
+}
+
+func (r *ormMesaRepository) Insert(mesa *models.Mesa) error {
+	_, err := r.s.q.Insert(mesa)
+	return err
+}
+
+func (r *ormMesaRepository) Update(mesa *models.Mesa, cols ...string) error {
+	num, err := r.s.q.Update(mesa, cols...)
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type ormTicketCocinaRepository struct {
+	s *ormStore
+}
+
+func (r *ormTicketCocinaRepository) List(estacion string, estados []string) ([]models.TicketCocina, error) {
+	qs := r.s.q.QueryTable(new(models.TicketCocina))
+	if estacion != "" {
+		qs = qs.Filter("ESTACION", estacion)
+	}
+	if len(estados) > 0 {
+		qs = qs.Filter("ESTADO__in", estados)
+	}
+	tickets := []models.TicketCocina{}
+	if _, err := qs.OrderBy("CREADO", "PK_ID_TICKET").All(&tickets); err != nil {
+		return nil, err
+	}
+	return tickets, r.items(tickets)
+}
+
+func (r *ormTicketCocinaRepository) ListByPedido(pedidoID int) ([]models.TicketCocina, error) {
+	tickets := []models.TicketCocina{}
+	_, err := r.s.q.QueryTable(new(models.TicketCocina)).
+		Filter("PK_ID_PEDIDO", pedidoID).
+		OrderBy("PK_ID_TICKET").
+		All(&tickets)
+	if err != nil {
+		return nil, err
+	}
+	return tickets, r.items(tickets)
+}
+
+func (r *ormTicketCocinaRepository) Get(id int64) (*models.TicketCocina, error) {
+	ticket := models.TicketCocina{PK_ID_TICKET: id}
+	if err := r.s.read(&ticket); err != nil {
+		return nil, err
+	}
+	tickets := []models.TicketCocina{ticket}
+	if err := r.items(tickets); err != nil {
+		return nil, err
+	}
+	return &tickets[0], nil
+}
+
+func (r *ormTicketCocinaRepository) Insert(ticket *models.TicketCocina) error {
+	if _, err := r.s.q.Insert(ticket); err != nil {
+		return err
+	}
+	for i := range ticket.ITEMS {
+		ticket.ITEMS[i].PK_ID_TICKET = ticket.PK_ID_TICKET
+		if _, err := r.s.q.Insert(&ticket.ITEMS[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ormTicketCocinaRepository) Update(ticket *models.TicketCocina) error {
+	num, err := r.s.q.Update(ticket, "ESTADO", "LISTO_EN")
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return ErrNotFound
+	}
+	for i := range ticket.ITEMS {
+		if _, err := r.s.q.Update(&ticket.ITEMS[i], "ESTADO"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// items carga los ITEMS de cada ticket en el orden en que se registraron
+func (r *ormTicketCocinaRepository) items(tickets []models.TicketCocina) error {
+	for i := range tickets {
+		tickets[i].ITEMS = []models.ItemTicket{}
+		_, err := r.s.q.QueryTable(new(models.ItemTicket)).
+			Filter("PK_ID_TICKET", tickets[i].PK_ID_TICKET).
+			OrderBy("PK_ID_ITEM_TICKET").
+			All(&tickets[i].ITEMS)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type ormTiempoPedidoRepository struct {
+	s *ormStore
+}
+
+func (r *ormTiempoPedidoRepository) Get(pedidoID int) (*models.TiempoPedido, error) {
+	tiempo := models.TiempoPedido{PK_ID_PEDIDO: pedidoID}
+	if err := r.s.read(&tiempo); err != nil {
+		return nil, err
+	}
+	return &tiempo, nil
+}
+
+func (r *ormTiempoPedidoRepository) List(desde, hasta time.Time) ([]models.TiempoPedido, error) {
+	tiempos := []models.TiempoPedido{}
+	_, err := r.s.q.QueryTable(new(models.TiempoPedido)).
+		Filter("INICIO__gte", desde).
+		Filter("INICIO__lt", hasta).
+		OrderBy("INICIO", "PK_ID_PEDIDO").
+		All(&tiempos)
+	return tiempos, err
+}
+
+func (r *ormTiempoPedidoRepository) Insert(tiempo *models.TiempoPedido) error {
+	_, err := r.s.q.Insert(tiempo)
+	return err
+}
+
+func (r *ormTiempoPedidoRepository) Update(tiempo *models.TiempoPedido) error {
+	num, err := r.s.q.Update(tiempo)
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type ormImpresoraRepository struct {
+	s *ormStore
+}
+
+func (r *ormImpresoraRepository) List() ([]models.Impresora, error) {
+	impresoras := []models.Impresora{}
+	_, err := r.s.q.QueryTable(new(models.Impresora)).OrderBy("PK_ID_IMPRESORA").All(&impresoras)
+	return impresoras, err
+}
+
+func (r *ormImpresoraRepository) Get(id int64) (*models.Impresora, error) {
+	impresora := models.Impresora{PK_ID_IMPRESORA: id}
+	if err := r.s.read(&impresora); err != nil {
+		return nil, err
+	}
+	return &impresora, nil
+}
+
+func (r *ormImpresoraRepository) Insert(impresora *models.Impresora) error {
+	_, err := r.s.q.Insert(impresora)
+	return err
+}
+
+func (r *ormImpresoraRepository) Update(impresora *models.Impresora) error {
+	num, err := r.s.q.Update(impresora)
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *ormImpresoraRepository) Delete(id int64) error {
+	return r.s.deleteByPK(&models.Impresora{PK_ID_IMPRESORA: id})
+}
+
+type ormTrabajoImpresionRepository struct {
+	s *ormStore
+}
+
+func (r *ormTrabajoImpresionRepository) List(estado string) ([]models.TrabajoImpresion, error) {
+	qs := r.s.q.QueryTable(new(models.TrabajoImpresion))
+	if estado != "" {
+		qs = qs.Filter("ESTADO", estado)
+	}
+	trabajos := []models.TrabajoImpresion{}
+	_, err := qs.OrderBy("CREADO", "PK_ID_TRABAJO").All(&trabajos)
+	return trabajos, err
+}
+
+func (r *ormTrabajoImpresionRepository) Get(id int64) (*models.TrabajoImpresion, error) {
+	trabajo := models.TrabajoImpresion{PK_ID_TRABAJO: id}
+	if err := r.s.read(&trabajo); err != nil {
+		return nil, err
+	}
+	return &trabajo, nil
+}
+
+func (r *ormTrabajoImpresionRepository) Insert(trabajo *models.TrabajoImpresion) error {
+	_, err := r.s.q.Insert(trabajo)
+	return err
+}
+
+func (r *ormTrabajoImpresionRepository) Update(trabajo *models.TrabajoImpresion) error {
+	num, err := r.s.q.Update(trabajo, "ESTADO", "INTENTOS", "ERROR", "IMPRESO_EN")
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type ormResolucionFacturacionRepository struct {
+	s *ormStore
+}
+
+func (r *ormResolucionFacturacionRepository) List() ([]models.ResolucionFacturacion, error) {
+	resoluciones := []models.ResolucionFacturacion{}
+	_, err := r.s.q.QueryTable(new(models.ResolucionFacturacion)).OrderBy("PK_ID_RESOLUCION").All(&resoluciones)
+	return resoluciones, err
+}
+
+func (r *ormResolucionFacturacionRepository) Get(id int64) (*models.ResolucionFacturacion, error) {
+	resolucion := models.ResolucionFacturacion{PK_ID_RESOLUCION: id}
+	if err := r.s.read(&resolucion); err != nil {
+		return nil, err
+	}
+	return &resolucion, nil
+}
+
+func (r *ormResolucionFacturacionRepository) Insert(resolucion *models.ResolucionFacturacion) error {
+	_, err := r.s.q.Insert(resolucion)
+	return err
+}
+
+func (r *ormResolucionFacturacionRepository) Update(resolucion *models.ResolucionFacturacion) error {
+	num, err := r.s.q.Update(resolucion, "NUMERO", "TIPO_DOCUMENTO", "PREFIJO", "DESDE", "HASTA",
+		"FECHA_DESDE", "FECHA_HASTA", "CLAVE_TECNICA", "ACTIVA")
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *ormResolucionFacturacionRepository) Consumir(id int64) (int64, error) {
+	result, err := r.s.q.Raw(`
+        UPDATE "RESOLUCION_FACTURACION"
+        SET "SIGUIENTE" = "SIGUIENTE" + 1
+        WHERE "PK_ID_RESOLUCION" = ? AND "SIGUIENTE" <= "HASTA"
+    `, id).Exec()
+	if err != nil {
+		return 0, err
+	}
+
+	resolucion, err := r.Get(id)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, ErrRangoAgotado
+	}
+	return resolucion.SIGUIENTE - 1, nil
+}
+
+type ormDocumentoElectronicoRepository struct {
+	s *ormStore
+}
+
+func (r *ormDocumentoElectronicoRepository) List(pedidoID int) ([]models.DocumentoElectronico, error) {
+	qs := r.s.q.QueryTable(new(models.DocumentoElectronico))
+	if pedidoID != 0 {
+		qs = qs.Filter("PK_ID_PEDIDO", pedidoID)
+	}
+	documentos := []models.DocumentoElectronico{}
+	_, err := qs.OrderBy("FECHA_EMISION", "PK_ID_DOCUMENTO").All(&documentos)
+	return documentos, err
+}
+
+func (r *ormDocumentoElectronicoRepository) Get(id int64) (*models.DocumentoElectronico, error) {
+	documento := models.DocumentoElectronico{PK_ID_DOCUMENTO: id}
+	if err := r.s.read(&documento); err != nil {
+		return nil, err
+	}
+	return &documento, nil
+}
+
+func (r *ormDocumentoElectronicoRepository) GetByPedido(pedidoID int) (*models.DocumentoElectronico, error) {
+	documento := models.DocumentoElectronico{PK_ID_PEDIDO: pedidoID}
+	if err := r.s.q.Read(&documento, "PK_ID_PEDIDO"); err == orm.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &documento, nil
+}
+
+func (r *ormDocumentoElectronicoRepository) Insert(documento *models.DocumentoElectronico) error {
+	_, err := r.s.q.Insert(documento)
+	return err
+}
+
+func (r *ormDocumentoElectronicoRepository) Update(documento *models.DocumentoElectronico) error {
+	num, err := r.s.q.Update(documento, "ESTADO", "ID_ENVIO", "RESPUESTA")
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type ormRestauranteRepository struct {
+	s *ormStore
+}
+
+func (r *ormRestauranteRepository) List() ([]models.Restaurante, error) {
+	restaurantes := []models.Restaurante{}
+	_, err := r.s.q.QueryTable(new(models.Restaurante)).OrderBy("PK_ID_RESTAURANTE").All(&restaurantes)
+	return restaurantes, err
+}
+
+func (r *ormRestauranteRepository) Get(id int) (*models.Restaurante, error) {
+	restaurante := models.Restaurante{PK_ID_RESTAURANTE: id}
+	if err := r.s.read(&restaurante); err != nil {
+		return nil, err
+	}
+	return &restaurante, nil
+}
+
+func (r *ormRestauranteRepository) Insert(restaurante *models.Restaurante) error {
+	_, err := r.s.q.Insert(restaurante)
+	return err
+}
+
+type ormCambioHorarioRepository struct {
+	s *ormStore
+}
+
+func (r *ormCambioHorarioRepository) GetByFecha(fecha time.Time) (*models.CambiosHorario, error) {
+	var cambio models.CambiosHorario
+	err := r.s.q.QueryTable(new(models.CambiosHorario)).
+		Filter("FECHA", fecha.Format("2006-01-02")).
+		OrderBy("-PK_ID_CAMBIO_HORARIO").
+		Limit(1).
+		One(&cambio)
+	if err == orm.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &cambio, nil
+}
+
+func (r *ormCambioHorarioRepository) Insert(cambio *models.CambiosHorario) error {
+	_, err := r.s.q.Insert(cambio)
+	return err
+}
+
+type ormPedidoFavoritoRepository struct {
+	s *ormStore
+}
+
+func (r *ormPedidoFavoritoRepository) ListByCliente(documento int64) ([]models.PedidoFavorito, error) {
+	favoritos := []models.PedidoFavorito{}
+	_, err := r.s.q.QueryTable(new(models.PedidoFavorito)).
+		Filter("PK_DOCUMENTO_CLIENTE", documento).
+		OrderBy("-PK_ID_FAVORITO").
+		All(&favoritos)
+	return favoritos, err
+}
+
+func (r *ormPedidoFavoritoRepository) Get(id int64) (*models.PedidoFavorito, error) {
+	favorito := models.PedidoFavorito{PK_ID_FAVORITO: id}
+	if err := r.s.read(&favorito); err != nil {
+		return nil, err
+	}
+	return &favorito, nil
+}
+
+func (r *ormPedidoFavoritoRepository) Insert(favorito *models.PedidoFavorito) error {
+	_, err := r.s.q.Insert(favorito)
+	return err
+}
+
+func (r *ormPedidoFavoritoRepository) Delete(id int64) error {
+	return r.s.deleteByPK(&models.PedidoFavorito{PK_ID_FAVORITO: id})
+}
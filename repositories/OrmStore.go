@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// ormStore implementa Store sobre el ORM de beego. Fuera de una transacción o es la conexión
+// y q la misma; dentro de una transacción o es nil, q es la transacción en curso y pendientes las
+// funciones que esperan a que se confirme.
+type ormStore struct {
+	o          orm.Ormer
+	q          orm.QueryExecutor
+	pendientes *[]func()
+}
+
+// NewOrmStore crea un Store respaldado por la base de datos configurada en el ORM
+func NewOrmStore(o orm.Ormer) Store {
+	return &ormStore{o: o, q: o}
+}
+
+func (s *ormStore) Transaction(fn func(tx Store) error) error {
+	if s.o == nil {
+		return fn(s)
+	}
+	pendientes := []func(){}
+	err := s.o.DoTx(func(ctx context.Context, txOrm orm.TxOrmer) error {
+		return fn(&ormStore{q: txOrm, pendientes: &pendientes})
+	})
+	if err != nil {
+		return err
+	}
+	for _, pendiente := range pendientes {
+		pendiente()
+	}
+	return nil
+}
+
+func (s *ormStore) AfterCommit(fn func()) {
+	if s.pendientes == nil {
+		fn()
+		return
+	}
+	*s.pendientes = append(*s.pendientes, fn)
+}
+
+func (s *ormStore) Pedidos() PedidoRepository          { return &ormPedidoRepository{s} }
+func (s *ormStore) Pagos() PagoRepository              { return &ormPagoRepository{s} }
+func (s *ormStore) MetodosPago() MetodoPagoRepository  { return &ormMetodoPagoRepository{s} }
+func (s *ormStore) Domicilios() DomicilioRepository    { return &ormDomicilioRepository{s} }
+func (s *ormStore) Clientes() ClienteRepository        { return &ormClienteRepository{s} }
+func (s *ormStore) Trabajadores() TrabajadorRepository { return &ormTrabajadorRepository{s} }
+func (s *ormStore) Productos() ProductoRepository      { return &ormProductoRepository{s} }
+func (s *ormStore) Reservas() ReservaRepository        { return &ormReservaRepository{s} }
+func (s *ormStore) Incidencias() IncidenciaRepository  { return &ormIncidenciaRepository{s} }
+func (s *ormStore) Nominas() NominaRepository          { return &ormNominaRepository{s} }
+func (s *ormStore) NominasTrabajador() NominaTrabajadorRepository {
+	return &ormNominaTrabajadorRepository{s}
+}
+func (s *ormStore) DetallesPedido() DetallePedidoRepository  { return &ormDetallePedidoRepository{s} }
+func (s *ormStore) PedidosClientes() PedidoClienteRepository { return &ormPedidoClienteRepository{s} }
+func (s *ormStore) HistorialEstados() PedidoEstadoHistorialRepository {
+	return &ormPedidoEstadoHistorialRepository{s}
+}
+func (s *ormStore) MovimientosStock() MovimientoStockRepository {
+	return &ormMovimientoStockRepository{s}
+}
+func (s *ormStore) Modificadores() ModificadorRepository    { return &ormModificadorRepository{s} }
+func (s *ormStore) ReglasImpuesto() ReglaImpuestoRepository { return &ormReglaImpuestoRepository{s} }
+func (s *ormStore) Promociones() PromocionRepository        { return &ormPromocionRepository{s} }
+func (s *ormStore) Cancelaciones() CancelacionRepository    { return &ormCancelacionRepository{s} }
+func (s *ormStore) Reembolsos() ReembolsoRepository         { return &ormReembolsoRepository{s} }
+func (s *ormStore) Mesas() MesaRepository                   { return &ormMesaRepository{s} }
+func (s *ormStore) TicketsCocina() TicketCocinaRepository   { return &ormTicketCocinaRepository{s} }
+func (s *ormStore) TiemposPedido() TiempoPedidoRepository   { return &ormTiempoPedidoRepository{s} }
+func (s *ormStore) Impresoras() ImpresoraRepository         { return &ormImpresoraRepository{s} }
+func (s *ormStore) TrabajosImpresion() TrabajoImpresionRepository {
+	return &ormTrabajoImpresionRepository{s}
+}
+func (s *ormStore) ResolucionesFacturacion() ResolucionFacturacionRepository {
+	return &ormResolucionFacturacionRepository{s}
+}
+func (s *ormStore) DocumentosElectronicos() DocumentoElectronicoRepository {
+	return &ormDocumentoElectronicoRepository{s}
+}
+func (s *ormStore) Restaurantes() RestauranteRepository     { return &ormRestauranteRepository{s} }
+func (s *ormStore) CambiosHorario() CambioHorarioRepository { return &ormCambioHorarioRepository{s} }
+func (s *ormStore) Favoritos() PedidoFavoritoRepository     { return &ormPedidoFavoritoRepository{s} }
+
+// read carga md por su llave primaria traduciendo orm.ErrNoRows a ErrNotFound
+func (s *ormStore) read(md any) error {
+	if err := s.q.Read(md); err == orm.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// readForUpdate es read con SELECT ... FOR UPDATE: la fila queda bloqueada hasta que termine la
+// transacción
+func (s *ormStore) readForUpdate(md any) error {
+	if err := s.q.ReadForUpdate(md); err == orm.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// deleteByPK elimina md por su llave primaria y devuelve ErrNotFound si no existía
+func (s *ormStore) deleteByPK(md any) error {
+	num, err := s.q.Delete(md)
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// updateVersioned guarda md sólo si su VERSION en la base de datos sigue siendo expected y la
+// incrementa. Las dos sentencias se ejecutan en la misma transacción.
+func (s *ormStore) updateVersioned(md Versioned, pkField string, pk any, expected int, cols ...string) error {
+	return s.Transaction(func(tx Store) error {
+		q := tx.(*ormStore).q
+		num, err := q.QueryTable(md).
+			Filter(pkField, pk).
+			Filter("VERSION", expected).
+			Update(orm.Params{"VERSION": expected + 1})
+		if err != nil {
+			return err
+		}
+		if num == 0 {
+			return ErrVersionConflict
+		}
+
+		md.SetVersion(expected + 1)
+		if len(cols) > 0 {
+			cols = append(cols, "VERSION")
+		}
+		_, err = q.Update(md, cols...)
+		return err
+	})
+}
@@ -0,0 +1,362 @@
+package repositories
+
+import (
+	"errors"
+	"restaurante/models"
+	"time"
+)
+
+// Errores comunes que devuelven todas las implementaciones de los repositorios
+var (
+	// ErrNotFound indica que el registro buscado no existe
+	ErrNotFound = errors.New("registro no encontrado")
+	// ErrVersionConflict indica que el registro cambió desde la versión que se quería actualizar
+	ErrVersionConflict = errors.New("el registro cambió desde la versión indicada")
+	// ErrStockInsuficiente indica que el producto no tiene las unidades que se quieren descontar
+	ErrStockInsuficiente = errors.New("stock insuficiente")
+	// ErrRangoAgotado indica que la resolución de facturación ya asignó todos sus consecutivos
+	ErrRangoAgotado = errors.New("rango de numeración agotado")
+)
+
+// Versioned lo implementan los modelos con columna VERSION para control de concurrencia optimista
+type Versioned interface {
+	GetVersion() int
+	SetVersion(version int)
+}
+
+// Store da acceso a los repositorios de cada entidad. Las implementaciones son la del ORM de
+// beego (NewOrmStore) y la de memoria para pruebas (paquete repositories/memory).
+type Store interface {
+	Pedidos() PedidoRepository
+	Pagos() PagoRepository
+	MetodosPago() MetodoPagoRepository
+	Domicilios() DomicilioRepository
+	Clientes() ClienteRepository
+	Trabajadores() TrabajadorRepository
+	Productos() ProductoRepository
+	Reservas() ReservaRepository
+	Incidencias() IncidenciaRepository
+	Nominas() NominaRepository
+	NominasTrabajador() NominaTrabajadorRepository
+	DetallesPedido() DetallePedidoRepository
+	PedidosClientes() PedidoClienteRepository
+	HistorialEstados() PedidoEstadoHistorialRepository
+	MovimientosStock() MovimientoStockRepository
+	Modificadores() ModificadorRepository
+	ReglasImpuesto() ReglaImpuestoRepository
+	Promociones() PromocionRepository
+	Cancelaciones() CancelacionRepository
+	Reembolsos() ReembolsoRepository
+	Mesas() MesaRepository
+	TicketsCocina() TicketCocinaRepository
+	TiemposPedido() TiempoPedidoRepository
+	Impresoras() ImpresoraRepository
+	TrabajosImpresion() TrabajoImpresionRepository
+	ResolucionesFacturacion() ResolucionFacturacionRepository
+	DocumentosElectronicos() DocumentoElectronicoRepository
+	Restaurantes() RestauranteRepository
+	CambiosHorario() CambioHorarioRepository
+	Favoritos() PedidoFavoritoRepository
+
+	// Transaction ejecuta fn con repositorios que comparten una transacción: se confirma si fn
+	// termina sin error y se revierte en caso contrario. Dentro de una transacción se reutiliza la actual.
+	Transaction(fn func(tx Store) error) error
+	// AfterCommit ejecuta fn cuando se confirma la transacción en curso, o de inmediato fuera de una
+	// transacción. Si la transacción se revierte fn no se ejecuta.
+	AfterCommit(fn func())
+}
+
+// PedidoFiltros agrupa los criterios opcionales de búsqueda de pedidos
+type PedidoFiltros struct {
+	Fecha      string
+	Desde      string
+	Hasta      string
+	Mes        int
+	Anio       int
+	Cliente    int
+	MetodoPago string
+	// Domicilio es nil cuando no se debe filtrar por domicilio
+	Domicilio *bool
+	Mesa      int64
+	// Programados deja solo los pedidos con PROGRAMADO_PARA
+	Programados bool
+}
+
+// NominaFiltros agrupa los criterios opcionales para consultar las nóminas de un trabajador
+type NominaFiltros struct {
+	Actual  bool
+	Pagas   bool
+	NoPagas bool
+	Mes     int
+	Anio    int
+}
+
+// TrabajadorFiltros agrupa los criterios opcionales de búsqueda de trabajadores
+type TrabajadorFiltros struct {
+	FechaIngreso string
+	Rol          string
+	// IncluirRetirados agrega los trabajadores con fecha de retiro; SoloRetirados tiene prioridad
+	IncluirRetirados bool
+	SoloRetirados    bool
+}
+
+type PedidoRepository interface {
+	Get(id int) (*models.Pedido, error)
+	Search(filtros PedidoFiltros) ([]models.Pedido, error)
+	Details(id int64) (*models.PedidoDetails, error)
+	Insert(pedido *models.Pedido) error
+	Update(pedido *models.Pedido, expected int, cols ...string) error
+}
+
+type PagoRepository interface {
+	Get(id int) (*models.Pago, error)
+	List() ([]models.Pago, error)
+	// ListByPedido devuelve los pagos del pedido en el orden en que se registraron
+	ListByPedido(pedidoID int) ([]models.Pago, error)
+	Insert(pago *models.Pago) error
+	Update(pago *models.Pago, expected int, cols ...string) error
+	Delete(id int) error
+}
+
+type MetodoPagoRepository interface {
+	Get(id int) (*models.MetodoPago, error)
+	Insert(metodo *models.MetodoPago) error
+}
+
+type DomicilioRepository interface {
+	Get(id int) (*models.Domicilio, error)
+	Insert(domicilio *models.Domicilio) error
+	Update(domicilio *models.Domicilio, expected int, cols ...string) error
+	Delete(id int) error
+}
+
+type ClienteRepository interface {
+	Get(documento int) (*models.Cliente, error)
+	List() ([]models.Cliente, error)
+	// ListByTelefono devuelve los clientes cuyo TELEFONO, sin espacios, guiones, puntos,
+	// paréntesis ni el signo +, es alguno de numeros
+	ListByTelefono(numeros []string) ([]models.Cliente, error)
+	Insert(cliente *models.Cliente) error
+}
+
+type TrabajadorRepository interface {
+	Get(documento int64) (*models.Trabajador, error)
+	List(filtros TrabajadorFiltros) ([]models.Trabajador, error)
+	Insert(trabajador *models.Trabajador) error
+	Update(trabajador *models.Trabajador, expected int, cols ...string) error
+}
+
+type ProductoRepository interface {
+	Get(id int64) (*models.Producto, error)
+	List(onlyActive bool) ([]models.Producto, error)
+	Insert(producto *models.Producto) error
+	Update(producto *models.Producto, expected int, cols ...string) error
+	// AjustarStock suma delta a CANTIDAD en una sola sentencia y devuelve ErrStockInsuficiente si
+	// quedaría negativa. En la misma sentencia el producto pasa a NO DISPONIBLE al llegar a cero y
+	// vuelve a DISPONIBLE cuando se repone desde cero.
+	AjustarStock(id int64, delta int) (*models.Producto, error)
+}
+
+type ReservaRepository interface {
+	Get(id int) (*models.Reserva, error)
+	List() ([]models.Reserva, error)
+	Insert(reserva *models.Reserva) error
+	Update(reserva *models.Reserva, expected int, cols ...string) error
+}
+
+type IncidenciaRepository interface {
+	ListByTrabajador(documento int64, desde, hasta time.Time) ([]models.Incidencia, error)
+	Insert(incidencia *models.Incidencia) error
+}
+
+type NominaRepository interface {
+	Get(id int64) (*models.Nomina, error)
+	Insert(nomina *models.Nomina) error
+}
+
+type NominaTrabajadorRepository interface {
+	ListByTrabajador(documento int64, filtros NominaFiltros) ([]models.NominaTrabajador, error)
+	Insert(nominaTrabajador *models.NominaTrabajador) error
+}
+
+type DetallePedidoRepository interface {
+	// ListByPedido devuelve las líneas del pedido en el orden en que se registraron
+	ListByPedido(pedidoID int) ([]models.DetallePedido, error)
+	Insert(detalles []models.DetallePedido) error
+	// UpdateDescuento guarda la parte del descuento de las promociones que le toca a la línea
+	UpdateDescuento(id int64, descuento int64) error
+	// UpdateCantidad cambia las unidades de la línea y las de su ítem en los tickets de cocina
+	UpdateCantidad(id int64, cantidad int) error
+	// Delete borra las líneas indicadas con sus opciones y sus ítems en los tickets de cocina
+	Delete(ids []int64) error
+}
+
+type PedidoClienteRepository interface {
+	List() ([]models.PedidoCliente, error)
+	ExistsForPedido(pedidoID int) (bool, error)
+	// GetByPedido devuelve la relación del pedido con su cliente o ErrNotFound si no tiene
+	GetByPedido(pedidoID int) (*models.PedidoCliente, error)
+	// ListByPedido devuelve los clientes del pedido; hay varios cuando dividen la cuenta
+	ListByPedido(pedidoID int) ([]models.PedidoCliente, error)
+	Insert(relacion *models.PedidoCliente) error
+}
+
+type PedidoEstadoHistorialRepository interface {
+	// ListByPedido devuelve los cambios de estado del pedido en orden cronológico
+	ListByPedido(pedidoID int) ([]models.PedidoEstadoHistorial, error)
+	Insert(historial *models.PedidoEstadoHistorial) error
+}
+
+type MovimientoStockRepository interface {
+	// ListByProducto devuelve los movimientos del producto del más reciente al más antiguo
+	ListByProducto(productoID int64) ([]models.MovimientoStock, error)
+	Insert(movimiento *models.MovimientoStock) error
+}
+
+// ModificadorRepository guarda los grupos de modificadores junto con sus opciones
+type ModificadorRepository interface {
+	// ListByProducto devuelve los grupos del producto con sus OPCIONES
+	ListByProducto(productoID int64) ([]models.GrupoModificador, error)
+	Get(id int64) (*models.GrupoModificador, error)
+	// Insert guarda el grupo y cada una de sus OPCIONES
+	Insert(grupo *models.GrupoModificador) error
+	// Delete borra el grupo y sus opciones
+	Delete(id int64) error
+}
+
+type ReglaImpuestoRepository interface {
+	List() ([]models.ReglaImpuesto, error)
+	Get(categoria string) (*models.ReglaImpuesto, error)
+	// Save crea la regla o reemplaza la tarifa y el nombre de una categoría existente
+	Save(regla *models.ReglaImpuesto) error
+}
+
+type PromocionRepository interface {
+	Get(id int64) (*models.Promocion, error)
+	GetByCodigo(codigo string) (*models.Promocion, error)
+	List(soloActivas bool) ([]models.Promocion, error)
+	Insert(promocion *models.Promocion) error
+	Update(promocion *models.Promocion, cols ...string) error
+	// ListByPedido devuelve las promociones aplicadas al pedido
+	ListByPedido(pedidoID int) ([]models.PedidoPromocion, error)
+	// ReplaceForPedido borra las promociones aplicadas al pedido y guarda aplicadas en su lugar
+	ReplaceForPedido(pedidoID int, aplicadas []models.PedidoPromocion) error
+	// ContarUsos cuenta los pedidos no cancelados, distintos de excluir, que tienen aplicada la
+	// promoción; con documento solo cuenta los de ese cliente
+	ContarUsos(promocionID int64, documento *int64, excluir int) (int, error)
+	// Bloquear toma la fila de la promoción hasta que termine la transacción, para que dos pedidos
+	// no cuenten a la vez los mismos usos disponibles
+	Bloquear(promocionID int64) error
+}
+
+type CancelacionRepository interface {
+	// GetByPedido devuelve la cancelación del pedido o ErrNotFound si no se ha cancelado
+	GetByPedido(pedidoID int) (*models.CancelacionPedido, error)
+	// List devuelve las cancelaciones con FECHA en [desde, hasta) en orden cronológico
+	List(desde, hasta time.Time) ([]models.CancelacionPedido, error)
+	Insert(cancelacion *models.CancelacionPedido) error
+}
+
+type ReembolsoRepository interface {
+	// ListByPedido devuelve los reembolsos del pedido, uno por cada pago cobrado
+	ListByPedido(pedidoID int) ([]models.Reembolso, error)
+	Insert(reembolso *models.Reembolso) error
+}
+
+type MesaRepository interface {
+	// List devuelve las mesas ordenadas por zona y número
+	List() ([]models.Mesa, error)
+	Get(id int64) (*models.Mesa, error)
+	Insert(mesa *models.Mesa) error
+	Update(mesa *models.Mesa, cols ...string) error
+}
+
+// TicketCocinaRepository guarda los tickets de cocina junto con sus ITEMS
+type TicketCocinaRepository interface {
+	// List devuelve los tickets con sus ITEMS del más antiguo al más reciente; estacion vacía es
+	// cualquier estación y estados vacío cualquier estado
+	List(estacion string, estados []string) ([]models.TicketCocina, error)
+	// ListByPedido devuelve los tickets del pedido con sus ITEMS
+	ListByPedido(pedidoID int) ([]models.TicketCocina, error)
+	Get(id int64) (*models.TicketCocina, error)
+	// Insert guarda el ticket y cada uno de sus ITEMS
+	Insert(ticket *models.TicketCocina) error
+	// Update guarda el estado del ticket y el de cada uno de sus ITEMS
+	Update(ticket *models.TicketCocina) error
+}
+
+// TiempoPedidoRepository guarda los minutos estimados y reales de los pedidos, uno por pedido
+type TiempoPedidoRepository interface {
+	Get(pedidoID int) (*models.TiempoPedido, error)
+	// List devuelve los tiempos de los pedidos con INICIO en [desde, hasta) en orden cronológico
+	List(desde, hasta time.Time) ([]models.TiempoPedido, error)
+	Insert(tiempo *models.TiempoPedido) error
+	Update(tiempo *models.TiempoPedido) error
+}
+
+// ImpresoraRepository guarda las impresoras de red
+type ImpresoraRepository interface {
+	// List devuelve las impresoras en el orden en que se registraron
+	List() ([]models.Impresora, error)
+	Get(id int64) (*models.Impresora, error)
+	Insert(impresora *models.Impresora) error
+	Update(impresora *models.Impresora) error
+	Delete(id int64) error
+}
+
+// TrabajoImpresionRepository guarda la cola de impresión
+type TrabajoImpresionRepository interface {
+	// List devuelve los trabajos del más antiguo al más reciente; estado vacío es cualquier estado
+	List(estado string) ([]models.TrabajoImpresion, error)
+	Get(id int64) (*models.TrabajoImpresion, error)
+	Insert(trabajo *models.TrabajoImpresion) error
+	Update(trabajo *models.TrabajoImpresion) error
+}
+
+// ResolucionFacturacionRepository guarda los rangos de numeración de la DIAN
+type ResolucionFacturacionRepository interface {
+	List() ([]models.ResolucionFacturacion, error)
+	Get(id int64) (*models.ResolucionFacturacion, error)
+	Insert(resolucion *models.ResolucionFacturacion) error
+	// Update guarda todo menos SIGUIENTE, que solo cambia con Consumir
+	Update(resolucion *models.ResolucionFacturacion) error
+	// Consumir asigna el consecutivo SIGUIENTE y lo avanza en una sola sentencia, para que dos
+	// documentos nunca reciban el mismo número. Devuelve ErrRangoAgotado si ya pasó de HASTA.
+	Consumir(id int64) (int64, error)
+}
+
+// DocumentoElectronicoRepository guarda las facturas y documentos equivalentes firmados
+type DocumentoElectronicoRepository interface {
+	// List devuelve los documentos en el orden en que se emitieron; pedidoID 0 es cualquier pedido
+	List(pedidoID int) ([]models.DocumentoElectronico, error)
+	Get(id int64) (*models.DocumentoElectronico, error)
+	// GetByPedido devuelve el documento del pedido o ErrNotFound si no tiene
+	GetByPedido(pedidoID int) (*models.DocumentoElectronico, error)
+	Insert(documento *models.DocumentoElectronico) error
+	// Update guarda el resultado del envío: ESTADO, ID_ENVIO y RESPUESTA
+	Update(documento *models.DocumentoElectronico) error
+}
+
+// RestauranteRepository da acceso a los datos del restaurante, entre ellos su horario habitual
+type RestauranteRepository interface {
+	// List devuelve los restaurantes por su identificador
+	List() ([]models.Restaurante, error)
+	Get(id int) (*models.Restaurante, error)
+	Insert(restaurante *models.Restaurante) error
+}
+
+// CambioHorarioRepository guarda las excepciones al horario habitual de un día
+type CambioHorarioRepository interface {
+	// GetByFecha devuelve el cambio de horario del día o ErrNotFound si no tiene
+	GetByFecha(fecha time.Time) (*models.CambiosHorario, error)
+	Insert(cambio *models.CambiosHorario) error
+}
+
+// PedidoFavoritoRepository guarda los pedidos favoritos de los clientes
+type PedidoFavoritoRepository interface {
+	// ListByCliente devuelve los favoritos del cliente del más reciente al más antiguo
+	ListByCliente(documento int64) ([]models.PedidoFavorito, error)
+	Get(id int64) (*models.PedidoFavorito, error)
+	Insert(favorito *models.PedidoFavorito) error
+	Delete(id int64) error
+}
@@ -0,0 +1,1000 @@
+package memory
+
+import (
+	"restaurante/models"
+	"restaurante/repositories"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type pedidoRepository struct{ t *tables }
+
+func (r *pedidoRepository) Get(id int) (*models.Pedido, error) {
+	return r.t.pedidos.get(int64(id))
+}
+
+func (r *pedidoRepository) Search(filtros repositories.PedidoFiltros) ([]models.Pedido, error) {
+	return r.t.pedidos.list(func(p models.Pedido) bool {
+		fecha := p.FECHA.Format("2006-01-02")
+		if filtros.Fecha != "" && fecha != filtros.Fecha {
+			return false
+		}
+		if filtros.Desde != "" && filtros.Hasta != "" && (fecha < filtros.Desde || fecha > filtros.Hasta) {
+			return false
+		}
+		if filtros.Mes > 0 && filtros.Mes <= 12 {
+			if int(p.FECHA.Month()) != filtros.Mes || (filtros.Anio > 0 && p.FECHA.Year() != filtros.Anio) {
+				return false
+			}
+		}
+		if filtros.Cliente > 0 && !r.perteneceA(p.PK_ID_PEDIDO, filtros.Cliente) {
+			return false
+		}
+		if filtros.MetodoPago != "" && !strings.EqualFold(r.metodoPago(p), filtros.MetodoPago) {
+			return false
+		}
+		if filtros.Domicilio != nil && (p.PK_ID_DOMICILIO != nil) != *filtros.Domicilio {
+			return false
+		}
+		if filtros.Mesa > 0 && (p.PK_ID_MESA == nil || *p.PK_ID_MESA != filtros.Mesa) {
+			return false
+		}
+		if filtros.Programados && p.PROGRAMADO_PARA == nil {
+			return false
+		}
+		return true
+	}), nil
+}
+
+func (r *pedidoRepository) perteneceA(pedidoID, cliente int) bool {
+	relaciones := r.t.pedidosClientes.list(func(pc models.PedidoCliente) bool {
+		return pc.PK_ID_PEDIDO != nil && *pc.PK_ID_PEDIDO == pedidoID &&
+			pc.PK_DOCUMENTO_CLIENTE != nil && *pc.PK_DOCUMENTO_CLIENTE == int64(cliente)
+	})
+	return len(relaciones) > 0
+}
+
+func (r *pedidoRepository) metodoPago(p models.Pedido) string {
+	if p.PK_ID_PAGO == nil {
+		return ""
+	}
+	pago, err := r.t.pagos.get(int64(*p.PK_ID_PAGO))
+	if err != nil {
+		return ""
+	}
+	metodo, err := r.t.metodosPago.get(int64(pago.PK_ID_METODO_PAGO))
+	if err != nil {
+		return ""
+	}
+	return metodo.TIPO
+}
+
+func (r *pedidoRepository) Details(id int64) (*models.PedidoDetails, error) {
+	pedido, err := r.t.pedidos.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PedidoDetails{
+		PKIDPedido:      int64(pedido.PK_ID_PEDIDO),
+		Fecha:           pedido.FECHA.Format("2006-01-02"),
+		Hora:            pedido.HORA,
+		Delivery:        pedido.DELIVERY,
+		EstadoPedido:    pedido.ESTADO_PEDIDO,
+		MetodoPago:      r.metodoPago(*pedido),
+		Subtotal:        pedido.SUBTOTAL,
+		Descuento:       pedido.DESCUENTO,
+		Impuesto:        pedido.IMPUESTO,
+		ValorDomicilio:  pedido.VALOR_DOMICILIO,
+		PropinaSugerida: pedido.PROPINA_SUGERIDA,
+		PropinaAceptada: pedido.PROPINA_ACEPTADA,
+		Propina:         pedido.PROPINA,
+		Total:           pedido.TOTAL,
+		Version:         pedido.VERSION,
+	}, nil
+}
+
+func (r *pedidoRepository) Insert(pedido *models.Pedido) error {
+	pedido.PK_ID_PEDIDO = int(r.t.pedidos.nextID(int64(pedido.PK_ID_PEDIDO)))
+	r.t.pedidos.rows[int64(pedido.PK_ID_PEDIDO)] = *pedido
+	return nil
+}
+
+func (r *pedidoRepository) Update(pedido *models.Pedido, expected int, cols ...string) error {
+	return updateVersioned(r.t.pedidos, int64(pedido.PK_ID_PEDIDO), pedido, expected)
+}
+
+type pagoRepository struct{ t *tables }
+
+func (r *pagoRepository) Get(id int) (*models.Pago, error) {
+	return r.t.pagos.get(int64(id))
+}
+
+func (r *pagoRepository) List() ([]models.Pago, error) {
+	return r.t.pagos.list(nil), nil
+}
+
+func (r *pagoRepository) ListByPedido(pedidoID int) ([]models.Pago, error) {
+	return r.t.pagos.list(func(p models.Pago) bool {
+		return p.PK_ID_PEDIDO != nil && *p.PK_ID_PEDIDO == pedidoID
+	}), nil
+}
+
+func (r *pagoRepository) Insert(pago *models.Pago) error {
+	pago.PK_ID_PAGO = int(r.t.pagos.nextID(int64(pago.PK_ID_PAGO)))
+	r.t.pagos.rows[int64(pago.PK_ID_PAGO)] = *pago
+	return nil
+}
+
+func (r *pagoRepository) Update(pago *models.Pago, expected int, cols ...string) error {
+	return updateVersioned(r.t.pagos, int64(pago.PK_ID_PAGO), pago, expected)
+}
+
+func (r *pagoRepository) Delete(id int) error {
+	return r.t.pagos.delete(int64(id))
+}
+
+type metodoPagoRepository struct{ t *tables }
+
+func (r *metodoPagoRepository) Get(id int) (*models.MetodoPago, error) {
+	return r.t.metodosPago.get(int64(id))
+}
+
+func (r *metodoPagoRepository) Insert(metodo *models.MetodoPago) error {
+	metodo.PK_ID_METODO_PAGO = int(r.t.metodosPago.nextID(int64(metodo.PK_ID_METODO_PAGO)))
+	r.t.metodosPago.rows[int64(metodo.PK_ID_METODO_PAGO)] = *metodo
+	return nil
+}
+
+type domicilioRepository struct{ t *tables }
+
+func (r *domicilioRepository) Get(id int) (*models.Domicilio, error) {
+	return r.t.domicilios.get(int64(id))
+}
+
+func (r *domicilioRepository) Insert(domicilio *models.Domicilio) error {
+	domicilio.PK_ID_DOMICILIO = int(r.t.domicilios.nextID(int64(domicilio.PK_ID_DOMICILIO)))
+	r.t.domicilios.rows[int64(domicilio.PK_ID_DOMICILIO)] = *domicilio
+	return nil
+}
+
+func (r *domicilioRepository) Update(domicilio *models.Domicilio, expected int, cols ...string) error {
+	return updateVersioned(r.t.domicilios, int64(domicilio.PK_ID_DOMICILIO), domicilio, expected)
+}
+
+func (r *domicilioRepository) Delete(id int) error {
+	return r.t.domicilios.delete(int64(id))
+}
+
+type clienteRepository struct{ t *tables }
+
+func (r *clienteRepository) Get(documento int) (*models.Cliente, error) {
+	return r.t.clientes.get(int64(documento))
+}
+
+func (r *clienteRepository) List() ([]models.Cliente, error) {
+	return r.t.clientes.list(nil), nil
+}
+
+func (r *clienteRepository) ListByTelefono(numeros []string) ([]models.Cliente, error) {
+	separadores := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", "+", "")
+	return r.t.clientes.list(func(c models.Cliente) bool {
+		return slices.Contains(numeros, separadores.Replace(c.TELEFONO))
+	}), nil
+}
+
+func (r *clienteRepository) Insert(cliente *models.Cliente) error {
+	r.t.clientes.rows[r.t.clientes.nextID(int64(cliente.PK_DOCUMENTO_CLIENTE))] = *cliente
+	return nil
+}
+
+type trabajadorRepository struct{ t *tables }
+
+func (r *trabajadorRepository) Get(documento int64) (*models.Trabajador, error) {
+	return r.t.trabajadores.get(documento)
+}
+
+func (r *trabajadorRepository) List(filtros repositories.TrabajadorFiltros) ([]models.Trabajador, error) {
+	return r.t.trabajadores.list(func(t models.Trabajador) bool {
+		retirado := t.FECHA_RETIRO != nil
+		if filtros.SoloRetirados && !retirado {
+			return false
+		}
+		if !filtros.SoloRetirados && !filtros.IncluirRetirados && retirado {
+			return false
+		}
+		if filtros.FechaIngreso != "" && t.FECHA_INGRESO.Format("2006-01-02") != filtros.FechaIngreso {
+			return false
+		}
+		return filtros.Rol == "" || t.ROL == filtros.Rol
+	}), nil
+}
+
+func (r *trabajadorRepository) Insert(trabajador *models.Trabajador) error {
+	r.t.trabajadores.rows[r.t.trabajadores.nextID(trabajador.PK_DOCUMENTO_TRABAJADOR)] = *trabajador
+	return nil
+}
+
+func (r *trabajadorRepository) Update(trabajador *models.Trabajador, expected int, cols ...string) error {
+	return updateVersioned(r.t.trabajadores, trabajador.PK_DOCUMENTO_TRABAJADOR, trabajador, expected)
+}
+
+type productoRepository struct{ t *tables }
+
+func (r *productoRepository) Get(id int64) (*models.Producto, error) {
+	return r.t.productos.get(id)
+}
+
+func (r *productoRepository) List(onlyActive bool) ([]models.Producto, error) {
+	return r.t.productos.list(func(p models.Producto) bool {
+		return !onlyActive || p.ESTADO_PRODUCTO == "DISPONIBLE"
+	}), nil
+}
+
+func (r *productoRepository) Insert(producto *models.Producto) error {
+	producto.PK_ID_PRODUCTO = r.t.productos.nextID(producto.PK_ID_PRODUCTO)
+	r.t.productos.rows[producto.PK_ID_PRODUCTO] = *producto
+	return nil
+}
+
+func (r *productoRepository) Update(producto *models.Producto, expected int, cols ...string) error {
+	return updateVersioned(r.t.productos, producto.PK_ID_PRODUCTO, producto, expected)
+}
+
+func (r *productoRepository) AjustarStock(id int64, delta int) (*models.Producto, error) {
+	producto, err := r.t.productos.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if producto.CANTIDAD+delta < 0 {
+		return producto, repositories.ErrStockInsuficiente
+	}
+
+	switch {
+	case producto.CANTIDAD+delta <= 0:
+		producto.ESTADO_PRODUCTO = "NO DISPONIBLE"
+	case producto.CANTIDAD <= 0:
+		producto.ESTADO_PRODUCTO = "DISPONIBLE"
+	}
+	producto.CANTIDAD += delta
+	producto.VERSION++
+	r.t.productos.rows[id] = *producto
+	return producto, nil
+}
+
+type reservaRepository struct{ t *tables }
+
+func (r *reservaRepository) Get(id int) (*models.Reserva, error) {
+	return r.t.reservas.get(int64(id))
+}
+
+func (r *reservaRepository) List() ([]models.Reserva, error) {
+	return r.t.reservas.list(nil), nil
+}
+
+func (r *reservaRepository) Insert(reserva *models.Reserva) error {
+	reserva.PK_ID_RESERVA = int(r.t.reservas.nextID(int64(reserva.PK_ID_RESERVA)))
+	r.t.reservas.rows[int64(reserva.PK_ID_RESERVA)] = *reserva
+	return nil
+}
+
+func (r *reservaRepository) Update(reserva *models.Reserva, expected int, cols ...string) error {
+	return updateVersioned(r.t.reservas, int64(reserva.PK_ID_RESERVA), reserva, expected)
+}
+
+type incidenciaRepository struct{ t *tables }
+
+func (r *incidenciaRepository) ListByTrabajador(documento int64, desde, hasta time.Time) ([]models.Incidencia, error) {
+	return r.t.incidencias.list(func(i models.Incidencia) bool {
+		return i.PK_DOCUMENTO_TRABAJADOR != nil && *i.PK_DOCUMENTO_TRABAJADOR == documento && !i.FECHA.Before(desde) && !i.FECHA.After(hasta)
+	}), nil
+}
+
+func (r *incidenciaRepository) Insert(incidencia *models.Incidencia) error {
+	incidencia.PK_ID_INCIDENCIA = r.t.incidencias.nextID(incidencia.PK_ID_INCIDENCIA)
+	r.t.incidencias.rows[incidencia.PK_ID_INCIDENCIA] = *incidencia
+	return nil
+}
+
+type nominaRepository struct{ t *tables }
+
+func (r *nominaRepository) Get(id int64) (*models.Nomina, error) {
+	return r.t.nominas.get(id)
+}
+
+func (r *nominaRepository) Insert(nomina *models.Nomina) error {
+	nomina.PK_ID_NOMINA = r.t.nominas.nextID(nomina.PK_ID_NOMINA)
+	r.t.nominas.rows[nomina.PK_ID_NOMINA] = *nomina
+	return nil
+}
+
+type nominaTrabajadorRepository struct{ t *tables }
+
+func (r *nominaTrabajadorRepository) ListByTrabajador(documento int64, filtros repositories.NominaFiltros) ([]models.NominaTrabajador, error) {
+	var ultima time.Time
+	for _, n := range r.t.nominas.list(nil) {
+		if n.FECHA.After(ultima) {
+			ultima = n.FECHA
+		}
+	}
+
+	return r.t.nominasTrabajador.list(func(nt models.NominaTrabajador) bool {
+		if nt.PK_DOCUMENTO_TRABAJADOR != documento || nt.PK_ID_NOMINA == nil {
+			return false
+		}
+		nomina, err := r.t.nominas.get(*nt.PK_ID_NOMINA)
+		if err != nil {
+			return false
+		}
+		if filtros.Actual && !nomina.FECHA.Equal(ultima) {
+			return false
+		}
+		if filtros.Pagas && nomina.ESTADO_NOMINA != "PAGO" {
+			return false
+		} else if !filtros.Pagas && filtros.NoPagas && nomina.ESTADO_NOMINA != "NO PAGO" {
+			return false
+		}
+		if filtros.Mes > 0 && filtros.Anio > 0 {
+			return int(nomina.FECHA.Month()) == filtros.Mes && nomina.FECHA.Year() == filtros.Anio
+		}
+		return true
+	}), nil
+}
+
+func (r *nominaTrabajadorRepository) Insert(nominaTrabajador *models.NominaTrabajador) error {
+	nominaTrabajador.PK_ID_NOMINA_TRABAJADOR = r.t.nominasTrabajador.nextID(nominaTrabajador.PK_ID_NOMINA_TRABAJADOR)
+	r.t.nominasTrabajador.rows[nominaTrabajador.PK_ID_NOMINA_TRABAJADOR] = *nominaTrabajador
+	return nil
+}
+
+type detallePedidoRepository struct{ t *tables }
+
+func (r *detallePedidoRepository) ListByPedido(pedidoID int) ([]models.DetallePedido, error) {
+	detalles := r.t.detallesPedido.list(func(d models.DetallePedido) bool { return d.PK_ID_PEDIDO == pedidoID })
+	for i := range detalles {
+		id := detalles[i].PK_ID_DETALLE_PEDIDO
+		detalles[i].MODIFICADORES = r.t.detalleOpciones.list(func(m models.DetallePedidoModificador) bool { return m.PK_ID_DETALLE_PEDIDO == id })
+		if len(detalles[i].MODIFICADORES) == 0 {
+			detalles[i].MODIFICADORES = nil
+		}
+	}
+	return detalles, nil
+}
+
+func (r *detallePedidoRepository) Insert(detalles []models.DetallePedido) error {
+	for i := range detalles {
+		detalles[i].PK_ID_DETALLE_PEDIDO = r.t.detallesPedido.nextID(detalles[i].PK_ID_DETALLE_PEDIDO)
+		for j := range detalles[i].MODIFICADORES {
+			m := &detalles[i].MODIFICADORES[j]
+			m.PK_ID_DETALLE_PEDIDO = detalles[i].PK_ID_DETALLE_PEDIDO
+			m.PK_ID_DETALLE_MODIFICADOR = r.t.detalleOpciones.nextID(m.PK_ID_DETALLE_MODIFICADOR)
+			r.t.detalleOpciones.rows[m.PK_ID_DETALLE_MODIFICADOR] = *m
+		}
+		// Las opciones viven en su propia tabla, como en Postgres
+		detalle := detalles[i]
+		detalle.MODIFICADORES = nil
+		r.t.detallesPedido.rows[detalle.PK_ID_DETALLE_PEDIDO] = detalle
+	}
+	return nil
+}
+
+func (r *detallePedidoRepository) UpdateDescuento(id int64, descuento int64) error {
+	detalle, err := r.t.detallesPedido.get(id)
+	if err != nil {
+		return err
+	}
+	detalle.DESCUENTO = descuento
+	r.t.detallesPedido.rows[id] = *detalle
+	return nil
+}
+
+func (r *detallePedidoRepository) UpdateCantidad(id int64, cantidad int) error {
+	detalle, err := r.t.detallesPedido.get(id)
+	if err != nil {
+		return err
+	}
+	detalle.CANTIDAD = cantidad
+	r.t.detallesPedido.rows[id] = *detalle
+	for _, i := range r.t.itemsTicket.list(func(i models.ItemTicket) bool { return i.PK_ID_DETALLE_PEDIDO == id }) {
+		i.CANTIDAD = cantidad
+		r.t.itemsTicket.rows[i.PK_ID_ITEM_TICKET] = i
+	}
+	return nil
+}
+
+func (r *detallePedidoRepository) Delete(ids []int64) error {
+	for _, id := range ids {
+		for _, m := range r.t.detalleOpciones.list(func(m models.DetallePedidoModificador) bool { return m.PK_ID_DETALLE_PEDIDO == id }) {
+			delete(r.t.detalleOpciones.rows, m.PK_ID_DETALLE_MODIFICADOR)
+		}
+		for _, i := range r.t.itemsTicket.list(func(i models.ItemTicket) bool { return i.PK_ID_DETALLE_PEDIDO == id }) {
+			delete(r.t.itemsTicket.rows, i.PK_ID_ITEM_TICKET)
+		}
+		delete(r.t.detallesPedido.rows, id)
+	}
+	return nil
+}
+
+type pedidoClienteRepository struct{ t *tables }
+
+func (r *pedidoClienteRepository) List() ([]models.PedidoCliente, error) {
+	return r.t.pedidosClientes.list(nil), nil
+}
+
+func (r *pedidoClienteRepository) ExistsForPedido(pedidoID int) (bool, error) {
+	relaciones := r.t.pedidosClientes.list(func(pc models.PedidoCliente) bool {
+		return pc.PK_ID_PEDIDO != nil && *pc.PK_ID_PEDIDO == pedidoID
+	})
+	return len(relaciones) > 0, nil
+}
+
+func (r *pedidoClienteRepository) GetByPedido(pedidoID int) (*models.PedidoCliente, error) {
+	relaciones := r.t.pedidosClientes.list(func(pc models.PedidoCliente) bool {
+		return pc.PK_ID_PEDIDO != nil && *pc.PK_ID_PEDIDO == pedidoID
+	})
+	if len(relaciones) == 0 {
+		return nil, repositories.ErrNotFound
+	}
+	return &relaciones[0], nil
+}
+
+func (r *pedidoClienteRepository) ListByPedido(pedidoID int) ([]models.PedidoCliente, error) {
+	return r.t.pedidosClientes.list(func(pc models.PedidoCliente) bool {
+		return pc.PK_ID_PEDIDO != nil && *pc.PK_ID_PEDIDO == pedidoID
+	}), nil
+}
+
+func (r *pedidoClienteRepository) Insert(relacion *models.PedidoCliente) error {
+	relacion.PK_ID_PEDIDO_CLIENTE = r.t.pedidosClientes.nextID(relacion.PK_ID_PEDIDO_CLIENTE)
+	r.t.pedidosClientes.rows[relacion.PK_ID_PEDIDO_CLIENTE] = *relacion
+	return nil
+}
+
+type pedidoEstadoHistorialRepository struct{ t *tables }
+
+func (r *pedidoEstadoHistorialRepository) ListByPedido(pedidoID int) ([]models.PedidoEstadoHistorial, error) {
+	return r.t.historialEstados.list(func(h models.PedidoEstadoHistorial) bool {
+		return h.PK_ID_PEDIDO == pedidoID
+	}), nil
+}
+
+func (r *pedidoEstadoHistorialRepository) Insert(historial *models.PedidoEstadoHistorial) error {
+	historial.PK_ID_HISTORIAL = r.t.historialEstados.nextID(historial.PK_ID_HISTORIAL)
+	r.t.historialEstados.rows[historial.PK_ID_HISTORIAL] = *historial
+	return nil
+}
+
+// updateVersioned guarda el registro completo si su versión sigue siendo expected y la incrementa
+func updateVersioned[T any, P interface {
+	*T
+	repositories.Versioned
+}](t *table[T], id int64, row P, expected int) error {
+	current, err := t.get(id)
+	if err != nil {
+		return err
+	}
+	if P(current).GetVersion() != expected {
+		return repositories.ErrVersionConflict
+	}
+	row.SetVersion(expected + 1)
+	t.rows[id] = *row
+	return nil
+}
+
+type movimientoStockRepository struct{ t *tables }
+
+func (r *movimientoStockRepository) ListByProducto(productoID int64) ([]models.MovimientoStock, error) {
+	movimientos := r.t.movimientosStock.list(func(m models.MovimientoStock) bool { return m.PK_ID_PRODUCTO == productoID })
+	for i, j := 0, len(movimientos)-1; i < j; i, j = i+1, j-1 {
+		movimientos[i], movimientos[j] = movimientos[j], movimientos[i]
+	}
+	return movimientos, nil
+}
+
+func (r *movimientoStockRepository) Insert(movimiento *models.MovimientoStock) error {
+	movimiento.PK_ID_MOVIMIENTO = r.t.movimientosStock.nextID(movimiento.PK_ID_MOVIMIENTO)
+	r.t.movimientosStock.rows[movimiento.PK_ID_MOVIMIENTO] = *movimiento
+	return nil
+}
+
+type modificadorRepository struct{ t *tables }
+
+func (r *modificadorRepository) ListByProducto(productoID int64) ([]models.GrupoModificador, error) {
+	grupos := r.t.grupos.list(func(g models.GrupoModificador) bool { return g.PK_ID_PRODUCTO == productoID })
+	for i := range grupos {
+		grupos[i].OPCIONES = r.opciones(grupos[i].PK_ID_GRUPO_MODIFICADOR)
+	}
+	return grupos, nil
+}
+
+func (r *modificadorRepository) Get(id int64) (*models.GrupoModificador, error) {
+	grupo, err := r.t.grupos.get(id)
+	if err != nil {
+		return nil, err
+	}
+	grupo.OPCIONES = r.opciones(id)
+	return grupo, nil
+}
+
+func (r *modificadorRepository) Insert(grupo *models.GrupoModificador) error {
+	grupo.PK_ID_GRUPO_MODIFICADOR = r.t.grupos.nextID(grupo.PK_ID_GRUPO_MODIFICADOR)
+	for i := range grupo.OPCIONES {
+		opcion := &grupo.OPCIONES[i]
+		opcion.PK_ID_GRUPO_MODIFICADOR = grupo.PK_ID_GRUPO_MODIFICADOR
+		opcion.PK_ID_OPCION_MODIFICADOR = r.t.opciones.nextID(opcion.PK_ID_OPCION_MODIFICADOR)
+		r.t.opciones.rows[opcion.PK_ID_OPCION_MODIFICADOR] = *opcion
+	}
+	row := *grupo
+	row.OPCIONES = nil
+	r.t.grupos.rows[row.PK_ID_GRUPO_MODIFICADOR] = row
+	return nil
+}
+
+func (r *modificadorRepository) Delete(id int64) error {
+	if err := r.t.grupos.delete(id); err != nil {
+		return err
+	}
+	for _, o := range r.opciones(id) {
+		delete(r.t.opciones.rows, o.PK_ID_OPCION_MODIFICADOR)
+	}
+	return nil
+}
+
+func (r *modificadorRepository) opciones(grupoID int64) []models.OpcionModificador {
+	return r.t.opciones.list(func(o models.OpcionModificador) bool { return o.PK_ID_GRUPO_MODIFICADOR == grupoID })
+}
+
+type reglaImpuestoRepository struct{ t *tables }
+
+func (r *reglaImpuestoRepository) List() ([]models.ReglaImpuesto, error) {
+	reglas := make([]models.ReglaImpuesto, 0, len(r.t.reglasImpuesto))
+	for _, regla := range r.t.reglasImpuesto {
+		reglas = append(reglas, regla)
+	}
+	sort.Slice(reglas, func(i, j int) bool { return reglas[i].CATEGORIA < reglas[j].CATEGORIA })
+	return reglas, nil
+}
+
+func (r *reglaImpuestoRepository) Get(categoria string) (*models.ReglaImpuesto, error) {
+	regla, ok := r.t.reglasImpuesto[categoria]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &regla, nil
+}
+
+func (r *reglaImpuestoRepository) Save(regla *models.ReglaImpuesto) error {
+	r.t.reglasImpuesto[regla.CATEGORIA] = *regla
+	return nil
+}
+
+type promocionRepository struct{ t *tables }
+
+func (r *promocionRepository) Get(id int64) (*models.Promocion, error) {
+	return r.t.promociones.get(id)
+}
+
+func (r *promocionRepository) GetByCodigo(codigo string) (*models.Promocion, error) {
+	promociones := r.t.promociones.list(func(p models.Promocion) bool { return p.CODIGO != nil && *p.CODIGO == codigo })
+	if len(promociones) == 0 {
+		return nil, repositories.ErrNotFound
+	}
+	return &promociones[0], nil
+}
+
+func (r *promocionRepository) List(soloActivas bool) ([]models.Promocion, error) {
+	return r.t.promociones.list(func(p models.Promocion) bool { return !soloActivas || p.ACTIVA }), nil
+}
+
+func (r *promocionRepository) Insert(promocion *models.Promocion) error {
+	promocion.PK_ID_PROMOCION = r.t.promociones.nextID(promocion.PK_ID_PROMOCION)
+	r.t.promociones.rows[promocion.PK_ID_PROMOCION] = *promocion
+	return nil
+}
+
+func (r *promocionRepository) Update(promocion *models.Promocion, cols ...string) error {
+	if _, err := r.t.promociones.get(promocion.PK_ID_PROMOCION); err != nil {
+		return err
+	}
+	r.t.promociones.rows[promocion.PK_ID_PROMOCION] = *promocion
+	return nil
+}
+
+func (r *promocionRepository) ListByPedido(pedidoID int) ([]models.PedidoPromocion, error) {
+	return r.t.pedidoPromociones.list(func(p models.PedidoPromocion) bool { return p.PK_ID_PEDIDO == pedidoID }), nil
+}
+
+func (r *promocionRepository) ReplaceForPedido(pedidoID int, aplicadas []models.PedidoPromocion) error {
+	for _, p := range r.t.pedidoPromociones.list(func(p models.PedidoPromocion) bool { return p.PK_ID_PEDIDO == pedidoID }) {
+		delete(r.t.pedidoPromociones.rows, p.PK_ID_PEDIDO_PROMOCION)
+	}
+	for i := range aplicadas {
+		aplicadas[i].PK_ID_PEDIDO = pedidoID
+		aplicadas[i].PK_ID_PEDIDO_PROMOCION = r.t.pedidoPromociones.nextID(aplicadas[i].PK_ID_PEDIDO_PROMOCION)
+		r.t.pedidoPromociones.rows[aplicadas[i].PK_ID_PEDIDO_PROMOCION] = aplicadas[i]
+	}
+	return nil
+}
+
+func (r *promocionRepository) ContarUsos(promocionID int64, documento *int64, excluir int) (int, error) {
+	usos := r.t.pedidoPromociones.list(func(p models.PedidoPromocion) bool {
+		if p.PK_ID_PROMOCION != promocionID || p.PK_ID_PEDIDO == excluir {
+			return false
+		}
+		if documento != nil && (p.PK_DOCUMENTO_CLIENTE == nil || *p.PK_DOCUMENTO_CLIENTE != *documento) {
+			return false
+		}
+		pedido, ok := r.t.pedidos.rows[int64(p.PK_ID_PEDIDO)]
+		return ok && pedido.ESTADO_PEDIDO != "CANCELADO"
+	})
+	return len(usos), nil
+}
+
+// Bloquear no hace nada: las transacciones del Store en memoria ya se ejecutan de una en una
+func (r *promocionRepository) Bloquear(promocionID int64) error {
+	if _, ok := r.t.promociones.rows[promocionID]; !ok {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+type cancelacionRepository struct{ t *tables }
+
+func (r *cancelacionRepository) GetByPedido(pedidoID int) (*models.CancelacionPedido, error) {
+	cancelaciones := r.t.cancelaciones.list(func(c models.CancelacionPedido) bool { return c.PK_ID_PEDIDO == pedidoID })
+	if len(cancelaciones) == 0 {
+		return nil, repositories.ErrNotFound
+	}
+	return &cancelaciones[0], nil
+}
+
+func (r *cancelacionRepository) List(desde, hasta time.Time) ([]models.CancelacionPedido, error) {
+	cancelaciones := r.t.cancelaciones.list(func(c models.CancelacionPedido) bool {
+		return !c.FECHA.Before(desde) && c.FECHA.Before(hasta)
+	})
+	sort.SliceStable(cancelaciones, func(i, j int) bool { return cancelaciones[i].FECHA.Before(cancelaciones[j].FECHA) })
+	return cancelaciones, nil
+}
+
+func (r *cancelacionRepository) Insert(cancelacion *models.CancelacionPedido) error {
+	cancelacion.PK_ID_CANCELACION = r.t.cancelaciones.nextID(cancelacion.PK_ID_CANCELACION)
+	r.t.cancelaciones.rows[cancelacion.PK_ID_CANCELACION] = *cancelacion
+	return nil
+}
+
+type reembolsoRepository struct{ t *tables }
+
+func (r *reembolsoRepository) ListByPedido(pedidoID int) ([]models.Reembolso, error) {
+	return r.t.reembolsos.list(func(re models.Reembolso) bool {
+		return re.PK_ID_PEDIDO == pedidoID
+	}), nil
+}
+
+func (r *reembolsoRepository) Insert(reembolso *models.Reembolso) error {
+	reembolso.PK_ID_REEMBOLSO = r.t.reembolsos.nextID(reembolso.PK_ID_REEMBOLSO)
+	r.t.reembolsos.rows[reembolso.PK_ID_REEMBOLSO] = *reembolso
+	return nil
+}
+
+type mesaRepository struct{ t *tables }
+
+func (r *mesaRepository) List() ([]models.Mesa, error) {
+	mesas := r.t.mesas.list(nil)
+	sort.SliceStable(mesas, func(i, j int) bool {
+		if mesas[i].ZONA != mesas[j].ZONA {
+			return mesas[i].ZONA < mesas[j].ZONA
+		}
+		return mesas[i].NUMERO < mesas[j].NUMERO
+	})
+	return mesas, nil
+}
+
+func (r *mesaRepository) Get(id int64) (*models.Mesa, error) {
+	return r.t.mesas.get(id)
+}
+
+func (r *mesaRepository) Insert(mesa *models.Mesa) error {
+	mesa.PK_ID_MESA = r.t.mesas.nextID(mesa.PK_ID_MESA)
+	r.t.mesas.rows[mesa.PK_ID_MESA] = *mesa
+	return nil
+}
+
+func (r *mesaRepository) Update(mesa *models.Mesa, cols ...string) error {
+	if _, err := r.t.mesas.get(mesa.PK_ID_MESA); err != nil {
+		return err
+	}
+	r.t.mesas.rows[mesa.PK_ID_MESA] = *mesa
+	return nil
+}
+
+type ticketCocinaRepository struct{ t *tables }
+
+func (r *ticketCocinaRepository) List(estacion string, estados []string) ([]models.TicketCocina, error) {
+	tickets := r.t.tickets.list(func(t models.TicketCocina) bool {
+		return (estacion == "" || t.ESTACION == estacion) && (len(estados) == 0 || slices.Contains(estados, t.ESTADO))
+	})
+	sort.SliceStable(tickets, func(i, j int) bool { return tickets[i].CREADO.Before(tickets[j].CREADO) })
+	for i := range tickets {
+		tickets[i].ITEMS = r.items(tickets[i].PK_ID_TICKET)
+	}
+	return tickets, nil
+}
+
+func (r *ticketCocinaRepository) ListByPedido(pedidoID int) ([]models.TicketCocina, error) {
+	tickets := r.t.tickets.list(func(t models.TicketCocina) bool { return t.PK_ID_PEDIDO == pedidoID })
+	for i := range tickets {
+		tickets[i].ITEMS = r.items(tickets[i].PK_ID_TICKET)
+	}
+	return tickets, nil
+}
+
+func (r *ticketCocinaRepository) Get(id int64) (*models.TicketCocina, error) {
+	ticket, err := r.t.tickets.get(id)
+	if err != nil {
+		return nil, err
+	}
+	ticket.ITEMS = r.items(id)
+	return ticket, nil
+}
+
+func (r *ticketCocinaRepository) Insert(ticket *models.TicketCocina) error {
+	ticket.PK_ID_TICKET = r.t.tickets.nextID(ticket.PK_ID_TICKET)
+	for i := range ticket.ITEMS {
+		item := &ticket.ITEMS[i]
+		item.PK_ID_TICKET = ticket.PK_ID_TICKET
+		item.PK_ID_ITEM_TICKET = r.t.itemsTicket.nextID(item.PK_ID_ITEM_TICKET)
+		r.t.itemsTicket.rows[item.PK_ID_ITEM_TICKET] = *item
+	}
+	row := *ticket
+	row.ITEMS = nil
+	r.t.tickets.rows[row.PK_ID_TICKET] = row
+	return nil
+}
+
+func (r *ticketCocinaRepository) Update(ticket *models.TicketCocina) error {
+	actual, err := r.t.tickets.get(ticket.PK_ID_TICKET)
+	if err != nil {
+		return err
+	}
+	actual.ESTADO = ticket.ESTADO
+	actual.LISTO_EN = ticket.LISTO_EN
+	r.t.tickets.rows[actual.PK_ID_TICKET] = *actual
+	for _, item := range ticket.ITEMS {
+		if row, ok := r.t.itemsTicket.rows[item.PK_ID_ITEM_TICKET]; ok {
+			row.ESTADO = item.ESTADO
+			r.t.itemsTicket.rows[item.PK_ID_ITEM_TICKET] = row
+		}
+	}
+	return nil
+}
+
+func (r *ticketCocinaRepository) items(ticketID int64) []models.ItemTicket {
+	return r.t.itemsTicket.list(func(i models.ItemTicket) bool { return i.PK_ID_TICKET == ticketID })
+}
+
+type tiempoPedidoRepository struct{ t *tables }
+
+func (r *tiempoPedidoRepository) Get(pedidoID int) (*models.TiempoPedido, error) {
+	return r.t.tiemposPedido.get(int64(pedidoID))
+}
+
+func (r *tiempoPedidoRepository) List(desde, hasta time.Time) ([]models.TiempoPedido, error) {
+	tiempos := r.t.tiemposPedido.list(func(t models.TiempoPedido) bool {
+		return !t.INICIO.Before(desde) && t.INICIO.Before(hasta)
+	})
+	sort.SliceStable(tiempos, func(i, j int) bool { return tiempos[i].INICIO.Before(tiempos[j].INICIO) })
+	return tiempos, nil
+}
+
+func (r *tiempoPedidoRepository) Insert(tiempo *models.TiempoPedido) error {
+	r.t.tiemposPedido.rows[int64(tiempo.PK_ID_PEDIDO)] = *tiempo
+	return nil
+}
+
+func (r *tiempoPedidoRepository) Update(tiempo *models.TiempoPedido) error {
+	if _, ok := r.t.tiemposPedido.rows[int64(tiempo.PK_ID_PEDIDO)]; !ok {
+		return repositories.ErrNotFound
+	}
+	r.t.tiemposPedido.rows[int64(tiempo.PK_ID_PEDIDO)] = *tiempo
+	return nil
+}
+
+type impresoraRepository struct{ t *tables }
+
+func (r *impresoraRepository) List() ([]models.Impresora, error) {
+	return r.t.impresoras.list(nil), nil
+}
+
+func (r *impresoraRepository) Get(id int64) (*models.Impresora, error) {
+	return r.t.impresoras.get(id)
+}
+
+func (r *impresoraRepository) Insert(impresora *models.Impresora) error {
+	impresora.PK_ID_IMPRESORA = r.t.impresoras.nextID(impresora.PK_ID_IMPRESORA)
+	r.t.impresoras.rows[impresora.PK_ID_IMPRESORA] = *impresora
+	return nil
+}
+
+func (r *impresoraRepository) Update(impresora *models.Impresora) error {
+	if _, err := r.t.impresoras.get(impresora.PK_ID_IMPRESORA); err != nil {
+		return err
+	}
+	r.t.impresoras.rows[impresora.PK_ID_IMPRESORA] = *impresora
+	return nil
+}
+
+func (r *impresoraRepository) Delete(id int64) error {
+	if err := r.t.impresoras.delete(id); err != nil {
+		return err
+	}
+	for _, trabajo := range r.t.trabajos.list(func(t models.TrabajoImpresion) bool { return t.PK_ID_IMPRESORA == id }) {
+		delete(r.t.trabajos.rows, trabajo.PK_ID_TRABAJO)
+	}
+	return nil
+}
+
+type trabajoImpresionRepository struct{ t *tables }
+
+func (r *trabajoImpresionRepository) List(estado string) ([]models.TrabajoImpresion, error) {
+	trabajos := r.t.trabajos.list(func(t models.TrabajoImpresion) bool { return estado == "" || t.ESTADO == estado })
+	sort.SliceStable(trabajos, func(i, j int) bool { return trabajos[i].CREADO.Before(trabajos[j].CREADO) })
+	return trabajos, nil
+}
+
+func (r *trabajoImpresionRepository) Get(id int64) (*models.TrabajoImpresion, error) {
+	return r.t.trabajos.get(id)
+}
+
+func (r *trabajoImpresionRepository) Insert(trabajo *models.TrabajoImpresion) error {
+	trabajo.PK_ID_TRABAJO = r.t.trabajos.nextID(trabajo.PK_ID_TRABAJO)
+	r.t.trabajos.rows[trabajo.PK_ID_TRABAJO] = *trabajo
+	return nil
+}
+
+func (r *trabajoImpresionRepository) Update(trabajo *models.TrabajoImpresion) error {
+	if _, err := r.t.trabajos.get(trabajo.PK_ID_TRABAJO); err != nil {
+		return err
+	}
+	r.t.trabajos.rows[trabajo.PK_ID_TRABAJO] = *trabajo
+	return nil
+}
+
+type resolucionFacturacionRepository struct{ t *tables }
+
+func (r *resolucionFacturacionRepository) List() ([]models.ResolucionFacturacion, error) {
+	return r.t.resoluciones.list(nil), nil
+}
+
+func (r *resolucionFacturacionRepository) Get(id int64) (*models.ResolucionFacturacion, error) {
+	return r.t.resoluciones.get(id)
+}
+
+func (r *resolucionFacturacionRepository) Insert(resolucion *models.ResolucionFacturacion) error {
+	resolucion.PK_ID_RESOLUCION = r.t.resoluciones.nextID(resolucion.PK_ID_RESOLUCION)
+	r.t.resoluciones.rows[resolucion.PK_ID_RESOLUCION] = *resolucion
+	return nil
+}
+
+func (r *resolucionFacturacionRepository) Update(resolucion *models.ResolucionFacturacion) error {
+	actual, err := r.t.resoluciones.get(resolucion.PK_ID_RESOLUCION)
+	if err != nil {
+		return err
+	}
+	guardada := *resolucion
+	guardada.SIGUIENTE = actual.SIGUIENTE
+	r.t.resoluciones.rows[resolucion.PK_ID_RESOLUCION] = guardada
+	return nil
+}
+
+func (r *resolucionFacturacionRepository) Consumir(id int64) (int64, error) {
+	resolucion, err := r.t.resoluciones.get(id)
+	if err != nil {
+		return 0, err
+	}
+	if resolucion.SIGUIENTE > resolucion.HASTA {
+		return 0, repositories.ErrRangoAgotado
+	}
+	numero := resolucion.SIGUIENTE
+	resolucion.SIGUIENTE++
+	r.t.resoluciones.rows[id] = *resolucion
+	return numero, nil
+}
+
+type documentoElectronicoRepository struct{ t *tables }
+
+func (r *documentoElectronicoRepository) List(pedidoID int) ([]models.DocumentoElectronico, error) {
+	documentos := r.t.documentos.list(func(d models.DocumentoElectronico) bool {
+		return pedidoID == 0 || d.PK_ID_PEDIDO == pedidoID
+	})
+	sort.SliceStable(documentos, func(i, j int) bool { return documentos[i].FECHA_EMISION.Before(documentos[j].FECHA_EMISION) })
+	return documentos, nil
+}
+
+func (r *documentoElectronicoRepository) Get(id int64) (*models.DocumentoElectronico, error) {
+	return r.t.documentos.get(id)
+}
+
+func (r *documentoElectronicoRepository) GetByPedido(pedidoID int) (*models.DocumentoElectronico, error) {
+	documentos := r.t.documentos.list(func(d models.DocumentoElectronico) bool { return d.PK_ID_PEDIDO == pedidoID })
+	if len(documentos) == 0 {
+		return nil, repositories.ErrNotFound
+	}
+	return &documentos[0], nil
+}
+
+func (r *documentoElectronicoRepository) Insert(documento *models.DocumentoElectronico) error {
+	documento.PK_ID_DOCUMENTO = r.t.documentos.nextID(documento.PK_ID_DOCUMENTO)
+	r.t.documentos.rows[documento.PK_ID_DOCUMENTO] = *documento
+	return nil
+}
+
+func (r *documentoElectronicoRepository) Update(documento *models.DocumentoElectronico) error {
+	actual, err := r.t.documentos.get(documento.PK_ID_DOCUMENTO)
+	if err != nil {
+		return err
+	}
+	actual.ESTADO, actual.ID_ENVIO, actual.RESPUESTA = documento.ESTADO, documento.ID_ENVIO, documento.RESPUESTA
+	r.t.documentos.rows[documento.PK_ID_DOCUMENTO] = *actual
+	return nil
+}
+
+type restauranteRepository struct{ t *tables }
+
+func (r *restauranteRepository) List() ([]models.Restaurante, error) {
+	return r.t.restaurantes.list(nil), nil
+}
+
+func (r *restauranteRepository) Get(id int) (*models.Restaurante, error) {
+	return r.t.restaurantes.get(int64(id))
+}
+
+func (r *restauranteRepository) Insert(restaurante *models.Restaurante) error {
+	restaurante.PK_ID_RESTAURANTE = int(r.t.restaurantes.nextID(int64(restaurante.PK_ID_RESTAURANTE)))
+	r.t.restaurantes.rows[int64(restaurante.PK_ID_RESTAURANTE)] = *restaurante
+	return nil
+}
+
+type cambioHorarioRepository struct{ t *tables }
+
+func (r *cambioHorarioRepository) GetByFecha(fecha time.Time) (*models.CambiosHorario, error) {
+	dia := fecha.Format("2006-01-02")
+	cambios := r.t.cambiosHorario.list(func(c models.CambiosHorario) bool {
+		return c.FECHA.Format("2006-01-02") == dia
+	})
+	if len(cambios) == 0 {
+		return nil, repositories.ErrNotFound
+	}
+	return &cambios[len(cambios)-1], nil
+}
+
+func (r *cambioHorarioRepository) Insert(cambio *models.CambiosHorario) error {
+	cambio.PK_ID_CAMBIO_HORARIO = r.t.cambiosHorario.nextID(cambio.PK_ID_CAMBIO_HORARIO)
+	r.t.cambiosHorario.rows[cambio.PK_ID_CAMBIO_HORARIO] = *cambio
+	return nil
+}
+
+type pedidoFavoritoRepository struct{ t *tables }
+
+func (r *pedidoFavoritoRepository) ListByCliente(documento int64) ([]models.PedidoFavorito, error) {
+	favoritos := r.t.favoritos.list(func(f models.PedidoFavorito) bool {
+		return f.PK_DOCUMENTO_CLIENTE == documento
+	})
+	slices.Reverse(favoritos)
+	return favoritos, nil
+}
+
+func (r *pedidoFavoritoRepository) Get(id int64) (*models.PedidoFavorito, error) {
+	return r.t.favoritos.get(id)
+}
+
+func (r *pedidoFavoritoRepository) Insert(favorito *models.PedidoFavorito) error {
+	favorito.PK_ID_FAVORITO = r.t.favoritos.nextID(favorito.PK_ID_FAVORITO)
+	r.t.favoritos.rows[favorito.PK_ID_FAVORITO] = *favorito
+	return nil
+}
+
+func (r *pedidoFavoritoRepository) Delete(id int64) error {
+	return r.t.favoritos.delete(id)
+}
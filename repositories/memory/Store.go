@@ -0,0 +1,312 @@
+// Package memory implementa repositories.Store en memoria para probar los servicios sin Postgres.
+package memory
+
+import (
+	"maps"
+	"restaurante/models"
+	"restaurante/repositories"
+	"sort"
+	"sync"
+)
+
+// table guarda copias de los registros de una entidad indexadas por su llave primaria
+type table[T any] struct {
+	rows map[int64]T
+	next int64
+}
+
+func newTable[T any]() *table[T] {
+	return &table[T]{rows: map[int64]T{}}
+}
+
+func (t *table[T]) get(id int64) (*T, error) {
+	row, ok := t.rows[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &row, nil
+}
+
+// nextID devuelve el identificador con el que se inserta un registro: el siguiente de la
+// secuencia cuando id es 0 (llaves autoincrementales) o el mismo id en otro caso
+func (t *table[T]) nextID(id int64) int64 {
+	if id == 0 {
+		t.next++
+		return t.next
+	}
+	if id > t.next {
+		t.next = id
+	}
+	return id
+}
+
+func (t *table[T]) delete(id int64) error {
+	if _, ok := t.rows[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(t.rows, id)
+	return nil
+}
+
+// list devuelve los registros ordenados por llave primaria que cumplen match
+func (t *table[T]) list(match func(T) bool) []T {
+	ids := make([]int64, 0, len(t.rows))
+	for id := range t.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows := []T{}
+	for _, id := range ids {
+		if match == nil || match(t.rows[id]) {
+			rows = append(rows, t.rows[id])
+		}
+	}
+	return rows
+}
+
+func (t *table[T]) clone() *table[T] {
+	c := &table[T]{rows: make(map[int64]T, len(t.rows)), next: t.next}
+	for id, row := range t.rows {
+		c.rows[id] = row
+	}
+	return c
+}
+
+type tables struct {
+	pedidos           *table[models.Pedido]
+	pagos             *table[models.Pago]
+	metodosPago       *table[models.MetodoPago]
+	domicilios        *table[models.Domicilio]
+	clientes          *table[models.Cliente]
+	trabajadores      *table[models.Trabajador]
+	productos         *table[models.Producto]
+	reservas          *table[models.Reserva]
+	incidencias       *table[models.Incidencia]
+	nominas           *table[models.Nomina]
+	nominasTrabajador *table[models.NominaTrabajador]
+	detallesPedido    *table[models.DetallePedido]
+	pedidosClientes   *table[models.PedidoCliente]
+	historialEstados  *table[models.PedidoEstadoHistorial]
+	movimientosStock  *table[models.MovimientoStock]
+	grupos            *table[models.GrupoModificador]
+	opciones          *table[models.OpcionModificador]
+	detalleOpciones   *table[models.DetallePedidoModificador]
+	reglasImpuesto    map[string]models.ReglaImpuesto
+	promociones       *table[models.Promocion]
+	pedidoPromociones *table[models.PedidoPromocion]
+	cancelaciones     *table[models.CancelacionPedido]
+	reembolsos        *table[models.Reembolso]
+	mesas             *table[models.Mesa]
+	tickets           *table[models.TicketCocina]
+	itemsTicket       *table[models.ItemTicket]
+	tiemposPedido     *table[models.TiempoPedido]
+	impresoras        *table[models.Impresora]
+	trabajos          *table[models.TrabajoImpresion]
+	resoluciones      *table[models.ResolucionFacturacion]
+	documentos        *table[models.DocumentoElectronico]
+	restaurantes      *table[models.Restaurante]
+	cambiosHorario    *table[models.CambiosHorario]
+	favoritos         *table[models.PedidoFavorito]
+}
+
+func (t *tables) clone() *tables {
+	return &tables{
+		pedidos:           t.pedidos.clone(),
+		pagos:             t.pagos.clone(),
+		metodosPago:       t.metodosPago.clone(),
+		domicilios:        t.domicilios.clone(),
+		clientes:          t.clientes.clone(),
+		trabajadores:      t.trabajadores.clone(),
+		productos:         t.productos.clone(),
+		reservas:          t.reservas.clone(),
+		incidencias:       t.incidencias.clone(),
+		nominas:           t.nominas.clone(),
+		nominasTrabajador: t.nominasTrabajador.clone(),
+		detallesPedido:    t.detallesPedido.clone(),
+		pedidosClientes:   t.pedidosClientes.clone(),
+		historialEstados:  t.historialEstados.clone(),
+		movimientosStock:  t.movimientosStock.clone(),
+		grupos:            t.grupos.clone(),
+		opciones:          t.opciones.clone(),
+		detalleOpciones:   t.detalleOpciones.clone(),
+		reglasImpuesto:    maps.Clone(t.reglasImpuesto),
+		promociones:       t.promociones.clone(),
+		pedidoPromociones: t.pedidoPromociones.clone(),
+		cancelaciones:     t.cancelaciones.clone(),
+		reembolsos:        t.reembolsos.clone(),
+		mesas:             t.mesas.clone(),
+		tickets:           t.tickets.clone(),
+		itemsTicket:       t.itemsTicket.clone(),
+		tiemposPedido:     t.tiemposPedido.clone(),
+		impresoras:        t.impresoras.clone(),
+		trabajos:          t.trabajos.clone(),
+		resoluciones:      t.resoluciones.clone(),
+		documentos:        t.documentos.clone(),
+		restaurantes:      t.restaurantes.clone(),
+		cambiosHorario:    t.cambiosHorario.clone(),
+		favoritos:         t.favoritos.clone(),
+	}
+}
+
+// Store es un repositories.Store en memoria. Las transacciones se serializan y, si fallan,
+// restauran una copia de las tablas tomada al iniciar.
+type Store struct {
+	mu         *sync.Mutex
+	data       *tables
+	inTx       bool
+	pendientes *[]func()
+}
+
+// NewStore crea un Store vacío
+func NewStore() *Store {
+	return &Store{
+		mu: &sync.Mutex{},
+		data: &tables{
+			pedidos:           newTable[models.Pedido](),
+			pagos:             newTable[models.Pago](),
+			metodosPago:       newTable[models.MetodoPago](),
+			domicilios:        newTable[models.Domicilio](),
+			clientes:          newTable[models.Cliente](),
+			trabajadores:      newTable[models.Trabajador](),
+			productos:         newTable[models.Producto](),
+			reservas:          newTable[models.Reserva](),
+			incidencias:       newTable[models.Incidencia](),
+			nominas:           newTable[models.Nomina](),
+			nominasTrabajador: newTable[models.NominaTrabajador](),
+			detallesPedido:    newTable[models.DetallePedido](),
+			pedidosClientes:   newTable[models.PedidoCliente](),
+			historialEstados:  newTable[models.PedidoEstadoHistorial](),
+			movimientosStock:  newTable[models.MovimientoStock](),
+			grupos:            newTable[models.GrupoModificador](),
+			opciones:          newTable[models.OpcionModificador](),
+			detalleOpciones:   newTable[models.DetallePedidoModificador](),
+			// Las mismas reglas que siembra la migración 0008
+			reglasImpuesto: map[string]models.ReglaImpuesto{
+				"IMPOCONSUMO": {CATEGORIA: "IMPOCONSUMO", NOMBRE: "Impuesto nacional al consumo", TARIFA: 8},
+				"IVA":         {CATEGORIA: "IVA", NOMBRE: "Impuesto al valor agregado", TARIFA: 19},
+				"EXENTO":      {CATEGORIA: "EXENTO", NOMBRE: "Exento", TARIFA: 0},
+			},
+			promociones:       newTable[models.Promocion](),
+			pedidoPromociones: newTable[models.PedidoPromocion](),
+			cancelaciones:     newTable[models.CancelacionPedido](),
+			reembolsos:        newTable[models.Reembolso](),
+			mesas:             newTable[models.Mesa](),
+			tickets:           newTable[models.TicketCocina](),
+			itemsTicket:       newTable[models.ItemTicket](),
+			tiemposPedido:     newTable[models.TiempoPedido](),
+			impresoras:        newTable[models.Impresora](),
+			trabajos:          newTable[models.TrabajoImpresion](),
+			resoluciones:      newTable[models.ResolucionFacturacion](),
+			documentos:        newTable[models.DocumentoElectronico](),
+			restaurantes:      newTable[models.Restaurante](),
+			cambiosHorario:    newTable[models.CambiosHorario](),
+			favoritos:         newTable[models.PedidoFavorito](),
+		},
+	}
+}
+
+func (s *Store) Transaction(fn func(tx repositories.Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	pendientes := []func(){}
+	if err := s.transaction(fn, &pendientes); err != nil {
+		return err
+	}
+	for _, pendiente := range pendientes {
+		pendiente()
+	}
+	return nil
+}
+
+// transaction ejecuta fn con el candado tomado; las funciones pendientes corren después de soltarlo
+func (s *Store) transaction(fn func(tx repositories.Store) error, pendientes *[]func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.data.clone()
+	if err := fn(&Store{mu: s.mu, data: s.data, inTx: true, pendientes: pendientes}); err != nil {
+		*s.data = *snapshot
+		return err
+	}
+	return nil
+}
+
+func (s *Store) AfterCommit(fn func()) {
+	if !s.inTx {
+		fn()
+		return
+	}
+	*s.pendientes = append(*s.pendientes, fn)
+}
+
+func (s *Store) Pedidos() repositories.PedidoRepository         { return &pedidoRepository{s.data} }
+func (s *Store) Pagos() repositories.PagoRepository             { return &pagoRepository{s.data} }
+func (s *Store) MetodosPago() repositories.MetodoPagoRepository { return &metodoPagoRepository{s.data} }
+func (s *Store) Domicilios() repositories.DomicilioRepository   { return &domicilioRepository{s.data} }
+func (s *Store) Clientes() repositories.ClienteRepository       { return &clienteRepository{s.data} }
+func (s *Store) Trabajadores() repositories.TrabajadorRepository {
+	return &trabajadorRepository{s.data}
+}
+func (s *Store) Productos() repositories.ProductoRepository     { return &productoRepository{s.data} }
+func (s *Store) Reservas() repositories.ReservaRepository       { return &reservaRepository{s.data} }
+func (s *Store) Incidencias() repositories.IncidenciaRepository { return &incidenciaRepository{s.data} }
+func (s *Store) Nominas() repositories.NominaRepository         { return &nominaRepository{s.data} }
+func (s *Store) NominasTrabajador() repositories.NominaTrabajadorRepository {
+	return &nominaTrabajadorRepository{s.data}
+}
+func (s *Store) DetallesPedido() repositories.DetallePedidoRepository {
+	return &detallePedidoRepository{s.data}
+}
+func (s *Store) PedidosClientes() repositories.PedidoClienteRepository {
+	return &pedidoClienteRepository{s.data}
+}
+func (s *Store) HistorialEstados() repositories.PedidoEstadoHistorialRepository {
+	return &pedidoEstadoHistorialRepository{s.data}
+}
+func (s *Store) MovimientosStock() repositories.MovimientoStockRepository {
+	return &movimientoStockRepository{s.data}
+}
+func (s *Store) Modificadores() repositories.ModificadorRepository {
+	return &modificadorRepository{s.data}
+}
+func (s *Store) ReglasImpuesto() repositories.ReglaImpuestoRepository {
+	return &reglaImpuestoRepository{s.data}
+}
+func (s *Store) Promociones() repositories.PromocionRepository {
+	return &promocionRepository{s.data}
+}
+func (s *Store) Cancelaciones() repositories.CancelacionRepository {
+	return &cancelacionRepository{s.data}
+}
+func (s *Store) Reembolsos() repositories.ReembolsoRepository {
+	return &reembolsoRepository{s.data}
+}
+func (s *Store) Mesas() repositories.MesaRepository { return &mesaRepository{s.data} }
+func (s *Store) TicketsCocina() repositories.TicketCocinaRepository {
+	return &ticketCocinaRepository{s.data}
+}
+func (s *Store) TiemposPedido() repositories.TiempoPedidoRepository {
+	return &tiempoPedidoRepository{s.data}
+}
+func (s *Store) Impresoras() repositories.ImpresoraRepository { return &impresoraRepository{s.data} }
+func (s *Store) TrabajosImpresion() repositories.TrabajoImpresionRepository {
+	return &trabajoImpresionRepository{s.data}
+}
+func (s *Store) ResolucionesFacturacion() repositories.ResolucionFacturacionRepository {
+	return &resolucionFacturacionRepository{s.data}
+}
+func (s *Store) DocumentosElectronicos() repositories.DocumentoElectronicoRepository {
+	return &documentoElectronicoRepository{s.data}
+}
+func (s *Store) Restaurantes() repositories.RestauranteRepository {
+	return &restauranteRepository{s.data}
+}
+func (s *Store) CambiosHorario() repositories.CambioHorarioRepository {
+	return &cambioHorarioRepository{s.data}
+}
+func (s *Store) Favoritos() repositories.PedidoFavoritoRepository {
+	return &pedidoFavoritoRepository{s.data}
+}
@@ -7,6 +7,9 @@ import (
 )
 
 func init() {
+	// Estado del servicio
+	beego.Router("/", &controllers.HealthController{}, "get:Get")
+
 	ns := beego.NewNamespace("/restaurante/v1",
 		// Ruta para login
 		beego.NSRouter("/login", &controllers.LoginController{}, "post:Login"),
@@ -25,7 +28,7 @@ func init() {
 		// Rutas para pedidos
 		beego.NSNamespace("/pedidos",
 			beego.NSBefore(controllers.ValidateToken),
-			beego.NSRouter("/", &controllers.PedidoController{}, "get:GetAll;post:Post;put:Put;delete:Delete"),
+			beego.NSRouter("/", &controllers.PedidoController{}, "get:GetAll;post:CreatePedido;put:Put;delete:Delete"),
 			beego.NSRouter("/asignar-domicilio", &controllers.PedidoController{}, "post:AssignDomicilio"),
 			beego.NSRouter("/asignar-pago", &controllers.PedidoController{}, "post:AssignPago"),
 			beego.NSRouter("/actualizar-estado", &controllers.PedidoController{}, "put:UpdateEstadoPedido"),
@@ -97,9 +100,146 @@ func init() {
 		// Rutas para productos_pedido
 		beego.NSNamespace("/producto_pedido",
 			beego.NSBefore(controllers.ValidateToken),
-			beego.NSRouter("/", &controllers.ProductoPedidoController{}, "get:GetAll;post:Post;put:Put;delete:Delete"),
+			beego.NSRouter("/", &controllers.ProductoPedidoController{}, "get:GetAll;post:Create;put:Update"),
+		),
+
+		// Rutas para el inventario de productos
+		beego.NSNamespace("/movimientos_stock",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/", &controllers.MovimientoStockController{}, "get:GetAll;post:Post"),
 		),
 	)
 
-	beego.AddNamespace(ns)
+	// API v2: recursos con parámetros de ruta que comparten los servicios de la v1
+	nsV2 := beego.NewNamespace("/restaurante/v2",
+		beego.NSRouter("/login", &controllers.LoginController{}, "post:Login"),
+
+		// Rutas para pedidos
+		beego.NSNamespace("/pedidos",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/", &controllers.PedidoV2Controller{}, "get:GetAll;post:Post"),
+			beego.NSRouter("/checkout", &controllers.CheckoutController{}, "post:Post"),
+			beego.NSRouter("/programados", &controllers.PedidoV2Controller{}, "get:GetProgramados"),
+			beego.NSRouter("/:id:int", &controllers.PedidoV2Controller{}, "get:Get;put:Put"),
+			beego.NSRouter("/:id:int/pago", &controllers.PedidoV2Controller{}, "put:PutPago"),
+			beego.NSRouter("/:id:int/cuenta", &controllers.PedidoV2Controller{}, "get:GetCuenta"),
+			beego.NSRouter("/:id:int/division", &controllers.PedidoV2Controller{}, "post:PostDivision"),
+			beego.NSRouter("/:id:int/domicilio", &controllers.PedidoV2Controller{}, "put:PutDomicilio"),
+			beego.NSRouter("/:id:int/estado", &controllers.PedidoV2Controller{}, "put:PutEstado"),
+			beego.NSRouter("/:id:int/propina", &controllers.PedidoV2Controller{}, "put:PutPropina"),
+			beego.NSRouter("/:id:int/recibo", &controllers.PedidoV2Controller{}, "get:GetRecibo"),
+			beego.NSRouter("/:id:int/promocion", &controllers.PedidoV2Controller{}, "put:PutPromocion"),
+			beego.NSRouter("/:id:int/cancelacion", &controllers.PedidoV2Controller{}, "post:PostCancelacion"),
+			beego.NSRouter("/:id:int/mesa", &controllers.PedidoV2Controller{}, "put:PutMesa"),
+			beego.NSRouter("/:id:int/programacion", &controllers.PedidoV2Controller{}, "put:PutProgramacion"),
+			beego.NSRouter("/:id:int/repetir", &controllers.PedidoV2Controller{}, "post:PostRepetir"),
+		),
+		// Rutas para pagos
+		beego.NSNamespace("/pagos",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/:id:int", &controllers.PagoV2Controller{}, "get:Get;delete:Delete"),
+		),
+		// Rutas para domicilios
+		beego.NSNamespace("/domicilios",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/:id:int", &controllers.DomicilioV2Controller{}, "get:Get;delete:Delete"),
+			beego.NSRouter("/:id:int/domiciliario", &controllers.DomicilioV2Controller{}, "put:PutDomiciliario"),
+		),
+		// Rutas para el historial y los pedidos favoritos de los clientes, y para los pedidos telefónicos
+		beego.NSNamespace("/clientes",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/telefono", &controllers.ClienteV2Controller{}, "get:GetPorTelefono"),
+			beego.NSRouter("/telefono/pedidos", &controllers.ClienteV2Controller{}, "post:PostPedidoTelefonico"),
+			beego.NSRouter("/:documento:int/pedidos", &controllers.ClienteV2Controller{}, "get:GetPedidos"),
+			beego.NSRouter("/:documento:int/favoritos", &controllers.ClienteV2Controller{}, "get:GetFavoritos;post:PostFavorito"),
+			beego.NSRouter("/:documento:int/favoritos/:id:int", &controllers.ClienteV2Controller{}, "delete:DeleteFavorito"),
+		),
+		// Rutas para trabajadores
+		beego.NSNamespace("/trabajadores",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/:documento:int/nominas", &controllers.TrabajadorV2Controller{}, "get:GetNominas"),
+		),
+		// Rutas para el menú: los modificadores de cada producto son públicos como el catálogo
+		beego.NSNamespace("/productos",
+			beego.NSRouter("/:id:int/modificadores", &controllers.ModificadorController{}, "get:GetAll"),
+		),
+		// Rutas para administrar los modificadores
+		beego.NSNamespace("/modificadores",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/", &controllers.ModificadorController{}, "post:Post"),
+			beego.NSRouter("/:id:int", &controllers.ModificadorController{}, "delete:Delete"),
+		),
+		// Rutas para las tarifas de impuesto por categoría de producto
+		beego.NSNamespace("/impuestos",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/", &controllers.ImpuestoController{}, "get:GetAll"),
+			beego.NSRouter("/:categoria", &controllers.ImpuestoController{}, "put:Put"),
+		),
+		// Rutas para promociones y códigos de descuento
+		beego.NSNamespace("/promociones",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/", &controllers.PromocionController{}, "get:GetAll;post:Post"),
+			beego.NSRouter("/:id:int", &controllers.PromocionController{}, "put:Put"),
+		),
+		// Rutas para las mesas del salón y sus rondas
+		beego.NSNamespace("/mesas",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/", &controllers.MesaController{}, "get:GetAll;post:Post"),
+			beego.NSRouter("/plano", &controllers.MesaController{}, "get:GetPlano"),
+			beego.NSRouter("/:id:int", &controllers.MesaController{}, "put:Put"),
+			beego.NSRouter("/:id:int/estado", &controllers.MesaController{}, "put:PutEstado"),
+			beego.NSRouter("/:id:int/pedidos", &controllers.MesaController{}, "post:PostPedido"),
+			beego.NSRouter("/:id:int/mover", &controllers.MesaController{}, "post:PostMover"),
+			beego.NSRouter("/:id:int/unir", &controllers.MesaController{}, "post:PostUnir"),
+		),
+		// Rutas para la pantalla de cocina y sus tickets por estación
+		beego.NSNamespace("/cocina",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/tickets", &controllers.CocinaController{}, "get:GetTickets"),
+			beego.NSRouter("/tickets/:id:int/bump", &controllers.CocinaController{}, "post:PostBump"),
+			beego.NSRouter("/tickets/:id:int/recall", &controllers.CocinaController{}, "post:PostRecall"),
+			beego.NSRouter("/tickets/:id:int/items/:item:int", &controllers.CocinaController{}, "put:PutItem"),
+			beego.NSRouter("/tickets/:id:int/comanda", &controllers.CocinaController{}, "get:GetComanda"),
+		),
+		// Rutas para las impresoras de red y la cola de recibos y comandas
+		beego.NSNamespace("/impresoras",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/", &controllers.ImpresionController{}, "get:GetImpresoras;post:PostImpresora"),
+			beego.NSRouter("/:id:int", &controllers.ImpresionController{}, "put:PutImpresora;delete:DeleteImpresora"),
+		),
+		beego.NSNamespace("/impresion",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/trabajos", &controllers.ImpresionController{}, "get:GetTrabajos;post:PostTrabajo"),
+			beego.NSRouter("/trabajos/:id:int/reintentar", &controllers.ImpresionController{}, "post:PostReintentar"),
+		),
+		// Rutas para la factura electrónica y el documento equivalente POS
+		beego.NSNamespace("/facturacion",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/resoluciones", &controllers.FacturacionController{}, "get:GetResoluciones;post:PostResolucion"),
+			beego.NSRouter("/resoluciones/:id:int", &controllers.FacturacionController{}, "put:PutResolucion"),
+			beego.NSRouter("/documentos", &controllers.FacturacionController{}, "get:GetDocumentos;post:PostDocumento"),
+			beego.NSRouter("/documentos/:id:int", &controllers.FacturacionController{}, "get:GetDocumento"),
+			beego.NSRouter("/documentos/:id:int/xml", &controllers.FacturacionController{}, "get:GetXML"),
+			beego.NSRouter("/documentos/:id:int/enviar", &controllers.FacturacionController{}, "post:PostEnviar"),
+		),
+		// Rutas para recibir en tiempo real los cambios de pedidos, domicilios, pagos y reservas
+		beego.NSNamespace("/eventos",
+			beego.NSBefore(controllers.ValidateStreamToken),
+			beego.NSRouter("/stream", &controllers.EventoController{}, "get:GetStream"),
+			beego.NSRouter("/ws", &controllers.EventoController{}, "get:GetWebSocket"),
+		),
+		// Rutas para reportes
+		beego.NSNamespace("/reportes",
+			beego.NSBefore(controllers.ValidateToken),
+			beego.NSRouter("/ventas", &controllers.ReporteController{}, "get:GetVentas"),
+			beego.NSRouter("/cancelaciones", &controllers.ReporteController{}, "get:GetCancelaciones"),
+			beego.NSRouter("/tiempos", &controllers.ReporteController{}, "get:GetTiempos"),
+		),
+	)
+
+	beego.AddNamespace(ns, nsV2)
+
+	// Reintentos seguros para los POST que envían la cabecera Idempotency-Key
+	beego.InsertFilter("/restaurante/*", beego.BeforeExec, controllers.CheckIdempotencyKey)
+	beego.InsertFilter("/restaurante/*", beego.FinishRouter, controllers.StoreIdempotentResponse, beego.WithReturnOnOutput(false))
 }
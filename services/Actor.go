@@ -0,0 +1,60 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Actor identifica al usuario autenticado que realiza una operación. Es el valor cero
+// cuando la operación llega sin token (rutas públicas o procesos internos).
+type Actor struct {
+	Documento int
+	Rol       string
+}
+
+// documento devuelve el documento del actor o nil si no hay usuario autenticado
+func (a Actor) documento() *int64 {
+	if a.Documento == 0 {
+		return nil
+	}
+	documento := int64(a.Documento)
+	return &documento
+}
+
+// rol devuelve el rol del actor o nil si no hay usuario autenticado
+func (a Actor) rol() *string {
+	if a.Rol == "" {
+		return nil
+	}
+	rol := a.Rol
+	return &rol
+}
+
+// RolCliente es el rol que el login asigna a los clientes
+const RolCliente = "cliente"
+
+// RolAdministrador es el rol de los trabajadores que administran el restaurante
+const RolAdministrador = "Administrador"
+
+// esAdministrador indica si el actor es un administrador; el rol de los trabajadores es texto
+// libre, por eso no distingue mayúsculas
+func (a Actor) esAdministrador() bool {
+	return strings.EqualFold(a.Rol, RolAdministrador)
+}
+
+// RolDomiciliario es el rol de los trabajadores que llevan los domicilios
+const RolDomiciliario = "Domiciliario"
+
+// esDomiciliario indica si el actor es un domiciliario; como en esAdministrador no distingue mayúsculas
+func (a Actor) esDomiciliario() bool {
+	return strings.EqualFold(a.Rol, RolDomiciliario)
+}
+
+// requierePersonal impide que un cliente haga una acción reservada al personal del restaurante;
+// accion completa el mensaje "Solo el personal del restaurante puede ..."
+func requierePersonal(actor Actor, accion string) error {
+	if actor.Rol == RolCliente {
+		return newError(http.StatusForbidden, "Solo el personal del restaurante puede "+accion, nil)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// Motivos por los que se cancela un pedido
+const (
+	CancelacionClienteDesiste = "CLIENTE_DESISTE"
+	CancelacionDemora         = "DEMORA"
+	CancelacionSinProducto    = "SIN_PRODUCTO"
+	CancelacionErrorPedido    = "ERROR_PEDIDO"
+	CancelacionPagoRechazado  = "PAGO_RECHAZADO"
+	CancelacionOtro           = "OTRO"
+)
+
+// MotivosCancelacion son los motivos válidos en el orden en que se reportan
+var MotivosCancelacion = []string{
+	CancelacionClienteDesiste, CancelacionDemora, CancelacionSinProducto, CancelacionErrorPedido, CancelacionPagoRechazado, CancelacionOtro,
+}
+
+// Estados del pago y del reembolso de un pedido cancelado
+const (
+	PagoReembolsado    = "REEMBOLSADO"
+	ReembolsoPendiente = "PENDIENTE"
+)
+
+// CancelacionService cancela pedidos: devuelve sus productos al inventario, reembolsa los pagos
+// que ya se habían cobrado y deja registro del motivo y de quién lo canceló
+type CancelacionService struct {
+	store repositories.Store
+}
+
+func NewCancelacionService(store repositories.Store) *CancelacionService {
+	return &CancelacionService{store: store}
+}
+
+// Cancelar cancela el pedido por el motivo indicado. El administrador puede cancelar un pedido en
+// cualquier estado, incluso entregado; el resto del personal mientras el ciclo de vida lo permita
+// y los clientes solo sus propios pedidos, antes de que entren a cocina y dentro del plazo de la
+// clave cancelacion_cliente_minutos. Un motivo inválido responde 400, un pedido ajeno 403 y un
+// pedido que ya no se puede cancelar 409.
+func (s *CancelacionService) Cancelar(pedidoID int, motivo, detalle string, actor Actor) (*models.CancelacionPedido, error) {
+	motivo = strings.ToUpper(strings.TrimSpace(motivo))
+	if motivo == "" {
+		return nil, badRequest("El MOTIVO de la cancelación es obligatorio")
+	}
+	if !esMotivoCancelacion(motivo) {
+		return nil, newError(http.StatusBadRequest, "Motivo de cancelación inválido",
+			fmt.Errorf("'%s' no es un motivo válido; use uno de: %s", motivo, strings.Join(MotivosCancelacion, ", ")))
+	}
+
+	var cancelacion *models.CancelacionPedido
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		pedido, err := NewPedidoService(tx).GetByID(pedidoID)
+		if err != nil {
+			return err
+		}
+		cancelacion, err = cancelarPedido(tx, pedido, motivo, strings.TrimSpace(detalle), actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cancelacion, nil
+}
+
+// cancelarPedido pasa el pedido a CANCELADO dentro de tx si el actor puede hacerlo. Las unidades
+// vuelven al inventario si ya se habían descontado, salvo que el pedido ya se hubiera entregado.
+func cancelarPedido(tx repositories.Store, pedido *models.Pedido, motivo, detalle string, actor Actor) (*models.CancelacionPedido, error) {
+	if err := puedeCancelar(tx, pedido, actor); err != nil {
+		return nil, err
+	}
+
+	anterior := pedido.ESTADO_PEDIDO
+	pedido.ESTADO_PEDIDO = EstadoCancelado
+	if err := NewPedidoService(tx).save(pedido, pedido.VERSION, "ESTADO_PEDIDO"); err != nil {
+		return nil, err
+	}
+	if stockDescontado(anterior) && anterior != EstadoEntregado {
+		if err := reponerLineas(tx, pedido.PK_ID_PEDIDO, MotivoCancelacion, actor); err != nil {
+			return nil, err
+		}
+	}
+	if err := registrarEstado(tx, pedido.PK_ID_PEDIDO, anterior, EstadoCancelado, actor); err != nil {
+		return nil, err
+	}
+
+	cancelacion := models.CancelacionPedido{
+		PK_ID_PEDIDO:    pedido.PK_ID_PEDIDO,
+		MOTIVO:          motivo,
+		ESTADO_ANTERIOR: anterior,
+		TOTAL:           pedido.TOTAL,
+		DOCUMENTO_ACTOR: actor.documento(),
+		ROL_ACTOR:       actor.rol(),
+		FECHA:           time.Now().In(database.BogotaZone),
+		PEDIDO:          pedido,
+	}
+	if detalle != "" {
+		cancelacion.DETALLE = &detalle
+	}
+	reembolsos, err := reembolsar(tx, pedido)
+	if err != nil {
+		return nil, err
+	}
+	cancelacion.REEMBOLSOS = reembolsos
+	if err := tx.Cancelaciones().Insert(&cancelacion); err != nil {
+		return nil, internalError("Error al registrar la cancelación", err)
+	}
+	return &cancelacion, nil
+}
+
+// puedeCancelar aplica las reglas de quién puede cancelar el pedido en su estado actual
+func puedeCancelar(tx repositories.Store, pedido *models.Pedido, actor Actor) error {
+	switch {
+	case pedido.ESTADO_PEDIDO == EstadoCancelado:
+		return &Error{Code: http.StatusConflict, Message: "El pedido ya está cancelado", Data: pedido}
+	case actor.esAdministrador():
+		return nil
+	case actor.Rol == RolCliente:
+		return clientePuedeCancelar(tx, pedido, actor)
+	case !puedeTransicionar(pedido, EstadoCancelado):
+		return transicionInvalida(pedido, EstadoCancelado)
+	}
+	return nil
+}
+
+// clientePuedeCancelar permite al cliente cancelar su pedido mientras no haya entrado a cocina y
+// no haya pasado el plazo contado desde su creación
+func clientePuedeCancelar(tx repositories.Store, pedido *models.Pedido, actor Actor) error {
+	cliente, err := clientePedido(tx, pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return err
+	}
+	if cliente == nil || *cliente != int64(actor.Documento) {
+		return newError(http.StatusForbidden, "Solo puede cancelar sus propios pedidos", nil)
+	}
+	if pedido.ESTADO_PEDIDO != EstadoIniciado && pedido.ESTADO_PEDIDO != EstadoPagado {
+		return &Error{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("Un pedido %s ya no se puede cancelar; comuníquese con el restaurante", pedido.ESTADO_PEDIDO),
+			Data:    pedido,
+		}
+	}
+
+	historial, err := tx.HistorialEstados().ListByPedido(pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return internalError("Error al consultar el historial del pedido", err)
+	}
+	if len(historial) > 0 {
+		plazo := minutosCancelacionCliente()
+		if time.Since(historial[0].FECHA) > time.Duration(plazo)*time.Minute {
+			return &Error{
+				Code:    http.StatusConflict,
+				Message: fmt.Sprintf("El plazo de %d minutos para cancelar el pedido ya venció; comuníquese con el restaurante", plazo),
+				Data:    pedido,
+			}
+		}
+	}
+	return nil
+}
+
+// reembolsar registra la devolución de cada pago cobrado del pedido, por el mismo método con que
+// se pagó, y marca esos pagos como REEMBOLSADO. Los pagos pendientes no se tocan.
+func reembolsar(tx repositories.Store, pedido *models.Pedido) ([]models.Reembolso, error) {
+	pagos, err := tx.Pagos().ListByPedido(pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return nil, internalError("Error al consultar los pagos del pedido", err)
+	}
+
+	var reembolsos []models.Reembolso
+	for i := range pagos {
+		pago := &pagos[i]
+		if pago.ESTADO_PAGO != "PAGADO" {
+			continue
+		}
+		reembolso := models.Reembolso{
+			PK_ID_PAGO:        pago.PK_ID_PAGO,
+			PK_ID_PEDIDO:      pedido.PK_ID_PEDIDO,
+			PK_ID_METODO_PAGO: pago.PK_ID_METODO_PAGO,
+			MONTO:             pago.MONTO,
+			ESTADO:            ReembolsoPendiente,
+			FECHA:             time.Now().In(database.BogotaZone),
+		}
+		if err := tx.Reembolsos().Insert(&reembolso); err != nil {
+			return nil, internalError("Error al registrar el reembolso", err)
+		}
+		pago.ESTADO_PAGO = PagoReembolsado
+		if err := NewPagoService(tx).save(pago, pago.VERSION, "ESTADO_PAGO"); err != nil {
+			return nil, err
+		}
+		reembolsos = append(reembolsos, reembolso)
+	}
+	return reembolsos, nil
+}
+
+// esMotivoCancelacion indica si motivo es uno de los motivos de cancelación
+func esMotivoCancelacion(motivo string) bool {
+	for _, m := range MotivosCancelacion {
+		if m == motivo {
+			return true
+		}
+	}
+	return false
+}
+
+// minutosCancelacionCliente es el plazo que tiene un cliente para cancelar su pedido desde que lo
+// crea (clave cancelacion_cliente_minutos)
+func minutosCancelacionCliente() int {
+	return web.AppConfig.DefaultInt("cancelacion_cliente_minutos", 10)
+}
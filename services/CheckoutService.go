@@ -0,0 +1,184 @@
+package services
+
+import (
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"time"
+)
+
+// CheckoutService registra un pedido completo (productos, cliente, domicilio y pago) en una sola operación
+type CheckoutService struct {
+	store repositories.Store
+}
+
+func NewCheckoutService(store repositories.Store) *CheckoutService {
+	return &CheckoutService{store: store}
+}
+
+// Checkout valida la solicitud y crea en una misma transacción el pedido, sus productos con el precio
+// vigente, la relación con el cliente, el domicilio (si se envía) y el pago por el total con las
+// promociones que apliquen. Si algo falla, incluido un código de promoción que no aplica, no queda
+// ningún registro. Un cliente autenticado solo puede hacer pedidos a su nombre. Con PROGRAMADO_PARA
+// el pedido queda programado y espera fuera de la cocina hasta su hora.
+func (s *CheckoutService) Checkout(req *models.CheckoutRequest, actor Actor) (*models.CheckoutResponse, error) {
+	return s.registrar(req, actor, true)
+}
+
+// registrar es el checkout. Con cobrado el pago y el domicilio quedan PAGADO y el pedido pasa a
+// PAGADO; sin él, como en los pedidos que toma el personal por teléfono, el pago y el domicilio
+// quedan PENDIENTE hasta que se cobren y el pedido sigue INICIADO.
+func (s *CheckoutService) registrar(req *models.CheckoutRequest, actor Actor, cobrado bool) (*models.CheckoutResponse, error) {
+	if actor.Rol == RolCliente {
+		if req.PK_DOCUMENTO_CLIENTE == 0 {
+			req.PK_DOCUMENTO_CLIENTE = actor.Documento
+		}
+		if req.PK_DOCUMENTO_CLIENTE != actor.Documento {
+			return nil, newError(http.StatusForbidden, "Un cliente solo puede hacer pedidos a su nombre", nil)
+		}
+	}
+	if err := validateCheckout(req); err != nil {
+		return nil, err
+	}
+
+	var response models.CheckoutResponse
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		cliente, err := tx.Clientes().Get(req.PK_DOCUMENTO_CLIENTE)
+		if err != nil {
+			return lookup(err, unprocessable("El cliente indicado no existe"))
+		}
+		if _, err := tx.MetodosPago().Get(req.PK_ID_METODO_PAGO); err != nil {
+			return lookup(err, unprocessable("El método de pago indicado no existe"))
+		}
+		lineas, err := cotizar(tx, req.PRODUCTOS)
+		if err != nil {
+			return err
+		}
+		response.PRODUCTOS = lineas
+
+		pedidos := NewPedidoService(tx)
+		pedido := models.Pedido{HORA: req.HORA, DELIVERY: req.DOMICILIO != nil, PROPINA_ACEPTADA: req.ACEPTA_PROPINA}
+		if err := pedidos.Create(&pedido, actor); err != nil {
+			return err
+		}
+		// El cliente se asocia antes de cotizar para que cuenten sus límites de uso de promociones
+		documento := int64(cliente.PK_DOCUMENTO_CLIENTE)
+		relacion := models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &documento, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO}
+		if err := tx.PedidosClientes().Insert(&relacion); err != nil {
+			return internalError("Error al asociar el pedido al cliente", err)
+		}
+		response.PK_ID_PEDIDO_CLIENTE = relacion.PK_ID_PEDIDO_CLIENTE
+		if req.PROGRAMADO_PARA != "" {
+			if _, err := pedidos.Programar(pedido.PK_ID_PEDIDO, req.PROGRAMADO_PARA, actor); err != nil {
+				return err
+			}
+		}
+
+		if err := agregarLineas(tx, pedido.PK_ID_PEDIDO, lineas, actor); err != nil {
+			return err
+		}
+		if req.CODIGO_PROMOCION != "" {
+			if _, err := pedidos.UpdatePromocion(pedido.PK_ID_PEDIDO, req.CODIGO_PROMOCION, actor); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().In(database.BogotaZone)
+		estadoPago := "PENDIENTE"
+		if cobrado {
+			estadoPago = "PAGADO"
+		}
+		if req.DOMICILIO != nil {
+			domicilio := models.Domicilio{
+				DIRECCION:     primeroNoVacio(req.DOMICILIO.DIRECCION, cliente.DIRECCION),
+				TELEFONO:      primeroNoVacio(req.DOMICILIO.TELEFONO, cliente.TELEFONO),
+				OBSERVACIONES: req.DOMICILIO.OBSERVACIONES,
+				DISTANCIA_KM:  req.DOMICILIO.DISTANCIA_KM,
+				ESTADO_PAGO:   estadoPago,
+				FECHA:         now,
+			}
+			if domicilio.DIRECCION == "" {
+				return badRequest("El domicilio necesita una DIRECCION y el cliente no tiene una registrada")
+			}
+			if err := tx.Domicilios().Insert(&domicilio); err != nil {
+				return internalError("Error al crear el domicilio", err)
+			}
+			PublicarDomicilio(tx, &domicilio, AccionCreado)
+			if _, err := pedidos.AssignDomicilio(pedido.PK_ID_PEDIDO, domicilio.PK_ID_DOMICILIO, actor); err != nil {
+				return err
+			}
+			if response.DOMICILIO, err = tx.Domicilios().Get(domicilio.PK_ID_DOMICILIO); err != nil {
+				return internalError("Error al consultar el domicilio", err)
+			}
+		}
+
+		totales, err := pedidos.GetByID(pedido.PK_ID_PEDIDO)
+		if err != nil {
+			return err
+		}
+		response.TOTAL = totales.TOTAL
+
+		pago := models.Pago{
+			FECHA:                now,
+			HORA:                 now.Format("15:04:05"),
+			MONTO:                response.TOTAL,
+			ESTADO_PAGO:          "PENDIENTE",
+			PK_ID_METODO_PAGO:    req.PK_ID_METODO_PAGO,
+			PK_ID_PEDIDO:         &pedido.PK_ID_PEDIDO,
+			PK_ID_PEDIDO_CLIENTE: &relacion.PK_ID_PEDIDO_CLIENTE,
+		}
+		if err := tx.Pagos().Insert(&pago); err != nil {
+			return internalError("Error al crear el pago", err)
+		}
+		PublicarPago(tx, &pago, AccionCreado)
+		if cobrado {
+			if response.PEDIDO, err = pedidos.AssignPago(pedido.PK_ID_PEDIDO, pago.PK_ID_PAGO, actor); err != nil {
+				return err
+			}
+		} else {
+			// El pago queda asociado al pedido sin cobrarse; se cobra asignándolo con PUT .../pago
+			totales.PK_ID_PAGO = &pago.PK_ID_PAGO
+			if err := pedidos.save(totales, totales.VERSION, "PK_ID_PAGO"); err != nil {
+				return err
+			}
+			response.PEDIDO = totales
+		}
+		if response.PAGO, err = tx.Pagos().Get(pago.PK_ID_PAGO); err != nil {
+			return internalError("Error al consultar el pago", err)
+		}
+		if response.PROMOCIONES, err = tx.Promociones().ListByPedido(pedido.PK_ID_PEDIDO); err != nil {
+			return internalError("Error al consultar las promociones del pedido", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// validateCheckout revisa la forma de la solicitud antes de consultar la base de datos; los
+// productos los valida cotizar
+func validateCheckout(req *models.CheckoutRequest) error {
+	if req.PK_DOCUMENTO_CLIENTE == 0 {
+		return badRequest("El campo PK_DOCUMENTO_CLIENTE es obligatorio")
+	}
+	if req.PK_ID_METODO_PAGO == 0 {
+		return badRequest("El campo PK_ID_METODO_PAGO es obligatorio")
+	}
+	if req.HORA != "" {
+		if _, err := time.Parse("15:04:05", req.HORA); err != nil {
+			return newError(http.StatusBadRequest, "Formato de hora inválido, debe ser HH:mm:ss", err)
+		}
+	}
+	return nil
+}
+
+// primeroNoVacio devuelve valor o, si está vacío, el valor por defecto
+func primeroNoVacio(valor, defecto string) string {
+	if valor != "" {
+		return valor
+	}
+	return defecto
+}
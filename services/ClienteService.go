@@ -0,0 +1,37 @@
+package services
+
+import (
+	"restaurante/models"
+	"restaurante/repositories"
+)
+
+// ClienteService concentra la lógica de clientes. Las respuestas nunca incluyen la contraseña.
+type ClienteService struct {
+	store repositories.Store
+}
+
+func NewClienteService(store repositories.Store) *ClienteService {
+	return &ClienteService{store: store}
+}
+
+// List devuelve todos los clientes
+func (s *ClienteService) List() ([]models.Cliente, error) {
+	clientes, err := s.store.Clientes().List()
+	if err != nil {
+		return nil, internalError("Error al obtener los clientes", err)
+	}
+	for i := range clientes {
+		clientes[i].PASSWORD = ""
+	}
+	return clientes, nil
+}
+
+// GetByID busca un cliente por su documento
+func (s *ClienteService) GetByID(documento int) (*models.Cliente, error) {
+	cliente, err := s.store.Clientes().Get(documento)
+	if err != nil {
+		return nil, lookup(err, notFound("Cliente no encontrado"))
+	}
+	cliente.PASSWORD = ""
+	return cliente, nil
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strings"
+	"time"
+)
+
+// Estados de los tickets de cocina y de cada uno de sus ítems
+const (
+	TicketPendiente  = "PENDIENTE"
+	TicketPreparando = "PREPARANDO"
+	TicketListo      = "LISTO"
+)
+
+// EstacionGeneral es la estación de los productos que no indican otra
+const EstacionGeneral = "COCINA"
+
+// EstadosTicket son los estados válidos de un ticket o de un ítem en el orden en que avanzan
+var EstadosTicket = []string{TicketPendiente, TicketPreparando, TicketListo}
+
+// CocinaService alimenta la pantalla de cocina: reparte los pedidos en tickets por estación y
+// avanza el pedido a LISTO cuando todas las estaciones terminan
+type CocinaService struct {
+	store repositories.Store
+}
+
+func NewCocinaService(store repositories.Store) *CocinaService {
+	return &CocinaService{store: store}
+}
+
+// Tickets devuelve los tickets de la estación (o de todas) en el estado indicado, del más antiguo
+// al más reciente. Sin estado devuelve los que siguen en la cocina (PENDIENTE y PREPARANDO). No se
+// muestran los tickets de pedidos cancelados ni los pendientes de pedidos que ya salieron de la
+// cocina.
+func (s *CocinaService) Tickets(estacion, estado string, actor Actor) ([]models.TicketCocina, error) {
+	if err := requierePersonal(actor, "ver los tickets de cocina"); err != nil {
+		return nil, err
+	}
+	estados := []string{TicketPendiente, TicketPreparando}
+	if estado = strings.ToUpper(strings.TrimSpace(estado)); estado != "" {
+		if !esEstadoTicket(estado) {
+			return nil, estadoTicketInvalido(estado)
+		}
+		estados = []string{estado}
+	}
+
+	tickets, err := s.store.TicketsCocina().List(strings.ToUpper(strings.TrimSpace(estacion)), estados)
+	if err != nil {
+		return nil, internalError("Error al consultar los tickets de cocina", err)
+	}
+
+	visibles := []models.TicketCocina{}
+	pedidos := map[int]*models.Pedido{}
+	for _, ticket := range tickets {
+		if len(ticket.ITEMS) == 0 {
+			continue
+		}
+		pedido, ok := pedidos[ticket.PK_ID_PEDIDO]
+		if !ok {
+			if pedido, err = s.store.Pedidos().Get(ticket.PK_ID_PEDIDO); err != nil {
+				return nil, internalError("Error al consultar el pedido del ticket", err)
+			}
+			pedidos[ticket.PK_ID_PEDIDO] = pedido
+		}
+		if pedido.ESTADO_PEDIDO == EstadoCancelado || (ticket.ESTADO != TicketListo && pedido.ESTADO_PEDIDO != EstadoEnPreparacion) {
+			continue
+		}
+		ticket.PK_ID_MESA = pedido.PK_ID_MESA
+		ticket.DELIVERY = esDomicilio(pedido)
+		visibles = append(visibles, ticket)
+	}
+	return visibles, nil
+}
+
+// UpdateItem cambia el estado de un ítem del ticket. El ticket toma el estado de sus ítems y,
+// cuando quedan listos todos los tickets del pedido, el pedido pasa a LISTO.
+func (s *CocinaService) UpdateItem(ticketID, itemID int64, estado string, actor Actor) (*models.TicketCocina, error) {
+	estado = strings.ToUpper(strings.TrimSpace(estado))
+	if estado == "" {
+		return nil, badRequest("El estado del ítem es obligatorio")
+	}
+	if !esEstadoTicket(estado) {
+		return nil, estadoTicketInvalido(estado)
+	}
+
+	var ticket *models.TicketCocina
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if ticket, err = ticketEnCocina(tx, ticketID, actor); err != nil {
+			return err
+		}
+		encontrado := false
+		for i := range ticket.ITEMS {
+			if ticket.ITEMS[i].PK_ID_ITEM_TICKET == itemID {
+				ticket.ITEMS[i].ESTADO = estado
+				encontrado = true
+			}
+		}
+		if !encontrado {
+			return notFound(fmt.Sprintf("El ticket %d no tiene el ítem %d", ticketID, itemID))
+		}
+		return guardarTicket(tx, ticket, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// Bump marca listos todos los ítems del ticket y lo saca de la pantalla de la estación
+func (s *CocinaService) Bump(ticketID int64, actor Actor) (*models.TicketCocina, error) {
+	var ticket *models.TicketCocina
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if ticket, err = ticketEnCocina(tx, ticketID, actor); err != nil {
+			return err
+		}
+		if ticket.ESTADO == TicketListo {
+			return &Error{Code: http.StatusConflict, Message: "El ticket ya está listo", Data: ticket}
+		}
+		for i := range ticket.ITEMS {
+			ticket.ITEMS[i].ESTADO = TicketListo
+		}
+		return guardarTicket(tx, ticket, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// Recall devuelve a la pantalla un ticket que se marcó listo por error: sus ítems vuelven a
+// PREPARANDO y, si el pedido ya había pasado a LISTO, regresa a EN PREPARACION. Un pedido que
+// ya salió de la cocina (EN CAMINO, ENTREGADO o CANCELADO) responde 409.
+func (s *CocinaService) Recall(ticketID int64, actor Actor) (*models.TicketCocina, error) {
+	if err := requierePersonal(actor, "manejar los tickets de cocina"); err != nil {
+		return nil, err
+	}
+
+	var ticket *models.TicketCocina
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if ticket, err = tx.TicketsCocina().Get(ticketID); err != nil {
+			return lookup(err, notFound("Ticket de cocina no encontrado"))
+		}
+		if ticket.ESTADO != TicketListo {
+			return &Error{Code: http.StatusConflict, Message: "Solo se puede devolver a la cocina un ticket listo", Data: ticket}
+		}
+		pedido, err := NewPedidoService(tx).GetByID(ticket.PK_ID_PEDIDO)
+		if err != nil {
+			return err
+		}
+		if pedido.ESTADO_PEDIDO != EstadoEnPreparacion && pedido.ESTADO_PEDIDO != EstadoListo {
+			return &Error{
+				Code:    http.StatusConflict,
+				Message: fmt.Sprintf("Un pedido %s ya salió de la cocina", pedido.ESTADO_PEDIDO),
+				Data:    pedido,
+			}
+		}
+
+		for i := range ticket.ITEMS {
+			ticket.ITEMS[i].ESTADO = TicketPreparando
+		}
+		ticket.ESTADO = TicketPreparando
+		ticket.LISTO_EN = nil
+		if err := tx.TicketsCocina().Update(ticket); err != nil {
+			return internalError("Error al actualizar el ticket de cocina", err)
+		}
+
+		// El regreso de LISTO a EN PREPARACION no está en transicionesPedido: solo lo hace la cocina
+		if pedido.ESTADO_PEDIDO == EstadoListo {
+			pedido.ESTADO_PEDIDO = EstadoEnPreparacion
+			if err := NewPedidoService(tx).save(pedido, pedido.VERSION, "ESTADO_PEDIDO"); err != nil {
+				return err
+			}
+			return registrarEstado(tx, pedido.PK_ID_PEDIDO, EstadoListo, EstadoEnPreparacion, actor)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// enviarACocina crea los tickets de las líneas del pedido que aún no están en ninguno: uno por
+// estación, con las estaciones en el orden en que aparecen en el pedido. Se llama cuando el
+// pedido entra a EN PREPARACION y cuando se le agregan productos estando en preparación.
+func enviarACocina(tx repositories.Store, pedidoID int) error {
+	detalles, err := tx.DetallesPedido().ListByPedido(pedidoID)
+	if err != nil {
+		return internalError("Error al consultar los productos del pedido", err)
+	}
+	existentes, err := tx.TicketsCocina().ListByPedido(pedidoID)
+	if err != nil {
+		return internalError("Error al consultar los tickets de cocina del pedido", err)
+	}
+	enviados := map[int64]bool{}
+	for _, ticket := range existentes {
+		for _, item := range ticket.ITEMS {
+			enviados[item.PK_ID_DETALLE_PEDIDO] = true
+		}
+	}
+
+	now := time.Now().In(database.BogotaZone)
+	tickets := map[string]*models.TicketCocina{}
+	estaciones := []string{}
+	for _, detalle := range detalles {
+		if enviados[detalle.PK_ID_DETALLE_PEDIDO] {
+			continue
+		}
+		estacion := EstacionGeneral
+		if producto, err := tx.Productos().Get(detalle.PK_ID_PRODUCTO); err == nil {
+			estacion = estacionProducto(producto)
+		}
+		ticket, ok := tickets[estacion]
+		if !ok {
+			ticket = &models.TicketCocina{PK_ID_PEDIDO: pedidoID, ESTACION: estacion, ESTADO: TicketPendiente, CREADO: now}
+			tickets[estacion] = ticket
+			estaciones = append(estaciones, estacion)
+		}
+		ticket.ITEMS = append(ticket.ITEMS, models.ItemTicket{
+			PK_ID_DETALLE_PEDIDO: detalle.PK_ID_DETALLE_PEDIDO,
+			NOMBRE:               detalle.NOMBRE,
+			CANTIDAD:             detalle.CANTIDAD,
+			NOTAS:                detalle.NOTAS,
+			MODIFICADORES:        modificadoresTicket(detalle.MODIFICADORES),
+			ESTADO:               TicketPendiente,
+		})
+	}
+
+	for _, estacion := range estaciones {
+		if err := tx.TicketsCocina().Insert(tickets[estacion]); err != nil {
+			return internalError("Error al crear el ticket de cocina", err)
+		}
+	}
+	return nil
+}
+
+// ticketEnCocina carga un ticket cuyo pedido sigue EN PREPARACION; los clientes responden 403
+func ticketEnCocina(tx repositories.Store, ticketID int64, actor Actor) (*models.TicketCocina, error) {
+	if err := requierePersonal(actor, "manejar los tickets de cocina"); err != nil {
+		return nil, err
+	}
+	ticket, err := tx.TicketsCocina().Get(ticketID)
+	if err != nil {
+		return nil, lookup(err, notFound("Ticket de cocina no encontrado"))
+	}
+	pedido, err := NewPedidoService(tx).GetByID(ticket.PK_ID_PEDIDO)
+	if err != nil {
+		return nil, err
+	}
+	if pedido.ESTADO_PEDIDO != EstadoEnPreparacion {
+		return nil, &Error{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("El ticket es de un pedido %s; solo se preparan los pedidos EN PREPARACION", pedido.ESTADO_PEDIDO),
+			Data:    ticket,
+		}
+	}
+	return ticket, nil
+}
+
+// guardarTicket recalcula el estado del ticket a partir de sus ítems, lo guarda y avanza el pedido
+// si era el último ticket pendiente
+func guardarTicket(tx repositories.Store, ticket *models.TicketCocina, actor Actor) error {
+	ticket.ESTADO = estadoTicket(ticket.ITEMS)
+	ticket.LISTO_EN = nil
+	if ticket.ESTADO == TicketListo {
+		now := time.Now().In(database.BogotaZone)
+		ticket.LISTO_EN = &now
+	}
+	if err := tx.TicketsCocina().Update(ticket); err != nil {
+		return internalError("Error al actualizar el ticket de cocina", err)
+	}
+	return sincronizarPedido(tx, ticket.PK_ID_PEDIDO, actor)
+}
+
+// sincronizarPedido pasa a LISTO el pedido EN PREPARACION cuando todos sus tickets están listos
+func sincronizarPedido(tx repositories.Store, pedidoID int, actor Actor) error {
+	tickets, err := tx.TicketsCocina().ListByPedido(pedidoID)
+	if err != nil {
+		return internalError("Error al consultar los tickets de cocina del pedido", err)
+	}
+	listos := 0
+	for _, ticket := range tickets {
+		if len(ticket.ITEMS) == 0 {
+			continue
+		}
+		if ticket.ESTADO != TicketListo {
+			return nil
+		}
+		listos++
+	}
+	if listos == 0 {
+		return nil
+	}
+
+	pedido, err := NewPedidoService(tx).GetByID(pedidoID)
+	if err != nil {
+		return err
+	}
+	if pedido.ESTADO_PEDIDO != EstadoEnPreparacion {
+		return nil
+	}
+	pedido.ESTADO_PEDIDO = EstadoListo
+	if err := NewPedidoService(tx).save(pedido, pedido.VERSION, "ESTADO_PEDIDO"); err != nil {
+		return err
+	}
+	return registrarEstado(tx, pedidoID, EstadoEnPreparacion, EstadoListo, actor)
+}
+
+// estadoTicket es LISTO si lo están todos los ítems, PENDIENTE si ninguno ha empezado y
+// PREPARANDO en otro caso
+func estadoTicket(items []models.ItemTicket) string {
+	listos, pendientes := 0, 0
+	for _, item := range items {
+		switch item.ESTADO {
+		case TicketListo:
+			listos++
+		case TicketPendiente:
+			pendientes++
+		}
+	}
+	switch {
+	case len(items) > 0 && listos == len(items):
+		return TicketListo
+	case pendientes == len(items):
+		return TicketPendiente
+	default:
+		return TicketPreparando
+	}
+}
+
+// modificadoresTicket resume las opciones de la línea como "Grupo: Opción, Grupo: Opción"
+func modificadoresTicket(modificadores []models.DetallePedidoModificador) *string {
+	if len(modificadores) == 0 {
+		return nil
+	}
+	partes := make([]string, 0, len(modificadores))
+	for _, m := range modificadores {
+		partes = append(partes, fmt.Sprintf("%s: %s", m.GRUPO, m.NOMBRE))
+	}
+	resumen := strings.Join(partes, ", ")
+	return &resumen
+}
+
+// estacionProducto normaliza la estación de un producto: vacía es la COCINA general, como el valor
+// por defecto de la columna
+func estacionProducto(producto *models.Producto) string {
+	estacion := strings.ToUpper(strings.TrimSpace(producto.ESTACION))
+	if estacion == "" {
+		return EstacionGeneral
+	}
+	return estacion
+}
+
+func esEstadoTicket(estado string) bool {
+	for _, e := range EstadosTicket {
+		if e == estado {
+			return true
+		}
+	}
+	return false
+}
+
+// estadoTicketInvalido responde 400 cuando el estado no es de los tickets de cocina
+func estadoTicketInvalido(estado string) *Error {
+	return newError(http.StatusBadRequest, "Estado de cocina inválido",
+		fmt.Errorf("'%s' no es un estado válido; use uno de: %s", estado, strings.Join(EstadosTicket, ", ")))
+}
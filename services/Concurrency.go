@@ -0,0 +1,18 @@
+package services
+
+import (
+	"net/http"
+	"restaurante/repositories"
+)
+
+// Versioned lo implementan los modelos con columna VERSION para control de concurrencia optimista
+type Versioned = repositories.Versioned
+
+// VersionMismatch responde 412 con la representación actual cuando el If-Match no coincide
+func VersionMismatch(current Versioned) *Error {
+	return &Error{
+		Code:    http.StatusPreconditionFailed,
+		Message: "El registro fue modificado por otro usuario; vuelva a consultarlo antes de actualizar",
+		Data:    current,
+	}
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strings"
+	"time"
+)
+
+// Modos de dividir la cuenta de un pedido
+const (
+	DivisionIguales     = "IGUALES"
+	DivisionPorProducto = "POR_PRODUCTO"
+	DivisionMonto       = "MONTO"
+)
+
+// CuentaService lleva la cuenta de los pedidos que se pagan en varias partes
+type CuentaService struct {
+	store repositories.Store
+}
+
+func NewCuentaService(store repositories.Store) *CuentaService {
+	return &CuentaService{store: store}
+}
+
+// Cuenta devuelve lo cobrado, lo pendiente y el saldo del pedido con sus pagos. Un cliente solo ve
+// la cuenta de sus propios pedidos; los de otros responden 404.
+func (s *CuentaService) Cuenta(pedidoID int, actor Actor) (*models.CuentaPedido, error) {
+	pedido, err := NewPedidoService(s.store).GetByID(pedidoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := accesoPedido(s.store, pedidoID, actor); err != nil {
+		return nil, err
+	}
+	return cuentaPedido(s.store, pedido)
+}
+
+// Dividir reparte el saldo del pedido en un pago PENDIENTE por cada parte, asociado al cliente que
+// la paga si se indica. Cada pago se cobra después con AssignPago y el pedido pasa a PAGADO cuando
+// quedan cubiertos todos. Un pedido cancelado, ya pagado o con pagos pendientes de otra división
+// responde 409 y el de otro cliente 404. Solo el personal puede asignar una parte a un cliente que
+// aún no está en el pedido; un cliente solo reparte entre los que ya están y recibe 403.
+func (s *CuentaService) Dividir(pedidoID int, division *models.DivisionCuenta, actor Actor) (*models.CuentaPedido, error) {
+	division.MODO = strings.ToUpper(strings.TrimSpace(division.MODO))
+	if err := validateDivision(division); err != nil {
+		return nil, err
+	}
+
+	var cuenta *models.CuentaPedido
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		pedido, err := NewPedidoService(tx).GetByID(pedidoID)
+		if err != nil {
+			return err
+		}
+		if err := accesoPedido(tx, pedidoID, actor); err != nil {
+			return err
+		}
+		if pedido.ESTADO_PEDIDO == EstadoCancelado {
+			return &Error{Code: http.StatusConflict, Message: "Un pedido cancelado no se puede cobrar", Data: pedido}
+		}
+		if cuenta, err = cuentaPedido(tx, pedido); err != nil {
+			return err
+		}
+		if cuenta.PENDIENTE > 0 {
+			return &Error{Code: http.StatusConflict, Message: "El pedido tiene pagos pendientes; cóbrelos o elimínelos antes de dividir la cuenta de nuevo", Data: cuenta}
+		}
+		if cuenta.SALDO == 0 {
+			return &Error{Code: http.StatusConflict, Message: "El pedido ya está pagado", Data: cuenta}
+		}
+
+		montos, err := montosDivision(tx, pedido, division, cuenta.SALDO)
+		if err != nil {
+			return err
+		}
+		now := time.Now().In(database.BogotaZone)
+		for i, parte := range division.PARTES {
+			if _, err := tx.MetodosPago().Get(parte.PK_ID_METODO_PAGO); err != nil {
+				return lookup(err, unprocessable(fmt.Sprintf("El método de pago %d no existe", parte.PK_ID_METODO_PAGO)))
+			}
+			pago := models.Pago{
+				FECHA:             now,
+				HORA:              now.Format("15:04:05"),
+				MONTO:             montos[i],
+				ESTADO_PAGO:       "PENDIENTE",
+				PK_ID_METODO_PAGO: parte.PK_ID_METODO_PAGO,
+				PK_ID_PEDIDO:      &pedido.PK_ID_PEDIDO,
+			}
+			if parte.PK_DOCUMENTO_CLIENTE != 0 {
+				relacion, err := relacionCliente(tx, pedido.PK_ID_PEDIDO, parte.PK_DOCUMENTO_CLIENTE, actor)
+				if err != nil {
+					return err
+				}
+				pago.PK_ID_PEDIDO_CLIENTE = &relacion.PK_ID_PEDIDO_CLIENTE
+			}
+			if err := tx.Pagos().Insert(&pago); err != nil {
+				return internalError("Error al crear el pago de la parte", err)
+			}
+			PublicarPago(tx, &pago, AccionCreado)
+		}
+
+		cuenta, err = cuentaPedido(tx, pedido)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cuenta, nil
+}
+
+// validateDivision revisa el modo y las partes antes de consultar la base de datos
+func validateDivision(division *models.DivisionCuenta) error {
+	switch division.MODO {
+	case DivisionIguales, DivisionPorProducto, DivisionMonto:
+	default:
+		return newError(http.StatusBadRequest, "Modo de división inválido",
+			fmt.Errorf("'%s' no es un modo válido; use %s, %s o %s", division.MODO, DivisionIguales, DivisionPorProducto, DivisionMonto))
+	}
+	if len(division.PARTES) == 0 {
+		return badRequest("Debe indicar al menos una parte en PARTES")
+	}
+	for _, parte := range division.PARTES {
+		if parte.PK_ID_METODO_PAGO == 0 {
+			return badRequest("Cada parte necesita su PK_ID_METODO_PAGO")
+		}
+		if division.MODO == DivisionMonto && parte.MONTO <= 0 {
+			return badRequest("En el modo MONTO cada parte necesita un MONTO mayor a cero")
+		}
+		if division.MODO == DivisionPorProducto && len(parte.DETALLES) == 0 {
+			return badRequest("En el modo POR_PRODUCTO cada parte necesita al menos una línea en DETALLES")
+		}
+	}
+	return nil
+}
+
+// montosDivision calcula cuánto paga cada parte del saldo. En partes iguales los pesos que sobran
+// se cargan a las primeras partes; por producto cada parte paga la proporción del saldo que
+// representan sus líneas, de modo que impuestos, domicilio y propina se reparten igual.
+func montosDivision(tx repositories.Store, pedido *models.Pedido, division *models.DivisionCuenta, saldo int64) ([]int64, error) {
+	montos := make([]int64, len(division.PARTES))
+	switch division.MODO {
+	case DivisionIguales:
+		partes := int64(len(montos))
+		for i := range montos {
+			montos[i] = saldo / partes
+			if int64(i) < saldo%partes {
+				montos[i]++
+			}
+		}
+
+	case DivisionMonto:
+		var suma int64
+		for i, parte := range division.PARTES {
+			montos[i] = parte.MONTO
+			suma += parte.MONTO
+		}
+		if suma > saldo {
+			return nil, badRequest(fmt.Sprintf("Los montos suman %d y el saldo del pedido es %d", suma, saldo))
+		}
+
+	case DivisionPorProducto:
+		detalles, err := tx.DetallesPedido().ListByPedido(pedido.PK_ID_PEDIDO)
+		if err != nil {
+			return nil, internalError("Error al consultar los productos del pedido", err)
+		}
+		valores := map[int64]int64{}
+		for _, detalle := range detalles {
+			valores[detalle.PK_ID_DETALLE_PEDIDO] = detalle.PRECIO_UNITARIO*int64(detalle.CANTIDAD) - detalle.DESCUENTO
+		}
+
+		asignadas := map[int64]bool{}
+		valoresPartes := make([]int64, len(montos))
+		var total int64
+		for i, parte := range division.PARTES {
+			for _, id := range parte.DETALLES {
+				valor, ok := valores[id]
+				if !ok {
+					return nil, unprocessable(fmt.Sprintf("La línea %d no pertenece al pedido", id))
+				}
+				if asignadas[id] {
+					return nil, badRequest(fmt.Sprintf("La línea %d está en más de una parte", id))
+				}
+				asignadas[id] = true
+				valoresPartes[i] += valor
+				total += valor
+			}
+		}
+		if len(asignadas) != len(valores) {
+			return nil, badRequest("Todas las líneas del pedido deben quedar en alguna parte")
+		}
+
+		var repartido int64
+		for i := range montos {
+			if total > 0 {
+				montos[i] = saldo * valoresPartes[i] / total
+			}
+			repartido += montos[i]
+		}
+		montos[len(montos)-1] += saldo - repartido
+	}
+	return montos, nil
+}
+
+// cuentaPedido suma los pagos del pedido por estado; los pagos reembolsados no cuentan
+func cuentaPedido(store repositories.Store, pedido *models.Pedido) (*models.CuentaPedido, error) {
+	pagos, err := store.Pagos().ListByPedido(pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return nil, internalError("Error al consultar los pagos del pedido", err)
+	}
+
+	cuenta := models.CuentaPedido{PK_ID_PEDIDO: pedido.PK_ID_PEDIDO, TOTAL: pedido.TOTAL, PAGOS: pagos}
+	for i := range pagos {
+		formatearPago(&pagos[i])
+		switch pagos[i].ESTADO_PAGO {
+		case "PAGADO":
+			cuenta.COBRADO += pagos[i].MONTO
+		case PagoReembolsado:
+		default:
+			cuenta.PENDIENTE += pagos[i].MONTO
+		}
+	}
+	if cuenta.COBRADO < cuenta.TOTAL {
+		cuenta.SALDO = cuenta.TOTAL - cuenta.COBRADO
+	}
+	return &cuenta, nil
+}
+
+// relacionCliente devuelve la relación del cliente con el pedido y, si aún no existe y quien la pide
+// es del personal, la crea; un cliente no puede agregar a otros clientes al pedido
+func relacionCliente(tx repositories.Store, pedidoID, documento int, actor Actor) (*models.PedidoCliente, error) {
+	if _, err := tx.Clientes().Get(documento); err != nil {
+		return nil, lookup(err, unprocessable(fmt.Sprintf("El cliente %d no existe", documento)))
+	}
+	relaciones, err := tx.PedidosClientes().ListByPedido(pedidoID)
+	if err != nil {
+		return nil, internalError("Error al consultar los clientes del pedido", err)
+	}
+	for i := range relaciones {
+		if relaciones[i].PK_DOCUMENTO_CLIENTE != nil && *relaciones[i].PK_DOCUMENTO_CLIENTE == int64(documento) {
+			return &relaciones[i], nil
+		}
+	}
+
+	if err := requierePersonal(actor, "agregar clientes a la cuenta de un pedido"); err != nil {
+		return nil, err
+	}
+	cliente := int64(documento)
+	relacion := models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &cliente, PK_ID_PEDIDO: &pedidoID}
+	if err := tx.PedidosClientes().Insert(&relacion); err != nil {
+		return nil, internalError("Error al asociar el cliente al pedido", err)
+	}
+	return &relacion, nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"restaurante/models"
+	"restaurante/repositories"
+)
+
+// DomicilioService concentra la lógica de domicilios compartida por las versiones v1 y v2 del API
+type DomicilioService struct {
+	store repositories.Store
+}
+
+func NewDomicilioService(store repositories.Store) *DomicilioService {
+	return &DomicilioService{store: store}
+}
+
+// GetByID busca un domicilio por su identificador
+func (s *DomicilioService) GetByID(id int) (*models.Domicilio, error) {
+	domicilio, err := s.store.Domicilios().Get(id)
+	if err != nil {
+		return nil, lookup(err, notFound("Domicilio no encontrado"))
+	}
+	return domicilio, nil
+}
+
+// Update guarda el domicilio si nadie lo modificó desde la versión indicada
+func (s *DomicilioService) Update(domicilio *models.Domicilio, version int) error {
+	return s.save(domicilio, version)
+}
+
+// AssignDomiciliario entrega el domicilio al trabajador que lo va a llevar. Desde ese momento el
+// domiciliario recibe los eventos del domicilio y de su pedido. Los clientes responden 403 y un
+// trabajador inexistente o retirado 422.
+func (s *DomicilioService) AssignDomiciliario(id int, documento int64, actor Actor) (*models.Domicilio, error) {
+	if err := requierePersonal(actor, "asignar domiciliarios"); err != nil {
+		return nil, err
+	}
+	if documento <= 0 {
+		return nil, badRequest("El campo PK_DOCUMENTO_DOMICILIARIO es obligatorio")
+	}
+
+	var domicilio *models.Domicilio
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		trabajador, err := tx.Trabajadores().Get(documento)
+		if err != nil {
+			return lookup(err, unprocessable("El trabajador indicado no existe"))
+		}
+		if trabajador.FECHA_RETIRO != nil {
+			return unprocessable("El trabajador indicado ya no trabaja en el restaurante")
+		}
+
+		service := NewDomicilioService(tx)
+		if domicilio, err = service.GetByID(id); err != nil {
+			return err
+		}
+		domicilio.PK_DOCUMENTO_DOMICILIARIO = &documento
+		return service.save(domicilio, domicilio.VERSION, "PK_DOCUMENTO_DOMICILIARIO")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return domicilio, nil
+}
+
+// Delete elimina un domicilio existente
+func (s *DomicilioService) Delete(id int) error {
+	if err := s.store.Domicilios().Delete(id); err != nil {
+		return lookup(err, notFound("Domicilio no encontrado"))
+	}
+	return nil
+}
+
+// save guarda el domicilio si sigue en la versión indicada; en un conflicto responde 409 con el actual
+func (s *DomicilioService) save(domicilio *models.Domicilio, version int, cols ...string) error {
+	err := s.store.Domicilios().Update(domicilio, version, cols...)
+	if err != nil {
+		return saveError(err, func() (*models.Domicilio, error) {
+			return s.store.Domicilios().Get(domicilio.PK_ID_DOMICILIO)
+		})
+	}
+	accion := AccionActualizado
+	if contiene(cols, "PK_DOCUMENTO_DOMICILIARIO") {
+		accion = AccionAsignado
+	}
+	PublicarDomicilio(s.store, domicilio, accion)
+	return nil
+}
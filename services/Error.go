@@ -0,0 +1,44 @@
+package services
+
+import "net/http"
+
+// Error representa un fallo de negocio junto con el código HTTP que debe devolver el controlador
+type Error struct {
+	Code    int
+	Message string
+	Cause   error
+	// Data acompaña la respuesta de error, por ejemplo la representación actual en un 412/409
+	Data any
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+func newError(code int, message string, cause error) *Error {
+	return &Error{Code: code, Message: message, Cause: cause}
+}
+
+// Atajos para los errores más comunes
+func badRequest(message string) *Error {
+	return newError(http.StatusBadRequest, message, nil)
+}
+
+func notFound(message string) *Error {
+	return newError(http.StatusNotFound, message, nil)
+}
+
+func unprocessable(message string) *Error {
+	return newError(http.StatusUnprocessableEntity, message, nil)
+}
+
+func internalError(message string, cause error) *Error {
+	return newError(http.StatusInternalServerError, message, cause)
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recursos que publican eventos
+const (
+	EventoPedido    = "PEDIDO"
+	EventoDomicilio = "DOMICILIO"
+	EventoPago      = "PAGO"
+	EventoReserva   = "RESERVA"
+)
+
+// Acciones de los eventos
+const (
+	AccionCreado      = "CREADO"
+	AccionActualizado = "ACTUALIZADO"
+	AccionAsignado    = "ASIGNADO"
+	AccionCancelado   = "CANCELADO"
+)
+
+// TiposEvento son los recursos a los que se puede suscribir un cliente de /v2/eventos
+var TiposEvento = []string{EventoPedido, EventoDomicilio, EventoPago, EventoReserva}
+
+// bufferSuscripcion es cuántos eventos esperan a un suscriptor lento antes de descartarse
+const bufferSuscripcion = 64
+
+// HubEventos reparte en el proceso los cambios de pedidos, domicilios, pagos y reservas entre los
+// suscriptores conectados. No guarda historial: quien se conecta recibe los eventos desde ese momento.
+type HubEventos struct {
+	mu            sync.Mutex
+	siguiente     int64
+	suscripciones map[*Suscripcion]struct{}
+}
+
+// Suscripcion es la conexión de un usuario al hub; Eventos recibe solo lo que el usuario puede ver
+type Suscripcion struct {
+	Eventos <-chan models.Evento
+	canal   chan models.Evento
+	actor   Actor
+	tipos   map[string]bool
+	hub     *HubEventos
+}
+
+// Eventos es el hub del proceso al que publican los servicios
+var Eventos = NewHubEventos()
+
+func NewHubEventos() *HubEventos {
+	return &HubEventos{suscripciones: map[*Suscripcion]struct{}{}}
+}
+
+// Suscribir conecta al actor con los tipos de evento indicados (todos si se omiten). Un tipo
+// desconocido responde 400. La suscripción se debe cerrar con Cerrar.
+func (h *HubEventos) Suscribir(actor Actor, tipos []string) (*Suscripcion, error) {
+	filtro := map[string]bool{}
+	for _, tipo := range tipos {
+		tipo = strings.ToUpper(strings.TrimSpace(tipo))
+		if tipo == "" {
+			continue
+		}
+		if !esTipoEvento(tipo) {
+			return nil, newError(http.StatusBadRequest, "Tipo de evento inválido",
+				fmt.Errorf("'%s' no es un tipo válido; use uno de: %s", tipo, strings.Join(TiposEvento, ", ")))
+		}
+		filtro[tipo] = true
+	}
+
+	canal := make(chan models.Evento, bufferSuscripcion)
+	suscripcion := &Suscripcion{Eventos: canal, canal: canal, actor: actor, tipos: filtro, hub: h}
+	h.mu.Lock()
+	h.suscripciones[suscripcion] = struct{}{}
+	h.mu.Unlock()
+	return suscripcion, nil
+}
+
+// Cerrar desconecta la suscripción y cierra su canal de eventos
+func (s *Suscripcion) Cerrar() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.suscripciones[s]; ok {
+		delete(s.hub.suscripciones, s)
+		close(s.canal)
+	}
+}
+
+// Publicar numera el evento y lo entrega a las suscripciones que pueden verlo. Nunca bloquea: si
+// un suscriptor tiene su cola llena el evento se descarta para él y debe volver a consultar el recurso.
+func (h *HubEventos) Publicar(evento models.Evento) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.siguiente++
+	evento.ID = h.siguiente
+	if evento.FECHA.IsZero() {
+		evento.FECHA = time.Now().In(database.BogotaZone)
+	}
+	for suscripcion := range h.suscripciones {
+		if !suscripcion.puedeVer(evento) {
+			continue
+		}
+		select {
+		case suscripcion.canal <- evento:
+		default:
+		}
+	}
+}
+
+// puedeVer aplica los filtros de la suscripción y su rol: los clientes solo ven sus pedidos y
+// pagos, los domiciliarios lo que tienen asignado y el resto del personal todos los eventos
+func (s *Suscripcion) puedeVer(evento models.Evento) bool {
+	if len(s.tipos) > 0 && !s.tipos[evento.TIPO] {
+		return false
+	}
+	switch {
+	case s.actor.Rol == "":
+		return false
+	case s.actor.Rol == RolCliente:
+		for _, cliente := range evento.CLIENTES {
+			if cliente == int64(s.actor.Documento) {
+				return true
+			}
+		}
+		return false
+	case s.actor.esDomiciliario():
+		return evento.DOMICILIARIO != nil && *evento.DOMICILIARIO == int64(s.actor.Documento)
+	default:
+		return true
+	}
+}
+
+// PublicarPedido anuncia el cambio del pedido cuando se confirme la transacción en curso. Los
+// clientes del pedido y el domiciliario de su domicilio también lo reciben.
+func PublicarPedido(tx repositories.Store, pedido *models.Pedido, accion string) {
+	evento := models.Evento{
+		TIPO:   EventoPedido,
+		ACCION: accion,
+		PK_ID:  int64(pedido.PK_ID_PEDIDO),
+		ESTADO: pedido.ESTADO_PEDIDO,
+		DATOS:  *pedido,
+	}
+	evento.CLIENTES = clientesPedido(tx, pedido.PK_ID_PEDIDO)
+	if pedido.PK_ID_DOMICILIO != nil {
+		if domicilio, err := tx.Domicilios().Get(*pedido.PK_ID_DOMICILIO); err == nil {
+			evento.DOMICILIARIO = domicilio.PK_DOCUMENTO_DOMICILIARIO
+		}
+	}
+	tx.AfterCommit(func() { Eventos.Publicar(evento) })
+}
+
+// PublicarDomicilio anuncia el cambio del domicilio al personal y a su domiciliario
+func PublicarDomicilio(tx repositories.Store, domicilio *models.Domicilio, accion string) {
+	evento := models.Evento{
+		TIPO:         EventoDomicilio,
+		ACCION:       accion,
+		PK_ID:        int64(domicilio.PK_ID_DOMICILIO),
+		DATOS:        *domicilio,
+		DOMICILIARIO: domicilio.PK_DOCUMENTO_DOMICILIARIO,
+	}
+	if domicilio.ENTREGADO {
+		evento.ESTADO = EstadoEntregado
+	}
+	tx.AfterCommit(func() { Eventos.Publicar(evento) })
+}
+
+// PublicarPago anuncia el cambio del pago al personal y a los clientes de su pedido
+func PublicarPago(tx repositories.Store, pago *models.Pago, accion string) {
+	evento := models.Evento{
+		TIPO:   EventoPago,
+		ACCION: accion,
+		PK_ID:  int64(pago.PK_ID_PAGO),
+		ESTADO: pago.ESTADO_PAGO,
+		DATOS:  *pago,
+	}
+	if pago.PK_ID_PEDIDO != nil {
+		evento.CLIENTES = clientesPedido(tx, *pago.PK_ID_PEDIDO)
+	}
+	tx.AfterCommit(func() { Eventos.Publicar(evento) })
+}
+
+// PublicarReserva anuncia el cambio de la reserva al personal
+func PublicarReserva(tx repositories.Store, reserva *models.Reserva, accion string) {
+	evento := models.Evento{
+		TIPO:   EventoReserva,
+		ACCION: accion,
+		PK_ID:  int64(reserva.PK_ID_RESERVA),
+		DATOS:  *reserva,
+	}
+	if reserva.ESTADO_RESERVA != nil {
+		evento.ESTADO = *reserva.ESTADO_RESERVA
+	}
+	tx.AfterCommit(func() { Eventos.Publicar(evento) })
+}
+
+// clientesPedido devuelve los documentos de los clientes del pedido. Un error al consultarlos deja
+// el evento solo para el personal: los eventos no hacen fallar la operación que los origina.
+func clientesPedido(tx repositories.Store, pedidoID int) []int64 {
+	relaciones, err := tx.PedidosClientes().ListByPedido(pedidoID)
+	if err != nil {
+		return nil
+	}
+	clientes := []int64{}
+	for _, relacion := range relaciones {
+		if relacion.PK_DOCUMENTO_CLIENTE != nil {
+			clientes = append(clientes, *relacion.PK_DOCUMENTO_CLIENTE)
+		}
+	}
+	return clientes
+}
+
+func esTipoEvento(tipo string) bool {
+	for _, t := range TiposEvento {
+		if t == tipo {
+			return true
+		}
+	}
+	return false
+}
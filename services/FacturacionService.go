@@ -0,0 +1,526 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/facturacion"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// Tipos de documento electrónico
+const (
+	DocumentoFactura = "FACTURA" // Factura electrónica de venta
+	DocumentoPOS     = "POS"     // Documento equivalente electrónico del tiquete POS
+)
+
+// Estados de un documento electrónico
+const (
+	DocumentoGenerado  = "GENERADO"
+	DocumentoAceptado  = "ACEPTADO"
+	DocumentoRechazado = "RECHAZADO"
+)
+
+// tiposDocumento relaciona cada tipo de documento con su InvoiceTypeCode
+var tiposDocumento = map[string]string{
+	DocumentoFactura: facturacion.TipoFactura,
+	DocumentoPOS:     facturacion.TipoPOS,
+}
+
+// tributosCategoria relaciona las categorías de impuesto de los productos con los tributos de la
+// DIAN; los productos exentos se reportan como IVA con tarifa 0
+var tributosCategoria = map[string]string{
+	CategoriaImpoconsumo: facturacion.TributoINC,
+	CategoriaIVA:         facturacion.TributoIVA,
+	CategoriaExento:      facturacion.TributoIVA,
+}
+
+// mediosPago relaciona los métodos de pago con los códigos de medio de pago de la DIAN; los que no
+// están aquí se reportan como acuerdo mutuo (ZZZ)
+var mediosPago = map[string]string{
+	"EFECTIVO":  "10",
+	"NEQUI":     "47",
+	"DAVIPLATA": "47",
+	"TARJETA":   "48",
+}
+
+// ProveedorFacturacion transmite los documentos firmados; por defecto es el proveedor local, que
+// los valida sin salir del servidor
+var ProveedorFacturacion facturacion.Proveedor = facturacion.NewProveedorLocal()
+
+// ambienteFacturacion es el ambiente de la DIAN al que van los documentos (clave facturacion_ambiente)
+func ambienteFacturacion() string {
+	return web.AppConfig.DefaultString("facturacion_ambiente", facturacion.AmbientePruebas)
+}
+
+// emisorFacturacion son los datos del restaurante que se facturan (claves facturacion_*)
+func emisorFacturacion() facturacion.Emisor {
+	return facturacion.Emisor{
+		NIT:             web.AppConfig.DefaultString("facturacion_nit", "900123456"),
+		RazonSocial:     web.AppConfig.DefaultString("facturacion_razon_social", "RESTAURANTE S.A.S."),
+		NombreComercial: web.AppConfig.DefaultString("facturacion_nombre_comercial", "RESTAURANTE"),
+		Direccion:       web.AppConfig.DefaultString("facturacion_direccion", ""),
+		Municipio:       web.AppConfig.DefaultString("facturacion_municipio", "11001"),
+		Ciudad:          web.AppConfig.DefaultString("facturacion_ciudad", "Bogotá, D.C."),
+		Departamento:    web.AppConfig.DefaultString("facturacion_departamento", "Bogotá"),
+		Correo:          web.AppConfig.DefaultString("facturacion_correo", ""),
+		Responsabilidad: web.AppConfig.DefaultString("facturacion_responsabilidad", "R-99-PN"),
+	}
+}
+
+// softwareFacturacion es el software registrado ante la DIAN; sin proveedor tecnológico el
+// software es propio del emisor
+func softwareFacturacion(emisor facturacion.Emisor) facturacion.Software {
+	return facturacion.Software{
+		ID:           web.AppConfig.DefaultString("facturacion_software_id", ""),
+		PIN:          web.AppConfig.DefaultString("facturacion_software_pin", ""),
+		ProveedorNIT: web.AppConfig.DefaultString("facturacion_proveedor_nit", emisor.NIT),
+	}
+}
+
+var (
+	firmanteMu sync.Mutex
+	firmante   *facturacion.Firmante
+)
+
+// firmanteFacturacion carga una sola vez el certificado de la clave facturacion_certificado. Sin
+// certificado, en el ambiente de pruebas se firma con uno autofirmado; en producción responde 500.
+func firmanteFacturacion() (*facturacion.Firmante, error) {
+	firmanteMu.Lock()
+	defer firmanteMu.Unlock()
+	if firmante != nil {
+		return firmante, nil
+	}
+
+	var err error
+	ruta := web.AppConfig.DefaultString("facturacion_certificado", "")
+	switch {
+	case ruta != "":
+		firmante, err = facturacion.CargarFirmante(ruta, web.AppConfig.DefaultString("facturacion_clave_certificado", ""))
+	case ambienteFacturacion() == facturacion.AmbientePruebas:
+		firmante, err = facturacion.Autofirmado(emisorFacturacion().RazonSocial)
+	default:
+		return nil, internalError("No hay un certificado de firma digital configurado",
+			errors.New("la clave facturacion_certificado es obligatoria en el ambiente de producción"))
+	}
+	if err != nil {
+		return nil, internalError("Error al cargar el certificado de firma digital", err)
+	}
+	return firmante, nil
+}
+
+// FacturacionService emite la factura electrónica o el documento equivalente POS de los pedidos
+// pagados y administra los rangos de numeración autorizados por la DIAN
+type FacturacionService struct {
+	store repositories.Store
+}
+
+func NewFacturacionService(store repositories.Store) *FacturacionService {
+	return &FacturacionService{store: store}
+}
+
+// Resoluciones devuelve los rangos de numeración registrados
+func (s *FacturacionService) Resoluciones(actor Actor) ([]models.ResolucionFacturacion, error) {
+	if err := requierePersonal(actor, "manejar la facturación electrónica"); err != nil {
+		return nil, err
+	}
+	resoluciones, err := s.store.ResolucionesFacturacion().List()
+	if err != nil {
+		return nil, internalError("Error al obtener las resoluciones de facturación", err)
+	}
+	return resoluciones, nil
+}
+
+// CreateResolucion registra un rango de numeración; el primer consecutivo es DESDE. Solo los
+// administradores pueden hacerlo.
+func (s *FacturacionService) CreateResolucion(resolucion *models.ResolucionFacturacion, actor Actor) error {
+	if !actor.esAdministrador() {
+		return newError(http.StatusForbidden, "Solo un administrador puede registrar resoluciones de facturación", nil)
+	}
+	resolucion.SIGUIENTE = resolucion.DESDE
+	if err := validateResolucion(resolucion); err != nil {
+		return err
+	}
+	if err := s.store.ResolucionesFacturacion().Insert(resolucion); err != nil {
+		return internalError("Error al registrar la resolución de facturación", err)
+	}
+	return nil
+}
+
+// UpdateResolucion cambia los datos de una resolución. El consecutivo SIGUIENTE no se modifica,
+// para que un número ya asignado no se repita.
+func (s *FacturacionService) UpdateResolucion(resolucion *models.ResolucionFacturacion, actor Actor) error {
+	if !actor.esAdministrador() {
+		return newError(http.StatusForbidden, "Solo un administrador puede modificar resoluciones de facturación", nil)
+	}
+	return s.store.Transaction(func(tx repositories.Store) error {
+		actual, err := tx.ResolucionesFacturacion().Get(resolucion.PK_ID_RESOLUCION)
+		if err != nil {
+			return lookup(err, notFound("Resolución de facturación no encontrada"))
+		}
+		resolucion.SIGUIENTE = actual.SIGUIENTE
+		if err := validateResolucion(resolucion); err != nil {
+			return err
+		}
+		if err := tx.ResolucionesFacturacion().Update(resolucion); err != nil {
+			return internalError("Error al actualizar la resolución de facturación", err)
+		}
+		return nil
+	})
+}
+
+// Documentos devuelve los documentos emitidos, opcionalmente solo los de un pedido
+func (s *FacturacionService) Documentos(pedidoID int, actor Actor) ([]models.DocumentoElectronico, error) {
+	if err := requierePersonal(actor, "manejar la facturación electrónica"); err != nil {
+		return nil, err
+	}
+	documentos, err := s.store.DocumentosElectronicos().List(pedidoID)
+	if err != nil {
+		return nil, internalError("Error al obtener los documentos electrónicos", err)
+	}
+	return documentos, nil
+}
+
+// Documento devuelve un documento emitido
+func (s *FacturacionService) Documento(id int64, actor Actor) (*models.DocumentoElectronico, error) {
+	if err := requierePersonal(actor, "manejar la facturación electrónica"); err != nil {
+		return nil, err
+	}
+	documento, err := s.store.DocumentosElectronicos().Get(id)
+	if err != nil {
+		return nil, lookup(err, notFound("Documento electrónico no encontrado"))
+	}
+	return documento, nil
+}
+
+// XML devuelve el archivo firmado del documento con su nombre
+func (s *FacturacionService) XML(id int64, actor Actor) (string, []byte, error) {
+	documento, err := s.Documento(id, actor)
+	if err != nil {
+		return "", nil, err
+	}
+	return nombreArchivoDocumento(documento), []byte(documento.XML), nil
+}
+
+// Emitir genera, firma y guarda el documento electrónico de un pedido pagado y lo envía al
+// proveedor. El consecutivo sale de la resolución activa y vigente del tipo de documento; sin una
+// con números disponibles responde 422. Un pedido cancelado, con saldo o que ya tiene documento
+// responde 409. Si el proveedor no responde el documento queda GENERADO para reenviarlo.
+func (s *FacturacionService) Emitir(req models.DocumentoElectronicoRequest, actor Actor) (*models.DocumentoElectronico, error) {
+	if err := requierePersonal(actor, "manejar la facturación electrónica"); err != nil {
+		return nil, err
+	}
+	tipo := strings.ToUpper(strings.TrimSpace(req.TIPO_DOCUMENTO))
+	if tipo == "" {
+		tipo = DocumentoPOS
+	}
+	if _, ok := tiposDocumento[tipo]; !ok {
+		return nil, newError(http.StatusBadRequest, "Tipo de documento inválido",
+			fmt.Errorf("'%s' no es un tipo válido; use %s o %s", tipo, DocumentoFactura, DocumentoPOS))
+	}
+	if req.PK_ID_PEDIDO <= 0 {
+		return nil, badRequest("PK_ID_PEDIDO es obligatorio")
+	}
+	if err := validateAdquirente(req.ADQUIRENTE); err != nil {
+		return nil, err
+	}
+	firmante, err := firmanteFacturacion()
+	if err != nil {
+		return nil, err
+	}
+
+	var documento *models.DocumentoElectronico
+	err = s.store.Transaction(func(tx repositories.Store) error {
+		pedido, err := tx.Pedidos().Get(req.PK_ID_PEDIDO)
+		if err != nil {
+			return lookup(err, notFound("Pedido no encontrado"))
+		}
+		if pedido.ESTADO_PEDIDO == EstadoCancelado {
+			return &Error{Code: http.StatusConflict, Message: "Un pedido cancelado no se factura", Data: pedido}
+		}
+		existente, err := tx.DocumentosElectronicos().GetByPedido(pedido.PK_ID_PEDIDO)
+		if err == nil {
+			return &Error{Code: http.StatusConflict, Message: "El pedido ya tiene un documento electrónico", Data: existente}
+		} else if !errors.Is(err, repositories.ErrNotFound) {
+			return internalError("Error al consultar los documentos del pedido", err)
+		}
+		cuenta, err := cuentaPedido(tx, pedido)
+		if err != nil {
+			return err
+		}
+		if cuenta.TOTAL <= 0 || cuenta.SALDO > 0 {
+			return &Error{Code: http.StatusConflict, Message: "Solo se facturan los pedidos pagados por completo", Data: cuenta}
+		}
+		recibo, err := NewPedidoService(tx).GetRecibo(pedido.PK_ID_PEDIDO, actor)
+		if err != nil {
+			return err
+		}
+
+		emision := time.Now().In(database.BogotaZone).Truncate(time.Second)
+		resolucion, err := resolucionVigente(tx, tipo, emision)
+		if err != nil {
+			return err
+		}
+		numero, err := tx.ResolucionesFacturacion().Consumir(resolucion.PK_ID_RESOLUCION)
+		if errors.Is(err, repositories.ErrRangoAgotado) {
+			return unprocessable(fmt.Sprintf("La resolución %s agotó su rango de numeración", resolucion.NUMERO))
+		} else if err != nil {
+			return internalError("Error al asignar el consecutivo del documento", err)
+		}
+		adquirente, err := adquirentePedido(tx, pedido.PK_ID_PEDIDO, req.ADQUIRENTE)
+		if err != nil {
+			return err
+		}
+
+		factura := facturaPedido(recibo, tipo, resolucion, numero, emision, adquirente)
+		xml, err := factura.XML(firmante)
+		if err != nil {
+			return internalError("Error al firmar el documento electrónico", err)
+		}
+		documento = &models.DocumentoElectronico{
+			PK_ID_PEDIDO:         pedido.PK_ID_PEDIDO,
+			PK_ID_RESOLUCION:     resolucion.PK_ID_RESOLUCION,
+			TIPO_DOCUMENTO:       tipo,
+			PREFIJO:              resolucion.PREFIJO,
+			NUMERO:               numero,
+			CODIGO:               factura.Codigo(),
+			FECHA_EMISION:        emision,
+			TOTAL:                factura.Total,
+			DOCUMENTO_ADQUIRENTE: adquirente.Numero,
+			NOMBRE_ADQUIRENTE:    adquirente.Nombre,
+			ESTADO:               DocumentoGenerado,
+			XML:                  string(xml),
+		}
+		if err := tx.DocumentosElectronicos().Insert(documento); err != nil {
+			return internalError("Error al guardar el documento electrónico", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.enviar(documento)
+}
+
+// Reenviar vuelve a enviar al proveedor un documento que no fue aceptado; uno aceptado responde 409
+func (s *FacturacionService) Reenviar(id int64, actor Actor) (*models.DocumentoElectronico, error) {
+	documento, err := s.Documento(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if documento.ESTADO == DocumentoAceptado {
+		return nil, &Error{Code: http.StatusConflict, Message: "El documento ya fue aceptado", Data: documento}
+	}
+	return s.enviar(documento)
+}
+
+// enviar transmite el documento y guarda la respuesta del proveedor. La transmisión se hace fuera
+// de una transacción; si falla la comunicación el documento sigue GENERADO con el error como respuesta.
+func (s *FacturacionService) enviar(documento *models.DocumentoElectronico) (*models.DocumentoElectronico, error) {
+	respuesta, err := ProveedorFacturacion.Enviar(facturacion.Envio{
+		Codigo:  documento.CODIGO,
+		Archivo: nombreArchivoDocumento(documento),
+		XML:     []byte(documento.XML),
+	})
+	switch {
+	case err != nil:
+		mensaje := "Error al comunicarse con el proveedor: " + err.Error()
+		documento.ESTADO, documento.RESPUESTA = DocumentoGenerado, &mensaje
+	case respuesta.Aceptado:
+		documento.ESTADO, documento.ID_ENVIO, documento.RESPUESTA = DocumentoAceptado, &respuesta.IDEnvio, &respuesta.Mensaje
+	default:
+		documento.ESTADO, documento.RESPUESTA = DocumentoRechazado, &respuesta.Mensaje
+		if respuesta.IDEnvio != "" {
+			documento.ID_ENVIO = &respuesta.IDEnvio
+		}
+	}
+	if err := s.store.DocumentosElectronicos().Update(documento); err != nil {
+		return nil, internalError("Error al registrar la respuesta del proveedor", err)
+	}
+	return documento, nil
+}
+
+// resolucionVigente devuelve la primera resolución activa del tipo de documento cuyo periodo
+// incluye la fecha de emisión y que aún tiene consecutivos
+func resolucionVigente(tx repositories.Store, tipo string, emision time.Time) (*models.ResolucionFacturacion, error) {
+	resoluciones, err := tx.ResolucionesFacturacion().List()
+	if err != nil {
+		return nil, internalError("Error al obtener las resoluciones de facturación", err)
+	}
+	hoy := emision.Format("2006-01-02")
+	var agotada *models.ResolucionFacturacion
+	for i := range resoluciones {
+		resolucion := &resoluciones[i]
+		if !resolucion.ACTIVA || resolucion.TIPO_DOCUMENTO != tipo ||
+			resolucion.FECHA_DESDE.Format("2006-01-02") > hoy || resolucion.FECHA_HASTA.Format("2006-01-02") < hoy {
+			continue
+		}
+		if resolucion.SIGUIENTE > resolucion.HASTA {
+			agotada = resolucion
+			continue
+		}
+		return resolucion, nil
+	}
+	if agotada != nil {
+		return nil, unprocessable(fmt.Sprintf("La resolución %s agotó su rango de numeración", agotada.NUMERO))
+	}
+	return nil, unprocessable(fmt.Sprintf("No hay una resolución de facturación %s activa y vigente", tipo))
+}
+
+// adquirentePedido es el adquirente indicado en la solicitud o, si no hay, el cliente del pedido;
+// los pedidos sin cliente se facturan al consumidor final
+func adquirentePedido(tx repositories.Store, pedidoID int, req *models.AdquirenteRequest) (facturacion.Adquirente, error) {
+	if req != nil {
+		return facturacion.Adquirente{TipoDocumento: req.TIPO_DOCUMENTO, Numero: req.NUMERO, Nombre: req.NOMBRE, Correo: req.CORREO}, nil
+	}
+	consumidorFinal := facturacion.Adquirente{TipoDocumento: "13", Numero: facturacion.ConsumidorFinal, Nombre: "Consumidor final"}
+	relacion, err := tx.PedidosClientes().GetByPedido(pedidoID)
+	if errors.Is(err, repositories.ErrNotFound) || (err == nil && relacion.PK_DOCUMENTO_CLIENTE == nil) {
+		return consumidorFinal, nil
+	} else if err != nil {
+		return facturacion.Adquirente{}, internalError("Error al consultar el cliente del pedido", err)
+	}
+	cliente, err := tx.Clientes().Get(int(*relacion.PK_DOCUMENTO_CLIENTE))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return consumidorFinal, nil
+	} else if err != nil {
+		return facturacion.Adquirente{}, internalError("Error al consultar el cliente del pedido", err)
+	}
+	return facturacion.Adquirente{
+		TipoDocumento: "13",
+		Numero:        strconv.Itoa(cliente.PK_DOCUMENTO_CLIENTE),
+		Nombre:        strings.TrimSpace(cliente.NOMBRE + " " + cliente.APELLIDO),
+	}, nil
+}
+
+// facturaPedido arma el documento con las líneas del recibo. La propina y el domicilio van como
+// cargos fuera de las líneas.
+func facturaPedido(recibo *models.Recibo, tipo string, resolucion *models.ResolucionFacturacion, numero int64,
+	emision time.Time, adquirente facturacion.Adquirente) *facturacion.Factura {
+	emisor := emisorFacturacion()
+	factura := &facturacion.Factura{
+		Tipo:     tiposDocumento[tipo],
+		Ambiente: ambienteFacturacion(),
+		Numero:   numero,
+		Resolucion: facturacion.Resolucion{
+			Numero:       resolucion.NUMERO,
+			Prefijo:      resolucion.PREFIJO,
+			Desde:        resolucion.DESDE,
+			Hasta:        resolucion.HASTA,
+			FechaDesde:   resolucion.FECHA_DESDE,
+			FechaHasta:   resolucion.FECHA_HASTA,
+			ClaveTecnica: resolucion.CLAVE_TECNICA,
+		},
+		Emision:    emision,
+		Emisor:     emisor,
+		Adquirente: adquirente,
+		Software:   softwareFacturacion(emisor),
+		MedioPago:  medioPagoDIAN(recibo.METODO_PAGO),
+		Total:      recibo.TOTAL,
+	}
+	for _, linea := range recibo.PRODUCTOS {
+		// Los precios incluyen el impuesto; la DIAN recibe los valores antes de impuestos
+		base := linea.SUBTOTAL - linea.DESCUENTO - linea.IMPUESTO
+		factura.Lineas = append(factura.Lineas, facturacion.Linea{
+			Codigo:         strconv.FormatInt(linea.PK_ID_PRODUCTO, 10),
+			Descripcion:    linea.NOMBRE,
+			Cantidad:       linea.CANTIDAD,
+			PrecioUnitario: models.BaseGravable(linea.PRECIO_UNITARIO, linea.TARIFA_IMPUESTO),
+			Descuento:      models.BaseGravable(linea.SUBTOTAL, linea.TARIFA_IMPUESTO) - base,
+			Total:          base,
+			Impuesto: &facturacion.Impuesto{
+				Codigo: tributoCategoria(linea.CATEGORIA_IMPUESTO),
+				Tarifa: linea.TARIFA_IMPUESTO,
+				Base:   base,
+				Valor:  linea.IMPUESTO,
+			},
+		})
+	}
+	if recibo.PROPINA > 0 {
+		factura.Cargos = append(factura.Cargos, facturacion.Cargo{Motivo: "Propina voluntaria", Valor: recibo.PROPINA})
+	}
+	if recibo.VALOR_DOMICILIO > 0 {
+		factura.Cargos = append(factura.Cargos, facturacion.Cargo{Motivo: "Domicilio", Valor: recibo.VALOR_DOMICILIO})
+	}
+	return factura
+}
+
+func tributoCategoria(categoria string) string {
+	if tributo, ok := tributosCategoria[categoria]; ok {
+		return tributo
+	}
+	return facturacion.TributoIVA
+}
+
+func medioPagoDIAN(metodo string) string {
+	metodo = strings.ToUpper(strings.TrimSpace(metodo))
+	if metodo == "" {
+		return ""
+	}
+	if codigo, ok := mediosPago[metodo]; ok {
+		return codigo
+	}
+	return "ZZZ"
+}
+
+// nombreArchivoDocumento es el nombre del XML: prefijo y consecutivo en minúsculas
+func nombreArchivoDocumento(documento *models.DocumentoElectronico) string {
+	return strings.ToLower(fmt.Sprintf("%s%d", documento.PREFIJO, documento.NUMERO)) + ".xml"
+}
+
+func validateResolucion(resolucion *models.ResolucionFacturacion) error {
+	resolucion.NUMERO = strings.TrimSpace(resolucion.NUMERO)
+	resolucion.TIPO_DOCUMENTO = strings.ToUpper(strings.TrimSpace(resolucion.TIPO_DOCUMENTO))
+	resolucion.PREFIJO = strings.ToUpper(strings.TrimSpace(resolucion.PREFIJO))
+	resolucion.CLAVE_TECNICA = strings.TrimSpace(resolucion.CLAVE_TECNICA)
+	if resolucion.NUMERO == "" {
+		return badRequest("El NUMERO de la resolución es obligatorio")
+	}
+	if _, ok := tiposDocumento[resolucion.TIPO_DOCUMENTO]; !ok {
+		return badRequest(fmt.Sprintf("El TIPO_DOCUMENTO debe ser %s o %s", DocumentoFactura, DocumentoPOS))
+	}
+	if len(resolucion.PREFIJO) > 4 {
+		return badRequest("El PREFIJO admite hasta 4 caracteres")
+	}
+	if resolucion.DESDE <= 0 || resolucion.HASTA < resolucion.DESDE {
+		return badRequest("El rango de numeración debe cumplir 0 < DESDE <= HASTA")
+	}
+	if resolucion.SIGUIENTE < resolucion.DESDE || resolucion.SIGUIENTE > resolucion.HASTA+1 {
+		return unprocessable(fmt.Sprintf("La resolución ya asignó hasta el número %d; el rango debe incluirlo", resolucion.SIGUIENTE-1))
+	}
+	if resolucion.FECHA_DESDE.IsZero() || resolucion.FECHA_HASTA.Before(resolucion.FECHA_DESDE) {
+		return badRequest("La vigencia de la resolución debe cumplir FECHA_DESDE <= FECHA_HASTA")
+	}
+	if resolucion.TIPO_DOCUMENTO == DocumentoFactura && resolucion.CLAVE_TECNICA == "" {
+		return badRequest("La CLAVE_TECNICA es obligatoria en las resoluciones de factura electrónica")
+	}
+	return nil
+}
+
+func validateAdquirente(adquirente *models.AdquirenteRequest) error {
+	if adquirente == nil {
+		return nil
+	}
+	adquirente.TIPO_DOCUMENTO = strings.TrimSpace(adquirente.TIPO_DOCUMENTO)
+	adquirente.NUMERO = strings.TrimSpace(adquirente.NUMERO)
+	adquirente.NOMBRE = strings.TrimSpace(adquirente.NOMBRE)
+	adquirente.CORREO = strings.TrimSpace(adquirente.CORREO)
+	if adquirente.TIPO_DOCUMENTO == "" {
+		adquirente.TIPO_DOCUMENTO = "13"
+	}
+	if _, err := strconv.ParseUint(adquirente.NUMERO, 10, 64); err != nil {
+		return badRequest("El NUMERO de documento del adquirente debe tener solo dígitos")
+	}
+	if adquirente.NOMBRE == "" {
+		return badRequest("El NOMBRE del adquirente es obligatorio")
+	}
+	return nil
+}
@@ -0,0 +1,435 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"restaurante/database"
+	"restaurante/impresion"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// Tipos de trabajo de impresión
+const (
+	TrabajoRecibo  = "RECIBO"
+	TrabajoComanda = "COMANDA"
+)
+
+// Estados de un trabajo de impresión
+const (
+	ImpresionPendiente = "PENDIENTE"
+	ImpresionImpreso   = "IMPRESO"
+	ImpresionFallido   = "FALLIDO"
+)
+
+// EstadosImpresion son los estados por los que se puede filtrar la cola de impresión
+var EstadosImpresion = []string{ImpresionPendiente, ImpresionImpreso, ImpresionFallido}
+
+// intentosImpresion son las veces que se intenta enviar un trabajo antes de marcarlo FALLIDO
+// (clave impresion_intentos)
+func intentosImpresion() int {
+	return web.AppConfig.DefaultInt("impresion_intentos", 3)
+}
+
+// esperaImpresion es la pausa antes del primer reintento; se duplica en cada uno (clave impresion_espera_ms)
+func esperaImpresion() time.Duration {
+	return time.Duration(web.AppConfig.DefaultInt("impresion_espera_ms", 500)) * time.Millisecond
+}
+
+// ColaImpresion envía los trabajos en segundo plano para que la solicitud no espere a la
+// impresora. Los trabajos de una misma impresora salen de a uno, así los bytes de dos documentos
+// no se mezclan en el papel.
+type ColaImpresion struct {
+	mu         sync.Mutex
+	impresoras map[int64]*sync.Mutex
+	pendientes sync.WaitGroup
+}
+
+func NewColaImpresion() *ColaImpresion {
+	return &ColaImpresion{impresoras: map[int64]*sync.Mutex{}}
+}
+
+// Impresiones es la cola que usan los servicios
+var Impresiones = NewColaImpresion()
+
+// Encolar ejecuta fn en segundo plano cuando la impresora termina los trabajos anteriores
+func (c *ColaImpresion) Encolar(impresoraID int64, fn func()) {
+	c.mu.Lock()
+	impresora, ok := c.impresoras[impresoraID]
+	if !ok {
+		impresora = &sync.Mutex{}
+		c.impresoras[impresoraID] = impresora
+	}
+	c.mu.Unlock()
+
+	c.pendientes.Add(1)
+	go func() {
+		defer c.pendientes.Done()
+		impresora.Lock()
+		defer impresora.Unlock()
+		fn()
+	}()
+}
+
+// Esperar bloquea hasta que se procesan todos los trabajos encolados
+func (c *ColaImpresion) Esperar() {
+	c.pendientes.Wait()
+}
+
+// ImpresionService administra las impresoras de red y la cola de recibos y comandas
+type ImpresionService struct {
+	store repositories.Store
+}
+
+func NewImpresionService(store repositories.Store) *ImpresionService {
+	return &ImpresionService{store: store}
+}
+
+// Impresoras devuelve las impresoras registradas; los clientes responden 403
+func (s *ImpresionService) Impresoras(actor Actor) ([]models.Impresora, error) {
+	if err := requierePersonal(actor, "manejar las impresoras"); err != nil {
+		return nil, err
+	}
+	impresoras, err := s.store.Impresoras().List()
+	if err != nil {
+		return nil, internalError("Error al obtener las impresoras", err)
+	}
+	return impresoras, nil
+}
+
+// CreateImpresora registra una impresora. Sin PUERTO usa el 9100 y sin ANCHO papel de 80 mm.
+// Solo los administradores pueden hacerlo.
+func (s *ImpresionService) CreateImpresora(impresora *models.Impresora, actor Actor) error {
+	if !actor.esAdministrador() {
+		return newError(http.StatusForbidden, "Solo un administrador puede registrar impresoras", nil)
+	}
+	if err := validateImpresora(impresora); err != nil {
+		return err
+	}
+	if err := s.store.Impresoras().Insert(impresora); err != nil {
+		return internalError("Error al registrar la impresora", err)
+	}
+	return nil
+}
+
+// UpdateImpresora cambia los datos de una impresora; solo los administradores pueden hacerlo
+func (s *ImpresionService) UpdateImpresora(impresora *models.Impresora, actor Actor) error {
+	if !actor.esAdministrador() {
+		return newError(http.StatusForbidden, "Solo un administrador puede modificar impresoras", nil)
+	}
+	if err := validateImpresora(impresora); err != nil {
+		return err
+	}
+	return s.store.Transaction(func(tx repositories.Store) error {
+		if _, err := tx.Impresoras().Get(impresora.PK_ID_IMPRESORA); err != nil {
+			return lookup(err, notFound("Impresora no encontrada"))
+		}
+		if err := tx.Impresoras().Update(impresora); err != nil {
+			return internalError("Error al actualizar la impresora", err)
+		}
+		return nil
+	})
+}
+
+// DeleteImpresora elimina la impresora junto con su historial de trabajos; solo los
+// administradores pueden hacerlo
+func (s *ImpresionService) DeleteImpresora(id int64, actor Actor) error {
+	if !actor.esAdministrador() {
+		return newError(http.StatusForbidden, "Solo un administrador puede eliminar impresoras", nil)
+	}
+	return s.store.Transaction(func(tx repositories.Store) error {
+		if _, err := tx.Impresoras().Get(id); err != nil {
+			return lookup(err, notFound("Impresora no encontrada"))
+		}
+		if err := tx.Impresoras().Delete(id); err != nil {
+			return internalError("Error al eliminar la impresora", err)
+		}
+		return nil
+	})
+}
+
+// Trabajos devuelve la cola de impresión, opcionalmente solo los trabajos en un estado
+func (s *ImpresionService) Trabajos(estado string, actor Actor) ([]models.TrabajoImpresion, error) {
+	if err := requierePersonal(actor, "manejar las impresoras"); err != nil {
+		return nil, err
+	}
+	estado = strings.ToUpper(strings.TrimSpace(estado))
+	if estado != "" && !contiene(EstadosImpresion, estado) {
+		return nil, newError(http.StatusBadRequest, "Estado de impresión inválido",
+			fmt.Errorf("'%s' no es un estado válido; use %s", estado, strings.Join(EstadosImpresion, ", ")))
+	}
+	trabajos, err := s.store.TrabajosImpresion().List(estado)
+	if err != nil {
+		return nil, internalError("Error al obtener la cola de impresión", err)
+	}
+	return trabajos, nil
+}
+
+// Imprimir pone en la cola el recibo del pedido o sus comandas y devuelve los trabajos creados,
+// que se envían en segundo plano. El recibo sale por la impresora indicada o por la primera
+// impresora activa sin estación (la de caja); cada comanda sale por la impresora activa de la
+// estación de su ticket. Si no hay una impresora que pueda recibir el trabajo responde 422.
+func (s *ImpresionService) Imprimir(req models.TrabajoImpresionRequest, actor Actor) ([]models.TrabajoImpresion, error) {
+	if err := requierePersonal(actor, "manejar las impresoras"); err != nil {
+		return nil, err
+	}
+	req.TIPO = strings.ToUpper(strings.TrimSpace(req.TIPO))
+	if req.TIPO != TrabajoRecibo && req.TIPO != TrabajoComanda {
+		return nil, newError(http.StatusBadRequest, "Tipo de impresión inválido",
+			fmt.Errorf("'%s' no es un tipo válido; use %s o %s", req.TIPO, TrabajoRecibo, TrabajoComanda))
+	}
+	if req.PK_ID_PEDIDO <= 0 {
+		return nil, badRequest("PK_ID_PEDIDO es obligatorio")
+	}
+
+	trabajos := []models.TrabajoImpresion{}
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		if _, err := tx.Pedidos().Get(req.PK_ID_PEDIDO); err != nil {
+			return lookup(err, notFound("Pedido no encontrado"))
+		}
+		impresoras, err := tx.Impresoras().List()
+		if err != nil {
+			return internalError("Error al obtener las impresoras", err)
+		}
+
+		now := time.Now().In(database.BogotaZone)
+		nuevo := func(impresora *models.Impresora, ticketID *int64) models.TrabajoImpresion {
+			return models.TrabajoImpresion{
+				PK_ID_IMPRESORA: impresora.PK_ID_IMPRESORA,
+				PK_ID_PEDIDO:    req.PK_ID_PEDIDO,
+				PK_ID_TICKET:    ticketID,
+				TIPO:            req.TIPO,
+				ESTADO:          ImpresionPendiente,
+				CREADO:          now,
+			}
+		}
+
+		if req.TIPO == TrabajoRecibo {
+			impresora, err := impresoraDestino(impresoras, req.PK_ID_IMPRESORA, nil)
+			if err != nil {
+				return err
+			}
+			trabajos = append(trabajos, nuevo(impresora, nil))
+		} else {
+			tickets, err := tx.TicketsCocina().ListByPedido(req.PK_ID_PEDIDO)
+			if err != nil {
+				return internalError("Error al consultar los tickets de cocina del pedido", err)
+			}
+			for _, ticket := range tickets {
+				if len(ticket.ITEMS) == 0 || (req.PK_ID_TICKET != nil && ticket.PK_ID_TICKET != *req.PK_ID_TICKET) {
+					continue
+				}
+				impresora, err := impresoraDestino(impresoras, req.PK_ID_IMPRESORA, &ticket.ESTACION)
+				if err != nil {
+					return err
+				}
+				trabajos = append(trabajos, nuevo(impresora, &ticket.PK_ID_TICKET))
+			}
+			if len(trabajos) == 0 {
+				if req.PK_ID_TICKET != nil {
+					return unprocessable(fmt.Sprintf("El pedido %d no tiene el ticket de cocina %d", req.PK_ID_PEDIDO, *req.PK_ID_TICKET))
+				}
+				return unprocessable("El pedido no tiene comandas; se generan cuando entra a EN PREPARACION")
+			}
+		}
+
+		for i := range trabajos {
+			if err := tx.TrabajosImpresion().Insert(&trabajos[i]); err != nil {
+				return internalError("Error al encolar el trabajo de impresión", err)
+			}
+		}
+		tx.AfterCommit(func() {
+			for _, trabajo := range trabajos {
+				s.encolar(trabajo)
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trabajos, nil
+}
+
+// Reintentar vuelve a encolar un trabajo pendiente o fallido; uno ya impreso responde 409
+func (s *ImpresionService) Reintentar(id int64, actor Actor) (*models.TrabajoImpresion, error) {
+	if err := requierePersonal(actor, "manejar las impresoras"); err != nil {
+		return nil, err
+	}
+	var trabajo *models.TrabajoImpresion
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if trabajo, err = tx.TrabajosImpresion().Get(id); err != nil {
+			return lookup(err, notFound("Trabajo de impresión no encontrado"))
+		}
+		if trabajo.ESTADO == ImpresionImpreso {
+			return &Error{Code: http.StatusConflict, Message: "El trabajo ya se imprimió", Data: trabajo}
+		}
+		trabajo.ESTADO, trabajo.ERROR = ImpresionPendiente, nil
+		if err := tx.TrabajosImpresion().Update(trabajo); err != nil {
+			return internalError("Error al actualizar el trabajo de impresión", err)
+		}
+		tx.AfterCommit(func() { s.encolar(*trabajo) })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trabajo, nil
+}
+
+// ReanudarPendientes vuelve a encolar los trabajos PENDIENTE, que la cola en memoria pierde si el
+// servidor se reinicia antes de enviarlos. Devuelve cuántos encoló.
+func (s *ImpresionService) ReanudarPendientes() (int, error) {
+	trabajos, err := s.store.TrabajosImpresion().List(ImpresionPendiente)
+	if err != nil {
+		return 0, internalError("Error al obtener los trabajos de impresión pendientes", err)
+	}
+	for _, trabajo := range trabajos {
+		s.encolar(trabajo)
+	}
+	return len(trabajos), nil
+}
+
+// Procesar arma el documento del trabajo, lo envía a la impresora con reintentos y guarda el
+// resultado. La conexión con la impresora se hace fuera de la transacción para no bloquear la
+// base de datos mientras se reintenta.
+func (s *ImpresionService) Procesar(id int64) error {
+	var trabajo *models.TrabajoImpresion
+	var impresora *models.Impresora
+	var datos []byte
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if trabajo, err = tx.TrabajosImpresion().Get(id); err != nil {
+			return lookup(err, notFound("Trabajo de impresión no encontrado"))
+		}
+		if trabajo.ESTADO != ImpresionPendiente {
+			return nil
+		}
+		if impresora, err = tx.Impresoras().Get(trabajo.PK_ID_IMPRESORA); err != nil {
+			return lookup(err, notFound("Impresora no encontrada"))
+		}
+		documento, err := documentoTrabajo(tx, trabajo, impresora)
+		if err != nil {
+			return err
+		}
+		datos = impresion.EscPos(documento)
+		return nil
+	})
+	if err != nil || trabajo.ESTADO != ImpresionPendiente {
+		return err
+	}
+
+	envio := impresion.Impresora{
+		Direccion: net.JoinHostPort(impresora.HOST, strconv.Itoa(impresora.PUERTO)),
+		Intentos:  intentosImpresion(),
+		Espera:    esperaImpresion(),
+	}
+	intentos, envioErr := envio.Enviar(datos)
+
+	return s.store.Transaction(func(tx repositories.Store) error {
+		trabajo.INTENTOS += intentos
+		if envioErr != nil {
+			mensaje := envioErr.Error()
+			trabajo.ESTADO, trabajo.ERROR = ImpresionFallido, &mensaje
+		} else {
+			now := time.Now().In(database.BogotaZone)
+			trabajo.ESTADO, trabajo.ERROR, trabajo.IMPRESO_EN = ImpresionImpreso, nil, &now
+		}
+		if err := tx.TrabajosImpresion().Update(trabajo); err != nil {
+			return internalError("Error al registrar el resultado de la impresión", err)
+		}
+		return nil
+	})
+}
+
+// encolar envía el trabajo en segundo plano; el resultado queda registrado en el trabajo
+func (s *ImpresionService) encolar(trabajo models.TrabajoImpresion) {
+	Impresiones.Encolar(trabajo.PK_ID_IMPRESORA, func() {
+		_ = s.Procesar(trabajo.PK_ID_TRABAJO)
+	})
+}
+
+// documentoTrabajo arma el recibo o la comanda del trabajo para el papel de la impresora. El
+// recibo abre el cajón si la impresora lo tiene conectado.
+func documentoTrabajo(tx repositories.Store, trabajo *models.TrabajoImpresion, impresora *models.Impresora) (*impresion.Documento, error) {
+	if trabajo.TIPO == TrabajoComanda && trabajo.PK_ID_TICKET != nil {
+		return comanda(tx, *trabajo.PK_ID_TICKET, impresora.ANCHO)
+	}
+	documento, err := NewPedidoService(tx).Recibo(trabajo.PK_ID_PEDIDO, impresora.ANCHO, Actor{})
+	if err != nil {
+		return nil, err
+	}
+	documento.AbrirCajon = impresora.CAJON
+	return documento, nil
+}
+
+// impresoraDestino elige la impresora de un trabajo: la indicada en la solicitud, que debe estar
+// activa, o la primera activa de la estación (sin estación para los recibos)
+func impresoraDestino(impresoras []models.Impresora, id *int64, estacion *string) (*models.Impresora, error) {
+	if id != nil {
+		for i := range impresoras {
+			if impresoras[i].PK_ID_IMPRESORA == *id {
+				if !impresoras[i].ACTIVA {
+					return nil, unprocessable(fmt.Sprintf("La impresora %s no está activa", impresoras[i].NOMBRE))
+				}
+				return &impresoras[i], nil
+			}
+		}
+		return nil, unprocessable("La impresora indicada no existe")
+	}
+	for i := range impresoras {
+		impresora := &impresoras[i]
+		if !impresora.ACTIVA {
+			continue
+		}
+		if estacion == nil && impresora.ESTACION == nil {
+			return impresora, nil
+		}
+		if estacion != nil && impresora.ESTACION != nil && *impresora.ESTACION == *estacion {
+			return impresora, nil
+		}
+	}
+	if estacion != nil {
+		return nil, unprocessable(fmt.Sprintf("No hay una impresora activa para la estación %s", *estacion))
+	}
+	return nil, unprocessable("No hay una impresora de caja activa")
+}
+
+func validateImpresora(impresora *models.Impresora) error {
+	impresora.NOMBRE = strings.TrimSpace(impresora.NOMBRE)
+	impresora.HOST = strings.TrimSpace(impresora.HOST)
+	if impresora.NOMBRE == "" {
+		return badRequest("El nombre de la impresora es obligatorio")
+	}
+	if impresora.HOST == "" {
+		return badRequest("El HOST de la impresora es obligatorio")
+	}
+	if impresora.PUERTO == 0 {
+		impresora.PUERTO = impresion.PuertoRaw
+	}
+	if impresora.PUERTO < 1 || impresora.PUERTO > 65535 {
+		return badRequest("El PUERTO debe estar entre 1 y 65535")
+	}
+	if impresora.ANCHO == 0 {
+		impresora.ANCHO = impresion.Ancho80
+	}
+	if !impresion.AnchoValido(impresora.ANCHO) {
+		return badRequest("El ANCHO del papel debe ser 58 u 80 mm")
+	}
+	if impresora.ESTACION != nil {
+		estacion := strings.ToUpper(strings.TrimSpace(*impresora.ESTACION))
+		impresora.ESTACION = &estacion
+		if estacion == "" {
+			impresora.ESTACION = nil
+		}
+	}
+	return nil
+}
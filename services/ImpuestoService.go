@@ -0,0 +1,111 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/repositories"
+	"sort"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// Categorías tributarias que siembra la migración 0008
+const (
+	CategoriaImpoconsumo = "IMPOCONSUMO"
+	CategoriaIVA         = "IVA"
+	CategoriaExento      = "EXENTO"
+)
+
+// ImpuestoService administra las tarifas de impuesto por categoría de producto
+type ImpuestoService struct {
+	store repositories.Store
+}
+
+func NewImpuestoService(store repositories.Store) *ImpuestoService {
+	return &ImpuestoService{store: store}
+}
+
+// List devuelve las reglas de impuesto vigentes
+func (s *ImpuestoService) List() ([]models.ReglaImpuesto, error) {
+	reglas, err := s.store.ReglasImpuesto().List()
+	if err != nil {
+		return nil, internalError("Error al obtener las reglas de impuesto", err)
+	}
+	return reglas, nil
+}
+
+// Save crea o actualiza la tarifa de una categoría. Los pedidos ya registrados conservan la tarifa
+// con la que se cobraron; solo los administradores pueden cambiarla.
+func (s *ImpuestoService) Save(regla *models.ReglaImpuesto, actor Actor) error {
+	if !actor.esAdministrador() {
+		return newError(http.StatusForbidden, "Solo un administrador puede cambiar los impuestos", nil)
+	}
+	regla.CATEGORIA = strings.ToUpper(strings.TrimSpace(regla.CATEGORIA))
+	regla.NOMBRE = strings.TrimSpace(regla.NOMBRE)
+	if regla.CATEGORIA == "" || regla.NOMBRE == "" {
+		return badRequest("Los campos CATEGORIA y NOMBRE son obligatorios")
+	}
+	if regla.TARIFA < 0 || regla.TARIFA > 100 {
+		return badRequest("La TARIFA debe ser un porcentaje entre 0 y 100")
+	}
+
+	if err := s.store.ReglasImpuesto().Save(regla); err != nil {
+		return internalError("Error al guardar la regla de impuesto", err)
+	}
+	return nil
+}
+
+// categoriaProducto normaliza la categoría tributaria de un producto: vacía es EXENTO, como el
+// valor por defecto de la columna
+func categoriaProducto(producto *models.Producto) string {
+	categoria := strings.ToUpper(strings.TrimSpace(producto.CATEGORIA_IMPUESTO))
+	if categoria == "" {
+		return CategoriaExento
+	}
+	return categoria
+}
+
+// reglaImpuesto busca la regla de la categoría; una categoría desconocida responde 422
+func reglaImpuesto(tx repositories.Store, categoria string) (*models.ReglaImpuesto, error) {
+	regla, err := tx.ReglasImpuesto().Get(categoria)
+	if err != nil {
+		return nil, lookup(err, unprocessable(fmt.Sprintf("La categoría de impuesto %s no existe", categoria)))
+	}
+	return regla, nil
+}
+
+// desgloseImpuestos agrupa el impuesto de las líneas por categoría y tarifa; la base es el valor de
+// cada línea después de su parte del descuento y sin el impuesto que incluye
+func desgloseImpuestos(lineas []models.LineaProducto) []models.DesgloseImpuesto {
+	desglose := []models.DesgloseImpuesto{}
+	for _, linea := range lineas {
+		desglose = sumarImpuesto(desglose, models.DesgloseImpuesto{
+			CATEGORIA: linea.CATEGORIA_IMPUESTO,
+			TARIFA:    linea.TARIFA_IMPUESTO,
+			BASE:      linea.SUBTOTAL - linea.DESCUENTO - linea.IMPUESTO,
+			VALOR:     linea.IMPUESTO,
+		})
+	}
+	return desglose
+}
+
+// sumarImpuesto acumula item en la entrada de su misma categoría y tarifa
+func sumarImpuesto(desglose []models.DesgloseImpuesto, item models.DesgloseImpuesto) []models.DesgloseImpuesto {
+	for i := range desglose {
+		if desglose[i].CATEGORIA == item.CATEGORIA && desglose[i].TARIFA == item.TARIFA {
+			desglose[i].BASE += item.BASE
+			desglose[i].VALOR += item.VALOR
+			return desglose
+		}
+	}
+	desglose = append(desglose, item)
+	sort.SliceStable(desglose, func(i, j int) bool { return desglose[i].CATEGORIA < desglose[j].CATEGORIA })
+	return desglose
+}
+
+// porcentajePropina es la propina sugerida para los pedidos en el restaurante (clave propina_sugerida)
+func porcentajePropina() int {
+	return web.AppConfig.DefaultInt("propina_sugerida", 10)
+}
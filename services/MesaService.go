@@ -0,0 +1,339 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strings"
+)
+
+// Estados de una mesa del salón
+const (
+	MesaLibre      = "LIBRE"
+	MesaOcupada    = "OCUPADA"
+	MesaPorLimpiar = "POR LIMPIAR"
+)
+
+// transicionesMesa indica a qué estados puede pasar una mesa desde cada estado. Una mesa con
+// rondas abiertas no puede dejar de estar OCUPADA.
+var transicionesMesa = map[string][]string{
+	MesaLibre:      {MesaOcupada, MesaPorLimpiar},
+	MesaOcupada:    {MesaPorLimpiar, MesaLibre},
+	MesaPorLimpiar: {MesaLibre},
+}
+
+// MesaService administra las mesas del salón y los pedidos que se toman en ellas
+type MesaService struct {
+	store repositories.Store
+}
+
+func NewMesaService(store repositories.Store) *MesaService {
+	return &MesaService{store: store}
+}
+
+// List devuelve las mesas ordenadas por zona y número; con zona solo las de esa zona
+func (s *MesaService) List(zona string) ([]models.Mesa, error) {
+	mesas, err := s.store.Mesas().List()
+	if err != nil {
+		return nil, internalError("Error al obtener las mesas", err)
+	}
+	zona = strings.TrimSpace(zona)
+	if zona == "" {
+		return mesas, nil
+	}
+
+	filtradas := []models.Mesa{}
+	for _, mesa := range mesas {
+		if strings.EqualFold(mesa.ZONA, zona) {
+			filtradas = append(filtradas, mesa)
+		}
+	}
+	return filtradas, nil
+}
+
+// GetByID busca una mesa con sus rondas abiertas
+func (s *MesaService) GetByID(id int64) (*models.Mesa, error) {
+	mesa, err := s.store.Mesas().Get(id)
+	if err != nil {
+		return nil, lookup(err, notFound("Mesa no encontrada"))
+	}
+	if err := cargarRondas(s.store, mesa); err != nil {
+		return nil, err
+	}
+	return mesa, nil
+}
+
+// Create registra una mesa LIBRE. Los clientes no pueden crearlas y un número repetido responde 409.
+func (s *MesaService) Create(mesa *models.Mesa, actor Actor) error {
+	if err := requierePersonal(actor, "administrar las mesas"); err != nil {
+		return err
+	}
+	if err := validateMesa(mesa); err != nil {
+		return err
+	}
+	mesa.ESTADO = MesaLibre
+
+	return s.store.Transaction(func(tx repositories.Store) error {
+		if err := numeroDisponible(tx, mesa); err != nil {
+			return err
+		}
+		if err := tx.Mesas().Insert(mesa); err != nil {
+			return internalError("Error al crear la mesa", err)
+		}
+		return nil
+	})
+}
+
+// Update cambia el número, la capacidad y la zona de una mesa; el estado se cambia con UpdateEstado
+func (s *MesaService) Update(mesa *models.Mesa, actor Actor) error {
+	if err := requierePersonal(actor, "administrar las mesas"); err != nil {
+		return err
+	}
+	if err := validateMesa(mesa); err != nil {
+		return err
+	}
+
+	return s.store.Transaction(func(tx repositories.Store) error {
+		actual, err := tx.Mesas().Get(mesa.PK_ID_MESA)
+		if err != nil {
+			return lookup(err, notFound("Mesa no encontrada"))
+		}
+		if err := numeroDisponible(tx, mesa); err != nil {
+			return err
+		}
+		mesa.ESTADO = actual.ESTADO
+		if err := tx.Mesas().Update(mesa, "NUMERO", "CAPACIDAD", "ZONA"); err != nil {
+			return internalError("Error al actualizar la mesa", err)
+		}
+		return nil
+	})
+}
+
+// UpdateEstado marca la mesa como LIBRE, OCUPADA o POR LIMPIAR. Una mesa con rondas abiertas sigue
+// OCUPADA hasta que se entregan o cancelan; un estado desconocido responde 400 y un cambio no
+// permitido 409.
+func (s *MesaService) UpdateEstado(id int64, estado string, actor Actor) (*models.Mesa, error) {
+	if err := requierePersonal(actor, "cambiar el estado de las mesas"); err != nil {
+		return nil, err
+	}
+	estado = strings.ToUpper(strings.TrimSpace(estado))
+	if _, ok := transicionesMesa[estado]; !ok {
+		return nil, newError(http.StatusBadRequest, "Estado de mesa inválido",
+			fmt.Errorf("'%s' no es un estado válido; use %s, %s o %s", estado, MesaLibre, MesaOcupada, MesaPorLimpiar))
+	}
+
+	var mesa *models.Mesa
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if mesa, err = tx.Mesas().Get(id); err != nil {
+			return lookup(err, notFound("Mesa no encontrada"))
+		}
+		if err := cargarRondas(tx, mesa); err != nil {
+			return err
+		}
+		if mesa.ESTADO == estado {
+			return nil
+		}
+		if !contiene(transicionesMesa[mesa.ESTADO], estado) {
+			return &Error{Code: http.StatusConflict, Message: fmt.Sprintf("No se puede pasar una mesa de %s a %s", mesa.ESTADO, estado), Data: mesa}
+		}
+		if len(mesa.PEDIDOS) > 0 {
+			return &Error{Code: http.StatusConflict, Message: "La mesa tiene pedidos abiertos; entréguelos o cancélelos antes de liberarla", Data: mesa}
+		}
+		return cambiarEstadoMesa(tx, mesa, estado)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mesa, nil
+}
+
+// AbrirRonda crea un pedido en la mesa a nombre del mesero que lo toma y la marca OCUPADA. Una
+// mesa puede tener varias rondas abiertas; una mesa POR LIMPIAR responde 409.
+func (s *MesaService) AbrirRonda(mesaID int64, pedido *models.Pedido, actor Actor) error {
+	pedido.PK_ID_MESA = &mesaID
+	return NewPedidoService(s.store).Create(pedido, actor)
+}
+
+// Trasladar pasa las rondas abiertas de la mesa origen a destino y deja origen POR LIMPIAR. Para
+// mover los clientes destino debe estar LIBRE; para unir las mesas debe estar OCUPADA.
+func (s *MesaService) Trasladar(origenID, destinoID int64, unir bool, actor Actor) (*models.Mesa, error) {
+	if err := requierePersonal(actor, "mover las mesas"); err != nil {
+		return nil, err
+	}
+	if destinoID <= 0 {
+		return nil, badRequest("Debe indicar la mesa de destino en PK_ID_MESA_DESTINO")
+	}
+	if destinoID == origenID {
+		return nil, badRequest("La mesa de destino debe ser distinta a la de origen")
+	}
+
+	var destino *models.Mesa
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		origen, err := tx.Mesas().Get(origenID)
+		if err != nil {
+			return lookup(err, notFound("Mesa no encontrada"))
+		}
+		if destino, err = tx.Mesas().Get(destinoID); err != nil {
+			return lookup(err, unprocessable("La mesa de destino no existe"))
+		}
+		if err := cargarRondas(tx, origen); err != nil {
+			return err
+		}
+		if len(origen.PEDIDOS) == 0 {
+			return &Error{Code: http.StatusConflict, Message: "La mesa no tiene pedidos abiertos para trasladar", Data: origen}
+		}
+		switch {
+		case unir && destino.ESTADO != MesaOcupada:
+			return &Error{Code: http.StatusConflict, Message: fmt.Sprintf("Solo se puede unir con una mesa OCUPADA; la mesa %d está %s", destino.NUMERO, destino.ESTADO), Data: destino}
+		case !unir && destino.ESTADO != MesaLibre:
+			return &Error{Code: http.StatusConflict, Message: fmt.Sprintf("Solo se puede mover a una mesa LIBRE; la mesa %d está %s", destino.NUMERO, destino.ESTADO), Data: destino}
+		}
+
+		for i := range origen.PEDIDOS {
+			pedido := &origen.PEDIDOS[i]
+			pedido.PK_ID_MESA = &destinoID
+			if err := NewPedidoService(tx).save(pedido, pedido.VERSION, "PK_ID_MESA"); err != nil {
+				return err
+			}
+		}
+		if err := cambiarEstadoMesa(tx, origen, MesaPorLimpiar); err != nil {
+			return err
+		}
+		if err := cambiarEstadoMesa(tx, destino, MesaOcupada); err != nil {
+			return err
+		}
+		return cargarRondas(tx, destino)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return destino, nil
+}
+
+// Plano devuelve el estado del salón: cuántas mesas hay en cada estado y las mesas de cada zona
+// con sus rondas abiertas y el total pendiente
+func (s *MesaService) Plano() (*models.PlanoMesas, error) {
+	mesas, err := s.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	plano := models.PlanoMesas{ZONAS: []models.ZonaMesas{}}
+	for i := range mesas {
+		mesa := mesas[i]
+		if err := cargarRondas(s.store, &mesa); err != nil {
+			return nil, err
+		}
+		switch mesa.ESTADO {
+		case MesaLibre:
+			plano.LIBRES++
+		case MesaOcupada:
+			plano.OCUPADAS++
+		case MesaPorLimpiar:
+			plano.POR_LIMPIAR++
+		}
+
+		if n := len(plano.ZONAS); n == 0 || plano.ZONAS[n-1].ZONA != mesa.ZONA {
+			plano.ZONAS = append(plano.ZONAS, models.ZonaMesas{ZONA: mesa.ZONA, MESAS: []models.Mesa{}})
+		}
+		zona := &plano.ZONAS[len(plano.ZONAS)-1]
+		zona.MESAS = append(zona.MESAS, mesa)
+	}
+	return &plano, nil
+}
+
+// ocuparMesa valida la mesa de un pedido del salón, le asigna el mesero y marca la mesa OCUPADA.
+// El mesero es el trabajador indicado o, si no se indica, el que toma el pedido.
+func ocuparMesa(tx repositories.Store, pedido *models.Pedido, actor Actor) error {
+	if esDomicilio(pedido) {
+		return &Error{Code: http.StatusConflict, Message: "Los pedidos a domicilio no se asignan a una mesa", Data: pedido}
+	}
+	mesa, err := tx.Mesas().Get(*pedido.PK_ID_MESA)
+	if err != nil {
+		return lookup(err, unprocessable("La mesa indicada no existe"))
+	}
+	if mesa.ESTADO == MesaPorLimpiar {
+		return &Error{Code: http.StatusConflict, Message: fmt.Sprintf("La mesa %d está POR LIMPIAR", mesa.NUMERO), Data: mesa}
+	}
+
+	if pedido.PK_DOCUMENTO_MESERO == nil && actor.Rol != RolCliente {
+		pedido.PK_DOCUMENTO_MESERO = actor.documento()
+	}
+	if pedido.PK_DOCUMENTO_MESERO != nil {
+		if _, err := tx.Trabajadores().Get(*pedido.PK_DOCUMENTO_MESERO); err != nil {
+			return lookup(err, unprocessable("El mesero indicado no existe"))
+		}
+	}
+	if mesa.ESTADO != MesaOcupada {
+		return cambiarEstadoMesa(tx, mesa, MesaOcupada)
+	}
+	return nil
+}
+
+// cargarRondas llena PEDIDOS con los pedidos abiertos de la mesa y TOTAL con la suma de sus totales
+func cargarRondas(store repositories.Store, mesa *models.Mesa) error {
+	pedidos, err := store.Pedidos().Search(PedidoFiltros{Mesa: mesa.PK_ID_MESA})
+	if err != nil {
+		return internalError("Error al consultar los pedidos de la mesa", err)
+	}
+
+	mesa.PEDIDOS, mesa.TOTAL = []models.Pedido{}, 0
+	vistos := map[int]bool{}
+	for _, pedido := range pedidos {
+		// La búsqueda puede repetir un pedido asociado a varios clientes
+		if pedidoAbierto(&pedido) != nil || vistos[pedido.PK_ID_PEDIDO] {
+			continue
+		}
+		vistos[pedido.PK_ID_PEDIDO] = true
+		mesa.PEDIDOS = append(mesa.PEDIDOS, pedido)
+		mesa.TOTAL += pedido.TOTAL
+	}
+	return nil
+}
+
+// cambiarEstadoMesa guarda el nuevo estado de la mesa
+func cambiarEstadoMesa(tx repositories.Store, mesa *models.Mesa, estado string) error {
+	mesa.ESTADO = estado
+	if err := tx.Mesas().Update(mesa, "ESTADO"); err != nil {
+		return lookup(err, notFound("Mesa no encontrada"))
+	}
+	return nil
+}
+
+// validateMesa normaliza la zona y revisa el número y la capacidad
+func validateMesa(mesa *models.Mesa) error {
+	mesa.ZONA = strings.ToUpper(strings.TrimSpace(mesa.ZONA))
+	if mesa.NUMERO <= 0 || mesa.CAPACIDAD <= 0 {
+		return badRequest("NUMERO y CAPACIDAD deben ser mayores a cero")
+	}
+	if mesa.ZONA == "" {
+		return badRequest("El campo ZONA es obligatorio")
+	}
+	return nil
+}
+
+// numeroDisponible responde 409 si otra mesa ya tiene el número
+func numeroDisponible(tx repositories.Store, mesa *models.Mesa) error {
+	mesas, err := tx.Mesas().List()
+	if err != nil {
+		return internalError("Error al obtener las mesas", err)
+	}
+	for _, otra := range mesas {
+		if otra.NUMERO == mesa.NUMERO && otra.PK_ID_MESA != mesa.PK_ID_MESA {
+			return newError(http.StatusConflict, fmt.Sprintf("Ya existe la mesa número %d", mesa.NUMERO), nil)
+		}
+	}
+	return nil
+}
+
+// contiene indica si valor está en valores
+func contiene(valores []string, valor string) bool {
+	for _, v := range valores {
+		if v == valor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"fmt"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strings"
+)
+
+// ModificadorService administra los grupos de modificadores de los productos y valida las
+// opciones elegidas al pedir
+type ModificadorService struct {
+	store repositories.Store
+}
+
+func NewModificadorService(store repositories.Store) *ModificadorService {
+	return &ModificadorService{store: store}
+}
+
+// List devuelve los grupos de modificadores de un producto existente con sus opciones
+func (s *ModificadorService) List(productoID int64) ([]models.GrupoModificador, error) {
+	if _, err := s.store.Productos().Get(productoID); err != nil {
+		return nil, lookup(err, notFound("Producto no encontrado"))
+	}
+
+	grupos, err := s.store.Modificadores().ListByProducto(productoID)
+	if err != nil {
+		return nil, internalError("Error al obtener los modificadores del producto", err)
+	}
+	return grupos, nil
+}
+
+// Create registra un grupo con sus opciones. Un grupo obligatorio exige al menos una opción y
+// MAX_SELECCION en 0 no limita cuántas se eligen.
+func (s *ModificadorService) Create(grupo *models.GrupoModificador) error {
+	if err := validateGrupo(grupo); err != nil {
+		return err
+	}
+
+	return s.store.Transaction(func(tx repositories.Store) error {
+		producto, err := tx.Productos().Get(grupo.PK_ID_PRODUCTO)
+		if err != nil {
+			return lookup(err, unprocessable("El producto indicado no existe"))
+		}
+		for _, opcion := range grupo.OPCIONES {
+			if producto.PRECIO+opcion.PRECIO_ADICIONAL < 0 {
+				return badRequest(fmt.Sprintf("La opción %s deja el precio de %s por debajo de cero", opcion.NOMBRE, producto.NOMBRE))
+			}
+		}
+
+		if err := tx.Modificadores().Insert(grupo); err != nil {
+			return internalError("Error al crear el grupo de modificadores", err)
+		}
+		return nil
+	})
+}
+
+// Delete borra un grupo y sus opciones; los pedidos ya hechos conservan las opciones elegidas
+func (s *ModificadorService) Delete(id int64) error {
+	err := s.store.Modificadores().Delete(id)
+	if err != nil {
+		return lookup(err, notFound("Grupo de modificadores no encontrado"))
+	}
+	return nil
+}
+
+// validateGrupo revisa el grupo y deja consistentes OBLIGATORIO y MIN_SELECCION
+func validateGrupo(grupo *models.GrupoModificador) error {
+	grupo.NOMBRE = strings.TrimSpace(grupo.NOMBRE)
+	if grupo.PK_ID_PRODUCTO <= 0 || grupo.NOMBRE == "" {
+		return badRequest("Los campos PK_ID_PRODUCTO y NOMBRE son obligatorios")
+	}
+	if len(grupo.OPCIONES) == 0 {
+		return badRequest("El grupo debe tener al menos una opción")
+	}
+	for i := range grupo.OPCIONES {
+		grupo.OPCIONES[i].NOMBRE = strings.TrimSpace(grupo.OPCIONES[i].NOMBRE)
+		if grupo.OPCIONES[i].NOMBRE == "" {
+			return badRequest(fmt.Sprintf("La opción %d debe tener NOMBRE", i+1))
+		}
+	}
+
+	if grupo.MIN_SELECCION < 0 || grupo.MAX_SELECCION < 0 {
+		return badRequest("MIN_SELECCION y MAX_SELECCION no pueden ser negativos")
+	}
+	if grupo.OBLIGATORIO && grupo.MIN_SELECCION == 0 {
+		grupo.MIN_SELECCION = 1
+	}
+	grupo.OBLIGATORIO = grupo.MIN_SELECCION > 0
+	if grupo.MAX_SELECCION > 0 && grupo.MIN_SELECCION > grupo.MAX_SELECCION {
+		return badRequest("MIN_SELECCION no puede ser mayor que MAX_SELECCION")
+	}
+	if grupo.MIN_SELECCION > len(grupo.OPCIONES) {
+		return badRequest("MIN_SELECCION no puede ser mayor que el número de opciones")
+	}
+	return nil
+}
+
+// elegirModificadores valida las opciones elegidas contra los grupos del producto: que le
+// pertenezcan, que no se repitan y que cada grupo quede entre su mínimo y su máximo. Devuelve las
+// opciones con el nombre de su grupo y lo que suman al precio unitario.
+func elegirModificadores(tx repositories.Store, producto *models.Producto, elegidas []int64) ([]models.LineaModificador, int64, error) {
+	grupos, err := tx.Modificadores().ListByProducto(producto.PK_ID_PRODUCTO)
+	if err != nil {
+		return nil, 0, internalError("Error al obtener los modificadores del producto", err)
+	}
+
+	grupoDe := map[int64]*models.GrupoModificador{}
+	opciones := map[int64]models.OpcionModificador{}
+	for i := range grupos {
+		for _, opcion := range grupos[i].OPCIONES {
+			grupoDe[opcion.PK_ID_OPCION_MODIFICADOR] = &grupos[i]
+			opciones[opcion.PK_ID_OPCION_MODIFICADOR] = opcion
+		}
+	}
+
+	var (
+		modificadores []models.LineaModificador
+		adicional     int64
+	)
+	elegidasPorGrupo := map[int64]int{}
+	for _, id := range elegidas {
+		grupo, ok := grupoDe[id]
+		if !ok {
+			return nil, 0, unprocessable(fmt.Sprintf("La opción %d no es un modificador de %s", id, producto.NOMBRE))
+		}
+		opcion := opciones[id]
+		for _, m := range modificadores {
+			if m.PK_ID_OPCION_MODIFICADOR == id {
+				return nil, 0, badRequest(fmt.Sprintf("La opción %s se eligió más de una vez", opcion.NOMBRE))
+			}
+		}
+
+		elegidasPorGrupo[grupo.PK_ID_GRUPO_MODIFICADOR]++
+		adicional += opcion.PRECIO_ADICIONAL
+		modificadores = append(modificadores, models.LineaModificador{
+			PK_ID_OPCION_MODIFICADOR: id,
+			GRUPO:                    grupo.NOMBRE,
+			NOMBRE:                   opcion.NOMBRE,
+			PRECIO_ADICIONAL:         opcion.PRECIO_ADICIONAL,
+		})
+	}
+
+	for _, grupo := range grupos {
+		n := elegidasPorGrupo[grupo.PK_ID_GRUPO_MODIFICADOR]
+		if n < grupo.MIN_SELECCION {
+			return nil, 0, unprocessable(fmt.Sprintf("%s: elija al menos %d opción(es) de %s", producto.NOMBRE, grupo.MIN_SELECCION, grupo.NOMBRE))
+		}
+		if grupo.MAX_SELECCION > 0 && n > grupo.MAX_SELECCION {
+			return nil, 0, unprocessable(fmt.Sprintf("%s: elija como máximo %d opción(es) de %s", producto.NOMBRE, grupo.MAX_SELECCION, grupo.NOMBRE))
+		}
+	}
+	return modificadores, adicional, nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"restaurante/models"
+	"restaurante/repositories"
+	"time"
+)
+
+// NominaTrabajadorService concentra la lógica de nóminas por trabajador compartida por v1 y v2
+type NominaTrabajadorService struct {
+	store repositories.Store
+}
+
+func NewNominaTrabajadorService(store repositories.Store) *NominaTrabajadorService {
+	return &NominaTrabajadorService{store: store}
+}
+
+// NominaFiltros agrupa los criterios opcionales para consultar las nóminas de un trabajador
+type NominaFiltros = repositories.NominaFiltros
+
+// ListByTrabajador devuelve las relaciones nómina-trabajador de un trabajador según los filtros
+func (s *NominaTrabajadorService) ListByTrabajador(documento int64, filtros NominaFiltros) ([]models.NominaTrabajador, error) {
+	if documento == 0 {
+		return nil, badRequest("El parámetro 'documento' es obligatorio.")
+	}
+
+	relaciones, err := s.store.NominasTrabajador().ListByTrabajador(documento, filtros)
+	if err != nil {
+		return nil, internalError("Error al buscar las relaciones nómina-trabajador.", err)
+	}
+	return relaciones, nil
+}
+
+// Create registra la nómina del trabajador para el periodo actual (del 20 del mes anterior al 20
+// del mes en curso), sumando o restando sus incidencias al sueldo base
+func (s *NominaTrabajadorService) Create(documento int64) (*models.NominaTrabajadorResponse, error) {
+	if documento == 0 {
+		return nil, badRequest("El campo PK_DOCUMENTO_TRABAJADOR es obligatorio y debe ser válido")
+	}
+
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month()-1, 20, 0, 0, 0, 0, now.Location())
+	endDate := time.Date(now.Year(), now.Month(), 20, 23, 59, 59, 999, now.Location())
+
+	var response models.NominaTrabajadorResponse
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		trabajador, err := tx.Trabajadores().Get(documento)
+		if err != nil {
+			return lookup(err, unprocessable("El trabajador indicado no existe"))
+		}
+
+		incidencias, err := tx.Incidencias().ListByTrabajador(documento, startDate, endDate)
+		if err != nil {
+			return internalError("Error al consultar incidencias del trabajador", err)
+		}
+
+		var montoIncidencias int64
+		for _, incidencia := range incidencias {
+			if incidencia.RESTA {
+				montoIncidencias -= incidencia.MONTO
+			} else {
+				montoIncidencias += incidencia.MONTO
+			}
+		}
+		total := trabajador.SUELDO + montoIncidencias
+		descripcion := fmt.Sprintf("Nómina del mes de %s más incidencias si aplica", MesEnEspanol(now.Month()))
+
+		nominaTrabajador := models.NominaTrabajador{
+			PK_DOCUMENTO_TRABAJADOR: documento,
+			SUELDO_BASE:             trabajador.SUELDO,
+			MONTO_INCIDENCIAS:       &montoIncidencias,
+			TOTAL:                   &total,
+			DETALLES:                &descripcion,
+		}
+		if err := tx.NominasTrabajador().Insert(&nominaTrabajador); err != nil {
+			return internalError("Error al registrar la nómina-trabajador", err)
+		}
+
+		response = models.NominaTrabajadorResponse{
+			PK_ID_NOMINA_TRABAJADOR: nominaTrabajador.PK_ID_NOMINA_TRABAJADOR,
+			SUELDO_BASE:             trabajador.SUELDO,
+			MONTO_INCIDENCIAS:       montoIncidencias,
+			TOTAL:                   total,
+			DETALLES:                descripcion,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// MesEnEspanol devuelve el nombre del mes en español
+func MesEnEspanol(mes time.Month) string {
+	meses := map[time.Month]string{
+		time.January:   "Enero",
+		time.February:  "Febrero",
+		time.March:     "Marzo",
+		time.April:     "Abril",
+		time.May:       "Mayo",
+		time.June:      "Junio",
+		time.July:      "Julio",
+		time.August:    "Agosto",
+		time.September: "Septiembre",
+		time.October:   "Octubre",
+		time.November:  "Noviembre",
+		time.December:  "Diciembre",
+	}
+	return meses[mes]
+}
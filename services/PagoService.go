@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+)
+
+// PagoService concentra la lógica de pagos compartida por las versiones v1 y v2 del API
+type PagoService struct {
+	store repositories.Store
+}
+
+func NewPagoService(store repositories.Store) *PagoService {
+	return &PagoService{store: store}
+}
+
+// GetByID busca un pago por su identificador y ajusta fechas y hora para la respuesta
+func (s *PagoService) GetByID(id int) (*models.Pago, error) {
+	pago, err := s.GetForUpdate(id)
+	if err != nil {
+		return nil, err
+	}
+	formatearPago(pago)
+	return pago, nil
+}
+
+// GetForUpdate busca un pago tal como está almacenado, para modificarlo y guardarlo con Update
+func (s *PagoService) GetForUpdate(id int) (*models.Pago, error) {
+	pago, err := s.store.Pagos().Get(id)
+	if err != nil {
+		return nil, lookup(err, notFound("Pago no encontrado"))
+	}
+	return pago, nil
+}
+
+// Update guarda el pago si nadie lo modificó desde la versión indicada
+func (s *PagoService) Update(pago *models.Pago, version int) error {
+	return s.save(pago, version)
+}
+
+// Delete elimina un pago que aún no se ha cobrado. Los pagos cobrados o reembolsados responden
+// 409: el dinero se devuelve cancelando el pedido, que deja registro del reembolso.
+func (s *PagoService) Delete(id int) error {
+	pago, err := s.GetForUpdate(id)
+	if err != nil {
+		return err
+	}
+	if pago.ESTADO_PAGO == "PAGADO" || pago.ESTADO_PAGO == PagoReembolsado {
+		return &Error{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("Un pago %s no se puede eliminar; cancele el pedido para reembolsarlo", pago.ESTADO_PAGO),
+			Data:    pago,
+		}
+	}
+	if err := s.store.Pagos().Delete(id); err != nil {
+		return lookup(err, notFound("Pago no encontrado"))
+	}
+	return nil
+}
+
+// formatearPago ajusta las fechas a la hora de Bogotá y la hora al formato HH:mm:ss
+func formatearPago(pago *models.Pago) {
+	pago.FECHA = pago.FECHA.In(database.BogotaZone)
+	pago.UPDATED_AT = pago.UPDATED_AT.In(database.BogotaZone)
+	if len(pago.HORA) >= 19 {
+		pago.HORA = pago.HORA[11:19] // Formato HH:mm:ss
+	}
+}
+
+// save guarda el pago si sigue en la versión indicada; en un conflicto responde 409 con el actual
+func (s *PagoService) save(pago *models.Pago, version int, cols ...string) error {
+	err := s.store.Pagos().Update(pago, version, cols...)
+	if err != nil {
+		return saveError(err, func() (*models.Pago, error) {
+			return s.store.Pagos().Get(pago.PK_ID_PAGO)
+		})
+	}
+	PublicarPago(s.store, pago, AccionActualizado)
+	return nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"restaurante/models"
+	"restaurante/repositories"
+)
+
+// PedidoClienteService gestiona la relación entre pedidos y clientes
+type PedidoClienteService struct {
+	store repositories.Store
+}
+
+func NewPedidoClienteService(store repositories.Store) *PedidoClienteService {
+	return &PedidoClienteService{store: store}
+}
+
+// Create asocia un pedido a un cliente verificando que ambos existan y que el pedido no tenga dueño
+func (s *PedidoClienteService) Create(relacion *models.PedidoCliente) error {
+	if relacion.PK_DOCUMENTO_CLIENTE == nil || relacion.PK_ID_PEDIDO == nil {
+		return badRequest("Los campos PK_DOCUMENTO_CLIENTE y PK_ID_PEDIDO son obligatorios")
+	}
+
+	return s.store.Transaction(func(tx repositories.Store) error {
+		if _, err := tx.Clientes().Get(int(*relacion.PK_DOCUMENTO_CLIENTE)); err != nil {
+			return lookup(err, unprocessable("Cliente no encontrado"))
+		}
+		if _, err := tx.Pedidos().Get(*relacion.PK_ID_PEDIDO); err != nil {
+			return lookup(err, unprocessable("Pedido no encontrado"))
+		}
+
+		exists, err := tx.PedidosClientes().ExistsForPedido(*relacion.PK_ID_PEDIDO)
+		if err != nil {
+			return internalError("Error al verificar el pedido", err)
+		}
+		if exists {
+			return badRequest("El pedido ya pertenece a otro cliente")
+		}
+
+		if err := tx.PedidosClientes().Insert(relacion); err != nil {
+			return internalError("Error al crear la relación", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strings"
+	"time"
+)
+
+// Estados del ciclo de vida de un pedido
+const (
+	EstadoIniciado      = "INICIADO"
+	EstadoPagado        = "PAGADO"
+	EstadoEnPreparacion = "EN PREPARACION"
+	EstadoListo         = "LISTO"
+	EstadoEnCamino      = "EN CAMINO"
+	EstadoEntregado     = "ENTREGADO"
+	EstadoCancelado     = "CANCELADO"
+)
+
+// EstadosPedido son los estados válidos en el orden del ciclo de vida
+var EstadosPedido = []string{
+	EstadoIniciado, EstadoPagado, EstadoEnPreparacion, EstadoListo, EstadoEnCamino, EstadoEntregado, EstadoCancelado,
+}
+
+// transicionesPedido indica a qué estados puede pasar un pedido desde cada estado.
+// PAGADO lo asigna el registro del pago cuando el pedido aún no ha entrado a cocina;
+// ENTREGADO y CANCELADO son estados finales.
+var transicionesPedido = map[string][]string{
+	EstadoIniciado:      {EstadoPagado, EstadoEnPreparacion, EstadoCancelado},
+	EstadoPagado:        {EstadoEnPreparacion, EstadoCancelado},
+	EstadoEnPreparacion: {EstadoListo, EstadoCancelado},
+	EstadoListo:         {EstadoEnCamino, EstadoEntregado, EstadoCancelado},
+	EstadoEnCamino:      {EstadoEntregado, EstadoCancelado},
+}
+
+// esEstadoPedido indica si estado es uno de los estados del ciclo de vida
+func esEstadoPedido(estado string) bool {
+	for _, e := range EstadosPedido {
+		if e == estado {
+			return true
+		}
+	}
+	return false
+}
+
+// puedeTransicionar indica si el pedido puede pasar al estado indicado. Desde LISTO los pedidos
+// a domicilio salen EN CAMINO y los de mesa o para recoger se entregan directamente.
+func puedeTransicionar(pedido *models.Pedido, estado string) bool {
+	if pedido.ESTADO_PEDIDO == EstadoListo {
+		domicilio := pedido.DELIVERY || pedido.PK_ID_DOMICILIO != nil
+		if (estado == EstadoEnCamino && !domicilio) || (estado == EstadoEntregado && domicilio) {
+			return false
+		}
+	}
+	for _, siguiente := range transicionesPedido[pedido.ESTADO_PEDIDO] {
+		if siguiente == estado {
+			return true
+		}
+	}
+	return false
+}
+
+// transicionInvalida responde 409 con el pedido actual cuando el cambio de estado no está permitido
+func transicionInvalida(pedido *models.Pedido, estado string) *Error {
+	return &Error{
+		Code:    http.StatusConflict,
+		Message: fmt.Sprintf("No se puede pasar un pedido de %s a %s", pedido.ESTADO_PEDIDO, estado),
+		Data:    pedido,
+	}
+}
+
+// estadoInvalido responde 400 cuando el estado no pertenece al ciclo de vida
+func estadoInvalido(estado string) *Error {
+	return newError(http.StatusBadRequest, "Estado de pedido inválido",
+		fmt.Errorf("'%s' no es un estado válido; use uno de: %s", estado, strings.Join(EstadosPedido, ", ")))
+}
+
+// registrarEstado agrega a la línea de tiempo del pedido el paso de anterior a nuevo.
+// anterior es vacío cuando el pedido se acaba de crear.
+func registrarEstado(tx repositories.Store, pedidoID int, anterior, nuevo string, actor Actor) error {
+	historial := models.PedidoEstadoHistorial{
+		PK_ID_PEDIDO:    pedidoID,
+		ESTADO_NUEVO:    nuevo,
+		DOCUMENTO_ACTOR: actor.documento(),
+		ROL_ACTOR:       actor.rol(),
+		FECHA:           time.Now().In(database.BogotaZone),
+	}
+	if anterior != "" {
+		historial.ESTADO_ANTERIOR = &anterior
+	}
+	if err := tx.HistorialEstados().Insert(&historial); err != nil {
+		return internalError("Error al registrar el historial del pedido", err)
+	}
+	return nil
+}
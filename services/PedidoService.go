@@ -0,0 +1,709 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PedidoService concentra la lógica de pedidos compartida por las versiones v1 y v2 del API
+type PedidoService struct {
+	store repositories.Store
+}
+
+func NewPedidoService(store repositories.Store) *PedidoService {
+	return &PedidoService{store: store}
+}
+
+// PedidoFiltros agrupa los criterios opcionales de búsqueda de pedidos
+type PedidoFiltros = repositories.PedidoFiltros
+
+// List devuelve los pedidos que cumplen con los filtros proporcionados. A un cliente solo se le
+// listan sus propios pedidos, sin importar el cliente que indiquen los filtros.
+func (s *PedidoService) List(filtros PedidoFiltros, actor Actor) ([]models.Pedido, error) {
+	if actor.Rol == RolCliente {
+		filtros.Cliente = actor.Documento
+	}
+	pedidos, err := s.store.Pedidos().Search(filtros)
+	if err != nil {
+		return nil, internalError("Error al obtener los pedidos", err)
+	}
+	return pedidos, nil
+}
+
+// GetByID busca un pedido por su identificador
+func (s *PedidoService) GetByID(id int) (*models.Pedido, error) {
+	pedido, err := s.store.Pedidos().Get(id)
+	if err != nil {
+		return nil, lookup(err, notFound("Pedido no encontrado"))
+	}
+	return pedido, nil
+}
+
+// Create registra un pedido nuevo en estado INICIADO, sin domicilio, pago ni productos asociados,
+// y abre su historial de estados. Los totales los calcula el servidor a medida que se agregan
+// productos; PROPINA_ACEPTADA indica si el cliente acepta la propina sugerida. Con PK_ID_MESA el
+// pedido es una ronda de esa mesa, que queda OCUPADA a nombre del mesero que lo toma. La respuesta
+// trae la hora estimada de entrega, que se ajusta a medida que el pedido avanza.
+func (s *PedidoService) Create(pedido *models.Pedido, actor Actor) error {
+	now := time.Now().In(database.BogotaZone)
+	pedido.FECHA = now
+	if pedido.HORA == "" {
+		pedido.HORA = now.Format("15:04:05")
+	}
+	pedido.ESTADO_PEDIDO = EstadoIniciado
+	pedido.PK_ID_DOMICILIO = nil
+	pedido.PK_ID_PAGO = nil
+	pedido.SUBTOTAL, pedido.DESCUENTO, pedido.IMPUESTO = 0, 0, 0
+	pedido.CODIGO_PROMOCION = nil
+	totalizar(pedido, false)
+
+	if pedido.PK_ID_MESA == nil {
+		pedido.PK_DOCUMENTO_MESERO = nil
+	}
+
+	return s.store.Transaction(func(tx repositories.Store) error {
+		if pedido.PK_ID_MESA != nil {
+			if err := ocuparMesa(tx, pedido, actor); err != nil {
+				return err
+			}
+		}
+		if err := estimarEntrega(tx, pedido); err != nil {
+			return err
+		}
+		if err := tx.Pedidos().Insert(pedido); err != nil {
+			return internalError("Error al crear el pedido", err)
+		}
+		PublicarPedido(tx, pedido, AccionCreado)
+		return registrarEstado(tx, pedido.PK_ID_PEDIDO, "", EstadoIniciado, actor)
+	})
+}
+
+// Update guarda los datos editables del pedido si nadie lo modificó desde la versión indicada.
+// El estado, el pago y el domicilio se cambian con sus operaciones específicas. Los pedidos
+// entregados o cancelados responden 409 y los de otro cliente 404. Cuando el pedido ya tiene pagos
+// sus totales quedan congelados, así que cambiar el tipo de entrega también responde 409.
+func (s *PedidoService) Update(pedido *models.Pedido, version int, actor Actor) error {
+	if pedido.HORA != "" {
+		if _, err := time.Parse("15:04:05", pedido.HORA); err != nil {
+			return newError(http.StatusBadRequest, "Formato de hora inválido, debe ser HH:mm:ss", err)
+		}
+	}
+
+	// Los totales no son editables: se vuelven a liquidar porque cambiar DELIVERY cambia el
+	// domicilio, la propina y las promociones que aplican
+	return s.store.Transaction(func(tx repositories.Store) error {
+		actual, err := NewPedidoService(tx).GetByID(pedido.PK_ID_PEDIDO)
+		if err != nil {
+			return err
+		}
+		if err := accesoPedido(tx, pedido.PK_ID_PEDIDO, actor); err != nil {
+			return err
+		}
+		if err := pedidoAbierto(actual); err != nil {
+			return err
+		}
+		pedido.ESTADO_PEDIDO = actual.ESTADO_PEDIDO
+		pedido.PK_ID_PAGO = actual.PK_ID_PAGO
+		pedido.PROPINA_ACEPTADA = actual.PROPINA_ACEPTADA
+		pedido.PK_ID_DOMICILIO = actual.PK_ID_DOMICILIO
+		pedido.CODIGO_PROMOCION = actual.CODIGO_PROMOCION
+		pedido.PK_ID_MESA, pedido.PK_DOCUMENTO_MESERO = actual.PK_ID_MESA, actual.PK_DOCUMENTO_MESERO
+		pedido.PROGRAMADO_PARA = actual.PROGRAMADO_PARA
+
+		cols := []string{"HORA", "DELIVERY", "PK_ID_RESTAURANTE", "UPDATED_BY", "UPDATED_AT"}
+		cobrado, err := tienePagos(tx, actual)
+		if err != nil {
+			return err
+		}
+		if cobrado {
+			if pedido.DELIVERY != actual.DELIVERY {
+				return &Error{Code: http.StatusConflict, Message: "El pedido ya tiene pagos; cambiar el tipo de entrega cambiaría su total", Data: actual}
+			}
+			pedido.SUBTOTAL, pedido.DESCUENTO, pedido.IMPUESTO = actual.SUBTOTAL, actual.DESCUENTO, actual.IMPUESTO
+			pedido.VALOR_DOMICILIO, pedido.PROPINA_SUGERIDA, pedido.PROPINA = actual.VALOR_DOMICILIO, actual.PROPINA_SUGERIDA, actual.PROPINA
+			pedido.TOTAL = actual.TOTAL
+		} else {
+			if _, err := liquidar(tx, pedido); err != nil {
+				return err
+			}
+			cols = append(cols, columnasTotales...)
+		}
+		return NewPedidoService(tx).save(pedido, version, cols...)
+	})
+}
+
+// tienePagos indica si el pedido ya tiene pagos asociados, cobrados o pendientes de una división;
+// desde entonces sus totales no se vuelven a liquidar
+func tienePagos(tx repositories.Store, pedido *models.Pedido) (bool, error) {
+	if pedido.PK_ID_PAGO != nil {
+		return true, nil
+	}
+	pagos, err := tx.Pagos().ListByPedido(pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return false, internalError("Error al consultar los pagos del pedido", err)
+	}
+	return len(pagos) > 0, nil
+}
+
+// AssignDomicilio asocia un domicilio existente al pedido y, si el pedido ya está LISTO, lo
+// marca como "EN CAMINO". Los pedidos entregados o cancelados responden 409.
+// El pedido, el domicilio y el historial se actualizan en la misma transacción. Un cliente solo
+// puede asignar el domicilio de sus propios pedidos (los de otros responden 404) y no despacharlos:
+// si el pedido ya está LISTO responde 403.
+func (s *PedidoService) AssignDomicilio(pedidoID, domicilioID int, actor Actor) (*models.Pedido, error) {
+	if domicilioID <= 0 {
+		return nil, badRequest("Debe indicar el domicilio a asignar")
+	}
+
+	var pedido *models.Pedido
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if pedido, err = tx.Pedidos().Get(pedidoID); err != nil {
+			return lookup(err, notFound("Pedido no encontrado"))
+		}
+		if err := accesoPedido(tx, pedidoID, actor); err != nil {
+			return err
+		}
+		if pedido.ESTADO_PEDIDO == EstadoEntregado || pedido.ESTADO_PEDIDO == EstadoCancelado {
+			return transicionInvalida(pedido, EstadoEnCamino)
+		}
+		domicilio, err := tx.Domicilios().Get(domicilioID)
+		if err != nil {
+			return lookup(err, unprocessable("El domicilio indicado no existe"))
+		}
+
+		anterior := pedido.ESTADO_PEDIDO
+		pedido.PK_ID_DOMICILIO = &domicilioID
+		if puedeTransicionar(pedido, EstadoEnCamino) {
+			if err := requierePersonal(actor, "despachar los pedidos"); err != nil {
+				return err
+			}
+			pedido.ESTADO_PEDIDO = EstadoEnCamino
+		}
+		if _, err := liquidar(tx, pedido); err != nil {
+			return err
+		}
+		cols := append([]string{"PK_ID_DOMICILIO", "ESTADO_PEDIDO"}, columnasTotales...)
+		if err := NewPedidoService(tx).save(pedido, pedido.VERSION, cols...); err != nil {
+			return err
+		}
+		if pedido.ESTADO_PEDIDO != anterior {
+			if err := registrarEstado(tx, pedido.PK_ID_PEDIDO, anterior, pedido.ESTADO_PEDIDO, actor); err != nil {
+				return err
+			}
+		}
+
+		domicilio.ENTREGADO = false
+		return NewDomicilioService(tx).save(domicilio, domicilio.VERSION, "ENTREGADO")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pedido, nil
+}
+
+// AssignMesa pasa el pedido a la mesa indicada, que queda OCUPADA; si el pedido no tenía mesero
+// queda a nombre de quien lo asigna. Los pedidos a domicilio, entregados o cancelados y las mesas
+// POR LIMPIAR responden 409 y los pedidos de otro cliente 404. La mesa anterior conserva su estado
+// hasta que el personal la libere.
+func (s *PedidoService) AssignMesa(pedidoID int, mesaID int64, actor Actor) (*models.Pedido, error) {
+	if mesaID <= 0 {
+		return nil, badRequest("Debe indicar la mesa a asignar")
+	}
+
+	var pedido *models.Pedido
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if pedido, err = NewPedidoService(tx).GetByID(pedidoID); err != nil {
+			return err
+		}
+		if err := accesoPedido(tx, pedidoID, actor); err != nil {
+			return err
+		}
+		if err := pedidoAbierto(pedido); err != nil {
+			return err
+		}
+		pedido.PK_ID_MESA = &mesaID
+		if err := ocuparMesa(tx, pedido, actor); err != nil {
+			return err
+		}
+		return NewPedidoService(tx).save(pedido, pedido.VERSION, "PK_ID_MESA", "PK_DOCUMENTO_MESERO")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pedido, nil
+}
+
+// AssignPago asocia un pago existente al pedido y marca el pago como "PAGADO". Un pedido puede
+// tener varios pagos cuando se divide la cuenta: pasa a "PAGADO" cuando lo cobrado cubre el total
+// y solo si aún no ha entrado a cocina; después conserva su estado (pago contra entrega). Los
+// pedidos cancelados, los pagos de otro pedido y los reembolsados responden 409. Al pasar a
+// "PAGADO" el pedido queda confirmado y sus unidades salen del inventario. El pedido, el pago, el
+// historial y el inventario se actualizan en la misma transacción. Un pedido sin productos responde
+// 409. Un cliente solo puede pagar sus propios pedidos (los de otros responden 404) y solo con los
+// pagos a su nombre; cobrar cualquier otro pago responde 403.
+func (s *PedidoService) AssignPago(pedidoID, pagoID int, actor Actor) (*models.Pedido, error) {
+	if pagoID <= 0 {
+		return nil, badRequest("Debe indicar el pago a asignar")
+	}
+
+	var pedido *models.Pedido
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if pedido, err = tx.Pedidos().Get(pedidoID); err != nil {
+			return lookup(err, notFound("Pedido no encontrado"))
+		}
+		if err := accesoPedido(tx, pedidoID, actor); err != nil {
+			return err
+		}
+		if pedido.ESTADO_PEDIDO == EstadoCancelado {
+			return transicionInvalida(pedido, EstadoPagado)
+		}
+		pago, err := tx.Pagos().Get(pagoID)
+		if err != nil {
+			return lookup(err, unprocessable("El pago indicado no existe"))
+		}
+		if pago.PK_ID_PEDIDO != nil && *pago.PK_ID_PEDIDO != pedidoID {
+			return &Error{Code: http.StatusConflict, Message: fmt.Sprintf("El pago pertenece al pedido %d", *pago.PK_ID_PEDIDO), Data: pago}
+		}
+		if pago.ESTADO_PAGO == PagoReembolsado {
+			return &Error{Code: http.StatusConflict, Message: "Un pago reembolsado no se puede asignar", Data: pago}
+		}
+		if actor.Rol == RolCliente {
+			propio, err := pagoDelCliente(tx, pago, actor)
+			if err != nil {
+				return err
+			}
+			if !propio {
+				return newError(http.StatusForbidden, "Solo puede cobrar los pagos a su nombre", nil)
+			}
+		}
+		detalles, err := tx.DetallesPedido().ListByPedido(pedidoID)
+		if err != nil {
+			return internalError("Error al obtener los productos del pedido", err)
+		}
+		if len(detalles) == 0 {
+			return &Error{Code: http.StatusConflict, Message: "El pedido no tiene productos que cobrar", Data: pedido}
+		}
+
+		pago.ESTADO_PAGO = "PAGADO"
+		pago.PK_ID_PEDIDO = &pedido.PK_ID_PEDIDO
+		if err := NewPagoService(tx).save(pago, pago.VERSION, "ESTADO_PAGO", "PK_ID_PEDIDO"); err != nil {
+			return err
+		}
+		cuenta, err := cuentaPedido(tx, pedido)
+		if err != nil {
+			return err
+		}
+
+		// PK_ID_PAGO conserva el primer pago del pedido
+		anterior := pedido.ESTADO_PEDIDO
+		if pedido.PK_ID_PAGO == nil {
+			pedido.PK_ID_PAGO = &pagoID
+		}
+		if cuenta.SALDO == 0 && puedeTransicionar(pedido, EstadoPagado) {
+			pedido.ESTADO_PEDIDO = EstadoPagado
+		}
+		if err := NewPedidoService(tx).save(pedido, pedido.VERSION, "PK_ID_PAGO", "ESTADO_PEDIDO"); err != nil {
+			return err
+		}
+		if pedido.ESTADO_PEDIDO != anterior {
+			if err := registrarEstado(tx, pedido.PK_ID_PEDIDO, anterior, pedido.ESTADO_PEDIDO, actor); err != nil {
+				return err
+			}
+			return descontarAlConfirmar(tx, pedido.PK_ID_PEDIDO, anterior, pedido.ESTADO_PEDIDO, actor)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pedido, nil
+}
+
+// pagoDelCliente indica si el pago está a nombre del cliente, es decir, si es una parte del pedido
+// asociada a su relación con él
+func pagoDelCliente(tx repositories.Store, pago *models.Pago, actor Actor) (bool, error) {
+	if pago.PK_ID_PEDIDO == nil || pago.PK_ID_PEDIDO_CLIENTE == nil {
+		return false, nil
+	}
+	relaciones, err := tx.PedidosClientes().ListByPedido(*pago.PK_ID_PEDIDO)
+	if err != nil {
+		return false, internalError("Error al consultar los clientes del pedido", err)
+	}
+	for _, relacion := range relaciones {
+		if relacion.PK_ID_PEDIDO_CLIENTE == *pago.PK_ID_PEDIDO_CLIENTE {
+			return relacion.PK_DOCUMENTO_CLIENTE != nil && *relacion.PK_DOCUMENTO_CLIENTE == int64(actor.Documento), nil
+		}
+	}
+	return false, nil
+}
+
+// UpdateEstado mueve el pedido al estado indicado si el ciclo de vida lo permite y registra
+// el cambio en el historial. CANCELADO sigue las reglas de CancelacionService con el motivo OTRO y
+// al pasar a EN PREPARACION el pedido se reparte en tickets de cocina. Un estado desconocido
+// responde 400 y una transición no permitida 409, igual que llevar a la cocina un pedido
+// programado antes de su hora de liberación. Un cliente solo puede cancelar sus propios pedidos:
+// los demás cambios responden 403 y los pedidos de otros 404.
+func (s *PedidoService) UpdateEstado(pedidoID int, estado string, actor Actor) (*models.Pedido, error) {
+	estado = strings.ToUpper(strings.TrimSpace(estado))
+	if estado == "" {
+		return nil, badRequest("El estado del pedido es obligatorio")
+	}
+	if !esEstadoPedido(estado) {
+		return nil, estadoInvalido(estado)
+	}
+
+	var pedido *models.Pedido
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if pedido, err = NewPedidoService(tx).GetByID(pedidoID); err != nil {
+			return err
+		}
+		if err := accesoPedido(tx, pedidoID, actor); err != nil {
+			return err
+		}
+		if estado != EstadoCancelado {
+			if err := requierePersonal(actor, "cambiar el estado de los pedidos"); err != nil {
+				return err
+			}
+		}
+		if estado == EstadoCancelado {
+			_, err := cancelarPedido(tx, pedido, CancelacionOtro, "", actor)
+			return err
+		}
+		if !puedeTransicionar(pedido, estado) {
+			return transicionInvalida(pedido, estado)
+		}
+		if estado == EstadoEnPreparacion && retenido(pedido, time.Now().In(database.BogotaZone)) {
+			return pedidoRetenido(pedido)
+		}
+		return cambiarEstado(tx, pedido, estado, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pedido, nil
+}
+
+// cambiarEstado guarda el nuevo estado del pedido, lo registra en el historial, descuenta el
+// inventario si con él se confirma y, si entra a EN PREPARACION, lo reparte en tickets de cocina
+func cambiarEstado(tx repositories.Store, pedido *models.Pedido, estado string, actor Actor) error {
+	anterior := pedido.ESTADO_PEDIDO
+	pedido.ESTADO_PEDIDO = estado
+	if err := NewPedidoService(tx).save(pedido, pedido.VERSION, "ESTADO_PEDIDO"); err != nil {
+		return err
+	}
+	if err := registrarEstado(tx, pedido.PK_ID_PEDIDO, anterior, estado, actor); err != nil {
+		return err
+	}
+	if err := descontarAlConfirmar(tx, pedido.PK_ID_PEDIDO, anterior, estado, actor); err != nil {
+		return err
+	}
+	if estado == EstadoEnPreparacion {
+		return enviarACocina(tx, pedido.PK_ID_PEDIDO)
+	}
+	return nil
+}
+
+// Programar fija la hora a la que el cliente quiere recibir o recoger el pedido (DD-MM-YYYY
+// HH:mm:ss, hora de Bogotá); vacío lo vuelve un pedido inmediato. La hora debe quedar después de
+// la anticipación configurada, dentro de los días permitidos y en el horario del restaurante o
+// en el cambio de horario de ese día. Solo se programan pedidos que no han entrado a la cocina y
+// un cliente solo puede programar sus propios pedidos.
+func (s *PedidoService) Programar(pedidoID int, programado string, actor Actor) (*models.Pedido, error) {
+	var hora *time.Time
+	if strings.TrimSpace(programado) != "" {
+		parsed, err := parseProgramado(programado)
+		if err != nil {
+			return nil, err
+		}
+		hora = &parsed
+	}
+
+	var pedido *models.Pedido
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if pedido, err = NewPedidoService(tx).GetByID(pedidoID); err != nil {
+			return err
+		}
+		cliente, err := clientePedido(tx, pedidoID)
+		if err != nil {
+			return err
+		}
+		if actor.Rol == RolCliente && (cliente == nil || *cliente != int64(actor.Documento)) {
+			return newError(http.StatusForbidden, "Solo puede programar sus propios pedidos", nil)
+		}
+		if pedido.ESTADO_PEDIDO != EstadoIniciado && pedido.ESTADO_PEDIDO != EstadoPagado {
+			return &Error{Code: http.StatusConflict, Message: fmt.Sprintf("Un pedido %s ya no se puede programar", pedido.ESTADO_PEDIDO), Data: pedido}
+		}
+		if hora != nil {
+			if err := validarProgramacion(tx, pedido, *hora, time.Now().In(database.BogotaZone)); err != nil {
+				return err
+			}
+		}
+		pedido.PROGRAMADO_PARA = hora
+		return NewPedidoService(tx).save(pedido, pedido.VERSION, "PROGRAMADO_PARA")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pedido, nil
+}
+
+// Programados devuelve los pedidos programados que todavía esperan para entrar a la cocina, del
+// más próximo al más lejano. Los clientes responden 403.
+func (s *PedidoService) Programados(actor Actor) ([]models.Pedido, error) {
+	if err := requierePersonal(actor, "ver los pedidos programados"); err != nil {
+		return nil, err
+	}
+	return s.pendientesDeCocina()
+}
+
+// LiberarProgramados pasa a EN PREPARACION los pedidos programados cuya hora de liberación ya
+// llegó. Cada pedido se libera en su propia transacción para que uno con problemas no detenga a
+// los demás; se devuelven los liberados junto con el primer error encontrado.
+func (s *PedidoService) LiberarProgramados(ahora time.Time) ([]models.Pedido, error) {
+	pendientes, err := s.pendientesDeCocina()
+	if err != nil {
+		return nil, err
+	}
+
+	liberados := []models.Pedido{}
+	var primero error
+	for _, pendiente := range pendientes {
+		if retenido(&pendiente, ahora) {
+			break
+		}
+		var pedido *models.Pedido
+		err := s.store.Transaction(func(tx repositories.Store) error {
+			var err error
+			if pedido, err = NewPedidoService(tx).GetByID(pendiente.PK_ID_PEDIDO); err != nil {
+				return err
+			}
+			// Entre la consulta y la transacción pudo cambiar de estado o de hora
+			if pedido.PROGRAMADO_PARA == nil || retenido(pedido, ahora) || !puedeTransicionar(pedido, EstadoEnPreparacion) {
+				pedido = nil
+				return nil
+			}
+			return cambiarEstado(tx, pedido, EstadoEnPreparacion, Actor{})
+		})
+		if err != nil {
+			if primero == nil {
+				primero = err
+			}
+			continue
+		}
+		if pedido != nil {
+			liberados = append(liberados, *pedido)
+		}
+	}
+	return liberados, primero
+}
+
+// pendientesDeCocina devuelve los pedidos programados en INICIADO o PAGADO ordenados por su hora
+func (s *PedidoService) pendientesDeCocina() ([]models.Pedido, error) {
+	pedidos, err := s.store.Pedidos().Search(PedidoFiltros{Programados: true})
+	if err != nil {
+		return nil, internalError("Error al consultar los pedidos programados", err)
+	}
+	pendientes := []models.Pedido{}
+	vistos := map[int]bool{}
+	for _, pedido := range pedidos {
+		if vistos[pedido.PK_ID_PEDIDO] || pedido.PROGRAMADO_PARA == nil {
+			continue
+		}
+		vistos[pedido.PK_ID_PEDIDO] = true
+		if pedido.ESTADO_PEDIDO == EstadoIniciado || pedido.ESTADO_PEDIDO == EstadoPagado {
+			pendientes = append(pendientes, pedido)
+		}
+	}
+	sort.SliceStable(pendientes, func(i, j int) bool {
+		return pendientes[i].PROGRAMADO_PARA.Before(*pendientes[j].PROGRAMADO_PARA)
+	})
+	return pendientes, nil
+}
+
+// UpdatePropina registra si el cliente acepta o rechaza la propina sugerida y recalcula el total.
+// Los pedidos con domicilio no llevan propina y los entregados o cancelados responden 409. Un
+// cliente solo puede cambiarla en sus propios pedidos; los de otros responden 404.
+func (s *PedidoService) UpdatePropina(pedidoID int, aceptada bool, actor Actor) (*models.Pedido, error) {
+	var pedido *models.Pedido
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if pedido, err = NewPedidoService(tx).GetByID(pedidoID); err != nil {
+			return err
+		}
+		if err := accesoPedido(tx, pedidoID, actor); err != nil {
+			return err
+		}
+		if err := pedidoAbierto(pedido); err != nil {
+			return err
+		}
+		if esDomicilio(pedido) {
+			return &Error{Code: http.StatusConflict, Message: "La propina sugerida solo aplica a los pedidos en el restaurante", Data: pedido}
+		}
+
+		pedido.PROPINA_ACEPTADA = aceptada
+		if _, err := liquidar(tx, pedido); err != nil {
+			return err
+		}
+		return NewPedidoService(tx).save(pedido, pedido.VERSION, append([]string{"PROPINA_ACEPTADA"}, columnasTotales...)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pedido, nil
+}
+
+// UpdatePromocion registra el código de promoción del pedido y recalcula sus totales. Un código
+// inexistente o que no aplica al pedido responde 422 con el motivo; un código vacío lo quita. Los
+// pedidos entregados o cancelados responden 409 y los de otro cliente 404.
+func (s *PedidoService) UpdatePromocion(pedidoID int, codigo string, actor Actor) (*models.Pedido, error) {
+	var pedido *models.Pedido
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		var err error
+		if pedido, err = NewPedidoService(tx).GetByID(pedidoID); err != nil {
+			return err
+		}
+		if err := accesoPedido(tx, pedidoID, actor); err != nil {
+			return err
+		}
+		if err := pedidoAbierto(pedido); err != nil {
+			return err
+		}
+		if err := aplicarCodigo(tx, pedido, codigo); err != nil {
+			return err
+		}
+		return NewPedidoService(tx).save(pedido, pedido.VERSION, append([]string{"CODIGO_PROMOCION"}, columnasTotales...)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pedido, nil
+}
+
+// GetRecibo arma el recibo del pedido con el desglose de impuestos por categoría, las promociones
+// aplicadas y la propina. Un cliente solo ve el recibo de sus propios pedidos; los de otros
+// responden 404.
+func (s *PedidoService) GetRecibo(pedidoID int, actor Actor) (*models.Recibo, error) {
+	details, err := s.store.Pedidos().Details(int64(pedidoID))
+	if err != nil {
+		return nil, lookup(err, notFound("Pedido no encontrado"))
+	}
+	if err := accesoPedido(s.store, pedidoID, actor); err != nil {
+		return nil, err
+	}
+	lineas, err := lineasPedido(s.store, pedidoID)
+	if err != nil {
+		return nil, err
+	}
+	promociones, err := s.store.Promociones().ListByPedido(pedidoID)
+	if err != nil {
+		return nil, internalError("Error al obtener las promociones del pedido", err)
+	}
+
+	return &models.Recibo{
+		PK_ID_PEDIDO:     pedidoID,
+		FECHA:            details.Fecha,
+		HORA:             details.Hora,
+		ESTADO_PEDIDO:    details.EstadoPedido,
+		DELIVERY:         details.Delivery,
+		METODO_PAGO:      details.MetodoPago,
+		PRODUCTOS:        lineas,
+		IMPUESTOS:        desgloseImpuestos(lineas),
+		PROMOCIONES:      promociones,
+		SUBTOTAL:         details.Subtotal,
+		DESCUENTO:        details.Descuento,
+		IMPUESTO:         details.Impuesto,
+		PROPINA_SUGERIDA: details.PropinaSugerida,
+		PROPINA_ACEPTADA: details.PropinaAceptada,
+		PROPINA:          details.Propina,
+		VALOR_DOMICILIO:  details.ValorDomicilio,
+		TOTAL:            details.Total,
+	}, nil
+}
+
+// GetDetails devuelve el pedido con su método de pago, los productos asociados, la línea de
+// tiempo de sus cambios de estado y la hora estimada de entrega. Un cliente solo ve sus propios
+// pedidos; los de otros responden 404.
+func (s *PedidoService) GetDetails(pedidoID int64, actor Actor) (*models.PedidoDetails, error) {
+	details, err := s.store.Pedidos().Details(pedidoID)
+	if err != nil {
+		return nil, lookup(err, notFound("Pedido no encontrado"))
+	}
+	if err := accesoPedido(s.store, int(pedidoID), actor); err != nil {
+		return nil, err
+	}
+
+	lineas, err := lineasPedido(s.store, int(pedidoID))
+	if err != nil {
+		return nil, err
+	}
+	if details.Productos, err = productosJSON(lineas); err != nil {
+		return nil, err
+	}
+
+	if details.Historial, err = s.store.HistorialEstados().ListByPedido(int(pedidoID)); err != nil {
+		return nil, internalError("Error al obtener el historial del pedido", err)
+	}
+
+	pedido, err := s.store.Pedidos().Get(int(pedidoID))
+	if err != nil {
+		return nil, lookup(err, notFound("Pedido no encontrado"))
+	}
+	if pedido.ENTREGA_ESTIMADA != nil {
+		details.EntregaEstimada = pedido.ENTREGA_ESTIMADA.In(database.BogotaZone).Format("02-01-2006 15:04:05")
+	}
+	return details, nil
+}
+
+// accesoPedido responde 404 cuando un cliente intenta ver o cambiar un pedido que no es suyo, para
+// no revelar que existe. Es suyo si está entre los clientes del pedido, incluidos los que pagan una
+// parte de la cuenta dividida. El personal y los procesos internos tienen acceso a todos los pedidos.
+func accesoPedido(tx repositories.Store, pedidoID int, actor Actor) error {
+	if actor.Rol != RolCliente {
+		return nil
+	}
+	relaciones, err := tx.PedidosClientes().ListByPedido(pedidoID)
+	if err != nil {
+		return internalError("Error al consultar los clientes del pedido", err)
+	}
+	for _, relacion := range relaciones {
+		if relacion.PK_DOCUMENTO_CLIENTE != nil && *relacion.PK_DOCUMENTO_CLIENTE == int64(actor.Documento) {
+			return nil
+		}
+	}
+	return notFound("Pedido no encontrado")
+}
+
+// save guarda el pedido si sigue en la versión indicada; en un conflicto responde 409 con el actual.
+// Los cambios de estado, de productos o de domicilio recalculan la entrega estimada, y cada cambio
+// guardado se anuncia a los suscriptores de eventos al confirmar la transacción.
+func (s *PedidoService) save(pedido *models.Pedido, version int, cols ...string) error {
+	if recalculaEntrega(cols) {
+		if err := estimarEntrega(s.store, pedido); err != nil {
+			return err
+		}
+		cols = append(cols[:len(cols):len(cols)], "ENTREGA_ESTIMADA")
+	}
+	err := s.store.Pedidos().Update(pedido, version, cols...)
+	if err != nil {
+		return saveError(err, func() (*models.Pedido, error) {
+			return s.store.Pedidos().Get(pedido.PK_ID_PEDIDO)
+		})
+	}
+	accion := AccionActualizado
+	if pedido.ESTADO_PEDIDO == EstadoCancelado && contiene(cols, "ESTADO_PEDIDO") {
+		accion = AccionCancelado
+	}
+	PublicarPedido(s.store, pedido, accion)
+	return nil
+}
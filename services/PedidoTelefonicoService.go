@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strings"
+)
+
+// indicativoColombia es el indicativo que se asume para los números sin indicativo
+const indicativoColombia = "57"
+
+// PedidoTelefonicoService atiende los pedidos que llegan por teléfono: identifica al cliente por el
+// número desde el que llama y registra el pedido a su nombre, o registra al cliente si es nuevo.
+// Solo lo usa el personal del restaurante.
+type PedidoTelefonicoService struct {
+	store repositories.Store
+}
+
+func NewPedidoTelefonicoService(store repositories.Store) *PedidoTelefonicoService {
+	return &PedidoTelefonicoService{store: store}
+}
+
+// Buscar identifica al cliente que llama desde numero y devuelve sus direcciones y últimos pedidos
+func (s *PedidoTelefonicoService) Buscar(numero string, actor Actor) (*models.ClienteLlamada, error) {
+	if err := requierePersonal(actor, "tomar pedidos por teléfono"); err != nil {
+		return nil, err
+	}
+	telefono, numeros, err := normalizarTelefono(numero)
+	if err != nil {
+		return nil, err
+	}
+	cliente, err := clientePorTelefono(s.store, numeros, 0)
+	if err != nil {
+		return nil, err
+	}
+	if cliente == nil {
+		return nil, notFound(fmt.Sprintf("No hay un cliente registrado con el teléfono %s", telefono))
+	}
+	cliente.PASSWORD = ""
+
+	llamada := &models.ClienteLlamada{TELEFONO: telefono, CLIENTE: cliente}
+	if llamada.PEDIDOS, err = NewRecompraService(s.store).Historial(cliente.PK_DOCUMENTO_CLIENTE, 0, actor); err != nil {
+		return nil, err
+	}
+	if llamada.DIRECCIONES, err = direccionesDelCliente(s.store, cliente, llamada.PEDIDOS); err != nil {
+		return nil, err
+	}
+	return llamada, nil
+}
+
+// Crear registra el pedido de quien llama desde req.TELEFONO con el mismo flujo del checkout. Si el
+// número no está registrado crea el cliente con los datos de req.CLIENTE y sin contraseña, así que
+// no puede iniciar sesión hasta que se le asigne una. Si varios clientes comparten el número,
+// PK_DOCUMENTO_CLIENTE indica cuál es; sin él se usa el que pidió más recientemente. Un domicilio
+// sin TELEFONO queda con el número de la llamada. Nadie ha cobrado todavía, así que el pago y el
+// domicilio quedan PENDIENTE y el pedido INICIADO.
+func (s *PedidoTelefonicoService) Crear(req *models.PedidoTelefonicoRequest, actor Actor) (*models.PedidoTelefonicoResponse, error) {
+	if err := requierePersonal(actor, "tomar pedidos por teléfono"); err != nil {
+		return nil, err
+	}
+	telefono, numeros, err := normalizarTelefono(req.TELEFONO)
+	if err != nil {
+		return nil, err
+	}
+
+	respuesta := &models.PedidoTelefonicoResponse{}
+	err = s.store.Transaction(func(tx repositories.Store) error {
+		cliente, err := clientePorTelefono(tx, numeros, req.PK_DOCUMENTO_CLIENTE)
+		if err != nil {
+			return err
+		}
+		if cliente == nil {
+			if cliente, err = registrarCliente(tx, req.CLIENTE, telefono); err != nil {
+				return err
+			}
+			respuesta.CLIENTE_NUEVO = true
+		}
+		cliente.PASSWORD = ""
+		respuesta.CLIENTE = cliente
+
+		checkout := req.CheckoutRequest
+		checkout.PK_DOCUMENTO_CLIENTE = cliente.PK_DOCUMENTO_CLIENTE
+		if checkout.DOMICILIO != nil && strings.TrimSpace(checkout.DOMICILIO.TELEFONO) == "" {
+			domicilio := *checkout.DOMICILIO
+			domicilio.TELEFONO = telefono
+			checkout.DOMICILIO = &domicilio
+		}
+		respuesta.CheckoutResponse, err = NewCheckoutService(tx).registrar(&checkout, actor, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return respuesta, nil
+}
+
+// normalizarTelefono lleva un número a E.164. Acepta espacios, guiones, puntos y paréntesis, y los
+// números colombianos con o sin +57 (o 0057). Devuelve también las formas, solo dígitos, en que
+// puede estar guardado el número: con y sin indicativo para los colombianos.
+func normalizarTelefono(texto string) (string, []string, error) {
+	digitos := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(strings.TrimSpace(texto))
+	internacional := strings.HasPrefix(digitos, "+") || strings.HasPrefix(digitos, "00")
+	digitos = strings.TrimPrefix(strings.TrimPrefix(digitos, "+"), "00")
+	if digitos == "" {
+		return "", nil, badRequest("El teléfono es obligatorio")
+	}
+	for _, r := range digitos {
+		if r < '0' || r > '9' {
+			return "", nil, badRequest(fmt.Sprintf("El teléfono %q tiene caracteres que no son dígitos", texto))
+		}
+	}
+
+	nacional := digitos
+	if strings.HasPrefix(digitos, indicativoColombia) && (internacional || len(digitos) == 12) {
+		nacional = strings.TrimPrefix(digitos, indicativoColombia)
+	} else if internacional {
+		// Otro país: E.164 admite hasta 15 dígitos con el indicativo
+		if len(digitos) < 8 || len(digitos) > 15 {
+			return "", nil, badRequest(fmt.Sprintf("El teléfono %q no es un número internacional válido", texto))
+		}
+		return "+" + digitos, []string{digitos}, nil
+	}
+
+	// Los números colombianos tienen 10 dígitos: celulares desde 3 y fijos desde 60
+	if len(nacional) != 10 || !(strings.HasPrefix(nacional, "3") || strings.HasPrefix(nacional, "60")) {
+		return "", nil, badRequest(fmt.Sprintf("El teléfono %q no es un número colombiano válido", texto))
+	}
+	return "+" + indicativoColombia + nacional, []string{nacional, indicativoColombia + nacional}, nil
+}
+
+// clientePorTelefono busca al cliente registrado con alguno de numeros. Con documento elige ese
+// cliente, que debe tener el número; sin documento, si varios comparten el número, elige el que
+// pidió más recientemente. Devuelve nil si el número no es de nadie.
+func clientePorTelefono(tx repositories.Store, numeros []string, documento int) (*models.Cliente, error) {
+	clientes, err := tx.Clientes().ListByTelefono(numeros)
+	if err != nil {
+		return nil, internalError("Error al buscar el cliente por teléfono", err)
+	}
+	if documento != 0 {
+		for i := range clientes {
+			if clientes[i].PK_DOCUMENTO_CLIENTE == documento {
+				return &clientes[i], nil
+			}
+		}
+		if len(clientes) > 0 {
+			return nil, unprocessable("El cliente indicado no tiene registrado ese teléfono")
+		}
+	}
+	if len(clientes) == 0 {
+		return nil, nil
+	}
+
+	elegido, reciente := 0, 0
+	for i := range clientes {
+		pedidos, err := pedidosDelCliente(tx, clientes[i].PK_DOCUMENTO_CLIENTE, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(pedidos) > 0 && pedidos[0].PK_ID_PEDIDO > reciente {
+			elegido, reciente = i, pedidos[0].PK_ID_PEDIDO
+		}
+	}
+	return &clientes[elegido], nil
+}
+
+// registrarCliente crea, sin contraseña, al cliente que llama por primera vez
+func registrarCliente(tx repositories.Store, datos *models.ClienteNuevoRequest, telefono string) (*models.Cliente, error) {
+	if datos == nil || datos.PK_DOCUMENTO_CLIENTE <= 0 || strings.TrimSpace(datos.NOMBRE) == "" {
+		return nil, badRequest(fmt.Sprintf("El teléfono %s no está registrado; envíe CLIENTE con PK_DOCUMENTO_CLIENTE y NOMBRE para registrarlo", telefono))
+	}
+	existente, err := tx.Clientes().Get(datos.PK_DOCUMENTO_CLIENTE)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, internalError("Error al consultar el cliente", err)
+	}
+	if existente != nil {
+		existente.PASSWORD = ""
+		return nil, &Error{Code: http.StatusConflict, Message: "Ya hay un cliente con ese documento registrado con otro teléfono", Data: existente}
+	}
+
+	cliente := &models.Cliente{
+		PK_DOCUMENTO_CLIENTE: datos.PK_DOCUMENTO_CLIENTE,
+		NOMBRE:               strings.TrimSpace(datos.NOMBRE),
+		APELLIDO:             strings.TrimSpace(datos.APELLIDO),
+		DIRECCION:            strings.TrimSpace(datos.DIRECCION),
+		TELEFONO:             telefono,
+		OBSERVACIONES:        datos.OBSERVACIONES,
+	}
+	if err := tx.Clientes().Insert(cliente); err != nil {
+		return nil, internalError("Error al registrar el cliente", err)
+	}
+	return cliente, nil
+}
+
+// direccionesDelCliente reúne, sin repetir, la dirección registrada del cliente y luego las de
+// los domicilios de sus pedidos, del más reciente al más antiguo
+func direccionesDelCliente(store repositories.Store, cliente *models.Cliente, pedidos []models.PedidoAnterior) ([]string, error) {
+	direcciones := []string{}
+	vistas := map[string]bool{}
+	agregar := func(direccion string) {
+		direccion = strings.TrimSpace(direccion)
+		clave := strings.ToLower(strings.Join(strings.Fields(direccion), " "))
+		if clave != "" && !vistas[clave] {
+			vistas[clave] = true
+			direcciones = append(direcciones, direccion)
+		}
+	}
+
+	agregar(cliente.DIRECCION)
+	for _, anterior := range pedidos {
+		if anterior.PEDIDO.PK_ID_DOMICILIO == nil {
+			continue
+		}
+		domicilio, err := store.Domicilios().Get(*anterior.PEDIDO.PK_ID_DOMICILIO)
+		if err != nil {
+			return nil, internalError("Error al consultar el domicilio del pedido", err)
+		}
+		agregar(domicilio.DIRECCION)
+	}
+	return direcciones, nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"fmt"
+	"restaurante/models"
+	"restaurante/repositories"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// columnasTotales son las columnas del pedido que se recalculan con cada cambio de precio
+var columnasTotales = []string{"SUBTOTAL", "DESCUENTO", "IMPUESTO", "VALOR_DOMICILIO", "PROPINA_SUGERIDA", "PROPINA", "TOTAL"}
+
+// valorDomicilio es la tarifa fija que se cobra a los pedidos con domicilio (clave valor_domicilio)
+func valorDomicilio() int64 {
+	return web.AppConfig.DefaultInt64("valor_domicilio", 0)
+}
+
+// cotizar valida los productos solicitados y sus modificadores y arma las líneas del pedido con el
+// nombre y el precio vigentes de cada producto más lo que suman las opciones elegidas, y el
+// impuesto de su categoría, que ya viene incluido en el precio; los precios que envíe el cliente
+// se ignoran. Pedir más unidades de las que quedan de un producto con control de stock, sumando
+// todas sus líneas aunque lleven notas u opciones distintas, responde 409.
+func cotizar(tx repositories.Store, items []models.ItemPedido) ([]models.LineaProducto, error) {
+	if len(items) == 0 {
+		return nil, badRequest("El pedido debe tener al menos un producto")
+	}
+
+	lineas := make([]models.LineaProducto, 0, len(items))
+	pedidas := make(map[int64]int, len(items))
+	for i, item := range items {
+		if err := validarItem(i, item); err != nil {
+			return nil, err
+		}
+		producto, err := tx.Productos().Get(item.PK_ID_PRODUCTO)
+		if err != nil {
+			return nil, lookup(err, unprocessable(fmt.Sprintf("El producto %d no existe", item.PK_ID_PRODUCTO)))
+		}
+		if producto.ESTADO_PRODUCTO == "NO DISPONIBLE" {
+			return nil, unprocessable(fmt.Sprintf("El producto %s no está disponible", producto.NOMBRE))
+		}
+		pedidas[producto.PK_ID_PRODUCTO] += item.CANTIDAD
+		if producto.CONTROLA_STOCK && pedidas[producto.PK_ID_PRODUCTO] > producto.CANTIDAD {
+			return nil, stockInsuficiente(producto, pedidas[producto.PK_ID_PRODUCTO])
+		}
+
+		modificadores, adicional, err := elegirModificadores(tx, producto, item.MODIFICADORES)
+		if err != nil {
+			return nil, err
+		}
+		unitario := producto.PRECIO + adicional
+		if unitario < 0 {
+			return nil, unprocessable(fmt.Sprintf("El precio de %s con las opciones elegidas no puede ser negativo", producto.NOMBRE))
+		}
+
+		regla, err := reglaImpuesto(tx, categoriaProducto(producto))
+		if err != nil {
+			return nil, err
+		}
+
+		subtotal := unitario * int64(item.CANTIDAD)
+		linea := models.LineaProducto{
+			PK_ID_PRODUCTO:     producto.PK_ID_PRODUCTO,
+			NOMBRE:             producto.NOMBRE,
+			CANTIDAD:           item.CANTIDAD,
+			PRECIO_UNITARIO:    unitario,
+			SUBTOTAL:           subtotal,
+			NOTAS:              item.NOTAS,
+			MODIFICADORES:      modificadores,
+			CATEGORIA_IMPUESTO: regla.CATEGORIA,
+			TARIFA_IMPUESTO:    regla.TARIFA,
+			IMPUESTO:           models.ImpuestoIncluido(subtotal, regla.TARIFA),
+		}
+		lineas = append(lineas, linea)
+	}
+	return lineas, nil
+}
+
+// validarItem responde 400 si el producto i-ésimo (desde cero) no indica el producto o sus unidades
+func validarItem(i int, item models.ItemPedido) error {
+	if item.PK_ID_PRODUCTO <= 0 || item.CANTIDAD <= 0 {
+		return badRequest(fmt.Sprintf("El producto %d debe indicar PK_ID_PRODUCTO y una CANTIDAD mayor a cero", i+1))
+	}
+	return nil
+}
+
+// esDomicilio indica si el pedido se entrega a domicilio
+func esDomicilio(pedido *models.Pedido) bool {
+	return pedido.DELIVERY || pedido.PK_ID_DOMICILIO != nil
+}
+
+// totalizar recalcula el valor del domicilio, la propina y el total del pedido a partir del
+// subtotal, el descuento y el impuesto ya liquidados. Los precios incluyen el impuesto, así que
+// el impuesto no se suma al total. La propina sugerida se calcula sobre el valor antes de
+// impuestos y solo aplica a los pedidos sin domicilio.
+func totalizar(pedido *models.Pedido, domicilioGratis bool) {
+	pedido.VALOR_DOMICILIO = 0
+	pedido.PROPINA_SUGERIDA = 0
+	if esDomicilio(pedido) {
+		if !domicilioGratis {
+			pedido.VALOR_DOMICILIO = valorDomicilio()
+		}
+	} else {
+		pedido.PROPINA_SUGERIDA = models.ValorImpuesto(pedido.SUBTOTAL-pedido.DESCUENTO-pedido.IMPUESTO, porcentajePropina())
+	}
+	pedido.PROPINA = 0
+	if pedido.PROPINA_ACEPTADA {
+		pedido.PROPINA = pedido.PROPINA_SUGERIDA
+	}
+	pedido.TOTAL = pedido.SUBTOTAL - pedido.DESCUENTO + pedido.PROPINA + pedido.VALOR_DOMICILIO
+}
+
+// liquidar recalcula en memoria los totales del pedido con sus líneas guardadas: aplica las
+// promociones, reparte el descuento entre las líneas para liquidar el impuesto sobre el valor
+// que se cobra y calcula el domicilio y la propina. Guarda el descuento de cada línea y las
+// promociones aplicadas, que devuelve; el pedido lo guarda quien llama.
+func liquidar(tx repositories.Store, pedido *models.Pedido) ([]models.PedidoPromocion, error) {
+	detalles, err := tx.DetallesPedido().ListByPedido(pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return nil, internalError("Error al obtener los productos del pedido", err)
+	}
+	pedido.SUBTOTAL = 0
+	for _, d := range detalles {
+		pedido.SUBTOTAL += d.PRECIO_UNITARIO * int64(d.CANTIDAD)
+	}
+
+	aplicadas, err := aplicarPromociones(tx, pedido, detalles)
+	if err != nil {
+		return nil, err
+	}
+	cliente, err := clientePedido(tx, pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return nil, err
+	}
+	descuentos := make([]int64, len(detalles))
+	domicilioGratis := false
+	registros := make([]models.PedidoPromocion, 0, len(aplicadas))
+	for _, a := range aplicadas {
+		domicilioGratis = domicilioGratis || a.promocion.TIPO == PromocionDomicilioGratis
+		for i, v := range a.porLinea {
+			descuentos[i] += v
+		}
+		registros = append(registros, models.PedidoPromocion{
+			PK_ID_PROMOCION:      a.promocion.PK_ID_PROMOCION,
+			PK_DOCUMENTO_CLIENTE: cliente,
+			NOMBRE:               a.promocion.NOMBRE,
+			TIPO:                 a.promocion.TIPO,
+			DESCUENTO:            a.valor,
+		})
+	}
+
+	pedido.DESCUENTO, pedido.IMPUESTO = 0, 0
+	for i, d := range detalles {
+		if d.DESCUENTO != descuentos[i] {
+			if err := tx.DetallesPedido().UpdateDescuento(d.PK_ID_DETALLE_PEDIDO, descuentos[i]); err != nil {
+				return nil, internalError("Error al guardar el descuento de los productos", err)
+			}
+			d.DESCUENTO = descuentos[i]
+		}
+		pedido.DESCUENTO += d.DESCUENTO
+		pedido.IMPUESTO += d.Linea().IMPUESTO
+	}
+	totalizar(pedido, domicilioGratis)
+
+	if err := tx.Promociones().ReplaceForPedido(pedido.PK_ID_PEDIDO, registros); err != nil {
+		return nil, internalError("Error al registrar las promociones del pedido", err)
+	}
+	return registros, nil
+}
+
+// recalcularTotales liquida el pedido con sus líneas guardadas y actualiza sus totales
+func recalcularTotales(tx repositories.Store, pedidoID int) (*models.Pedido, error) {
+	pedido, err := tx.Pedidos().Get(pedidoID)
+	if err != nil {
+		return nil, lookup(err, unprocessable("El pedido indicado no existe"))
+	}
+	if _, err := liquidar(tx, pedido); err != nil {
+		return nil, err
+	}
+	if err := NewPedidoService(tx).save(pedido, pedido.VERSION, columnasTotales...); err != nil {
+		return nil, err
+	}
+	return pedido, nil
+}
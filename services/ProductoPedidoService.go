@@ -0,0 +1,357 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"restaurante/models"
+	"restaurante/repositories"
+	"slices"
+	"strings"
+)
+
+// ProductoPedidoService gestiona las líneas de productos de un pedido
+type ProductoPedidoService struct {
+	store repositories.Store
+}
+
+func NewProductoPedidoService(store repositories.Store) *ProductoPedidoService {
+	return &ProductoPedidoService{store: store}
+}
+
+// List devuelve las líneas de un pedido; responde 404 si el pedido no tiene productos o si es de
+// otro cliente
+func (s *ProductoPedidoService) List(pedidoID int64, actor Actor) ([]models.LineaProducto, error) {
+	if err := accesoPedido(s.store, int(pedidoID), actor); err != nil {
+		return nil, err
+	}
+	lineas, err := lineasPedido(s.store, int(pedidoID))
+	if err != nil {
+		return nil, err
+	}
+	if len(lineas) == 0 {
+		return nil, notFound("No se encontraron productos asociados a este pedido")
+	}
+	return lineas, nil
+}
+
+// Create agrega productos a un pedido existente con el precio vigente de cada producto y actualiza
+// los totales del pedido; si el pedido ya está confirmado descuenta las unidades del inventario.
+// Un pedido PAGADO, LISTO o EN CAMINO responde 409 y el de otro cliente 404.
+func (s *ProductoPedidoService) Create(pedidoID int64, items []models.ItemPedido, actor Actor) (*models.ProductosPedidoResponse, error) {
+	if pedidoID == 0 || len(items) == 0 {
+		return nil, badRequest("El pedido y los detalles de los productos son obligatorios")
+	}
+
+	var response *models.ProductosPedidoResponse
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		pedido, err := tx.Pedidos().Get(int(pedidoID))
+		if err != nil {
+			return lookup(err, unprocessable("El pedido indicado no existe"))
+		}
+		if err := accesoPedido(tx, int(pedidoID), actor); err != nil {
+			return err
+		}
+		if err := lineasEditables(pedido); err != nil {
+			return err
+		}
+
+		lineas, err := cotizar(tx, items)
+		if err != nil {
+			return err
+		}
+		if err := agregarLineas(tx, int(pedidoID), lineas, actor); err != nil {
+			return err
+		}
+		response = &models.ProductosPedidoResponse{PK_ID_PEDIDO: pedidoID, DETALLES_PRODUCTOS: lineas}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Update deja en el pedido exactamente los productos indicados y recalcula sus totales. Compara
+// las líneas guardadas con las pedidas (mismo producto, notas y opciones): las que no cambian se
+// conservan con sus tickets de cocina, las unidades de más se agregan como líneas nuevas que van a
+// la cocina y las de menos se quitan de las últimas líneas, siempre que la cocina no haya empezado
+// a prepararlas. Si el pedido ya está confirmado solo la diferencia entra o sale del inventario.
+// Un pedido PAGADO, LISTO o EN CAMINO responde 409 y el de otro cliente 404.
+func (s *ProductoPedidoService) Update(pedidoID int64, items []models.ItemPedido, actor Actor) (*models.ProductosPedidoResponse, error) {
+	if len(items) == 0 {
+		return nil, badRequest("La lista de productos no puede estar vacía")
+	}
+
+	var response *models.ProductosPedidoResponse
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		pedido, err := tx.Pedidos().Get(int(pedidoID))
+		if err != nil {
+			return lookup(err, notFound("Pedido no encontrado"))
+		}
+		if err := accesoPedido(tx, int(pedidoID), actor); err != nil {
+			return err
+		}
+		actuales, err := tx.DetallesPedido().ListByPedido(int(pedidoID))
+		if err != nil {
+			return internalError("Error al obtener los productos del pedido", err)
+		}
+		if len(actuales) == 0 {
+			return notFound("Pedido no encontrado")
+		}
+		if err := lineasEditables(pedido); err != nil {
+			return err
+		}
+
+		pedidas := map[string]int{}
+		muestras := map[string]models.ItemPedido{}
+		claves := []string{}
+		for i, item := range items {
+			if err := validarItem(i, item); err != nil {
+				return err
+			}
+			clave := claveLinea(item.PK_ID_PRODUCTO, item.NOTAS, item.MODIFICADORES)
+			if _, ok := muestras[clave]; !ok {
+				muestras[clave] = item
+				claves = append(claves, clave)
+			}
+			pedidas[clave] += item.CANTIDAD
+		}
+		guardadas := map[string][]models.DetallePedido{}
+		for _, detalle := range actuales {
+			clave := claveDetalle(detalle)
+			guardadas[clave] = append(guardadas[clave], detalle)
+		}
+
+		if err := quitarUnidades(tx, pedido, guardadas, pedidas, actor); err != nil {
+			return err
+		}
+		nuevas := []models.ItemPedido{}
+		for _, clave := range claves {
+			if faltan := pedidas[clave] - unidades(guardadas[clave]); faltan > 0 {
+				item := muestras[clave]
+				item.CANTIDAD = faltan
+				nuevas = append(nuevas, item)
+			}
+		}
+		if len(nuevas) > 0 {
+			lineas, err := cotizar(tx, nuevas)
+			if err != nil {
+				return err
+			}
+			if err := agregarLineas(tx, int(pedidoID), lineas, actor); err != nil {
+				return err
+			}
+		} else if _, err := recalcularTotales(tx, int(pedidoID)); err != nil {
+			return err
+		}
+		// Si solo quedaron líneas que la cocina ya terminó, el pedido pasa a LISTO
+		if pedido.ESTADO_PEDIDO == EstadoEnPreparacion {
+			if err := sincronizarPedido(tx, int(pedidoID), actor); err != nil {
+				return err
+			}
+		}
+
+		lineas, err := lineasPedido(tx, int(pedidoID))
+		if err != nil {
+			return err
+		}
+		response = &models.ProductosPedidoResponse{PK_ID_PEDIDO: pedidoID, DETALLES_PRODUCTOS: lineas}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// quitarUnidades saca de las líneas guardadas las unidades que ya no se piden, empezando por las
+// más recientes: reduce la línea o la borra con su ítem de cocina y, si el pedido está confirmado,
+// devuelve las unidades al inventario. Responde 409 si la cocina ya empezó alguna de esas líneas.
+func quitarUnidades(tx repositories.Store, pedido *models.Pedido, guardadas map[string][]models.DetallePedido, pedidas map[string]int, actor Actor) error {
+	tickets, err := tx.TicketsCocina().ListByPedido(pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return internalError("Error al consultar los tickets de cocina del pedido", err)
+	}
+	enCocina := map[int64]bool{}
+	for _, ticket := range tickets {
+		for _, item := range ticket.ITEMS {
+			if item.ESTADO != TicketPendiente {
+				enCocina[item.PK_ID_DETALLE_PEDIDO] = true
+			}
+		}
+	}
+
+	claves := make([]string, 0, len(guardadas))
+	for clave := range guardadas {
+		claves = append(claves, clave)
+	}
+	slices.Sort(claves)
+
+	borrar := []int64{}
+	for _, clave := range claves {
+		detalles := guardadas[clave]
+		sobran := unidades(detalles) - pedidas[clave]
+		for i := len(detalles) - 1; i >= 0 && sobran > 0; i-- {
+			detalle := detalles[i]
+			if enCocina[detalle.PK_ID_DETALLE_PEDIDO] {
+				return &Error{
+					Code:    http.StatusConflict,
+					Message: fmt.Sprintf("La cocina ya empezó a preparar %s; no se puede quitar del pedido", detalle.NOMBRE),
+					Data:    detalle.Linea(),
+				}
+			}
+			quitar := min(sobran, detalle.CANTIDAD)
+			if quitar == detalle.CANTIDAD {
+				borrar = append(borrar, detalle.PK_ID_DETALLE_PEDIDO)
+			} else if err := tx.DetallesPedido().UpdateCantidad(detalle.PK_ID_DETALLE_PEDIDO, detalle.CANTIDAD-quitar); err != nil {
+				return internalError("Error al actualizar los productos del pedido", err)
+			}
+			if stockDescontado(pedido.ESTADO_PEDIDO) {
+				if err := moverStockPedido(tx, detalle.PK_ID_PRODUCTO, quitar, MotivoAjustePedido, pedido.PK_ID_PEDIDO, actor); err != nil {
+					return err
+				}
+			}
+			detalles[i].CANTIDAD -= quitar
+			sobran -= quitar
+		}
+	}
+	if err := tx.DetallesPedido().Delete(borrar); err != nil {
+		return internalError("Error al actualizar los productos del pedido", err)
+	}
+	return nil
+}
+
+// claveLinea identifica las unidades de un producto con las mismas notas y opciones
+func claveLinea(productoID int64, notas string, opciones []int64) string {
+	ordenadas := slices.Clone(opciones)
+	slices.Sort(ordenadas)
+	return fmt.Sprintf("%d|%s|%v", productoID, strings.TrimSpace(notas), ordenadas)
+}
+
+// claveDetalle es la claveLinea de una línea guardada
+func claveDetalle(detalle models.DetallePedido) string {
+	notas := ""
+	if detalle.NOTAS != nil {
+		notas = *detalle.NOTAS
+	}
+	opciones := make([]int64, 0, len(detalle.MODIFICADORES))
+	for _, m := range detalle.MODIFICADORES {
+		opciones = append(opciones, m.PK_ID_OPCION_MODIFICADOR)
+	}
+	return claveLinea(detalle.PK_ID_PRODUCTO, notas, opciones)
+}
+
+// unidades suma las unidades de las líneas
+func unidades(detalles []models.DetallePedido) int {
+	total := 0
+	for _, detalle := range detalles {
+		total += detalle.CANTIDAD
+	}
+	return total
+}
+
+// agregarLineas guarda las líneas cotizadas en DETALLE_PEDIDO y recalcula los totales del pedido.
+// Si el pedido ya está confirmado sus unidades salen del inventario y si está en preparación las
+// líneas nuevas van a la cocina.
+func agregarLineas(tx repositories.Store, pedidoID int, lineas []models.LineaProducto, actor Actor) error {
+	detalles := make([]models.DetallePedido, 0, len(lineas))
+	for _, linea := range lineas {
+		detalle := models.DetallePedido{
+			PK_ID_PEDIDO:       pedidoID,
+			PK_ID_PRODUCTO:     linea.PK_ID_PRODUCTO,
+			NOMBRE:             linea.NOMBRE,
+			CANTIDAD:           linea.CANTIDAD,
+			PRECIO_UNITARIO:    linea.PRECIO_UNITARIO,
+			CATEGORIA_IMPUESTO: linea.CATEGORIA_IMPUESTO,
+			TARIFA_IMPUESTO:    linea.TARIFA_IMPUESTO,
+		}
+		if linea.NOTAS != "" {
+			notas := linea.NOTAS
+			detalle.NOTAS = &notas
+		}
+		for _, m := range linea.MODIFICADORES {
+			detalle.MODIFICADORES = append(detalle.MODIFICADORES, models.DetallePedidoModificador{
+				PK_ID_OPCION_MODIFICADOR: m.PK_ID_OPCION_MODIFICADOR,
+				GRUPO:                    m.GRUPO,
+				NOMBRE:                   m.NOMBRE,
+				PRECIO_ADICIONAL:         m.PRECIO_ADICIONAL,
+			})
+		}
+		detalles = append(detalles, detalle)
+	}
+	if err := tx.DetallesPedido().Insert(detalles); err != nil {
+		return internalError("Error al registrar los productos del pedido", err)
+	}
+
+	pedido, err := recalcularTotales(tx, pedidoID)
+	if err != nil {
+		return err
+	}
+	if stockDescontado(pedido.ESTADO_PEDIDO) {
+		if err := descontarLineas(tx, pedidoID, lineas, actor); err != nil {
+			return err
+		}
+	}
+	if pedido.ESTADO_PEDIDO == EstadoEnPreparacion {
+		return enviarACocina(tx, pedidoID)
+	}
+	return nil
+}
+
+// lineasPedido devuelve los productos del pedido con el formato de DETALLES_PRODUCTOS
+func lineasPedido(store repositories.Store, pedidoID int) ([]models.LineaProducto, error) {
+	detalles, err := store.DetallesPedido().ListByPedido(pedidoID)
+	if err != nil {
+		return nil, internalError("Error al obtener los productos del pedido", err)
+	}
+
+	lineas := make([]models.LineaProducto, 0, len(detalles))
+	for _, detalle := range detalles {
+		lineas = append(lineas, detalle.Linea())
+	}
+	return lineas, nil
+}
+
+// productosJSON serializa las líneas del pedido como el arreglo PRODUCTOS de los detalles
+func productosJSON(lineas []models.LineaProducto) (string, error) {
+	productos, err := json.Marshal(lineas)
+	if err != nil {
+		return "", internalError("Error al procesar los productos del pedido", err)
+	}
+	return string(productos), nil
+}
+
+// lineasEditables responde 409 si el pedido ya no admite cambios en sus productos: además de los
+// terminados, los que ya salieron de la cocina (LISTO o EN CAMINO) y los PAGADO, cuyo total ya se
+// cobró completo
+func lineasEditables(pedido *models.Pedido) error {
+	if pedido.ESTADO_PEDIDO == EstadoPagado {
+		return &Error{
+			Code:    http.StatusConflict,
+			Message: "Un pedido PAGADO ya se cobró y no admite cambios en sus productos; registre los nuevos en otro pedido",
+			Data:    pedido,
+		}
+	}
+	if pedido.ESTADO_PEDIDO == EstadoListo || pedido.ESTADO_PEDIDO == EstadoEnCamino {
+		return &Error{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("Un pedido %s ya salió de la cocina y no admite cambios en sus productos", pedido.ESTADO_PEDIDO),
+			Data:    pedido,
+		}
+	}
+	return pedidoAbierto(pedido)
+}
+
+// pedidoAbierto responde 409 si el pedido ya terminó y no admite cambios en sus productos ni en
+// su cuenta
+func pedidoAbierto(pedido *models.Pedido) error {
+	if pedido.ESTADO_PEDIDO == EstadoEntregado || pedido.ESTADO_PEDIDO == EstadoCancelado {
+		return &Error{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("Un pedido %s no admite cambios en sus productos ni en su cuenta", pedido.ESTADO_PEDIDO),
+			Data:    pedido,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"restaurante/models"
+	"restaurante/repositories"
+)
+
+// ProductoService concentra la lógica del catálogo de productos
+type ProductoService struct {
+	store repositories.Store
+}
+
+func NewProductoService(store repositories.Store) *ProductoService {
+	return &ProductoService{store: store}
+}
+
+// List devuelve los productos; con onlyActive sólo los que están DISPONIBLE
+func (s *ProductoService) List(onlyActive bool) ([]models.Producto, error) {
+	productos, err := s.store.Productos().List(onlyActive)
+	if err != nil {
+		return nil, internalError("Error al obtener productos de la base de datos", err)
+	}
+	return productos, nil
+}
+
+// GetByID busca un producto por su identificador
+func (s *ProductoService) GetByID(id int64) (*models.Producto, error) {
+	producto, err := s.store.Productos().Get(id)
+	if err != nil {
+		return nil, lookup(err, notFound("Producto no encontrado"))
+	}
+	return producto, nil
+}
+
+// Create registra un producto nuevo y, si controla stock, anota su inventario inicial y sin unidades
+// queda NO DISPONIBLE. Sin categoría de impuesto queda EXENTO y sin estación se prepara en la COCINA general.
+func (s *ProductoService) Create(producto *models.Producto) error {
+	if producto.CONTROLA_STOCK && producto.CANTIDAD <= 0 {
+		producto.ESTADO_PRODUCTO = "NO DISPONIBLE"
+	}
+	return s.store.Transaction(func(tx repositories.Store) error {
+		if err := validarCategoria(tx, producto); err != nil {
+			return err
+		}
+		if err := tx.Productos().Insert(producto); err != nil {
+			return internalError("Error al crear el producto", err)
+		}
+		if producto.CONTROLA_STOCK && producto.CANTIDAD > 0 {
+			_, err := anotarMovimiento(tx, producto, producto.CANTIDAD, MotivoInventarioInicial, nil, Actor{}, "")
+			return err
+		}
+		return nil
+	})
+}
+
+// Update guarda el producto si nadie lo modificó desde la versión indicada. En un producto que
+// controla stock un cambio en CANTIDAD queda en el libro de movimientos como AJUSTE_MANUAL.
+func (s *ProductoService) Update(producto *models.Producto, version int) error {
+	return s.store.Transaction(func(tx repositories.Store) error {
+		actual, err := tx.Productos().Get(producto.PK_ID_PRODUCTO)
+		if err != nil {
+			return lookup(err, notFound("Producto no encontrado"))
+		}
+		if err := validarCategoria(tx, producto); err != nil {
+			return err
+		}
+		if producto.CONTROLA_STOCK {
+			ajustarDisponibilidad(producto, actual.CANTIDAD)
+		}
+		if err := NewProductoService(tx).save(producto, version); err != nil {
+			return err
+		}
+		if delta := producto.CANTIDAD - actual.CANTIDAD; producto.CONTROLA_STOCK && delta != 0 {
+			_, err := anotarMovimiento(tx, producto, delta, MotivoAjusteManual, nil, Actor{}, "Edición del producto")
+			return err
+		}
+		return nil
+	})
+}
+
+// Deactivate hace el borrado lógico del producto marcándolo como NO DISPONIBLE
+func (s *ProductoService) Deactivate(id int64) (*models.Producto, error) {
+	producto, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if producto.ESTADO_PRODUCTO == "NO DISPONIBLE" {
+		return nil, badRequest("El producto ya está desactivado.")
+	}
+
+	producto.ESTADO_PRODUCTO = "NO DISPONIBLE"
+	if err := s.save(producto, producto.VERSION, "ESTADO_PRODUCTO"); err != nil {
+		return nil, err
+	}
+	return producto, nil
+}
+
+// validarCategoria normaliza la categoría de impuesto y la estación del producto y comprueba que la
+// categoría tenga regla
+func validarCategoria(tx repositories.Store, producto *models.Producto) error {
+	producto.CATEGORIA_IMPUESTO = categoriaProducto(producto)
+	producto.ESTACION = estacionProducto(producto)
+	_, err := reglaImpuesto(tx, producto.CATEGORIA_IMPUESTO)
+	return err
+}
+
+// ajustarDisponibilidad aplica a una edición la misma regla que el ajuste de stock: sin unidades
+// el producto queda NO DISPONIBLE y vuelve a DISPONIBLE cuando se repone desde cero
+func ajustarDisponibilidad(producto *models.Producto, anterior int) {
+	switch {
+	case producto.CANTIDAD <= 0:
+		producto.ESTADO_PRODUCTO = "NO DISPONIBLE"
+	case anterior <= 0:
+		producto.ESTADO_PRODUCTO = "DISPONIBLE"
+	}
+}
+
+// save guarda el producto si sigue en la versión indicada; en un conflicto responde 409 con el actual
+func (s *ProductoService) save(producto *models.Producto, version int, cols ...string) error {
+	err := s.store.Productos().Update(producto, version, cols...)
+	return saveError(err, func() (*models.Producto, error) {
+		return s.store.Productos().Get(producto.PK_ID_PRODUCTO)
+	})
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// formatoProgramado es el formato en que se reciben y se muestran las horas programadas
+const formatoProgramado = "02-01-2006 15:04:05"
+
+// anticipacionProgramados son los minutos antes de la hora programada en que el pedido entra a la cocina
+func anticipacionProgramados() int {
+	return web.AppConfig.DefaultInt("programados_anticipacion_minutos", 45)
+}
+
+// diasProgramados es lo más lejos que se puede programar un pedido
+func diasProgramados() int {
+	return web.AppConfig.DefaultInt("programados_dias_maximos", 7)
+}
+
+// diaSemana traduce el nombre de un día, con o sin tilde, con los mismos nombres de diasSemana
+func diaSemana(nombre string) (time.Weekday, bool) {
+	nombre = strings.ToUpper(strings.NewReplacer("á", "a", "é", "e", "Á", "A", "É", "E").Replace(strings.TrimSpace(nombre)))
+	for dia, valido := range diasSemana {
+		if nombre == valido {
+			return time.Weekday(dia), true
+		}
+	}
+	return 0, false
+}
+
+// horario es la franja en que el restaurante atiende un día, en tiempo desde la medianoche
+type horario struct {
+	abierto  bool
+	apertura time.Duration
+	cierre   time.Duration
+}
+
+func (h horario) atiende(hora time.Duration) bool {
+	return h.abierto && hora >= h.apertura && hora < h.cierre
+}
+
+// parseProgramado interpreta una hora programada en formato DD-MM-YYYY HH:mm:ss en la hora de Bogotá
+func parseProgramado(texto string) (time.Time, error) {
+	programado, err := time.ParseInLocation(formatoProgramado, strings.TrimSpace(texto), database.BogotaZone)
+	if err != nil {
+		return time.Time{}, newError(http.StatusBadRequest, "Formato de PROGRAMADO_PARA inválido, debe ser DD-MM-YYYY HH:mm:ss", err)
+	}
+	return programado, nil
+}
+
+// liberacion es el momento en que un pedido programado entra a la cocina
+func liberacion(pedido *models.Pedido) time.Time {
+	return pedido.PROGRAMADO_PARA.Add(-time.Duration(anticipacionProgramados()) * time.Minute)
+}
+
+// retenido indica si el pedido está programado y todavía no le toca entrar a la cocina
+func retenido(pedido *models.Pedido, ahora time.Time) bool {
+	if pedido.PROGRAMADO_PARA == nil {
+		return false
+	}
+	if pedido.ESTADO_PEDIDO != EstadoIniciado && pedido.ESTADO_PEDIDO != EstadoPagado {
+		return false
+	}
+	return ahora.Before(liberacion(pedido))
+}
+
+// pedidoRetenido responde 409 cuando se intenta llevar a la cocina un pedido programado antes de tiempo
+func pedidoRetenido(pedido *models.Pedido) *Error {
+	return &Error{
+		Code: http.StatusConflict,
+		Message: fmt.Sprintf("El pedido está programado para el %s y entra a la cocina a partir del %s",
+			pedido.PROGRAMADO_PARA.Format(formatoProgramado), liberacion(pedido).Format(formatoProgramado)),
+		Data: pedido,
+	}
+}
+
+// validarProgramacion revisa que la hora programada quede después de la anticipación, dentro de
+// los días permitidos y en el horario del restaurante para ese día
+func validarProgramacion(tx repositories.Store, pedido *models.Pedido, programado, ahora time.Time) error {
+	minimo := ahora.Add(time.Duration(anticipacionProgramados()) * time.Minute)
+	if !programado.After(minimo) {
+		return unprocessable(fmt.Sprintf("Un pedido programado debe ser para después del %s; para antes haga un pedido inmediato",
+			minimo.Format(formatoProgramado)))
+	}
+	if programado.After(ahora.AddDate(0, 0, diasProgramados())) {
+		return unprocessable(fmt.Sprintf("Los pedidos se pueden programar con máximo %d días de anticipación", diasProgramados()))
+	}
+
+	h, err := horarioDelDia(tx, pedido.PK_ID_RESTAURANTE, programado)
+	if err != nil {
+		return err
+	}
+	hora := time.Duration(programado.Hour())*time.Hour + time.Duration(programado.Minute())*time.Minute + time.Duration(programado.Second())*time.Second
+	if !h.abierto {
+		return unprocessable(fmt.Sprintf("El restaurante no atiende el %s", programado.Format("02-01-2006")))
+	}
+	if !h.atiende(hora) {
+		return unprocessable(fmt.Sprintf("El %s el restaurante atiende de %s a %s", programado.Format("02-01-2006"),
+			formatoHora(h.apertura), formatoHora(h.cierre)))
+	}
+	return nil
+}
+
+// horarioDelDia arma el horario del restaurante para la fecha: un cambio de horario de ese día
+// manda sobre el horario habitual, y sus horas vacías se toman del habitual. Sin restaurante
+// registrado se atiende todo el día.
+func horarioDelDia(tx repositories.Store, restauranteID *int, fecha time.Time) (horario, error) {
+	h := horario{abierto: true, cierre: 24 * time.Hour}
+	restaurante, err := restauranteDe(tx, restauranteID)
+	if err != nil {
+		return h, err
+	}
+	if restaurante != nil {
+		if h.apertura, err = horaDelDia(restaurante.HORA_APERTURA); err != nil {
+			return h, internalError("La hora de apertura del restaurante no es válida", err)
+		}
+		if restaurante.HORA_CIERRE != nil && *restaurante.HORA_CIERRE != "" {
+			if h.cierre, err = horaDelDia(*restaurante.HORA_CIERRE); err != nil {
+				return h, internalError("La hora de cierre del restaurante no es válida", err)
+			}
+		}
+		if dias := diasLaborales(restaurante.DIAS_LABORALES); dias != nil {
+			h.abierto = dias[fecha.Weekday()]
+		}
+	}
+
+	cambio, err := tx.CambiosHorario().GetByFecha(fecha)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return h, nil
+	}
+	if err != nil {
+		return h, internalError("Error al consultar los cambios de horario", err)
+	}
+	h.abierto = cambio.ABIERTO
+	if cambio.HORA_APERTURA != nil {
+		h.apertura = time.Duration(cambio.HORA_APERTURA.Hour())*time.Hour + time.Duration(cambio.HORA_APERTURA.Minute())*time.Minute
+	}
+	if cambio.HORA_CIERRE != nil {
+		h.cierre = time.Duration(cambio.HORA_CIERRE.Hour())*time.Hour + time.Duration(cambio.HORA_CIERRE.Minute())*time.Minute
+	}
+	return h, nil
+}
+
+// restauranteDe devuelve el restaurante del pedido o, si no indica uno, el primero registrado
+func restauranteDe(tx repositories.Store, restauranteID *int) (*models.Restaurante, error) {
+	if restauranteID != nil {
+		restaurante, err := tx.Restaurantes().Get(*restauranteID)
+		if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			return nil, internalError("Error al consultar el restaurante", err)
+		}
+		if restaurante != nil {
+			return restaurante, nil
+		}
+	}
+	restaurantes, err := tx.Restaurantes().List()
+	if err != nil {
+		return nil, internalError("Error al consultar el restaurante", err)
+	}
+	if len(restaurantes) == 0 {
+		return nil, nil
+	}
+	return &restaurantes[0], nil
+}
+
+// horaDelDia lee una hora HH:mm:ss; la base de datos puede devolverla con una fecha adelante
+func horaDelDia(texto string) (time.Duration, error) {
+	if len(texto) > 8 {
+		if i := strings.Index(texto, "T"); i >= 0 && len(texto) >= i+9 {
+			texto = texto[i+1 : i+9]
+		}
+	}
+	hora, err := time.Parse("15:04:05", texto)
+	if err != nil {
+		if hora, err = time.Parse("15:04", texto); err != nil {
+			return 0, err
+		}
+	}
+	return time.Duration(hora.Hour())*time.Hour + time.Duration(hora.Minute())*time.Minute + time.Duration(hora.Second())*time.Second, nil
+}
+
+func formatoHora(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// diasLaborales interpreta DIAS_LABORALES, que puede ser una lista JSON (["Lunes", "Martes"]),
+// un rango ("Lunes a Sábado") o una lista separada por comas. Devuelve nil cuando no se puede
+// interpretar, y en ese caso no se restringen los días.
+func diasLaborales(texto string) map[time.Weekday]bool {
+	partes := []string{}
+	if err := json.Unmarshal([]byte(texto), &partes); err != nil {
+		texto = strings.NewReplacer(" y ", ",", ";", ",").Replace(strings.ToLower(texto))
+		partes = strings.Split(texto, ",")
+	}
+
+	dias := map[time.Weekday]bool{}
+	for _, parte := range partes {
+		parte = strings.TrimSpace(parte)
+		if parte == "" {
+			continue
+		}
+		desde, hasta, rango := strings.Cut(strings.ToLower(parte), " a ")
+		if !rango {
+			desde, hasta, rango = strings.Cut(parte, "-")
+		}
+		inicio, ok := diaSemana(desde)
+		if !ok {
+			return nil
+		}
+		if !rango {
+			dias[inicio] = true
+			continue
+		}
+		fin, ok := diaSemana(hasta)
+		if !ok {
+			return nil
+		}
+		for dia := inicio; ; dia = (dia + 1) % 7 {
+			dias[dia] = true
+			if dia == fin {
+				break
+			}
+		}
+	}
+	if len(dias) == 0 {
+		return nil
+	}
+	return dias
+}
@@ -0,0 +1,511 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tipos de promoción
+const (
+	PromocionPorcentaje      = "PORCENTAJE"
+	PromocionValorFijo       = "VALOR_FIJO"
+	PromocionLlevaPaga       = "LLEVA_PAGA"
+	PromocionDomicilioGratis = "DOMICILIO_GRATIS"
+)
+
+// diasSemana son los nombres con los que se escriben los DIAS_SEMANA, en el orden de time.Weekday
+var diasSemana = [...]string{"DOMINGO", "LUNES", "MARTES", "MIERCOLES", "JUEVES", "VIERNES", "SABADO"}
+
+// PromocionService administra las promociones y los códigos de descuento
+type PromocionService struct {
+	store repositories.Store
+}
+
+func NewPromocionService(store repositories.Store) *PromocionService {
+	return &PromocionService{store: store}
+}
+
+// List devuelve las promociones. Los clientes solo ven las activas que se aplican sin código.
+func (s *PromocionService) List(soloActivas bool, actor Actor) ([]models.Promocion, error) {
+	esCliente := actor.Rol == RolCliente
+	promociones, err := s.store.Promociones().List(soloActivas || esCliente)
+	if err != nil {
+		return nil, internalError("Error al obtener las promociones", err)
+	}
+	if !esCliente {
+		return promociones, nil
+	}
+
+	visibles := []models.Promocion{}
+	for _, p := range promociones {
+		if p.CODIGO == nil {
+			visibles = append(visibles, p)
+		}
+	}
+	return visibles, nil
+}
+
+// Create registra una promoción; los clientes no pueden crearlas y un código repetido responde 409
+func (s *PromocionService) Create(promocion *models.Promocion, actor Actor) error {
+	if err := requierePersonal(actor, "administrar promociones"); err != nil {
+		return err
+	}
+	if err := validatePromocion(promocion); err != nil {
+		return err
+	}
+
+	return s.store.Transaction(func(tx repositories.Store) error {
+		if err := validateReferencias(tx, promocion); err != nil {
+			return err
+		}
+		if err := tx.Promociones().Insert(promocion); err != nil {
+			return internalError("Error al crear la promoción", err)
+		}
+		return nil
+	})
+}
+
+// Update reemplaza la configuración de una promoción. Los pedidos ya cobrados conservan el
+// descuento que recibieron; los abiertos toman el cambio la próxima vez que se recalculen.
+func (s *PromocionService) Update(promocion *models.Promocion, actor Actor) error {
+	if err := requierePersonal(actor, "administrar promociones"); err != nil {
+		return err
+	}
+	if err := validatePromocion(promocion); err != nil {
+		return err
+	}
+
+	return s.store.Transaction(func(tx repositories.Store) error {
+		if _, err := tx.Promociones().Get(promocion.PK_ID_PROMOCION); err != nil {
+			return lookup(err, notFound("Promoción no encontrada"))
+		}
+		if err := validateReferencias(tx, promocion); err != nil {
+			return err
+		}
+		if err := tx.Promociones().Update(promocion); err != nil {
+			return internalError("Error al actualizar la promoción", err)
+		}
+		return nil
+	})
+}
+
+// validatePromocion normaliza el código y los días y revisa que la regla tenga sentido para su tipo
+func validatePromocion(p *models.Promocion) error {
+	p.NOMBRE = strings.TrimSpace(p.NOMBRE)
+	p.TIPO = strings.ToUpper(strings.TrimSpace(p.TIPO))
+	if p.NOMBRE == "" || p.TIPO == "" {
+		return badRequest("Los campos NOMBRE y TIPO son obligatorios")
+	}
+	if p.CODIGO != nil {
+		codigo := normalizarCodigo(*p.CODIGO)
+		p.CODIGO = &codigo
+		if codigo == "" {
+			p.CODIGO = nil
+		}
+	}
+
+	switch p.TIPO {
+	case PromocionPorcentaje:
+		if p.VALOR < 1 || p.VALOR > 100 {
+			return badRequest("El VALOR de una promoción PORCENTAJE debe estar entre 1 y 100")
+		}
+	case PromocionValorFijo:
+		if p.VALOR <= 0 {
+			return badRequest("El VALOR de una promoción VALOR_FIJO debe ser mayor a cero")
+		}
+	case PromocionLlevaPaga:
+		if p.PK_ID_PRODUCTO == nil {
+			return badRequest("Una promoción LLEVA_PAGA debe indicar PK_ID_PRODUCTO")
+		}
+		if p.PAGA < 1 || p.LLEVA <= p.PAGA {
+			return badRequest("Una promoción LLEVA_PAGA necesita PAGA mayor a cero y LLEVA mayor que PAGA")
+		}
+	case PromocionDomicilioGratis:
+	default:
+		return badRequest(fmt.Sprintf("Tipo de promoción inválido: %s. Use PORCENTAJE, VALOR_FIJO, LLEVA_PAGA o DOMICILIO_GRATIS", p.TIPO))
+	}
+
+	if p.MINIMO_COMPRA < 0 || p.LIMITE_USOS < 0 || p.LIMITE_POR_CLIENTE < 0 {
+		return badRequest("MINIMO_COMPRA, LIMITE_USOS y LIMITE_POR_CLIENTE no pueden ser negativos")
+	}
+	if p.VIGENTE_DESDE != nil && p.VIGENTE_HASTA != nil && p.VIGENTE_HASTA.Before(*p.VIGENTE_DESDE) {
+		return badRequest("VIGENTE_HASTA no puede ser anterior a VIGENTE_DESDE")
+	}
+
+	dias, err := normalizarDias(p.DIAS_SEMANA)
+	if err != nil {
+		return err
+	}
+	p.DIAS_SEMANA = dias
+	return nil
+}
+
+// validateReferencias revisa contra la base de datos el producto y que el código no esté en uso
+func validateReferencias(tx repositories.Store, p *models.Promocion) error {
+	if p.PK_ID_PRODUCTO != nil {
+		if _, err := tx.Productos().Get(*p.PK_ID_PRODUCTO); err != nil {
+			return lookup(err, unprocessable("El producto indicado no existe"))
+		}
+	}
+	if p.CODIGO != nil {
+		existente, err := tx.Promociones().GetByCodigo(*p.CODIGO)
+		if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			return internalError("Error al consultar el código de promoción", err)
+		}
+		if existente != nil && existente.PK_ID_PROMOCION != p.PK_ID_PROMOCION {
+			return &Error{Code: http.StatusConflict, Message: fmt.Sprintf("El código %s ya está en uso", *p.CODIGO), Data: existente}
+		}
+	}
+	return nil
+}
+
+func normalizarCodigo(codigo string) string {
+	return strings.ToUpper(strings.TrimSpace(codigo))
+}
+
+// normalizarDias deja los días en mayúsculas, sin tildes y separados por coma
+func normalizarDias(dias string) (string, error) {
+	if strings.TrimSpace(dias) == "" {
+		return "", nil
+	}
+	sinTildes := strings.NewReplacer("Á", "A", "É", "E", "á", "A", "é", "E")
+	var normalizados []string
+	for _, dia := range strings.Split(dias, ",") {
+		dia = strings.ToUpper(sinTildes.Replace(strings.TrimSpace(dia)))
+		valido := false
+		for _, nombre := range diasSemana {
+			valido = valido || dia == nombre
+		}
+		if !valido {
+			return "", badRequest(fmt.Sprintf("Día de la semana inválido en DIAS_SEMANA: %s", dia))
+		}
+		normalizados = append(normalizados, dia)
+	}
+	return strings.Join(normalizados, ","), nil
+}
+
+// aplicarCodigo registra el código en el pedido y lo liquida. Si el código no existe o su promoción
+// no queda entre las aplicadas responde 422 con el motivo; un código vacío lo quita del pedido.
+func aplicarCodigo(tx repositories.Store, pedido *models.Pedido, codigo string) error {
+	codigo = normalizarCodigo(codigo)
+	if codigo == "" {
+		pedido.CODIGO_PROMOCION = nil
+		_, err := liquidar(tx, pedido)
+		return err
+	}
+	promocion, err := tx.Promociones().GetByCodigo(codigo)
+	if err != nil {
+		return lookup(err, unprocessable(fmt.Sprintf("El código de promoción %s no existe", codigo)))
+	}
+
+	pedido.CODIGO_PROMOCION = &codigo
+	aplicadas, err := liquidar(tx, pedido)
+	if err != nil {
+		return err
+	}
+	for _, a := range aplicadas {
+		if a.PK_ID_PROMOCION == promocion.PK_ID_PROMOCION {
+			return nil
+		}
+	}
+
+	cliente, err := clientePedido(tx, pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return err
+	}
+	motivo, err := motivoNoAplica(tx, promocion, pedido, cliente, time.Now().In(database.BogotaZone))
+	if err != nil {
+		return err
+	}
+	if motivo == "" && promocion.TIPO == PromocionDomicilioGratis && valorDomicilio() == 0 {
+		motivo = "el restaurante no cobra el domicilio (valor_domicilio es 0), así que no hay nada que descontar"
+	}
+	if motivo == "" {
+		detalles, err := tx.DetallesPedido().ListByPedido(pedido.PK_ID_PEDIDO)
+		if err != nil {
+			return internalError("Error al obtener los productos del pedido", err)
+		}
+		motivo = "no es acumulable con las promociones del pedido"
+		if calcularPromocion(promocion, detalles, valoresLineas(detalles)).valor == 0 {
+			motivo = "no genera descuento en este pedido"
+		}
+	}
+	return unprocessable(fmt.Sprintf("La promoción %s no aplica: %s", promocion.NOMBRE, motivo))
+}
+
+// promocionAplicada es una promoción que aplica a un pedido y el descuento que le toca a cada línea
+type promocionAplicada struct {
+	promocion *models.Promocion
+	porLinea  []int64
+	valor     int64 // descuento total, incluido el domicilio que deja de cobrarse
+}
+
+// aplicarPromociones evalúa, en la hora de Bogotá, las promociones automáticas activas y la del
+// código del pedido sobre sus líneas y elige cuáles se aplican: la de mayor descuento y, si ella
+// es acumulable, las demás acumulables. Los descuentos de una línea nunca superan su valor. Las
+// promociones que el pedido ya tiene registradas se conservan aunque hayan vencido, se hayan
+// desactivado o agotado sus usos después, mientras el pedido siga cumpliendo sus condiciones.
+func aplicarPromociones(tx repositories.Store, pedido *models.Pedido, detalles []models.DetallePedido) ([]promocionAplicada, error) {
+	registradas, err := tx.Promociones().ListByPedido(pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return nil, internalError("Error al obtener las promociones del pedido", err)
+	}
+	conservadas := map[int64]bool{}
+	for _, r := range registradas {
+		conservadas[r.PK_ID_PROMOCION] = true
+	}
+	candidatas, err := promocionesCandidatas(tx, pedido, registradas)
+	if err != nil {
+		return nil, err
+	}
+	cliente, err := clientePedido(tx, pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(database.BogotaZone)
+	valores := valoresLineas(detalles)
+	var evaluadas []promocionAplicada
+	for i := range candidatas {
+		motivo := motivoPedido(&candidatas[i], pedido)
+		if !conservadas[candidatas[i].PK_ID_PROMOCION] {
+			if motivo, err = motivoNoAplica(tx, &candidatas[i], pedido, cliente, now); err != nil {
+				return nil, err
+			}
+		}
+		if motivo != "" {
+			continue
+		}
+		if aplicada := calcularPromocion(&candidatas[i], detalles, valores); aplicada.valor > 0 {
+			evaluadas = append(evaluadas, aplicada)
+		}
+	}
+	if len(evaluadas) == 0 {
+		return nil, nil
+	}
+
+	sort.SliceStable(evaluadas, func(i, j int) bool { return evaluadas[i].valor > evaluadas[j].valor })
+	elegidas := evaluadas[:1]
+	if evaluadas[0].promocion.ACUMULABLE {
+		for _, a := range evaluadas[1:] {
+			if a.promocion.ACUMULABLE {
+				elegidas = append(elegidas, a)
+			}
+		}
+	}
+
+	// Cada promoción acumulada descuenta sobre lo que dejaron las anteriores
+	for k := range elegidas {
+		elegidas[k] = calcularPromocion(elegidas[k].promocion, detalles, valores)
+		for i, v := range elegidas[k].porLinea {
+			valores[i] -= v
+		}
+	}
+	return elegidas, nil
+}
+
+// valoresLineas es el valor antes de descuentos de cada línea
+func valoresLineas(detalles []models.DetallePedido) []int64 {
+	valores := make([]int64, len(detalles))
+	for i, d := range detalles {
+		valores[i] = d.PRECIO_UNITARIO * int64(d.CANTIDAD)
+	}
+	return valores
+}
+
+// promocionesCandidatas son las promociones activas sin código más la del código del pedido, y
+// las que el pedido ya tiene registradas aunque ya no estén activas; las de un código que se quitó
+// del pedido dejan de ser candidatas
+func promocionesCandidatas(tx repositories.Store, pedido *models.Pedido, registradas []models.PedidoPromocion) ([]models.Promocion, error) {
+	activas, err := tx.Promociones().List(true)
+	if err != nil {
+		return nil, internalError("Error al obtener las promociones", err)
+	}
+
+	var candidatas []models.Promocion
+	vistas := map[int64]bool{}
+	for _, p := range activas {
+		if candidata(&p, pedido) {
+			candidatas = append(candidatas, p)
+			vistas[p.PK_ID_PROMOCION] = true
+		}
+	}
+	for _, r := range registradas {
+		if vistas[r.PK_ID_PROMOCION] {
+			continue
+		}
+		p, err := tx.Promociones().Get(r.PK_ID_PROMOCION)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, internalError("Error al obtener las promociones del pedido", err)
+		}
+		if candidata(p, pedido) {
+			candidatas = append(candidatas, *p)
+			vistas[p.PK_ID_PROMOCION] = true
+		}
+	}
+	return candidatas, nil
+}
+
+// candidata indica si la promoción se aplica sin código o con el código del pedido
+func candidata(p *models.Promocion, pedido *models.Pedido) bool {
+	return p.CODIGO == nil || (pedido.CODIGO_PROMOCION != nil && *p.CODIGO == *pedido.CODIGO_PROMOCION)
+}
+
+// clientePedido devuelve el documento del cliente asociado al pedido o nil si no tiene
+func clientePedido(tx repositories.Store, pedidoID int) (*int64, error) {
+	relacion, err := tx.PedidosClientes().GetByPedido(pedidoID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, internalError("Error al consultar el cliente del pedido", err)
+	}
+	return relacion.PK_DOCUMENTO_CLIENTE, nil
+}
+
+// motivoNoAplica explica por qué la promoción no aplica al pedido en el momento now, o devuelve
+// una cadena vacía si aplica
+func motivoNoAplica(tx repositories.Store, p *models.Promocion, pedido *models.Pedido, cliente *int64, now time.Time) (string, error) {
+	switch {
+	case !p.ACTIVA:
+		return "no está activa", nil
+	case p.VIGENTE_DESDE != nil && now.Before(*p.VIGENTE_DESDE):
+		return "aún no está vigente", nil
+	case p.VIGENTE_HASTA != nil && now.After(*p.VIGENTE_HASTA):
+		return "ya no está vigente", nil
+	case p.DIAS_SEMANA != "" && !strings.Contains(","+p.DIAS_SEMANA+",", ","+diasSemana[now.Weekday()]+","):
+		return fmt.Sprintf("solo aplica los días %s", p.DIAS_SEMANA), nil
+	}
+	if motivo := motivoPedido(p, pedido); motivo != "" {
+		return motivo, nil
+	}
+
+	if p.LIMITE_USOS > 0 || p.LIMITE_POR_CLIENTE > 0 {
+		// Los usos se cuentan con la promoción bloqueada hasta guardar el pedido; si no, dos pedidos
+		// simultáneos podrían tomar el último uso disponible
+		if err := tx.Promociones().Bloquear(p.PK_ID_PROMOCION); err != nil {
+			return "", internalError("Error al reservar la promoción", err)
+		}
+	}
+	if p.LIMITE_USOS > 0 {
+		usos, err := tx.Promociones().ContarUsos(p.PK_ID_PROMOCION, nil, pedido.PK_ID_PEDIDO)
+		if err != nil {
+			return "", internalError("Error al contar los usos de la promoción", err)
+		}
+		if usos >= p.LIMITE_USOS {
+			return "ya se agotaron sus usos", nil
+		}
+	}
+	if p.LIMITE_POR_CLIENTE > 0 {
+		if cliente == nil {
+			return "requiere un cliente asociado al pedido", nil
+		}
+		usos, err := tx.Promociones().ContarUsos(p.PK_ID_PROMOCION, cliente, pedido.PK_ID_PEDIDO)
+		if err != nil {
+			return "", internalError("Error al contar los usos de la promoción", err)
+		}
+		if usos >= p.LIMITE_POR_CLIENTE {
+			return "el cliente ya la usó el máximo de veces permitido", nil
+		}
+	}
+	return "", nil
+}
+
+// motivoPedido explica qué le falta al pedido para la promoción, sin importar su vigencia ni sus
+// usos, o devuelve una cadena vacía si la cumple
+func motivoPedido(p *models.Promocion, pedido *models.Pedido) string {
+	switch {
+	case pedido.SUBTOTAL < p.MINIMO_COMPRA:
+		return fmt.Sprintf("requiere una compra mínima de %d", p.MINIMO_COMPRA)
+	case p.TIPO == PromocionDomicilioGratis && !esDomicilio(pedido):
+		return "solo aplica a los pedidos con domicilio"
+	}
+	return ""
+}
+
+// calcularPromocion reparte el descuento de la promoción entre las líneas a las que aplica (todas
+// o solo las de su producto) sin pasar de lo que aún queda por cobrar de cada una en valores
+func calcularPromocion(p *models.Promocion, detalles []models.DetallePedido, valores []int64) promocionAplicada {
+	aplicada := promocionAplicada{promocion: p, porLinea: make([]int64, len(detalles))}
+	bases := make([]int64, len(detalles))
+	for i, d := range detalles {
+		if p.PK_ID_PRODUCTO == nil || *p.PK_ID_PRODUCTO == d.PK_ID_PRODUCTO {
+			bases[i] = valores[i]
+		}
+	}
+
+	switch p.TIPO {
+	case PromocionPorcentaje:
+		for i, base := range bases {
+			aplicada.porLinea[i] = models.ValorImpuesto(base, int(p.VALOR))
+		}
+	case PromocionValorFijo:
+		var total int64
+		for _, base := range bases {
+			total += base
+		}
+		aplicada.porLinea = repartir(min(p.VALOR, total), bases)
+	case PromocionLlevaPaga:
+		// Las unidades gratis son las más baratas del producto
+		unidades := 0
+		orden := make([]int, 0, len(detalles))
+		for i, d := range detalles {
+			if bases[i] > 0 {
+				unidades += d.CANTIDAD
+				orden = append(orden, i)
+			}
+		}
+		sort.SliceStable(orden, func(a, b int) bool { return detalles[orden[a]].PRECIO_UNITARIO < detalles[orden[b]].PRECIO_UNITARIO })
+		gratis := unidades / p.LLEVA * (p.LLEVA - p.PAGA)
+		for _, i := range orden {
+			n := min(gratis, detalles[i].CANTIDAD)
+			aplicada.porLinea[i] = min(int64(n)*detalles[i].PRECIO_UNITARIO, bases[i])
+			gratis -= n
+		}
+	case PromocionDomicilioGratis:
+		aplicada.valor = valorDomicilio()
+		return aplicada
+	}
+
+	for _, v := range aplicada.porLinea {
+		aplicada.valor += v
+	}
+	return aplicada
+}
+
+// repartir divide total entre las bases en proporción a cada una; el redondeo se lo lleva la última
+func repartir(total int64, bases []int64) []int64 {
+	partes := make([]int64, len(bases))
+	var suma int64
+	ultima := -1
+	for i, base := range bases {
+		suma += base
+		if base > 0 {
+			ultima = i
+		}
+	}
+	if suma == 0 {
+		return partes
+	}
+
+	var asignado int64
+	for i, base := range bases {
+		if i == ultima {
+			partes[i] = total - asignado
+			break
+		}
+		partes[i] = total * base / suma
+		asignado += partes[i]
+	}
+	return partes
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// historialPedidos es cuántos pedidos anteriores se devuelven cuando no se indica un límite
+func historialPedidos() int {
+	return web.AppConfig.DefaultInt("historial_pedidos_cliente", 20)
+}
+
+// RecompraService le permite al cliente ver sus pedidos anteriores, guardar sus favoritos y
+// repetirlos con los precios vigentes
+type RecompraService struct {
+	store repositories.Store
+}
+
+func NewRecompraService(store repositories.Store) *RecompraService {
+	return &RecompraService{store: store}
+}
+
+// Historial devuelve los últimos pedidos del cliente, del más reciente al más antiguo, con los
+// productos tal como se cobraron. limite 0 usa historial_pedidos_cliente. Un cliente solo ve
+// su propio historial.
+func (s *RecompraService) Historial(documento int, limite int, actor Actor) ([]models.PedidoAnterior, error) {
+	if err := soloSusPedidos(documento, actor); err != nil {
+		return nil, err
+	}
+	if limite <= 0 {
+		limite = historialPedidos()
+	}
+	if _, err := s.store.Clientes().Get(documento); err != nil {
+		return nil, lookup(err, notFound("Cliente no encontrado"))
+	}
+
+	pedidos, err := pedidosDelCliente(s.store, documento, limite)
+	if err != nil {
+		return nil, err
+	}
+	favoritos, err := s.store.Favoritos().ListByCliente(int64(documento))
+	if err != nil {
+		return nil, internalError("Error al consultar los favoritos del cliente", err)
+	}
+	porPedido := map[int]int64{}
+	for _, favorito := range favoritos {
+		porPedido[favorito.PK_ID_PEDIDO] = favorito.PK_ID_FAVORITO
+	}
+
+	historial := make([]models.PedidoAnterior, 0, len(pedidos))
+	for _, pedido := range pedidos {
+		lineas, err := lineasPedido(s.store, pedido.PK_ID_PEDIDO)
+		if err != nil {
+			return nil, err
+		}
+		anterior := models.PedidoAnterior{PEDIDO: pedido, PRODUCTOS: lineas}
+		if id, ok := porPedido[pedido.PK_ID_PEDIDO]; ok {
+			anterior.PK_ID_FAVORITO = &id
+		}
+		historial = append(historial, anterior)
+	}
+	return historial, nil
+}
+
+// Favoritos devuelve los pedidos que el cliente guardó, del más reciente al más antiguo, con sus productos
+func (s *RecompraService) Favoritos(documento int, actor Actor) ([]models.PedidoFavorito, error) {
+	if err := soloSusPedidos(documento, actor); err != nil {
+		return nil, err
+	}
+	favoritos, err := s.store.Favoritos().ListByCliente(int64(documento))
+	if err != nil {
+		return nil, internalError("Error al consultar los favoritos del cliente", err)
+	}
+	for i := range favoritos {
+		if favoritos[i].PRODUCTOS, err = lineasPedido(s.store, favoritos[i].PK_ID_PEDIDO); err != nil {
+			return nil, err
+		}
+	}
+	return favoritos, nil
+}
+
+// CreateFavorito guarda como favorito un pedido del cliente. Los pedidos cancelados no se pueden
+// guardar y un pedido que ya es favorito responde 409.
+func (s *RecompraService) CreateFavorito(documento int, req *models.FavoritoRequest, actor Actor) (*models.PedidoFavorito, error) {
+	if err := soloSusPedidos(documento, actor); err != nil {
+		return nil, err
+	}
+	if req.PK_ID_PEDIDO <= 0 {
+		return nil, badRequest("El campo PK_ID_PEDIDO es obligatorio")
+	}
+
+	favorito := &models.PedidoFavorito{
+		PK_DOCUMENTO_CLIENTE: int64(documento),
+		PK_ID_PEDIDO:         req.PK_ID_PEDIDO,
+		NOMBRE:               strings.TrimSpace(req.NOMBRE),
+		CREADO:               time.Now().In(database.BogotaZone),
+	}
+	if favorito.NOMBRE == "" {
+		favorito.NOMBRE = fmt.Sprintf("Pedido %d", req.PK_ID_PEDIDO)
+	}
+
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		pedido, err := tx.Pedidos().Get(req.PK_ID_PEDIDO)
+		if err != nil {
+			return lookup(err, unprocessable("El pedido indicado no existe"))
+		}
+		cliente, err := clientePedido(tx, pedido.PK_ID_PEDIDO)
+		if err != nil {
+			return err
+		}
+		if cliente == nil || *cliente != int64(documento) {
+			return unprocessable("El pedido indicado no es del cliente")
+		}
+		if pedido.ESTADO_PEDIDO == EstadoCancelado {
+			return &Error{Code: http.StatusConflict, Message: "Un pedido cancelado no se puede guardar como favorito", Data: pedido}
+		}
+
+		existentes, err := tx.Favoritos().ListByCliente(int64(documento))
+		if err != nil {
+			return internalError("Error al consultar los favoritos del cliente", err)
+		}
+		for _, existente := range existentes {
+			if existente.PK_ID_PEDIDO == req.PK_ID_PEDIDO {
+				return &Error{Code: http.StatusConflict, Message: "El pedido ya está en los favoritos del cliente", Data: existente}
+			}
+		}
+		if err := tx.Favoritos().Insert(favorito); err != nil {
+			return internalError("Error al guardar el favorito", err)
+		}
+		favorito.PRODUCTOS, err = lineasPedido(tx, pedido.PK_ID_PEDIDO)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return favorito, nil
+}
+
+// DeleteFavorito quita un pedido de los favoritos del cliente; el pedido no cambia
+func (s *RecompraService) DeleteFavorito(documento int, id int64, actor Actor) error {
+	if err := soloSusPedidos(documento, actor); err != nil {
+		return err
+	}
+	return s.store.Transaction(func(tx repositories.Store) error {
+		favorito, err := tx.Favoritos().Get(id)
+		if err != nil {
+			return lookup(err, notFound("Favorito no encontrado"))
+		}
+		if favorito.PK_DOCUMENTO_CLIENTE != int64(documento) {
+			return notFound("Favorito no encontrado")
+		}
+		if err := tx.Favoritos().Delete(id); err != nil {
+			return internalError("Error al eliminar el favorito", err)
+		}
+		return nil
+	})
+}
+
+// Repetir crea un pedido nuevo con los productos de uno anterior, para el mismo cliente y, si era
+// a domicilio, a la misma dirección. Los productos se vuelven a cotizar con el precio vigente;
+// los que ya no existen, no están disponibles, perdieron alguna de sus opciones o no tienen
+// unidades suficientes quedan por fuera y se informan en NO_DISPONIBLES. Si no queda ninguno
+// responde 422 sin crear el pedido. Un cliente solo puede repetir sus propios pedidos.
+func (s *RecompraService) Repetir(pedidoID int, actor Actor) (*models.PedidoRepetido, error) {
+	respuesta := &models.PedidoRepetido{PK_ID_PEDIDO_ORIGEN: pedidoID, NO_DISPONIBLES: []models.ProductoNoDisponible{}}
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		origen, err := tx.Pedidos().Get(pedidoID)
+		if err != nil {
+			return lookup(err, notFound("Pedido no encontrado"))
+		}
+		cliente, err := clientePedido(tx, pedidoID)
+		if err != nil {
+			return err
+		}
+		if actor.Rol == RolCliente && (cliente == nil || *cliente != int64(actor.Documento)) {
+			return newError(http.StatusForbidden, "Solo puede repetir sus propios pedidos", nil)
+		}
+
+		detalles, err := tx.DetallesPedido().ListByPedido(pedidoID)
+		if err != nil {
+			return internalError("Error al consultar los productos del pedido", err)
+		}
+		if respuesta.PRODUCTOS, respuesta.NO_DISPONIBLES, err = recotizar(tx, detalles); err != nil {
+			return err
+		}
+		if len(respuesta.PRODUCTOS) == 0 {
+			return &Error{Code: http.StatusUnprocessableEntity, Message: "Ninguno de los productos del pedido está disponible", Data: respuesta.NO_DISPONIBLES}
+		}
+
+		pedidos := NewPedidoService(tx)
+		pedido := models.Pedido{DELIVERY: origen.DELIVERY, PK_ID_RESTAURANTE: origen.PK_ID_RESTAURANTE}
+		if err := pedidos.Create(&pedido, actor); err != nil {
+			return err
+		}
+		if cliente != nil {
+			relacion := models.PedidoCliente{PK_DOCUMENTO_CLIENTE: cliente, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO}
+			if err := tx.PedidosClientes().Insert(&relacion); err != nil {
+				return internalError("Error al asociar el pedido al cliente", err)
+			}
+		}
+		if err := agregarLineas(tx, pedido.PK_ID_PEDIDO, respuesta.PRODUCTOS, actor); err != nil {
+			return err
+		}
+
+		if origen.PK_ID_DOMICILIO != nil {
+			if respuesta.DOMICILIO, err = repetirDomicilio(tx, *origen.PK_ID_DOMICILIO); err != nil {
+				return err
+			}
+			if _, err := pedidos.AssignDomicilio(pedido.PK_ID_PEDIDO, respuesta.DOMICILIO.PK_ID_DOMICILIO, actor); err != nil {
+				return err
+			}
+		}
+
+		respuesta.PEDIDO, err = pedidos.GetByID(pedido.PK_ID_PEDIDO)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return respuesta, nil
+}
+
+// recotizar arma las líneas de un pedido nuevo con los productos de detalles al precio vigente y
+// separa los que ya no se pueden pedir con el motivo
+func recotizar(tx repositories.Store, detalles []models.DetallePedido) ([]models.LineaProducto, []models.ProductoNoDisponible, error) {
+	lineas := []models.LineaProducto{}
+	noDisponibles := []models.ProductoNoDisponible{}
+	reservadas := map[int64]int{}
+	for _, detalle := range detalles {
+		item := models.ItemPedido{PK_ID_PRODUCTO: detalle.PK_ID_PRODUCTO, CANTIDAD: detalle.CANTIDAD}
+		if detalle.NOTAS != nil {
+			item.NOTAS = *detalle.NOTAS
+		}
+		for _, m := range detalle.MODIFICADORES {
+			item.MODIFICADORES = append(item.MODIFICADORES, m.PK_ID_OPCION_MODIFICADOR)
+		}
+		noDisponible := models.ProductoNoDisponible{PK_ID_PRODUCTO: detalle.PK_ID_PRODUCTO, NOMBRE: detalle.NOMBRE, CANTIDAD: detalle.CANTIDAD}
+
+		cotizadas, err := cotizar(tx, []models.ItemPedido{item})
+		var serviceErr *Error
+		if errors.As(err, &serviceErr) && serviceErr.Code == http.StatusUnprocessableEntity {
+			noDisponible.MOTIVO = serviceErr.Message
+			noDisponibles = append(noDisponibles, noDisponible)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		producto, err := tx.Productos().Get(detalle.PK_ID_PRODUCTO)
+		if err != nil {
+			return nil, nil, internalError("Error al consultar el producto", err)
+		}
+		if quedan := producto.CANTIDAD - reservadas[producto.PK_ID_PRODUCTO]; producto.CONTROLA_STOCK && quedan < detalle.CANTIDAD {
+			noDisponible.MOTIVO = fmt.Sprintf("Solo quedan %d unidades de %s", max(quedan, 0), producto.NOMBRE)
+			noDisponibles = append(noDisponibles, noDisponible)
+			continue
+		}
+		reservadas[producto.PK_ID_PRODUCTO] += detalle.CANTIDAD
+		lineas = append(lineas, cotizadas...)
+	}
+	return lineas, noDisponibles, nil
+}
+
+// repetirDomicilio crea un domicilio nuevo, pendiente de pago, a la dirección de uno anterior
+func repetirDomicilio(tx repositories.Store, domicilioID int) (*models.Domicilio, error) {
+	anterior, err := tx.Domicilios().Get(domicilioID)
+	if err != nil {
+		return nil, internalError("Error al consultar el domicilio del pedido", err)
+	}
+	domicilio := models.Domicilio{
+		DIRECCION:     anterior.DIRECCION,
+		TELEFONO:      anterior.TELEFONO,
+		OBSERVACIONES: anterior.OBSERVACIONES,
+		DISTANCIA_KM:  anterior.DISTANCIA_KM,
+		ESTADO_PAGO:   "PENDIENTE",
+		FECHA:         time.Now().In(database.BogotaZone),
+	}
+	if err := tx.Domicilios().Insert(&domicilio); err != nil {
+		return nil, internalError("Error al crear el domicilio", err)
+	}
+	PublicarDomicilio(tx, &domicilio, AccionCreado)
+	return tx.Domicilios().Get(domicilio.PK_ID_DOMICILIO)
+}
+
+// pedidosDelCliente devuelve los últimos pedidos del cliente, del más reciente al más antiguo
+func pedidosDelCliente(store repositories.Store, documento, limite int) ([]models.Pedido, error) {
+	encontrados, err := store.Pedidos().Search(PedidoFiltros{Cliente: documento})
+	if err != nil {
+		return nil, internalError("Error al consultar los pedidos del cliente", err)
+	}
+	// Un pedido con la cuenta dividida puede aparecer una vez por cada relación con el cliente
+	pedidos := []models.Pedido{}
+	vistos := map[int]bool{}
+	for _, pedido := range encontrados {
+		if !vistos[pedido.PK_ID_PEDIDO] {
+			vistos[pedido.PK_ID_PEDIDO] = true
+			pedidos = append(pedidos, pedido)
+		}
+	}
+	sort.SliceStable(pedidos, func(i, j int) bool {
+		if !pedidos[i].FECHA.Equal(pedidos[j].FECHA) {
+			return pedidos[i].FECHA.After(pedidos[j].FECHA)
+		}
+		return pedidos[i].PK_ID_PEDIDO > pedidos[j].PK_ID_PEDIDO
+	})
+	if len(pedidos) > limite {
+		pedidos = pedidos[:limite]
+	}
+	return pedidos, nil
+}
+
+// soloSusPedidos impide que un cliente consulte o cambie el historial de otro
+func soloSusPedidos(documento int, actor Actor) error {
+	if actor.Rol == RolCliente && actor.Documento != documento {
+		return newError(http.StatusForbidden, "Un cliente solo puede consultar sus propios pedidos", nil)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"math"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"time"
+)
+
+// ReporteService arma los reportes de ventas, cancelaciones y tiempos de entrega del restaurante
+type ReporteService struct {
+	store repositories.Store
+}
+
+func NewReporteService(store repositories.Store) *ReporteService {
+	return &ReporteService{store: store}
+}
+
+// Ventas suma los pedidos no cancelados entre desde y hasta (YYYY-MM-DD, ambos incluidos) con el
+// desglose de impuestos por categoría, las propinas y los domicilios. Los clientes no tienen acceso.
+func (s *ReporteService) Ventas(desde, hasta string, actor Actor) (*models.ReporteVentas, error) {
+	if err := requierePersonal(actor, "consultar las ventas"); err != nil {
+		return nil, err
+	}
+	if _, _, err := rangoFechas(desde, hasta); err != nil {
+		return nil, err
+	}
+
+	pedidos, err := s.store.Pedidos().Search(PedidoFiltros{Desde: desde, Hasta: hasta})
+	if err != nil {
+		return nil, internalError("Error al obtener los pedidos del periodo", err)
+	}
+
+	reporte := models.ReporteVentas{DESDE: desde, HASTA: hasta, IMPUESTOS: []models.DesgloseImpuesto{}}
+	contados := map[int]bool{}
+	for _, pedido := range pedidos {
+		// La búsqueda puede repetir un pedido asociado a varios clientes
+		if pedido.ESTADO_PEDIDO == EstadoCancelado || contados[pedido.PK_ID_PEDIDO] {
+			continue
+		}
+		contados[pedido.PK_ID_PEDIDO] = true
+
+		reporte.PEDIDOS++
+		reporte.SUBTOTAL += pedido.SUBTOTAL
+		reporte.DESCUENTO += pedido.DESCUENTO
+		reporte.IMPUESTO += pedido.IMPUESTO
+		reporte.PROPINA += pedido.PROPINA
+		reporte.VALOR_DOMICILIO += pedido.VALOR_DOMICILIO
+		reporte.TOTAL += pedido.TOTAL
+
+		lineas, err := lineasPedido(s.store, pedido.PK_ID_PEDIDO)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range desgloseImpuestos(lineas) {
+			reporte.IMPUESTOS = sumarImpuesto(reporte.IMPUESTOS, item)
+		}
+	}
+	return &reporte, nil
+}
+
+// Cancelaciones resume las cancelaciones entre desde y hasta (YYYY-MM-DD, ambos incluidos): cuántas
+// hubo por motivo, el valor de los pedidos cancelados y lo reembolsado. Los clientes no tienen acceso.
+func (s *ReporteService) Cancelaciones(desde, hasta string, actor Actor) (*models.ReporteCancelaciones, error) {
+	if err := requierePersonal(actor, "consultar las cancelaciones"); err != nil {
+		return nil, err
+	}
+	inicio, fin, err := rangoFechas(desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelaciones, err := s.store.Cancelaciones().List(inicio, fin.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, internalError("Error al obtener las cancelaciones del periodo", err)
+	}
+
+	reporte := models.ReporteCancelaciones{DESDE: desde, HASTA: hasta, POR_MOTIVO: []models.CancelacionMotivo{}, DETALLE: cancelaciones}
+	porMotivo := map[string]*models.CancelacionMotivo{}
+	for i := range cancelaciones {
+		cancelacion := &cancelaciones[i]
+		if cancelacion.REEMBOLSOS, err = s.store.Reembolsos().ListByPedido(cancelacion.PK_ID_PEDIDO); err != nil {
+			return nil, internalError("Error al consultar los reembolsos de la cancelación", err)
+		}
+		for _, reembolso := range cancelacion.REEMBOLSOS {
+			reporte.REEMBOLSADO += reembolso.MONTO
+		}
+		reporte.CANCELACIONES++
+		reporte.VALOR += cancelacion.TOTAL
+
+		item, ok := porMotivo[cancelacion.MOTIVO]
+		if !ok {
+			item = &models.CancelacionMotivo{MOTIVO: cancelacion.MOTIVO}
+			porMotivo[cancelacion.MOTIVO] = item
+		}
+		item.CANTIDAD++
+		item.VALOR += cancelacion.TOTAL
+	}
+	for _, motivo := range MotivosCancelacion {
+		if item, ok := porMotivo[motivo]; ok {
+			reporte.POR_MOTIVO = append(reporte.POR_MOTIVO, *item)
+		}
+	}
+	return &reporte, nil
+}
+
+// Tiempos compara los minutos estimados de preparación y de entrega con los reales de los pedidos
+// que entraron a la cocina entre desde y hasta (YYYY-MM-DD, ambos incluidos). Las desviaciones
+// positivas indican que el restaurante tarda más de lo que promete. Los clientes no tienen acceso.
+func (s *ReporteService) Tiempos(desde, hasta string, actor Actor) (*models.ReporteTiempos, error) {
+	if err := requierePersonal(actor, "consultar los tiempos"); err != nil {
+		return nil, err
+	}
+	inicio, fin, err := rangoFechas(desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+
+	tiempos, err := s.store.TiemposPedido().List(inicio, fin.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, internalError("Error al obtener los tiempos del periodo", err)
+	}
+
+	reporte := models.ReporteTiempos{DESDE: desde, HASTA: hasta, PEDIDOS: len(tiempos), DETALLE: tiempos}
+	var preparados, entregados int
+	var prepEstimada, prepReal, entregaEstimada, entregaReal int
+	for _, tiempo := range tiempos {
+		if tiempo.PREPARACION_REAL != nil {
+			preparados++
+			prepEstimada += tiempo.PREPARACION_ESTIMADA
+			prepReal += *tiempo.PREPARACION_REAL
+		}
+		// Solo los domicilios tienen tiempo de entrega estimado
+		if tiempo.ENTREGA_REAL != nil && tiempo.ENTREGA_ESTIMADA > 0 {
+			entregados++
+			entregaEstimada += tiempo.ENTREGA_ESTIMADA
+			entregaReal += *tiempo.ENTREGA_REAL
+		}
+	}
+	reporte.PREPARACION_ESTIMADA = promedio(prepEstimada, preparados)
+	reporte.PREPARACION_REAL = promedio(prepReal, preparados)
+	reporte.DESVIACION_PREPARACION = reporte.PREPARACION_REAL - reporte.PREPARACION_ESTIMADA
+	reporte.ENTREGA_ESTIMADA = promedio(entregaEstimada, entregados)
+	reporte.ENTREGA_REAL = promedio(entregaReal, entregados)
+	reporte.DESVIACION_ENTREGA = reporte.ENTREGA_REAL - reporte.ENTREGA_ESTIMADA
+	return &reporte, nil
+}
+
+// promedio redondea a un decimal; sin registros es 0
+func promedio(total, cantidad int) float64 {
+	if cantidad == 0 {
+		return 0
+	}
+	return math.Round(float64(total)/float64(cantidad)*10) / 10
+}
+
+// rangoFechas valida las fechas desde y hasta (YYYY-MM-DD) de un reporte y las devuelve en la hora
+// de Bogotá
+func rangoFechas(desde, hasta string) (time.Time, time.Time, error) {
+	inicio, err := time.ParseInLocation("2006-01-02", desde, database.BogotaZone)
+	if err != nil {
+		return time.Time{}, time.Time{}, newError(http.StatusBadRequest, "El parámetro 'desde' es obligatorio con formato YYYY-MM-DD", err)
+	}
+	fin, err := time.ParseInLocation("2006-01-02", hasta, database.BogotaZone)
+	if err != nil {
+		return time.Time{}, time.Time{}, newError(http.StatusBadRequest, "El parámetro 'hasta' es obligatorio con formato YYYY-MM-DD", err)
+	}
+	if fin.Before(inicio) {
+		return time.Time{}, time.Time{}, badRequest("La fecha 'hasta' no puede ser anterior a 'desde'")
+	}
+	return inicio, fin, nil
+}
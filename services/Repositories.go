@@ -0,0 +1,41 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"restaurante/repositories"
+)
+
+// lookup traduce el error de una lectura del repositorio: devuelve missing si el registro no
+// existe (404 para el recurso solicitado, 422 para uno referenciado en la solicitud)
+func lookup(err error, missing *Error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, repositories.ErrNotFound) {
+		return missing
+	}
+	return internalError("Error al consultar la base de datos", err)
+}
+
+// saveError traduce el error de una actualización versionada. Si otro usuario modificó el
+// registro responde 409 con la representación actual que devuelve reload.
+func saveError[T any](err error, reload func() (T, error)) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repositories.ErrVersionConflict):
+		current, rerr := reload()
+		if errors.Is(rerr, repositories.ErrNotFound) {
+			return notFound("El registro ya no existe")
+		}
+		conflict := newError(http.StatusConflict, "El registro cambió mientras se actualizaba", nil)
+		if rerr == nil {
+			conflict.Data = current
+		}
+		return conflict
+	case errors.Is(err, repositories.ErrNotFound):
+		return notFound("El registro ya no existe")
+	}
+	return internalError("Error al actualizar el registro", err)
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"time"
+)
+
+// EstadosReserva son los estados permitidos para una reserva
+var EstadosReserva = map[string]bool{
+	"PENDIENTE":  true,
+	"CONFIRMADA": true,
+	"CANCELADA":  true,
+	"CUMPLIDA":   true,
+}
+
+// ReservaService concentra la lógica de reservas
+type ReservaService struct {
+	store repositories.Store
+}
+
+func NewReservaService(store repositories.Store) *ReservaService {
+	return &ReservaService{store: store}
+}
+
+// List devuelve todas las reservas con fechas y hora ajustadas para la respuesta
+func (s *ReservaService) List() ([]models.Reserva, error) {
+	reservas, err := s.store.Reservas().List()
+	if err != nil {
+		return nil, internalError("Error al obtener reservas de la base de datos", err)
+	}
+	for i := range reservas {
+		formatReserva(&reservas[i])
+	}
+	return reservas, nil
+}
+
+// GetByID busca una reserva por su identificador
+func (s *ReservaService) GetByID(id int) (*models.Reserva, error) {
+	reserva, err := s.GetForUpdate(id)
+	if err != nil {
+		return nil, err
+	}
+	formatReserva(reserva)
+	return reserva, nil
+}
+
+// GetForUpdate busca una reserva tal como está almacenada, para modificarla y guardarla con Update
+func (s *ReservaService) GetForUpdate(id int) (*models.Reserva, error) {
+	reserva, err := s.store.Reservas().Get(id)
+	if err != nil {
+		return nil, lookup(err, notFound("Reserva no encontrada"))
+	}
+	return reserva, nil
+}
+
+// Create registra una reserva nueva
+func (s *ReservaService) Create(reserva *models.Reserva) error {
+	if reserva.ESTADO_RESERVA != nil && !EstadosReserva[*reserva.ESTADO_RESERVA] {
+		return badRequest("Estado de reserva inválido")
+	}
+	reserva.CREATED_AT = time.Now().UTC()
+	reserva.UPDATED_AT = time.Time{}
+	if err := s.store.Reservas().Insert(reserva); err != nil {
+		return internalError("Error al crear la reserva", err)
+	}
+	PublicarReserva(s.store, reserva, AccionCreado)
+	return nil
+}
+
+// Update guarda la reserva si nadie la modificó desde la versión indicada
+func (s *ReservaService) Update(reserva *models.Reserva, version int) error {
+	reserva.UPDATED_AT = time.Now().UTC()
+	return s.save(reserva, version)
+}
+
+// Cancel marca la reserva como CANCELADA
+func (s *ReservaService) Cancel(id int) (*models.Reserva, error) {
+	reserva, err := s.GetForUpdate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	estadoCancelada := "CANCELADA"
+	reserva.ESTADO_RESERVA = &estadoCancelada
+	reserva.UPDATED_AT = time.Now()
+	if err := s.save(reserva, reserva.VERSION, "ESTADO_RESERVA", "UPDATED_AT"); err != nil {
+		return nil, err
+	}
+	return reserva, nil
+}
+
+// save guarda la reserva si sigue en la versión indicada; en un conflicto responde 409 con la actual
+func (s *ReservaService) save(reserva *models.Reserva, version int, cols ...string) error {
+	err := s.store.Reservas().Update(reserva, version, cols...)
+	if err != nil {
+		return saveError(err, func() (*models.Reserva, error) {
+			return s.store.Reservas().Get(reserva.PK_ID_RESERVA)
+		})
+	}
+	accion := AccionActualizado
+	if reserva.ESTADO_RESERVA != nil && *reserva.ESTADO_RESERVA == "CANCELADA" {
+		accion = AccionCancelado
+	}
+	PublicarReserva(s.store, reserva, accion)
+	return nil
+}
+
+// formatReserva ajusta las fechas a la zona horaria de Bogotá y la hora al formato HH:MM:SS
+func formatReserva(reserva *models.Reserva) {
+	reserva.FECHA = reserva.FECHA.In(database.BogotaZone)
+	reserva.CREATED_AT = reserva.CREATED_AT.In(database.BogotaZone)
+	reserva.UPDATED_AT = reserva.UPDATED_AT.In(database.BogotaZone)
+	if len(reserva.HORA) >= 19 {
+		reserva.HORA = reserva.HORA[11:19]
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"strings"
+	"time"
+)
+
+// Motivos de los movimientos de inventario
+const (
+	MotivoVenta             = "VENTA"
+	MotivoCancelacion       = "CANCELACION"
+	MotivoAjustePedido      = "AJUSTE_PEDIDO"
+	MotivoInventarioInicial = "INVENTARIO_INICIAL"
+	MotivoReabastecimiento  = "REABASTECIMIENTO"
+	MotivoMerma             = "MERMA"
+	MotivoAjusteManual      = "AJUSTE_MANUAL"
+)
+
+// StockService consulta y registra los movimientos de inventario de los productos
+type StockService struct {
+	store repositories.Store
+}
+
+func NewStockService(store repositories.Store) *StockService {
+	return &StockService{store: store}
+}
+
+// List devuelve los movimientos de un producto existente, del más reciente al más antiguo
+func (s *StockService) List(productoID int64) ([]models.MovimientoStock, error) {
+	if _, err := s.store.Productos().Get(productoID); err != nil {
+		return nil, lookup(err, notFound("Producto no encontrado"))
+	}
+
+	movimientos, err := s.store.MovimientosStock().ListByProducto(productoID)
+	if err != nil {
+		return nil, internalError("Error al obtener los movimientos de inventario", err)
+	}
+	return movimientos, nil
+}
+
+// Registrar aplica un movimiento manual: REABASTECIMIENTO suma unidades, MERMA las resta y
+// AJUSTE_MANUAL acepta cantidades positivas o negativas
+func (s *StockService) Registrar(req *models.MovimientoStockRequest, actor Actor) (*models.MovimientoStock, error) {
+	req.MOTIVO = strings.ToUpper(strings.TrimSpace(req.MOTIVO))
+	if req.PK_ID_PRODUCTO <= 0 || req.CANTIDAD == 0 {
+		return nil, badRequest("Los campos PK_ID_PRODUCTO y CANTIDAD (distinta de cero) son obligatorios")
+	}
+	switch req.MOTIVO {
+	case MotivoReabastecimiento:
+		if req.CANTIDAD < 0 {
+			return nil, badRequest("Un reabastecimiento debe tener CANTIDAD positiva")
+		}
+	case MotivoMerma:
+		if req.CANTIDAD > 0 {
+			req.CANTIDAD = -req.CANTIDAD
+		}
+	case MotivoAjusteManual:
+	default:
+		return nil, badRequest(fmt.Sprintf("MOTIVO inválido; use %s, %s o %s", MotivoReabastecimiento, MotivoMerma, MotivoAjusteManual))
+	}
+
+	var movimiento *models.MovimientoStock
+	err := s.store.Transaction(func(tx repositories.Store) error {
+		producto, err := tx.Productos().Get(req.PK_ID_PRODUCTO)
+		if err != nil {
+			return lookup(err, unprocessable(fmt.Sprintf("El producto %d no existe", req.PK_ID_PRODUCTO)))
+		}
+		if !producto.CONTROLA_STOCK {
+			return &Error{
+				Code:    http.StatusConflict,
+				Message: fmt.Sprintf("%s no controla inventario; actívelo con CONTROLA_STOCK al editar el producto", producto.NOMBRE),
+				Data:    producto,
+			}
+		}
+		movimiento, err = moverStock(tx, req.PK_ID_PRODUCTO, req.CANTIDAD, req.MOTIVO, nil, actor, req.OBSERVACIONES)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movimiento, nil
+}
+
+// moverStock suma delta al inventario del producto y lo anota en el libro de movimientos
+func moverStock(tx repositories.Store, productoID int64, delta int, motivo string, pedidoID *int, actor Actor, observaciones string) (*models.MovimientoStock, error) {
+	producto, err := tx.Productos().AjustarStock(productoID, delta)
+	if errors.Is(err, repositories.ErrStockInsuficiente) {
+		return nil, stockInsuficiente(producto, -delta)
+	}
+	if err != nil {
+		return nil, lookup(err, unprocessable(fmt.Sprintf("El producto %d no existe", productoID)))
+	}
+
+	return anotarMovimiento(tx, producto, delta, motivo, pedidoID, actor, observaciones)
+}
+
+// stockInsuficiente responde 409 con el producto cuando no tiene las unidades que se necesitan
+func stockInsuficiente(producto *models.Producto, necesarias int) *Error {
+	return &Error{
+		Code:    http.StatusConflict,
+		Message: fmt.Sprintf("Stock insuficiente para %s: hay %d unidades y se necesitan %d", producto.NOMBRE, producto.CANTIDAD, necesarias),
+		Data:    producto,
+	}
+}
+
+// anotarMovimiento agrega al libro un movimiento ya aplicado sobre el producto
+func anotarMovimiento(tx repositories.Store, producto *models.Producto, delta int, motivo string, pedidoID *int, actor Actor, observaciones string) (*models.MovimientoStock, error) {
+	movimiento := models.MovimientoStock{
+		PK_ID_PRODUCTO:   producto.PK_ID_PRODUCTO,
+		PK_ID_PEDIDO:     pedidoID,
+		CANTIDAD:         delta,
+		MOTIVO:           motivo,
+		STOCK_RESULTANTE: producto.CANTIDAD,
+		DOCUMENTO_ACTOR:  actor.documento(),
+		FECHA:            time.Now().In(database.BogotaZone),
+	}
+	if observaciones != "" {
+		movimiento.OBSERVACIONES = &observaciones
+	}
+	if err := tx.MovimientosStock().Insert(&movimiento); err != nil {
+		return nil, internalError("Error al registrar el movimiento de inventario", err)
+	}
+	return &movimiento, nil
+}
+
+// stockDescontado indica si las unidades de un pedido en ese estado ya salieron del inventario: se
+// descuentan cuando el pedido se confirma al pasar de INICIADO a PAGADO o EN PREPARACION y vuelven
+// si se cancela
+func stockDescontado(estado string) bool {
+	return estado != EstadoIniciado && estado != EstadoCancelado
+}
+
+// descontarAlConfirmar saca del inventario las unidades del pedido cuando pasa de anterior a un
+// estado que lo confirma
+func descontarAlConfirmar(tx repositories.Store, pedidoID int, anterior, nuevo string, actor Actor) error {
+	if stockDescontado(anterior) || !stockDescontado(nuevo) {
+		return nil
+	}
+	lineas, err := lineasPedido(tx, pedidoID)
+	if err != nil {
+		return err
+	}
+	return descontarLineas(tx, pedidoID, lineas, actor)
+}
+
+// descontarLineas saca del inventario las unidades de cada línea del pedido cuyo producto
+// controla stock
+func descontarLineas(tx repositories.Store, pedidoID int, lineas []models.LineaProducto, actor Actor) error {
+	for _, linea := range lineas {
+		if err := moverStockPedido(tx, linea.PK_ID_PRODUCTO, -linea.CANTIDAD, MotivoVenta, pedidoID, actor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reponerLineas devuelve al inventario las unidades de las líneas guardadas del pedido cuyo
+// producto controla stock
+func reponerLineas(tx repositories.Store, pedidoID int, motivo string, actor Actor) error {
+	lineas, err := lineasPedido(tx, pedidoID)
+	if err != nil {
+		return err
+	}
+	for _, linea := range lineas {
+		if err := moverStockPedido(tx, linea.PK_ID_PRODUCTO, linea.CANTIDAD, motivo, pedidoID, actor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moverStockPedido aplica el movimiento de un pedido solo si el producto controla stock
+func moverStockPedido(tx repositories.Store, productoID int64, delta int, motivo string, pedidoID int, actor Actor) error {
+	producto, err := tx.Productos().Get(productoID)
+	if err != nil {
+		return lookup(err, unprocessable(fmt.Sprintf("El producto %d no existe", productoID)))
+	}
+	if !producto.CONTROLA_STOCK {
+		return nil
+	}
+	_, err = moverStock(tx, productoID, delta, motivo, &pedidoID, actor, "")
+	return err
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// columnasEntrega son las columnas del pedido cuyo cambio obliga a recalcular la entrega estimada
+var columnasEntrega = []string{"ESTADO_PEDIDO", "SUBTOTAL", "PK_ID_DOMICILIO", "DELIVERY", "PROGRAMADO_PARA"}
+
+// tiempoPreparacion son los minutos de los productos sin TIEMPO_PREPARACION (clave tiempo_preparacion_minutos)
+func tiempoPreparacion() int {
+	return web.AppConfig.DefaultInt("tiempo_preparacion_minutos", 15)
+}
+
+// minutosPorPedidoEnCola es lo que suma cada pedido que la cocina tiene por delante (clave minutos_por_pedido_en_cola)
+func minutosPorPedidoEnCola() int {
+	return web.AppConfig.DefaultInt("minutos_por_pedido_en_cola", 3)
+}
+
+// tiempoSalidaDomicilio son los minutos fijos de cada domicilio, antes de contar la distancia
+// (clave tiempo_salida_domicilio_minutos)
+func tiempoSalidaDomicilio() int {
+	return web.AppConfig.DefaultInt("tiempo_salida_domicilio_minutos", 10)
+}
+
+// minutosPorKm es lo que tarda el domiciliario en recorrer un kilómetro (clave minutos_por_km)
+func minutosPorKm() float64 {
+	return web.AppConfig.DefaultFloat("minutos_por_km", 3)
+}
+
+// distanciaDomicilio es la distancia que se supone para los domicilios sin DISTANCIA_KM (clave distancia_domicilio_km)
+func distanciaDomicilio() float64 {
+	return web.AppConfig.DefaultFloat("distancia_domicilio_km", 3)
+}
+
+// estimacion son los minutos que se esperan para preparar y entregar un pedido
+type estimacion struct {
+	productos int // el producto más demorado; las estaciones cocinan en paralelo
+	cola      int
+	distancia *float64
+	entrega   int // 0 si no es a domicilio
+}
+
+// preparacion suma al producto más demorado la espera por los pedidos que la cocina tiene por delante
+func (e estimacion) preparacion(cola int) int {
+	return e.productos + cola*minutosPorPedidoEnCola()
+}
+
+// estimar calcula los tiempos del pedido con sus productos, los pedidos que hay en la cocina y la
+// distancia de su domicilio
+func estimar(tx repositories.Store, pedido *models.Pedido) (estimacion, error) {
+	var e estimacion
+	detalles, err := tx.DetallesPedido().ListByPedido(pedido.PK_ID_PEDIDO)
+	if err != nil {
+		return e, internalError("Error al consultar los productos del pedido", err)
+	}
+	for _, detalle := range detalles {
+		minutos := tiempoPreparacion()
+		if producto, err := tx.Productos().Get(detalle.PK_ID_PRODUCTO); err == nil && producto.TIEMPO_PREPARACION > 0 {
+			minutos = producto.TIEMPO_PREPARACION
+		}
+		e.productos = max(e.productos, minutos)
+	}
+	if len(detalles) == 0 {
+		e.productos = tiempoPreparacion()
+	}
+
+	if e.cola, err = colaCocina(tx, pedido.PK_ID_PEDIDO); err != nil {
+		return e, err
+	}
+
+	if pedido.DELIVERY || pedido.PK_ID_DOMICILIO != nil {
+		distancia := distanciaDomicilio()
+		if pedido.PK_ID_DOMICILIO != nil {
+			domicilio, err := tx.Domicilios().Get(*pedido.PK_ID_DOMICILIO)
+			if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+				return e, internalError("Error al consultar el domicilio del pedido", err)
+			}
+			if domicilio != nil && domicilio.DISTANCIA_KM != nil {
+				e.distancia = domicilio.DISTANCIA_KM
+				distancia = *domicilio.DISTANCIA_KM
+			}
+		}
+		e.entrega = tiempoSalidaDomicilio() + int(math.Ceil(distancia*minutosPorKm()))
+	}
+	return e, nil
+}
+
+// colaCocina cuenta los otros pedidos con tickets sin terminar en la cocina
+func colaCocina(tx repositories.Store, pedidoID int) (int, error) {
+	tickets, err := tx.TicketsCocina().List("", []string{TicketPendiente, TicketPreparando})
+	if err != nil {
+		return 0, internalError("Error al consultar la cola de la cocina", err)
+	}
+	pedidos := map[int]bool{}
+	for _, ticket := range tickets {
+		if ticket.PK_ID_PEDIDO != pedidoID {
+			pedidos[ticket.PK_ID_PEDIDO] = true
+		}
+	}
+	return len(pedidos), nil
+}
+
+// estimarEntrega recalcula ENTREGA_ESTIMADA según el estado del pedido y registra en TIEMPO_PEDIDO
+// los minutos reales de cada etapa:
+//   - Antes de la cocina se cuenta desde ahora con la cola actual.
+//   - Al entrar a EN PREPARACION se guarda la estimación con la cola de ese momento; la
+//     preparación real se mide hasta LISTO y la entrega real de LISTO a ENTREGADO.
+//   - Un pedido entregado conserva su última estimación y uno cancelado se queda sin ella.
+//   - Un pedido programado no se estima antes de la hora que pidió el cliente.
+func estimarEntrega(tx repositories.Store, pedido *models.Pedido) error {
+	ahora := time.Now().In(database.BogotaZone)
+	if pedido.ESTADO_PEDIDO == EstadoCancelado {
+		pedido.ENTREGA_ESTIMADA = nil
+		return nil
+	}
+
+	tiempo, err := tx.TiemposPedido().Get(pedido.PK_ID_PEDIDO)
+	if errors.Is(err, repositories.ErrNotFound) {
+		tiempo, err = nil, nil
+	}
+	if err != nil {
+		return internalError("Error al consultar los tiempos del pedido", err)
+	}
+
+	if pedido.ESTADO_PEDIDO == EstadoEntregado {
+		if tiempo == nil || tiempo.ENTREGADO_EN != nil {
+			return nil
+		}
+		tiempo.ENTREGADO_EN = &ahora
+		if tiempo.LISTO_EN != nil {
+			real := minutosEntre(*tiempo.LISTO_EN, ahora)
+			tiempo.ENTREGA_REAL = &real
+		}
+		return guardarTiempo(tx, tiempo, false)
+	}
+
+	e, err := estimar(tx, pedido)
+	if err != nil {
+		return err
+	}
+
+	listo := ahora.Add(time.Duration(e.preparacion(e.cola)) * time.Minute)
+	switch pedido.ESTADO_PEDIDO {
+	case EstadoEnPreparacion:
+		nuevo := tiempo == nil
+		if nuevo {
+			tiempo = &models.TiempoPedido{PK_ID_PEDIDO: pedido.PK_ID_PEDIDO, COLA: e.cola, INICIO: ahora}
+		}
+		// Un pedido devuelto a la cocina vuelve a medir su preparación
+		tiempo.LISTO_EN, tiempo.PREPARACION_REAL = nil, nil
+		tiempo.PREPARACION_ESTIMADA = e.preparacion(tiempo.COLA)
+		tiempo.DISTANCIA_KM, tiempo.ENTREGA_ESTIMADA = e.distancia, e.entrega
+		if err := guardarTiempo(tx, tiempo, nuevo); err != nil {
+			return err
+		}
+		listo = tiempo.INICIO.Add(time.Duration(tiempo.PREPARACION_ESTIMADA) * time.Minute)
+		if listo.Before(ahora) {
+			listo = ahora
+		}
+	case EstadoListo, EstadoEnCamino:
+		listo = ahora
+		if tiempo != nil {
+			if tiempo.LISTO_EN == nil {
+				real := minutosEntre(tiempo.INICIO, ahora)
+				tiempo.LISTO_EN, tiempo.PREPARACION_REAL = &ahora, &real
+				if err := guardarTiempo(tx, tiempo, false); err != nil {
+					return err
+				}
+			}
+			listo = *tiempo.LISTO_EN
+		}
+	}
+
+	entrega := listo.Add(time.Duration(e.entrega) * time.Minute)
+	// Un pedido programado no se entrega antes de la hora que pidió el cliente
+	if pedido.PROGRAMADO_PARA != nil && entrega.Before(*pedido.PROGRAMADO_PARA) {
+		entrega = *pedido.PROGRAMADO_PARA
+	}
+	pedido.ENTREGA_ESTIMADA = &entrega
+	return nil
+}
+
+func guardarTiempo(tx repositories.Store, tiempo *models.TiempoPedido, nuevo bool) error {
+	var err error
+	if nuevo {
+		err = tx.TiemposPedido().Insert(tiempo)
+	} else {
+		err = tx.TiemposPedido().Update(tiempo)
+	}
+	if err != nil {
+		return internalError("Error al registrar los tiempos del pedido", err)
+	}
+	return nil
+}
+
+// minutosEntre redondea a minutos el tiempo transcurrido entre desde y hasta
+func minutosEntre(desde, hasta time.Time) int {
+	return int(math.Round(hasta.Sub(desde).Minutes()))
+}
+
+// recalculaEntrega indica si guardar cols cambia la entrega estimada del pedido
+func recalculaEntrega(cols []string) bool {
+	for _, col := range columnasEntrega {
+		if contiene(cols, col) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"restaurante/impresion"
+	"restaurante/repositories"
+	"strconv"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+// encabezadoTiquete es el nombre que encabeza los recibos (clave impresion_encabezado)
+func encabezadoTiquete() string {
+	return web.AppConfig.DefaultString("impresion_encabezado", "RESTAURANTE")
+}
+
+// Recibo arma el tiquete del cliente con los productos, el desglose de impuestos, las promociones
+// y los totales del pedido, para el papel del ancho indicado. Un cliente solo ve el de sus pedidos.
+func (s *PedidoService) Recibo(pedidoID, ancho int, actor Actor) (*impresion.Documento, error) {
+	recibo, err := s.GetRecibo(pedidoID, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	d := impresion.Nuevo(ancho)
+	d.Titulo(encabezadoTiquete())
+	d.Centrado(fmt.Sprintf("Pedido #%d", recibo.PK_ID_PEDIDO))
+	d.Centrado(strings.TrimSpace(recibo.FECHA + " " + recibo.HORA))
+	if recibo.DELIVERY {
+		d.Centrado("DOMICILIO")
+	}
+	d.Separador()
+
+	for _, linea := range recibo.PRODUCTOS {
+		d.Par(fmt.Sprintf("%d x %s", linea.CANTIDAD, linea.NOMBRE), pesos(linea.SUBTOTAL), false)
+		for _, modificador := range linea.MODIFICADORES {
+			d.Texto("  + "+modificador.NOMBRE, false)
+		}
+		if linea.NOTAS != "" {
+			d.Texto("  "+linea.NOTAS, false)
+		}
+	}
+	d.Separador()
+
+	d.Par("Subtotal", pesos(recibo.SUBTOTAL), false)
+	for _, promocion := range recibo.PROMOCIONES {
+		d.Par(promocion.NOMBRE, "-"+pesos(promocion.DESCUENTO), false)
+	}
+	for _, impuesto := range recibo.IMPUESTOS {
+		d.Par(fmt.Sprintf("Incluye %s %d%%", impuesto.CATEGORIA, impuesto.TARIFA), pesos(impuesto.VALOR), false)
+	}
+	if recibo.VALOR_DOMICILIO > 0 {
+		d.Par("Domicilio", pesos(recibo.VALOR_DOMICILIO), false)
+	}
+	if recibo.PROPINA > 0 {
+		d.Par("Propina", pesos(recibo.PROPINA), false)
+	}
+	d.Par("TOTAL", pesos(recibo.TOTAL), true)
+	if recibo.METODO_PAGO != "" {
+		d.Par("Pago", recibo.METODO_PAGO, false)
+	}
+	if recibo.PROPINA == 0 && recibo.PROPINA_SUGERIDA > 0 {
+		d.Blanco()
+		d.Texto("Propina sugerida: "+pesos(recibo.PROPINA_SUGERIDA), false)
+	}
+	d.Blanco()
+	d.Centrado("¡Gracias por su compra!")
+	return d, nil
+}
+
+// Comanda arma el papel que sale en la estación con los ítems del ticket, sus modificadores y
+// notas, y la mesa o el domicilio al que va el pedido. Los clientes responden 403.
+func (s *CocinaService) Comanda(ticketID int64, ancho int, actor Actor) (*impresion.Documento, error) {
+	if err := requierePersonal(actor, "ver las comandas"); err != nil {
+		return nil, err
+	}
+	return comanda(s.store, ticketID, ancho)
+}
+
+func comanda(tx repositories.Store, ticketID int64, ancho int) (*impresion.Documento, error) {
+	ticket, err := tx.TicketsCocina().Get(ticketID)
+	if err != nil {
+		return nil, lookup(err, notFound("Ticket de cocina no encontrado"))
+	}
+	pedido, err := tx.Pedidos().Get(ticket.PK_ID_PEDIDO)
+	if err != nil {
+		return nil, lookup(err, notFound("Pedido no encontrado"))
+	}
+
+	d := impresion.Nuevo(ancho)
+	d.Titulo(ticket.ESTACION)
+	d.Par(fmt.Sprintf("Pedido #%d", ticket.PK_ID_PEDIDO), ticket.CREADO.Format("15:04"), true)
+	switch {
+	case pedido.PK_ID_MESA != nil:
+		destino := fmt.Sprintf("Mesa %d", *pedido.PK_ID_MESA)
+		if mesa, err := tx.Mesas().Get(*pedido.PK_ID_MESA); err == nil {
+			destino = fmt.Sprintf("Mesa %d", mesa.NUMERO)
+		}
+		d.Texto(destino, true)
+	case esDomicilio(pedido):
+		d.Texto("DOMICILIO", true)
+	default:
+		d.Texto("PARA LLEVAR", true)
+	}
+	d.Separador()
+
+	for _, item := range ticket.ITEMS {
+		d.Texto(fmt.Sprintf("%d x %s", item.CANTIDAD, item.NOMBRE), true)
+		if item.MODIFICADORES != nil {
+			d.Texto("  "+*item.MODIFICADORES, false)
+		}
+		if item.NOTAS != nil && *item.NOTAS != "" {
+			d.Texto("  NOTA: "+*item.NOTAS, false)
+		}
+	}
+	d.Separador()
+	return d, nil
+}
+
+// pesos formatea un valor en pesos con punto de miles: 60000 -> $60.000
+func pesos(valor int64) string {
+	signo := ""
+	if valor < 0 {
+		signo, valor = "-", -valor
+	}
+	digitos := strconv.FormatInt(valor, 10)
+	var b strings.Builder
+	for i, digito := range digitos {
+		if i > 0 && (len(digitos)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digito)
+	}
+	return signo + "$" + b.String()
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"restaurante/database"
+	"restaurante/models"
+	"restaurante/repositories"
+	"time"
+)
+
+// TrabajadorService concentra la lógica de trabajadores. Las respuestas nunca incluyen la
+// contraseña salvo en GetForUpdate, que devuelve el registro completo para modificarlo.
+type TrabajadorService struct {
+	store repositories.Store
+}
+
+func NewTrabajadorService(store repositories.Store) *TrabajadorService {
+	return &TrabajadorService{store: store}
+}
+
+// TrabajadorFiltros agrupa los criterios opcionales de búsqueda de trabajadores
+type TrabajadorFiltros = repositories.TrabajadorFiltros
+
+// List devuelve los trabajadores que cumplen los filtros, sin contraseña
+func (s *TrabajadorService) List(filtros TrabajadorFiltros) ([]models.Trabajador, error) {
+	trabajadores, err := s.store.Trabajadores().List(filtros)
+	if err != nil {
+		return nil, internalError("Error al obtener trabajadores de la base de datos", err)
+	}
+	for i := range trabajadores {
+		trabajadores[i].PASSWORD = ""
+	}
+	return trabajadores, nil
+}
+
+// GetByID busca un trabajador por su documento, sin contraseña
+func (s *TrabajadorService) GetByID(documento int64) (*models.Trabajador, error) {
+	trabajador, err := s.GetForUpdate(documento)
+	if err != nil {
+		return nil, err
+	}
+	trabajador.PASSWORD = ""
+	return trabajador, nil
+}
+
+// GetForUpdate busca un trabajador por su documento conservando la contraseña almacenada
+func (s *TrabajadorService) GetForUpdate(documento int64) (*models.Trabajador, error) {
+	trabajador, err := s.store.Trabajadores().Get(documento)
+	if err != nil {
+		return nil, lookup(err, notFound("Trabajador no encontrado"))
+	}
+	return trabajador, nil
+}
+
+// Update guarda el trabajador si nadie lo modificó desde la versión indicada
+func (s *TrabajadorService) Update(trabajador *models.Trabajador, version int) error {
+	err := s.save(trabajador, version)
+	trabajador.PASSWORD = ""
+	return err
+}
+
+// Retire registra la fecha de retiro del trabajador con la fecha actual
+func (s *TrabajadorService) Retire(documento int64) (*models.Trabajador, error) {
+	trabajador, err := s.GetForUpdate(documento)
+	if err != nil {
+		return nil, err
+	}
+
+	fechaRetiro := time.Now().In(database.BogotaZone)
+	trabajador.FECHA_RETIRO = &fechaRetiro
+	err = s.save(trabajador, trabajador.VERSION, "FECHA_RETIRO")
+	trabajador.PASSWORD = ""
+	if err != nil {
+		return nil, err
+	}
+	return trabajador, nil
+}
+
+// save guarda el trabajador si sigue en la versión indicada; en un conflicto responde 409 con
+// el actual sin contraseña
+func (s *TrabajadorService) save(trabajador *models.Trabajador, version int, cols ...string) error {
+	err := s.store.Trabajadores().Update(trabajador, version, cols...)
+	return saveError(err, func() (*models.Trabajador, error) {
+		return s.GetByID(trabajador.PK_DOCUMENTO_TRABAJADOR)
+	})
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"restaurante/database"
+	"restaurante/models"
+
+	"github.com/beego/beego/v2/client/orm"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestMigraciones aplica las migraciones embebidas sobre una base Postgres nueva con el esquema
+// anterior a la primera migración (testdata/esquema_base.sql), que todavía guarda los productos
+// de los pedidos en el arreglo JSONB de PRODUCTO_PEDIDO, y compara el resultado con el esquema
+// que RunSyncdb crea con los modelos actuales. Las pruebas de SQLite crean el esquema con
+// RunSyncdb y no ejecutan el SQL de las migraciones, así que esta prueba solo corre con
+// TEST_DATABASE_URL.
+func TestMigraciones(t *testing.T) {
+	adminURL := os.Getenv("TEST_DATABASE_URL")
+	if adminURL == "" {
+		t.Skip("TEST_DATABASE_URL no está definida; las migraciones solo se prueban sobre Postgres")
+	}
+
+	Convey("Subject: Migraciones sobre Postgres\n", t, func() {
+		cleanup, err := basePostgres(adminURL, "migraciones")
+		So(err, ShouldBeNil)
+		defer cleanup()
+
+		esquema, err := os.ReadFile(filepath.Join("testdata", "esquema_base.sql"))
+		So(err, ShouldBeNil)
+		o := orm.NewOrmUsingDB("migraciones")
+		_, err = o.Raw(string(esquema)).Exec()
+		So(err, ShouldBeNil)
+
+		// Los modelos actuales tienen columnas que el esquema base no conoce, así que los datos
+		// anteriores a las migraciones se insertan con SQL
+		var productoID, pedidoID int64
+		So(o.Raw(`INSERT INTO "PRODUCTO" ("NOMBRE", "DESCRIPCION", "PRECIO", "ESTADO_PRODUCTO") VALUES ('Bandeja paisa', '', 25000, 'DISPONIBLE') RETURNING "PK_ID_PRODUCTO"`).QueryRow(&productoID), ShouldBeNil)
+		So(o.Raw(`INSERT INTO "PEDIDO" ("FECHA", "HORA", "ESTADO_PEDIDO", "UPDATED_AT") VALUES (?, '12:00:00', 'ENTREGADO', ?) RETURNING "PK_ID_PEDIDO"`, time.Now(), time.Now()).QueryRow(&pedidoID), ShouldBeNil)
+		detalles := `[
+			{"PK_ID_PRODUCTO": ` + strconv.FormatInt(productoID, 10) + `, "CANTIDAD": 2},
+			{"PK_ID_PRODUCTO": 9999, "CANTIDAD": 1},
+			{"PK_ID_PRODUCTO": "` + strconv.FormatInt(productoID, 10) + `", "NOMBRE": "Bandeja", "PRECIO_UNITARIO": 20000, "NOTAS": "Sin chicharrón"}
+		]`
+		_, err = o.Raw(`INSERT INTO "PRODUCTO_PEDIDO" ("PK_ID_PEDIDO", "DETALLES_PRODUCTOS") VALUES (?, ?::JSONB)`, pedidoID, detalles).Exec()
+		So(err, ShouldBeNil)
+
+		So(database.ApplyMigrations(o), ShouldBeNil)
+
+		// Todas quedan registradas y volver a aplicarlas no hace nada
+		archivos, err := filepath.Glob("../database/migrations/*.sql")
+		So(err, ShouldBeNil)
+		var aplicadas int
+		So(o.Raw(`SELECT COUNT(*) FROM "SCHEMA_MIGRATIONS"`).QueryRow(&aplicadas), ShouldBeNil)
+		So(aplicadas, ShouldEqual, len(archivos))
+
+		So(database.ApplyMigrations(o), ShouldBeNil)
+		So(o.Raw(`SELECT COUNT(*) FROM "SCHEMA_MIGRATIONS"`).QueryRow(&aplicadas), ShouldBeNil)
+		So(aplicadas, ShouldEqual, len(archivos))
+
+		// Las migraciones dejan las mismas tablas y columnas que los modelos actuales
+		cleanupModelos, err := basePostgres(adminURL, "modelos")
+		So(err, ShouldBeNil)
+		defer cleanupModelos()
+		So(orm.RunSyncdb("modelos", false, false), ShouldBeNil)
+
+		migradas, err := columnasEsquema(o)
+		So(err, ShouldBeNil)
+		delete(migradas, "SCHEMA_MIGRATIONS")
+		esperadas, err := columnasEsquema(orm.NewOrmUsingDB("modelos"))
+		So(err, ShouldBeNil)
+		So(migradas, ShouldResemble, esperadas)
+
+		// Los arreglos JSONB pasan a DETALLE_PEDIDO y el pedido toma el subtotal de sus líneas
+		var lineas []models.DetallePedido
+		_, err = o.QueryTable(new(models.DetallePedido)).Filter("PK_ID_PEDIDO", pedidoID).OrderBy("PK_ID_DETALLE_PEDIDO").All(&lineas)
+		So(err, ShouldBeNil)
+		So(len(lineas), ShouldEqual, 2)
+		So(lineas[0].NOMBRE, ShouldEqual, "Bandeja paisa")
+		So(lineas[0].CANTIDAD, ShouldEqual, 2)
+		So(lineas[0].PRECIO_UNITARIO, ShouldEqual, 25000)
+		So(lineas[1].NOMBRE, ShouldEqual, "Bandeja")
+		So(lineas[1].CANTIDAD, ShouldEqual, 1)
+		So(lineas[1].PRECIO_UNITARIO, ShouldEqual, 20000)
+		So(*lineas[1].NOTAS, ShouldEqual, "Sin chicharrón")
+		So(lineas[1].CATEGORIA_IMPUESTO, ShouldEqual, "EXENTO")
+
+		migrado := models.Pedido{PK_ID_PEDIDO: int(pedidoID)}
+		So(o.Read(&migrado), ShouldBeNil)
+		So(migrado.SUBTOTAL, ShouldEqual, 70000)
+		So(migrado.TOTAL, ShouldEqual, 70000)
+	})
+}
+
+// columnasEsquema devuelve las columnas de cada tabla del esquema public, ordenadas por nombre
+func columnasEsquema(o orm.Ormer) (map[string][]string, error) {
+	var filas []orm.Params
+	if _, err := o.Raw(`SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = 'public' ORDER BY table_name, column_name`).Values(&filas); err != nil {
+		return nil, err
+	}
+	columnas := make(map[string][]string)
+	for _, f := range filas {
+		tabla := f["table_name"].(string)
+		columnas[tabla] = append(columnas[tabla], f["column_name"].(string))
+	}
+	return columnas, nil
+}
@@ -0,0 +1,621 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"restaurante/controllers"
+	"restaurante/database"
+	"restaurante/models"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/context"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// routeCase es una solicitud contra una ruta registrada y el código que debe responder
+type routeCase struct {
+	route    string // método y patrón tal como aparecen en el árbol de rutas de beego
+	name     string
+	path     string
+	rol      string // rol cuyo token se envía; vacío para no enviar token
+	token    string // token explícito, tiene prioridad sobre rol
+	body     interface{}
+	headers  map[string]string
+	status   int
+	postgres bool // depende de SQL propio de Postgres (EXTRACT, ILIKE, jsonb, procedimientos)
+}
+
+var (
+	// Cabecera If-Match que acepta la versión vigente del registro
+	ifMatchAny = map[string]string{"If-Match": "*"}
+
+	// Cabecera para los endpoints que leen el cuerpo como formulario
+	formHeaders = map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+)
+
+func routeCases() []routeCase {
+	v1 := "/restaurante/v1"
+	v2 := "/restaurante/v2"
+	admin := "Administrador"
+	hoy := fixtureDate.Format("2006-01-02")
+	// Vigencia de las resoluciones de facturación que se registran
+	vigencia := []string{fixtureDate.AddDate(-1, 0, 0).Format(time.RFC3339), fixtureDate.AddDate(1, 0, 0).Format(time.RFC3339)}
+	// Una hora dentro de la anticipación de los pedidos programados
+	pronto := time.Now().In(database.BogotaZone).Add(10 * time.Minute).Format("02-01-2006 15:04:05")
+
+	return []routeCase{
+		{route: "GET /", name: "estado del servicio", path: "/", status: http.StatusOK},
+
+		// Login
+		{route: "POST /restaurante/v1/login", name: "credenciales válidas", path: v1 + "/login", body: map[string]interface{}{"documento": docMesero, "password": passwordPruebas}, status: http.StatusOK},
+		{route: "POST /restaurante/v1/login", name: "contraseña incorrecta", path: v1 + "/login", body: map[string]interface{}{"documento": docMesero, "password": "otra"}, status: http.StatusUnauthorized},
+		{route: "POST /restaurante/v1/login", name: "usuario inexistente", path: v1 + "/login", body: map[string]interface{}{"documento": 9999, "password": passwordPruebas}, status: http.StatusUnauthorized},
+		{route: "POST /restaurante/v1/login", name: "cuerpo inválido", path: v1 + "/login", body: "{", status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/login", name: "credenciales de cliente", path: v2 + "/login", body: map[string]interface{}{"documento": docCliente, "password": passwordPruebas}, status: http.StatusOK},
+
+		// Clientes
+		{route: "GET /restaurante/v1/clientes/", name: "sin token", path: v1 + "/clientes", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/clientes/", name: "token inválido", path: v1 + "/clientes", token: "no-es-un-jwt", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/clientes/", name: "listar", path: v1 + "/clientes", rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v1/clientes/search", name: "por documento", path: fmt.Sprintf("%s/clientes/search?id=%d", v1, docCliente), rol: "cliente", status: http.StatusOK},
+		{route: "GET /restaurante/v1/clientes/search", name: "inexistente", path: v1 + "/clientes/search?id=9999", rol: admin, status: http.StatusNotFound},
+		{route: "POST /restaurante/v1/clientes/", name: "crear", path: v1 + "/clientes", rol: admin, body: map[string]interface{}{"PK_DOCUMENTO_CLIENTE": 2002, "NOMBRE": "Nuevo", "APELLIDO": "Cliente", "DIRECCION": "Calle 9", "TELEFONO": "3000000000", "OBSERVACIONES": "", "PASSWORD": "clave"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/clientes/", name: "cuerpo inválido", path: v1 + "/clientes", rol: admin, body: "{", status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/clientes/", name: "actualizar", path: fmt.Sprintf("%s/clientes?id=%d", v1, docCliente), rol: "cliente", body: map[string]interface{}{"NOMBRE": "Cliente", "APELLIDO": "Frecuente", "DIRECCION": "Calle 1 # 2-3", "TELEFONO": "3111111111"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/clientes/", name: "id inválido", path: v1 + "/clientes?id=abc", rol: admin, body: map[string]interface{}{}, status: http.StatusBadRequest},
+		{route: "DELETE /restaurante/v1/clientes/", name: "eliminar", path: v1 + "/clientes?id=2002", rol: admin, status: http.StatusOK},
+		{route: "DELETE /restaurante/v1/clientes/", name: "inexistente", path: v1 + "/clientes?id=9999", rol: admin, status: http.StatusNotFound},
+
+		// Restaurantes
+		{route: "GET /restaurante/v1/restaurantes/", name: "listar sin token", path: v1 + "/restaurantes", status: http.StatusOK},
+		{route: "GET /restaurante/v1/restaurantes/search", name: "por id", path: fmt.Sprintf("%s/restaurantes/search?id=%d", v1, fx.Restaurante), status: http.StatusOK},
+		{route: "GET /restaurante/v1/restaurantes/search", name: "id inválido", path: v1 + "/restaurantes/search?id=abc", status: http.StatusBadRequest},
+		{route: "POST /restaurante/v1/restaurantes/", name: "crear", path: v1 + "/restaurantes", body: map[string]interface{}{"pk_id_restaurante": 2, "nombre_restaurante": "Sede norte", "HORA_APERTURA": "09:00:00", "dias_laborales": "Lunes a Viernes"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/restaurantes/", name: "cuerpo inválido", path: v1 + "/restaurantes", body: "{", status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/restaurantes/", name: "actualizar", path: v1 + "/restaurantes?id=2", body: map[string]interface{}{"nombre_restaurante": "Sede norte 2"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/restaurantes/", name: "inexistente", path: v1 + "/restaurantes?id=99", body: map[string]interface{}{}, status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v1/restaurantes/", name: "eliminar", path: v1 + "/restaurantes?id=2", status: http.StatusOK},
+		{route: "DELETE /restaurante/v1/restaurantes/", name: "id inválido", path: v1 + "/restaurantes?id=abc", status: http.StatusBadRequest},
+
+		// Productos
+		{route: "GET /restaurante/v1/productos/", name: "listar disponibles", path: v1 + "/productos?onlyActive=true", status: http.StatusOK},
+		{route: "GET /restaurante/v1/productos/search", name: "por id", path: fmt.Sprintf("%s/productos/search?id=%d", v1, fx.Producto), status: http.StatusOK},
+		{route: "GET /restaurante/v1/productos/search", name: "inexistente", path: v1 + "/productos/search?id=9999", status: http.StatusNotFound},
+		{route: "POST /restaurante/v1/productos/", name: "crear", path: v1 + "/productos", body: multipartForm{"NOMBRE": "Arepa", "CALORIAS": "200", "DESCRIPCION": "Con queso", "ESTADO_PRODUCTO": "DISPONIBLE", "PRECIO": "5000", "CANTIDAD": "20"}, status: http.StatusCreated},
+		{route: "PUT /restaurante/v1/productos/", name: "actualizar", path: fmt.Sprintf("%s/productos?id=%d", v1, fx.Producto), headers: ifMatchAny, body: multipartForm{"NOMBRE": "Bandeja paisa", "CALORIAS": "700", "DESCRIPCION": "Plato típico", "ESTADO_PRODUCTO": "DISPONIBLE", "PRECIO": "26000", "CANTIDAD": "40"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/productos/", name: "sin If-Match", path: fmt.Sprintf("%s/productos?id=%d", v1, fx.Producto), body: multipartForm{"NOMBRE": "Bandeja"}, status: http.StatusPreconditionRequired},
+		{route: "DELETE /restaurante/v1/productos/", name: "desactivar", path: fmt.Sprintf("%s/productos?id=%d", v1, fx.ProductoBorrable), status: http.StatusOK},
+		{route: "DELETE /restaurante/v1/productos/", name: "ya desactivado", path: fmt.Sprintf("%s/productos?id=%d", v1, fx.ProductoBorrable), status: http.StatusBadRequest},
+
+		// Reservas
+		{route: "GET /restaurante/v1/reservas/", name: "listar", path: v1 + "/reservas", status: http.StatusOK},
+		{route: "GET /restaurante/v1/reservas/search", name: "por id", path: fmt.Sprintf("%s/reservas/search?id=%d", v1, fx.Reserva), status: http.StatusOK},
+		{route: "GET /restaurante/v1/reservas/search", name: "inexistente", path: v1 + "/reservas/search?id=9999", status: http.StatusNotFound},
+		{route: "POST /restaurante/v1/reservas/", name: "crear", path: v1 + "/reservas", body: map[string]interface{}{"FECHA": hoy, "HORA": "20:00:00", "PERSONAS": 2, "INDICACIONES": "Ventana", "CREATED_BY": "admin"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/reservas/", name: "sin fecha", path: v1 + "/reservas", body: map[string]interface{}{"HORA": "20:00:00", "PERSONAS": 2}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v1/reservas/", name: "estado inválido", path: v1 + "/reservas", body: map[string]interface{}{"FECHA": hoy, "HORA": "20:00:00", "PERSONAS": 2, "ESTADO_RESERVA": "PERDIDA"}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/reservas/", name: "confirmar", path: fmt.Sprintf("%s/reservas?id=%d", v1, fx.Reserva), headers: ifMatchAny, body: map[string]interface{}{"ESTADO_RESERVA": "CONFIRMADA", "UPDATED_BY": "admin"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/reservas/", name: "versión vieja", path: fmt.Sprintf("%s/reservas?id=%d", v1, fx.Reserva), headers: map[string]string{"If-Match": `"0"`}, body: map[string]interface{}{"PERSONAS": 3}, status: http.StatusPreconditionFailed},
+		{route: "PUT /restaurante/v1/reservas/", name: "hora inválida", path: fmt.Sprintf("%s/reservas?id=%d", v1, fx.Reserva), headers: ifMatchAny, body: map[string]interface{}{"HORA": "8pm"}, status: http.StatusBadRequest},
+		{route: "DELETE /restaurante/v1/reservas/", name: "cancelar", path: fmt.Sprintf("%s/reservas?id=%d", v1, fx.Reserva), status: http.StatusOK},
+		{route: "DELETE /restaurante/v1/reservas/", name: "id inválido", path: v1 + "/reservas?id=abc", status: http.StatusBadRequest},
+
+		// Cambios de horario
+		{route: "GET /restaurante/v1/cambios_horario/", name: "listar", path: v1 + "/cambios_horario", status: http.StatusOK},
+		{route: "GET /restaurante/v1/cambios_horario/actual", name: "horario de hoy", path: v1 + "/cambios_horario/actual", status: http.StatusOK},
+		{route: "POST /restaurante/v1/cambios_horario/", name: "crear", path: v1 + "/cambios_horario", body: map[string]interface{}{"FECHA": "2030-01-01", "ABIERTO": false}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/cambios_horario/", name: "fecha inválida", path: v1 + "/cambios_horario", body: map[string]interface{}{"FECHA": "01/01/2030", "ABIERTO": false}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/cambios_horario/", name: "actualizar", path: fmt.Sprintf("%s/cambios_horario?id=%d", v1, fx.CambioHorario), body: map[string]interface{}{"HORA_CIERRE": "22:00:00", "ABIERTO": true}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/cambios_horario/", name: "inexistente", path: v1 + "/cambios_horario?id=9999", body: map[string]interface{}{"ABIERTO": true}, status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v1/cambios_horario/", name: "eliminar", path: fmt.Sprintf("%s/cambios_horario?id=%d", v1, fx.CambioHorario), status: http.StatusOK},
+		{route: "DELETE /restaurante/v1/cambios_horario/", name: "inexistente", path: v1 + "/cambios_horario?id=9999", status: http.StatusNotFound},
+
+		// Métodos de pago
+		{route: "GET /restaurante/v1/metodos_pago/", name: "sin token", path: v1 + "/metodos_pago", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/metodos_pago/", name: "listar", path: v1 + "/metodos_pago", rol: "cliente", status: http.StatusOK},
+		{route: "GET /restaurante/v1/metodos_pago/search", name: "por id", path: fmt.Sprintf("%s/metodos_pago/search?id=%d", v1, fx.MetodoPago), rol: "cliente", status: http.StatusOK},
+		{route: "GET /restaurante/v1/metodos_pago/search", name: "inexistente", path: v1 + "/metodos_pago/search?id=9999", rol: "cliente", status: http.StatusNotFound},
+		{route: "POST /restaurante/v1/metodos_pago/", name: "crear", path: v1 + "/metodos_pago", rol: admin, body: map[string]interface{}{"TIPO": "EFECTIVO", "DETALLE": "Caja"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/metodos_pago/", name: "cuerpo inválido", path: v1 + "/metodos_pago", rol: admin, body: "{", status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/metodos_pago/", name: "actualizar", path: fmt.Sprintf("%s/metodos_pago?id=%d", v1, fx.MetodoPago), rol: admin, body: map[string]interface{}{"TIPO": "NEQUI", "DETALLE": "App"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/metodos_pago/", name: "inexistente", path: v1 + "/metodos_pago?id=9999", rol: admin, body: map[string]interface{}{"TIPO": "NEQUI"}, status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v1/metodos_pago/", name: "eliminar", path: fmt.Sprintf("%s/metodos_pago?id=%d", v1, fx.MetodoPago+1), rol: admin, status: http.StatusOK},
+		{route: "DELETE /restaurante/v1/metodos_pago/", name: "inexistente", path: v1 + "/metodos_pago?id=9999", rol: admin, status: http.StatusNotFound},
+
+		// Pagos
+		{route: "GET /restaurante/v1/pagos/", name: "sin token", path: v1 + "/pagos", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/pagos/", name: "listar pendientes", path: v1 + "/pagos?estado=PENDIENTE", rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v1/pagos/search", name: "por id", path: fmt.Sprintf("%s/pagos/search?id=%d", v1, fx.Pago), rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v1/pagos/search", name: "inexistente", path: v1 + "/pagos/search?id=9999", rol: admin, status: http.StatusNotFound},
+		{route: "POST /restaurante/v1/pagos/", name: "crear", path: v1 + "/pagos", rol: "Mesero", body: map[string]interface{}{"FECHA": hoy, "HORA": "14:00:00", "MONTO": 12000, "ESTADO_PAGO": "PENDIENTE", "PK_ID_METODO_PAGO": fx.MetodoPago, "UPDATED_BY": "mesero"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/pagos/", name: "hora inválida", path: v1 + "/pagos", rol: "Mesero", body: map[string]interface{}{"FECHA": hoy, "HORA": "2pm", "MONTO": 12000}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/pagos/", name: "actualizar", path: fmt.Sprintf("%s/pagos?id=%d", v1, fx.Pago), rol: admin, headers: ifMatchAny, body: map[string]interface{}{"HORA": "12:05:00", "MONTO": 26000, "PK_ID_METODO_PAGO": fx.MetodoPago, "UPDATED_BY": "admin"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/pagos/", name: "sin If-Match", path: fmt.Sprintf("%s/pagos?id=%d", v1, fx.Pago), rol: admin, body: map[string]interface{}{"MONTO": 1}, status: http.StatusPreconditionRequired},
+		{route: "DELETE /restaurante/v1/pagos/", name: "eliminar", path: fmt.Sprintf("%s/pagos?id=%d", v1, fx.PagoBorrable), rol: admin, status: http.StatusOK},
+		{route: "DELETE /restaurante/v1/pagos/", name: "inexistente", path: v1 + "/pagos?id=9999", rol: admin, status: http.StatusNotFound},
+
+		// Domicilios
+		{route: "GET /restaurante/v1/domicilios/", name: "sin token", path: v1 + "/domicilios", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/domicilios/", name: "listar", path: v1 + "/domicilios", rol: "Domiciliario", status: http.StatusOK},
+		{route: "GET /restaurante/v1/domicilios/search", name: "por id", path: fmt.Sprintf("%s/domicilios/search?id=%d", v1, fx.Domicilio), rol: "Domiciliario", status: http.StatusOK},
+		{route: "GET /restaurante/v1/domicilios/search", name: "inexistente", path: v1 + "/domicilios/search?id=9999", rol: "Domiciliario", status: http.StatusNotFound},
+		{route: "POST /restaurante/v1/domicilios/", name: "crear", path: v1 + "/domicilios", rol: "Mesero", body: map[string]interface{}{"DIRECCION": "Carrera 5", "TELEFONO": "3009876543", "FECHA": hoy, "ESTADO_PAGO": "PENDIENTE", "ENTREGADO": false, "OBSERVACIONES": "", "CREATED_BY": "mesero"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/domicilios/", name: "sin dirección", path: v1 + "/domicilios", rol: "Mesero", body: map[string]interface{}{"TELEFONO": "3009876543", "FECHA": hoy}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/domicilios/", name: "marcar entregado", path: fmt.Sprintf("%s/domicilios?id=%d", v1, fx.Domicilio), rol: "Domiciliario", headers: ifMatchAny, body: map[string]interface{}{"ENTREGADO": true, "UPDATED_BY": "domiciliario"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/domicilios/", name: "versión vieja", path: fmt.Sprintf("%s/domicilios?id=%d", v1, fx.Domicilio), rol: "Domiciliario", headers: map[string]string{"If-Match": `"0"`}, body: map[string]interface{}{"ENTREGADO": false}, status: http.StatusPreconditionFailed},
+		{route: "DELETE /restaurante/v1/domicilios/", name: "eliminar", path: fmt.Sprintf("%s/domicilios?id=%d", v1, fx.DomicilioBorrado), rol: admin, status: http.StatusOK},
+		{route: "DELETE /restaurante/v1/domicilios/", name: "inexistente", path: v1 + "/domicilios?id=9999", rol: admin, status: http.StatusNotFound},
+
+		// Trabajadores
+		{route: "GET /restaurante/v1/trabajadores/", name: "sin token", path: v1 + "/trabajadores", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/trabajadores/", name: "listar por rol", path: v1 + "/trabajadores?rol=Mesero", rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v1/trabajadores/search", name: "por documento", path: fmt.Sprintf("%s/trabajadores/search?id=%d", v1, docMesero), rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v1/trabajadores/search", name: "inexistente", path: v1 + "/trabajadores/search?id=9999", rol: admin, status: http.StatusNotFound},
+		{route: "POST /restaurante/v1/trabajadores/", name: "crear", path: v1 + "/trabajadores", rol: admin, body: map[string]interface{}{"PK_DOCUMENTO_TRABAJADOR": 1004, "NOMBRE": "Nuevo", "APELLIDO": "Cocinero", "ROL": "Cocinero", "FECHA_INGRESO": hoy, "FECHA_NACIMIENTO": "1990-01-01", "SUELDO": 1300000, "PASSWORD": "clave", "TELEFONO": "3001112233"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/trabajadores/", name: "sin nombre", path: v1 + "/trabajadores", rol: admin, body: map[string]interface{}{"PK_DOCUMENTO_TRABAJADOR": 1005}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/trabajadores/", name: "actualizar", path: v1 + "/trabajadores?id=1004", rol: admin, headers: ifMatchAny, body: map[string]interface{}{"HORARIO": "Mañana", "NUEVO": false}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/trabajadores/", name: "sin If-Match", path: v1 + "/trabajadores?id=1004", rol: admin, body: map[string]interface{}{"NUEVO": true}, status: http.StatusPreconditionRequired},
+		{route: "DELETE /restaurante/v1/trabajadores/", name: "retirar", path: v1 + "/trabajadores?id=1004", rol: admin, status: http.StatusOK},
+		{route: "DELETE /restaurante/v1/trabajadores/", name: "inexistente", path: v1 + "/trabajadores?id=9999", rol: admin, status: http.StatusNotFound},
+
+		// Pedidos
+		{route: "GET /restaurante/v1/pedidos/", name: "sin token", path: v1 + "/pedidos", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/pedidos/", name: "listar del cliente", path: fmt.Sprintf("%s/pedidos?cliente=%d", v1, docCliente), rol: "cliente", status: http.StatusOK},
+		{route: "GET /restaurante/v1/pedidos/", name: "filtrar por mes", path: v1 + "/pedidos?mes=12&anio=2024&metodo_pago=nequi", rol: admin, status: http.StatusOK, postgres: true},
+		{route: "POST /restaurante/v1/pedidos/", name: "crear", path: v1 + "/pedidos", rol: "Mesero", body: "DELIVERY=false&UPDATED_BY=mesero", headers: formHeaders, status: http.StatusOK},
+		{route: "POST /restaurante/v1/pedidos/", name: "datos inválidos", path: v1 + "/pedidos", rol: "Mesero", body: "DELIVERY=quizas", headers: formHeaders, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/pedidos/", name: "actualizar", path: fmt.Sprintf("%s/pedidos?id=%d", v1, fx.Pedido), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"DELIVERY": true}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/pedidos/", name: "sin If-Match", path: fmt.Sprintf("%s/pedidos?id=%d", v1, fx.Pedido), rol: "Mesero", body: map[string]interface{}{"DELIVERY": false}, status: http.StatusPreconditionRequired},
+		{route: "POST /restaurante/v1/pedidos/asignar-domicilio", name: "asignar", path: fmt.Sprintf("%s/pedidos/asignar-domicilio?pedido_id=%d&domicilio_id=%d", v1, fx.Pedido, fx.Domicilio), rol: "Mesero", status: http.StatusOK},
+		{route: "POST /restaurante/v1/pedidos/asignar-domicilio", name: "domicilio inexistente", path: fmt.Sprintf("%s/pedidos/asignar-domicilio?pedido_id=%d&domicilio_id=9999", v1, fx.Pedido), rol: "Mesero", status: http.StatusUnprocessableEntity},
+		{route: "POST /restaurante/v1/pedidos/asignar-pago", name: "asignar", path: fmt.Sprintf("%s/pedidos/asignar-pago?pedido_id=%d&pago_id=%d", v1, fx.Pedido, fx.Pago), rol: "Mesero", status: http.StatusOK},
+		{route: "POST /restaurante/v1/pedidos/asignar-pago", name: "pedido inexistente", path: fmt.Sprintf("%s/pedidos/asignar-pago?pedido_id=9999&pago_id=%d", v1, fx.Pago), rol: "Mesero", status: http.StatusNotFound},
+		{route: "PUT /restaurante/v1/pedidos/actualizar-estado", name: "cambiar estado", path: fmt.Sprintf("%s/pedidos/actualizar-estado?pedido_id=%d&estado=EN+PREPARACION", v1, fx.Pedido), rol: "Mesero", status: http.StatusOK},
+		{route: "PUT /restaurante/v1/pedidos/actualizar-estado", name: "sin estado", path: fmt.Sprintf("%s/pedidos/actualizar-estado?pedido_id=%d", v1, fx.Pedido), rol: "Mesero", status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/pedidos/actualizar-estado", name: "estado desconocido", path: fmt.Sprintf("%s/pedidos/actualizar-estado?pedido_id=%d&estado=PERDIDO", v1, fx.Pedido), rol: "Mesero", status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/pedidos/actualizar-estado", name: "saltar a entregado", path: fmt.Sprintf("%s/pedidos/actualizar-estado?pedido_id=%d&estado=ENTREGADO", v1, fx.Pedido), rol: "Mesero", status: http.StatusConflict},
+		{route: "GET /restaurante/v1/pedidos/detalles", name: "detalles", path: fmt.Sprintf("%s/pedidos/detalles?pedido_id=%d", v1, fx.Pedido), rol: "cliente", status: http.StatusOK},
+		{route: "GET /restaurante/v1/pedidos/detalles", name: "pedido de otro cliente", path: fmt.Sprintf("%s/pedidos/detalles?pedido_id=%d", v1, fx.PedidoSalon), rol: "cliente", status: http.StatusNotFound},
+		{route: "GET /restaurante/v1/pedidos/detalles", name: "sin pedido", path: v1 + "/pedidos/detalles", rol: "cliente", status: http.StatusBadRequest},
+		{route: "DELETE /restaurante/v1/pedidos/", name: "cliente con pedido en cocina", path: fmt.Sprintf("%s/pedidos?id=%d&motivo=CLIENTE_DESISTE", v1, fx.Pedido), rol: "cliente", status: http.StatusConflict},
+		{route: "DELETE /restaurante/v1/pedidos/", name: "motivo inválido", path: fmt.Sprintf("%s/pedidos?id=%d&motivo=PERDIDO", v1, fx.Pedido), rol: admin, status: http.StatusBadRequest},
+		{route: "DELETE /restaurante/v1/pedidos/", name: "inexistente", path: v1 + "/pedidos?id=9999", rol: admin, status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v1/pedidos/", name: "cliente desiste", path: fmt.Sprintf("%s/pedidos?id=%d&motivo=CLIENTE_DESISTE", v1, fx.PedidoCancelable), rol: "cliente", status: http.StatusOK},
+		{route: "DELETE /restaurante/v1/pedidos/", name: "sin token", path: fmt.Sprintf("%s/pedidos?id=%d", v1, fx.Pedido), status: http.StatusUnauthorized},
+
+		// Pedido de clientes
+		{route: "GET /restaurante/v1/pedido_clientes/", name: "sin token", path: v1 + "/pedido_clientes", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/pedido_clientes/", name: "listar", path: v1 + "/pedido_clientes", rol: admin, status: http.StatusOK},
+		{route: "POST /restaurante/v1/pedido_clientes/", name: "crear", path: v1 + "/pedido_clientes", rol: "cliente", body: map[string]interface{}{"PK_DOCUMENTO_CLIENTE": docCliente, "PK_ID_PEDIDO": fx.PedidoV2}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/pedido_clientes/", name: "pedido inexistente", path: v1 + "/pedido_clientes", rol: "cliente", body: map[string]interface{}{"PK_DOCUMENTO_CLIENTE": docCliente, "PK_ID_PEDIDO": 9999}, status: http.StatusUnprocessableEntity},
+
+		// Productos de un pedido
+		{route: "GET /restaurante/v1/producto_pedido/", name: "sin token", path: fmt.Sprintf("%s/producto_pedido?pedido_id=%d", v1, fx.Pedido), status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/producto_pedido/", name: "productos del pedido", path: fmt.Sprintf("%s/producto_pedido?pedido_id=%d", v1, fx.Pedido), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v1/producto_pedido/", name: "sin pedido", path: v1 + "/producto_pedido", rol: "Mesero", status: http.StatusBadRequest},
+		{route: "POST /restaurante/v1/producto_pedido/", name: "registrar", path: v1 + "/producto_pedido", rol: "Mesero", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoV2, "DETALLES_PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 2}}}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/producto_pedido/", name: "con modificadores", path: v1 + "/producto_pedido", rol: "Mesero", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoV2, "DETALLES_PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 1, "NOTAS": "Sin cebolla", "MODIFICADORES": []int64{fx.OpcionModificador}}}}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/producto_pedido/", name: "modificador inexistente", path: v1 + "/producto_pedido", rol: "Mesero", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoV2, "DETALLES_PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 1, "MODIFICADORES": []int64{9999}}}}, status: http.StatusUnprocessableEntity},
+		{route: "POST /restaurante/v1/producto_pedido/", name: "producto inexistente", path: v1 + "/producto_pedido", rol: "Mesero", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoV2, "DETALLES_PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": 9999, "CANTIDAD": 1}}}, status: http.StatusUnprocessableEntity},
+		{route: "PUT /restaurante/v1/producto_pedido/", name: "actualizar", path: fmt.Sprintf("%s/producto_pedido?pedido_id=%d", v1, fx.Pedido), rol: "Mesero", body: []map[string]interface{}{{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 3}}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/producto_pedido/", name: "cuerpo inválido", path: fmt.Sprintf("%s/producto_pedido?pedido_id=%d", v1, fx.Pedido), rol: "Mesero", body: "{", status: http.StatusBadRequest},
+		{route: "POST /restaurante/v1/producto_pedido/", name: "sin stock suficiente", path: v1 + "/producto_pedido", rol: "Mesero", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoV2, "DETALLES_PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 500}}}, status: http.StatusConflict},
+
+		// Movimientos de inventario
+		{route: "GET /restaurante/v1/movimientos_stock/", name: "sin token", path: fmt.Sprintf("%s/movimientos_stock?producto_id=%d", v1, fx.Producto), status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/movimientos_stock/", name: "listar", path: fmt.Sprintf("%s/movimientos_stock?producto_id=%d", v1, fx.Producto), rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v1/movimientos_stock/", name: "sin producto", path: v1 + "/movimientos_stock", rol: admin, status: http.StatusBadRequest},
+		{route: "GET /restaurante/v1/movimientos_stock/", name: "producto inexistente", path: v1 + "/movimientos_stock?producto_id=9999", rol: admin, status: http.StatusNotFound},
+		{route: "POST /restaurante/v1/movimientos_stock/", name: "reabastecer", path: v1 + "/movimientos_stock", rol: admin, body: map[string]interface{}{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 5, "MOTIVO": "REABASTECIMIENTO"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/movimientos_stock/", name: "motivo desconocido", path: v1 + "/movimientos_stock", rol: admin, body: map[string]interface{}{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 5, "MOTIVO": "REGALO"}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v1/movimientos_stock/", name: "merma mayor al stock", path: v1 + "/movimientos_stock", rol: admin, body: map[string]interface{}{"PK_ID_PRODUCTO": fx.ProductoBorrable, "CANTIDAD": 500, "MOTIVO": "MERMA"}, status: http.StatusConflict},
+
+		// Nóminas
+		{route: "GET /restaurante/v1/nominas/", name: "sin token", path: v1 + "/nominas", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/nominas/", name: "listar", path: v1 + "/nominas", rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v1/nominas/", name: "sin resultados", path: v1 + "/nominas?anio=1990", rol: admin, status: http.StatusNotFound},
+		{route: "POST /restaurante/v1/nominas/", name: "crear", path: v1 + "/nominas", rol: admin, body: map[string]interface{}{"ESTADO_NOMINA": "NO PAGO"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/nominas/", name: "cuerpo inválido", path: v1 + "/nominas", rol: admin, body: "{", status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/nominas/", name: "marcar pagada", path: fmt.Sprintf("%s/nominas?id=%d", v1, fx.Nomina), rol: admin, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/nominas/", name: "inexistente", path: v1 + "/nominas?id=9999", rol: admin, status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v1/nominas/", name: "id inválido", path: v1 + "/nominas?id=abc", rol: admin, status: http.StatusBadRequest},
+
+		// Incidencias
+		{route: "GET /restaurante/v1/incidencias/", name: "sin token", path: v1 + "/incidencias", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/incidencias/", name: "listar", path: v1 + "/incidencias", rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v1/incidencias/search", name: "por trabajador y mes", path: fmt.Sprintf("%s/incidencias/search?documento=%d&mes=%d&anio=%d", v1, docMesero, fixtureDate.Month(), fixtureDate.Year()), rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v1/incidencias/search", name: "mes inválido", path: fmt.Sprintf("%s/incidencias/search?documento=%d&mes=13&anio=2024", v1, docMesero), rol: admin, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v1/incidencias/", name: "crear", path: v1 + "/incidencias", rol: admin, body: map[string]interface{}{"FECHA": hoy, "MONTO": 10000, "RESTA": true, "MOTIVO": "Retardo", "PK_DOCUMENTO_TRABAJADOR": docMesero}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/incidencias/", name: "sin motivo", path: v1 + "/incidencias", rol: admin, body: map[string]interface{}{"FECHA": hoy, "MONTO": 10000, "PK_DOCUMENTO_TRABAJADOR": docMesero}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v1/incidencias/", name: "actualizar", path: fmt.Sprintf("%s/incidencias?id=%d", v1, fx.Incidencia), rol: admin, body: map[string]interface{}{"MONTO": 25000, "MOTIVO": "Horas extra dominicales"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v1/incidencias/", name: "inexistente", path: v1 + "/incidencias?id=9999", rol: admin, body: map[string]interface{}{"MONTO": 1}, status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v1/incidencias/", name: "eliminar", path: fmt.Sprintf("%s/incidencias?id=%d", v1, fx.Incidencia), rol: admin, status: http.StatusOK},
+		{route: "DELETE /restaurante/v1/incidencias/", name: "inexistente", path: v1 + "/incidencias?id=9999", rol: admin, status: http.StatusNotFound},
+
+		// Nóminas de trabajadores
+		{route: "GET /restaurante/v1/nomina_trabajador/", name: "sin token", path: v1 + "/nomina_trabajador", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v1/nomina_trabajador/", name: "listar", path: v1 + "/nomina_trabajador", rol: admin, status: http.StatusOK},
+		{route: "POST /restaurante/v1/nomina_trabajador/", name: "liquidar", path: v1 + "/nomina_trabajador", rol: admin, body: map[string]interface{}{"PK_DOCUMENTO_TRABAJADOR": docDomiciliario, "DETALLES": "Nómina del mes"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v1/nomina_trabajador/", name: "trabajador inexistente", path: v1 + "/nomina_trabajador", rol: admin, body: map[string]interface{}{"PK_DOCUMENTO_TRABAJADOR": 9999}, status: http.StatusUnprocessableEntity},
+		{route: "GET /restaurante/v1/nomina_trabajador/search", name: "del trabajador", path: fmt.Sprintf("%s/nomina_trabajador/search?documento=%d", v1, docMesero), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v1/nomina_trabajador/search", name: "sin documento", path: v1 + "/nomina_trabajador/search", rol: "Mesero", status: http.StatusBadRequest},
+		{route: "GET /restaurante/v1/nomina_trabajador/mes", name: "del mes", path: fmt.Sprintf("%s/nomina_trabajador/mes?mes=%d&anio=%d", v1, fixtureDate.Month(), fixtureDate.Year()), rol: admin, status: http.StatusOK, postgres: true},
+		{route: "GET /restaurante/v1/nomina_trabajador/mes", name: "mes inválido", path: v1 + "/nomina_trabajador/mes?mes=13&anio=2024", rol: admin, status: http.StatusBadRequest},
+
+		{route: "PUT /restaurante/v1/nomina_trabajador/", name: "no implementado", path: fmt.Sprintf("%s/nomina_trabajador?id=%d", v1, fx.NominaTrabajador), rol: admin, body: map[string]interface{}{}, status: http.StatusMethodNotAllowed},
+		{route: "DELETE /restaurante/v1/nomina_trabajador/", name: "no implementado", path: fmt.Sprintf("%s/nomina_trabajador?id=%d", v1, fx.NominaTrabajador), rol: admin, status: http.StatusMethodNotAllowed},
+		{route: "DELETE /restaurante/v1/nomina_trabajador/", name: "sin token", path: fmt.Sprintf("%s/nomina_trabajador?id=%d", v1, fx.NominaTrabajador), status: http.StatusUnauthorized},
+
+		// API v2: pedidos
+		{route: "GET /restaurante/v2/pedidos/", name: "sin token", path: v2 + "/pedidos", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v2/pedidos/", name: "listar", path: v2 + "/pedidos?domicilio=false", rol: "Mesero", status: http.StatusOK},
+		{route: "POST /restaurante/v2/pedidos/", name: "crear", path: v2 + "/pedidos", rol: "Mesero", body: map[string]interface{}{"DELIVERY": true}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/pedidos/", name: "cuerpo inválido", path: v2 + "/pedidos", rol: "Mesero", body: "{", status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/pedidos/checkout", name: "pedido completo", path: v2 + "/pedidos/checkout", rol: "cliente", body: map[string]interface{}{"PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 2}}, "DOMICILIO": map[string]interface{}{"OBSERVACIONES": "Portería"}, "PK_ID_METODO_PAGO": fx.MetodoPago}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/pedidos/checkout", name: "a nombre de otro cliente", path: v2 + "/pedidos/checkout", rol: "cliente", body: map[string]interface{}{"PK_DOCUMENTO_CLIENTE": 9999, "PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 1}}, "PK_ID_METODO_PAGO": fx.MetodoPago}, status: http.StatusForbidden},
+		{route: "POST /restaurante/v2/pedidos/checkout", name: "producto inexistente", path: v2 + "/pedidos/checkout", rol: "Mesero", body: map[string]interface{}{"PK_DOCUMENTO_CLIENTE": docCliente, "PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": 9999, "CANTIDAD": 1}}, "PK_ID_METODO_PAGO": fx.MetodoPago}, status: http.StatusUnprocessableEntity},
+		{route: "POST /restaurante/v2/pedidos/checkout", name: "sin productos", path: v2 + "/pedidos/checkout", rol: "Mesero", body: map[string]interface{}{"PK_DOCUMENTO_CLIENTE": docCliente, "PK_ID_METODO_PAGO": fx.MetodoPago}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/pedidos/checkout", name: "sin token", path: v2 + "/pedidos/checkout", body: map[string]interface{}{}, status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v2/pedidos/:id:int", name: "por id", path: fmt.Sprintf("%s/pedidos/%d", v2, fx.PedidoV2), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/pedidos/:id:int", name: "inexistente", path: v2 + "/pedidos/9999", rol: "Mesero", status: http.StatusNotFound},
+		{route: "GET /restaurante/v2/pedidos/:id:int", name: "sin token", path: fmt.Sprintf("%s/pedidos/%d", v2, fx.PedidoV2), status: http.StatusUnauthorized},
+		{route: "PUT /restaurante/v2/pedidos/:id:int", name: "actualizar", path: fmt.Sprintf("%s/pedidos/%d", v2, fx.PedidoV2), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"DELIVERY": true}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/pedidos/:id:int", name: "sin If-Match", path: fmt.Sprintf("%s/pedidos/%d", v2, fx.PedidoV2), rol: "Mesero", body: map[string]interface{}{"DELIVERY": false}, status: http.StatusPreconditionRequired},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/pago", name: "pago inexistente", path: fmt.Sprintf("%s/pedidos/%d/pago", v2, fx.PedidoV2), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"PK_ID_PAGO": 9999}, status: http.StatusUnprocessableEntity},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/pago", name: "sin pago", path: fmt.Sprintf("%s/pedidos/%d/pago", v2, fx.PedidoV2), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/domicilio", name: "asignar", path: fmt.Sprintf("%s/pedidos/%d/domicilio", v2, fx.PedidoV2), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"PK_ID_DOMICILIO": fx.Domicilio}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/domicilio", name: "pedido inexistente", path: v2 + "/pedidos/9999/domicilio", rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"PK_ID_DOMICILIO": fx.Domicilio}, status: http.StatusNotFound},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/programacion", name: "muy pronto", path: fmt.Sprintf("%s/pedidos/%d/programacion", v2, fx.PedidoV2), rol: "cliente", headers: ifMatchAny, body: map[string]interface{}{"PROGRAMADO_PARA": pronto}, status: http.StatusUnprocessableEntity},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/programacion", name: "formato inválido", path: fmt.Sprintf("%s/pedidos/%d/programacion", v2, fx.PedidoV2), rol: "cliente", headers: ifMatchAny, body: map[string]interface{}{"PROGRAMADO_PARA": "mañana a las 12"}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/programacion", name: "sin campo", path: fmt.Sprintf("%s/pedidos/%d/programacion", v2, fx.PedidoV2), rol: "cliente", headers: ifMatchAny, body: map[string]interface{}{}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/programacion", name: "pedido de otro cliente", path: fmt.Sprintf("%s/pedidos/%d/programacion", v2, fx.PedidoSalon), rol: "cliente", headers: ifMatchAny, body: map[string]interface{}{"PROGRAMADO_PARA": ""}, status: http.StatusForbidden},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/programacion", name: "pedido inmediato", path: fmt.Sprintf("%s/pedidos/%d/programacion", v2, fx.PedidoV2), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"PROGRAMADO_PARA": ""}, status: http.StatusOK},
+		{route: "GET /restaurante/v2/pedidos/programados", name: "en espera", path: v2 + "/pedidos/programados", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/pedidos/programados", name: "cliente", path: v2 + "/pedidos/programados", rol: "cliente", status: http.StatusForbidden},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/estado", name: "sin If-Match", path: fmt.Sprintf("%s/pedidos/%d/estado", v2, fx.PedidoV2), rol: "Mesero", body: map[string]interface{}{"ESTADO_PEDIDO": "EN PREPARACION"}, status: http.StatusPreconditionRequired},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/estado", name: "versión vieja", path: fmt.Sprintf("%s/pedidos/%d/estado", v2, fx.PedidoV2), rol: "Mesero", headers: map[string]string{"If-Match": `"999"`}, body: map[string]interface{}{"ESTADO_PEDIDO": "EN PREPARACION"}, status: http.StatusPreconditionFailed},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/pago", name: "sin If-Match", path: fmt.Sprintf("%s/pedidos/%d/pago", v2, fx.PedidoV2), rol: "Mesero", body: map[string]interface{}{"PK_ID_PAGO": fx.Pago}, status: http.StatusPreconditionRequired},
+		{route: "GET /restaurante/v2/pedidos/:id:int", name: "pedido propio", path: fmt.Sprintf("%s/pedidos/%d", v2, fx.PedidoV2), rol: "cliente", status: http.StatusOK},
+		{route: "GET /restaurante/v2/pedidos/:id:int", name: "pedido de otro cliente", path: fmt.Sprintf("%s/pedidos/%d", v2, fx.PedidoSalon), rol: "cliente", status: http.StatusNotFound},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/estado", name: "cliente no lo lleva a la cocina", path: fmt.Sprintf("%s/pedidos/%d/estado", v2, fx.PedidoV2), rol: "cliente", headers: ifMatchAny, body: map[string]interface{}{"ESTADO_PEDIDO": "EN PREPARACION"}, status: http.StatusForbidden},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/estado", name: "pedido de otro cliente", path: fmt.Sprintf("%s/pedidos/%d/estado", v2, fx.PedidoSalon), rol: "cliente", headers: ifMatchAny, body: map[string]interface{}{"ESTADO_PEDIDO": "CANCELADO"}, status: http.StatusNotFound},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/pago", name: "pedido de otro cliente", path: fmt.Sprintf("%s/pedidos/%d/pago", v2, fx.PedidoSalon), rol: "cliente", headers: ifMatchAny, body: map[string]interface{}{"PK_ID_PAGO": fx.Pago}, status: http.StatusNotFound},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/propina", name: "pedido de otro cliente", path: fmt.Sprintf("%s/pedidos/%d/propina", v2, fx.PedidoSalon), rol: "cliente", headers: ifMatchAny, body: map[string]interface{}{"PROPINA_ACEPTADA": false}, status: http.StatusNotFound},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/promocion", name: "pedido de otro cliente", path: fmt.Sprintf("%s/pedidos/%d/promocion", v2, fx.PedidoSalon), rol: "cliente", headers: ifMatchAny, body: map[string]interface{}{"CODIGO_PROMOCION": ""}, status: http.StatusNotFound},
+		{route: "POST /restaurante/v2/pedidos/:id:int/division", name: "pedido de otro cliente", path: fmt.Sprintf("%s/pedidos/%d/division", v2, fx.PedidoSalon), rol: "cliente", body: map[string]interface{}{"MODO": "IGUALES", "PARTES": []map[string]interface{}{{"PK_ID_METODO_PAGO": fx.MetodoPago}}}, status: http.StatusNotFound},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/estado", name: "cambiar estado", path: fmt.Sprintf("%s/pedidos/%d/estado", v2, fx.PedidoV2), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"ESTADO_PEDIDO": "EN PREPARACION"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/estado", name: "sin estado", path: fmt.Sprintf("%s/pedidos/%d/estado", v2, fx.PedidoV2), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/estado", name: "volver a iniciado", path: fmt.Sprintf("%s/pedidos/%d/estado", v2, fx.PedidoV2), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"ESTADO_PEDIDO": "INICIADO"}, status: http.StatusConflict},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/programacion", name: "ya en cocina", path: fmt.Sprintf("%s/pedidos/%d/programacion", v2, fx.PedidoV2), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"PROGRAMADO_PARA": ""}, status: http.StatusConflict},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/propina", name: "aceptar propina", path: fmt.Sprintf("%s/pedidos/%d/propina", v2, fx.PedidoSalon), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"PROPINA_ACEPTADA": true}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/propina", name: "pedido a domicilio", path: fmt.Sprintf("%s/pedidos/%d/propina", v2, fx.PedidoV2), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"PROPINA_ACEPTADA": true}, status: http.StatusConflict},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/propina", name: "sin campo", path: fmt.Sprintf("%s/pedidos/%d/propina", v2, fx.PedidoSalon), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{}, status: http.StatusBadRequest},
+		{route: "GET /restaurante/v2/pedidos/:id:int/recibo", name: "recibo", path: fmt.Sprintf("%s/pedidos/%d/recibo", v2, fx.PedidoSalon), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/pedidos/:id:int/recibo", name: "inexistente", path: v2 + "/pedidos/9999/recibo", rol: "Mesero", status: http.StatusNotFound},
+		{route: "GET /restaurante/v2/pedidos/:id:int/recibo", name: "sin token", path: fmt.Sprintf("%s/pedidos/%d/recibo", v2, fx.PedidoSalon), status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v2/pedidos/:id:int/recibo", name: "tiquete ESC/POS de 58 mm", path: fmt.Sprintf("%s/pedidos/%d/recibo?formato=escpos&ancho=58", v2, fx.PedidoSalon), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/pedidos/:id:int/recibo", name: "tiquete en PDF", path: fmt.Sprintf("%s/pedidos/%d/recibo?formato=pdf", v2, fx.PedidoSalon), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/pedidos/:id:int/recibo", name: "formato desconocido", path: fmt.Sprintf("%s/pedidos/%d/recibo?formato=docx", v2, fx.PedidoSalon), rol: "Mesero", status: http.StatusBadRequest},
+		{route: "GET /restaurante/v2/pedidos/:id:int/recibo", name: "ancho no soportado", path: fmt.Sprintf("%s/pedidos/%d/recibo?formato=texto&ancho=70", v2, fx.PedidoSalon), rol: "Mesero", status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/promocion", name: "código válido", path: fmt.Sprintf("%s/pedidos/%d/promocion", v2, fx.PedidoSalon), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"CODIGO_PROMOCION": "bienvenida"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/promocion", name: "código inexistente", path: fmt.Sprintf("%s/pedidos/%d/promocion", v2, fx.PedidoSalon), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"CODIGO_PROMOCION": "NOEXISTE"}, status: http.StatusUnprocessableEntity},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/promocion", name: "sin campo", path: fmt.Sprintf("%s/pedidos/%d/promocion", v2, fx.PedidoSalon), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{}, status: http.StatusBadRequest},
+		{route: "GET /restaurante/v2/pedidos/:id:int/cuenta", name: "cuenta", path: fmt.Sprintf("%s/pedidos/%d/cuenta", v2, fx.PedidoSalon), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/pedidos/:id:int/cuenta", name: "inexistente", path: v2 + "/pedidos/9999/cuenta", rol: "Mesero", status: http.StatusNotFound},
+		{route: "GET /restaurante/v2/pedidos/:id:int/cuenta", name: "sin token", path: fmt.Sprintf("%s/pedidos/%d/cuenta", v2, fx.PedidoSalon), status: http.StatusUnauthorized},
+		{route: "POST /restaurante/v2/pedidos/:id:int/division", name: "partes iguales", path: fmt.Sprintf("%s/pedidos/%d/division", v2, fx.PedidoSalon), rol: "Mesero", body: map[string]interface{}{"MODO": "IGUALES", "PARTES": []map[string]interface{}{{"PK_ID_METODO_PAGO": fx.MetodoPago}, {"PK_ID_METODO_PAGO": fx.MetodoPago}}}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/pedidos/:id:int/division", name: "con pagos pendientes", path: fmt.Sprintf("%s/pedidos/%d/division", v2, fx.PedidoSalon), rol: "Mesero", body: map[string]interface{}{"MODO": "IGUALES", "PARTES": []map[string]interface{}{{"PK_ID_METODO_PAGO": fx.MetodoPago}}}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/pedidos/:id:int/division", name: "modo inválido", path: fmt.Sprintf("%s/pedidos/%d/division", v2, fx.PedidoSalon), rol: "Mesero", body: map[string]interface{}{"MODO": "MITAD", "PARTES": []map[string]interface{}{{"PK_ID_METODO_PAGO": fx.MetodoPago}}}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/pedidos/:id:int/division", name: "inexistente", path: v2 + "/pedidos/9999/division", rol: "Mesero", body: map[string]interface{}{"MODO": "IGUALES", "PARTES": []map[string]interface{}{{"PK_ID_METODO_PAGO": fx.MetodoPago}}}, status: http.StatusNotFound},
+		{route: "POST /restaurante/v2/pedidos/:id:int/division", name: "sin token", path: fmt.Sprintf("%s/pedidos/%d/division", v2, fx.PedidoSalon), body: map[string]interface{}{}, status: http.StatusUnauthorized},
+		{route: "POST /restaurante/v2/pedidos/:id:int/cancelacion", name: "sin motivo", path: fmt.Sprintf("%s/pedidos/%d/cancelacion", v2, fx.PedidoV2), rol: "Mesero", body: map[string]interface{}{}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/pedidos/:id:int/cancelacion", name: "pedido de otro cliente", path: fmt.Sprintf("%s/pedidos/%d/cancelacion", v2, fx.PedidoSalon), rol: "cliente", body: map[string]interface{}{"MOTIVO": "CLIENTE_DESISTE"}, status: http.StatusForbidden},
+		{route: "POST /restaurante/v2/pedidos/:id:int/cancelacion", name: "ya cancelado", path: fmt.Sprintf("%s/pedidos/%d/cancelacion", v2, fx.PedidoCancelable), rol: admin, body: map[string]interface{}{"MOTIVO": "OTRO"}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/pedidos/:id:int/cancelacion", name: "inexistente", path: v2 + "/pedidos/9999/cancelacion", rol: admin, body: map[string]interface{}{"MOTIVO": "OTRO"}, status: http.StatusNotFound},
+		{route: "POST /restaurante/v2/pedidos/:id:int/cancelacion", name: "cancelar", path: fmt.Sprintf("%s/pedidos/%d/cancelacion", v2, fx.PedidoV2), rol: admin, body: map[string]interface{}{"MOTIVO": "SIN_PRODUCTO", "DETALLE": "Se acabó la bandeja"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/pedidos/:id:int/cancelacion", name: "sin token", path: fmt.Sprintf("%s/pedidos/%d/cancelacion", v2, fx.PedidoV2), body: map[string]interface{}{"MOTIVO": "OTRO"}, status: http.StatusUnauthorized},
+
+		// API v2: pagos, domicilios y trabajadores
+		{route: "GET /restaurante/v2/pagos/:id:int", name: "por id", path: fmt.Sprintf("%s/pagos/%d", v2, fx.Pago), rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v2/pagos/:id:int", name: "sin token", path: fmt.Sprintf("%s/pagos/%d", v2, fx.Pago), status: http.StatusUnauthorized},
+		{route: "DELETE /restaurante/v2/pagos/:id:int", name: "inexistente", path: v2 + "/pagos/9999", rol: admin, status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v2/pagos/:id:int", name: "pago cobrado", path: fmt.Sprintf("%s/pagos/%d", v2, fx.Pago), rol: admin, status: http.StatusConflict},
+		{route: "GET /restaurante/v2/domicilios/:id:int", name: "por id", path: fmt.Sprintf("%s/domicilios/%d", v2, fx.Domicilio), rol: "Domiciliario", status: http.StatusOK},
+		{route: "GET /restaurante/v2/domicilios/:id:int", name: "inexistente", path: v2 + "/domicilios/9999", rol: "Domiciliario", status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v2/domicilios/:id:int", name: "inexistente", path: v2 + "/domicilios/9999", rol: admin, status: http.StatusNotFound},
+		{route: "PUT /restaurante/v2/domicilios/:id:int/domiciliario", name: "asignar", path: fmt.Sprintf("%s/domicilios/%d/domiciliario", v2, fx.Domicilio), rol: admin, body: map[string]interface{}{"PK_DOCUMENTO_DOMICILIARIO": docDomiciliario}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/domicilios/:id:int/domiciliario", name: "trabajador inexistente", path: fmt.Sprintf("%s/domicilios/%d/domiciliario", v2, fx.Domicilio), rol: admin, body: map[string]interface{}{"PK_DOCUMENTO_DOMICILIARIO": 9999}, status: http.StatusUnprocessableEntity},
+		{route: "PUT /restaurante/v2/domicilios/:id:int/domiciliario", name: "sin documento", path: fmt.Sprintf("%s/domicilios/%d/domiciliario", v2, fx.Domicilio), rol: admin, body: map[string]interface{}{}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v2/domicilios/:id:int/domiciliario", name: "domicilio inexistente", path: v2 + "/domicilios/9999/domiciliario", rol: admin, body: map[string]interface{}{"PK_DOCUMENTO_DOMICILIARIO": docDomiciliario}, status: http.StatusNotFound},
+		{route: "PUT /restaurante/v2/domicilios/:id:int/domiciliario", name: "como cliente", path: fmt.Sprintf("%s/domicilios/%d/domiciliario", v2, fx.Domicilio), rol: "cliente", body: map[string]interface{}{"PK_DOCUMENTO_DOMICILIARIO": docDomiciliario}, status: http.StatusForbidden},
+		{route: "GET /restaurante/v2/trabajadores/:documento:int/nominas", name: "del trabajador", path: fmt.Sprintf("%s/trabajadores/%d/nominas", v2, docMesero), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/trabajadores/:documento:int/nominas", name: "sin token", path: fmt.Sprintf("%s/trabajadores/%d/nominas", v2, docMesero), status: http.StatusUnauthorized},
+
+		// API v2: modificadores de productos
+		{route: "POST /restaurante/v2/modificadores/", name: "crear grupo opcional", path: v2 + "/modificadores", rol: admin, body: map[string]interface{}{"PK_ID_PRODUCTO": fx.Producto, "NOMBRE": "Adiciones", "MAX_SELECCION": 2, "OPCIONES": []map[string]interface{}{{"NOMBRE": "Extra queso", "PRECIO_ADICIONAL": 2000}, {"NOMBRE": "Huevo", "PRECIO_ADICIONAL": 1500}}}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/modificadores/", name: "sin opciones", path: v2 + "/modificadores", rol: admin, body: map[string]interface{}{"PK_ID_PRODUCTO": fx.Producto, "NOMBRE": "Término"}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/modificadores/", name: "producto inexistente", path: v2 + "/modificadores", rol: admin, body: map[string]interface{}{"PK_ID_PRODUCTO": 9999, "NOMBRE": "Término", "OPCIONES": []map[string]interface{}{{"NOMBRE": "Medio"}}}, status: http.StatusUnprocessableEntity},
+		{route: "POST /restaurante/v2/modificadores/", name: "sin token", path: v2 + "/modificadores", body: map[string]interface{}{}, status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v2/productos/:id:int/modificadores", name: "del producto", path: fmt.Sprintf("%s/productos/%d/modificadores", v2, fx.Producto), status: http.StatusOK},
+		{route: "GET /restaurante/v2/productos/:id:int/modificadores", name: "producto inexistente", path: v2 + "/productos/9999/modificadores", status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v2/modificadores/:id:int", name: "eliminar", path: fmt.Sprintf("%s/modificadores/%d", v2, fx.GrupoModificador), rol: admin, status: http.StatusNoContent},
+		{route: "DELETE /restaurante/v2/modificadores/:id:int", name: "inexistente", path: v2 + "/modificadores/9999", rol: admin, status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v2/modificadores/:id:int", name: "sin token", path: v2 + "/modificadores/9999", status: http.StatusUnauthorized},
+
+		// API v2: promociones
+		{route: "GET /restaurante/v2/promociones/", name: "listar", path: v2 + "/promociones", rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v2/promociones/", name: "como cliente", path: v2 + "/promociones?activas=true", rol: "cliente", status: http.StatusOK},
+		{route: "GET /restaurante/v2/promociones/", name: "sin token", path: v2 + "/promociones", status: http.StatusUnauthorized},
+		{route: "POST /restaurante/v2/promociones/", name: "2x1 los martes", path: v2 + "/promociones", rol: admin, body: map[string]interface{}{"NOMBRE": "2x1 los martes", "TIPO": "LLEVA_PAGA", "PK_ID_PRODUCTO": fx.Producto, "LLEVA": 2, "PAGA": 1, "DIAS_SEMANA": "martes"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/promociones/", name: "tipo inválido", path: v2 + "/promociones", rol: admin, body: map[string]interface{}{"NOMBRE": "Regalo", "TIPO": "REGALO"}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/promociones/", name: "código repetido", path: v2 + "/promociones", rol: admin, body: map[string]interface{}{"NOMBRE": "Otra", "CODIGO": "BIENVENIDA", "TIPO": "VALOR_FIJO", "VALOR": 5000}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/promociones/", name: "como cliente", path: v2 + "/promociones", rol: "cliente", body: map[string]interface{}{"NOMBRE": "Mía", "TIPO": "PORCENTAJE", "VALOR": 100}, status: http.StatusForbidden},
+		{route: "PUT /restaurante/v2/promociones/:id:int", name: "actualizar", path: fmt.Sprintf("%s/promociones/%d", v2, fx.Promocion), rol: admin, body: map[string]interface{}{"NOMBRE": "Bienvenida", "CODIGO": "BIENVENIDA", "TIPO": "PORCENTAJE", "VALOR": 15, "LIMITE_POR_CLIENTE": 1, "ACTIVA": true}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/promociones/:id:int", name: "inexistente", path: v2 + "/promociones/9999", rol: admin, body: map[string]interface{}{"NOMBRE": "Nada", "TIPO": "DOMICILIO_GRATIS"}, status: http.StatusNotFound},
+
+		// API v2: mesas del salón
+		{route: "GET /restaurante/v2/mesas/", name: "listar", path: v2 + "/mesas", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/mesas/", name: "por zona", path: v2 + "/mesas?zona=terraza", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/mesas/", name: "sin token", path: v2 + "/mesas", status: http.StatusUnauthorized},
+		{route: "POST /restaurante/v2/mesas/", name: "crear", path: v2 + "/mesas", rol: admin, body: map[string]interface{}{"NUMERO": 3, "CAPACIDAD": 6, "ZONA": "salon"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/mesas/", name: "número repetido", path: v2 + "/mesas", rol: admin, body: map[string]interface{}{"NUMERO": 1, "CAPACIDAD": 2, "ZONA": "SALON"}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/mesas/", name: "sin capacidad", path: v2 + "/mesas", rol: admin, body: map[string]interface{}{"NUMERO": 4, "ZONA": "SALON"}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/mesas/", name: "como cliente", path: v2 + "/mesas", rol: "cliente", body: map[string]interface{}{"NUMERO": 5, "CAPACIDAD": 2, "ZONA": "SALON"}, status: http.StatusForbidden},
+		{route: "PUT /restaurante/v2/mesas/:id:int", name: "actualizar", path: fmt.Sprintf("%s/mesas/%d", v2, fx.Mesa), rol: admin, body: map[string]interface{}{"NUMERO": 1, "CAPACIDAD": 6, "ZONA": "SALON"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/mesas/:id:int", name: "inexistente", path: v2 + "/mesas/9999", rol: admin, body: map[string]interface{}{"NUMERO": 9, "CAPACIDAD": 2, "ZONA": "SALON"}, status: http.StatusNotFound},
+		{route: "POST /restaurante/v2/mesas/:id:int/pedidos", name: "abrir ronda", path: fmt.Sprintf("%s/mesas/%d/pedidos", v2, fx.Mesa), rol: "Mesero", body: map[string]interface{}{}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/mesas/:id:int/pedidos", name: "pedido a domicilio", path: fmt.Sprintf("%s/mesas/%d/pedidos", v2, fx.Mesa), rol: "Mesero", body: map[string]interface{}{"DELIVERY": true}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/mesas/:id:int/pedidos", name: "mesa inexistente", path: v2 + "/mesas/9999/pedidos", rol: "Mesero", body: map[string]interface{}{}, status: http.StatusUnprocessableEntity},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/mesa", name: "segunda ronda", path: fmt.Sprintf("%s/pedidos/%d/mesa", v2, fx.PedidoSalon), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"PK_ID_MESA": fx.Mesa}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/mesa", name: "sin mesa", path: fmt.Sprintf("%s/pedidos/%d/mesa", v2, fx.PedidoSalon), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/mesa", name: "pedido cancelado", path: fmt.Sprintf("%s/pedidos/%d/mesa", v2, fx.PedidoCancelable), rol: "Mesero", headers: ifMatchAny, body: map[string]interface{}{"PK_ID_MESA": fx.Mesa}, status: http.StatusConflict},
+		{route: "PUT /restaurante/v2/pedidos/:id:int/mesa", name: "sin token", path: fmt.Sprintf("%s/pedidos/%d/mesa", v2, fx.PedidoSalon), body: map[string]interface{}{"PK_ID_MESA": fx.Mesa}, status: http.StatusUnauthorized},
+		{route: "PUT /restaurante/v2/mesas/:id:int/estado", name: "liberar con rondas abiertas", path: fmt.Sprintf("%s/mesas/%d/estado", v2, fx.Mesa), rol: "Mesero", body: map[string]interface{}{"ESTADO": "LIBRE"}, status: http.StatusConflict},
+		{route: "PUT /restaurante/v2/mesas/:id:int/estado", name: "estado desconocido", path: fmt.Sprintf("%s/mesas/%d/estado", v2, fx.Mesa), rol: "Mesero", body: map[string]interface{}{"ESTADO": "RESERVADA"}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v2/mesas/:id:int/estado", name: "como cliente", path: fmt.Sprintf("%s/mesas/%d/estado", v2, fx.Mesa), rol: "cliente", body: map[string]interface{}{"ESTADO": "LIBRE"}, status: http.StatusForbidden},
+		{route: "GET /restaurante/v2/mesas/plano", name: "plano", path: v2 + "/mesas/plano", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/mesas/plano", name: "sin token", path: v2 + "/mesas/plano", status: http.StatusUnauthorized},
+		{route: "POST /restaurante/v2/mesas/:id:int/unir", name: "destino libre", path: fmt.Sprintf("%s/mesas/%d/unir", v2, fx.Mesa), rol: "Mesero", body: map[string]interface{}{"PK_ID_MESA_DESTINO": fx.MesaLibre}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/mesas/:id:int/mover", name: "mover", path: fmt.Sprintf("%s/mesas/%d/mover", v2, fx.Mesa), rol: "Mesero", body: map[string]interface{}{"PK_ID_MESA_DESTINO": fx.MesaLibre}, status: http.StatusOK},
+		{route: "POST /restaurante/v2/mesas/:id:int/mover", name: "sin rondas abiertas", path: fmt.Sprintf("%s/mesas/%d/mover", v2, fx.Mesa), rol: "Mesero", body: map[string]interface{}{"PK_ID_MESA_DESTINO": fx.MesaLibre}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/mesas/:id:int/mover", name: "destino inexistente", path: fmt.Sprintf("%s/mesas/%d/mover", v2, fx.MesaLibre), rol: "Mesero", body: map[string]interface{}{"PK_ID_MESA_DESTINO": 9999}, status: http.StatusUnprocessableEntity},
+		{route: "PUT /restaurante/v2/mesas/:id:int/estado", name: "limpiar la mesa", path: fmt.Sprintf("%s/mesas/%d/estado", v2, fx.Mesa), rol: "Mesero", body: map[string]interface{}{"ESTADO": "LIBRE"}, status: http.StatusOK},
+		{route: "POST /restaurante/v2/mesas/:id:int/pedidos", name: "ronda para unir", path: fmt.Sprintf("%s/mesas/%d/pedidos", v2, fx.Mesa), rol: "Mesero", body: map[string]interface{}{}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/mesas/:id:int/unir", name: "unir", path: fmt.Sprintf("%s/mesas/%d/unir", v2, fx.Mesa), rol: "Mesero", body: map[string]interface{}{"PK_ID_MESA_DESTINO": fx.MesaLibre}, status: http.StatusOK},
+		{route: "POST /restaurante/v2/mesas/:id:int/unir", name: "misma mesa", path: fmt.Sprintf("%s/mesas/%d/unir", v2, fx.MesaLibre), rol: "Mesero", body: map[string]interface{}{"PK_ID_MESA_DESTINO": fx.MesaLibre}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/mesas/:id:int/unir", name: "como cliente", path: fmt.Sprintf("%s/mesas/%d/unir", v2, fx.Mesa), rol: "cliente", body: map[string]interface{}{"PK_ID_MESA_DESTINO": fx.MesaLibre}, status: http.StatusForbidden},
+
+		// API v2: cocina
+		{route: "GET /restaurante/v2/cocina/tickets", name: "pendientes", path: v2 + "/cocina/tickets", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/cocina/tickets", name: "por estación", path: v2 + "/cocina/tickets?estacion=cocina&estado=pendiente", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/cocina/tickets", name: "estado desconocido", path: v2 + "/cocina/tickets?estado=QUEMADO", rol: "Mesero", status: http.StatusBadRequest},
+		{route: "GET /restaurante/v2/cocina/tickets", name: "como cliente", path: v2 + "/cocina/tickets", rol: "cliente", status: http.StatusForbidden},
+		{route: "GET /restaurante/v2/cocina/tickets", name: "sin token", path: v2 + "/cocina/tickets", status: http.StatusUnauthorized},
+		{route: "PUT /restaurante/v2/cocina/tickets/:id:int/items/:item:int", name: "preparando", path: fmt.Sprintf("%s/cocina/tickets/%d/items/%d", v2, fx.TicketCocina, fx.ItemTicket), rol: "Mesero", body: map[string]interface{}{"ESTADO": "PREPARANDO"}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/cocina/tickets/:id:int/items/:item:int", name: "estado desconocido", path: fmt.Sprintf("%s/cocina/tickets/%d/items/%d", v2, fx.TicketCocina, fx.ItemTicket), rol: "Mesero", body: map[string]interface{}{"ESTADO": "QUEMADO"}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v2/cocina/tickets/:id:int/items/:item:int", name: "ítem de otro ticket", path: fmt.Sprintf("%s/cocina/tickets/%d/items/9999", v2, fx.TicketCocina), rol: "Mesero", body: map[string]interface{}{"ESTADO": "LISTO"}, status: http.StatusNotFound},
+		{route: "POST /restaurante/v2/cocina/tickets/:id:int/recall", name: "ticket sin terminar", path: fmt.Sprintf("%s/cocina/tickets/%d/recall", v2, fx.TicketCocina), rol: "Mesero", status: http.StatusConflict},
+		{route: "POST /restaurante/v2/cocina/tickets/:id:int/bump", name: "despachar", path: fmt.Sprintf("%s/cocina/tickets/%d/bump", v2, fx.TicketCocina), rol: "Mesero", status: http.StatusOK},
+		{route: "POST /restaurante/v2/cocina/tickets/:id:int/bump", name: "pedido ya listo", path: fmt.Sprintf("%s/cocina/tickets/%d/bump", v2, fx.TicketCocina), rol: "Mesero", status: http.StatusConflict},
+		{route: "POST /restaurante/v2/cocina/tickets/:id:int/bump", name: "inexistente", path: v2 + "/cocina/tickets/9999/bump", rol: "Mesero", status: http.StatusNotFound},
+		{route: "POST /restaurante/v2/cocina/tickets/:id:int/recall", name: "como cliente", path: fmt.Sprintf("%s/cocina/tickets/%d/recall", v2, fx.TicketCocina), rol: "cliente", status: http.StatusForbidden},
+		{route: "POST /restaurante/v2/cocina/tickets/:id:int/recall", name: "devolver a la cocina", path: fmt.Sprintf("%s/cocina/tickets/%d/recall", v2, fx.TicketCocina), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/cocina/tickets/:id:int/comanda", name: "en texto", path: fmt.Sprintf("%s/cocina/tickets/%d/comanda", v2, fx.TicketCocina), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/cocina/tickets/:id:int/comanda", name: "en ESC/POS de 58 mm", path: fmt.Sprintf("%s/cocina/tickets/%d/comanda?formato=escpos&ancho=58", v2, fx.TicketCocina), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/cocina/tickets/:id:int/comanda", name: "como cliente", path: fmt.Sprintf("%s/cocina/tickets/%d/comanda", v2, fx.TicketCocina), rol: "cliente", status: http.StatusForbidden},
+		{route: "GET /restaurante/v2/cocina/tickets/:id:int/comanda", name: "inexistente", path: v2 + "/cocina/tickets/9999/comanda", rol: "Mesero", status: http.StatusNotFound},
+
+		// API v2: eventos en tiempo real (los streams abiertos no terminan, solo se prueban los rechazos)
+		{route: "GET /restaurante/v2/eventos/stream", name: "sin token", path: v2 + "/eventos/stream", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v2/eventos/stream", name: "token inválido en la consulta", path: v2 + "/eventos/stream?token=invalido", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v2/eventos/stream", name: "tipo desconocido", path: v2 + "/eventos/stream?tipos=PEDIDO,FACTURA", rol: "Mesero", status: http.StatusBadRequest},
+		{route: "GET /restaurante/v2/eventos/ws", name: "sin token", path: v2 + "/eventos/ws", status: http.StatusUnauthorized},
+		{route: "GET /restaurante/v2/eventos/ws", name: "sin upgrade", path: v2 + "/eventos/ws", rol: "Mesero", status: http.StatusBadRequest},
+
+		// API v2: impuestos y reportes
+		{route: "GET /restaurante/v2/impuestos/", name: "listar", path: v2 + "/impuestos", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/impuestos/", name: "sin token", path: v2 + "/impuestos", status: http.StatusUnauthorized},
+		{route: "PUT /restaurante/v2/impuestos/:categoria", name: "cambiar tarifa", path: v2 + "/impuestos/IVA", rol: admin, body: map[string]interface{}{"NOMBRE": "Impuesto al valor agregado", "TARIFA": 19}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/impuestos/:categoria", name: "tarifa inválida", path: v2 + "/impuestos/IVA", rol: admin, body: map[string]interface{}{"NOMBRE": "IVA", "TARIFA": 150}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v2/impuestos/:categoria", name: "como mesero", path: v2 + "/impuestos/IVA", rol: "Mesero", body: map[string]interface{}{"NOMBRE": "IVA", "TARIFA": 0}, status: http.StatusForbidden},
+		{route: "PUT /restaurante/v2/impuestos/:categoria", name: "como cliente", path: v2 + "/impuestos/IVA", rol: "cliente", body: map[string]interface{}{"NOMBRE": "IVA", "TARIFA": 0}, status: http.StatusForbidden},
+		{route: "GET /restaurante/v2/reportes/ventas", name: "ventas del día", path: fmt.Sprintf("%s/reportes/ventas?desde=%s&hasta=%s", v2, hoy, hoy), rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v2/reportes/ventas", name: "sin fechas", path: v2 + "/reportes/ventas", rol: admin, status: http.StatusBadRequest},
+		{route: "GET /restaurante/v2/reportes/ventas", name: "como cliente", path: fmt.Sprintf("%s/reportes/ventas?desde=%s&hasta=%s", v2, hoy, hoy), rol: "cliente", status: http.StatusForbidden},
+		{route: "GET /restaurante/v2/reportes/cancelaciones", name: "cancelaciones del día", path: fmt.Sprintf("%s/reportes/cancelaciones?desde=%s&hasta=%s", v2, hoy, hoy), rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v2/reportes/cancelaciones", name: "hasta antes de desde", path: v2 + "/reportes/cancelaciones?desde=2024-12-31&hasta=2024-01-01", rol: admin, status: http.StatusBadRequest},
+		{route: "GET /restaurante/v2/reportes/cancelaciones", name: "como cliente", path: fmt.Sprintf("%s/reportes/cancelaciones?desde=%s&hasta=%s", v2, hoy, hoy), rol: "cliente", status: http.StatusForbidden},
+		{route: "GET /restaurante/v2/reportes/tiempos", name: "tiempos del día", path: fmt.Sprintf("%s/reportes/tiempos?desde=%s&hasta=%s", v2, hoy, hoy), rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v2/reportes/tiempos", name: "sin fechas", path: v2 + "/reportes/tiempos", rol: admin, status: http.StatusBadRequest},
+		{route: "GET /restaurante/v2/reportes/tiempos", name: "como cliente", path: fmt.Sprintf("%s/reportes/tiempos?desde=%s&hasta=%s", v2, hoy, hoy), rol: "cliente", status: http.StatusForbidden},
+		{route: "GET /restaurante/v2/impresoras/", name: "impresoras", path: v2 + "/impresoras", rol: admin, status: http.StatusOK},
+		{route: "GET /restaurante/v2/impresoras/", name: "como cliente", path: v2 + "/impresoras", rol: "cliente", status: http.StatusForbidden},
+		{route: "POST /restaurante/v2/impresoras/", name: "impresora de la barra", path: v2 + "/impresoras", rol: admin, body: map[string]interface{}{"NOMBRE": "Barra", "HOST": "127.0.0.1", "PUERTO": impresoraPruebas.Puerto(), "ANCHO": 58, "ESTACION": "bebidas"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/impresoras/", name: "sin host", path: v2 + "/impresoras", rol: admin, body: map[string]interface{}{"NOMBRE": "Barra"}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/impresoras/", name: "ancho no soportado", path: v2 + "/impresoras", rol: admin, body: map[string]interface{}{"NOMBRE": "Barra", "HOST": "127.0.0.1", "ANCHO": 70}, status: http.StatusBadRequest},
+		{route: "PUT /restaurante/v2/impresoras/:id:int", name: "cambiar el nombre", path: fmt.Sprintf("%s/impresoras/%d", v2, fx.Impresora), rol: admin, body: map[string]interface{}{"NOMBRE": "Caja principal", "HOST": "127.0.0.1", "PUERTO": impresoraPruebas.Puerto(), "CAJON": true}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/impresoras/:id:int", name: "como mesero", path: fmt.Sprintf("%s/impresoras/%d", v2, fx.Impresora), rol: "Mesero", body: map[string]interface{}{"NOMBRE": "Caja", "HOST": "127.0.0.1"}, status: http.StatusForbidden},
+		{route: "PUT /restaurante/v2/impresoras/:id:int", name: "inexistente", path: v2 + "/impresoras/9999", rol: admin, body: map[string]interface{}{"NOMBRE": "Caja", "HOST": "127.0.0.1"}, status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v2/impresoras/:id:int", name: "como cliente", path: fmt.Sprintf("%s/impresoras/%d", v2, fx.Impresora), rol: "cliente", status: http.StatusForbidden},
+		{route: "DELETE /restaurante/v2/impresoras/:id:int", name: "inexistente", path: v2 + "/impresoras/9999", rol: admin, status: http.StatusNotFound},
+		{route: "GET /restaurante/v2/impresion/trabajos", name: "cola", path: v2 + "/impresion/trabajos", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/impresion/trabajos", name: "fallidos", path: v2 + "/impresion/trabajos?estado=fallido", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/impresion/trabajos", name: "estado desconocido", path: v2 + "/impresion/trabajos?estado=PERDIDO", rol: "Mesero", status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/impresion/trabajos", name: "recibo", path: v2 + "/impresion/trabajos", rol: "Mesero", body: map[string]interface{}{"TIPO": "RECIBO", "PK_ID_PEDIDO": fx.PedidoSalon}, status: http.StatusAccepted},
+		{route: "POST /restaurante/v2/impresion/trabajos", name: "pedido sin comandas", path: v2 + "/impresion/trabajos", rol: "Mesero", body: map[string]interface{}{"TIPO": "COMANDA", "PK_ID_PEDIDO": fx.PedidoSalon}, status: http.StatusUnprocessableEntity},
+		{route: "POST /restaurante/v2/impresion/trabajos", name: "tipo desconocido", path: v2 + "/impresion/trabajos", rol: "Mesero", body: map[string]interface{}{"TIPO": "FACTURA", "PK_ID_PEDIDO": fx.PedidoSalon}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/impresion/trabajos", name: "pedido inexistente", path: v2 + "/impresion/trabajos", rol: "Mesero", body: map[string]interface{}{"TIPO": "RECIBO", "PK_ID_PEDIDO": 9999}, status: http.StatusNotFound},
+		{route: "POST /restaurante/v2/impresion/trabajos", name: "como cliente", path: v2 + "/impresion/trabajos", rol: "cliente", body: map[string]interface{}{"TIPO": "RECIBO", "PK_ID_PEDIDO": fx.PedidoSalon}, status: http.StatusForbidden},
+		{route: "POST /restaurante/v2/impresion/trabajos/:id:int/reintentar", name: "trabajo fallido", path: fmt.Sprintf("%s/impresion/trabajos/%d/reintentar", v2, fx.TrabajoImpresion), rol: "Mesero", status: http.StatusAccepted},
+		{route: "POST /restaurante/v2/impresion/trabajos/:id:int/reintentar", name: "inexistente", path: v2 + "/impresion/trabajos/9999/reintentar", rol: "Mesero", status: http.StatusNotFound},
+
+		// API v2: historial, favoritos y recompra del cliente
+		{route: "GET /restaurante/v2/clientes/:documento:int/pedidos", name: "historial propio", path: fmt.Sprintf("%s/clientes/%d/pedidos?limite=5", v2, docCliente), rol: "cliente", status: http.StatusOK},
+		{route: "GET /restaurante/v2/clientes/:documento:int/pedidos", name: "historial de otro cliente", path: v2 + "/clientes/9999/pedidos", rol: "cliente", status: http.StatusForbidden},
+		{route: "GET /restaurante/v2/clientes/:documento:int/pedidos", name: "cliente inexistente", path: v2 + "/clientes/9999/pedidos", rol: "Mesero", status: http.StatusNotFound},
+		{route: "GET /restaurante/v2/clientes/:documento:int/pedidos", name: "límite inválido", path: fmt.Sprintf("%s/clientes/%d/pedidos?limite=muchos", v2, docCliente), rol: "cliente", status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/clientes/:documento:int/favoritos", name: "guardar favorito", path: fmt.Sprintf("%s/clientes/%d/favoritos", v2, docCliente), rol: "cliente", body: map[string]interface{}{"PK_ID_PEDIDO": fx.Pedido, "NOMBRE": "El de siempre"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/clientes/:documento:int/favoritos", name: "ya es favorito", path: fmt.Sprintf("%s/clientes/%d/favoritos", v2, docCliente), rol: "cliente", body: map[string]interface{}{"PK_ID_PEDIDO": fx.Pedido}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/clientes/:documento:int/favoritos", name: "pedido cancelado", path: fmt.Sprintf("%s/clientes/%d/favoritos", v2, docCliente), rol: "cliente", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoCancelable}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/clientes/:documento:int/favoritos", name: "pedido de otro cliente", path: fmt.Sprintf("%s/clientes/%d/favoritos", v2, docCliente), rol: "cliente", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoSalon}, status: http.StatusUnprocessableEntity},
+		{route: "POST /restaurante/v2/clientes/:documento:int/favoritos", name: "sin pedido", path: fmt.Sprintf("%s/clientes/%d/favoritos", v2, docCliente), rol: "cliente", body: map[string]interface{}{}, status: http.StatusBadRequest},
+		{route: "GET /restaurante/v2/clientes/:documento:int/favoritos", name: "favoritos", path: fmt.Sprintf("%s/clientes/%d/favoritos", v2, docCliente), rol: "cliente", status: http.StatusOK},
+		{route: "GET /restaurante/v2/clientes/:documento:int/favoritos", name: "sin token", path: fmt.Sprintf("%s/clientes/%d/favoritos", v2, docCliente), status: http.StatusUnauthorized},
+		{route: "POST /restaurante/v2/pedidos/:id:int/repetir", name: "repetir pedido propio", path: fmt.Sprintf("%s/pedidos/%d/repetir", v2, fx.Pedido), rol: "cliente", status: http.StatusCreated},
+		{route: "POST /restaurante/v2/pedidos/:id:int/repetir", name: "pedido de otro cliente", path: fmt.Sprintf("%s/pedidos/%d/repetir", v2, fx.PedidoSalon), rol: "cliente", status: http.StatusForbidden},
+		{route: "POST /restaurante/v2/pedidos/:id:int/repetir", name: "inexistente", path: v2 + "/pedidos/9999/repetir", rol: "Mesero", status: http.StatusNotFound},
+		{route: "DELETE /restaurante/v2/clientes/:documento:int/favoritos/:id:int", name: "quitar favorito", path: fmt.Sprintf("%s/clientes/%d/favoritos/1", v2, docCliente), rol: "cliente", status: http.StatusOK},
+		{route: "DELETE /restaurante/v2/clientes/:documento:int/favoritos/:id:int", name: "inexistente", path: fmt.Sprintf("%s/clientes/%d/favoritos/1", v2, docCliente), rol: "cliente", status: http.StatusNotFound},
+
+		// API v2: pedidos telefónicos
+		{route: "GET /restaurante/v2/clientes/telefono", name: "cliente que llama", path: v2 + "/clientes/telefono?numero=%2B57%20311%20111%201111", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/clientes/telefono", name: "número sin registrar", path: v2 + "/clientes/telefono?numero=3209876543", rol: "Mesero", status: http.StatusNotFound},
+		{route: "GET /restaurante/v2/clientes/telefono", name: "número inválido", path: v2 + "/clientes/telefono?numero=12345", rol: "Mesero", status: http.StatusBadRequest},
+		{route: "GET /restaurante/v2/clientes/telefono", name: "como cliente", path: v2 + "/clientes/telefono?numero=3111111111", rol: "cliente", status: http.StatusForbidden},
+		{route: "POST /restaurante/v2/clientes/telefono/pedidos", name: "cliente registrado", path: v2 + "/clientes/telefono/pedidos", rol: "Mesero", body: map[string]interface{}{"TELEFONO": "311-111-1111", "PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 1}}, "DOMICILIO": map[string]interface{}{}, "PK_ID_METODO_PAGO": fx.MetodoPago}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/clientes/telefono/pedidos", name: "número nuevo sin datos del cliente", path: v2 + "/clientes/telefono/pedidos", rol: "Mesero", body: map[string]interface{}{"TELEFONO": "320 987 6543", "PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 1}}, "PK_ID_METODO_PAGO": fx.MetodoPago}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/clientes/telefono/pedidos", name: "documento con otro teléfono", path: v2 + "/clientes/telefono/pedidos", rol: "Mesero", body: map[string]interface{}{"TELEFONO": "320 987 6543", "CLIENTE": map[string]interface{}{"PK_DOCUMENTO_CLIENTE": docCliente, "NOMBRE": "Cliente"}, "PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 1}}, "PK_ID_METODO_PAGO": fx.MetodoPago}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/clientes/telefono/pedidos", name: "registra al cliente nuevo", path: v2 + "/clientes/telefono/pedidos", rol: "Mesero", body: map[string]interface{}{"TELEFONO": "+57 320 987 6543", "CLIENTE": map[string]interface{}{"PK_DOCUMENTO_CLIENTE": 3001, "NOMBRE": "Pedro", "DIRECCION": "Carrera 7 # 45-10"}, "PRODUCTOS": []map[string]interface{}{{"PK_ID_PRODUCTO": fx.Producto, "CANTIDAD": 1}}, "PK_ID_METODO_PAGO": fx.MetodoPago}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/clientes/telefono/pedidos", name: "como cliente", path: v2 + "/clientes/telefono/pedidos", rol: "cliente", body: map[string]interface{}{"TELEFONO": "3111111111"}, status: http.StatusForbidden},
+		{route: "GET /restaurante/v2/clientes/telefono", name: "cliente recién registrado", path: v2 + "/clientes/telefono?numero=3209876543", rol: "Mesero", status: http.StatusOK},
+
+		// Facturación electrónica
+		{route: "GET /restaurante/v2/facturacion/resoluciones", name: "resoluciones", path: v2 + "/facturacion/resoluciones", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/facturacion/resoluciones", name: "como cliente", path: v2 + "/facturacion/resoluciones", rol: "cliente", status: http.StatusForbidden},
+		{route: "POST /restaurante/v2/facturacion/resoluciones", name: "resolución de factura", path: v2 + "/facturacion/resoluciones", rol: admin, body: map[string]interface{}{"NUMERO": "18760000002", "TIPO_DOCUMENTO": "FACTURA", "PREFIJO": "fe", "DESDE": 1, "HASTA": 500, "FECHA_DESDE": vigencia[0], "FECHA_HASTA": vigencia[1], "CLAVE_TECNICA": "fc8eac422eba16e22ffd8c6f94b3f40a6e38162c"}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/facturacion/resoluciones", name: "factura sin clave técnica", path: v2 + "/facturacion/resoluciones", rol: admin, body: map[string]interface{}{"NUMERO": "18760000003", "TIPO_DOCUMENTO": "FACTURA", "DESDE": 1, "HASTA": 500, "FECHA_DESDE": vigencia[0], "FECHA_HASTA": vigencia[1]}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/facturacion/resoluciones", name: "como mesero", path: v2 + "/facturacion/resoluciones", rol: "Mesero", body: map[string]interface{}{"NUMERO": "18760000004", "TIPO_DOCUMENTO": "POS", "DESDE": 1, "HASTA": 500, "FECHA_DESDE": vigencia[0], "FECHA_HASTA": vigencia[1]}, status: http.StatusForbidden},
+		{route: "PUT /restaurante/v2/facturacion/resoluciones/:id:int", name: "ampliar el rango", path: fmt.Sprintf("%s/facturacion/resoluciones/%d", v2, fx.Resolucion), rol: admin, body: map[string]interface{}{"NUMERO": "18760000001", "TIPO_DOCUMENTO": "POS", "PREFIJO": "POS", "DESDE": 1, "HASTA": 2000, "FECHA_DESDE": vigencia[0], "FECHA_HASTA": vigencia[1]}, status: http.StatusOK},
+		{route: "PUT /restaurante/v2/facturacion/resoluciones/:id:int", name: "inexistente", path: v2 + "/facturacion/resoluciones/9999", rol: admin, body: map[string]interface{}{"NUMERO": "1", "TIPO_DOCUMENTO": "POS", "DESDE": 1, "HASTA": 10, "FECHA_DESDE": vigencia[0], "FECHA_HASTA": vigencia[1]}, status: http.StatusNotFound},
+		{route: "POST /restaurante/v2/facturacion/documentos", name: "documento POS de un pedido pagado", path: v2 + "/facturacion/documentos", rol: "Mesero", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoFacturable}, status: http.StatusCreated},
+		{route: "POST /restaurante/v2/facturacion/documentos", name: "pedido ya facturado", path: v2 + "/facturacion/documentos", rol: "Mesero", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoFacturable}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/facturacion/documentos", name: "pedido sin pagar", path: v2 + "/facturacion/documentos", rol: "Mesero", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoSalon}, status: http.StatusConflict},
+		{route: "POST /restaurante/v2/facturacion/documentos", name: "tipo desconocido", path: v2 + "/facturacion/documentos", rol: "Mesero", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoSalon, "TIPO_DOCUMENTO": "NOTA"}, status: http.StatusBadRequest},
+		{route: "POST /restaurante/v2/facturacion/documentos", name: "pedido inexistente", path: v2 + "/facturacion/documentos", rol: "Mesero", body: map[string]interface{}{"PK_ID_PEDIDO": 9999}, status: http.StatusNotFound},
+		{route: "POST /restaurante/v2/facturacion/documentos", name: "como cliente", path: v2 + "/facturacion/documentos", rol: "cliente", body: map[string]interface{}{"PK_ID_PEDIDO": fx.PedidoFacturable}, status: http.StatusForbidden},
+		{route: "GET /restaurante/v2/facturacion/documentos", name: "documentos", path: v2 + "/facturacion/documentos", rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/facturacion/documentos", name: "de un pedido", path: fmt.Sprintf("%s/facturacion/documentos?pedido=%d", v2, fx.PedidoFacturable), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/facturacion/documentos", name: "pedido inválido", path: v2 + "/facturacion/documentos?pedido=uno", rol: "Mesero", status: http.StatusBadRequest},
+		{route: "GET /restaurante/v2/facturacion/documentos/:id:int", name: "documento", path: fmt.Sprintf("%s/facturacion/documentos/%d", v2, fx.Documento), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/facturacion/documentos/:id:int", name: "inexistente", path: v2 + "/facturacion/documentos/9999", rol: "Mesero", status: http.StatusNotFound},
+		{route: "GET /restaurante/v2/facturacion/documentos/:id:int/xml", name: "xml", path: fmt.Sprintf("%s/facturacion/documentos/%d/xml", v2, fx.Documento), rol: "Mesero", status: http.StatusOK},
+		{route: "GET /restaurante/v2/facturacion/documentos/:id:int/xml", name: "como cliente", path: fmt.Sprintf("%s/facturacion/documentos/%d/xml", v2, fx.Documento), rol: "cliente", status: http.StatusForbidden},
+		{route: "POST /restaurante/v2/facturacion/documentos/:id:int/enviar", name: "reenviar un rechazado", path: fmt.Sprintf("%s/facturacion/documentos/%d/enviar", v2, fx.Documento), rol: "Mesero", status: http.StatusOK},
+		{route: "POST /restaurante/v2/facturacion/documentos/:id:int/enviar", name: "inexistente", path: v2 + "/facturacion/documentos/9999/enviar", rol: "Mesero", status: http.StatusNotFound},
+	}
+}
+
+// TestRoutes recorre cada caso en orden; los casos que modifican datos van después de las consultas
+func TestRoutes(t *testing.T) {
+	Convey("Subject: Rutas de la API sobre una base de datos desechable\n", t, func() {
+		for _, tc := range routeCases() {
+			tc := tc
+			Convey(fmt.Sprintf("%s (%s) responde %d", tc.route, tc.name, tc.status), func() {
+				if tc.postgres && !usingPostgres {
+					SkipSo(tc.status, ShouldEqual, tc.status)
+					return
+				}
+
+				token := tc.token
+				if token == "" && tc.rol != "" {
+					token = tokens[tc.rol]
+				}
+
+				method, _, _ := strings.Cut(tc.route, " ")
+				w := doRequest(method, tc.path, token, tc.body, tc.headers)
+				So(w.Code, ShouldEqual, tc.status)
+			})
+		}
+	})
+}
+
+// TestRoutesCoverage verifica que cada ruta registrada tenga al menos un caso de prueba
+func TestRoutesCoverage(t *testing.T) {
+	Convey("Subject: Cobertura de las rutas registradas\n", t, func() {
+		covered := map[string]bool{}
+		for _, tc := range routeCases() {
+			covered[tc.route] = true
+		}
+
+		var missing []string
+		for _, route := range registeredRoutes() {
+			if !covered[route] {
+				missing = append(missing, route)
+			}
+		}
+		So(missing, ShouldBeEmpty)
+	})
+}
+
+// TestIdempotencia repite la respuesta de un POST reintentado con la misma Idempotency-Key
+func TestIdempotencia(t *testing.T) {
+	Convey("Subject: Reintentos con Idempotency-Key\n", t, func() {
+		path := "/restaurante/v1/metodos_pago"
+		body := map[string]interface{}{"TIPO": "DATAFONO", "DETALLE": "Caja 2"}
+		clave := map[string]string{"Idempotency-Key": fmt.Sprintf("metodo-%d", time.Now().UnixNano())}
+
+		original := doRequest(http.MethodPost, path, tokens["Administrador"], body, clave)
+		So(original.Code, ShouldEqual, http.StatusCreated)
+
+		Convey("El mismo usuario con el token en otro formato recibe la respuesta original", func() {
+			// ValidateToken acepta el token sin el prefijo Bearer, como lo envía una app que lo renovó
+			reintento := doRequest(http.MethodPost, path, "", body, map[string]string{
+				"Idempotency-Key": clave["Idempotency-Key"],
+				"Authorization":   tokens["Administrador"],
+			})
+			So(reintento.Code, ShouldEqual, http.StatusCreated)
+			So(reintento.Header().Get("Idempotent-Replayed"), ShouldEqual, "true")
+			So(reintento.Body.String(), ShouldEqual, original.Body.String())
+		})
+
+		Convey("Otro usuario con la misma clave no recibe la respuesta ajena ni queda bloqueado", func() {
+			ajeno := doRequest(http.MethodPost, path, tokens["Mesero"], body, clave)
+			So(ajeno.Code, ShouldEqual, http.StatusCreated)
+			So(ajeno.Header().Get("Idempotent-Replayed"), ShouldBeEmpty)
+			So(ajeno.Body.String(), ShouldNotEqual, original.Body.String())
+		})
+
+		Convey("Una respuesta 200 con un código de error en el cuerpo libera la clave", func() {
+			// Simula un controlador v1 que informa el error solo en el cuerpo
+			solicitud := func(respuesta models.ApiResponse) *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, path, strings.NewReader("{}"))
+				req.Header.Set("Idempotency-Key", clave["Idempotency-Key"]+"-cuerpo")
+				ctx := context.NewContext()
+				ctx.Reset(w, req)
+				ctx.Input.RequestBody = []byte("{}")
+				controllers.CheckIdempotencyKey(ctx)
+				if !ctx.ResponseWriter.Started {
+					ctx.Output.JSON(respuesta, false, false)
+					controllers.StoreIdempotentResponse(ctx)
+				}
+				return w
+			}
+
+			fallida := models.ApiResponse{Code: http.StatusInternalServerError, Message: "Error en la base de datos"}
+			So(solicitud(fallida).Header().Get("Idempotent-Replayed"), ShouldBeEmpty)
+			So(solicitud(fallida).Header().Get("Idempotent-Replayed"), ShouldBeEmpty)
+
+			exitosa := models.ApiResponse{Code: http.StatusOK, Message: "Listo"}
+			So(solicitud(exitosa).Header().Get("Idempotent-Replayed"), ShouldBeEmpty)
+			So(solicitud(exitosa).Header().Get("Idempotent-Replayed"), ShouldEqual, "true")
+		})
+	})
+}
+
+// registeredRoutes lista "MÉTODO patrón" de cada ruta en el árbol de beego
+func registeredRoutes() []string {
+	var routes []string
+	for method, tree := range beego.PrintTree()["Data"].(beego.M) {
+		for _, route := range *tree.(*[][]string) {
+			routes = append(routes, method+" "+route[0])
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
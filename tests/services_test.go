@@ -0,0 +1,2013 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"restaurante/database"
+	"restaurante/facturacion"
+	"restaurante/impresion"
+	"restaurante/models"
+	"restaurante/repositories"
+	"restaurante/repositories/memory"
+	"restaurante/services"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func init() {
+	database.InitTimezone()
+}
+
+// errorCode devuelve el código HTTP de un error de servicios, o 0 si no lo es
+func errorCode(err error) int {
+	var svcErr *services.Error
+	if errors.As(err, &svcErr) {
+		return svcErr.Code
+	}
+	return 0
+}
+
+// nuevoProducto arma un producto de prueba DISPONIBLE, EXENTO y con 10 unidades sin control de stock;
+// los ajustes cambian lo que cada prueba necesita
+func nuevoProducto(id int64, nombre string, precio int64, ajustes ...func(*models.Producto)) models.Producto {
+	p := models.Producto{PK_ID_PRODUCTO: id, NOMBRE: nombre, PRECIO: precio, ESTADO_PRODUCTO: "DISPONIBLE", CANTIDAD: 10, CATEGORIA_IMPUESTO: "EXENTO"}
+	for _, ajuste := range ajustes {
+		ajuste(&p)
+	}
+	return p
+}
+
+// conImpuesto cambia la categoría de impuesto del producto
+func conImpuesto(categoria string) func(*models.Producto) {
+	return func(p *models.Producto) { p.CATEGORIA_IMPUESTO = categoria }
+}
+
+// conStock activa el control de stock del producto con las unidades indicadas
+func conStock(cantidad int) func(*models.Producto) {
+	return func(p *models.Producto) { p.CONTROLA_STOCK, p.CANTIDAD = true, cantidad }
+}
+
+// enEstacion cambia la estación de la cocina que prepara el producto
+func enEstacion(estacion string) func(*models.Producto) {
+	return func(p *models.Producto) { p.ESTACION = estacion }
+}
+
+// conPreparacion fija los minutos de preparación del producto
+func conPreparacion(minutos int) func(*models.Producto) {
+	return func(p *models.Producto) { p.TIEMPO_PREPARACION = minutos }
+}
+
+// noDisponible marca el producto como NO DISPONIBLE
+func noDisponible(p *models.Producto) {
+	p.ESTADO_PRODUCTO = "NO DISPONIBLE"
+}
+
+// nuevoStore crea un store en memoria con los productos indicados
+func nuevoStore(productos ...models.Producto) *memory.Store {
+	store := memory.NewStore()
+	sembrarProductos(store, productos...)
+	return store
+}
+
+// sembrarProductos guarda los productos en el store de la prueba
+func sembrarProductos(store repositories.Store, productos ...models.Producto) {
+	for i := range productos {
+		So(store.Productos().Insert(&productos[i]), ShouldBeNil)
+	}
+}
+
+func TestPedidoService(t *testing.T) {
+	Convey("Subject: PedidoService sobre repositorios en memoria\n", t, func() {
+		store := nuevoStore()
+		service := services.NewPedidoService(store)
+		mesero := services.Actor{Documento: 1002, Rol: "Mesero"}
+
+		pedido := models.Pedido{HORA: "12:00:00"}
+		So(service.Create(&pedido, mesero), ShouldBeNil)
+		So(pedido.PK_ID_PEDIDO, ShouldBeGreaterThan, 0)
+		So(pedido.ESTADO_PEDIDO, ShouldEqual, "INICIADO")
+
+		metodo := models.MetodoPago{TIPO: "NEQUI"}
+		So(store.MetodosPago().Insert(&metodo), ShouldBeNil)
+		pago := models.Pago{MONTO: 15000, ESTADO_PAGO: "PENDIENTE", PK_ID_METODO_PAGO: metodo.PK_ID_METODO_PAGO}
+		So(store.Pagos().Insert(&pago), ShouldBeNil)
+
+		Convey("Asignar un pago a un pedido inexistente responde 404", func() {
+			_, err := service.AssignPago(999, pago.PK_ID_PAGO, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("Asignar un pago inexistente responde 422 sin modificar el pedido", func() {
+			_, err := service.AssignPago(pedido.PK_ID_PEDIDO, 999, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+
+			actual, _ := service.GetByID(pedido.PK_ID_PEDIDO)
+			So(actual.ESTADO_PEDIDO, ShouldEqual, "INICIADO")
+			So(actual.PK_ID_PAGO, ShouldBeNil)
+		})
+
+		Convey("Asignar un pago marca pedido y pago como PAGADO", func() {
+			sembrarProductos(store, nuevoProducto(7, "Bandeja", 15000))
+			_, err := services.NewProductoPedidoService(store).Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}}, mesero)
+			So(err, ShouldBeNil)
+
+			actualizado, err := service.AssignPago(pedido.PK_ID_PEDIDO, pago.PK_ID_PAGO, mesero)
+			So(err, ShouldBeNil)
+			So(actualizado.ESTADO_PEDIDO, ShouldEqual, "PAGADO")
+			So(actualizado.VERSION, ShouldEqual, 2)
+
+			guardado, _ := services.NewPagoService(store).GetByID(pago.PK_ID_PAGO)
+			So(guardado.ESTADO_PAGO, ShouldEqual, "PAGADO")
+
+			pedidos, err := service.List(services.PedidoFiltros{MetodoPago: "nequi"}, services.Actor{})
+			So(err, ShouldBeNil)
+			So(len(pedidos), ShouldEqual, 1)
+		})
+
+		Convey("Un pedido sin productos no se cobra y un cliente no cobra pagos ajenos", func() {
+			_, err := service.AssignPago(pedido.PK_ID_PEDIDO, pago.PK_ID_PAGO, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			sembrarProductos(store, nuevoProducto(7, "Bandeja", 15000))
+			_, err = services.NewProductoPedidoService(store).Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}}, mesero)
+			So(err, ShouldBeNil)
+			documento := int64(2001)
+			So(store.PedidosClientes().Insert(&models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &documento, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO}), ShouldBeNil)
+			_, err = service.AssignPago(pedido.PK_ID_PEDIDO, pago.PK_ID_PAGO, services.Actor{Documento: 2001, Rol: services.RolCliente})
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+
+			guardado, _ := store.Pagos().Get(pago.PK_ID_PAGO)
+			So(guardado.ESTADO_PAGO, ShouldEqual, "PENDIENTE")
+			So(guardado.PK_ID_PEDIDO, ShouldBeNil)
+		})
+
+		Convey("Actualizar con una versión vieja responde 409 con el pedido actual", func() {
+			_, err := service.UpdateEstado(pedido.PK_ID_PEDIDO, "EN PREPARACION", mesero)
+			So(err, ShouldBeNil)
+
+			viejo := pedido
+			viejo.HORA = "13:00:00"
+			err = service.Update(&viejo, 0, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			var svcErr *services.Error
+			errors.As(err, &svcErr)
+			So(svcErr.Data.(*models.Pedido).ESTADO_PEDIDO, ShouldEqual, "EN PREPARACION")
+		})
+
+		Convey("Actualizar un pedido ajeno, terminado o con pagos no vuelve a liquidar sus totales", func() {
+			sembrarProductos(store, nuevoProducto(7, "Bandeja", 20000))
+			_, err := services.NewProductoPedidoService(store).Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}}, mesero)
+			So(err, ShouldBeNil)
+			documento := int64(2001)
+			So(store.PedidosClientes().Insert(&models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &documento, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO}), ShouldBeNil)
+
+			actual, _ := service.GetByID(pedido.PK_ID_PEDIDO)
+			actual.HORA = "13:00:00"
+			So(errorCode(service.Update(actual, actual.VERSION, services.Actor{Documento: 2002, Rol: services.RolCliente})), ShouldEqual, http.StatusNotFound)
+
+			_, err = service.AssignPago(pedido.PK_ID_PEDIDO, pago.PK_ID_PAGO, mesero)
+			So(err, ShouldBeNil)
+			actual, _ = service.GetByID(pedido.PK_ID_PEDIDO)
+			actual.DELIVERY = true
+			So(errorCode(service.Update(actual, actual.VERSION, mesero)), ShouldEqual, http.StatusConflict)
+			actual.DELIVERY = false
+			actual.HORA = "13:00:00"
+			actual.TOTAL = 0
+			So(service.Update(actual, actual.VERSION, mesero), ShouldBeNil)
+			guardado, _ := service.GetByID(pedido.PK_ID_PEDIDO)
+			So(guardado.HORA, ShouldEqual, "13:00:00")
+			So(guardado.TOTAL, ShouldEqual, 20000)
+
+			_, err = service.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoCancelado, mesero)
+			So(err, ShouldBeNil)
+			actual, _ = service.GetByID(pedido.PK_ID_PEDIDO)
+			So(errorCode(service.Update(actual, actual.VERSION, mesero)), ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("Un estado fuera del ciclo de vida responde 400", func() {
+			_, err := service.UpdateEstado(pedido.PK_ID_PEDIDO, "PERDIDO", mesero)
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("Saltarse pasos del ciclo de vida responde 409 sin modificar el pedido", func() {
+			_, err := service.UpdateEstado(pedido.PK_ID_PEDIDO, "ENTREGADO", mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			actual, _ := service.GetByID(pedido.PK_ID_PEDIDO)
+			So(actual.ESTADO_PEDIDO, ShouldEqual, "INICIADO")
+		})
+
+		Convey("Un pedido cancelado no admite más cambios", func() {
+			_, err := service.UpdateEstado(pedido.PK_ID_PEDIDO, "CANCELADO", mesero)
+			So(err, ShouldBeNil)
+
+			_, err = service.UpdateEstado(pedido.PK_ID_PEDIDO, "EN PREPARACION", mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+			_, err = service.AssignPago(pedido.PK_ID_PEDIDO, pago.PK_ID_PAGO, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("Un cliente solo asigna el domicilio de sus pedidos y no los despacha", func() {
+			documento := int64(2001)
+			So(store.PedidosClientes().Insert(&models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &documento, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO}), ShouldBeNil)
+			domicilio := models.Domicilio{DIRECCION: "Calle 1 # 2-3", TELEFONO: "3001234567", ESTADO_PAGO: "PENDIENTE"}
+			So(store.Domicilios().Insert(&domicilio), ShouldBeNil)
+			cliente := services.Actor{Documento: 2001, Rol: services.RolCliente}
+
+			_, err := service.AssignDomicilio(pedido.PK_ID_PEDIDO, domicilio.PK_ID_DOMICILIO, services.Actor{Documento: 2002, Rol: services.RolCliente})
+			So(errorCode(err), ShouldEqual, http.StatusNotFound)
+			actualizado, err := service.AssignDomicilio(pedido.PK_ID_PEDIDO, domicilio.PK_ID_DOMICILIO, cliente)
+			So(err, ShouldBeNil)
+			So(actualizado.ESTADO_PEDIDO, ShouldEqual, services.EstadoIniciado)
+
+			for _, estado := range []string{services.EstadoEnPreparacion, services.EstadoListo} {
+				_, err := service.UpdateEstado(pedido.PK_ID_PEDIDO, estado, mesero)
+				So(err, ShouldBeNil)
+			}
+			_, err = service.AssignDomicilio(pedido.PK_ID_PEDIDO, domicilio.PK_ID_DOMICILIO, cliente)
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+			actualizado, err = service.AssignDomicilio(pedido.PK_ID_PEDIDO, domicilio.PK_ID_DOMICILIO, mesero)
+			So(err, ShouldBeNil)
+			So(actualizado.ESTADO_PEDIDO, ShouldEqual, services.EstadoEnCamino)
+		})
+
+		Convey("Cada transición queda en el historial con quién la hizo", func() {
+			for _, estado := range []string{"EN PREPARACION", "LISTO", "ENTREGADO"} {
+				_, err := service.UpdateEstado(pedido.PK_ID_PEDIDO, estado, mesero)
+				So(err, ShouldBeNil)
+			}
+
+			details, err := service.GetDetails(int64(pedido.PK_ID_PEDIDO), mesero)
+			So(err, ShouldBeNil)
+			So(len(details.Historial), ShouldEqual, 4)
+			So(details.Historial[0].ESTADO_ANTERIOR, ShouldBeNil)
+			So(details.Historial[0].ESTADO_NUEVO, ShouldEqual, "INICIADO")
+			So(*details.Historial[3].ESTADO_ANTERIOR, ShouldEqual, "LISTO")
+			So(details.Historial[3].ESTADO_NUEVO, ShouldEqual, "ENTREGADO")
+			So(*details.Historial[3].DOCUMENTO_ACTOR, ShouldEqual, 1002)
+			So(*details.Historial[3].ROL_ACTOR, ShouldEqual, "Mesero")
+		})
+	})
+}
+
+func TestCheckoutService(t *testing.T) {
+	Convey("Subject: Checkout de un pedido completo\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Bandeja", 20000))
+		service := services.NewCheckoutService(store)
+		cliente := services.Actor{Documento: 2001, Rol: services.RolCliente}
+
+		So(store.Clientes().Insert(&models.Cliente{PK_DOCUMENTO_CLIENTE: 2001, DIRECCION: "Calle 1 # 2-3", TELEFONO: "3001234567"}), ShouldBeNil)
+		metodo := models.MetodoPago{TIPO: "NEQUI"}
+		So(store.MetodosPago().Insert(&metodo), ShouldBeNil)
+
+		Convey("Crea pedido, productos, cliente, domicilio y pago por el total", func() {
+			req := models.CheckoutRequest{
+				PRODUCTOS:         []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2}},
+				DOMICILIO:         &models.CheckoutDomicilio{},
+				PK_ID_METODO_PAGO: metodo.PK_ID_METODO_PAGO,
+			}
+			response, err := service.Checkout(&req, cliente)
+			So(err, ShouldBeNil)
+			So(response.TOTAL, ShouldEqual, 40000)
+			So(response.PEDIDO.ESTADO_PEDIDO, ShouldEqual, "PAGADO")
+			So(response.PEDIDO.DELIVERY, ShouldBeTrue)
+			So(response.PAGO.MONTO, ShouldEqual, 40000)
+			So(response.PAGO.ESTADO_PAGO, ShouldEqual, "PAGADO")
+			So(response.DOMICILIO.DIRECCION, ShouldEqual, "Calle 1 # 2-3")
+			So(*response.PEDIDO.PK_ID_DOMICILIO, ShouldEqual, response.DOMICILIO.PK_ID_DOMICILIO)
+
+			relaciones, _ := store.PedidosClientes().List()
+			So(len(relaciones), ShouldEqual, 1)
+		})
+
+		Convey("Un producto inexistente responde 422 sin dejar registros", func() {
+			req := models.CheckoutRequest{
+				PK_DOCUMENTO_CLIENTE: 2001,
+				PRODUCTOS:            []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}, {PK_ID_PRODUCTO: 99, CANTIDAD: 1}},
+				PK_ID_METODO_PAGO:    metodo.PK_ID_METODO_PAGO,
+			}
+			_, err := service.Checkout(&req, services.Actor{})
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+
+			pedidos, _ := services.NewPedidoService(store).List(services.PedidoFiltros{}, services.Actor{})
+			So(len(pedidos), ShouldEqual, 0)
+			pagos, _ := store.Pagos().List()
+			So(len(pagos), ShouldEqual, 0)
+		})
+
+		Convey("Un domicilio sin dirección revierte el pedido ya creado", func() {
+			So(store.Clientes().Insert(&models.Cliente{PK_DOCUMENTO_CLIENTE: 2002}), ShouldBeNil)
+			req := models.CheckoutRequest{
+				PK_DOCUMENTO_CLIENTE: 2002,
+				PRODUCTOS:            []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}},
+				DOMICILIO:            &models.CheckoutDomicilio{},
+				PK_ID_METODO_PAGO:    metodo.PK_ID_METODO_PAGO,
+			}
+			_, err := service.Checkout(&req, services.Actor{})
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+
+			pedidos, _ := services.NewPedidoService(store).List(services.PedidoFiltros{}, services.Actor{})
+			So(len(pedidos), ShouldEqual, 0)
+			relaciones, _ := store.PedidosClientes().List()
+			So(len(relaciones), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestStoreTransaction(t *testing.T) {
+	Convey("Subject: Transacciones del store en memoria\n", t, func() {
+		store := nuevoStore()
+
+		Convey("Un error revierte todo lo escrito dentro de la transacción", func() {
+			err := store.Transaction(func(tx repositories.Store) error {
+				if err := tx.Clientes().Insert(&models.Cliente{PK_DOCUMENTO_CLIENTE: 10}); err != nil {
+					return err
+				}
+				return errors.New("fallo")
+			})
+			So(err, ShouldNotBeNil)
+
+			_, err = store.Clientes().Get(10)
+			So(err, ShouldEqual, repositories.ErrNotFound)
+		})
+	})
+}
+
+func TestNominaTrabajadorService(t *testing.T) {
+	Convey("Subject: Nómina de un trabajador con incidencias\n", t, func() {
+		store := nuevoStore()
+		service := services.NewNominaTrabajadorService(store)
+
+		Convey("Un trabajador inexistente responde 422", func() {
+			_, err := service.Create(123)
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+		})
+
+		Convey("Las incidencias del periodo se suman o restan al sueldo", func() {
+			documento := int64(1015466494)
+			So(store.Trabajadores().Insert(&models.Trabajador{PK_DOCUMENTO_TRABAJADOR: documento, SUELDO: 1000000}), ShouldBeNil)
+
+			now := time.Now()
+			store.Incidencias().Insert(&models.Incidencia{FECHA: now, MONTO: 50000, PK_DOCUMENTO_TRABAJADOR: &documento})
+			store.Incidencias().Insert(&models.Incidencia{FECHA: now, MONTO: 20000, RESTA: true, PK_DOCUMENTO_TRABAJADOR: &documento})
+
+			response, err := service.Create(documento)
+			So(err, ShouldBeNil)
+			So(response.MONTO_INCIDENCIAS, ShouldEqual, 30000)
+			So(response.TOTAL, ShouldEqual, 1030000)
+		})
+	})
+}
+
+func TestProductoPedidoService(t *testing.T) {
+	Convey("Subject: Productos de un pedido\n", t, func() {
+		store := nuevoStore()
+		service := services.NewProductoPedidoService(store)
+
+		pedido := models.Pedido{ESTADO_PEDIDO: services.EstadoIniciado}
+		So(store.Pedidos().Insert(&pedido), ShouldBeNil)
+		detalles := []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2}}
+
+		Convey("Un producto inexistente responde 422", func() {
+			_, err := service.Create(int64(pedido.PK_ID_PEDIDO), detalles, services.Actor{})
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+		})
+
+		Convey("Los productos registrados aparecen en los detalles del pedido", func() {
+			sembrarProductos(store, nuevoProducto(7, "Bandeja", 20000))
+
+			_, err := service.Create(int64(pedido.PK_ID_PEDIDO), detalles, services.Actor{})
+			So(err, ShouldBeNil)
+
+			details, err := services.NewPedidoService(store).GetDetails(int64(pedido.PK_ID_PEDIDO), services.Actor{})
+			So(err, ShouldBeNil)
+			So(details.Productos, ShouldContainSubstring, `"PK_ID_PRODUCTO":7`)
+			So(details.Productos, ShouldContainSubstring, `"PRECIO_UNITARIO":20000`)
+			So(details.Subtotal, ShouldEqual, 40000)
+			So(details.Total, ShouldEqual, 40000)
+		})
+
+		Convey("Agregar y reemplazar productos recalcula los totales con todas las líneas", func() {
+			sembrarProductos(store, nuevoProducto(7, "Bandeja", 20000))
+
+			_, err := service.Create(int64(pedido.PK_ID_PEDIDO), detalles, services.Actor{})
+			So(err, ShouldBeNil)
+			_, err = service.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1, NOTAS: "Sin cebolla"}}, services.Actor{})
+			So(err, ShouldBeNil)
+
+			lineas, err := service.List(int64(pedido.PK_ID_PEDIDO), services.Actor{})
+			So(err, ShouldBeNil)
+			So(len(lineas), ShouldEqual, 2)
+			So(lineas[1].NOTAS, ShouldEqual, "Sin cebolla")
+			actual, _ := services.NewPedidoService(store).GetByID(pedido.PK_ID_PEDIDO)
+			So(actual.TOTAL, ShouldEqual, 60000)
+
+			_, err = service.Update(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}}, services.Actor{})
+			So(err, ShouldBeNil)
+			lineas, _ = service.List(int64(pedido.PK_ID_PEDIDO), services.Actor{})
+			So(len(lineas), ShouldEqual, 1)
+			actual, _ = services.NewPedidoService(store).GetByID(pedido.PK_ID_PEDIDO)
+			So(actual.TOTAL, ShouldEqual, 20000)
+		})
+
+		Convey("Un cliente solo cambia los productos de sus pedidos y no los de un pedido pagado", func() {
+			sembrarProductos(store, nuevoProducto(7, "Bandeja", 20000))
+			documento := int64(2001)
+			relacion := models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &documento, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO}
+			So(store.PedidosClientes().Insert(&relacion), ShouldBeNil)
+			cliente := services.Actor{Documento: 2001, Rol: services.RolCliente}
+			otro := services.Actor{Documento: 2002, Rol: services.RolCliente}
+
+			_, err := service.Create(int64(pedido.PK_ID_PEDIDO), detalles, otro)
+			So(errorCode(err), ShouldEqual, http.StatusNotFound)
+			_, err = service.Create(int64(pedido.PK_ID_PEDIDO), detalles, cliente)
+			So(err, ShouldBeNil)
+			_, err = service.Update(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 5}}, otro)
+			So(errorCode(err), ShouldEqual, http.StatusNotFound)
+
+			metodo := models.MetodoPago{TIPO: "NEQUI"}
+			So(store.MetodosPago().Insert(&metodo), ShouldBeNil)
+			pago := models.Pago{MONTO: 40000, ESTADO_PAGO: "PENDIENTE", PK_ID_METODO_PAGO: metodo.PK_ID_METODO_PAGO, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO, PK_ID_PEDIDO_CLIENTE: &relacion.PK_ID_PEDIDO_CLIENTE}
+			So(store.Pagos().Insert(&pago), ShouldBeNil)
+			pagado, err := services.NewPedidoService(store).AssignPago(pedido.PK_ID_PEDIDO, pago.PK_ID_PAGO, cliente)
+			So(err, ShouldBeNil)
+			So(pagado.ESTADO_PEDIDO, ShouldEqual, services.EstadoPagado)
+
+			_, err = service.Create(int64(pedido.PK_ID_PEDIDO), detalles, cliente)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+			_, err = service.Update(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}}, cliente)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+			actual, _ := services.NewPedidoService(store).GetByID(pedido.PK_ID_PEDIDO)
+			So(actual.TOTAL, ShouldEqual, 40000)
+		})
+
+		Convey("Un producto no disponible responde 422 sin cambiar los totales", func() {
+			sembrarProductos(store, nuevoProducto(7, "Bandeja", 20000, noDisponible))
+
+			_, err := service.Create(int64(pedido.PK_ID_PEDIDO), detalles, services.Actor{})
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+
+			actual, _ := services.NewPedidoService(store).GetByID(pedido.PK_ID_PEDIDO)
+			So(actual.TOTAL, ShouldEqual, 0)
+		})
+	})
+}
+
+func TestStockService(t *testing.T) {
+	Convey("Subject: Inventario de productos\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Bandeja", 20000, conStock(3)), nuevoProducto(8, "Limonada", 6000))
+		service := services.NewStockService(store)
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+
+		pedidos := services.NewPedidoService(store)
+		pedido := models.Pedido{}
+		So(pedidos.Create(&pedido, mesero), ShouldBeNil)
+		productos := services.NewProductoPedidoService(store)
+
+		Convey("Las unidades se descuentan al confirmar el pedido y se anotan como VENTA", func() {
+			_, err := productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2}}, mesero)
+			So(err, ShouldBeNil)
+			producto, _ := store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 3)
+
+			_, err = pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoEnPreparacion, mesero)
+			So(err, ShouldBeNil)
+			producto, _ = store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 1)
+			movimientos, err := service.List(7)
+			So(err, ShouldBeNil)
+			So(len(movimientos), ShouldEqual, 1)
+			So(movimientos[0].MOTIVO, ShouldEqual, services.MotivoVenta)
+			So(movimientos[0].CANTIDAD, ShouldEqual, -2)
+			So(movimientos[0].STOCK_RESULTANTE, ShouldEqual, 1)
+			So(*movimientos[0].DOCUMENTO_ACTOR, ShouldEqual, 1015466494)
+
+			_, err = productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}}, mesero)
+			So(err, ShouldBeNil)
+			producto, _ = store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 0)
+		})
+
+		Convey("Pedir más de lo disponible responde 409 sin tocar el stock", func() {
+			_, err := productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 4}}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			// Las líneas del mismo producto suman sus unidades aunque lleven notas distintas
+			_, err = productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2, NOTAS: "Sin cebolla"}, {PK_ID_PRODUCTO: 7, CANTIDAD: 2}}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+			So(err.Error(), ShouldContainSubstring, "se necesitan 4")
+
+			producto, _ := store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 3)
+			lineas, _ := store.DetallesPedido().ListByPedido(pedido.PK_ID_PEDIDO)
+			So(len(lineas), ShouldEqual, 0)
+		})
+
+		Convey("Si otro pedido se confirmó antes y ya no alcanza, la confirmación responde 409", func() {
+			otro := models.Pedido{}
+			So(pedidos.Create(&otro, mesero), ShouldBeNil)
+			for _, id := range []int{pedido.PK_ID_PEDIDO, otro.PK_ID_PEDIDO} {
+				_, err := productos.Create(int64(id), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2}}, mesero)
+				So(err, ShouldBeNil)
+			}
+
+			_, err := pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoEnPreparacion, mesero)
+			So(err, ShouldBeNil)
+			_, err = pedidos.UpdateEstado(otro.PK_ID_PEDIDO, services.EstadoEnPreparacion, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+			actual, _ := store.Pedidos().Get(otro.PK_ID_PEDIDO)
+			So(actual.ESTADO_PEDIDO, ShouldEqual, services.EstadoIniciado)
+			producto, _ := store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 1)
+		})
+
+		Convey("Agotar el producto lo marca NO DISPONIBLE y cancelar el pedido lo repone", func() {
+			_, err := productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 3}}, mesero)
+			So(err, ShouldBeNil)
+			_, err = pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoEnPreparacion, mesero)
+			So(err, ShouldBeNil)
+			producto, _ := store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 0)
+			So(producto.ESTADO_PRODUCTO, ShouldEqual, "NO DISPONIBLE")
+
+			_, err = pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, "CANCELADO", mesero)
+			So(err, ShouldBeNil)
+			producto, _ = store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 3)
+			So(producto.ESTADO_PRODUCTO, ShouldEqual, "DISPONIBLE")
+			movimientos, _ := service.List(7)
+			So(movimientos[0].MOTIVO, ShouldEqual, services.MotivoCancelacion)
+
+			_, err = productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("Cancelar un pedido sin confirmar no mueve el inventario", func() {
+			_, err := productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2}}, mesero)
+			So(err, ShouldBeNil)
+			_, err = pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, "CANCELADO", mesero)
+			So(err, ShouldBeNil)
+
+			producto, _ := store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 3)
+			movimientos, _ := service.List(7)
+			So(movimientos, ShouldBeEmpty)
+		})
+
+		Convey("Reemplazar los productos de un pedido confirmado devuelve las unidades anteriores antes de descontar", func() {
+			_, err := productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2}}, mesero)
+			So(err, ShouldBeNil)
+			_, err = pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoEnPreparacion, mesero)
+			So(err, ShouldBeNil)
+			_, err = productos.Update(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 3}}, mesero)
+			So(err, ShouldBeNil)
+
+			producto, _ := store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 0)
+		})
+
+		Convey("Un producto sin control de stock se vende aunque se pidan más unidades de las registradas", func() {
+			_, err := productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 8, CANTIDAD: 15}}, mesero)
+			So(err, ShouldBeNil)
+			_, err = pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoEnPreparacion, mesero)
+			So(err, ShouldBeNil)
+
+			producto, _ := store.Productos().Get(8)
+			So(producto.CANTIDAD, ShouldEqual, 10)
+			So(producto.ESTADO_PRODUCTO, ShouldEqual, "DISPONIBLE")
+			movimientos, _ := service.List(8)
+			So(movimientos, ShouldBeEmpty)
+
+			_, err = service.Registrar(&models.MovimientoStockRequest{PK_ID_PRODUCTO: 8, CANTIDAD: 5, MOTIVO: "REABASTECIMIENTO"}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("Los movimientos manuales validan el motivo y el signo", func() {
+			_, err := service.Registrar(&models.MovimientoStockRequest{PK_ID_PRODUCTO: 7, CANTIDAD: 5, MOTIVO: "regalo"}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+			_, err = service.Registrar(&models.MovimientoStockRequest{PK_ID_PRODUCTO: 7, CANTIDAD: -5, MOTIVO: "REABASTECIMIENTO"}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+			_, err = service.Registrar(&models.MovimientoStockRequest{PK_ID_PRODUCTO: 99, CANTIDAD: 5, MOTIVO: "REABASTECIMIENTO"}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+
+			movimiento, err := service.Registrar(&models.MovimientoStockRequest{PK_ID_PRODUCTO: 7, CANTIDAD: 3, MOTIVO: "merma", OBSERVACIONES: "Se quemó"}, mesero)
+			So(err, ShouldBeNil)
+			So(movimiento.CANTIDAD, ShouldEqual, -3)
+			producto, _ := store.Productos().Get(7)
+			So(producto.ESTADO_PRODUCTO, ShouldEqual, "NO DISPONIBLE")
+
+			movimiento, err = service.Registrar(&models.MovimientoStockRequest{PK_ID_PRODUCTO: 7, CANTIDAD: 10, MOTIVO: "REABASTECIMIENTO"}, mesero)
+			So(err, ShouldBeNil)
+			So(movimiento.STOCK_RESULTANTE, ShouldEqual, 10)
+			producto, _ = store.Productos().Get(7)
+			So(producto.ESTADO_PRODUCTO, ShouldEqual, "DISPONIBLE")
+		})
+	})
+}
+
+func TestModificadorService(t *testing.T) {
+	Convey("Subject: Modificadores de productos\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Hamburguesa", 20000))
+		service := services.NewModificadorService(store)
+		productos := services.NewProductoPedidoService(store)
+
+		termino := models.GrupoModificador{PK_ID_PRODUCTO: 7, NOMBRE: "Término", OBLIGATORIO: true, MAX_SELECCION: 1, OPCIONES: []models.OpcionModificador{{NOMBRE: "Medio"}, {NOMBRE: "Bien asado"}}}
+		So(service.Create(&termino), ShouldBeNil)
+		adiciones := models.GrupoModificador{PK_ID_PRODUCTO: 7, NOMBRE: "Adiciones", MAX_SELECCION: 2, OPCIONES: []models.OpcionModificador{{NOMBRE: "Extra queso", PRECIO_ADICIONAL: 2000}, {NOMBRE: "Tocineta", PRECIO_ADICIONAL: 3000}, {NOMBRE: "Huevo", PRECIO_ADICIONAL: 1500}}}
+		So(service.Create(&adiciones), ShouldBeNil)
+
+		pedido := models.Pedido{}
+		So(store.Pedidos().Insert(&pedido), ShouldBeNil)
+		medio := termino.OPCIONES[0].PK_ID_OPCION_MODIFICADOR
+		queso := adiciones.OPCIONES[0].PK_ID_OPCION_MODIFICADOR
+
+		Convey("Un grupo obligatorio exige al menos una opción", func() {
+			So(termino.MIN_SELECCION, ShouldEqual, 1)
+			grupos, err := service.List(7)
+			So(err, ShouldBeNil)
+			So(len(grupos), ShouldEqual, 2)
+			So(len(grupos[1].OPCIONES), ShouldEqual, 3)
+
+			_, err = productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}}, services.Actor{})
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+		})
+
+		Convey("Las opciones elegidas suman al precio y aparecen en los detalles", func() {
+			_, err := productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2, NOTAS: "Sin cebolla", MODIFICADORES: []int64{medio, queso}}}, services.Actor{})
+			So(err, ShouldBeNil)
+
+			lineas, err := productos.List(int64(pedido.PK_ID_PEDIDO), services.Actor{})
+			So(err, ShouldBeNil)
+			So(lineas[0].PRECIO_UNITARIO, ShouldEqual, 22000)
+			So(lineas[0].SUBTOTAL, ShouldEqual, 44000)
+			So(len(lineas[0].MODIFICADORES), ShouldEqual, 2)
+			So(lineas[0].MODIFICADORES[1].GRUPO, ShouldEqual, "Adiciones")
+
+			details, err := services.NewPedidoService(store).GetDetails(int64(pedido.PK_ID_PEDIDO), services.Actor{})
+			So(err, ShouldBeNil)
+			So(details.Productos, ShouldContainSubstring, `"NOMBRE":"Extra queso"`)
+			So(details.Total, ShouldEqual, 44000)
+
+			Convey("Borrar el grupo no cambia las líneas ya registradas", func() {
+				So(service.Delete(adiciones.PK_ID_GRUPO_MODIFICADOR), ShouldBeNil)
+				lineas, _ := productos.List(int64(pedido.PK_ID_PEDIDO), services.Actor{})
+				So(lineas[0].MODIFICADORES[1].NOMBRE, ShouldEqual, "Extra queso")
+			})
+		})
+
+		Convey("Se rechazan opciones ajenas, repetidas o por encima del máximo", func() {
+			otro := models.GrupoModificador{PK_ID_PRODUCTO: 7, NOMBRE: "Salsas", OPCIONES: []models.OpcionModificador{{NOMBRE: "Rosada"}}}
+			So(service.Create(&otro), ShouldBeNil)
+
+			casos := [][]int64{
+				{medio, 9999},
+				{medio, termino.OPCIONES[1].PK_ID_OPCION_MODIFICADOR},
+				{medio, queso, adiciones.OPCIONES[1].PK_ID_OPCION_MODIFICADOR, adiciones.OPCIONES[2].PK_ID_OPCION_MODIFICADOR},
+			}
+			for _, elegidas := range casos {
+				_, err := productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1, MODIFICADORES: elegidas}}, services.Actor{})
+				So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+			}
+			_, err := productos.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1, MODIFICADORES: []int64{medio, medio}}}, services.Actor{})
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("Un grupo inválido responde 400", func() {
+			err := service.Create(&models.GrupoModificador{PK_ID_PRODUCTO: 7, NOMBRE: "Término", MIN_SELECCION: 3, MAX_SELECCION: 1, OPCIONES: []models.OpcionModificador{{NOMBRE: "Medio"}}})
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+			err = service.Create(&models.GrupoModificador{PK_ID_PRODUCTO: 7, NOMBRE: "Descuento", OPCIONES: []models.OpcionModificador{{NOMBRE: "Sin carne", PRECIO_ADICIONAL: -30000}}})
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+		})
+	})
+}
+
+func TestImpuestoService(t *testing.T) {
+	Convey("Subject: Impuestos y propina de los pedidos\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Bandeja", 21600, conImpuesto("IMPOCONSUMO")), nuevoProducto(8, "Gaseosa", 5950, conImpuesto("IVA")))
+		pedidos := services.NewPedidoService(store)
+		productos := services.NewProductoPedidoService(store)
+
+		// Los precios incluyen el impuesto: 20000 + 8 % y 5000 + 19 %
+		items := []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}, {PK_ID_PRODUCTO: 8, CANTIDAD: 2}}
+
+		pedido := models.Pedido{}
+		So(pedidos.Create(&pedido, services.Actor{}), ShouldBeNil)
+		_, err := productos.Create(int64(pedido.PK_ID_PEDIDO), items, services.Actor{})
+		So(err, ShouldBeNil)
+
+		Convey("Cada línea liquida el impuesto de su categoría y la propina solo suma si se acepta", func() {
+			actual, _ := pedidos.GetByID(pedido.PK_ID_PEDIDO)
+			So(actual.SUBTOTAL, ShouldEqual, 33500)
+			So(actual.IMPUESTO, ShouldEqual, 1600+1900)
+			So(actual.PROPINA_SUGERIDA, ShouldEqual, 3000)
+			So(actual.PROPINA, ShouldEqual, 0)
+			So(actual.TOTAL, ShouldEqual, 33500)
+
+			actual, err := pedidos.UpdatePropina(pedido.PK_ID_PEDIDO, true, services.Actor{})
+			So(err, ShouldBeNil)
+			So(actual.PROPINA, ShouldEqual, 3000)
+			So(actual.TOTAL, ShouldEqual, 36500)
+
+			recibo, err := pedidos.GetRecibo(pedido.PK_ID_PEDIDO, services.Actor{})
+			So(err, ShouldBeNil)
+			So(len(recibo.IMPUESTOS), ShouldEqual, 2)
+			So(recibo.IMPUESTOS[0].CATEGORIA, ShouldEqual, "IMPOCONSUMO")
+			So(recibo.IMPUESTOS[0].VALOR, ShouldEqual, 1600)
+			So(recibo.IMPUESTOS[1].CATEGORIA, ShouldEqual, "IVA")
+			So(recibo.IMPUESTOS[1].BASE, ShouldEqual, 10000)
+			So(recibo.TOTAL, ShouldEqual, 36500)
+		})
+
+		Convey("Un pedido a domicilio no lleva propina", func() {
+			domicilio := models.Pedido{DELIVERY: true}
+			So(pedidos.Create(&domicilio, services.Actor{}), ShouldBeNil)
+
+			_, err := pedidos.UpdatePropina(domicilio.PK_ID_PEDIDO, true, services.Actor{})
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("El reporte de ventas deja por fuera los pedidos cancelados", func() {
+			cancelado := models.Pedido{}
+			So(pedidos.Create(&cancelado, services.Actor{}), ShouldBeNil)
+			_, err := productos.Create(int64(cancelado.PK_ID_PEDIDO), items, services.Actor{})
+			So(err, ShouldBeNil)
+			_, err = pedidos.UpdateEstado(cancelado.PK_ID_PEDIDO, services.EstadoCancelado, services.Actor{})
+			So(err, ShouldBeNil)
+
+			hoy := time.Now().In(database.BogotaZone).Format("2006-01-02")
+			reporte, err := services.NewReporteService(store).Ventas(hoy, hoy, services.Actor{Rol: "Administrador"})
+			So(err, ShouldBeNil)
+			So(reporte.PEDIDOS, ShouldEqual, 1)
+			So(reporte.IMPUESTO, ShouldEqual, 3500)
+			So(reporte.TOTAL, ShouldEqual, 33500)
+
+			_, err = services.NewReporteService(store).Ventas(hoy, hoy, services.Actor{Rol: services.RolCliente})
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("Un producto sin categoría queda exento y solo un administrador cambia las tarifas", func() {
+			producto := models.Producto{NOMBRE: "Agua", PRECIO: 3000, ESTADO_PRODUCTO: "DISPONIBLE", CANTIDAD: 5}
+			So(services.NewProductoService(store).Create(&producto), ShouldBeNil)
+			agua := models.Pedido{}
+			So(pedidos.Create(&agua, services.Actor{}), ShouldBeNil)
+			respuesta, err := productos.Create(int64(agua.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: producto.PK_ID_PRODUCTO, CANTIDAD: 1}}, services.Actor{})
+			So(err, ShouldBeNil)
+			So(respuesta.DETALLES_PRODUCTOS[0].CATEGORIA_IMPUESTO, ShouldEqual, services.CategoriaExento)
+			So(respuesta.DETALLES_PRODUCTOS[0].IMPUESTO, ShouldEqual, 0)
+
+			impuestos := services.NewImpuestoService(store)
+			regla := models.ReglaImpuesto{CATEGORIA: "IVA", NOMBRE: "IVA", TARIFA: 5}
+			So(errorCode(impuestos.Save(&regla, services.Actor{Documento: 1015466494, Rol: "Mesero"})), ShouldEqual, http.StatusForbidden)
+			So(impuestos.Save(&regla, services.Actor{Documento: 1, Rol: "Administrador"}), ShouldBeNil)
+		})
+
+		Convey("Una categoría sin regla responde 422", func() {
+			err := services.NewProductoService(store).Create(&models.Producto{NOMBRE: "Vino", PRECIO: 60000, CATEGORIA_IMPUESTO: "LICORES"})
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+		})
+	})
+}
+
+func TestPromocionService(t *testing.T) {
+	Convey("Subject: Promociones aplicadas al cotizar los pedidos\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Bandeja", 20000, conImpuesto("IMPOCONSUMO")), nuevoProducto(8, "Gaseosa", 5000))
+		pedidos := services.NewPedidoService(store)
+		productos := services.NewProductoPedidoService(store)
+		promociones := services.NewPromocionService(store)
+		admin := services.Actor{Rol: "Administrador"}
+
+		items := []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}, {PK_ID_PRODUCTO: 8, CANTIDAD: 2}}
+		gaseosa := int64(8)
+		hoy := time.Now().In(database.BogotaZone)
+
+		nuevoPedido := func(documento int64) models.Pedido {
+			pedido := models.Pedido{}
+			So(pedidos.Create(&pedido, services.Actor{}), ShouldBeNil)
+			if documento != 0 {
+				So(store.PedidosClientes().Insert(&models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &documento, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO}), ShouldBeNil)
+			}
+			_, err := productos.Create(int64(pedido.PK_ID_PEDIDO), items, services.Actor{})
+			So(err, ShouldBeNil)
+			return pedido
+		}
+
+		Convey("El descuento se reparte entre las líneas y el impuesto se liquida sobre lo cobrado", func() {
+			So(promociones.Create(&models.Promocion{NOMBRE: "Diez por ciento", TIPO: "PORCENTAJE", VALOR: 10, ACTIVA: true}, admin), ShouldBeNil)
+
+			pedido := nuevoPedido(0)
+			actual, _ := pedidos.GetByID(pedido.PK_ID_PEDIDO)
+			So(actual.SUBTOTAL, ShouldEqual, 30000)
+			So(actual.DESCUENTO, ShouldEqual, 3000)
+			So(actual.IMPUESTO, ShouldEqual, 1333) // 8 % incluido en 18000
+			So(actual.TOTAL, ShouldEqual, 27000)
+
+			recibo, err := pedidos.GetRecibo(pedido.PK_ID_PEDIDO, services.Actor{})
+			So(err, ShouldBeNil)
+			So(len(recibo.PROMOCIONES), ShouldEqual, 1)
+			So(recibo.PROMOCIONES[0].DESCUENTO, ShouldEqual, 3000)
+			So(recibo.IMPUESTOS[1].CATEGORIA, ShouldEqual, "IMPOCONSUMO")
+			So(recibo.IMPUESTOS[1].BASE, ShouldEqual, 16667)
+		})
+
+		Convey("Sin acumular se aplica la de mayor descuento; acumulables se suman", func() {
+			dosPorUno := models.Promocion{NOMBRE: "2x1 gaseosa", TIPO: "LLEVA_PAGA", PK_ID_PRODUCTO: &gaseosa, LLEVA: 2, PAGA: 1, ACTIVA: true, DIAS_SEMANA: hoy.Weekday().String()}
+			So(errorCode(promociones.Create(&dosPorUno, admin)), ShouldEqual, http.StatusBadRequest)
+
+			dias := map[time.Weekday]string{time.Sunday: "domingo", time.Monday: "lunes", time.Tuesday: "martes", time.Wednesday: "miércoles", time.Thursday: "jueves", time.Friday: "viernes", time.Saturday: "sábado"}
+			dosPorUno.DIAS_SEMANA = dias[hoy.Weekday()]
+			So(promociones.Create(&dosPorUno, admin), ShouldBeNil)
+			diez := models.Promocion{NOMBRE: "Diez por ciento", TIPO: "PORCENTAJE", VALOR: 10, ACTIVA: true}
+			So(promociones.Create(&diez, admin), ShouldBeNil)
+
+			pedido := nuevoPedido(0)
+			actual, _ := pedidos.GetByID(pedido.PK_ID_PEDIDO)
+			So(actual.DESCUENTO, ShouldEqual, 5000)
+
+			dosPorUno.ACUMULABLE, diez.ACUMULABLE = true, true
+			So(promociones.Update(&dosPorUno, admin), ShouldBeNil)
+			So(promociones.Update(&diez, admin), ShouldBeNil)
+			_, err := productos.Update(int64(pedido.PK_ID_PEDIDO), items, services.Actor{})
+			So(err, ShouldBeNil)
+			actual, _ = pedidos.GetByID(pedido.PK_ID_PEDIDO)
+			So(actual.DESCUENTO, ShouldEqual, 5000+2000+500)
+		})
+
+		Convey("Un pedido conserva las promociones que ya tenía aunque venzan antes de recalcularlo", func() {
+			codigo := "ALMUERZO"
+			almuerzo := models.Promocion{NOMBRE: "Almuerzo", CODIGO: &codigo, TIPO: "VALOR_FIJO", VALOR: 4000, LIMITE_USOS: 1, ACTIVA: true}
+			So(promociones.Create(&almuerzo, admin), ShouldBeNil)
+			pedido := nuevoPedido(0)
+			_, err := pedidos.UpdatePromocion(pedido.PK_ID_PEDIDO, "almuerzo", services.Actor{})
+			So(err, ShouldBeNil)
+
+			ayer := hoy.AddDate(0, 0, -1)
+			almuerzo.VIGENTE_HASTA = &ayer
+			So(promociones.Update(&almuerzo, admin), ShouldBeNil)
+			_, err = productos.Update(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}, {PK_ID_PRODUCTO: 8, CANTIDAD: 1}}, services.Actor{})
+			So(err, ShouldBeNil)
+			actual, _ := pedidos.GetByID(pedido.PK_ID_PEDIDO)
+			So(actual.SUBTOTAL, ShouldEqual, 25000)
+			So(actual.DESCUENTO, ShouldEqual, 4000)
+
+			otro := nuevoPedido(0)
+			_, err = pedidos.UpdatePromocion(otro.PK_ID_PEDIDO, "ALMUERZO", services.Actor{})
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+
+			actual, err = pedidos.UpdatePromocion(pedido.PK_ID_PEDIDO, "", services.Actor{})
+			So(err, ShouldBeNil)
+			So(actual.DESCUENTO, ShouldEqual, 0)
+		})
+
+		Convey("Con la tarifa de domicilio por defecto el código de domicilio gratis responde 422 con el motivo", func() {
+			codigo := "ENVIO"
+			So(promociones.Create(&models.Promocion{NOMBRE: "Envío gratis", CODIGO: &codigo, TIPO: "DOMICILIO_GRATIS", ACTIVA: true}, admin), ShouldBeNil)
+			pedido := nuevoPedido(0)
+			actual, _ := pedidos.GetByID(pedido.PK_ID_PEDIDO)
+			actual.DELIVERY = true
+			So(pedidos.Update(actual, actual.VERSION, admin), ShouldBeNil)
+			So(actual.VALOR_DOMICILIO, ShouldEqual, 0)
+
+			_, err := pedidos.UpdatePromocion(pedido.PK_ID_PEDIDO, "envio", services.Actor{})
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+			var svcErr *services.Error
+			So(errors.As(err, &svcErr), ShouldBeTrue)
+			So(svcErr.Message, ShouldContainSubstring, "valor_domicilio es 0")
+		})
+
+		Convey("Los códigos respetan la vigencia y el límite por cliente", func() {
+			ayer := hoy.AddDate(0, 0, -1)
+			vencido := "VERANO"
+			So(promociones.Create(&models.Promocion{NOMBRE: "Verano", CODIGO: &vencido, TIPO: "VALOR_FIJO", VALOR: 4000, VIGENTE_HASTA: &ayer, ACTIVA: true}, admin), ShouldBeNil)
+			codigo := " bienvenida "
+			So(promociones.Create(&models.Promocion{NOMBRE: "Bienvenida", CODIGO: &codigo, TIPO: "VALOR_FIJO", VALOR: 4000, LIMITE_POR_CLIENTE: 1, ACTIVA: true}, admin), ShouldBeNil)
+			visibles, err := promociones.List(false, services.Actor{Rol: services.RolCliente})
+			So(err, ShouldBeNil)
+			So(len(visibles), ShouldEqual, 0)
+
+			primero := nuevoPedido(2001)
+			_, err = pedidos.UpdatePromocion(primero.PK_ID_PEDIDO, "verano", services.Actor{})
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+			actual, err := pedidos.UpdatePromocion(primero.PK_ID_PEDIDO, "BIENVENIDA", services.Actor{})
+			So(err, ShouldBeNil)
+			So(actual.DESCUENTO, ShouldEqual, 4000)
+
+			segundo := nuevoPedido(2001)
+			_, err = pedidos.UpdatePromocion(segundo.PK_ID_PEDIDO, "BIENVENIDA", services.Actor{})
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+
+			_, err = pedidos.UpdateEstado(primero.PK_ID_PEDIDO, services.EstadoCancelado, services.Actor{})
+			So(err, ShouldBeNil)
+			actual, err = pedidos.UpdatePromocion(segundo.PK_ID_PEDIDO, "BIENVENIDA", services.Actor{})
+			So(err, ShouldBeNil)
+			So(actual.DESCUENTO, ShouldEqual, 4000)
+		})
+	})
+}
+
+func TestCancelacionService(t *testing.T) {
+	Convey("Subject: Cancelación de pedidos\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Bandeja", 20000, conStock(10)))
+		service := services.NewCancelacionService(store)
+		cliente := services.Actor{Documento: 2001, Rol: services.RolCliente}
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+		admin := services.Actor{Documento: 1001, Rol: services.RolAdministrador}
+
+		So(store.Clientes().Insert(&models.Cliente{PK_DOCUMENTO_CLIENTE: 2001, DIRECCION: "Calle 1 # 2-3"}), ShouldBeNil)
+		metodo := models.MetodoPago{TIPO: "NEQUI"}
+		So(store.MetodosPago().Insert(&metodo), ShouldBeNil)
+		checkout, err := services.NewCheckoutService(store).Checkout(&models.CheckoutRequest{
+			PRODUCTOS:         []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2}},
+			PK_ID_METODO_PAGO: metodo.PK_ID_METODO_PAGO,
+		}, cliente)
+		So(err, ShouldBeNil)
+		pedidoID := checkout.PEDIDO.PK_ID_PEDIDO
+
+		Convey("El cliente cancela su pedido pagado: repone el stock y deja un reembolso pendiente", func() {
+			cancelacion, err := service.Cancelar(pedidoID, "cliente_desiste", "Ya no lo necesito", cliente)
+			So(err, ShouldBeNil)
+			So(cancelacion.ESTADO_ANTERIOR, ShouldEqual, services.EstadoPagado)
+			So(len(cancelacion.REEMBOLSOS), ShouldEqual, 1)
+			So(cancelacion.REEMBOLSOS[0].MONTO, ShouldEqual, 40000)
+			So(cancelacion.REEMBOLSOS[0].ESTADO, ShouldEqual, services.ReembolsoPendiente)
+
+			producto, _ := store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 10)
+			pago, _ := store.Pagos().Get(checkout.PAGO.PK_ID_PAGO)
+			So(pago.ESTADO_PAGO, ShouldEqual, services.PagoReembolsado)
+			So(errorCode(services.NewPagoService(store).Delete(pago.PK_ID_PAGO)), ShouldEqual, http.StatusConflict)
+
+			_, err = service.Cancelar(pedidoID, "OTRO", "", admin)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			hoy := time.Now().In(database.BogotaZone).Format("2006-01-02")
+			reporte, err := services.NewReporteService(store).Cancelaciones(hoy, hoy, admin)
+			So(err, ShouldBeNil)
+			So(reporte.CANCELACIONES, ShouldEqual, 1)
+			So(reporte.REEMBOLSADO, ShouldEqual, 40000)
+			So(reporte.POR_MOTIVO[0].MOTIVO, ShouldEqual, services.CancelacionClienteDesiste)
+		})
+
+		Convey("El cliente no cancela pedidos en cocina ni después del plazo", func() {
+			_, err := services.NewPedidoService(store).UpdateEstado(pedidoID, services.EstadoEnPreparacion, mesero)
+			So(err, ShouldBeNil)
+			_, err = service.Cancelar(pedidoID, "CLIENTE_DESISTE", "", cliente)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			documento := int64(2001)
+			antiguo := models.Pedido{ESTADO_PEDIDO: services.EstadoIniciado}
+			So(store.Pedidos().Insert(&antiguo), ShouldBeNil)
+			So(store.PedidosClientes().Insert(&models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &documento, PK_ID_PEDIDO: &antiguo.PK_ID_PEDIDO}), ShouldBeNil)
+			So(store.HistorialEstados().Insert(&models.PedidoEstadoHistorial{PK_ID_PEDIDO: antiguo.PK_ID_PEDIDO, ESTADO_NUEVO: services.EstadoIniciado, FECHA: time.Now().Add(-time.Hour)}), ShouldBeNil)
+			_, err = service.Cancelar(antiguo.PK_ID_PEDIDO, "CLIENTE_DESISTE", "", cliente)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			_, err = service.Cancelar(pedidoID, "CLIENTE_DESISTE", "", services.Actor{Documento: 2002, Rol: services.RolCliente})
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("Solo el administrador cancela un pedido entregado y sus productos no vuelven al inventario", func() {
+			pedidos := services.NewPedidoService(store)
+			for _, estado := range []string{services.EstadoEnPreparacion, services.EstadoListo, services.EstadoEntregado} {
+				_, err := pedidos.UpdateEstado(pedidoID, estado, mesero)
+				So(err, ShouldBeNil)
+			}
+			_, err := service.Cancelar(pedidoID, "ERROR_PEDIDO", "", mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			cancelacion, err := service.Cancelar(pedidoID, "ERROR_PEDIDO", "", admin)
+			So(err, ShouldBeNil)
+			So(cancelacion.REEMBOLSOS, ShouldNotBeEmpty)
+			producto, _ := store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 8)
+		})
+
+		Convey("Un motivo desconocido responde 400", func() {
+			_, err := service.Cancelar(pedidoID, "PERDIDO", "", admin)
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+		})
+	})
+}
+
+func TestMesaService(t *testing.T) {
+	Convey("Subject: Mesas del salón y sus rondas\n", t, func() {
+		store := nuevoStore()
+		service := services.NewMesaService(store)
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+		admin := services.Actor{Documento: 1001, Rol: services.RolAdministrador}
+
+		So(store.Trabajadores().Insert(&models.Trabajador{PK_DOCUMENTO_TRABAJADOR: 1015466494, ROL: "Mesero"}), ShouldBeNil)
+		ventana := models.Mesa{NUMERO: 1, CAPACIDAD: 4, ZONA: "salon"}
+		terraza := models.Mesa{NUMERO: 2, CAPACIDAD: 2, ZONA: "terraza"}
+		So(service.Create(&ventana, admin), ShouldBeNil)
+		So(service.Create(&terraza, admin), ShouldBeNil)
+		So(ventana.ESTADO, ShouldEqual, services.MesaLibre)
+		So(ventana.ZONA, ShouldEqual, "SALON")
+
+		Convey("Varias rondas en una mesa quedan a nombre del mesero", func() {
+			primera := models.Pedido{}
+			segunda := models.Pedido{}
+			So(service.AbrirRonda(ventana.PK_ID_MESA, &primera, mesero), ShouldBeNil)
+			So(service.AbrirRonda(ventana.PK_ID_MESA, &segunda, mesero), ShouldBeNil)
+			So(*primera.PK_DOCUMENTO_MESERO, ShouldEqual, 1015466494)
+
+			mesa, err := service.GetByID(ventana.PK_ID_MESA)
+			So(err, ShouldBeNil)
+			So(mesa.ESTADO, ShouldEqual, services.MesaOcupada)
+			So(len(mesa.PEDIDOS), ShouldEqual, 2)
+
+			_, err = service.UpdateEstado(ventana.PK_ID_MESA, services.MesaLibre, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			domicilio := models.Pedido{DELIVERY: true}
+			So(errorCode(service.AbrirRonda(ventana.PK_ID_MESA, &domicilio, mesero)), ShouldEqual, http.StatusConflict)
+			ajeno := int64(999)
+			otro := models.Pedido{PK_DOCUMENTO_MESERO: &ajeno}
+			So(errorCode(service.AbrirRonda(ventana.PK_ID_MESA, &otro, mesero)), ShouldEqual, http.StatusUnprocessableEntity)
+		})
+
+		Convey("Mover exige una mesa libre y unir una ocupada; el origen queda por limpiar", func() {
+			ronda := models.Pedido{}
+			So(service.AbrirRonda(ventana.PK_ID_MESA, &ronda, mesero), ShouldBeNil)
+
+			_, err := service.Trasladar(ventana.PK_ID_MESA, terraza.PK_ID_MESA, true, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+			destino, err := service.Trasladar(ventana.PK_ID_MESA, terraza.PK_ID_MESA, false, mesero)
+			So(err, ShouldBeNil)
+			So(destino.ESTADO, ShouldEqual, services.MesaOcupada)
+			So(destino.PEDIDOS[0].PK_ID_PEDIDO, ShouldEqual, ronda.PK_ID_PEDIDO)
+
+			origen, _ := service.GetByID(ventana.PK_ID_MESA)
+			So(origen.ESTADO, ShouldEqual, services.MesaPorLimpiar)
+			So(origen.PEDIDOS, ShouldBeEmpty)
+			otra := models.Pedido{}
+			So(errorCode(service.AbrirRonda(ventana.PK_ID_MESA, &otra, mesero)), ShouldEqual, http.StatusConflict)
+
+			_, err = service.UpdateEstado(ventana.PK_ID_MESA, services.MesaLibre, mesero)
+			So(err, ShouldBeNil)
+			So(service.AbrirRonda(ventana.PK_ID_MESA, &otra, mesero), ShouldBeNil)
+			unida, err := service.Trasladar(ventana.PK_ID_MESA, terraza.PK_ID_MESA, true, mesero)
+			So(err, ShouldBeNil)
+			So(len(unida.PEDIDOS), ShouldEqual, 2)
+		})
+
+		Convey("El plano agrupa las mesas por zona y cuenta sus estados", func() {
+			ronda := models.Pedido{}
+			So(service.AbrirRonda(terraza.PK_ID_MESA, &ronda, mesero), ShouldBeNil)
+
+			plano, err := service.Plano()
+			So(err, ShouldBeNil)
+			So(plano.LIBRES, ShouldEqual, 1)
+			So(plano.OCUPADAS, ShouldEqual, 1)
+			So(len(plano.ZONAS), ShouldEqual, 2)
+			So(plano.ZONAS[1].MESAS[0].PEDIDOS[0].PK_ID_PEDIDO, ShouldEqual, ronda.PK_ID_PEDIDO)
+		})
+
+		Convey("Un número repetido responde 409 y los clientes no administran mesas", func() {
+			So(errorCode(service.Create(&models.Mesa{NUMERO: 1, CAPACIDAD: 2, ZONA: "BARRA"}, admin)), ShouldEqual, http.StatusConflict)
+			So(errorCode(service.Create(&models.Mesa{NUMERO: 9, CAPACIDAD: 2, ZONA: "BARRA"}, services.Actor{Documento: 2001, Rol: services.RolCliente})), ShouldEqual, http.StatusForbidden)
+		})
+	})
+}
+
+func TestCuentaService(t *testing.T) {
+	Convey("Subject: Cuenta dividida entre varios pagadores\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Bandeja", 20000), nuevoProducto(8, "Limonada", 10000))
+		service := services.NewCuentaService(store)
+		pedidos := services.NewPedidoService(store)
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+
+		So(store.Clientes().Insert(&models.Cliente{PK_DOCUMENTO_CLIENTE: 2001}), ShouldBeNil)
+		metodo := models.MetodoPago{TIPO: "EFECTIVO"}
+		So(store.MetodosPago().Insert(&metodo), ShouldBeNil)
+
+		pedido := models.Pedido{}
+		So(pedidos.Create(&pedido, mesero), ShouldBeNil)
+		_, err := services.NewProductoPedidoService(store).Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}, {PK_ID_PRODUCTO: 8, CANTIDAD: 1}}, mesero)
+		So(err, ShouldBeNil)
+		parte := models.ParteCuenta{PK_ID_METODO_PAGO: metodo.PK_ID_METODO_PAGO}
+
+		cobrar := func(cuenta *models.CuentaPedido, i int) *models.Pedido {
+			actual, err := pedidos.AssignPago(pedido.PK_ID_PEDIDO, cuenta.PAGOS[i].PK_ID_PAGO, mesero)
+			So(err, ShouldBeNil)
+			return actual
+		}
+
+		Convey("En partes iguales el pedido pasa a PAGADO solo cuando se cobran todas", func() {
+			pagador := parte
+			pagador.PK_DOCUMENTO_CLIENTE = 2001
+			cuenta, err := service.Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "iguales", PARTES: []models.ParteCuenta{pagador, parte, parte, parte}}, mesero)
+			So(err, ShouldBeNil)
+			So(len(cuenta.PAGOS), ShouldEqual, 4)
+			So(cuenta.PAGOS[0].MONTO, ShouldEqual, 7500)
+			So(cuenta.PENDIENTE, ShouldEqual, 30000)
+			So(cuenta.PAGOS[0].PK_ID_PEDIDO_CLIENTE, ShouldNotBeNil)
+			So(cuenta.PAGOS[1].PK_ID_PEDIDO_CLIENTE, ShouldBeNil)
+
+			So(cobrar(cuenta, 0).ESTADO_PEDIDO, ShouldEqual, services.EstadoIniciado)
+			parcial, _ := service.Cuenta(pedido.PK_ID_PEDIDO, mesero)
+			So(parcial.SALDO, ShouldEqual, 22500)
+
+			_, err = service.Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "IGUALES", PARTES: []models.ParteCuenta{parte}}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			cobrar(cuenta, 1)
+			cobrar(cuenta, 2)
+			So(cobrar(cuenta, 3).ESTADO_PEDIDO, ShouldEqual, services.EstadoPagado)
+			final, _ := service.Cuenta(pedido.PK_ID_PEDIDO, mesero)
+			So(final.SALDO, ShouldEqual, 0)
+			So(final.COBRADO, ShouldEqual, 30000)
+		})
+
+		Convey("Por producto cada parte paga sus líneas y todas deben quedar asignadas", func() {
+			lineas, _ := store.DetallesPedido().ListByPedido(pedido.PK_ID_PEDIDO)
+			bandeja, limonada := parte, parte
+			bandeja.DETALLES = []int64{lineas[0].PK_ID_DETALLE_PEDIDO}
+			limonada.DETALLES = []int64{lineas[1].PK_ID_DETALLE_PEDIDO}
+
+			_, err := service.Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "POR_PRODUCTO", PARTES: []models.ParteCuenta{bandeja}}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+			ajena := parte
+			ajena.DETALLES = []int64{999}
+			_, err = service.Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "POR_PRODUCTO", PARTES: []models.ParteCuenta{bandeja, limonada, ajena}}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+
+			cuenta, err := service.Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "POR_PRODUCTO", PARTES: []models.ParteCuenta{bandeja, limonada}}, mesero)
+			So(err, ShouldBeNil)
+			So(cuenta.PAGOS[0].MONTO, ShouldEqual, 20000)
+			So(cuenta.PAGOS[1].MONTO, ShouldEqual, 10000)
+		})
+
+		Convey("Por monto lo que no se reparte queda como saldo", func() {
+			abono := parte
+			abono.MONTO = 12000
+			cuenta, err := service.Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "MONTO", PARTES: []models.ParteCuenta{abono}}, mesero)
+			So(err, ShouldBeNil)
+			So(cuenta.SALDO, ShouldEqual, 30000)
+			So(cuenta.PENDIENTE, ShouldEqual, 12000)
+			cobrar(cuenta, 0)
+
+			resto := parte
+			resto.MONTO = 20000
+			_, err = service.Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "MONTO", PARTES: []models.ParteCuenta{resto}}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+			resto.MONTO = 18000
+			cuenta, err = service.Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "MONTO", PARTES: []models.ParteCuenta{resto}}, mesero)
+			So(err, ShouldBeNil)
+			So(cobrar(cuenta, 1).ESTADO_PEDIDO, ShouldEqual, services.EstadoPagado)
+		})
+
+		Convey("Un cliente solo ve la cuenta, el recibo y la lista de sus propios pedidos", func() {
+			documento := int64(2001)
+			So(store.PedidosClientes().Insert(&models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &documento, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO}), ShouldBeNil)
+			ajeno := models.Pedido{}
+			So(pedidos.Create(&ajeno, mesero), ShouldBeNil)
+			cliente := services.Actor{Documento: 2001, Rol: services.RolCliente}
+			otro := services.Actor{Documento: 2002, Rol: services.RolCliente}
+
+			cuenta, err := service.Cuenta(pedido.PK_ID_PEDIDO, cliente)
+			So(err, ShouldBeNil)
+			So(cuenta.SALDO, ShouldEqual, 30000)
+			_, err = service.Cuenta(pedido.PK_ID_PEDIDO, otro)
+			So(errorCode(err), ShouldEqual, http.StatusNotFound)
+			_, err = pedidos.GetRecibo(pedido.PK_ID_PEDIDO, otro)
+			So(errorCode(err), ShouldEqual, http.StatusNotFound)
+			_, err = services.NewProductoPedidoService(store).List(int64(pedido.PK_ID_PEDIDO), otro)
+			So(errorCode(err), ShouldEqual, http.StatusNotFound)
+
+			propios, err := pedidos.List(services.PedidoFiltros{}, cliente)
+			So(err, ShouldBeNil)
+			So(len(propios), ShouldEqual, 1)
+			So(propios[0].PK_ID_PEDIDO, ShouldEqual, pedido.PK_ID_PEDIDO)
+			ajenos, err := pedidos.List(services.PedidoFiltros{Cliente: 2001}, otro)
+			So(err, ShouldBeNil)
+			So(ajenos, ShouldBeEmpty)
+		})
+
+		Convey("Solo el personal agrega pagadores al pedido y ellos ven la cuenta que comparten", func() {
+			So(store.Clientes().Insert(&models.Cliente{PK_DOCUMENTO_CLIENTE: 2002}), ShouldBeNil)
+			documento := int64(2001)
+			So(store.PedidosClientes().Insert(&models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &documento, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO}), ShouldBeNil)
+			cliente := services.Actor{Documento: 2001, Rol: services.RolCliente}
+			amigo := services.Actor{Documento: 2002, Rol: services.RolCliente}
+			propia, ajena := parte, parte
+			propia.PK_DOCUMENTO_CLIENTE, ajena.PK_DOCUMENTO_CLIENTE = 2001, 2002
+
+			_, err := service.Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "IGUALES", PARTES: []models.ParteCuenta{propia, ajena}}, cliente)
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+			_, err = service.Cuenta(pedido.PK_ID_PEDIDO, amigo)
+			So(errorCode(err), ShouldEqual, http.StatusNotFound)
+
+			cuenta, err := service.Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "IGUALES", PARTES: []models.ParteCuenta{propia, ajena}}, mesero)
+			So(err, ShouldBeNil)
+			So(len(cuenta.PAGOS), ShouldEqual, 2)
+			compartida, err := service.Cuenta(pedido.PK_ID_PEDIDO, amigo)
+			So(err, ShouldBeNil)
+			So(compartida.PENDIENTE, ShouldEqual, 30000)
+		})
+
+		Convey("Cancelar el pedido reembolsa solo las partes cobradas", func() {
+			cuenta, err := service.Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "IGUALES", PARTES: []models.ParteCuenta{parte, parte}}, mesero)
+			So(err, ShouldBeNil)
+			cobrar(cuenta, 0)
+
+			admin := services.Actor{Documento: 1001, Rol: services.RolAdministrador}
+			cancelacion, err := services.NewCancelacionService(store).Cancelar(pedido.PK_ID_PEDIDO, "OTRO", "", admin)
+			So(err, ShouldBeNil)
+			So(len(cancelacion.REEMBOLSOS), ShouldEqual, 1)
+			So(cancelacion.REEMBOLSOS[0].MONTO, ShouldEqual, 15000)
+			pendiente, _ := store.Pagos().Get(cuenta.PAGOS[1].PK_ID_PAGO)
+			So(pendiente.ESTADO_PAGO, ShouldEqual, "PENDIENTE")
+		})
+	})
+}
+
+func TestCocinaService(t *testing.T) {
+	Convey("Subject: Tickets de cocina por estación\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Churrasco", 30000, conStock(10), enEstacion("PARRILLA")), nuevoProducto(8, "Limonada", 8000, enEstacion("BEBIDAS")), nuevoProducto(9, "Papas", 6000))
+		service := services.NewCocinaService(store)
+		pedidos := services.NewPedidoService(store)
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+
+		pedido := models.Pedido{}
+		So(pedidos.Create(&pedido, mesero), ShouldBeNil)
+		_, err := services.NewProductoPedidoService(store).Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2, NOTAS: "Término medio"}, {PK_ID_PRODUCTO: 8, CANTIDAD: 1}}, mesero)
+		So(err, ShouldBeNil)
+
+		tickets, err := service.Tickets("", "", mesero)
+		So(err, ShouldBeNil)
+		So(tickets, ShouldBeEmpty)
+
+		_, err = pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoEnPreparacion, mesero)
+		So(err, ShouldBeNil)
+		parrilla, err := service.Tickets("parrilla", "", mesero)
+		So(err, ShouldBeNil)
+		So(len(parrilla), ShouldEqual, 1)
+		So(parrilla[0].ITEMS[0].CANTIDAD, ShouldEqual, 2)
+		So(*parrilla[0].ITEMS[0].NOTAS, ShouldEqual, "Término medio")
+		bebidas, _ := service.Tickets("BEBIDAS", "", mesero)
+		So(len(bebidas), ShouldEqual, 1)
+
+		estadoPedido := func() string {
+			actual, err := pedidos.GetByID(pedido.PK_ID_PEDIDO)
+			So(err, ShouldBeNil)
+			return actual.ESTADO_PEDIDO
+		}
+
+		Convey("El pedido pasa a LISTO cuando todas las estaciones terminan", func() {
+			ticket, err := service.Bump(parrilla[0].PK_ID_TICKET, mesero)
+			So(err, ShouldBeNil)
+			So(ticket.ESTADO, ShouldEqual, services.TicketListo)
+			So(ticket.LISTO_EN, ShouldNotBeNil)
+			So(estadoPedido(), ShouldEqual, services.EstadoEnPreparacion)
+
+			ticket, err = service.UpdateItem(bebidas[0].PK_ID_TICKET, bebidas[0].ITEMS[0].PK_ID_ITEM_TICKET, "preparando", mesero)
+			So(err, ShouldBeNil)
+			So(ticket.ESTADO, ShouldEqual, services.TicketPreparando)
+			_, err = service.UpdateItem(bebidas[0].PK_ID_TICKET, bebidas[0].ITEMS[0].PK_ID_ITEM_TICKET, services.TicketListo, mesero)
+			So(err, ShouldBeNil)
+			So(estadoPedido(), ShouldEqual, services.EstadoListo)
+
+			pendientes, _ := service.Tickets("", "", mesero)
+			So(pendientes, ShouldBeEmpty)
+			listos, _ := service.Tickets("", services.TicketListo, mesero)
+			So(len(listos), ShouldEqual, 2)
+		})
+
+		Convey("Recall devuelve el ticket y el pedido a la cocina", func() {
+			_, err := service.Recall(parrilla[0].PK_ID_TICKET, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			service.Bump(parrilla[0].PK_ID_TICKET, mesero)
+			service.Bump(bebidas[0].PK_ID_TICKET, mesero)
+			So(estadoPedido(), ShouldEqual, services.EstadoListo)
+
+			ticket, err := service.Recall(parrilla[0].PK_ID_TICKET, mesero)
+			So(err, ShouldBeNil)
+			So(ticket.ESTADO, ShouldEqual, services.TicketPreparando)
+			So(ticket.ITEMS[0].ESTADO, ShouldEqual, services.TicketPreparando)
+			So(estadoPedido(), ShouldEqual, services.EstadoEnPreparacion)
+
+			historial, _ := store.HistorialEstados().ListByPedido(pedido.PK_ID_PEDIDO)
+			So(historial[len(historial)-1].ESTADO_NUEVO, ShouldEqual, services.EstadoEnPreparacion)
+		})
+
+		Convey("Los productos agregados en preparación llegan en un ticket nuevo", func() {
+			_, err := services.NewProductoPedidoService(store).Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 9, CANTIDAD: 1}}, mesero)
+			So(err, ShouldBeNil)
+			general, _ := service.Tickets(services.EstacionGeneral, "", mesero)
+			So(len(general), ShouldEqual, 1)
+			So(general[0].ITEMS[0].NOMBRE, ShouldEqual, "Papas")
+			todos, _ := service.Tickets("", "", mesero)
+			So(len(todos), ShouldEqual, 3)
+		})
+
+		Convey("Editar los productos después de un bump conserva los tickets y solo envía la diferencia", func() {
+			lineas := services.NewProductoPedidoService(store)
+			_, err := service.Bump(parrilla[0].PK_ID_TICKET, mesero)
+			So(err, ShouldBeNil)
+
+			respuesta, err := lineas.Update(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 3, NOTAS: "Término medio"}, {PK_ID_PRODUCTO: 8, CANTIDAD: 1}}, mesero)
+			So(err, ShouldBeNil)
+			So(len(respuesta.DETALLES_PRODUCTOS), ShouldEqual, 3)
+			producto, _ := store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 7)
+			listos, _ := service.Tickets("PARRILLA", services.TicketListo, mesero)
+			So(len(listos), ShouldEqual, 1)
+			So(listos[0].PK_ID_TICKET, ShouldEqual, parrilla[0].PK_ID_TICKET)
+			nuevos, _ := service.Tickets("PARRILLA", "", mesero)
+			So(len(nuevos), ShouldEqual, 1)
+			So(nuevos[0].ITEMS[0].CANTIDAD, ShouldEqual, 1)
+			actuales, _ := service.Tickets("BEBIDAS", "", mesero)
+			So(actuales[0].PK_ID_TICKET, ShouldEqual, bebidas[0].PK_ID_TICKET)
+
+			_, err = lineas.Update(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1, NOTAS: "Término medio"}}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			_, err = lineas.Update(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2, NOTAS: "Término medio"}}, mesero)
+			So(err, ShouldBeNil)
+			producto, _ = store.Productos().Get(7)
+			So(producto.CANTIDAD, ShouldEqual, 8)
+			pendientes, _ := service.Tickets("", "", mesero)
+			So(pendientes, ShouldBeEmpty)
+			So(estadoPedido(), ShouldEqual, services.EstadoListo)
+
+			_, err = lineas.Update(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 3, NOTAS: "Término medio"}}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+			_, err = lineas.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 9, CANTIDAD: 1}}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("Los clientes no ven la cocina y un pedido cancelado sale de la pantalla", func() {
+			_, err := service.Tickets("", "", services.Actor{Documento: 2001, Rol: services.RolCliente})
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+			_, err = service.Tickets("", "QUEMADO", mesero)
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+			_, err = service.UpdateItem(parrilla[0].PK_ID_TICKET, 999, services.TicketListo, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusNotFound)
+
+			_, err = pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoCancelado, mesero)
+			So(err, ShouldBeNil)
+			tickets, _ := service.Tickets("", "", mesero)
+			So(tickets, ShouldBeEmpty)
+			_, err = service.Bump(parrilla[0].PK_ID_TICKET, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+		})
+	})
+}
+
+func TestEventoHub(t *testing.T) {
+	Convey("Subject: Eventos en tiempo real filtrados por rol\n", t, func() {
+		store := nuevoStore()
+		pedidos := services.NewPedidoService(store)
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+
+		suscribir := func(actor services.Actor, tipos ...string) *services.Suscripcion {
+			suscripcion, err := services.Eventos.Suscribir(actor, tipos)
+			So(err, ShouldBeNil)
+			Reset(suscripcion.Cerrar)
+			return suscripcion
+		}
+		// recibidos devuelve los eventos en cola; se publican al confirmar, antes de que vuelva la operación
+		recibidos := func(suscripcion *services.Suscripcion) []models.Evento {
+			eventos := []models.Evento{}
+			for {
+				select {
+				case evento := <-suscripcion.Eventos:
+					eventos = append(eventos, evento)
+				default:
+					return eventos
+				}
+			}
+		}
+
+		cocina := suscribir(mesero)
+		cliente := suscribir(services.Actor{Documento: 111, Rol: services.RolCliente})
+		otroCliente := suscribir(services.Actor{Documento: 222, Rol: services.RolCliente})
+		domiciliario := suscribir(services.Actor{Documento: 333, Rol: "domiciliario"})
+		reservas := suscribir(mesero, "reserva")
+
+		pedido := models.Pedido{}
+		So(pedidos.Create(&pedido, mesero), ShouldBeNil)
+		documento := int64(111)
+		So(store.PedidosClientes().Insert(&models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &documento, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO}), ShouldBeNil)
+
+		Convey("El personal ve los pedidos nuevos y el cliente solo los suyos", func() {
+			eventos := recibidos(cocina)
+			So(len(eventos), ShouldEqual, 1)
+			So(eventos[0].TIPO, ShouldEqual, services.EventoPedido)
+			So(eventos[0].ACCION, ShouldEqual, services.AccionCreado)
+			So(eventos[0].PK_ID, ShouldEqual, pedido.PK_ID_PEDIDO)
+			So(eventos[0].ID, ShouldBeGreaterThan, 0)
+			So(recibidos(reservas), ShouldBeEmpty)
+
+			_, err := pedidos.UpdatePropina(pedido.PK_ID_PEDIDO, true, mesero)
+			So(err, ShouldBeNil)
+			eventos = recibidos(cliente)
+			So(len(eventos), ShouldEqual, 1)
+			So(eventos[0].ACCION, ShouldEqual, services.AccionActualizado)
+			So(recibidos(otroCliente), ShouldBeEmpty)
+			So(recibidos(domiciliario), ShouldBeEmpty)
+		})
+
+		Convey("El domiciliario ve los domicilios que tiene asignados y sus pedidos", func() {
+			So(store.Trabajadores().Insert(&models.Trabajador{PK_DOCUMENTO_TRABAJADOR: 333, ROL: "Domiciliario"}), ShouldBeNil)
+			domicilio := models.Domicilio{DIRECCION: "Calle 1 # 2-3", TELEFONO: "3001234567", ESTADO_PAGO: "PENDIENTE"}
+			So(store.Domicilios().Insert(&domicilio), ShouldBeNil)
+
+			_, err := services.NewDomicilioService(store).AssignDomiciliario(domicilio.PK_ID_DOMICILIO, 333, mesero)
+			So(err, ShouldBeNil)
+			_, err = pedidos.AssignDomicilio(pedido.PK_ID_PEDIDO, domicilio.PK_ID_DOMICILIO, mesero)
+			So(err, ShouldBeNil)
+
+			eventos := recibidos(domiciliario)
+			So(len(eventos), ShouldBeGreaterThanOrEqualTo, 2)
+			So(eventos[0].TIPO, ShouldEqual, services.EventoDomicilio)
+			So(eventos[0].ACCION, ShouldEqual, services.AccionAsignado)
+			tipos := []string{}
+			for _, evento := range eventos {
+				tipos = append(tipos, evento.TIPO)
+			}
+			So(tipos, ShouldContain, services.EventoPedido)
+		})
+
+		Convey("Un cambio revertido no se publica", func() {
+			recibidos(cocina)
+			err := store.Transaction(func(tx repositories.Store) error {
+				if _, err := services.NewPedidoService(tx).UpdatePropina(pedido.PK_ID_PEDIDO, true, mesero); err != nil {
+					return err
+				}
+				return errors.New("revertir")
+			})
+			So(err, ShouldNotBeNil)
+			So(recibidos(cocina), ShouldBeEmpty)
+		})
+
+		Convey("Un tipo desconocido responde 400", func() {
+			_, err := services.Eventos.Suscribir(mesero, []string{"FACTURA"})
+			var svcErr *services.Error
+			So(errors.As(err, &svcErr), ShouldBeTrue)
+			So(svcErr.Code, ShouldEqual, http.StatusBadRequest)
+		})
+	})
+}
+
+func TestTiemposPedido(t *testing.T) {
+	Convey("Subject: Entrega estimada y tiempos reales de los pedidos\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Churrasco", 30000, conPreparacion(25)), nuevoProducto(8, "Limonada", 8000, conPreparacion(5)))
+		pedidos := services.NewPedidoService(store)
+		lineas := services.NewProductoPedidoService(store)
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+
+		// Un pedido que ya está en la cocina por delante
+		anterior := models.Pedido{}
+		So(pedidos.Create(&anterior, mesero), ShouldBeNil)
+		_, err := lineas.Create(int64(anterior.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 8, CANTIDAD: 1}}, mesero)
+		So(err, ShouldBeNil)
+		_, err = pedidos.UpdateEstado(anterior.PK_ID_PEDIDO, services.EstadoEnPreparacion, mesero)
+		So(err, ShouldBeNil)
+
+		distancia := 2.0
+		domicilio := models.Domicilio{DIRECCION: "Calle 1 # 2-3", TELEFONO: "3001234567", ESTADO_PAGO: "PENDIENTE", DISTANCIA_KM: &distancia}
+		So(store.Domicilios().Insert(&domicilio), ShouldBeNil)
+		pedido := models.Pedido{DELIVERY: true}
+		So(pedidos.Create(&pedido, mesero), ShouldBeNil)
+		So(pedido.ENTREGA_ESTIMADA, ShouldNotBeNil)
+
+		// Preparación: el producto más demorado más 3 minutos por el pedido en cola; entrega: 10
+		// minutos de salida más 3 por kilómetro
+		_, err = lineas.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}, {PK_ID_PRODUCTO: 8, CANTIDAD: 2}}, mesero)
+		So(err, ShouldBeNil)
+		_, err = pedidos.AssignDomicilio(pedido.PK_ID_PEDIDO, domicilio.PK_ID_DOMICILIO, mesero)
+		So(err, ShouldBeNil)
+		actual, err := pedidos.GetByID(pedido.PK_ID_PEDIDO)
+		So(err, ShouldBeNil)
+		So(*actual.ENTREGA_ESTIMADA, ShouldHappenWithin, time.Minute, time.Now().Add((25+3+10+6)*time.Minute))
+
+		details, err := pedidos.GetDetails(int64(pedido.PK_ID_PEDIDO), mesero)
+		So(err, ShouldBeNil)
+		So(details.EntregaEstimada, ShouldNotBeEmpty)
+
+		Convey("Se registran los minutos estimados y reales de cada etapa", func() {
+			_, err := pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoEnPreparacion, mesero)
+			So(err, ShouldBeNil)
+			tiempo, err := store.TiemposPedido().Get(pedido.PK_ID_PEDIDO)
+			So(err, ShouldBeNil)
+			So(tiempo.COLA, ShouldEqual, 1)
+			So(tiempo.PREPARACION_ESTIMADA, ShouldEqual, 28)
+			So(tiempo.ENTREGA_ESTIMADA, ShouldEqual, 16)
+			So(*tiempo.DISTANCIA_KM, ShouldEqual, 2.0)
+			So(tiempo.PREPARACION_REAL, ShouldBeNil)
+
+			for _, estado := range []string{services.EstadoListo, services.EstadoEnCamino, services.EstadoEntregado} {
+				_, err = pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, estado, mesero)
+				So(err, ShouldBeNil)
+			}
+			tiempo, err = store.TiemposPedido().Get(pedido.PK_ID_PEDIDO)
+			So(err, ShouldBeNil)
+			So(*tiempo.PREPARACION_REAL, ShouldEqual, 0)
+			So(*tiempo.ENTREGA_REAL, ShouldEqual, 0)
+			So(tiempo.ENTREGADO_EN, ShouldNotBeNil)
+
+			hoy := time.Now().In(database.BogotaZone).Format("2006-01-02")
+			reporte, err := services.NewReporteService(store).Tiempos(hoy, hoy, mesero)
+			So(err, ShouldBeNil)
+			So(reporte.PEDIDOS, ShouldEqual, 2)
+			So(reporte.ENTREGA_ESTIMADA, ShouldEqual, 16)
+			So(reporte.DESVIACION_ENTREGA, ShouldEqual, -16)
+		})
+
+		Convey("Un pedido cancelado se queda sin entrega estimada", func() {
+			cancelado, err := pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoCancelado, mesero)
+			So(err, ShouldBeNil)
+			So(cancelado.ENTREGA_ESTIMADA, ShouldBeNil)
+		})
+	})
+}
+
+func TestPedidosProgramados(t *testing.T) {
+	Convey("Subject: Pedidos programados para más tarde\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Churrasco", 30000))
+		pedidos := services.NewPedidoService(store)
+		lineas := services.NewProductoPedidoService(store)
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+
+		cierre := "22:00:00"
+		So(store.Restaurantes().Insert(&models.Restaurante{PK_ID_RESTAURANTE: 1, NOMBRE_RESTAURANTE: "El fogón de María", HORA_APERTURA: "08:00:00", HORA_CIERRE: &cierre, DIAS_LABORALES: "Lunes a Viernes"}), ShouldBeNil)
+
+		// Mañana atiende de 11:00 a 15:00 y pasado mañana no abre
+		ahora := time.Now().In(database.BogotaZone)
+		manana := time.Date(ahora.Year(), ahora.Month(), ahora.Day()+1, 0, 0, 0, 0, database.BogotaZone)
+		apertura := time.Date(0, 1, 1, 11, 0, 0, 0, time.UTC)
+		hasta := time.Date(0, 1, 1, 15, 0, 0, 0, time.UTC)
+		So(store.CambiosHorario().Insert(&models.CambiosHorario{FECHA: manana, HORA_APERTURA: &apertura, HORA_CIERRE: &hasta, ABIERTO: true}), ShouldBeNil)
+		So(store.CambiosHorario().Insert(&models.CambiosHorario{FECHA: manana.AddDate(0, 0, 1), ABIERTO: false}), ShouldBeNil)
+
+		pedido := models.Pedido{}
+		So(pedidos.Create(&pedido, mesero), ShouldBeNil)
+		_, err := lineas.Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}}, mesero)
+		So(err, ShouldBeNil)
+
+		programar := func(hora time.Time) error {
+			_, err := pedidos.Programar(pedido.PK_ID_PEDIDO, hora.Format("02-01-2006 15:04:05"), mesero)
+			return err
+		}
+
+		Convey("La hora se valida contra la anticipación, los cambios de horario y el horario habitual", func() {
+			So(programar(ahora.Add(10*time.Minute)).(*services.Error).Code, ShouldEqual, http.StatusUnprocessableEntity)
+			So(programar(manana.Add(16*time.Hour)).(*services.Error).Code, ShouldEqual, http.StatusUnprocessableEntity)
+			So(programar(manana.AddDate(0, 0, 1).Add(12*time.Hour)).(*services.Error).Code, ShouldEqual, http.StatusUnprocessableEntity)
+			So(programar(manana.AddDate(0, 0, 30).Add(12*time.Hour)).(*services.Error).Code, ShouldEqual, http.StatusUnprocessableEntity)
+
+			// Sin cambio de horario mandan los días laborales del restaurante
+			sabado := manana.AddDate(0, 0, 2)
+			for sabado.Weekday() != time.Saturday {
+				sabado = sabado.AddDate(0, 0, 1)
+			}
+			So(programar(sabado.Add(12*time.Hour)).(*services.Error).Code, ShouldEqual, http.StatusUnprocessableEntity)
+
+			// Un cliente no programa pedidos ajenos
+			_, err := pedidos.Programar(pedido.PK_ID_PEDIDO, "", services.Actor{Documento: 1010, Rol: services.RolCliente})
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("Un pedido programado espera fuera de la cocina hasta su hora de liberación", func() {
+			hora := manana.Add(12*time.Hour + 30*time.Minute)
+			So(programar(hora), ShouldBeNil)
+			actual, err := pedidos.GetByID(pedido.PK_ID_PEDIDO)
+			So(err, ShouldBeNil)
+			So(actual.PROGRAMADO_PARA.Equal(hora), ShouldBeTrue)
+			So(actual.ENTREGA_ESTIMADA.Equal(hora), ShouldBeTrue)
+
+			_, err = pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoEnPreparacion, mesero)
+			So(err.(*services.Error).Code, ShouldEqual, http.StatusConflict)
+
+			programados, err := pedidos.Programados(mesero)
+			So(err, ShouldBeNil)
+			So(programados, ShouldHaveLength, 1)
+			_, err = pedidos.Programados(services.Actor{Documento: 1010, Rol: services.RolCliente})
+			So(err.(*services.Error).Code, ShouldEqual, http.StatusForbidden)
+
+			liberados, err := pedidos.LiberarProgramados(ahora)
+			So(err, ShouldBeNil)
+			So(liberados, ShouldBeEmpty)
+
+			// 45 minutos antes entra a la cocina
+			liberados, err = pedidos.LiberarProgramados(hora.Add(-45 * time.Minute))
+			So(err, ShouldBeNil)
+			So(liberados, ShouldHaveLength, 1)
+			So(liberados[0].ESTADO_PEDIDO, ShouldEqual, services.EstadoEnPreparacion)
+			tickets, err := store.TicketsCocina().ListByPedido(pedido.PK_ID_PEDIDO)
+			So(err, ShouldBeNil)
+			So(tickets, ShouldHaveLength, 1)
+
+			programados, err = pedidos.Programados(mesero)
+			So(err, ShouldBeNil)
+			So(programados, ShouldBeEmpty)
+			_, err = pedidos.Programar(pedido.PK_ID_PEDIDO, "", mesero)
+			So(err.(*services.Error).Code, ShouldEqual, http.StatusConflict)
+		})
+	})
+}
+
+func TestImpresion(t *testing.T) {
+	Convey("Subject: Recibos y comandas impresos en impresoras de red\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Churrasco con papas criollas y ensalada", 30000, enEstacion("PARRILLA")))
+		pedidos := services.NewPedidoService(store)
+		impresoras := services.NewImpresionService(store)
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+		admin := services.Actor{Documento: 1, Rol: "Administrador"}
+
+		pedido := models.Pedido{}
+		So(pedidos.Create(&pedido, mesero), ShouldBeNil)
+		_, err := services.NewProductoPedidoService(store).Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 2, NOTAS: "Sin sal"}}, mesero)
+		So(err, ShouldBeNil)
+
+		Convey("El recibo cabe en el ancho del papel y lleva los totales", func() {
+			for ancho, columnas := range map[int]int{58: 32, 80: 48} {
+				documento, err := pedidos.Recibo(pedido.PK_ID_PEDIDO, ancho, services.Actor{})
+				So(err, ShouldBeNil)
+				texto := string(impresion.Texto(documento))
+				So(texto, ShouldContainSubstring, "$60.000")
+				So(texto, ShouldContainSubstring, "TOTAL")
+				for _, renglon := range strings.Split(strings.TrimSuffix(texto, "\n"), "\n") {
+					So(utf8.RuneCountInString(renglon), ShouldBeLessThanOrEqualTo, columnas)
+				}
+			}
+
+			documento, _ := pedidos.Recibo(pedido.PK_ID_PEDIDO, 80, services.Actor{})
+			pdf := impresion.PDF(documento)
+			So(string(pdf), ShouldStartWith, "%PDF-")
+			So(string(pdf), ShouldEndWith, "%%EOF\n")
+
+			documento.AbrirCajon = true
+			escpos := impresion.EscPos(documento)
+			So(escpos[:2], ShouldResemble, []byte{0x1B, '@'})
+			So(bytes.Contains(escpos, []byte{0x1D, 'V', 66, 0}), ShouldBeTrue)
+			So(bytes.Contains(escpos, []byte{0x1B, 'p', 0}), ShouldBeTrue)
+		})
+
+		Convey("Las comandas salen en la impresora de su estación", func() {
+			falsa, err := nuevaImpresoraFalsa("127.0.0.1:0")
+			So(err, ShouldBeNil)
+			defer falsa.Cerrar()
+
+			estacion := "parrilla"
+			parrilla := models.Impresora{NOMBRE: "Parrilla", HOST: "127.0.0.1", PUERTO: falsa.Puerto(), ANCHO: 58, ESTACION: &estacion, ACTIVA: true}
+			So(impresoras.CreateImpresora(&parrilla, admin), ShouldBeNil)
+			So(*parrilla.ESTACION, ShouldEqual, "PARRILLA")
+
+			_, err = impresoras.Imprimir(models.TrabajoImpresionRequest{TIPO: "comanda", PK_ID_PEDIDO: pedido.PK_ID_PEDIDO}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+
+			_, err = pedidos.UpdateEstado(pedido.PK_ID_PEDIDO, services.EstadoEnPreparacion, mesero)
+			So(err, ShouldBeNil)
+			trabajos, err := impresoras.Imprimir(models.TrabajoImpresionRequest{TIPO: "comanda", PK_ID_PEDIDO: pedido.PK_ID_PEDIDO}, mesero)
+			So(err, ShouldBeNil)
+			So(len(trabajos), ShouldEqual, 1)
+			So(trabajos[0].PK_ID_TICKET, ShouldNotBeNil)
+			services.Impresiones.Esperar()
+
+			trabajo, err := store.TrabajosImpresion().Get(trabajos[0].PK_ID_TRABAJO)
+			So(err, ShouldBeNil)
+			So(trabajo.ESTADO, ShouldEqual, services.ImpresionImpreso)
+			So(trabajo.INTENTOS, ShouldEqual, 1)
+			So(trabajo.IMPRESO_EN, ShouldNotBeNil)
+			recibidos := falsa.Esperar(1)
+			So(len(recibidos), ShouldEqual, 1)
+			So(string(recibidos[0]), ShouldContainSubstring, "PARRILLA")
+			So(string(recibidos[0]), ShouldContainSubstring, "NOTA: Sin sal")
+
+			// Sin impresora de caja el recibo no tiene a dónde salir
+			_, err = impresoras.Imprimir(models.TrabajoImpresionRequest{TIPO: "RECIBO", PK_ID_PEDIDO: pedido.PK_ID_PEDIDO}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+			_, err = impresoras.Reintentar(trabajo.PK_ID_TRABAJO, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("Un trabajo se reintenta hasta que la impresora responde", func() {
+			// Un puerto libre en el que la impresora todavía no escucha
+			reservado, err := net.Listen("tcp", "127.0.0.1:0")
+			So(err, ShouldBeNil)
+			direccion := reservado.Addr().String()
+			reservado.Close()
+			_, puerto, _ := net.SplitHostPort(direccion)
+			numero, _ := strconv.Atoi(puerto)
+
+			caja := models.Impresora{NOMBRE: "Caja", HOST: "127.0.0.1", PUERTO: numero, CAJON: true, ACTIVA: true}
+			So(impresoras.CreateImpresora(&caja, admin), ShouldBeNil)
+			So(caja.ANCHO, ShouldEqual, 80)
+
+			trabajos, err := impresoras.Imprimir(models.TrabajoImpresionRequest{TIPO: "RECIBO", PK_ID_PEDIDO: pedido.PK_ID_PEDIDO}, mesero)
+			So(err, ShouldBeNil)
+			time.Sleep(100 * time.Millisecond)
+			falsa, err := nuevaImpresoraFalsa(direccion)
+			So(err, ShouldBeNil)
+			defer falsa.Cerrar()
+			services.Impresiones.Esperar()
+
+			trabajo, err := store.TrabajosImpresion().Get(trabajos[0].PK_ID_TRABAJO)
+			So(err, ShouldBeNil)
+			So(trabajo.ESTADO, ShouldEqual, services.ImpresionImpreso)
+			So(trabajo.INTENTOS, ShouldEqual, 2)
+			recibidos := falsa.Esperar(1)
+			So(len(recibidos), ShouldEqual, 1)
+			So(bytes.HasSuffix(recibidos[0], []byte{0x1B, 'p', 0, 25, 250}), ShouldBeTrue)
+		})
+
+		Convey("Los clientes no manejan las impresoras y solo los administradores las registran", func() {
+			_, err := impresoras.Impresoras(services.Actor{Documento: 2001, Rol: "cliente"})
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+			err = impresoras.CreateImpresora(&models.Impresora{NOMBRE: "Caja", HOST: "127.0.0.1"}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("Los trabajos pendientes se vuelven a encolar al reiniciar", func() {
+			falsa, err := nuevaImpresoraFalsa("127.0.0.1:0")
+			So(err, ShouldBeNil)
+			defer falsa.Cerrar()
+			caja := models.Impresora{NOMBRE: "Caja", HOST: "127.0.0.1", PUERTO: falsa.Puerto(), ACTIVA: true}
+			So(impresoras.CreateImpresora(&caja, admin), ShouldBeNil)
+
+			// Un trabajo que quedó en la cola en memoria cuando se detuvo el servidor
+			trabajo := models.TrabajoImpresion{PK_ID_IMPRESORA: caja.PK_ID_IMPRESORA, PK_ID_PEDIDO: pedido.PK_ID_PEDIDO, TIPO: "RECIBO", ESTADO: services.ImpresionPendiente, CREADO: time.Now()}
+			So(store.TrabajosImpresion().Insert(&trabajo), ShouldBeNil)
+
+			pendientes, err := impresoras.ReanudarPendientes()
+			So(err, ShouldBeNil)
+			So(pendientes, ShouldEqual, 1)
+			services.Impresiones.Esperar()
+
+			actual, err := store.TrabajosImpresion().Get(trabajo.PK_ID_TRABAJO)
+			So(err, ShouldBeNil)
+			So(actual.ESTADO, ShouldEqual, services.ImpresionImpreso)
+			So(falsa.Esperar(1), ShouldHaveLength, 1)
+		})
+	})
+}
+
+func TestFacturacion(t *testing.T) {
+	Convey("Subject: Factura electrónica y documento equivalente POS\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Bandeja paisa", 27000, conImpuesto("IMPOCONSUMO")), nuevoProducto(8, "Gaseosa", 5950, conImpuesto("IVA")))
+		pedidos := services.NewPedidoService(store)
+		facturas := services.NewFacturacionService(store)
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+		admin := services.Actor{Documento: 1, Rol: "Administrador"}
+
+		metodo := models.MetodoPago{TIPO: "EFECTIVO"}
+		So(store.MetodosPago().Insert(&metodo), ShouldBeNil)
+
+		hoy := time.Now().In(database.BogotaZone)
+		resolucion := models.ResolucionFacturacion{NUMERO: "18760000001", TIPO_DOCUMENTO: "pos", PREFIJO: "setp", DESDE: 990000000, HASTA: 990000001, FECHA_DESDE: hoy.AddDate(0, 0, -1), FECHA_HASTA: hoy.AddDate(1, 0, 0), ACTIVA: true}
+		So(facturas.CreateResolucion(&resolucion, admin), ShouldBeNil)
+		So(resolucion.SIGUIENTE, ShouldEqual, 990000000)
+
+		nuevoPedido := func(pagar bool) int {
+			pedido := models.Pedido{}
+			So(pedidos.Create(&pedido, mesero), ShouldBeNil)
+			_, err := services.NewProductoPedidoService(store).Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}, {PK_ID_PRODUCTO: 8, CANTIDAD: 2}}, mesero)
+			So(err, ShouldBeNil)
+			if pagar {
+				cuenta, err := services.NewCuentaService(store).Dividir(pedido.PK_ID_PEDIDO, &models.DivisionCuenta{MODO: "IGUALES", PARTES: []models.ParteCuenta{{PK_ID_METODO_PAGO: metodo.PK_ID_METODO_PAGO}}}, mesero)
+				So(err, ShouldBeNil)
+				_, err = pedidos.AssignPago(pedido.PK_ID_PEDIDO, cuenta.PAGOS[0].PK_ID_PAGO, mesero)
+				So(err, ShouldBeNil)
+			}
+			return pedido.PK_ID_PEDIDO
+		}
+
+		Convey("El documento de un pedido pagado lleva el CUDE y una firma que se verifica", func() {
+			documento, err := facturas.Emitir(models.DocumentoElectronicoRequest{PK_ID_PEDIDO: nuevoPedido(true)}, mesero)
+			So(err, ShouldBeNil)
+			So(documento.TIPO_DOCUMENTO, ShouldEqual, services.DocumentoPOS)
+			So(documento.PREFIJO, ShouldEqual, "SETP")
+			So(documento.NUMERO, ShouldEqual, 990000000)
+			So(len(documento.CODIGO), ShouldEqual, 96)
+			So(documento.TOTAL, ShouldEqual, 38900)
+			So(documento.DOCUMENTO_ADQUIRENTE, ShouldEqual, facturacion.ConsumidorFinal)
+			So(documento.ESTADO, ShouldEqual, services.DocumentoAceptado)
+			So(*documento.ID_ENVIO, ShouldStartWith, "LOCAL-")
+
+			nombre, xml, err := facturas.XML(documento.PK_ID_DOCUMENTO, mesero)
+			So(err, ShouldBeNil)
+			So(nombre, ShouldEqual, "setp990000000.xml")
+			So(string(xml), ShouldContainSubstring, `<cbc:UUID schemeID="2" schemeName="CUDE-SHA384">`+documento.CODIGO+`</cbc:UUID>`)
+			So(string(xml), ShouldContainSubstring, `<cbc:TaxAmount currencyID="COP">2000.00</cbc:TaxAmount>`)
+			_, err = facturacion.Verificar(xml)
+			So(err, ShouldBeNil)
+			_, err = facturacion.Verificar(bytes.Replace(xml, []byte("Consumidor final"), []byte("Consumidor falso"), 1))
+			So(err, ShouldNotBeNil)
+
+			local, ok := services.ProveedorFacturacion.(*facturacion.ProveedorLocal)
+			So(ok, ShouldBeTrue)
+			_, ok = local.Recibido(documento.CODIGO)
+			So(ok, ShouldBeTrue)
+
+			_, err = facturas.Reenviar(documento.PK_ID_DOCUMENTO, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("Los consecutivos no se repiten y el rango agotado responde 422", func() {
+			primero, err := facturas.Emitir(models.DocumentoElectronicoRequest{PK_ID_PEDIDO: nuevoPedido(true)}, mesero)
+			So(err, ShouldBeNil)
+			segundoPedido := nuevoPedido(true)
+			segundo, err := facturas.Emitir(models.DocumentoElectronicoRequest{PK_ID_PEDIDO: segundoPedido}, mesero)
+			So(err, ShouldBeNil)
+			So(segundo.NUMERO, ShouldEqual, primero.NUMERO+1)
+			So(segundo.CODIGO, ShouldNotEqual, primero.CODIGO)
+
+			_, err = facturas.Emitir(models.DocumentoElectronicoRequest{PK_ID_PEDIDO: segundoPedido}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+			_, err = facturas.Emitir(models.DocumentoElectronicoRequest{PK_ID_PEDIDO: nuevoPedido(true)}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+
+			resolucion.HASTA = 990000000
+			So(errorCode(facturas.UpdateResolucion(&resolucion, admin)), ShouldEqual, http.StatusUnprocessableEntity)
+		})
+
+		Convey("Solo se facturan los pedidos pagados y la factura exige resolución propia", func() {
+			_, err := facturas.Emitir(models.DocumentoElectronicoRequest{PK_ID_PEDIDO: nuevoPedido(false)}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			adquirente := &models.AdquirenteRequest{TIPO_DOCUMENTO: "31", NUMERO: "800197268", NOMBRE: "Empresa S.A.S."}
+			_, err = facturas.Emitir(models.DocumentoElectronicoRequest{PK_ID_PEDIDO: nuevoPedido(true), TIPO_DOCUMENTO: "factura", ADQUIRENTE: adquirente}, mesero)
+			So(errorCode(err), ShouldEqual, http.StatusUnprocessableEntity)
+
+			_, err = facturas.Emitir(models.DocumentoElectronicoRequest{PK_ID_PEDIDO: nuevoPedido(true)}, services.Actor{Documento: 2001, Rol: "cliente"})
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("El CUFE depende de todos los datos del documento", func() {
+			factura := facturacion.Factura{
+				Tipo:       facturacion.TipoFactura,
+				Ambiente:   facturacion.AmbientePruebas,
+				Numero:     1,
+				Resolucion: facturacion.Resolucion{Prefijo: "SETT", ClaveTecnica: "fc8eac422eba16e22ffd8c6f94b3f40a6e38162c"},
+				Emision:    time.Date(2026, 1, 15, 12, 30, 0, 0, database.BogotaZone),
+				Emisor:     facturacion.Emisor{NIT: "900123456"},
+				Adquirente: facturacion.Adquirente{Numero: "800197268"},
+				Lineas:     []facturacion.Linea{{Cantidad: 1, PrecioUnitario: 10000, Total: 10000, Impuesto: &facturacion.Impuesto{Codigo: facturacion.TributoIVA, Tarifa: 19, Base: 10000, Valor: 1900}}},
+				Total:      11900,
+			}
+			codigo := factura.Codigo()
+			So(len(codigo), ShouldEqual, 96)
+			So(factura.Codigo(), ShouldEqual, codigo)
+			factura.Total = 11901
+			So(factura.Codigo(), ShouldNotEqual, codigo)
+			So(facturacion.DigitoVerificacion("800197268"), ShouldEqual, "4")
+			So(facturacion.DigitoVerificacion("900.123.456"), ShouldEqual, facturacion.DigitoVerificacion("900123456"))
+		})
+	})
+}
+
+func TestRecompra(t *testing.T) {
+	Convey("Subject: Historial, favoritos y recompra del cliente\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Churrasco", 30000), nuevoProducto(8, "Limonada", 6000))
+		pedidos := services.NewPedidoService(store)
+		recompra := services.NewRecompraService(store)
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+		cliente := services.Actor{Documento: 1010, Rol: services.RolCliente}
+		documento := int64(cliente.Documento)
+
+		So(store.Clientes().Insert(&models.Cliente{PK_DOCUMENTO_CLIENTE: cliente.Documento, NOMBRE: "Ana", TELEFONO: "3001234567"}), ShouldBeNil)
+
+		pedido := models.Pedido{}
+		So(pedidos.Create(&pedido, mesero), ShouldBeNil)
+		So(store.PedidosClientes().Insert(&models.PedidoCliente{PK_DOCUMENTO_CLIENTE: &documento, PK_ID_PEDIDO: &pedido.PK_ID_PEDIDO}), ShouldBeNil)
+		_, err := services.NewProductoPedidoService(store).Create(int64(pedido.PK_ID_PEDIDO), []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}, {PK_ID_PRODUCTO: 8, CANTIDAD: 2}}, mesero)
+		So(err, ShouldBeNil)
+
+		Convey("El historial muestra los productos cobrados y marca los favoritos", func() {
+			favorito, err := recompra.CreateFavorito(cliente.Documento, &models.FavoritoRequest{PK_ID_PEDIDO: pedido.PK_ID_PEDIDO}, cliente)
+			So(err, ShouldBeNil)
+			So(favorito.NOMBRE, ShouldEqual, "Pedido "+strconv.Itoa(pedido.PK_ID_PEDIDO))
+			So(favorito.PRODUCTOS, ShouldHaveLength, 2)
+			_, err = recompra.CreateFavorito(cliente.Documento, &models.FavoritoRequest{PK_ID_PEDIDO: pedido.PK_ID_PEDIDO}, cliente)
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			historial, err := recompra.Historial(cliente.Documento, 0, cliente)
+			So(err, ShouldBeNil)
+			So(historial, ShouldHaveLength, 1)
+			So(historial[0].PRODUCTOS, ShouldHaveLength, 2)
+			So(*historial[0].PK_ID_FAVORITO, ShouldEqual, favorito.PK_ID_FAVORITO)
+
+			_, err = recompra.Historial(2020, 0, cliente)
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+
+			So(recompra.DeleteFavorito(cliente.Documento, favorito.PK_ID_FAVORITO, cliente), ShouldBeNil)
+			favoritos, err := recompra.Favoritos(cliente.Documento, cliente)
+			So(err, ShouldBeNil)
+			So(favoritos, ShouldBeEmpty)
+		})
+
+		Convey("Repetir cobra el precio vigente y deja por fuera lo que no está disponible", func() {
+			churrasco, _ := store.Productos().Get(7)
+			churrasco.PRECIO = 32000
+			So(store.Productos().Update(churrasco, churrasco.VERSION), ShouldBeNil)
+			limonada, _ := store.Productos().Get(8)
+			limonada.ESTADO_PRODUCTO = "NO DISPONIBLE"
+			So(store.Productos().Update(limonada, limonada.VERSION), ShouldBeNil)
+
+			repetido, err := recompra.Repetir(pedido.PK_ID_PEDIDO, cliente)
+			So(err, ShouldBeNil)
+			So(repetido.PEDIDO.PK_ID_PEDIDO, ShouldNotEqual, pedido.PK_ID_PEDIDO)
+			So(repetido.PEDIDO.TOTAL, ShouldEqual, 32000)
+			So(repetido.NO_DISPONIBLES, ShouldHaveLength, 1)
+			So(repetido.NO_DISPONIBLES[0].PK_ID_PRODUCTO, ShouldEqual, 8)
+
+			historial, err := recompra.Historial(cliente.Documento, 0, cliente)
+			So(err, ShouldBeNil)
+			So(historial, ShouldHaveLength, 2)
+			So(historial[0].PEDIDO.PK_ID_PEDIDO, ShouldEqual, repetido.PEDIDO.PK_ID_PEDIDO)
+
+			_, err = recompra.Repetir(pedido.PK_ID_PEDIDO, services.Actor{Documento: 2020, Rol: services.RolCliente})
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+		})
+	})
+}
+
+func TestPedidosTelefonicos(t *testing.T) {
+	Convey("Subject: Pedidos que llegan por teléfono\n", t, func() {
+		store := nuevoStore(nuevoProducto(7, "Bandeja", 20000))
+		service := services.NewPedidoTelefonicoService(store)
+		mesero := services.Actor{Documento: 1015466494, Rol: "Mesero"}
+
+		So(store.Clientes().Insert(&models.Cliente{PK_DOCUMENTO_CLIENTE: 1010, NOMBRE: "Ana", DIRECCION: "Calle 1 # 2-3", TELEFONO: "+57 300 123 4567", PASSWORD: "hash"}), ShouldBeNil)
+		metodo := models.MetodoPago{TIPO: "EFECTIVO"}
+		So(store.MetodosPago().Insert(&metodo), ShouldBeNil)
+		pedido := func(telefono string, domicilio *models.CheckoutDomicilio, cliente *models.ClienteNuevoRequest) (*models.PedidoTelefonicoResponse, error) {
+			req := models.PedidoTelefonicoRequest{TELEFONO: telefono, CLIENTE: cliente}
+			req.PRODUCTOS = []models.ItemPedido{{PK_ID_PRODUCTO: 7, CANTIDAD: 1}}
+			req.DOMICILIO = domicilio
+			req.PK_ID_METODO_PAGO = metodo.PK_ID_METODO_PAGO
+			return service.Crear(&req, mesero)
+		}
+
+		Convey("El número se reconoce con o sin indicativo y con separadores", func() {
+			_, err := pedido("3001234567", &models.CheckoutDomicilio{DIRECCION: "Carrera 7 # 45-10"}, nil)
+			So(err, ShouldBeNil)
+
+			for _, numero := range []string{"3001234567", "573001234567", "+57 300 123 4567", "(300) 123-4567", "0057 300.123.4567"} {
+				llamada, err := service.Buscar(numero, mesero)
+				So(err, ShouldBeNil)
+				So(llamada.TELEFONO, ShouldEqual, "+573001234567")
+				So(llamada.CLIENTE.PK_DOCUMENTO_CLIENTE, ShouldEqual, 1010)
+				So(llamada.CLIENTE.PASSWORD, ShouldBeEmpty)
+				So(llamada.DIRECCIONES, ShouldResemble, []string{"Calle 1 # 2-3", "Carrera 7 # 45-10"})
+				So(llamada.PEDIDOS, ShouldHaveLength, 1)
+			}
+
+			for _, numero := range []string{"", "300123456", "1234567890", "+57 300 ABC 4567"} {
+				_, err := service.Buscar(numero, mesero)
+				So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+			}
+			_, err = service.Buscar("3001234567", services.Actor{Documento: 1010, Rol: services.RolCliente})
+			So(errorCode(err), ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("Un número desconocido registra al cliente sin contraseña", func() {
+			_, err := pedido("+57 320 987 6543", nil, nil)
+			So(errorCode(err), ShouldEqual, http.StatusBadRequest)
+			_, err = pedido("+57 320 987 6543", nil, &models.ClienteNuevoRequest{PK_DOCUMENTO_CLIENTE: 1010, NOMBRE: "Ana"})
+			So(errorCode(err), ShouldEqual, http.StatusConflict)
+
+			respuesta, err := pedido("+57 320 987 6543", &models.CheckoutDomicilio{}, &models.ClienteNuevoRequest{PK_DOCUMENTO_CLIENTE: 2020, NOMBRE: "Pedro", DIRECCION: "Calle 80 # 20-15"})
+			So(err, ShouldBeNil)
+			So(respuesta.CLIENTE_NUEVO, ShouldBeTrue)
+			So(respuesta.DOMICILIO.DIRECCION, ShouldEqual, "Calle 80 # 20-15")
+			So(respuesta.DOMICILIO.TELEFONO, ShouldEqual, "+573209876543")
+
+			registrado, err := store.Clientes().Get(2020)
+			So(err, ShouldBeNil)
+			So(registrado.TELEFONO, ShouldEqual, "+573209876543")
+			So(registrado.PASSWORD, ShouldBeEmpty)
+
+			// La siguiente llamada ya lo encuentra
+			respuesta, err = pedido("3209876543", nil, nil)
+			So(err, ShouldBeNil)
+			So(respuesta.CLIENTE_NUEVO, ShouldBeFalse)
+			So(respuesta.CLIENTE.PK_DOCUMENTO_CLIENTE, ShouldEqual, 2020)
+		})
+
+		Convey("El pedido queda por cobrar hasta que se asigna el pago", func() {
+			respuesta, err := pedido("3001234567", &models.CheckoutDomicilio{}, nil)
+			So(err, ShouldBeNil)
+			So(respuesta.PEDIDO.ESTADO_PEDIDO, ShouldEqual, services.EstadoIniciado)
+			So(*respuesta.PEDIDO.PK_ID_PAGO, ShouldEqual, respuesta.PAGO.PK_ID_PAGO)
+			So(respuesta.PAGO.ESTADO_PAGO, ShouldEqual, "PENDIENTE")
+			So(respuesta.DOMICILIO.ESTADO_PAGO, ShouldEqual, "PENDIENTE")
+
+			cobrado, err := services.NewPedidoService(store).AssignPago(respuesta.PEDIDO.PK_ID_PEDIDO, respuesta.PAGO.PK_ID_PAGO, mesero)
+			So(err, ShouldBeNil)
+			So(cobrado.ESTADO_PEDIDO, ShouldEqual, services.EstadoPagado)
+		})
+	})
+}
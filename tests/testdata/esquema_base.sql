@@ -0,0 +1,153 @@
+-- Esquema de la base antes de la primera migración, generado con "orm sqlall" sobre los modelos
+-- del commit inicial. TestMigraciones parte de este esquema para aplicar la serie completa de
+-- migraciones; no se modifica cuando cambian los modelos.
+
+CREATE TABLE IF NOT EXISTS "CAMBIOS_HORARIO" (
+    "PK_ID_CAMBIO_HORARIO" bigserial NOT NULL PRIMARY KEY,
+    "FECHA" date NOT NULL,
+    "HORA_APERTURA" timestamp with time zone,
+    "HORA_CIERRE" timestamp with time zone,
+    "ABIERTO" bool NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS "CLIENTE" (
+    "PK_DOCUMENTO_CLIENTE" integer NOT NULL PRIMARY KEY,
+    "NOMBRE" text NOT NULL,
+    "APELLIDO" text NOT NULL,
+    "DIRECCION" text NOT NULL,
+    "TELEFONO" text NOT NULL,
+    "OBSERVACIONES" text NOT NULL,
+    "PASSWORD" text NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "DOMICILIO" (
+    "PK_ID_DOMICILIO" bigserial NOT NULL PRIMARY KEY,
+    "DIRECCION" text NOT NULL,
+    "TELEFONO" text NOT NULL,
+    "ESTADO_PAGO" text NOT NULL,
+    "ENTREGADO" bool NOT NULL DEFAULT FALSE ,
+    "FECHA" date NOT NULL,
+    "OBSERVACIONES" text NOT NULL,
+    "CREATED_AT" timestamp with time zone NOT NULL,
+    "UPDATED_AT" timestamp with time zone NOT NULL,
+    "CREATED_BY" text NOT NULL DEFAULT '' ,
+    "UPDATED_BY" text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS "INCIDENCIA" (
+    "PK_ID_INCIDENCIA" bigserial NOT NULL PRIMARY KEY,
+    "FECHA" date NOT NULL,
+    "MONTO" bigint NOT NULL DEFAULT 0 ,
+    "RESTA" bool NOT NULL DEFAULT FALSE ,
+    "MOTIVO" text NOT NULL,
+    "PK_DOCUMENTO_TRABAJADOR" bigint
+);
+
+CREATE TABLE IF NOT EXISTS "METODO_PAGO" (
+    "PK_ID_METODO_PAGO" bigserial NOT NULL PRIMARY KEY,
+    "TIPO" varchar(50) NOT NULL DEFAULT '' ,
+    "DETALLE" text,
+    "PK_ID_PAGO" integer
+);
+
+CREATE TABLE IF NOT EXISTS "NOMINA" (
+    "PK_ID_NOMINA" bigserial NOT NULL PRIMARY KEY,
+    "FECHA" date NOT NULL,
+    "MONTO" bigint NOT NULL DEFAULT 0 ,
+    "ESTADO_NOMINA" text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS "NOMINA_TRABAJADOR" (
+    "PK_ID_NOMINA_TRABAJADOR" bigserial NOT NULL PRIMARY KEY,
+    "SUELDO_BASE" bigint NOT NULL DEFAULT 0 ,
+    "MONTO_INCIDENCIAS" bigint,
+    "TOTAL" bigint,
+    "DETALLES" text,
+    "PK_DOCUMENTO_TRABAJADOR" bigint,
+    "PK_ID_NOMINA" bigint
+);
+
+CREATE TABLE IF NOT EXISTS "PAGO" (
+    "PK_ID_PAGO" bigserial NOT NULL PRIMARY KEY,
+    "FECHA" date NOT NULL,
+    "HORA" text NOT NULL DEFAULT '' ,
+    "MONTO" bigint NOT NULL DEFAULT 0 ,
+    "ESTADO_PAGO" text NOT NULL,
+    "PK_ID_METODO_PAGO" integer,
+    "UPDATED_AT" timestamp with time zone NOT NULL,
+    "UPDATED_BY" text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS "PEDIDO" (
+    "PK_ID_PEDIDO" bigserial NOT NULL PRIMARY KEY,
+    "FECHA" date NOT NULL,
+    "HORA" text NOT NULL DEFAULT '' ,
+    "DELIVERY" bool NOT NULL DEFAULT FALSE ,
+    "ESTADO_PEDIDO" text NOT NULL DEFAULT '' ,
+    "PK_ID_DOMICILIO" integer,
+    "PK_ID_PAGO" integer,
+    "PK_ID_RESTAURANTE" integer,
+    "UPDATED_AT" timestamp with time zone NOT NULL,
+    "UPDATED_BY" text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS "PEDIDO_CLIENTE" (
+    "PK_ID_PEDIDO_CLIENTE" bigserial NOT NULL PRIMARY KEY,
+    "PK_DOCUMENTO_CLIENTE" bigint,
+    "PK_ID_PEDIDO" integer
+);
+
+CREATE TABLE IF NOT EXISTS "PRODUCTO" (
+    "PK_ID_PRODUCTO" bigserial NOT NULL PRIMARY KEY,
+    "NOMBRE" text NOT NULL,
+    "CALORIAS" bigint NOT NULL DEFAULT 0 ,
+    "DESCRIPCION" text NOT NULL,
+    "PRECIO" bigint NOT NULL DEFAULT 0 ,
+    "ESTADO_PRODUCTO" text NOT NULL,
+    "IMAGEN" text,
+    "CANTIDAD" integer NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS "PRODUCTO_PEDIDO" (
+    "PK_ID_PRODUCTO_PEDIDO" bigserial NOT NULL PRIMARY KEY,
+    "DETALLES_PRODUCTOS" jsonb NOT NULL DEFAULT '{}' ,
+    "PK_ID_PEDIDO" bigint NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS "RESERVA" (
+    "PK_ID_RESERVA" bigserial NOT NULL PRIMARY KEY,
+    "FECHA" date NOT NULL,
+    "HORA" varchar(8) NOT NULL DEFAULT '' ,
+    "PERSONAS" integer NOT NULL DEFAULT 0 ,
+    "ESTADO_RESERVA" text,
+    "INDICACIONES" text,
+    "CREATED_AT" timestamp with time zone NOT NULL,
+    "UPDATED_AT" timestamp with time zone NOT NULL,
+    "CREATED_BY" text NOT NULL DEFAULT '' ,
+    "UPDATED_BY" text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS "RESTAURANTE" (
+    "PK_ID_RESTAURANTE" integer NOT NULL PRIMARY KEY,
+    "NOMBRE_RESTAURANTE" text NOT NULL DEFAULT '' ,
+    "HORA_APERTURA" text NOT NULL DEFAULT '' ,
+    "DIAS_LABORALES" text NOT NULL DEFAULT '' ,
+    "PK_ID_CAMBIO_HORARIO" integer,
+    "PK_ID_RESERVA" integer
+);
+
+CREATE TABLE IF NOT EXISTS "TRABAJADOR" (
+    "PK_DOCUMENTO_TRABAJADOR" bigint NOT NULL PRIMARY KEY,
+    "NOMBRE" text NOT NULL,
+    "APELLIDO" text NOT NULL,
+    "SUELDO" bigint NOT NULL DEFAULT 0 ,
+    "TELEFONO" text,
+    "FECHA_NACIMIENTO" date NOT NULL,
+    "NUEVO" bool NOT NULL DEFAULT FALSE ,
+    "ROL" text NOT NULL,
+    "FECHA_INGRESO" date NOT NULL,
+    "FECHA_RETIRO" date,
+    "PASSWORD" text NOT NULL DEFAULT '' ,
+    "HORARIO" text,
+    "PK_ID_RESTAURANTE" bigint
+);